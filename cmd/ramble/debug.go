@@ -0,0 +1,89 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"time"
+
+	"github.com/jeff-barlow-spady/ramble/pkg/config"
+	"github.com/jeff-barlow-spady/ramble/pkg/crash"
+	"github.com/jeff-barlow-spady/ramble/pkg/logger"
+)
+
+// processStarted is when Ramble started, for the uptime in /debug/status
+var processStarted = time.Now()
+
+// debugStatus is served at /debug/status to follow memory and buffer growth
+// over a long session
+type debugStatus struct {
+	Uptime     string `json:"uptime"`
+	Goroutines int    `json:"goroutines"`
+	Memory     struct {
+		HeapAllocBytes uint64 `json:"heap_alloc_bytes"`
+		HeapObjects    uint64 `json:"heap_objects"`
+		SysBytes       uint64 `json:"sys_bytes"`
+		GCRuns         uint32 `json:"gc_runs"`
+	} `json:"memory"`
+	Buffers struct {
+		CaptureQueueSeconds float64 `json:"capture_queue_seconds"`
+		TranscriberSeconds  float64 `json:"transcriber_seconds"`
+		SegmentAudioSeconds float64 `json:"segment_audio_seconds"` // Kept for rewriting
+		TranscriptChars     int     `json:"transcript_chars"`
+	} `json:"buffers"`
+	Model string            `json:"model"`
+	State map[string]string `json:"state"` // As written to crash reports
+}
+
+// serveDebug serves pprof and /debug/status when started with -debug, so
+// memory growth in long sessions can be diagnosed. Like metrics, they have
+// no authentication, so they listen on this machine by default.
+func (a *App) serveDebug() {
+	address := config.Current.DebugAddress
+	if !a.debug || address == "" {
+		return
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mux.HandleFunc("/debug/status", a.serveDebugStatus)
+	crash.Go(func() {
+		logger.Info(logger.CategoryApp, "Serving diagnostics at http://%s/debug/pprof/ and /debug/status", address)
+		if err := http.ListenAndServe(address, mux); err != nil {
+			logger.Error(logger.CategoryApp, "Diagnostics server stopped: %v", err)
+		}
+	})
+}
+
+// serveDebugStatus writes the debugStatus as JSON
+func (a *App) serveDebugStatus(w http.ResponseWriter, _ *http.Request) {
+	var status debugStatus
+	status.Uptime = time.Since(processStarted).Round(time.Second).String()
+	status.Goroutines = runtime.NumGoroutine()
+
+	var memory runtime.MemStats
+	runtime.ReadMemStats(&memory)
+	status.Memory.HeapAllocBytes = memory.HeapAlloc
+	status.Memory.HeapObjects = memory.HeapObjects
+	status.Memory.SysBytes = memory.Sys
+	status.Memory.GCRuns = memory.NumGC
+
+	status.Buffers.CaptureQueueSeconds = a.audio.QueuedSeconds()
+	status.Buffers.TranscriberSeconds = float64(a.transcriber.BufferedSamples()) / 16000
+	a.mu.Lock()
+	status.Buffers.SegmentAudioSeconds = float64(len(a.segmentAudio)) / 16000
+	status.Buffers.TranscriptChars = len(a.fullText)
+	status.Model = string(a.model)
+	a.mu.Unlock()
+	status.State = crash.State()
+
+	w.Header().Set("Content-Type", "application/json")
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(status)
+}
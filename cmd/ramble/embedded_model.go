@@ -0,0 +1,20 @@
+//go:build embed_model
+
+package main
+
+import (
+	_ "embed"
+
+	"github.com/jeff-barlow-spady/ramble/pkg/transcription"
+)
+
+// embeddedModel is the tiny English model, built in with the embed_model tag
+// so release binaries work without a separate download. Fetch it first with
+// scripts/fetch-embedded-model.sh.
+//
+//go:embed models/ggml-tiny.en.bin
+var embeddedModel []byte
+
+func init() {
+	transcription.SetEmbeddedModel(embeddedModel)
+}
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/jeff-barlow-spady/ramble/pkg/audio"
+	"github.com/jeff-barlow-spady/ramble/pkg/config"
+	"github.com/jeff-barlow-spady/ramble/pkg/crash"
+	"github.com/jeff-barlow-spady/ramble/pkg/engine"
+	"github.com/jeff-barlow-spady/ramble/pkg/logger"
+	"github.com/jeff-barlow-spady/ramble/pkg/output"
+	"github.com/jeff-barlow-spady/ramble/pkg/session"
+	"github.com/jeff-barlow-spady/ramble/pkg/textproc"
+	"github.com/jeff-barlow-spady/ramble/pkg/ui"
+)
+
+// Frontends chosen with the -frontend flag
+const (
+	frontendGUI      = "gui"      // The Fyne window, with every feature
+	frontendTerminal = "terminal" // The terminal UI
+	frontendNone     = "none"     // No UI, printing each segment to stdout
+)
+
+// runFrontend records and transcribes behind the terminal UI or no UI at all.
+// These run the engine alone, so the session history and other features of
+// the window aren't available, but text is corrected, redacted and sent to
+// the outputs and hooks like the window's. Without a UI, recording starts
+// at once and each segment is printed until Ramble is interrupted or the
+// audio read with -stdin ends.
+func runFrontend(name, profile string) error {
+	var frontend ui.Frontend
+	var quit func()
+	switch name {
+	case frontendTerminal:
+		if pipeInput != nil {
+			return errors.New("the terminal frontend reads the keyboard, so it can't be used with -stdin")
+		}
+		terminal := ui.NewTerminalUI("")
+		buffer := &ui.LogBuffer{}
+		buffer.SetLogConsumer(terminal)
+		logger.SetOutput(buffer)
+		frontend, quit = terminal, terminal.Stop
+	case frontendNone:
+		null := ui.NewNullFrontend()
+		frontend, quit = null, null.Quit
+	default:
+		return fmt.Errorf("unknown frontend %q; use %s, %s or %s", name, frontendGUI, frontendTerminal, frontendNone)
+	}
+
+	if err := selectProfile(profile); err != nil {
+		return err
+	}
+	app := newFrontendApp(frontend)
+	defer app.closeMQTT()
+	transcriber, err := newTranscriber(liveModel())
+	if err != nil {
+		return err
+	}
+	transcriber.SetTuning(latencyTuning())
+	transcriber.SetVocabulary(config.Current.Vocabulary)
+	transcriber.SetKeepContext(config.Current.KeepContext)
+
+	var pause time.Duration
+	if seconds := config.Current.UtteranceSilenceSeconds; seconds > 0 {
+		pause = time.Duration(seconds * float64(time.Second))
+	}
+	eng, err := engine.New(engine.Options{
+		Transcriber: transcriber,
+		Backend:     audioBackend(),
+		SampleRate:  float64(config.Current.AudioSampleRate),
+		Language:    config.Current.Language,
+		Pause:       pause,
+		OnAudio: func(samples []float32) {
+			frontend.UpdateAudioLevel(audio.CalculateLevel(samples))
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	// Show the text as it is transcribed, and print each segment without a UI
+	shown := make(chan struct{})
+	crash.Go(func() {
+		defer close(shown)
+		var transcript, preview string
+		for event := range eng.Events() {
+			switch event.Type {
+			case engine.EventPartial:
+				text := app.processText(event.Text)
+				if text == "" {
+					continue
+				}
+				preview = textproc.Join(preview, text)
+				frontend.UpdateStreamingPreview(preview)
+				app.sendPartial(text)
+			case engine.EventSegment:
+				text := app.finishSegment(event)
+				preview = ""
+				transcript = strings.TrimSpace(transcript + "\n\n" + text)
+				frontend.UpdateTranscript(transcript)
+				if jsonEvents == nil && name == frontendNone {
+					fmt.Println(text)
+				}
+			case engine.EventError:
+				frontend.ShowTemporaryStatus(fmt.Sprintf("Error: %v", event.Err), 3*time.Second)
+			}
+		}
+	})
+
+	start := func() error {
+		frontend.SetState(ui.StateWarmingUp)
+		if err := eng.Start(); err != nil {
+			frontend.SetState(ui.StateIdle)
+			return err
+		}
+		frontend.SetState(ui.StateListening)
+		return nil
+	}
+	stop := func() {
+		if err := eng.Stop(); err != nil {
+			logger.Error(logger.CategoryAudio, "Error stopping audio: %v", err)
+		}
+		frontend.SetState(ui.StateIdle)
+	}
+	frontend.SetCallbacks(func() {
+		if err := start(); err != nil {
+			logger.Error(logger.CategoryAudio, "Failed to start recording: %v", err)
+			frontend.ShowErrorDialog("Error", err.Error())
+		}
+	}, stop, nil)
+
+	// Handle termination signals, and the end of audio read from stdin
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	crash.Go(func() {
+		<-sigChan
+		logger.Info(logger.CategoryApp, "Shutting down...")
+		quit()
+	})
+	if pipeInput != nil {
+		crash.Go(func() {
+			<-pipeInput.Ended()
+			if err := pipeInput.Err(); err != nil {
+				logger.Error(logger.CategoryAudio, "Failed to read audio from stdin: %v", err)
+			}
+			quit()
+		})
+	}
+
+	if name == frontendNone {
+		err = start()
+	}
+	if err == nil {
+		frontend.Run()
+	}
+
+	// Transcribe the end of the recording before exiting
+	stop()
+	err = errors.Join(err, eng.Close())
+	<-shown
+	return err
+}
+
+// newFrontendApp returns an App that sends the text transcribed behind
+// frontend on like the window does, configured from the config
+func newFrontendApp(frontend ui.Frontend) *App {
+	app := &App{
+		frontend:  frontend,
+		privacy:   &output.Privacy{},
+		corrector: correctorFromConfig(),
+	}
+	app.configureRedaction()
+	app.configureCleanup()
+	app.configureHooks()
+	app.configureWebhooks()
+	app.configureMQTT()
+	app.configureOutputs()
+	return app
+}
+
+// finishSegment returns the text of a segment the engine finalized, corrected,
+// redacted, cleaned up and run through the hooks, after sending it to the
+// outputs, webhooks, notes and MQTT broker
+func (a *App) finishSegment(event engine.Event) string {
+	a.mu.Lock()
+	cleaner, runner := a.cleaner, a.hooks
+	a.mu.Unlock()
+	if !a.privacy.Allows(output.KindHook) {
+		runner = nil
+	}
+
+	segment := session.Segment{
+		ID:        event.Segment,
+		Text:      a.processText(event.Text),
+		Language:  event.Language,
+		StartedAt: event.StartedAt,
+		EndedAt:   event.EndedAt,
+	}
+	segment.Text = a.runHooks(runner, "", segment, a.cleanup(cleaner, segment.Text))
+	a.sendSegment("", segment)
+	return segment.Text
+}
+
+// closeMQTT disconnects from the MQTT broker, if connected
+func (a *App) closeMQTT() {
+	if client := a.mqttClient(); client != nil {
+		client.Close()
+	}
+}
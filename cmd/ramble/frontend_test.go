@@ -0,0 +1,51 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jeff-barlow-spady/ramble/pkg/config"
+	"github.com/jeff-barlow-spady/ramble/pkg/engine"
+	"github.com/jeff-barlow-spady/ramble/pkg/ui"
+)
+
+func TestFrontendSegment(t *testing.T) {
+	saved := config.Current
+	t.Cleanup(func() { config.Current = saved })
+
+	path := filepath.Join(t.TempDir(), "segments.txt")
+	config.Current = config.DefaultConfig()
+	config.Current.RedactEmails = true
+	config.Current.RedactDisplay = true
+	config.Current.Hooks = []string{"tr a-z A-Z"}
+	config.Current.Outputs = []config.OutputRule{{Type: "file", Target: path}}
+
+	app := newFrontendApp(ui.NewNullFrontend())
+	defer app.closeMQTT()
+
+	text := app.finishSegment(engine.Event{Type: engine.EventSegment, Segment: 1, Text: "Mail bob@example.com"})
+	if text != "MAIL [EMAIL]" {
+		t.Errorf("Expected the segment redacted and run through the hook, got %q", text)
+	}
+
+	// The file output is written in the background
+	var written []byte
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		written, _ = os.ReadFile(path)
+		if len(written) > 0 {
+			break
+		}
+	}
+	if got := strings.TrimSpace(string(written)); got != "MAIL [EMAIL]" {
+		t.Errorf("Expected the file output to get the finished segment, got %q", got)
+	}
+
+	// Incognito dictation withholds the segment from the hooks
+	app.privacy.SetIncognito(true)
+	if text := app.finishSegment(engine.Event{Type: engine.EventSegment, Segment: 2, Text: "Mail bob@example.com"}); text != "Mail [email]" {
+		t.Errorf("Expected the hooks skipped while incognito, got %q", text)
+	}
+}
@@ -2,33 +2,156 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
+	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"strings"
 	"sync"
 	"syscall"
 	"time"
 
+	"github.com/jeff-barlow-spady/ramble/pkg/analytics"
 	"github.com/jeff-barlow-spady/ramble/pkg/audio"
+	"github.com/jeff-barlow-spady/ramble/pkg/autostart"
+	"github.com/jeff-barlow-spady/ramble/pkg/clipboard"
+	"github.com/jeff-barlow-spady/ramble/pkg/commands"
+	"github.com/jeff-barlow-spady/ramble/pkg/config"
+	"github.com/jeff-barlow-spady/ramble/pkg/crash"
+	"github.com/jeff-barlow-spady/ramble/pkg/hooks"
+	"github.com/jeff-barlow-spady/ramble/pkg/i18n"
+	"github.com/jeff-barlow-spady/ramble/pkg/instance"
+	"github.com/jeff-barlow-spady/ramble/pkg/integrations/llm"
+	"github.com/jeff-barlow-spady/ramble/pkg/integrations/mqtt"
+	"github.com/jeff-barlow-spady/ramble/pkg/integrations/webhook"
 	"github.com/jeff-barlow-spady/ramble/pkg/logger"
+	"github.com/jeff-barlow-spady/ramble/pkg/metrics"
+	"github.com/jeff-barlow-spady/ramble/pkg/notes"
+	"github.com/jeff-barlow-spady/ramble/pkg/output"
+	"github.com/jeff-barlow-spady/ramble/pkg/session"
+	"github.com/jeff-barlow-spady/ramble/pkg/tempfiles"
+	"github.com/jeff-barlow-spady/ramble/pkg/textproc"
+	"github.com/jeff-barlow-spady/ramble/pkg/tracing"
 	"github.com/jeff-barlow-spady/ramble/pkg/transcription"
+	"github.com/jeff-barlow-spady/ramble/pkg/tts"
 	"github.com/jeff-barlow-spady/ramble/pkg/ui"
 )
 
-// Comment out the embedded model code for now since the directory doesn't exist
-// Uncomment and use this when you want to embed models in the binary
-// //go:embed models/ggml-small.bin
-// var embeddedModel []byte
-
 // App represents the main application
 type App struct {
 	ui          *ui.App
-	transcriber *transcription.WhisperTranscriber
+	frontend    ui.Frontend // Shows state, status and errors: ui, or the frontend chosen with -frontend
+	transcriber transcription.Transcriber
 	audio       *audio.Capture
 	debug       bool
 	mu          sync.Mutex
 	fullText    string
+	stopAudio   chan struct{}       // Closed to stop the audio consumer goroutine
+	audioDone   chan struct{}       // Closed once the consumer handed the rest of the queue to the transcriber
+	archiver    *audio.Archiver     // Saves raw recordings when audio archiving is enabled
+	recording   *audio.Archiver     // Archiver for the recording in progress, if any
+	idleTimer   *time.Timer         // Releases the model and audio system when it fires
+	warmingUp   bool                // Reloading released resources before recording
+	redactor    *textproc.Redactor  // Masks transcribed text before it is displayed, if enabled; guarded by mu
+	corrector   *textproc.Corrector // Applies the user's correction rules; guarded by mu
+	notifier    *webhook.Notifier   // Posts finalized segments to webhooks; guarded by mu
+	mqtt        *mqtt.Client        // Publishes transcripts for home automation; guarded by mu
+	outputs     *output.Router      // Sends finalized segments to the enabled outputs; guarded by mu
+	outgoing    *textproc.Redactor  // Masks text leaving the app, if enabled; guarded by mu
+	model       transcription.ModelSize
+	analytics   *analytics.Tracker   // Local usage statistics, nil if unavailable
+	started     time.Time            // When the recording in progress started
+	calibrating chan struct{}        // Closed to stop microphone calibration; nil when not calibrating
+	metrics     *appMetrics          // Exported at /metrics if a metrics address is set
+	logFile     *logger.RotatingFile // Receives logs when file logging is enabled; nil otherwise
+	tracer      *tracing.Tracer      // Records the latency of each stage when tracing; nil otherwise
+	spoken      string               // Language detected for the newest live text; guarded by mu
+
+	// Speed of live transcription, shown in the status bar
+	performance *transcription.PerformanceMeter
+
+	// Runs commands sent by other Ramble processes; nil if not listening
+	commands *instance.Server
+
+	// Transcribes files opened in the UI with models of its own
+	files *transcription.Queue
+
+	// Incognito dictation, switched from the UI; every destination that keeps
+	// transcripts or recordings asks it first
+	privacy *output.Privacy
+
+	// Two-pass transcription, guarded by mu
+	rewriter       *transcription.Rewriter // Re-transcribes finalized segments; nil when off
+	rewriteModel   transcription.ModelSize // Model the rewriter uses
+	cleaner        *textproc.Cleaner       // Cleans up the punctuation of finalized segments; nil when off
+	hooks          *hooks.Runner           // Runs the user's programs on finalized segments; guarded by mu
+	cleanups       chan func()             // Cleanups waiting for the cleanup worker
+	spotter        *textproc.Spotter       // Finds watched keywords in finalized segments; guarded by mu
+	dispatcher     *commands.Dispatcher    // Runs the voice commands heard in command mode; guarded by mu
+	segmentAudio   []float32               // Audio of the segment being recorded, kept for the rewriter
+	utteranceEnd   int                     // Samples of segmentAudio before the last detected pause, 0 if none
+	segmentTooLong bool                    // segmentAudio outgrew maxRewriteSamples and was dropped
+
+	// Speech heard in the segment being recorded, for session statistics; guarded by mu
+	segmentSpeech time.Duration // Speech before the last detected pause, or all of it if none
+	nextSpeech    time.Duration // Speech after the last detected pause, which starts the next segment
+	pauseMarked   bool          // A pause was detected in the segment being recorded
+}
+
+// maxRewriteSamples bounds the audio kept for rewriting a single segment
+const maxRewriteSamples = 16000 * 60 * 5
+
+// commandPause ends a phrase in command mode when no utterance pause is configured
+const commandPause = 800 * time.Millisecond
+
+// drainTimeout bounds how long stopping waits for the end of the recording
+// to be transcribed before finalizing the segment without it
+const drainTimeout = 5 * time.Second
+
+// metricsAddress is where Prometheus metrics are served, set by the -metrics
+// flag. It overrides the MetricsAddress config setting.
+var metricsAddress string
+
+// traceFile is where a latency trace is written, set by the -trace flag.
+// Tracing is off without it.
+var traceFile string
+
+// jsonEvents prints transcript events to stdout when the -stdout-json flag
+// is set; nil otherwise
+var jsonEvents *output.JSONLines
+
+// pipeInput is the audio read from standard input when the -stdin flag is
+// set, recorded instead of the configured audio backend; nil otherwise
+var pipeInput *audio.PipeBackend
+
+// appMetrics are the values exported for monitoring
+type appMetrics struct {
+	registry  *metrics.Registry
+	chunks    *metrics.Counter
+	latency   *metrics.Histogram
+	realTime  *metrics.Gauge
+	reconnect *metrics.Counter
+	dropped   *metrics.Counter
+	modelLoad *metrics.Gauge
+}
+
+// newAppMetrics registers the exported metrics
+func newAppMetrics() *appMetrics {
+	r := metrics.NewRegistry()
+	return &appMetrics{
+		registry:  r,
+		chunks:    r.Counter("ramble_audio_chunks_processed_total", "Audio chunks passed to the transcriber."),
+		latency:   r.Histogram("ramble_transcription_latency_seconds", "Time whisper took for each live transcription pass.", metrics.DefBuckets),
+		realTime:  r.Gauge("ramble_transcription_real_time_factor", "Processing time divided by audio length for the last pass; above 1 falls behind."),
+		reconnect: r.Counter("ramble_device_reconnects_total", "Audio streams reopened after the input device was lost."),
+		dropped:   r.Counter("ramble_dropped_audio_seconds_total", "Audio dropped because transcription fell behind."),
+		modelLoad: r.Gauge("ramble_model_load_seconds", "Time the whisper model last took to load."),
+	}
 }
 
 // New creates a new application instance
@@ -37,10 +160,18 @@ func New(debug bool) (*App, error) {
 	app := &App{
 		debug:    debug,
 		fullText: "",
+		metrics:  newAppMetrics(),
+		privacy:  &output.Privacy{},
+	}
+	if traceFile != "" {
+		app.tracer = tracing.New()
 	}
+	app.configureLogging()
 
-	// Setup UI
+	// Setup UI in the chosen language
+	i18n.SetLanguage(config.Current.UILanguage)
 	app.ui = ui.NewWithOptions(debug)
+	app.frontend = app.ui
 	app.ui.SetCallbacks(
 		app.startRecording,
 		app.stopRecording,
@@ -52,181 +183,2681 @@ func New(debug bool) (*App, error) {
 		},
 	)
 
-	// Find model path
-	modelPath := transcription.GetLocalModelPath(transcription.ModelTiny)
-	if modelPath == "" {
-		return nil, fmt.Errorf("could not find a valid model file")
+	// Find the model of the active preset or latency profile, or the tiny model if it isn't installed.
+	// With two-pass transcription tiny shows text live and the rewrite model corrects it.
+	app.model = liveModel()
+	if config.Current.RewriteModel != "" && usesLocalModel() {
+		app.model = transcription.ModelTiny
+	}
+	if usesLocalModel() && transcription.GetLocalModelPath(app.model) == "" && app.model != transcription.ModelTiny {
+		logger.Warning(logger.CategoryTranscription, "The %s model is not installed; using tiny", app.model)
+		app.model = transcription.ModelTiny
 	}
 
-	// Setup transcriber using the Manager directly
-	transcriber, err := transcription.NewManager(modelPath)
+	loadStarted := time.Now()
+	transcriber, err := newTranscriber(app.model)
 	if err != nil {
-		return nil, fmt.Errorf("failed to initialize transcriber: %w", err)
+		return nil, err
 	}
 	app.transcriber = transcriber
+	app.metrics.modelLoad.Set(time.Since(loadStarted).Seconds())
+	app.performance = newPerformanceMeter(app.model)
+	app.ui.SetPerformance(app.performance.Current())
+	app.transcriber.SetPassCallback(func(window, elapsed time.Duration) {
+		app.metrics.latency.Observe(elapsed.Seconds())
+		if window > 0 {
+			app.metrics.realTime.Set(elapsed.Seconds() / window.Seconds())
+		}
+		app.ui.SetPerformance(app.performance.Pass(window, elapsed))
+		app.tracer.Pass(window, elapsed)
+	})
+	if restarter, ok := app.transcriber.(transcription.Restarter); ok {
+		restarter.SetRestartCallback(func(reason error) {
+			app.frontend.ShowTemporaryStatus("Transcription stopped responding; restarted it", 4*time.Second)
+		})
+	}
 
 	// Setup audio capture
-	capture, err := audio.New(16000, debug)
+	capture, err := audio.NewWithBackend(audioBackend(), float64(config.Current.AudioSampleRate), debug)
 	if err != nil {
 		app.transcriber.Close()
 		return nil, fmt.Errorf("failed to initialize audio: %w", err)
 	}
 	app.audio = capture
 
+	// Bound the audio held for the transcriber while it catches up
+	if err := app.audio.SetQueueSeconds(float64(config.Current.AudioQueueSeconds)); err != nil {
+		logger.Warning(logger.CategoryAudio, "Using default audio queue length: %v", err)
+	}
+
+	app.configureLatency()
+	app.configureSuppression()
+
+	// Apply the gain saved for the microphone in use
+	device := app.audio.DeviceName()
+	app.audio.SetGain(inputGain(device))
+	app.audio.SetDeviceHandling(config.Current.AudioFallbackToDefault, app.onDeviceEvent)
+
+	// Show saved settings in the preferences dialog and persist changes to them
+	prefs := app.ui.GetPreferences()
+	prefs.AudioBackend = config.Current.AudioBackend
+	prefs.SampleRate = float64(config.Current.AudioSampleRate)
+	prefs.InputDevice = device
+	prefs.InputGain = inputGain(device)
+	prefs.FallbackDevice = config.Current.AudioFallbackToDefault
+	prefs.UILanguage = config.Current.UILanguage
+	prefs.Visualization = config.Current.Visualization
+	prefs.TranscriptFontSize = config.Current.TranscriptFontSize
+	prefs.TranscriptFont = config.Current.TranscriptFont
+	prefs.TranscriptLineSpacing = config.Current.TranscriptLineSpacing
+	prefs.HoverOnTop = config.Current.HoverOnTop
+	prefs.HoverWidth = config.Current.HoverWidth
+	prefs.HoverHeight = config.Current.HoverHeight
+	prefs.HoverOpacity = config.Current.HoverOpacity
+	prefs.HoverClickThrough = config.Current.HoverClickThrough
+	prefs.RecordingIndicator = config.Current.RecordingIndicator
+	prefs.ArchiveAudio = config.Current.ArchiveAudio
+	prefs.ArchiveMaxDays = config.Current.ArchiveMaxDays
+	prefs.ArchiveMaxSizeMB = config.Current.ArchiveMaxSizeMB
+	prefs.ArchiveFormat = config.Current.ArchiveFormat
+	prefs.RelaunchOnCrash = config.Current.RelaunchAfterCrash
+	prefs.IdleReleaseMinutes = config.Current.IdleReleaseMinutes
+	prefs.StartMinimized = config.Current.StartMinimized
+	prefs.StartAtLogin = autostart.Enabled()
+	prefs.UtteranceSilenceSeconds = config.Current.UtteranceSilenceSeconds
+	prefs.LatencyProfile = config.Current.LatencyProfile
+	prefs.Language = config.Current.Language
+	prefs.RewriteModel = config.Current.RewriteModel
+	prefs.CleanupSegments = config.Current.CleanupSegments
+	prefs.CleanupCommand = config.Current.CleanupCommand
+	prefs.TranscriptionBackend = config.Current.TranscriptionBackend
+	prefs.WhisperServerURL = config.Current.WhisperServerURL
+	prefs.FasterWhisperCommand = config.Current.FasterWhisperCommand
+	prefs.VoskCommand = config.Current.VoskCommand
+	prefs.Decoding = make(map[string]transcription.Decoding)
+	for backend, d := range config.Current.Decoding {
+		prefs.Decoding[backend] = transcription.Decoding(d)
+	}
+	prefs.SuppressHallucinations = config.Current.SuppressHallucinations
+	prefs.RolloverMinutes = config.Current.RolloverMinutes
+	prefs.RolloverCharacters = config.Current.RolloverCharacters
+	prefs.AutoStopSilenceMinutes = config.Current.AutoStopSilenceMinutes
+	prefs.KeepContext = config.Current.KeepContext
+	prefs.AutoCopy = config.Current.AutoCopy
+	prefs.AutoCopyMode = config.Current.AutoCopyMode
+	prefs.AutoCopyTransient = config.Current.AutoCopyTransient
+	prefs.CopyToPrimary = config.Current.CopyToPrimary
+	prefs.WebhookURL = config.Current.WebhookURL
+	prefs.SegmentWebhooks = config.Current.SegmentWebhookURLs
+	prefs.WebhookSecret = config.Current.WebhookSecret
+	prefs.NotesEnabled = config.Current.NotesEnabled
+	prefs.NotesPath = config.Current.NotesPath
+	prefs.NotesTemplate = config.Current.NotesTemplate
+	prefs.MQTTEnabled = config.Current.MQTTEnabled
+	prefs.MQTTBroker = config.Current.MQTTBroker
+	prefs.MQTTUsername = config.Current.MQTTUsername
+	prefs.MQTTPassword = config.Current.MQTTPassword
+	prefs.MQTTTopic = config.Current.MQTTTopic
+	prefs.MQTTPublishPartial = config.Current.MQTTPublishPartial
+	prefs.WatchKeywords = config.Current.WatchKeywords
+	prefs.VoiceCommands = config.Current.VoiceCommands
+	prefs.Hooks = config.Current.Hooks
+	prefs.Corrections = correctionLines(config.Current.Corrections)
+	prefs.SummaryEnabled = config.Current.SummaryEnabled
+	prefs.SummaryProvider = config.Current.SummaryProvider
+	prefs.SummaryURL = config.Current.SummaryURL
+	prefs.SummaryModel = config.Current.SummaryModel
+	prefs.SummaryAPIKey = config.Current.SummaryAPIKey
+	prefs.SummaryPrompt = config.Current.SummaryPrompt
+	prefs.TTSEngine = config.Current.TTSEngine
+	prefs.TTSVoice = config.Current.TTSVoice
+	prefs.Outputs = outputConfigs(config.Current.Outputs)
+	prefs.NumberLocale = config.Current.NumberLocale
+	prefs.AppOutputs = appRules(config.Current.AppOutputs)
+	prefs.AnalyticsSessions = config.Current.AnalyticsSessions
+	prefs.AnalyticsFeatures = config.Current.AnalyticsFeatures
+	prefs.RedactProfanity = config.Current.RedactProfanity
+	prefs.RedactEmails = config.Current.RedactEmails
+	prefs.RedactPhoneNumbers = config.Current.RedactPhoneNumbers
+	prefs.RedactCardNumbers = config.Current.RedactCardNumbers
+	prefs.RedactDisplay = config.Current.RedactDisplay
+	prefs.RedactClipboard = config.Current.RedactClipboard
+	prefs.RedactSaved = config.Current.RedactSaved
+	prefs.LogLevel = config.Current.LogLevel
+	prefs.LogCategoryLevels = config.Current.LogCategoryLevels
+	prefs.LogJSON = config.Current.LogJSON
+	prefs.LogToFile = config.Current.LogToFile
+	prefs.HotkeyKey = config.Current.HotKeyKey
+	prefs.HotkeyModifiers = nil
+	for _, mod := range []struct {
+		name    string
+		enabled bool
+	}{
+		{"ctrl", config.Current.HotKeyCtrl},
+		{"shift", config.Current.HotKeyShift},
+		{"alt", config.Current.HotKeyAlt},
+	} {
+		if mod.enabled {
+			prefs.HotkeyModifiers = append(prefs.HotkeyModifiers, mod.name)
+		}
+	}
+	app.ui.SetPreferences(prefs)
+	app.ui.SetPreferencesCallback(app.applyPreferences)
+	app.ui.SetHoverCallback(app.saveHoverSettings)
+	app.ui.SetCalibrationCallbacks(app.startCalibration, app.stopCalibration, app.audio.SetGain)
+	app.ui.SetPrivacy(app.privacy)
+	app.configureArchive()
+	clipboard.SetAlsoPrimary(config.Current.CopyToPrimary)
+
+	// Usage statistics stay on this computer and are only recorded if enabled
+	if tracker, err := analytics.DefaultTracker(); err != nil {
+		logger.Warning(logger.CategoryApp, "Usage statistics unavailable: %v", err)
+	} else {
+		app.analytics = tracker
+		app.configureAnalytics()
+		app.ui.SetAnalytics(tracker)
+	}
+
+	// Describe the backend in crash reports and keep the session if we crash
+	crash.AddState("recording", func() string {
+		return fmt.Sprintf("%v", app.audio.IsActive())
+	})
+	crash.AddState("audio queue", func() string {
+		return fmt.Sprintf("%.1fs of %.1fs, %d samples dropped",
+			app.audio.QueuedSeconds(), app.audio.QueueCapacitySeconds(), app.audio.DroppedSamples())
+	})
+	crash.AddState("transcriber busy", func() string {
+		return fmt.Sprintf("%v", app.transcriber.IsBusy())
+	})
+	crash.AddState("transcriber buffer", func() string {
+		return fmt.Sprintf("%.1fs", float64(app.transcriber.BufferedSamples())/16000)
+	})
+	crash.AddState("model", func() string {
+		switch config.Current.TranscriptionBackend {
+		case transcription.BackendServer:
+			return "whisper-server at " + config.Current.WhisperServerURL
+		case transcription.BackendFasterWhisper, transcription.BackendVosk:
+			return config.Current.TranscriptionBackend + ": " + sidecarCommand(config.Current.TranscriptionBackend)
+		case transcription.BackendWindowsSpeech:
+			return "Windows speech recognition"
+		}
+		return transcription.GetLocalModelPath(app.model)
+	})
+	crash.OnCrash(app.ui.SaveSession)
+	crash.OnCrash(stopTempFiles)
+	app.ui.SetQuitCallback(stopTempFiles)
+
+	// Allow archived recordings to be transcribed again with another model
+	app.ui.SetRetranscribeCallback(func(audioPath, modelSize string) (string, error) {
+		text, err := retranscribe(audioPath, modelSize)
+		return app.processText(text), err
+	})
+
+	// Transcribe audio files in their own session while the microphone stays usable
+	app.files = newFileQueue(func() string {
+		return app.ui.GetPreferences().ModelSize
+	})
+	app.files.SetProgressCallback(app.ui.SetFileProgress)
+	app.ui.SetTranscribeFileCallback(func(path string, progress func(float64)) ([]session.Segment, error) {
+		segments, err := transcribeFile(app.files, nil, path, progress)
+		for i := range segments {
+			segments[i].Text = app.processText(segments[i].Text)
+		}
+		return segments, err
+	})
+
+	// Rewrite each finalized segment with a more accurate model, clean up
+	// its punctuation and run the user's hooks on it if enabled
+	app.cleanups = make(chan func(), 64)
+	crash.Go(app.runCleanups)
+	app.configureRewrite()
+	app.configureCleanup()
+	app.configureHooks()
+	app.ui.SetRewriteCallbacks(app.takeSegmentAudio, app.rewrite)
+	app.ui.SetSpeechCallback(app.takeSegmentSpeech)
+
+	// Tag segments in which watched keywords are heard
+	app.configureKeywords()
+	app.ui.SetKeywordMatcher(func(text string) []string {
+		app.mu.Lock()
+		spotter := app.spotter
+		app.mu.Unlock()
+		return spotter.Match(text)
+	})
+
+	// Run voice commands for phrases heard in command mode
+	app.configureCommands()
+	app.ui.SetCommandCallback(app.runCommand)
+
+	// Switch between presets from the tray menu and shortcuts, starting in
+	// command mode if the active preset uses it
+	app.ui.SetPresets(presetNames(), config.Current.ActivePreset, app.applyPreset)
+	if preset, ok := config.Current.FindPreset(config.Current.ActivePreset); ok {
+		app.ui.SetCommandMode(preset.CommandMode)
+	}
+
+	// Summarize sessions with the configured language model
+	app.ui.SetSummarizeCallback(summarize)
+	app.ui.SetReadAloudCallback(readAloud)
+
+	// Free memory if the app sits unused
+	app.resetIdleTimer()
+
+	// Mask sensitive text according to the redaction settings
+	app.configureRedaction()
+
+	// Actions on text selected in the transcript
+	app.corrector = correctorFromConfig()
+	app.transcriber.SetVocabulary(config.Current.Vocabulary)
+	app.transcriber.SetLanguage(config.Current.Language)
+	app.transcriber.SetKeepContext(config.Current.KeepContext)
+	app.ui.SetSelectionCallbacks(app.addVocabulary, app.addCorrection, sendToWebhook)
+
+	// Send finalized segments to the configured outputs, webhooks, notes and MQTT broker
+	app.configureWebhooks()
+	app.configureMQTT()
+	app.configureOutputs()
+	app.ui.SetSegmentFinalizedCallback(app.sendSegment)
+
 	// Set up transcript callback
 	app.transcriber.SetStreamingCallback(func(text string) {
-		// Normalize text before displaying
-		normalizedText := transcription.NormalizeTranscriptionText(text)
+		received := time.Now()
+
+		// Normalize text before displaying, capitalizing it only where it
+		// starts the segment or a sentence
+		normalizedText := app.processText(transcription.NormalizeLiveText(text, app.ui.PendingText(), app.liveLanguage()))
 		if normalizedText != "" {
 			// Use the new session accumulation method to build session text
 			app.ui.AppendSessionText(normalizedText)
+			app.tracer.Delivered(received, len(normalizedText))
 
 			// Store the text for later
 			app.appendToFullText(normalizedText)
 
+			app.sendPartial(normalizedText)
+
 			// The finalization only happens when recording stops, not on a timer
 			// So we don't need to reset a timer here
 		}
 	})
 
+	// Tag each segment with the language it was spoken in, when detecting it
+	app.transcriber.SetLanguageCallback(func(language string) {
+		app.mu.Lock()
+		app.spoken = language
+		app.mu.Unlock()
+		app.ui.NoteSpokenLanguage(language)
+	})
+
+	// Highlight words in the live preview at the pace they were spoken, shown
+	// as they would be after corrections and redaction
+	app.transcriber.SetWordCallback(func(words []transcription.Word) {
+		raw := make([]string, len(words))
+		for i, word := range words {
+			raw[i] = word.Text
+		}
+		text := app.processText(transcription.NormalizeTranscriptionTextIn(strings.Join(raw, " "), app.liveLanguage()))
+		app.ui.ShowSpokenWords(transcription.RetimeWords(words, text))
+	})
+
 	return app, nil
 }
 
 // Run starts the application
 func (a *App) Run() {
+	// Offer crash reports from previous runs for review
+	a.showCrashReports()
+
 	// Run the UI
 	a.ui.Run()
 }
 
-// startRecording begins audio capture and transcription
+// serveMetrics serves Prometheus metrics if an address was configured
+func (a *App) serveMetrics() {
+	address := config.Current.MetricsAddress
+	if metricsAddress != "" {
+		address = metricsAddress
+	}
+	if address == "" {
+		return
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", a.metrics.registry)
+	crash.Go(func() {
+		logger.Info(logger.CategoryApp, "Serving metrics at http://%s/metrics", address)
+		if err := http.ListenAndServe(address, mux); err != nil {
+			logger.Error(logger.CategoryApp, "Metrics server stopped: %v", err)
+		}
+	})
+}
+
+// Show shows the application when the UI event loop is already running
+func (a *App) Show() {
+	a.showCrashReports()
+	a.ui.Show()
+}
+
+// startRecording begins audio capture and transcription, first reloading the
+// model and audio system if they were released while idle
 func (a *App) startRecording() {
+	a.mu.Lock()
+	a.stopIdleTimer()
+	if a.warmingUp {
+		a.mu.Unlock()
+		return
+	}
+	if a.calibrating != nil {
+		a.mu.Unlock()
+		a.frontend.ShowTemporaryStatus("Close the microphone calibration to record", 3*time.Second)
+		return
+	}
+	if a.transcriber.IsLoaded() && !a.audio.IsSuspended() {
+		a.mu.Unlock()
+		a.beginRecording()
+		return
+	}
+	a.warmingUp = true
+	a.mu.Unlock()
+
+	// Loading the model takes a moment, so do it off the UI thread
+	a.frontend.SetState(ui.StateWarmingUp)
+	crash.Go(func() {
+		started := time.Now()
+		err := a.transcriber.Load()
+		a.metrics.modelLoad.Set(time.Since(started).Seconds())
+
+		a.mu.Lock()
+		a.warmingUp = false
+		a.mu.Unlock()
+
+		if err != nil {
+			logger.Error(logger.CategoryTranscription, "Failed to reload model: %v", err)
+			a.frontend.ShowErrorDialog("Error", fmt.Sprintf("Failed to load the transcription model: %v", err))
+			a.frontend.SetState(ui.StateIdle)
+			a.resetIdleTimer()
+			return
+		}
+
+		logger.Info(logger.CategoryApp, "Warmed up in %v", time.Since(started).Round(time.Millisecond))
+		a.ui.SetResourcesReleased(false)
+		a.beginRecording()
+	})
+}
+
+// beginRecording starts capture and transcription once resources are loaded
+func (a *App) beginRecording() {
 	// Reset the transcript for a new recording
 	a.mu.Lock()
 	a.fullText = ""
 	a.mu.Unlock()
 
 	// Clear the UI for the new recording session
-	a.ui.UpdateTranscript("")
-	a.ui.UpdateStreamingPreview("")
+	a.frontend.UpdateTranscript("")
+	a.frontend.UpdateStreamingPreview("")
 
 	// Start the transcriber
 	a.transcriber.SetRecordingState(true)
-	a.ui.ShowTemporaryStatus("Starting recording...", 2*time.Second)
+	a.ui.BeginTranscriptionSegment()
+	a.frontend.ShowTemporaryStatus("Starting recording...", 2*time.Second)
 
-	// Start audio capture with callback
-	err := a.audio.Start(func(samples []float32) {
-		// Calculate audio level for visualization
-		level := audio.CalculateLevel(samples)
-		a.ui.UpdateAudioLevel(level)
+	// Archive the raw recording if enabled. Incognito recordings are never
+	// written to disk, so there is nothing to scrub afterwards.
+	a.mu.Lock()
+	archiver := a.archiver
+	a.mu.Unlock()
+	if !a.privacy.Allows(output.KindArchive) {
+		archiver = nil
+	}
+	if archiver != nil {
+		if err := archiver.Begin(); err != nil {
+			logger.Warning(logger.CategoryAudio, "Not archiving this recording: %v", err)
+			archiver = nil
+		}
+	}
+	a.mu.Lock()
+	a.recording = archiver
+	a.segmentAudio, a.utteranceEnd, a.segmentTooLong = nil, 0, false
+	a.segmentSpeech, a.nextSpeech, a.pauseMarked = 0, 0, false
+	a.mu.Unlock()
 
-		// Process audio through transcriber
-		_, err := a.transcriber.ProcessAudioChunk(samples)
-		if err != nil {
-			logger.Error(logger.CategoryTranscription, "Error processing audio: %v", err)
+	// Start audio capture; only the level meter, the spectrum and the archive queue run in
+	// the PortAudio callback, samples are drained from the capture queue by the consumer goroutine
+	spectrum := audio.NewSpectrumAnalyzer(audio.TargetSampleRate, audio.SpectrumBands)
+	a.tracer.Begin()
+	err := a.audio.Start(func(samples []float32) {
+		a.tracer.Captured(len(samples))
+		a.frontend.UpdateAudioLevel(audio.CalculateLevel(samples))
+		if a.ui.SpectrogramShown() {
+			a.ui.UpdateSpectrum(spectrum.Analyze(samples))
+		}
+		if archiver != nil {
+			archiver.Write(samples)
 		}
 	})
 
 	if err != nil {
 		logger.Error(logger.CategoryAudio, "Failed to start recording: %v", err)
-		a.ui.ShowTemporaryStatus(fmt.Sprintf("Error: %v", err), 3*time.Second)
+		a.frontend.ShowTemporaryStatus(fmt.Sprintf("Error: %v", err), 3*time.Second)
 		a.stopRecording()
 		return
 	}
 
-	a.ui.SetState(ui.StateListening)
+	a.mu.Lock()
+	a.started = time.Now()
+	a.stopAudio = make(chan struct{})
+	a.audioDone = make(chan struct{})
+	stop, done := a.stopAudio, a.audioDone
+	crash.Go(func() {
+		defer close(done)
+		a.consumeAudio(stop)
+	})
+	a.mu.Unlock()
+
+	a.frontend.SetState(ui.StateListening)
 }
 
-// stopRecording ends audio capture and transcription
-func (a *App) stopRecording() {
-	// Stop audio capture
-	if a.audio != nil && a.audio.IsActive() {
-		if err := a.audio.Stop(); err != nil {
-			logger.Error(logger.CategoryAudio, "Error stopping audio: %v", err)
+// consumeAudio reads captured audio and feeds it to the transcriber until stop is closed,
+// then hands it what is still queued so the end of the recording can be drained.
+// While whisper is busy the audio stays in the bounded capture queue, which drops
+// the oldest audio when full, and the UI is told that transcription is lagging.
+// If a pause length is configured, each pause finalizes the segment so far,
+// and long segments are split once they reach the configured rollover limits.
+func (a *App) consumeAudio(stop <-chan struct{}) {
+	// Reused for every read so the capture path doesn't allocate
+	samples := make([]float32, 1024)
+	lagging := false
+	lastDropped := a.audio.DroppedSamples()
+
+	// Pauses also end phrases in command mode, where each phrase is one command
+	pause := commandPause
+	if seconds := config.Current.UtteranceSilenceSeconds; seconds > 0 {
+		pause = time.Duration(seconds * float64(time.Second))
+	}
+	utterances := audio.NewUtteranceDetector(16000, pause)
+	utteranceEnded := false
+
+	// Stop a recording left running in silence, except one read from stdin,
+	// which ends with its input
+	var silence *audio.SilenceDetector
+	autoStop := config.Current.AutoStopSilenceMinutes
+	if autoStop > 0 && pipeInput == nil {
+		silence = audio.NewSilenceDetector(16000, time.Duration(autoStop)*time.Minute)
+	}
+
+	// Hand the whole queue to the transcriber. Pauses don't matter once
+	// stopped, since the rest of the recording ends its last segment.
+	feed := func(stopped bool) {
+		for {
+			n := a.audio.Read(samples)
+			if n == 0 {
+				return
+			}
+			a.keepSegmentAudio(samples[:n])
+			a.countSpeech(samples[:n])
+			if !stopped && utterances.Process(samples[:n]) && (config.Current.UtteranceSilenceSeconds > 0 || a.ui.CommandMode()) {
+				logger.Debug(logger.CategoryTranscription, "End of utterance detected")
+				utteranceEnded = true
+				a.markUtteranceEnd()
+			}
+			if !stopped && silence != nil && silence.Process(samples[:n]) {
+				logger.Info(logger.CategoryAudio, "No speech for %d minutes; stopping the recording", autoStop)
+				// Stopping waits for this goroutine to finish
+				crash.Go(func() { a.ui.StopForSilence(autoStop) })
+			}
+			a.transcriber.AppendAudio(samples[:n])
+			a.tracer.Dequeued(n)
+			a.metrics.chunks.Inc()
 		}
 	}
 
-	// Stop transcriber
-	if a.transcriber != nil {
-		a.transcriber.SetRecordingState(false)
+	for {
+		select {
+		case <-stop:
+			if lagging {
+				a.ui.SetTranscriptionLagging(false)
+			}
+			feed(true)
+			return
+		case <-a.audio.Ready():
+		}
+
+		// Lagging once the queue is half full or audio has been dropped
+		dropped := a.audio.DroppedSamples()
+		isLagging := dropped > lastDropped ||
+			a.audio.QueuedSeconds() > a.audio.QueueCapacitySeconds()/2
+		if dropped > lastDropped {
+			logger.Warning(logger.CategoryTranscription,
+				"Transcription lagging: dropped %.1fs of audio", float64(dropped-lastDropped)/16000)
+			a.metrics.dropped.Add(float64(dropped-lastDropped) / 16000)
+			a.tracer.Dropped(int(dropped - lastDropped))
+			lastDropped = dropped
+		}
+		if isLagging != lagging {
+			lagging = isLagging
+			a.ui.SetTranscriptionLagging(lagging)
+		}
+
+		// Apply backpressure: leave audio queued until whisper is ready for more
+		if a.transcriber.IsBusy() {
+			continue
+		}
+
+		// Whisper has caught up with the utterance, so its text is complete
+		if utteranceEnded {
+			utteranceEnded = false
+			a.transcriber.EndUtterance()
+			a.ui.FinalizeTranscriptionSegment()
+		}
+
+		// Bound the text and audio held for a single segment, with limits
+		// changed in Preferences applying to the recording in progress
+		rollover := session.Rollover{
+			MaxAge:   time.Duration(config.Current.RolloverMinutes) * time.Minute,
+			MaxChars: config.Current.RolloverCharacters,
+		}
+		if chars, started := a.ui.PendingSegment(); rollover.Enabled() && rollover.Due(started, chars, time.Now()) {
+			a.rollSegment()
+		}
+
+		// Hand the whole queue to the transcriber before starting a pass, so
+		// a backlog is transcribed in order rather than behind the next pass
+		feed(false)
+		if _, err := a.transcriber.ProcessAudioChunk(nil); err != nil {
+			logger.Error(logger.CategoryTranscription, "Error processing audio: %v", err)
+		}
 	}
+}
 
-	// Finalize current session
-	a.ui.FinalizeTranscriptionSegment()
+// keepSegmentAudio keeps audio passed to the transcriber so the segment can
+// be rewritten once it is finalized
+func (a *App) keepSegmentAudio(samples []float32) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
 
-	a.ui.SetState(ui.StateIdle)
+	if a.rewriter == nil || a.segmentTooLong {
+		return
+	}
+	if len(a.segmentAudio)+len(samples) > maxRewriteSamples {
+		logger.Info(logger.CategoryTranscription, "Segment too long to rewrite; keeping its live text")
+		a.segmentAudio, a.utteranceEnd, a.segmentTooLong = nil, 0, true
+		return
+	}
+	a.segmentAudio = append(a.segmentAudio, samples...)
 }
 
-// appendToFullText adds text to the complete transcript
-func (a *App) appendToFullText(text string) {
+// markUtteranceEnd notes where the speaker paused. The segment finalized for
+// the pause ends there; audio after it starts the next segment.
+func (a *App) markUtteranceEnd() {
 	a.mu.Lock()
 	defer a.mu.Unlock()
+	a.utteranceEnd = len(a.segmentAudio)
+	a.segmentSpeech += a.nextSpeech
+	a.nextSpeech, a.pauseMarked = 0, true
+}
+
+// countSpeech adds the audio passed to the transcriber to the speech heard in
+// the segment if it is loud enough to be speech
+func (a *App) countSpeech(samples []float32) {
+	if audio.CalculateLevel(samples) < audio.SpeechLevel {
+		return
+	}
+	heard := time.Duration(len(samples)) * time.Second / 16000
 
-	if a.fullText != "" {
-		a.fullText += " "
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.pauseMarked {
+		a.nextSpeech += heard
+	} else {
+		a.segmentSpeech += heard
 	}
-	a.fullText += text
 }
 
-// Close performs cleanup
-func (a *App) Close() {
-	a.stopRecording()
+// takeSegmentSpeech returns the speech heard in the segment being finalized,
+// up to the last detected pause
+func (a *App) takeSegmentSpeech() time.Duration {
+	a.mu.Lock()
+	defer a.mu.Unlock()
 
-	if a.transcriber != nil {
-		a.transcriber.Close()
+	speech := a.segmentSpeech
+	a.segmentSpeech, a.nextSpeech, a.pauseMarked = a.nextSpeech, 0, false
+	return speech
+}
+
+// takeSegmentAudio returns the audio of the segment being finalized, or nil
+// if it won't be rewritten
+func (a *App) takeSegmentAudio() []float32 {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.segmentTooLong {
+		a.segmentTooLong = false
+		return nil
+	}
+	end := a.utteranceEnd
+	if end == 0 {
+		end = len(a.segmentAudio)
+	}
+	samples := a.segmentAudio[:end:end]
+	a.segmentAudio = append([]float32(nil), a.segmentAudio[end:]...)
+	a.utteranceEnd = 0
+	return samples
+}
+
+// rewrite improves a finalized segment of the session with the given ID in
+// the background: its audio is transcribed again with the rewrite model, if
+// enabled, and the text is cleaned up and run through the hooks, if enabled,
+// before being passed to apply
+func (a *App) rewrite(sessionID string, segment session.Segment, samples []float32, apply func(text string)) {
+	a.mu.Lock()
+	rewriter, cleaner, runner := a.rewriter, a.cleaner, a.hooks
+	a.mu.Unlock()
+	if !a.privacy.Allows(output.KindHook) {
+		runner = nil
+	}
+	text := segment.Text
+	finish := func(text string) string {
+		return a.runHooks(runner, sessionID, segment, a.cleanup(cleaner, text))
 	}
 
-	if a.audio != nil {
-		a.audio.Close()
+	if rewriter != nil && len(samples) > 0 {
+		queued := rewriter.Enqueue(samples, func(rewritten string, err error) {
+			if err != nil {
+				if !errors.Is(err, transcription.ErrRewriterClosed) {
+					logger.Warning(logger.CategoryTranscription, "Failed to rewrite segment: %v", err)
+				}
+				rewritten = text
+			} else {
+				rewritten = a.processText(rewritten)
+			}
+			apply(finish(rewritten))
+		})
+		if queued {
+			return
+		}
+		logger.Warning(logger.CategoryTranscription, "Rewrite queue full; keeping the live text")
+	}
+
+	if cleaner != nil || runner.Enabled() {
+		select {
+		case a.cleanups <- func() { apply(finish(text)) }:
+		default:
+			logger.Warning(logger.CategoryTranscription, "Cleanup queue full; keeping the segment as transcribed")
+		}
 	}
 }
 
-/*
-// Comment out the loadEmbeddedModel function until we actually implement embedded models
-func loadEmbeddedModel() ([]byte, error) {
-	// Logic to potentially write embeddedModel to a temporary file
-	// if the whisper library needs a file path, or use a memory-based
-	// loading function if the library supports it.
-	// For ggml libraries, often a file path is needed.
-	tmpDir := os.TempDir()
-	tmpFile := filepath.Join(tmpDir, "ramble-embedded-model.bin")
-	err := os.WriteFile(tmpFile, embeddedModel, 0644)
+// runHooks returns text as changed by the hooks of runner, or text unchanged
+// if runner is nil
+func (a *App) runHooks(runner *hooks.Runner, sessionID string, segment session.Segment, text string) string {
+	if !runner.Enabled() {
+		return text
+	}
+	changed, err := runner.Run(text, sessionID, segment)
 	if err != nil {
-		return nil, fmt.Errorf("failed to write embedded model to temp file: %w", err)
+		logger.Warning(logger.CategoryTranscription, "Hook failed on segment %d: %v", segment.ID, err)
+		a.frontend.ShowTemporaryStatus("A hook failed, see the log", 3*time.Second)
 	}
-	// Return the path to the temp file
-	// Remember to clean up this file on exit
-	return []byte(tmpFile), nil // Returning path as bytes for example
+	return changed
 }
-*/
 
-func main() {
-	// Parse command line flags
-	debug := flag.Bool("debug", false, "Enable debug output")
-	flag.Parse()
+// cleanup returns text cleaned up by cleaner, or text unchanged if cleaner is nil
+func (a *App) cleanup(cleaner *textproc.Cleaner, text string) string {
+	if cleaner == nil {
+		return text
+	}
+	cleaned, err := cleaner.Clean(text)
+	if err != nil {
+		logger.Warning(logger.CategoryTranscription, "Using the built-in cleanup: %v", err)
+	}
+	return cleaned
+}
 
-	// Configure logger based on debug flag
-	if *debug {
-		logger.SetLevel(logger.LevelDebug)
+// runCleanups cleans up finalized segments one at a time, so a slow cleanup
+// command doesn't hold up transcription
+func (a *App) runCleanups() {
+	for cleanup := range a.cleanups {
+		cleanup()
 	}
-	logger.Info(logger.CategoryApp, "Starting Ramble - Speech to Text")
+}
 
-	// Create and run the application
-	app, err := New(*debug)
+// configureKeywords sets the keywords watched for in finalized segments
+func (a *App) configureKeywords() {
+	spotter, err := textproc.NewSpotter(config.Current.WatchKeywords)
 	if err != nil {
-		logger.Error(logger.CategoryApp, "Failed to initialize application: %v", err)
-		os.Exit(1)
+		logger.Error(logger.CategoryApp, "Keyword watching disabled: %v", err)
+		a.frontend.ShowTemporaryStatus("Invalid keyword pattern, see the log", 3*time.Second)
 	}
+	a.mu.Lock()
+	a.spotter = spotter
+	a.mu.Unlock()
+}
 
-	// Handle termination signals
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
-	go func() {
-		<-sigChan
-		logger.Info(logger.CategoryApp, "Shutting down...")
-		app.Close()
-		os.Exit(0)
-	}()
+// configureCommands sets the voice commands run in command mode
+func (a *App) configureCommands() {
+	list, err := commands.Parse(strings.Join(config.Current.VoiceCommands, "\n"))
+	if err != nil {
+		logger.Error(logger.CategoryApp, "Voice commands disabled: %v", err)
+		a.frontend.ShowTemporaryStatus("Invalid voice command, see the log", 3*time.Second)
+	}
+	a.mu.Lock()
+	a.dispatcher = commands.NewDispatcher(list)
+	a.mu.Unlock()
+}
 
-	// Run the application
-	app.Run()
+// runCommand runs the voice command for a phrase heard in command mode and
+// describes what it ran
+func (a *App) runCommand(text string) (string, error) {
+	a.mu.Lock()
+	dispatcher := a.dispatcher
+	a.mu.Unlock()
+
+	c, err := dispatcher.Run(text)
+	if errors.Is(err, commands.ErrNoCommand) {
+		return "", fmt.Errorf("no command for %q", strings.TrimSpace(text))
+	}
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%q", c.Phrase), nil
+}
+
+// configureCleanup turns the punctuation and casing cleanup of finalized
+// segments on or off
+func (a *App) configureCleanup() {
+	var cleaner *textproc.Cleaner
+	if config.Current.CleanupSegments {
+		cleaner = textproc.NewCleaner(strings.Fields(config.Current.CleanupCommand))
+	}
+	a.mu.Lock()
+	a.cleaner = cleaner
+	a.mu.Unlock()
+}
+
+// configureHooks sets the programs run on each finalized segment
+func (a *App) configureHooks() {
+	runner := hooks.NewRunner(config.Current.Hooks)
+	a.mu.Lock()
+	a.hooks = runner
+	a.mu.Unlock()
+}
+
+// configureRewrite starts or stops two-pass transcription, in which each
+// finalized segment is transcribed again with the configured rewrite model
+func (a *App) configureRewrite() {
+	model := transcription.ModelSize(config.Current.RewriteModel)
+	if model != "" && !usesLocalModel() {
+		logger.Warning(logger.CategoryTranscription, "Segments are only rewritten with the built-in whisper backend")
+		model = ""
+	}
+	if model != "" && transcription.GetLocalModelPath(model) == "" {
+		logger.Warning(logger.CategoryTranscription, "The %s model is not installed; segments won't be rewritten", model)
+		model = ""
+	}
+
+	a.mu.Lock()
+	if model == a.rewriteModel {
+		a.mu.Unlock()
+		return
+	}
+	old := a.rewriter
+	a.rewriter, a.rewriteModel = nil, model
+	if model != "" {
+		a.rewriter = transcription.NewRewriter(func() (transcription.Transcriber, error) {
+			transcriber, err := newTranscriber(model)
+			if err != nil {
+				return nil, err
+			}
+			transcriber.SetVocabulary(config.Current.Vocabulary)
+			transcriber.SetLanguage(config.Current.Language)
+			return transcriber, nil
+		})
+		logger.Info(logger.CategoryTranscription, "Rewriting finalized segments with the %s model", model)
+	}
+	a.mu.Unlock()
+
+	if old != nil {
+		crash.Go(func() { old.Close() })
+	}
+}
+
+// rollSegment finalizes the segment being recorded and continues recording
+// into a new segment and archive file
+func (a *App) rollSegment() {
+	a.transcriber.EndUtterance()
+
+	a.mu.Lock()
+	archiver := a.recording
+	a.fullText = ""
+	a.mu.Unlock()
+
+	audioPath := ""
+	if archiver != nil {
+		path, err := archiver.Rotate()
+		if err != nil {
+			logger.Warning(logger.CategoryAudio, "Failed to start a new audio archive: %v", err)
+		}
+		audioPath = path
+		a.pruneArchive(archiver)
+	}
+
+	logger.Info(logger.CategoryTranscription, "Segment reached its length limit; starting a new one")
+	a.ui.FinalizeTranscriptionSegmentWithAudio(audioPath)
+}
+
+// stopRecording ends audio capture and transcription
+func (a *App) stopRecording() {
+	// Stop audio capture
+	if a.audio != nil && a.audio.IsActive() {
+		if err := a.audio.Stop(); err != nil {
+			logger.Error(logger.CategoryAudio, "Error stopping audio: %v", err)
+		}
+	}
+
+	// Stop the audio consumer, which hands the transcriber what is still queued
+	a.mu.Lock()
+	if a.stopAudio != nil {
+		close(a.stopAudio)
+		a.stopAudio = nil
+	}
+	done := a.audioDone
+	a.audioDone = nil
+	a.mu.Unlock()
+	if done != nil {
+		<-done
+	}
+
+	// Transcribe the rest of the recording before finalizing it, so the
+	// last sentence isn't lost
+	if a.transcriber != nil {
+		started := time.Now()
+		if err := a.transcriber.Drain(drainTimeout); err != nil {
+			logger.Warning(logger.CategoryTranscription, "Stopped without the end of the recording: %v", err)
+		} else {
+			logger.Debug(logger.CategoryTranscription, "Drained the recording in %v", time.Since(started).Round(time.Millisecond))
+		}
+	}
+
+	// Finish the archived recording so it can be linked to the segment
+	a.mu.Lock()
+	archiver := a.recording
+	a.recording = nil
+	started := a.started
+	a.started = time.Time{}
+	a.mu.Unlock()
+
+	if !started.IsZero() && a.analytics != nil {
+		if err := a.analytics.RecordSession(time.Since(started), string(a.model)); err != nil {
+			logger.Warning(logger.CategoryApp, "Failed to record usage statistics: %v", err)
+		}
+	}
+
+	audioPath := ""
+	if archiver != nil {
+		path, err := archiver.End()
+		if err != nil {
+			logger.Warning(logger.CategoryAudio, "Failed to finish audio archive: %v", err)
+		}
+		audioPath = path
+		a.pruneArchive(archiver)
+	}
+
+	// Finalize current session
+	a.ui.FinalizeTranscriptionSegmentWithAudio(audioPath)
+
+	a.frontend.SetState(ui.StateIdle)
+
+	// Start counting idle time from the end of the recording
+	a.resetIdleTimer()
+
+	// Keep the trace so far, in case Ramble doesn't exit cleanly
+	a.writeTrace()
+}
+
+// writeTrace writes the latency trace, if tracing
+func (a *App) writeTrace() {
+	if a.tracer == nil {
+		return
+	}
+	if err := a.tracer.WriteFile(traceFile); err != nil {
+		logger.Warning(logger.CategoryApp, "Failed to write latency trace: %v", err)
+	}
+}
+
+// configureRedaction sets up redaction of transcribed text for each destination
+// enabled in the config
+func (a *App) configureRedaction() {
+	redactor, err := textproc.NewRedactor(textproc.RedactOptions{
+		Profanity:    config.Current.RedactProfanity,
+		Emails:       config.Current.RedactEmails,
+		PhoneNumbers: config.Current.RedactPhoneNumbers,
+		CardNumbers:  config.Current.RedactCardNumbers,
+		Patterns:     config.Current.RedactPatterns,
+	})
+	if err != nil {
+		// Don't silently record text the user expects to be masked
+		logger.Error(logger.CategoryApp, "Redaction disabled: %v", err)
+		a.frontend.ShowErrorDialog("Redaction", fmt.Sprintf("Redaction is disabled because of a configuration error: %v", err))
+		redactor = nil
+	}
+
+	// Destinations that aren't redacted any more get nil, undoing earlier settings
+	var display, outgoing *textproc.Redactor
+	var clipboardFilter, savedFilter func(string) string
+	if redactor != nil && redactor.Enabled() {
+		if config.Current.RedactDisplay {
+			display = redactor
+		}
+		if config.Current.RedactClipboard {
+			outgoing = redactor
+			clipboardFilter = redactor.Redact
+		}
+		if config.Current.RedactSaved {
+			savedFilter = redactor.Redact
+		}
+	}
+
+	a.mu.Lock()
+	a.redactor = display
+	a.outgoing = outgoing
+	a.mu.Unlock()
+	if a.ui != nil {
+		a.ui.SetClipboardFilter(clipboardFilter)
+		a.ui.SetSavedTextFilter(savedFilter)
+	}
+}
+
+// redactOutgoing masks text sent outside the app like text copied to the clipboard
+func (a *App) redactOutgoing(text string) string {
+	a.mu.Lock()
+	outgoing := a.outgoing
+	a.mu.Unlock()
+
+	if outgoing == nil {
+		return text
+	}
+	return outgoing.Redact(text)
+}
+
+// processText applies correction rules and then redaction to transcribed text
+func (a *App) processText(text string) string {
+	a.mu.Lock()
+	corrector, redactor := a.corrector, a.redactor
+	a.mu.Unlock()
+
+	text = corrector.Correct(text)
+	if redactor != nil {
+		text = redactor.Redact(text)
+	}
+	return text
+}
+
+// addVocabulary adds a word or name for the model to recognize
+func (a *App) addVocabulary(word string) error {
+	word = strings.TrimSpace(word)
+	for _, existing := range config.Current.Vocabulary {
+		if strings.EqualFold(existing, word) {
+			return nil
+		}
+	}
+
+	config.Current.Vocabulary = append(config.Current.Vocabulary, word)
+	if err := config.SaveConfig(); err != nil {
+		return fmt.Errorf("failed to save vocabulary: %w", err)
+	}
+	a.transcriber.SetVocabulary(config.Current.Vocabulary)
+	return nil
+}
+
+// addCorrection adds a rule replacing from with to in future transcripts
+func (a *App) addCorrection(from, to string) error {
+	config.Current.Corrections = append(config.Current.Corrections, config.CorrectionRule{
+		From: strings.TrimSpace(from),
+		To:   to,
+	})
+	if err := config.SaveConfig(); err != nil {
+		return fmt.Errorf("failed to save correction rule: %w", err)
+	}
+
+	// Keep the Replacements tab of Preferences up to date
+	prefs := a.ui.GetPreferences()
+	prefs.Corrections = correctionLines(config.Current.Corrections)
+	a.ui.SetPreferences(prefs)
+
+	a.mu.Lock()
+	a.corrector = correctorFromConfig()
+	a.mu.Unlock()
+	return nil
+}
+
+// correctorFromConfig creates a corrector for the configured correction
+// rules. Corrections are turned off if a rule is invalid.
+func correctorFromConfig() *textproc.Corrector {
+	corrector, err := textproc.NewCorrector(configCorrections())
+	if err != nil {
+		logger.Error(logger.CategoryApp, "Replacements disabled: %v", err)
+	}
+	return corrector
+}
+
+// configCorrections returns the configured correction rules
+func configCorrections() []textproc.Correction {
+	rules := make([]textproc.Correction, len(config.Current.Corrections))
+	for i, rule := range config.Current.Corrections {
+		rules[i] = textproc.Correction{From: rule.From, To: rule.To, Regex: rule.Regex}
+	}
+	return rules
+}
+
+// correctionLines writes correction rules one per line, as edited in Preferences
+func correctionLines(rules []config.CorrectionRule) []string {
+	lines := make([]string, len(rules))
+	for i, rule := range rules {
+		lines[i] = textproc.FormatCorrections([]textproc.Correction{{From: rule.From, To: rule.To, Regex: rule.Regex}})
+	}
+	return lines
+}
+
+// sendToWebhook posts text to the configured webhook
+func sendToWebhook(text string) error {
+	if config.Current.WebhookURL == "" {
+		return fmt.Errorf("no webhook URL is set; add one in Preferences")
+	}
+	return webhook.Send(config.Current.WebhookURL, text)
+}
+
+// configureWebhooks sets up the webhooks notified of finalized segments
+func (a *App) configureWebhooks() {
+	notifier, err := webhook.NewNotifier(config.Current.SegmentWebhookURLs, config.Current.WebhookSecret)
+	if err != nil {
+		logger.Error(logger.CategoryApp, "Segment webhooks disabled: %v", err)
+		a.frontend.ShowErrorDialog("Webhooks", fmt.Sprintf("Segment webhooks are disabled because of an invalid URL: %v", err))
+	}
+
+	a.mu.Lock()
+	a.notifier = notifier
+	a.mu.Unlock()
+}
+
+// notifySegment posts a finalized segment to the configured webhooks in the background
+func (a *App) notifySegment(sessionID string, segment session.Segment) {
+	a.mu.Lock()
+	notifier := a.notifier
+	a.mu.Unlock()
+	if notifier == nil || !notifier.Enabled() {
+		return
+	}
+
+	payload := webhook.SegmentPayload{
+		SessionID: sessionID,
+		SegmentID: segment.ID,
+		Text:      segment.Text,
+		StartMs:   segment.Start.Milliseconds(),
+		EndMs:     segment.End.Milliseconds(),
+		Finalized: time.Now(),
+	}
+	crash.Go(func() {
+		if err := notifier.Notify(payload); err != nil {
+			logger.Warning(logger.CategoryApp, "Segment webhook failed: %v", err)
+		}
+	})
+}
+
+// configureMQTT connects to the MQTT broker in the config, replacing any
+// previous connection
+func (a *App) configureMQTT() {
+	var client *mqtt.Client
+	if config.Current.MQTTEnabled {
+		// Brokers drop an older connection with the same client ID
+		clientID := "ramble"
+		if host, err := os.Hostname(); err == nil {
+			clientID += "-" + host
+		}
+		if profile := config.ActiveProfile(); profile != "" {
+			clientID += "-" + profile
+		}
+
+		var err error
+		client, err = mqtt.NewClient(mqtt.Options{
+			Broker:      config.Current.MQTTBroker,
+			ClientID:    clientID,
+			Username:    config.Current.MQTTUsername,
+			Password:    config.Current.MQTTPassword,
+			TopicPrefix: config.Current.MQTTTopic,
+			OnError: func(err error) {
+				logger.Warning(logger.CategoryApp, "MQTT: %v", err)
+			},
+		})
+		if err != nil {
+			logger.Error(logger.CategoryApp, "MQTT publishing disabled: %v", err)
+		}
+	}
+
+	a.mu.Lock()
+	old := a.mqtt
+	a.mqtt = client
+	a.mu.Unlock()
+
+	if old != nil {
+		crash.Go(func() { old.Close() })
+	}
+}
+
+// mqttClient returns the MQTT client, or nil if publishing is disabled
+func (a *App) mqttClient() *mqtt.Client {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.mqtt
+}
+
+// outputConfigs converts the outputs in the config to sink configurations
+func outputConfigs(rules []config.OutputRule) []output.Config {
+	configs := make([]output.Config, len(rules))
+	for i, rule := range rules {
+		configs[i] = output.Config{Kind: output.Kind(rule.Type), Target: rule.Target, Format: rule.Format, Numbers: rule.Numbers}
+	}
+	return configs
+}
+
+// outputRules converts sink configurations back to config outputs
+func outputRules(configs []output.Config) []config.OutputRule {
+	rules := make([]config.OutputRule, len(configs))
+	for i, cfg := range configs {
+		rules[i] = config.OutputRule{Type: string(cfg.Kind), Target: cfg.Target, Format: cfg.Format, Numbers: cfg.Numbers}
+	}
+	return rules
+}
+
+// appRules converts the app rules in the config to router rules
+func appRules(rules []config.AppOutputRule) []output.AppRule {
+	converted := make([]output.AppRule, len(rules))
+	for i, rule := range rules {
+		converted[i] = output.AppRule(rule)
+	}
+	return converted
+}
+
+// appOutputRules converts router rules back to config app rules
+func appOutputRules(rules []output.AppRule) []config.AppOutputRule {
+	converted := make([]config.AppOutputRule, len(rules))
+	for i, rule := range rules {
+		converted[i] = config.AppOutputRule(rule)
+	}
+	return converted
+}
+
+// configureOutputs creates the outputs enabled in the config
+func (a *App) configureOutputs() {
+	numbers := textproc.NewNumberNormalizer(config.Current.NumberLocale)
+	router, err := output.NewRouter(outputConfigs(config.Current.Outputs), numbers.Normalize)
+	if err != nil {
+		logger.Error(logger.CategoryApp, "Some outputs are disabled: %v", err)
+		a.frontend.ShowErrorDialog("Outputs", fmt.Sprintf("Some outputs are disabled because they are not set up correctly: %v", err))
+	}
+	router.SetPrivacy(a.privacy)
+	router.SetAppRules(appRules(config.Current.AppOutputs), output.FocusedWindow)
+
+	a.mu.Lock()
+	a.outputs = router
+	a.mu.Unlock()
+}
+
+// writeOutputs sends a finalized segment to every enabled output in the background
+func (a *App) writeOutputs(sessionID string, segment session.Segment) {
+	a.mu.Lock()
+	router := a.outputs
+	a.mu.Unlock()
+	if !router.Enabled() {
+		return
+	}
+
+	event := output.Event{Text: segment.Text, SessionID: sessionID, SegmentID: segment.ID, Time: time.Now()}
+	crash.Go(func() {
+		if err := router.Write(event); err != nil {
+			logger.Warning(logger.CategoryApp, "Output failed: %v", err)
+			a.frontend.ShowTemporaryStatus(fmt.Sprintf("Output failed: %v", err), 3*time.Second)
+		}
+	})
+}
+
+// appendToNote adds a finished recording to the configured note in the background
+func (a *App) appendToNote(sessionID string, segment session.Segment) {
+	if !config.Current.NotesEnabled {
+		return
+	}
+
+	pathTemplate, template := config.Current.NotesPath, config.Current.NotesTemplate
+	entry := notes.Entry{Text: segment.Text, SessionID: sessionID, Time: time.Now()}
+	crash.Go(func() {
+		path, err := notes.Append(pathTemplate, template, entry)
+		if err != nil {
+			logger.Warning(logger.CategoryApp, "Failed to append to note: %v", err)
+			a.frontend.ShowTemporaryStatus(fmt.Sprintf("Failed to append to note: %v", err), 3*time.Second)
+			return
+		}
+		logger.Debug(logger.CategoryApp, "Appended recording to %s", path)
+	})
+}
+
+// sendSegment sends a finalized segment to the outputs, webhooks, notes and
+// MQTT broker. While incognito, the outputs skip those that keep text by
+// themselves.
+func (a *App) sendSegment(sessionID string, segment session.Segment) {
+	a.writeOutputs(sessionID, segment)
+	if a.privacy.Allows(output.KindWebhook) {
+		a.notifySegment(sessionID, segment)
+	}
+	if a.privacy.Allows(output.KindNote) {
+		a.appendToNote(sessionID, segment)
+	}
+	if client := a.mqttClient(); client != nil && a.privacy.Allows(output.KindMQTT) {
+		client.PublishFinal(sessionID, segment.ID, segment.Text)
+	}
+	jsonEvents.Final(sessionID, segment.ID, segment.Text, segment.Language)
+}
+
+// sendPartial publishes text transcribed live, redacted like text copied to
+// the clipboard
+func (a *App) sendPartial(text string) {
+	if client := a.mqttClient(); client != nil && config.Current.MQTTPublishPartial && a.privacy.Allows(output.KindMQTT) {
+		client.PublishPartial(a.redactOutgoing(text))
+	}
+	jsonEvents.Partial(a.redactOutgoing(text))
+}
+
+// inputGain returns the gain saved for an input device, or 1 if none is saved
+func inputGain(device string) float64 {
+	if gain, ok := config.Current.InputGains[device]; ok && gain > 0 {
+		return gain
+	}
+	return 1
+}
+
+// onDeviceEvent pauses the recording while the microphone is unplugged and
+// resumes it once capture is back on the same or the default device
+func (a *App) onDeviceEvent(event audio.DeviceEvent) {
+	a.mu.Lock()
+	recording := a.stopAudio != nil
+	a.mu.Unlock()
+	if !recording {
+		return
+	}
+
+	switch event.Kind {
+	case audio.DeviceLost:
+		logger.Warning(logger.CategoryAudio, "Microphone %q disconnected; waiting for it to return", event.Device)
+		a.frontend.SetState(ui.StateReconnecting)
+		a.frontend.ShowTemporaryStatus("Microphone disconnected, waiting for it to return", 3*time.Second)
+	case audio.DeviceReconnected:
+		a.metrics.reconnect.Inc()
+		// Keep what was said before the interruption as its own segment
+		a.transcriber.EndUtterance()
+		a.ui.FinalizeTranscriptionSegment()
+		a.audio.SetGain(inputGain(event.Device))
+		a.frontend.SetState(ui.StateListening)
+		a.frontend.ShowTemporaryStatus("Recording resumed on "+event.Device, 3*time.Second)
+	}
+}
+
+// recordPipe records the audio piped to stdin with the -stdin flag from the
+// start, and stops the recording when the input ends so the last segment is
+// finalized. It does nothing without the flag.
+func (a *App) recordPipe() {
+	if pipeInput == nil {
+		return
+	}
+	crash.Go(func() {
+		a.ui.SetListening(true)
+		<-pipeInput.Ended()
+		if err := pipeInput.Err(); err != nil {
+			logger.Error(logger.CategoryAudio, "Failed to read audio from stdin: %v", err)
+		} else {
+			logger.Info(logger.CategoryAudio, "Audio from stdin ended")
+		}
+		a.ui.SetListening(false)
+		a.frontend.ShowTemporaryStatus("Audio input ended", 5*time.Second)
+	})
+}
+
+// startCalibration captures audio without transcribing it and reports the
+// input levels to onReading until stopCalibration is called
+func (a *App) startCalibration(onReading func(audio.Reading)) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.calibrating != nil {
+		return fmt.Errorf("calibration is already running")
+	}
+	if a.audio.IsActive() || a.warmingUp {
+		return fmt.Errorf("stop recording first")
+	}
+
+	meter := audio.NewLevelMeter(16000, 3*time.Second)
+	if err := a.audio.Start(meter.Add); err != nil {
+		return err
+	}
+	a.stopIdleTimer()
+
+	stop := make(chan struct{})
+	a.calibrating = stop
+	crash.Go(func() {
+		ticker := time.NewTicker(100 * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				onReading(meter.Reading())
+			}
+		}
+	})
+	return nil
+}
+
+// stopCalibration ends calibration and restores the saved gain, which changes
+// only once the preferences are saved
+func (a *App) stopCalibration() {
+	a.mu.Lock()
+	stop := a.calibrating
+	a.calibrating = nil
+	a.mu.Unlock()
+	if stop == nil {
+		return
+	}
+
+	close(stop)
+	if err := a.audio.Stop(); err != nil {
+		logger.Error(logger.CategoryAudio, "Error stopping calibration: %v", err)
+	}
+	a.audio.SetGain(inputGain(a.audio.DeviceName()))
+	a.resetIdleTimer()
+}
+
+// resetIdleTimer schedules the release of idle resources after the configured delay
+func (a *App) resetIdleTimer() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.stopIdleTimer()
+
+	minutes := config.Current.IdleReleaseMinutes
+	if minutes <= 0 {
+		return
+	}
+	a.idleTimer = time.AfterFunc(time.Duration(minutes)*time.Minute, a.releaseResources)
+}
+
+// stopIdleTimer cancels a pending idle release. The caller must hold a.mu.
+func (a *App) stopIdleTimer() {
+	if a.idleTimer != nil {
+		a.idleTimer.Stop()
+		a.idleTimer = nil
+	}
+}
+
+// releaseResources unloads the whisper model and releases the audio system
+// after a period of inactivity. They are reloaded on the next recording.
+func (a *App) releaseResources() {
+	// Holding a.mu keeps startRecording from racing the release
+	a.mu.Lock()
+	if a.idleTimer == nil || a.warmingUp || a.audio.IsActive() {
+		// Cancelled or recording started in the meantime
+		a.mu.Unlock()
+		return
+	}
+	a.idleTimer = nil
+
+	if err := a.transcriber.Unload(); err != nil {
+		logger.Warning(logger.CategoryTranscription, "Failed to unload idle model: %v", err)
+	}
+	if err := a.audio.Suspend(); err != nil {
+		logger.Warning(logger.CategoryAudio, "Failed to release idle audio system: %v", err)
+	}
+	a.mu.Unlock()
+
+	a.ui.SetResourcesReleased(true)
+	logger.Info(logger.CategoryApp, "Released model and audio after %d idle minutes",
+		config.Current.IdleReleaseMinutes)
+}
+
+// showCrashReports shows unreviewed crash reports one after another
+func (a *App) showCrashReports() {
+	reports, err := crash.Pending()
+	if err != nil {
+		logger.Warning(logger.CategoryApp, "Failed to check for crash reports: %v", err)
+		return
+	}
+	if len(reports) == 0 {
+		return
+	}
+
+	path := reports[0]
+	data, err := os.ReadFile(path)
+	if err != nil {
+		logger.Warning(logger.CategoryApp, "Failed to read crash report: %v", err)
+		return
+	}
+
+	a.ui.ShowCrashReport(string(data), func() {
+		if err := crash.MarkReviewed(path); err != nil {
+			logger.Warning(logger.CategoryApp, "%v", err)
+			return
+		}
+		a.showCrashReports()
+	})
+}
+
+// applyPreferences stores changed preferences in the config file
+func (a *App) applyPreferences(prefs ui.Preferences) {
+	config.Current.ArchiveAudio = prefs.ArchiveAudio
+	config.Current.ArchiveMaxDays = prefs.ArchiveMaxDays
+	config.Current.ArchiveMaxSizeMB = prefs.ArchiveMaxSizeMB
+	config.Current.ArchiveFormat = prefs.ArchiveFormat
+	config.Current.RelaunchAfterCrash = prefs.RelaunchOnCrash
+	config.Current.IdleReleaseMinutes = prefs.IdleReleaseMinutes
+	config.Current.StartMinimized = prefs.StartMinimized
+	config.Current.UtteranceSilenceSeconds = prefs.UtteranceSilenceSeconds
+	if prefs.LatencyProfile != config.Current.LatencyProfile {
+		// A latency profile chosen by hand picks its own model again
+		config.Current.LiveModel = ""
+	}
+	config.Current.LatencyProfile = prefs.LatencyProfile
+	config.Current.Language = prefs.Language
+	config.Current.RewriteModel = prefs.RewriteModel
+	config.Current.CleanupSegments = prefs.CleanupSegments
+	config.Current.CleanupCommand = prefs.CleanupCommand
+	config.Current.TranscriptionBackend = prefs.TranscriptionBackend
+	config.Current.WhisperServerURL = prefs.WhisperServerURL
+	config.Current.FasterWhisperCommand = prefs.FasterWhisperCommand
+	config.Current.VoskCommand = prefs.VoskCommand
+	config.Current.Decoding = make(map[string]config.DecodingConfig)
+	for backend, d := range prefs.Decoding {
+		config.Current.Decoding[backend] = config.DecodingConfig(d)
+	}
+	config.Current.SuppressHallucinations = prefs.SuppressHallucinations
+	config.Current.RolloverMinutes = prefs.RolloverMinutes
+	config.Current.RolloverCharacters = prefs.RolloverCharacters
+	config.Current.AutoStopSilenceMinutes = prefs.AutoStopSilenceMinutes
+	config.Current.KeepContext = prefs.KeepContext
+	if config.Current.InputGains == nil {
+		config.Current.InputGains = make(map[string]float64)
+	}
+	config.Current.InputGains[prefs.InputDevice] = prefs.InputGain
+	if prefs.SampleRate > 0 {
+		config.Current.AudioSampleRate = int(prefs.SampleRate)
+	}
+	config.Current.AudioBackend = prefs.AudioBackend
+	config.Current.AudioFallbackToDefault = prefs.FallbackDevice
+	config.Current.UILanguage = prefs.UILanguage
+	config.Current.Visualization = prefs.Visualization
+	config.Current.TranscriptFontSize = prefs.TranscriptFontSize
+	config.Current.TranscriptFont = prefs.TranscriptFont
+	config.Current.TranscriptLineSpacing = prefs.TranscriptLineSpacing
+	config.Current.HoverOnTop = prefs.HoverOnTop
+	config.Current.HoverWidth = prefs.HoverWidth
+	config.Current.HoverHeight = prefs.HoverHeight
+	config.Current.HoverOpacity = prefs.HoverOpacity
+	config.Current.HoverClickThrough = prefs.HoverClickThrough
+	config.Current.RecordingIndicator = prefs.RecordingIndicator
+	config.Current.AutoCopy = prefs.AutoCopy
+	config.Current.AutoCopyMode = prefs.AutoCopyMode
+	config.Current.AutoCopyTransient = prefs.AutoCopyTransient
+	config.Current.CopyToPrimary = prefs.CopyToPrimary
+	config.Current.WebhookURL = prefs.WebhookURL
+	config.Current.SegmentWebhookURLs = prefs.SegmentWebhooks
+	config.Current.WebhookSecret = prefs.WebhookSecret
+	config.Current.NotesEnabled = prefs.NotesEnabled
+	config.Current.NotesPath = prefs.NotesPath
+	config.Current.NotesTemplate = prefs.NotesTemplate
+	config.Current.MQTTEnabled = prefs.MQTTEnabled
+	config.Current.MQTTBroker = prefs.MQTTBroker
+	config.Current.MQTTUsername = prefs.MQTTUsername
+	config.Current.MQTTPassword = prefs.MQTTPassword
+	config.Current.MQTTTopic = prefs.MQTTTopic
+	config.Current.MQTTPublishPartial = prefs.MQTTPublishPartial
+	config.Current.WatchKeywords = prefs.WatchKeywords
+	config.Current.VoiceCommands = prefs.VoiceCommands
+	config.Current.Hooks = prefs.Hooks
+	if rules, err := textproc.ParseCorrections(strings.Join(prefs.Corrections, "\n")); err != nil {
+		logger.Warning(logger.CategoryApp, "Keeping the previous replacements: %v", err)
+		a.frontend.ShowTemporaryStatus("Invalid replacement, see the log", 3*time.Second)
+	} else {
+		config.Current.Corrections = make([]config.CorrectionRule, len(rules))
+		for i, rule := range rules {
+			config.Current.Corrections[i] = config.CorrectionRule{From: rule.From, To: rule.To, Regex: rule.Regex}
+		}
+	}
+	config.Current.SummaryEnabled = prefs.SummaryEnabled
+	config.Current.SummaryProvider = prefs.SummaryProvider
+	config.Current.SummaryURL = prefs.SummaryURL
+	config.Current.SummaryModel = prefs.SummaryModel
+	config.Current.SummaryAPIKey = prefs.SummaryAPIKey
+	config.Current.SummaryPrompt = prefs.SummaryPrompt
+	config.Current.TTSEngine = prefs.TTSEngine
+	config.Current.TTSVoice = prefs.TTSVoice
+	config.Current.Outputs = outputRules(prefs.Outputs)
+	config.Current.NumberLocale = prefs.NumberLocale
+	config.Current.AppOutputs = appOutputRules(prefs.AppOutputs)
+	config.Current.AnalyticsSessions = prefs.AnalyticsSessions
+	config.Current.AnalyticsFeatures = prefs.AnalyticsFeatures
+	config.Current.RedactProfanity = prefs.RedactProfanity
+	config.Current.RedactEmails = prefs.RedactEmails
+	config.Current.RedactPhoneNumbers = prefs.RedactPhoneNumbers
+	config.Current.RedactCardNumbers = prefs.RedactCardNumbers
+	config.Current.RedactDisplay = prefs.RedactDisplay
+	config.Current.RedactClipboard = prefs.RedactClipboard
+	config.Current.RedactSaved = prefs.RedactSaved
+	config.Current.LogLevel = prefs.LogLevel
+	config.Current.LogCategoryLevels = prefs.LogCategoryLevels
+	config.Current.LogJSON = prefs.LogJSON
+	config.Current.LogToFile = prefs.LogToFile
+	config.Current.HotKeyKey = prefs.HotkeyKey
+	config.Current.HotKeyCtrl, config.Current.HotKeyShift, config.Current.HotKeyAlt = false, false, false
+	for _, mod := range prefs.HotkeyModifiers {
+		switch mod {
+		case "ctrl":
+			config.Current.HotKeyCtrl = true
+		case "shift":
+			config.Current.HotKeyShift = true
+		case "alt":
+			config.Current.HotKeyAlt = true
+		}
+	}
+
+	if err := config.SaveConfig(); err != nil {
+		logger.Warning(logger.CategoryApp, "Failed to save preferences: %v", err)
+	}
+
+	a.configureLogging()
+	a.configureAutostart(prefs.StartAtLogin)
+	a.configureRedaction()
+	a.configureArchive()
+	a.configureAnalytics()
+	a.configureWebhooks()
+	a.configureMQTT()
+	a.configureOutputs()
+	a.configureSuppression()
+	applyDecoding(a.transcriber)
+	a.transcriber.SetLanguage(config.Current.Language)
+	a.transcriber.SetKeepContext(config.Current.KeepContext)
+	a.configureRewrite()
+	a.configureCleanup()
+	a.configureHooks()
+	a.configureKeywords()
+	a.configureCommands()
+	a.mu.Lock()
+	a.corrector = correctorFromConfig()
+	a.mu.Unlock()
+	a.audio.SetGain(prefs.InputGain)
+	a.audio.SetDeviceHandling(config.Current.AudioFallbackToDefault, a.onDeviceEvent)
+	clipboard.SetAlsoPrimary(config.Current.CopyToPrimary)
+
+	// Apply a changed idle delay, backend and sample rate unless a recording is in progress
+	if !a.audio.IsActive() {
+		a.resetIdleTimer()
+		if err := a.audio.SetBackend(audioBackend()); err != nil {
+			logger.Warning(logger.CategoryAudio, "Keeping the previous audio backend: %v", err)
+			a.frontend.ShowTemporaryStatus("Audio backend unavailable, see the log", 3*time.Second)
+		}
+		if err := a.audio.SetSampleRate(float64(config.Current.AudioSampleRate)); err != nil {
+			logger.Warning(logger.CategoryAudio, "Keeping the previous sample rate: %v", err)
+		}
+		a.configureLatency()
+	}
+}
+
+// presetNames returns the names of the configured presets, in order
+func presetNames() []string {
+	names := make([]string, len(config.Current.Presets))
+	for i, preset := range config.Current.Presets {
+		names[i] = preset.Name
+	}
+	return names
+}
+
+// applyPreset switches to the settings of the named preset. Like a latency
+// profile's, a different live model is loaded at the next start.
+func (a *App) applyPreset(name string) {
+	model := liveModel()
+	preset, err := config.Current.ApplyPreset(name)
+	if err != nil {
+		logger.Warning(logger.CategoryApp, "Failed to apply preset: %v", err)
+		return
+	}
+	logger.Info(logger.CategoryApp, "Applying the %s preset", preset.Name)
+
+	// Show the preset's settings in the preferences dialog, then save and apply them
+	prefs := a.ui.GetPreferences()
+	prefs.LatencyProfile = config.Current.LatencyProfile
+	prefs.Language = config.Current.Language
+	prefs.UtteranceSilenceSeconds = config.Current.UtteranceSilenceSeconds
+	prefs.SuppressHallucinations = config.Current.SuppressHallucinations
+	prefs.Outputs = outputConfigs(config.Current.Outputs)
+	a.ui.SetPreferences(prefs)
+	a.applyPreferences(prefs)
+	a.ui.SetCommandMode(preset.CommandMode)
+	a.ui.SetActivePreset(preset.Name)
+
+	if usesLocalModel() && liveModel() != model {
+		a.frontend.ShowTemporaryStatus(fmt.Sprintf("Preset: %s; the %s model loads when Ramble restarts", preset.Name, liveModel()), 4*time.Second)
+	} else {
+		a.frontend.ShowTemporaryStatus("Preset: "+preset.Name, 2*time.Second)
+	}
+}
+
+// configureAutostart adds or removes the entry starting Ramble at login
+func (a *App) configureAutostart(enabled bool) {
+	if enabled == autostart.Enabled() {
+		return
+	}
+	var err error
+	if enabled {
+		err = autostart.Enable(autostartCommand())
+	} else {
+		err = autostart.Disable()
+	}
+	if err != nil {
+		logger.Warning(logger.CategoryApp, "Failed to change starting at login: %v", err)
+		a.frontend.ShowTemporaryStatus("Failed to change starting at login, see the log", 3*time.Second)
+	}
+}
+
+// autostartCommand returns the command run at login: this program, started
+// minimized with the active user profile
+func autostartCommand() []string {
+	executable, err := os.Executable()
+	if err != nil {
+		executable = os.Args[0]
+	}
+	command := []string{executable, "-minimized"}
+	if profile := config.ActiveProfile(); profile != "" {
+		command = append(command, "-profile", profile)
+	}
+	return command
+}
+
+// saveHoverSettings saves hover window settings changed from the window itself
+func (a *App) saveHoverSettings(settings ui.HoverSettings) {
+	config.Current.HoverOnTop = settings.OnTop
+	config.Current.HoverWidth = settings.Width
+	config.Current.HoverHeight = settings.Height
+	if err := config.SaveConfig(); err != nil {
+		logger.Warning(logger.CategoryApp, "Failed to save hover window settings: %v", err)
+	}
+}
+
+// audioBackend returns the configured audio backend, or PortAudio if the
+// configured one is unknown. Audio from standard input overrides both.
+func audioBackend() audio.Backend {
+	if pipeInput != nil {
+		return pipeInput
+	}
+	backend, err := audio.NewBackend(config.Current.AudioBackend)
+	if err != nil {
+		logger.Warning(logger.CategoryAudio, "Using %s: %v", audio.DefaultBackend, err)
+		backend, _ = audio.NewBackend(audio.DefaultBackend)
+	}
+	return backend
+}
+
+// configureSuppression sets which live text is dropped as hallucinated
+func (a *App) configureSuppression() {
+	if !config.Current.SuppressHallucinations {
+		a.transcriber.SetSuppressor(nil)
+		return
+	}
+	phrases := append(append([]string(nil), transcription.DefaultHallucinations...), config.Current.HallucinationPhrases...)
+	a.transcriber.SetSuppressor(transcription.NewSuppressor(config.Current.HallucinationSilenceRMS, phrases))
+}
+
+// applyDecoding gives transcriber the decoding settings of the backend, if
+// both take them
+func applyDecoding(transcriber transcription.Transcriber) {
+	if decoder, ok := transcriber.(transcription.Decoder); ok {
+		if decoding, ok := backendDecoding(); ok {
+			decoder.SetDecoding(decoding)
+		}
+	}
+}
+
+// backendDecoding returns the decoding settings configured for the
+// transcription backend, or its defaults if there are none or they are
+// invalid. ok is false for backends that don't run whisper.
+func backendDecoding() (decoding transcription.Decoding, ok bool) {
+	backend, _ := transcription.LookupBackend(config.Current.TranscriptionBackend)
+	decoding, ok = transcription.DefaultDecoding(backend.Name)
+	if !ok {
+		return decoding, false
+	}
+	if configured, found := config.Current.Decoding[backend.Name]; found {
+		if err := transcription.Decoding(configured).Validate(); err != nil {
+			logger.Warning(logger.CategoryTranscription, "Using the default decoding settings of %s: %v", backend.Name, err)
+		} else {
+			decoding = transcription.Decoding(configured)
+		}
+	}
+	return decoding, true
+}
+
+// liveModel returns the model for live transcription: the active preset's,
+// or else the one the latency profile prefers
+func liveModel() transcription.ModelSize {
+	if config.Current.LiveModel != "" {
+		return transcription.ModelSize(config.Current.LiveModel)
+	}
+	return latencyTuning().Model
+}
+
+// latencyTuning returns the streaming settings for the configured latency profile
+func latencyTuning() transcription.Tuning {
+	return transcription.ProfileTuning(transcription.LatencyProfile(config.Current.LatencyProfile))
+}
+
+// configureLatency applies the latency profile to capture and transcription.
+// Its model is only loaded at startup.
+func (a *App) configureLatency() {
+	tuning := latencyTuning()
+	a.transcriber.SetTuning(tuning)
+	if err := a.audio.SetFramesPerBuffer(tuning.FramesPerBuffer); err != nil {
+		logger.Warning(logger.CategoryAudio, "Keeping the previous buffer size: %v", err)
+	}
+}
+
+// configureLogging applies the configured log levels and format and opens or
+// closes the log file. The -debug flag keeps every category at debug level.
+func (a *App) configureLogging() {
+	level, err := logger.ParseLevel(config.Current.LogLevel)
+	if err != nil {
+		logger.Warning(logger.CategoryApp, "Using info log level: %v", err)
+	}
+	if a.debug {
+		level = logger.LevelDebug
+	}
+	logger.SetLevel(level)
+
+	logger.ClearCategoryLevels()
+	if !a.debug {
+		for name, levelName := range config.Current.LogCategoryLevels {
+			categoryLevel, err := logger.ParseLevel(levelName)
+			if err != nil {
+				logger.Warning(logger.CategoryApp, "Ignoring log level for %s: %v", name, err)
+				continue
+			}
+			logger.SetCategoryLevel(logger.Category(strings.ToUpper(name)), categoryLevel)
+		}
+	}
+	logger.SetJSON(config.Current.LogJSON)
+
+	if !config.Current.LogToFile {
+		if a.logFile != nil {
+			logger.SetFileOutput(nil)
+			a.logFile.Close()
+			a.logFile = nil
+		}
+		return
+	}
+	if a.logFile != nil {
+		return
+	}
+	dir, err := config.GetLogDir()
+	if err != nil {
+		logger.Warning(logger.CategoryApp, "File logging disabled: %v", err)
+		return
+	}
+	file, err := logger.NewRotatingFile(filepath.Join(dir, "ramble.log"),
+		int64(config.Current.LogMaxSizeMB)*1024*1024, config.Current.LogMaxFiles)
+	if err != nil {
+		logger.Warning(logger.CategoryApp, "File logging disabled: %v", err)
+		return
+	}
+	a.logFile = file
+	logger.SetFileOutput(file)
+	logger.Info(logger.CategoryApp, "Logging to %s", file.Path())
+}
+
+// configureAnalytics chooses which usage statistics are recorded
+func (a *App) configureAnalytics() {
+	if a.analytics == nil {
+		return
+	}
+	a.analytics.SetOptions(analytics.Options{
+		Sessions: config.Current.AnalyticsSessions,
+		Features: config.Current.AnalyticsFeatures,
+	})
+}
+
+// configureArchive creates or drops the audio archiver to match the config.
+// A recording in progress keeps archiving until it stops.
+func (a *App) configureArchive() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if !config.Current.ArchiveAudio {
+		a.archiver = nil
+		return
+	}
+
+	if a.archiver == nil {
+		dir, err := config.GetSessionDir()
+		if err != nil {
+			logger.Warning(logger.CategoryAudio, "Audio archiving disabled: %v", err)
+			return
+		}
+		archiver, err := audio.NewArchiver(dir)
+		if err != nil {
+			logger.Warning(logger.CategoryAudio, "Audio archiving disabled: %v", err)
+			return
+		}
+		a.archiver = archiver
+	}
+	a.archiver.SetFormat(config.Current.ArchiveFormat)
+
+	archiver := a.archiver
+	crash.Go(func() { a.pruneArchive(archiver) })
+}
+
+// pruneArchive applies the configured retention limits to archived recordings
+func (a *App) pruneArchive(archiver *audio.Archiver) {
+	maxAge := time.Duration(config.Current.ArchiveMaxDays) * 24 * time.Hour
+	maxBytes := int64(config.Current.ArchiveMaxSizeMB) * 1024 * 1024
+	if err := archiver.Prune(maxAge, maxBytes); err != nil {
+		logger.Warning(logger.CategoryAudio, "Failed to prune audio archive: %v", err)
+	}
+}
+
+// liveLanguage returns the language live text is in, to capitalize it by
+// that language's rules: the chosen language, or the one detected for the
+// newest text when detecting it
+func (a *App) liveLanguage() string {
+	if config.Current.Language != transcription.LanguageAuto {
+		return config.Current.Language
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.spoken
+}
+
+// appendToFullText adds text to the complete transcript
+func (a *App) appendToFullText(text string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.fullText = textproc.Join(a.fullText, text)
+}
+
+// Close performs cleanup
+func (a *App) Close() {
+	if a.commands != nil {
+		a.commands.Close()
+	}
+	a.stopRecording()
+
+	if a.transcriber != nil {
+		a.transcriber.Close()
+	}
+
+	a.mu.Lock()
+	rewriter := a.rewriter
+	a.rewriter = nil
+	a.mu.Unlock()
+	if rewriter != nil {
+		rewriter.Close()
+	}
+	if a.files != nil {
+		a.files.Close()
+	}
+
+	if a.audio != nil {
+		a.audio.Close()
+	}
+
+	a.closeMQTT()
+
+	stopTempFiles()
+
+	if a.logFile != nil {
+		logger.SetFileOutput(nil)
+		a.logFile.Close()
+	}
+}
+
+// startTempFiles opens the temporary file directory of this run, first
+// removing those left by runs that crashed
+func startTempFiles() {
+	root, err := config.GetTempDir()
+	if err != nil {
+		logger.Warning(logger.CategoryApp, "Using the system temporary directory: %v", err)
+		return
+	}
+	swept, err := tempfiles.Start(root)
+	if err != nil {
+		logger.Warning(logger.CategoryApp, "%v", err)
+	}
+	if swept > 0 {
+		logger.Info(logger.CategoryApp, "Removed temporary files left by %d earlier runs", swept)
+	}
+}
+
+// stopTempFiles removes the temporary files of this run
+func stopTempFiles() {
+	if err := tempfiles.Stop(); err != nil {
+		logger.Warning(logger.CategoryApp, "Failed to remove temporary files: %v", err)
+	}
+}
+
+// newTranscriber creates a transcriber using the configured backend and its
+// decoding settings. The whisper backend loads the installed model of the
+// given size; other backends choose their own model.
+func newTranscriber(modelSize transcription.ModelSize) (transcription.Transcriber, error) {
+	transcriber, err := newBackendTranscriber(modelSize)
+	if err != nil {
+		return nil, err
+	}
+	applyDecoding(transcriber)
+	return transcriber, nil
+}
+
+// newBackendTranscriber creates a transcriber using the configured backend
+func newBackendTranscriber(modelSize transcription.ModelSize) (transcription.Transcriber, error) {
+	switch config.Current.TranscriptionBackend {
+	case transcription.BackendServer:
+		logger.Info(logger.CategoryTranscription, "Transcribing with whisper-server at %s", config.Current.WhisperServerURL)
+		return transcription.NewServerTranscriber(config.Current.WhisperServerURL), nil
+	case transcription.BackendFasterWhisper, transcription.BackendVosk:
+		command := strings.Fields(sidecarCommand(config.Current.TranscriptionBackend))
+		logger.Info(logger.CategoryTranscription, "Transcribing with %s sidecar %s", config.Current.TranscriptionBackend, command[0])
+		return newSidecarTranscriber(command), nil
+	case transcription.BackendWindowsSpeech:
+		return newSidecarTranscriber(transcription.WindowsSpeechCommand()), nil
+	default:
+		if _, ok := transcription.LookupBackend(config.Current.TranscriptionBackend); !ok {
+			logger.Warning(logger.CategoryTranscription, "Unknown transcription backend %q; using whisper", config.Current.TranscriptionBackend)
+		}
+	}
+
+	modelPath := transcription.GetLocalModelPath(modelSize)
+	if modelPath == "" {
+		// Fall back to the recognizer built into the OS until a model is installed
+		if native, ok := transcription.NativeBackend(); ok {
+			logger.Warning(logger.CategoryTranscription, "The %s model is not installed; using %s", modelSize, native.Label)
+			return newSidecarTranscriber(transcription.WindowsSpeechCommand()), nil
+		}
+		return nil, fmt.Errorf("the %s model is not installed", modelSize)
+	}
+	transcriber, err := transcription.NewManager(modelPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize transcriber: %w", err)
+	}
+	return transcriber, nil
+}
+
+// newSidecarTranscriber creates a transcriber running the sidecar command,
+// restarted when it stops answering for the configured time
+func newSidecarTranscriber(command []string) transcription.Transcriber {
+	engine := transcription.NewSidecarEngine(command)
+	engine.SetResponseTimeout(time.Duration(config.Current.SidecarTimeout) * time.Second)
+	return transcription.NewEngineTranscriber(engine)
+}
+
+// newPerformanceMeter creates the meter for a live transcriber made by
+// newTranscriber with the given model size, naming the backend it chose
+func newPerformanceMeter(modelSize transcription.ModelSize) *transcription.PerformanceMeter {
+	if !usesLocalModel() {
+		backend, _ := transcription.LookupBackend(config.Current.TranscriptionBackend)
+		return transcription.NewPerformanceMeter(backend.Label, "", 0)
+	}
+	if transcription.GetLocalModelPath(modelSize) == "" {
+		if native, ok := transcription.NativeBackend(); ok {
+			return transcription.NewPerformanceMeter(native.Label, "", 0)
+		}
+	}
+	whisper, _ := transcription.LookupBackend(transcription.BackendWhisper)
+	return transcription.NewPerformanceMeter(whisper.Label, string(modelSize), transcription.WhisperThreads())
+}
+
+// sidecarCommand returns the configured command for a sidecar backend, or
+// its default
+func sidecarCommand(backend string) string {
+	command, fallback := config.Current.FasterWhisperCommand, transcription.DefaultFasterWhisperCommand
+	if backend == transcription.BackendVosk {
+		command, fallback = config.Current.VoskCommand, transcription.DefaultVoskCommand
+	}
+	if strings.TrimSpace(command) == "" {
+		return fallback
+	}
+	return command
+}
+
+// summarize asks the configured language model for a summary of transcript
+func summarize(transcript string) (string, error) {
+	if !config.Current.SummaryEnabled {
+		return "", errors.New("summaries are off; turn them on under Preferences > Summary")
+	}
+	client, err := llm.NewClient(llm.Options{
+		Provider: config.Current.SummaryProvider,
+		URL:      config.Current.SummaryURL,
+		Model:    config.Current.SummaryModel,
+		APIKey:   config.Current.SummaryAPIKey,
+	})
+	if err != nil {
+		return "", err
+	}
+	return client.Summarize(context.Background(), config.Current.SummaryPrompt, transcript)
+}
+
+// readAloud speaks text with the configured speech synthesizer
+func readAloud(ctx context.Context, text string) error {
+	engine, err := tts.New(config.Current.TTSEngine, config.Current.TTSVoice)
+	if err != nil {
+		return err
+	}
+	logger.Debug(logger.CategoryApp, "Reading %d characters aloud with %s", len(text), engine.Name())
+	return engine.Speak(ctx, text)
+}
+
+// usesLocalModel reports whether the configured backend loads an installed
+// whisper model
+func usesLocalModel() bool {
+	backend, ok := transcription.LookupBackend(config.Current.TranscriptionBackend)
+	return !ok || backend.LocalModel
+}
+
+// retranscribe transcribes an archived recording with the given model size,
+// using a separate transcriber so live transcription is not disturbed
+func retranscribe(audioPath, modelSize string) (string, error) {
+	samples, err := audio.LoadRecording(audioPath)
+	if err != nil {
+		return "", err
+	}
+
+	transcriber, err := newTranscriber(transcription.ModelSize(modelSize))
+	if err != nil {
+		return "", err
+	}
+	defer transcriber.Close()
+	transcriber.SetVocabulary(config.Current.Vocabulary)
+	transcriber.SetLanguage(config.Current.Language)
+
+	segments, err := transcriber.TranscribeSamples(samples)
+	if err != nil {
+		return "", err
+	}
+
+	texts := make([]string, len(segments))
+	for i, seg := range segments {
+		texts[i] = seg.Text
+	}
+	return strings.Join(texts, " "), nil
+}
+
+// newFileQueue creates a queue transcribing files with up to FileWorkers
+// models of the size modelSize returns when they load, or tiny if that size
+// isn't installed. Its models are separate from live transcription's.
+func newFileQueue(modelSize func() string) *transcription.Queue {
+	return transcription.NewQueue(config.Current.FileWorkers, func() (transcription.Transcriber, error) {
+		size := transcription.ModelSize(modelSize())
+		if usesLocalModel() && transcription.GetLocalModelPath(size) == "" {
+			size = transcription.ModelTiny
+		}
+		logger.Info(logger.CategoryTranscription, "Loading %s model for file transcription", size)
+		transcriber, err := newTranscriber(size)
+		if err != nil {
+			return nil, err
+		}
+		transcriber.SetVocabulary(config.Current.Vocabulary)
+		transcriber.SetLanguage(config.Current.Language)
+		return transcriber, nil
+	})
+}
+
+// transcribeFile transcribes a WAV or FLAC file in queue. The file is split
+// at pauses into chunks of whisper's length, so a long file is spread over
+// the queue's workers. With a manifest, chunks transcribed by an earlier run
+// are skipped and each new one is recorded.
+func transcribeFile(queue *transcription.Queue, manifest *transcription.Manifest, path string, progress func(fraction float64)) ([]session.Segment, error) {
+	samples, err := audio.LoadRecording(path)
+	if err != nil {
+		return nil, err
+	}
+	chunks := transcription.SplitAtSilence(samples, transcription.ChunkMinLength, transcription.ChunkMaxLength)
+	logger.Info(logger.CategoryTranscription, "Transcribing %s in %d chunks", filepath.Base(path), len(chunks))
+	if manifest != nil {
+		if chunks, err = manifest.Pending(path, chunks); err != nil {
+			return nil, err
+		}
+	}
+
+	transcribed, err := queue.Add(chunks, func(percent int) {
+		progress(float64(percent) / 100)
+	}).Wait()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filepath.Base(path), err)
+	}
+	if manifest != nil {
+		transcribed = manifest.Segments(path)
+	}
+
+	segments := make([]session.Segment, len(transcribed))
+	for i, seg := range transcribed {
+		segments[i] = session.Segment{Text: seg.Text, Start: seg.Start, End: seg.End, Language: seg.Language}
+	}
+	return segments, nil
+}
+
+// transcribeInterview transcribes each channel of a WAV file separately and
+// prints the speaker-labelled transcript to stdout
+func transcribeInterview(path, speakerNames string) error {
+	channels, sampleRate, err := audio.LoadChannelsFromWav(path)
+	if err != nil {
+		return err
+	}
+	if len(channels) < 2 {
+		return fmt.Errorf("%s has a single channel; per-channel transcription needs one speaker per channel", path)
+	}
+	for i := range channels {
+		channels[i] = audio.ResampleTo16k(channels[i], sampleRate)
+	}
+
+	var speakers []string
+	if speakerNames != "" {
+		for _, name := range strings.Split(speakerNames, ",") {
+			speakers = append(speakers, strings.TrimSpace(name))
+		}
+	}
+
+	transcriber, err := newTranscriber(transcription.ModelTiny)
+	if err != nil {
+		return err
+	}
+	defer transcriber.Close()
+	transcriber.SetVocabulary(config.Current.Vocabulary)
+	transcriber.SetLanguage(config.Current.Language)
+
+	segments, err := transcriber.TranscribeChannels(channels, speakers)
+	if err != nil {
+		return err
+	}
+
+	fmt.Print(transcription.FormatSpeakerTranscript(segments))
+	return nil
+}
+
+func main() {
+	// Write a crash report if the main goroutine panics
+	defer crash.Handle()
+
+	// Subcommands that work on saved history without starting the UI
+	if len(os.Args) > 1 && os.Args[1] == "export" {
+		if err := exportCommand(os.Args[2:]); err != nil {
+			logger.Error(logger.CategoryApp, "Export failed: %v", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// Transcribe an audio file without starting the UI
+	if len(os.Args) > 1 && os.Args[1] == "transcribe" {
+		if err := transcribeCommand(os.Args[2:]); err != nil {
+			logger.Error(logger.CategoryApp, "Transcription failed: %v", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// Long-running stability test of live transcription
+	if len(os.Args) > 1 && os.Args[1] == "soak" {
+		if err := soakCommand(os.Args[2:]); err != nil {
+			logger.Error(logger.CategoryApp, "%v", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// Parse command line flags
+	debug := flag.Bool("debug", false, "Enable debug output")
+	interview := flag.String("interview", "", "Transcribe a stereo WAV file with one speaker per channel and exit")
+	speakers := flag.String("speakers", "", "Comma-separated speaker names for -interview, one per channel")
+	profile := flag.String("profile", "", "Use the named user profile, creating it if needed")
+	flag.StringVar(&metricsAddress, "metrics", "", "Serve Prometheus metrics at this address, e.g. 127.0.0.1:9464")
+	flag.StringVar(&traceFile, "trace", "", "Write the latency of each stage of live transcription to this Chrome trace file")
+	stdoutJSON := flag.Bool("stdout-json", false, "Print each partial and final transcript to stdout as a JSON line, and nothing else")
+	stdin := flag.Bool("stdin", false, "Record 16-bit PCM or WAV audio from stdin instead of the microphone, e.g. piped from arecord")
+	stdinRate := flag.Int("stdin-rate", audio.TargetSampleRate, "Sample rate of raw PCM read with -stdin")
+	stdinChannels := flag.Int("stdin-channels", 1, "Channel count of raw PCM read with -stdin")
+	minimized := flag.Bool("minimized", false, "Start with only the tray icon shown, as when started at login")
+	frontend := flag.String("frontend", frontendGUI, "User interface: gui, terminal, or none to print each segment to stdout")
+	control := []struct {
+		command string
+		set     *bool
+	}{
+		{instance.CommandToggle, flag.Bool(instance.CommandToggle, false, "Start recording in the running Ramble, or stop if it is recording, and exit")},
+		{instance.CommandStart, flag.Bool(instance.CommandStart, false, "Start recording in the running Ramble and exit")},
+		{instance.CommandStop, flag.Bool(instance.CommandStop, false, "Stop recording in the running Ramble and exit")},
+		{instance.CommandCopyLast, flag.Bool(instance.CommandCopyLast, false, "Copy the newest segment in the running Ramble to the clipboard, print it and exit")},
+	}
+	flag.Parse()
+
+	// Configure logger based on debug flag
+	if *debug {
+		logger.SetLevel(logger.LevelDebug)
+	}
+
+	// Keep stdout for the events: logs are dropped, apart from the log file
+	// if enabled, and anything else printed goes to stderr
+	if *stdoutJSON {
+		jsonEvents = output.NewJSONLines(os.Stdout)
+		os.Stdout = os.Stderr
+		logger.SetOutput(io.Discard)
+	}
+	logger.Info(logger.CategoryApp, "Starting Ramble - Speech to Text")
+
+	if *stdin {
+		if *stdinRate <= 0 || *stdinChannels <= 0 {
+			logger.Error(logger.CategoryApp, "-stdin-rate and -stdin-channels must be positive")
+			os.Exit(1)
+		}
+		pipeInput = audio.NewPipeBackend("stdin", os.Stdin, *stdinRate, *stdinChannels)
+	}
+
+	// Control the running instance, e.g. from a hotkey daemon, instead of starting another
+	for _, c := range control {
+		if !*c.set {
+			continue
+		}
+		if err := sendCommand(*profile, c.command); err != nil {
+			logger.Error(logger.CategoryApp, "%v", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// Batch mode: transcribe an interview recording without starting the UI
+	if *interview != "" {
+		if err := selectProfile(*profile); err != nil {
+			logger.Error(logger.CategoryApp, "%v", err)
+			os.Exit(1)
+		}
+		if err := transcribeInterview(*interview, *speakers); err != nil {
+			logger.Error(logger.CategoryApp, "Interview transcription failed: %v", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// Run without the window, e.g. in a terminal or as a server
+	if *frontend != frontendGUI {
+		if err := runFrontend(*frontend, *profile); err != nil {
+			logger.Error(logger.CategoryApp, "%v", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// On shared machines with named profiles, ask who is using Ramble
+	if *profile == "" {
+		profiles, err := config.ListProfiles()
+		if err != nil {
+			logger.Warning(logger.CategoryApp, "Failed to list profiles: %v", err)
+		}
+		if len(profiles) > 0 {
+			ui.ChooseProfile(profiles, config.ValidateProfileName, func(name string) {
+				app := startApp(name, *debug)
+				app.ui.SetStartHidden(*minimized)
+				app.Show()
+				app.recordPipe()
+			})
+			return
+		}
+	}
+
+	// Run the application
+	app := startApp(*profile, *debug)
+	app.ui.SetStartHidden(*minimized)
+	app.recordPipe()
+	app.Run()
+}
+
+// exportCommand implements "ramble export", which writes a saved session's
+// transcript as Markdown, plain text or HTML
+func exportCommand(args []string) error {
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	format := flags.String("format", "markdown", "Output format: markdown, text or html")
+	output := flags.String("o", "", "Write to this file instead of stdout; its extension sets the default format")
+	profile := flags.String("profile", "", "Export from the named user profile")
+	list := flags.Bool("list", false, "List saved sessions instead of exporting one")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: ramble export [flags] [session ID | latest]\n\n")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if err := selectProfile(*profile); err != nil {
+		return err
+	}
+	store, err := session.DefaultStore()
+	if err != nil {
+		return err
+	}
+
+	if *list {
+		ids, err := store.List()
+		if err != nil {
+			return err
+		}
+		for _, id := range ids {
+			s, err := store.Load(id)
+			if err != nil {
+				logger.Warning(logger.CategoryApp, "Skipping session %s: %v", id, err)
+				continue
+			}
+			preview := strings.Join(s.Texts(), " ")
+			if len([]rune(preview)) > 60 {
+				preview = string([]rune(preview)[:60]) + "..."
+			}
+			fmt.Printf("%s\t%d segments\t%s\n", id, len(s.List()), preview)
+		}
+		return nil
+	}
+
+	// Without an explicit format, the output file's extension decides
+	name := *format
+	formatSet := false
+	flags.Visit(func(f *flag.Flag) {
+		if f.Name == "format" {
+			formatSet = true
+		}
+	})
+	if !formatSet && *output != "" {
+		if ext := filepath.Ext(*output); ext != "" {
+			name = ext
+		}
+	}
+	transcriptFormat, err := session.ParseTranscriptFormat(name)
+	if err != nil {
+		return err
+	}
+
+	var s *session.Session
+	if id := flags.Arg(0); id == "" || id == "latest" {
+		s, err = store.Latest()
+		if err == nil && s == nil {
+			err = fmt.Errorf("no saved sessions")
+		}
+	} else {
+		s, err = store.Load(id)
+	}
+	if err != nil {
+		return err
+	}
+
+	data, err := session.RenderTranscript(s, transcriptFormat)
+	if err != nil {
+		return err
+	}
+	if *output == "" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	if err := os.WriteFile(*output, data, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", *output, err)
+	}
+	return nil
+}
+
+// transcribeCommand implements "ramble transcribe", which transcribes WAV
+// or FLAC files of any length and writes their timed transcripts. Several
+// files are transcribed together, sharing the workers.
+func transcribeCommand(args []string) error {
+	flags := flag.NewFlagSet("transcribe", flag.ExitOnError)
+	format := flags.String("format", "text", "Output format: markdown, text or html")
+	output := flags.String("o", "", "Write to this file instead of stdout; its extension sets the default format")
+	profile := flags.String("profile", "", "Use the settings of the named user profile")
+	model := flags.String("model", "", "Model size to transcribe with (default: the configured model)")
+	workers := flags.Int("workers", 0, "Chunks transcribed at once, each loading its own model (default: FileWorkers from the config)")
+	manifestPath := flags.String("manifest", "", "Record progress in this JSON file, and resume from it if it exists")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: ramble transcribe [flags] file.wav...\n\n")
+		fmt.Fprintf(flags.Output(), "With several files or a manifest, each transcript is written next to its file.\n\n")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+	paths := flags.Args()
+	if len(paths) == 0 {
+		flags.Usage()
+		os.Exit(2)
+	}
+	if len(paths) > 1 && *output != "" {
+		return fmt.Errorf("-o takes a single file; transcripts of several files are written next to them")
+	}
+
+	// Without an explicit format, the output file's extension decides
+	name := *format
+	formatSet := false
+	flags.Visit(func(f *flag.Flag) {
+		if f.Name == "format" {
+			formatSet = true
+		}
+	})
+	if !formatSet && *output != "" {
+		if ext := filepath.Ext(*output); ext != "" {
+			name = ext
+		}
+	}
+	transcriptFormat, err := session.ParseTranscriptFormat(name)
+	if err != nil {
+		return err
+	}
+	if err := selectProfile(*profile); err != nil {
+		return err
+	}
+	if *model == "" {
+		*model = config.Current.WhisperModelType
+	}
+	if *workers > 0 {
+		config.Current.FileWorkers = *workers
+	}
+
+	// Files finished by an interrupted run are skipped
+	var manifest *transcription.Manifest
+	if *manifestPath != "" {
+		if manifest, err = transcription.OpenManifest(*manifestPath, *model); err != nil {
+			return err
+		}
+		var remaining []string
+		for _, path := range paths {
+			if manifest.Complete(path) {
+				logger.Info(logger.CategoryApp, "Skipping %s, transcribed by an earlier run", path)
+				continue
+			}
+			remaining = append(remaining, path)
+		}
+		paths = remaining
+	}
+
+	queue := newFileQueue(func() string { return *model })
+	defer queue.Close()
+	queue.SetProgressCallback(func(p transcription.QueueProgress) {
+		fmt.Fprintf(os.Stderr, "\rTranscribing: %d of %d files done, %3d%%", p.JobsDone, p.Jobs, p.Percent())
+	})
+
+	results := make([][]session.Segment, len(paths))
+	errs := make([]error, len(paths))
+	var wg sync.WaitGroup
+	for i, path := range paths {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], errs[i] = transcribeFile(queue, manifest, path, func(float64) {})
+		}()
+	}
+	wg.Wait()
+	fmt.Fprintln(os.Stderr)
+
+	failed := 0
+	for i, path := range paths {
+		if errs[i] != nil {
+			logger.Error(logger.CategoryApp, "%v", errs[i])
+			failed++
+			continue
+		}
+
+		s := session.New()
+		s.Segments = results[i]
+		s.Metadata = map[string]string{"title": filepath.Base(path), "model": *model}
+		data, err := session.RenderTranscript(s, transcriptFormat)
+		if err != nil {
+			return err
+		}
+
+		target := *output
+		if len(flags.Args()) > 1 || (manifest != nil && target == "") {
+			target = strings.TrimSuffix(path, filepath.Ext(path)) + transcriptFormat.Extension()
+		}
+		if target == "" {
+			if _, err := os.Stdout.Write(data); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := os.WriteFile(target, data, 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", target, err)
+		}
+		if manifest != nil {
+			if err := manifest.MarkComplete(path); err != nil {
+				return err
+			}
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d files could not be transcribed", failed, len(paths))
+	}
+	return nil
+}
+
+// selectProfile activates a user profile and loads its configuration
+func selectProfile(name string) error {
+	if err := config.SetProfile(name); err != nil {
+		return fmt.Errorf("failed to select profile %q: %w", name, err)
+	}
+	if name != "" {
+		logger.Info(logger.CategoryApp, "Using profile %q", name)
+	}
+
+	// Load saved configuration, falling back to defaults
+	if err := config.LoadConfig(); err != nil {
+		logger.Warning(logger.CategoryApp, "Failed to load config, using defaults: %v", err)
+	}
+	return nil
+}
+
+// sendCommand runs command in the running instance of the named user
+// profile and prints its reply, if any
+func sendCommand(profile, command string) error {
+	if err := config.SetProfile(profile); err != nil {
+		return fmt.Errorf("failed to select profile %q: %w", profile, err)
+	}
+	path, err := config.GetSocketPath()
+	if err != nil {
+		return err
+	}
+	reply, err := instance.Send(path, command)
+	if err != nil {
+		return fmt.Errorf("-%s failed: %w", command, err)
+	}
+	if reply != "" {
+		fmt.Println(reply)
+	}
+	return nil
+}
+
+// showRunning shows the main window of the instance already running for
+// the active profile, and reports whether there is one
+func showRunning() bool {
+	path, err := config.GetSocketPath()
+	if err != nil {
+		return false
+	}
+	if _, err := instance.Send(path, instance.CommandShow); err != nil {
+		if !errors.Is(err, instance.ErrNotRunning) {
+			logger.Warning(logger.CategoryApp, "Failed to reach the running Ramble: %v", err)
+		}
+		return false
+	}
+	logger.Info(logger.CategoryApp, "Ramble is already running; showed its window")
+	return true
+}
+
+// listenForCommands lets other processes control this instance through
+// its socket. Audio from stdin runs alongside other instances instead.
+func (a *App) listenForCommands() {
+	if pipeInput != nil {
+		return
+	}
+	path, err := config.GetSocketPath()
+	if err == nil {
+		a.commands, err = instance.Listen(path, a.runInstanceCommand)
+	}
+	if err != nil {
+		logger.Warning(logger.CategoryApp, "Ramble can't be controlled from the command line: %v", err)
+	}
+}
+
+// runInstanceCommand runs a command sent by another Ramble process
+func (a *App) runInstanceCommand(command string) (string, error) {
+	logger.Info(logger.CategoryApp, "Received command %s", command)
+	switch command {
+	case instance.CommandShow:
+		a.ui.ShowMainWindow()
+	case instance.CommandToggle:
+		a.ui.ToggleListening()
+	case instance.CommandStart:
+		a.ui.SetListening(true)
+	case instance.CommandStop:
+		a.ui.SetListening(false)
+	case instance.CommandCopyLast:
+		return a.ui.CopyLastSegment()
+	default:
+		return "", fmt.Errorf("unknown command %q", command)
+	}
+	return "", nil
+}
+
+// startApp selects the profile, creates the application and handles
+// termination signals. The caller shows or runs the returned application.
+func startApp(profile string, debug bool) *App {
+	if err := selectProfile(profile); err != nil {
+		logger.Error(logger.CategoryApp, "%v", err)
+		os.Exit(1)
+	}
+
+	// Launching Ramble again shows the window of the one running
+	if pipeInput == nil && showRunning() {
+		os.Exit(0)
+	}
+
+	// Temporary files go in a directory of this run, removed when it ends
+	startTempFiles()
+
+	// Create the application
+	app, err := New(debug)
+	if err != nil {
+		logger.Error(logger.CategoryApp, "Failed to initialize application: %v", err)
+		os.Exit(1)
+	}
+	app.serveMetrics()
+	app.serveDebug()
+	app.listenForCommands()
+
+	// Handle termination signals
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	crash.Go(func() {
+		<-sigChan
+		logger.Info(logger.CategoryApp, "Shutting down...")
+		app.Close()
+		os.Exit(0)
+	})
+
+	return app
 }
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/jeff-barlow-spady/ramble/pkg/audio"
+	"github.com/jeff-barlow-spady/ramble/pkg/config"
+	"github.com/jeff-barlow-spady/ramble/pkg/soak"
+	"github.com/jeff-barlow-spady/ramble/pkg/transcription"
+)
+
+// soakCommand implements "ramble soak", which runs live transcription on
+// looped audio for hours and fails if memory, goroutines or buffers keep
+// growing
+func soakCommand(args []string) error {
+	opts := soak.DefaultOptions()
+	flags := flag.NewFlagSet("soak", flag.ExitOnError)
+	flags.DurationVar(&opts.Duration, "duration", opts.Duration, "How long to run")
+	flags.Float64Var(&opts.Speed, "speed", opts.Speed, "Seconds of audio fed per second; above 1 feeds faster than real time")
+	flags.DurationVar(&opts.Recording, "recording", opts.Recording, "Length of each recording before the next is started")
+	flags.DurationVar(&opts.Interval, "interval", opts.Interval, "Time between measurements")
+	heapGrowth := flags.Uint64("max-heap-growth", opts.MaxHeapGrowth>>20, "Fail if the heap grows by more MB after warm-up")
+	flags.IntVar(&opts.MaxGoroutineGrowth, "max-goroutine-growth", opts.MaxGoroutineGrowth, "Fail if more goroutines are added after warm-up")
+	flags.Float64Var(&opts.MaxBufferSeconds, "max-buffer", opts.MaxBufferSeconds, "Fail if the transcriber holds more seconds of audio")
+	wav := flags.String("wav", "", "Loop this WAV file instead of synthetic speech-like audio")
+	profile := flags.String("profile", "", "Use the named user profile's settings")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: ramble soak [flags]\n\n")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+	opts.MaxHeapGrowth = *heapGrowth << 20
+
+	if opts.Speed <= 0 || opts.Interval <= 0 || opts.Duration < 4*opts.Interval {
+		return errors.New("run for at least four intervals at a positive speed")
+	}
+	if err := selectProfile(*profile); err != nil {
+		return err
+	}
+	opts.QueueSeconds = float64(config.Current.AudioQueueSeconds)
+	if seconds := config.Current.UtteranceSilenceSeconds; seconds > 0 {
+		opts.Pause = time.Duration(seconds * float64(time.Second))
+	}
+
+	source := soak.SpeechLike(time.Minute, 1)
+	if *wav != "" {
+		channels, sampleRate, err := audio.LoadChannelsFromWav(*wav)
+		if err != nil {
+			return err
+		}
+		source = audio.ResampleTo16k(channels[0], sampleRate)
+	}
+
+	tuning := latencyTuning()
+	model := tuning.Model
+	if usesLocalModel() && transcription.GetLocalModelPath(model) == "" {
+		model = transcription.ModelTiny
+	}
+	transcriber, err := newTranscriber(model)
+	if err != nil {
+		return err
+	}
+	defer transcriber.Close()
+	transcriber.SetTuning(tuning)
+	transcriber.SetVocabulary(config.Current.Vocabulary)
+	transcriber.SetLanguage(config.Current.Language)
+
+	// Stop early on Ctrl+C, still reporting what was measured
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	fmt.Printf("Soaking the %s model for %v at %gx real time\n", model, opts.Duration, opts.Speed)
+	opts.Progress = func(sample soak.Sample) {
+		fmt.Println(sample)
+	}
+	result := soak.Run(ctx, transcriber, source, opts)
+	if !result.Passed() {
+		return fmt.Errorf("soak test failed:\n  %s", strings.Join(result.Failures, "\n  "))
+	}
+	fmt.Println("Soak test passed")
+	return nil
+}
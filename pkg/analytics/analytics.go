@@ -0,0 +1,333 @@
+// Package analytics keeps usage statistics on this computer so users can see
+// their own dictation habits. Nothing recorded here is ever sent anywhere.
+package analytics
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/jeff-barlow-spady/ramble/pkg/config"
+)
+
+// Features counted when feature statistics are enabled
+const (
+	FeatureCopy           = "copy"
+	FeatureExport         = "export"
+	FeatureImport         = "import"
+	FeatureTranscribeFile = "transcribe file"
+	FeatureRetranscribe   = "re-run with model"
+	FeatureMerge          = "merge segments"
+	FeatureSplit          = "split segment"
+	FeatureSearch         = "search history"
+	FeatureVocabulary     = "add to vocabulary"
+	FeatureCorrection     = "correction rule"
+	FeatureWebhook        = "send to webhook"
+)
+
+// dateFormat is the layout of Day.Date
+const dateFormat = "2006-01-02"
+
+// Options chooses what is recorded. Everything is off unless the user opts in.
+type Options struct {
+	Sessions bool // Recording sessions, their length and the model used
+	Features bool // How often each feature is used
+}
+
+// Day holds the statistics for one calendar day
+type Day struct {
+	Date     string         `json:"date"` // Local date, YYYY-MM-DD
+	Sessions int            `json:"sessions"`
+	Seconds  float64        `json:"seconds"`  // Time spent recording
+	Models   map[string]int `json:"models"`   // Sessions per model
+	Features map[string]int `json:"features"` // Uses per feature
+}
+
+// Minutes returns the time spent recording in minutes
+func (d Day) Minutes() float64 {
+	return d.Seconds / 60
+}
+
+// Summary adds up the statistics of several days
+type Summary struct {
+	ActiveDays int // Days with at least one session
+	Sessions   int
+	Minutes    float64
+	Models     map[string]int
+	Features   map[string]int
+}
+
+// Summarize adds up the given days
+func Summarize(days []Day) Summary {
+	s := Summary{
+		Models:   make(map[string]int),
+		Features: make(map[string]int),
+	}
+	for _, day := range days {
+		if day.Sessions > 0 {
+			s.ActiveDays++
+		}
+		s.Sessions += day.Sessions
+		s.Minutes += day.Minutes()
+		for name, n := range day.Models {
+			s.Models[name] += n
+		}
+		for name, n := range day.Features {
+			s.Features[name] += n
+		}
+	}
+	return s
+}
+
+// Since returns the days on or after the given time
+func Since(days []Day, t time.Time) []Day {
+	from := t.Format(dateFormat)
+	var recent []Day
+	for _, day := range days {
+		if day.Date >= from {
+			recent = append(recent, day)
+		}
+	}
+	return recent
+}
+
+// Tracker records usage statistics to a JSON file
+type Tracker struct {
+	path    string
+	options Options
+	days    map[string]*Day
+	now     func() time.Time
+	mu      sync.Mutex
+}
+
+// NewTracker creates a tracker stored at path, loading any existing statistics
+func NewTracker(path string) (*Tracker, error) {
+	t := &Tracker{
+		path: path,
+		days: make(map[string]*Day),
+		now:  time.Now,
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return t, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read usage statistics: %w", err)
+	}
+
+	var days []*Day
+	if err := json.Unmarshal(data, &days); err != nil {
+		return nil, fmt.Errorf("failed to parse usage statistics: %w", err)
+	}
+	for _, day := range days {
+		t.days[day.Date] = day
+	}
+	return t, nil
+}
+
+// DefaultTracker returns a tracker stored in the profile's data directory
+func DefaultTracker() (*Tracker, error) {
+	dir, err := config.GetDataDir()
+	if err != nil {
+		return nil, err
+	}
+	return NewTracker(filepath.Join(dir, "analytics.json"))
+}
+
+// SetOptions chooses what is recorded from now on. Statistics that were
+// already recorded are kept until Clear is called.
+func (t *Tracker) SetOptions(options Options) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.options = options
+}
+
+// Options returns what is being recorded
+func (t *Tracker) Options() Options {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.options
+}
+
+// RecordSession counts a finished recording session if session statistics are enabled
+func (t *Tracker) RecordSession(duration time.Duration, model string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if !t.options.Sessions {
+		return nil
+	}
+
+	day := t.today()
+	day.Sessions++
+	day.Seconds += duration.Seconds()
+	if model != "" {
+		day.Models[model]++
+	}
+	return t.save()
+}
+
+// RecordFeature counts a use of a feature if feature statistics are enabled
+func (t *Tracker) RecordFeature(feature string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if !t.options.Features {
+		return nil
+	}
+
+	t.today().Features[feature]++
+	return t.save()
+}
+
+// Days returns the recorded statistics, oldest day first
+func (t *Tracker) Days() []Day {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	days := make([]Day, 0, len(t.days))
+	for _, day := range t.days {
+		c := *day
+		c.Models = copyCounts(day.Models)
+		c.Features = copyCounts(day.Features)
+		days = append(days, c)
+	}
+	sort.Slice(days, func(i, j int) bool {
+		return days[i].Date < days[j].Date
+	})
+	return days
+}
+
+// Clear deletes all recorded statistics
+func (t *Tracker) Clear() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.days = make(map[string]*Day)
+	if err := os.Remove(t.path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete usage statistics: %w", err)
+	}
+	return nil
+}
+
+// WriteCSV writes one row per day, with a column for each model and feature
+func WriteCSV(w io.Writer, days []Day) error {
+	models := make(map[string]bool)
+	features := make(map[string]bool)
+	for _, day := range days {
+		for name := range day.Models {
+			models[name] = true
+		}
+		for name := range day.Features {
+			features[name] = true
+		}
+	}
+	modelNames := sortedKeys(models)
+	featureNames := sortedKeys(features)
+
+	header := []string{"date", "sessions", "minutes"}
+	for _, name := range modelNames {
+		header = append(header, "model: "+name)
+	}
+	for _, name := range featureNames {
+		header = append(header, "feature: "+name)
+	}
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write(header); err != nil {
+		return fmt.Errorf("failed to write CSV: %w", err)
+	}
+	for _, day := range days {
+		row := []string{
+			day.Date,
+			strconv.Itoa(day.Sessions),
+			strconv.FormatFloat(day.Minutes(), 'f', 1, 64),
+		}
+		for _, name := range modelNames {
+			row = append(row, strconv.Itoa(day.Models[name]))
+		}
+		for _, name := range featureNames {
+			row = append(row, strconv.Itoa(day.Features[name]))
+		}
+		if err := cw.Write(row); err != nil {
+			return fmt.Errorf("failed to write CSV: %w", err)
+		}
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("failed to write CSV: %w", err)
+	}
+	return nil
+}
+
+// today returns the statistics for the current day, creating them if needed.
+// The caller must hold t.mu.
+func (t *Tracker) today() *Day {
+	date := t.now().Format(dateFormat)
+	day, ok := t.days[date]
+	if !ok {
+		day = &Day{Date: date}
+		t.days[date] = day
+	}
+	if day.Models == nil {
+		day.Models = make(map[string]int)
+	}
+	if day.Features == nil {
+		day.Features = make(map[string]int)
+	}
+	return day
+}
+
+// save writes the statistics to disk. The caller must hold t.mu.
+func (t *Tracker) save() error {
+	days := make([]*Day, 0, len(t.days))
+	for _, day := range t.days {
+		days = append(days, day)
+	}
+	sort.Slice(days, func(i, j int) bool {
+		return days[i].Date < days[j].Date
+	})
+
+	data, err := json.MarshalIndent(days, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal usage statistics: %w", err)
+	}
+
+	// Write to a temporary file first so a crash can't leave a truncated file
+	tmpPath := t.path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write usage statistics: %w", err)
+	}
+	if err := os.Rename(tmpPath, t.path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to replace usage statistics: %w", err)
+	}
+	return nil
+}
+
+// copyCounts returns a copy of a count map
+func copyCounts(counts map[string]int) map[string]int {
+	c := make(map[string]int, len(counts))
+	for k, v := range counts {
+		c[k] = v
+	}
+	return c
+}
+
+// sortedKeys returns the keys of a set in order
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for k := range set {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,132 @@
+package analytics
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func newTestTracker(t *testing.T) *Tracker {
+	t.Helper()
+	tracker, err := NewTracker(filepath.Join(t.TempDir(), "analytics.json"))
+	if err != nil {
+		t.Fatalf("NewTracker failed: %v", err)
+	}
+	tracker.now = func() time.Time {
+		return time.Date(2025, 3, 1, 10, 0, 0, 0, time.Local)
+	}
+	return tracker
+}
+
+func TestNothingRecordedWithoutOptIn(t *testing.T) {
+	tracker := newTestTracker(t)
+
+	if err := tracker.RecordSession(time.Minute, "tiny"); err != nil {
+		t.Fatalf("RecordSession failed: %v", err)
+	}
+	if err := tracker.RecordFeature(FeatureCopy); err != nil {
+		t.Fatalf("RecordFeature failed: %v", err)
+	}
+
+	if days := tracker.Days(); len(days) != 0 {
+		t.Errorf("Expected no statistics, got %+v", days)
+	}
+	if _, err := os.Stat(tracker.path); !os.IsNotExist(err) {
+		t.Error("Expected no statistics file to be written")
+	}
+}
+
+func TestRecordAndReload(t *testing.T) {
+	tracker := newTestTracker(t)
+	tracker.SetOptions(Options{Sessions: true, Features: true})
+
+	tracker.RecordSession(90*time.Second, "tiny")
+	tracker.RecordSession(30*time.Second, "small")
+	tracker.RecordFeature(FeatureCopy)
+	tracker.RecordFeature(FeatureCopy)
+
+	reloaded, err := NewTracker(tracker.path)
+	if err != nil {
+		t.Fatalf("NewTracker failed: %v", err)
+	}
+	days := reloaded.Days()
+	if len(days) != 1 {
+		t.Fatalf("Expected 1 day, got %d", len(days))
+	}
+	day := days[0]
+	if day.Date != "2025-03-01" || day.Sessions != 2 || day.Minutes() != 2 {
+		t.Errorf("Unexpected day: %+v", day)
+	}
+	if day.Models["tiny"] != 1 || day.Models["small"] != 1 || day.Features[FeatureCopy] != 2 {
+		t.Errorf("Unexpected counts: %+v", day)
+	}
+}
+
+func TestOptionsAreSeparate(t *testing.T) {
+	tracker := newTestTracker(t)
+	tracker.SetOptions(Options{Features: true})
+
+	tracker.RecordSession(time.Minute, "tiny")
+	tracker.RecordFeature(FeatureExport)
+
+	days := tracker.Days()
+	if len(days) != 1 || days[0].Sessions != 0 || days[0].Features[FeatureExport] != 1 {
+		t.Errorf("Expected only feature statistics, got %+v", days)
+	}
+}
+
+func TestClear(t *testing.T) {
+	tracker := newTestTracker(t)
+	tracker.SetOptions(Options{Sessions: true})
+	tracker.RecordSession(time.Minute, "tiny")
+
+	if err := tracker.Clear(); err != nil {
+		t.Fatalf("Clear failed: %v", err)
+	}
+	if days := tracker.Days(); len(days) != 0 {
+		t.Errorf("Expected no statistics after Clear, got %+v", days)
+	}
+	if _, err := os.Stat(tracker.path); !os.IsNotExist(err) {
+		t.Error("Expected the statistics file to be deleted")
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	days := []Day{
+		{Date: "2025-03-01", Sessions: 2, Seconds: 90, Models: map[string]int{"tiny": 2}},
+		{Date: "2025-03-02", Sessions: 1, Seconds: 60, Models: map[string]int{"small": 1},
+			Features: map[string]int{FeatureCopy: 3}},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, days); err != nil {
+		t.Fatalf("WriteCSV failed: %v", err)
+	}
+
+	expected := "date,sessions,minutes,model: small,model: tiny,feature: copy\n" +
+		"2025-03-01,2,1.5,0,2,0\n" +
+		"2025-03-02,1,1.0,1,0,3\n"
+	if buf.String() != expected {
+		t.Errorf("Unexpected CSV:\n%s", buf.String())
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	days := []Day{
+		{Date: "2025-02-01", Sessions: 1, Seconds: 60, Models: map[string]int{"tiny": 1}},
+		{Date: "2025-03-01", Features: map[string]int{FeatureCopy: 1}},
+		{Date: "2025-03-02", Sessions: 2, Seconds: 180, Models: map[string]int{"tiny": 2}},
+	}
+
+	all := Summarize(days)
+	if all.ActiveDays != 2 || all.Sessions != 3 || all.Minutes != 4 || all.Models["tiny"] != 3 || all.Features[FeatureCopy] != 1 {
+		t.Errorf("Unexpected summary: %+v", all)
+	}
+
+	recent := Summarize(Since(days, time.Date(2025, 3, 1, 12, 0, 0, 0, time.Local)))
+	if recent.Sessions != 2 || recent.Features[FeatureCopy] != 1 {
+		t.Errorf("Unexpected recent summary: %+v", recent)
+	}
+}
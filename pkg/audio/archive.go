@@ -0,0 +1,277 @@
+package audio
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	logger "github.com/jeff-barlow-spady/ramble/pkg/logger"
+)
+
+// archiveFlushInterval is how often buffered audio is written to the archive
+// file, to avoid a disk write per audio callback
+const archiveFlushInterval = time.Second
+
+// Archiver saves the audio of each recording as a WAV or FLAC file. It stores
+// what the transcriber heard, 16kHz mono after input gain, so archived
+// recordings can be re-transcribed; the device's original rate and channels
+// are not kept.
+// Write is cheap enough to call from the PortAudio callback: samples go into
+// a ring buffer that a background goroutine flushes to disk.
+type Archiver struct {
+	dir     string
+	ring    *RingBuffer
+	active  atomic.Bool
+	path    string // File for the current recording
+	written int    // Samples written to the current file
+	format  string // ArchiveWAV or ArchiveFLAC
+	stop    chan struct{}
+	done    chan struct{}
+	mu      sync.Mutex
+}
+
+// NewArchiver creates an archiver that stores recordings in dir
+func NewArchiver(dir string) (*Archiver, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create audio archive directory: %w", err)
+	}
+	return &Archiver{
+		dir:    dir,
+		ring:   NewRingBuffer(16000 * defaultRingSeconds),
+		format: ArchiveWAV,
+	}, nil
+}
+
+// SetFormat sets the format of recordings archived from now on: ArchiveWAV
+// or ArchiveFLAC. A recording in progress keeps its format.
+func (a *Archiver) SetFormat(format string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if format != ArchiveFLAC {
+		format = ArchiveWAV
+	}
+	a.format = format
+}
+
+// Begin starts archiving a new recording
+func (a *Archiver) Begin() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.active.Load() {
+		return fmt.Errorf("audio archive already recording")
+	}
+
+	a.ring.Reset()
+	if err := a.start(); err != nil {
+		return err
+	}
+	a.active.Store(true)
+	return nil
+}
+
+// Rotate finishes the current recording and continues archiving into a new
+// file, keeping audio captured in between. It returns the finished file's
+// path, or an empty path if it holds no audio.
+func (a *Archiver) Rotate() (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if !a.active.Load() {
+		return "", fmt.Errorf("audio archive is not recording")
+	}
+
+	// Flush the finished file; Write keeps queueing for the next one
+	close(a.stop)
+	<-a.done
+	finished, written := a.path, a.written
+
+	if err := a.start(); err != nil {
+		// Nothing is flushing any more; Begin starts afresh
+		a.active.Store(false)
+		a.stop, a.done = nil, nil
+		return finished, err
+	}
+
+	if written == 0 {
+		os.Remove(finished)
+		return "", nil
+	}
+	return finished, nil
+}
+
+// start creates a new archive file and begins flushing queued audio to it.
+// The caller must hold a.mu.
+func (a *Archiver) start() error {
+	name := "recording-" + time.Now().Format("20060102-150405")
+	path := filepath.Join(a.dir, name+"."+a.format)
+	for i := 2; fileExists(path); i++ {
+		path = filepath.Join(a.dir, fmt.Sprintf("%s-%d.%s", name, i, a.format))
+	}
+
+	w, err := createRecording(path)
+	if err != nil {
+		return err
+	}
+
+	a.path = path
+	a.written = 0
+	a.stop = make(chan struct{})
+	a.done = make(chan struct{})
+
+	go a.run(w, a.stop, a.done)
+	return nil
+}
+
+// fileExists reports whether a file exists at path
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// Write queues samples for the current recording
+func (a *Archiver) Write(samples []float32) {
+	if a.active.Load() {
+		a.ring.Write(samples)
+	}
+}
+
+// End finishes the current recording and returns the archived file path.
+// An empty path is returned if no audio was captured.
+func (a *Archiver) End() (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if !a.active.Load() || a.stop == nil {
+		return "", nil
+	}
+	a.active.Store(false)
+
+	// Wait for the remaining audio to be flushed
+	close(a.stop)
+	<-a.done
+
+	if dropped := a.ring.Dropped(); dropped > 0 {
+		logger.Warning(logger.CategoryAudio, "Audio archive dropped %d samples", dropped)
+	}
+
+	if a.written == 0 {
+		os.Remove(a.path)
+		return "", nil
+	}
+	return a.path, nil
+}
+
+// run periodically writes buffered audio to the archive file until stop is
+// closed, then closes it. The file's header is updated with each write, so
+// all but the last second of a recording survives a crash.
+func (a *Archiver) run(w recordingWriter, stop <-chan struct{}, done chan<- struct{}) {
+	defer close(done)
+	defer func() {
+		if err := w.Close(); err != nil {
+			logger.Warning(logger.CategoryAudio, "Failed to finish audio archive: %v", err)
+		}
+	}()
+
+	ticker := time.NewTicker(archiveFlushInterval)
+	defer ticker.Stop()
+
+	// Reused for every flush
+	samples := make([]float32, a.ring.Cap())
+
+	flush := func() {
+		n := a.ring.Read(samples)
+		if n == 0 {
+			return
+		}
+		if err := w.WriteSamples(samples[:n]); err != nil {
+			logger.Warning(logger.CategoryAudio, "Failed to archive audio: %v", err)
+			return
+		}
+		a.written += n
+		if err := w.Flush(); err != nil {
+			logger.Warning(logger.CategoryAudio, "Failed to archive audio: %v", err)
+		}
+	}
+
+	for {
+		select {
+		case <-stop:
+			flush()
+			return
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
+
+// Prune deletes archived recordings older than maxAge, then the oldest
+// recordings until the archive is no larger than maxBytes.
+// A zero maxAge or maxBytes disables that limit.
+func (a *Archiver) Prune(maxAge time.Duration, maxBytes int64) error {
+	a.mu.Lock()
+	current := a.path
+	a.mu.Unlock()
+
+	entries, err := os.ReadDir(a.dir)
+	if err != nil {
+		return fmt.Errorf("failed to list audio archive: %w", err)
+	}
+
+	type archived struct {
+		path    string
+		size    int64
+		modTime time.Time
+	}
+	var files []archived
+	var total int64
+
+	for _, entry := range entries {
+		if entry.IsDir() || !isRecording(entry.Name()) {
+			continue
+		}
+		path := filepath.Join(a.dir, entry.Name())
+		if path == current {
+			continue // Never prune the recording in progress
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+
+		if maxAge > 0 && time.Since(info.ModTime()) > maxAge {
+			if err := os.Remove(path); err == nil {
+				logger.Debug(logger.CategoryAudio, "Pruned expired audio archive: %s", path)
+			}
+			continue
+		}
+
+		files = append(files, archived{path: path, size: info.Size(), modTime: info.ModTime()})
+		total += info.Size()
+	}
+
+	if maxBytes <= 0 || total <= maxBytes {
+		return nil
+	}
+
+	// Oldest first
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].modTime.Before(files[j].modTime)
+	})
+	for _, f := range files {
+		if total <= maxBytes {
+			break
+		}
+		if err := os.Remove(f.path); err == nil {
+			total -= f.size
+			logger.Debug(logger.CategoryAudio, "Pruned audio archive over size limit: %s", f.path)
+		}
+	}
+
+	return nil
+}
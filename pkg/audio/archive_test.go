@@ -0,0 +1,236 @@
+package audio
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestArchiverRecordsAudio tests that written samples end up in the archived WAV
+func TestArchiverRecordsAudio(t *testing.T) {
+	archiver, err := NewArchiver(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewArchiver failed: %v", err)
+	}
+
+	if err := archiver.Begin(); err != nil {
+		t.Fatalf("Begin failed: %v", err)
+	}
+	archiver.Write(make([]float32, 1600))
+	archiver.Write(make([]float32, 1600))
+
+	path, err := archiver.End()
+	if err != nil {
+		t.Fatalf("End failed: %v", err)
+	}
+	if path == "" {
+		t.Fatal("Expected an archived file path")
+	}
+
+	samples, err := LoadFromWav(path)
+	if err != nil {
+		t.Fatalf("Failed to load archived audio: %v", err)
+	}
+	if len(samples) != 3200 {
+		t.Errorf("Expected 3200 archived samples, got %d", len(samples))
+	}
+
+	// Writes after End are ignored
+	archiver.Write(make([]float32, 1600))
+}
+
+// TestArchiverFLAC tests that recordings can be archived compressed and
+// loaded back, and that WAV recordings are still pruned
+func TestArchiverFLAC(t *testing.T) {
+	dir := t.TempDir()
+	archiver, err := NewArchiver(dir)
+	if err != nil {
+		t.Fatalf("NewArchiver failed: %v", err)
+	}
+	archiver.SetFormat(ArchiveFLAC)
+
+	if err := archiver.Begin(); err != nil {
+		t.Fatalf("Begin failed: %v", err)
+	}
+	samples := make([]float32, 10000)
+	for i := range samples {
+		samples[i] = float32(math.Sin(float64(i) / 10))
+	}
+	archiver.Write(samples)
+
+	path, err := archiver.End()
+	if err != nil {
+		t.Fatalf("End failed: %v", err)
+	}
+	if filepath.Ext(path) != ".flac" {
+		t.Fatalf("Expected a FLAC file, got %q", path)
+	}
+	loaded, err := LoadRecording(path)
+	if err != nil {
+		t.Fatalf("Failed to load archived audio: %v", err)
+	}
+	if len(loaded) != len(samples) {
+		t.Fatalf("Expected %d archived samples, got %d", len(samples), len(loaded))
+	}
+	for i := range samples {
+		if math.Abs(float64(loaded[i]-samples[i])) > 0.001 {
+			t.Fatalf("Sample %d: expected %f, got %f", i, samples[i], loaded[i])
+		}
+	}
+
+	if err := SaveToWav(samples, filepath.Join(dir, "old.wav")); err != nil {
+		t.Fatalf("Failed to save WAV: %v", err)
+	}
+	if err := archiver.Prune(0, 1); err != nil {
+		t.Fatalf("Prune failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "old.wav")); err == nil {
+		t.Error("Expected the WAV recording to be pruned")
+	}
+}
+
+// TestArchiverDiscardsEmptyRecording tests that a recording without audio leaves no file behind
+func TestArchiverDiscardsEmptyRecording(t *testing.T) {
+	dir := t.TempDir()
+	archiver, err := NewArchiver(dir)
+	if err != nil {
+		t.Fatalf("NewArchiver failed: %v", err)
+	}
+
+	if err := archiver.Begin(); err != nil {
+		t.Fatalf("Begin failed: %v", err)
+	}
+	path, err := archiver.End()
+	if err != nil {
+		t.Fatalf("End failed: %v", err)
+	}
+	if path != "" {
+		t.Errorf("Expected no path for an empty recording, got %s", path)
+	}
+
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 0 {
+		t.Errorf("Expected empty archive directory, found %d files", len(entries))
+	}
+}
+
+// TestArchiverRotate tests that rotating splits a recording into files
+// without losing audio
+func TestArchiverRotate(t *testing.T) {
+	archiver, err := NewArchiver(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewArchiver failed: %v", err)
+	}
+
+	if err := archiver.Begin(); err != nil {
+		t.Fatalf("Begin failed: %v", err)
+	}
+	archiver.Write(make([]float32, 1600))
+
+	first, err := archiver.Rotate()
+	if err != nil {
+		t.Fatalf("Rotate failed: %v", err)
+	}
+	archiver.Write(make([]float32, 800))
+	second, err := archiver.End()
+	if err != nil {
+		t.Fatalf("End failed: %v", err)
+	}
+
+	if first == "" || second == "" || first == second {
+		t.Fatalf("Expected two archived files, got %q and %q", first, second)
+	}
+	for path, want := range map[string]int{first: 1600, second: 800} {
+		samples, err := LoadFromWav(path)
+		if err != nil {
+			t.Fatalf("Failed to load %s: %v", path, err)
+		}
+		if len(samples) != want {
+			t.Errorf("%s: expected %d samples, got %d", filepath.Base(path), want, len(samples))
+		}
+	}
+
+	if _, err := archiver.Rotate(); err == nil {
+		t.Error("Expected Rotate to fail when not recording")
+	}
+}
+
+// TestArchiverPrune tests the age and size retention limits
+// TestArchiverRotateFailure tests that a failed rotation stops archiving
+// cleanly so the archiver can be ended and used again
+func TestArchiverRotateFailure(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "archive")
+	archiver, err := NewArchiver(dir)
+	if err != nil {
+		t.Fatalf("NewArchiver failed: %v", err)
+	}
+	if err := archiver.Begin(); err != nil {
+		t.Fatalf("Begin failed: %v", err)
+	}
+
+	// The next file can't be created once the directory is replaced by a file
+	os.RemoveAll(dir)
+	if err := os.WriteFile(dir, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := archiver.Rotate(); err == nil {
+		t.Fatal("Expected Rotate to fail without an archive directory")
+	}
+	archiver.Write(make([]float32, 160))
+
+	ended := make(chan struct{})
+	go func() {
+		archiver.End()
+		close(ended)
+	}()
+	select {
+	case <-ended:
+	case <-time.After(time.Second):
+		t.Fatal("End blocked after a failed rotation")
+	}
+
+	os.Remove(dir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := archiver.Begin(); err != nil {
+		t.Fatalf("Expected Begin to work again, got %v", err)
+	}
+	archiver.End()
+}
+
+func TestArchiverPrune(t *testing.T) {
+	dir := t.TempDir()
+	archiver, err := NewArchiver(dir)
+	if err != nil {
+		t.Fatalf("NewArchiver failed: %v", err)
+	}
+
+	write := func(name string, size int, age time.Duration) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, make([]byte, size), 0644); err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+		modTime := time.Now().Add(-age)
+		os.Chtimes(path, modTime, modTime)
+		return path
+	}
+
+	expired := write("expired.wav", 10, 48*time.Hour)
+	oldest := write("oldest.wav", 100, 3*time.Hour)
+	newest := write("newest.wav", 100, time.Hour)
+	other := write("notes.txt", 1000, 48*time.Hour)
+
+	if err := archiver.Prune(24*time.Hour, 150); err != nil {
+		t.Fatalf("Prune failed: %v", err)
+	}
+
+	for path, keep := range map[string]bool{expired: false, oldest: false, newest: true, other: true} {
+		_, err := os.Stat(path)
+		if exists := err == nil; exists != keep {
+			t.Errorf("%s: expected exists=%v, got %v", filepath.Base(path), keep, exists)
+		}
+	}
+}
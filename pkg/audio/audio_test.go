@@ -87,6 +87,45 @@ func TestWavSaveLoad(t *testing.T) {
 	}
 }
 
+func TestLoadChannelsFromWav(t *testing.T) {
+	// Write interleaved samples as mono, then mark the file as stereo
+	wavPath := filepath.Join(t.TempDir(), "stereo.wav")
+	if err := SaveToWav([]float32{0.1, 0.5, 0.2, 0.6, 0.3, 0.7}, wavPath); err != nil {
+		t.Fatalf("Failed to save WAV: %v", err)
+	}
+	data, err := os.ReadFile(wavPath)
+	if err != nil {
+		t.Fatalf("Failed to read WAV: %v", err)
+	}
+	data[22] = 2
+	if err := os.WriteFile(wavPath, data, 0644); err != nil {
+		t.Fatalf("Failed to write WAV: %v", err)
+	}
+
+	channels, sampleRate, err := LoadChannelsFromWav(wavPath)
+	if err != nil {
+		t.Fatalf("Failed to load channels: %v", err)
+	}
+	if sampleRate != 16000 {
+		t.Errorf("Expected 16000 Hz, got %d", sampleRate)
+	}
+	if len(channels) != 2 {
+		t.Fatalf("Expected 2 channels, got %d", len(channels))
+	}
+
+	expected := [][]float32{{0.1, 0.2, 0.3}, {0.5, 0.6, 0.7}}
+	for c := range expected {
+		if len(channels[c]) != len(expected[c]) {
+			t.Fatalf("Channel %d: expected %d samples, got %d", c, len(expected[c]), len(channels[c]))
+		}
+		for i, want := range expected[c] {
+			if math.Abs(float64(channels[c][i]-want)) > 0.01 {
+				t.Errorf("Channel %d sample %d: expected %f, got %f", c, i, want, channels[c][i])
+			}
+		}
+	}
+}
+
 func TestProcessDspFilters(t *testing.T) {
 	// Test a basic case
 	input := []float32{0.1, -0.2, 0.3, -0.4}
@@ -0,0 +1,64 @@
+package audio
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Backend is an audio system capture records from. PortAudio is the default;
+// the PulseAudio backend avoids PortAudio's ALSA configuration on Linux
+// desktops running PulseAudio or PipeWire.
+type Backend interface {
+	// Name identifies the backend in the config, e.g. "portaudio"
+	Name() string
+
+	// Acquire prepares the audio system for use; every Acquire needs a Release
+	Acquire() error
+	Release() error
+
+	// Refresh makes devices plugged in since Acquire visible. If the backend
+	// lost the caller's reference doing so, released is true.
+	Refresh() (released bool, err error)
+
+	// Devices returns the names of the input devices
+	Devices() ([]string, error)
+
+	// DefaultDevice returns the name of the default input device
+	DefaultDevice() (string, error)
+
+	// Open starts a mono stream on the named input device, calling callback
+	// with each buffer of up to framesPerBuffer samples. If the device is
+	// missing the default device is used when fallback is set; otherwise
+	// errDeviceMissing is returned. Open returns the device it opened.
+	Open(device string, fallback bool, sampleRate float64, framesPerBuffer int, callback func([]float32)) (Stream, string, error)
+}
+
+// Stream is an input stream opened by a Backend
+type Stream interface {
+	// Stop ends the stream after delivering buffered audio and closes it
+	Stop() error
+	// Abort ends the stream at once, discarding buffered audio, and closes it
+	Abort() error
+}
+
+// errDeviceMissing is returned while the recorded device hasn't come back
+var errDeviceMissing = errors.New("input device not connected")
+
+// DefaultBackend is the backend used unless another one is configured
+const DefaultBackend = "portaudio"
+
+// BackendNames are the backends NewBackend accepts
+var BackendNames = []string{"portaudio", "pulse"}
+
+// NewBackend returns the backend with the given name. An empty name selects
+// DefaultBackend.
+func NewBackend(name string) (Backend, error) {
+	switch name {
+	case "", "portaudio":
+		return portaudioBackend{}, nil
+	case "pulse":
+		return newPulseBackend(), nil
+	default:
+		return nil, fmt.Errorf("unknown audio backend %q", name)
+	}
+}
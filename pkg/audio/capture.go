@@ -5,11 +5,16 @@ import (
 	"fmt"
 	"math"
 	"sync"
+	"sync/atomic"
+	"time"
 
-	"github.com/gordonklaus/portaudio"
 	"github.com/jeff-barlow-spady/ramble/pkg/logger"
 )
 
+// TargetSampleRate is the sample rate of captured audio delivered to callers,
+// which is what Whisper expects
+const TargetSampleRate = 16000
+
 // Capture handles microphone recording with minimal overhead
 type Capture struct {
 	// Configuration
@@ -19,49 +24,74 @@ type Capture struct {
 	debug           bool
 
 	// Runtime state
-	stream      *portaudio.Stream
+	backend     Backend // Audio system the stream is opened on
+	stream      Stream
 	isActive    bool
 	onAudio     func([]float32)
 	audioBuffer []float32
+	ring        *RingBuffer   // Preallocated buffer drained by Read
+	resampler   *Resampler    // Converts the stream to TargetSampleRate; nil if it already is
+	suspended   bool          // Backend released while idle; reacquired by Start
+	gain        atomic.Uint32 // Input gain as float32 bits, read by the audio callback
+
+	// Recovery from a lost input device, see reconnect.go
+	device        string        // Name of the device being recorded
+	lastAudio     atomic.Int64  // When the audio callback last ran, in Unix nanoseconds
+	watchStop     chan struct{} // Closed by Stop to end the device watchdog
+	lost          bool          // The device stopped delivering audio and hasn't been reopened
+	fallback      bool          // Switch to the default device if the lost one doesn't return
+	onDeviceEvent func(DeviceEvent)
 
 	// Thread safety
 	mu sync.Mutex
 }
 
-// New creates a new audio capture instance
+// New creates a new audio capture instance that records at sampleRate through
+// PortAudio. Audio is always delivered at TargetSampleRate.
 func New(sampleRate float64, debug bool) (*Capture, error) {
+	return NewWithBackend(portaudioBackend{}, sampleRate, debug)
+}
+
+// NewWithBackend creates a new audio capture instance that records at
+// sampleRate through the given backend
+func NewWithBackend(backend Backend, sampleRate float64, debug bool) (*Capture, error) {
 	// Use reasonable defaults
 	if sampleRate <= 0 {
 		sampleRate = 16000 // 16kHz is standard for speech recognition
 	}
 
-	// Initialize PortAudio
-	err := portaudio.Initialize()
+	// Initialize the audio system
+	err := backend.Acquire()
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize audio: %w", err)
 	}
 
 	// Create the capture instance
 	capture := &Capture{
+		backend:         backend,
 		sampleRate:      sampleRate,
 		channels:        1, // Mono for speech recognition
 		framesPerBuffer: 1024,
 		debug:           debug,
 		isActive:        false,
 		audioBuffer:     make([]float32, 1024), // Pre-allocate buffer
+		ring:            NewRingBuffer(TargetSampleRate * defaultRingSeconds),
+	}
+	if int(sampleRate) != TargetSampleRate {
+		capture.resampler = NewResampler(int(sampleRate), TargetSampleRate)
 	}
+	capture.gain.Store(math.Float32bits(1))
 
 	if debug {
 		// Log audio system information
-		logger.Info(logger.CategoryAudio, "Audio system initialized: %s", portaudio.VersionText())
+		logger.Info(logger.CategoryAudio, "Audio system initialized: %s", backend.Name())
 
 		// List available devices
-		devices, err := portaudio.Devices()
+		devices, err := backend.Devices()
 		if err == nil && len(devices) > 0 {
-			logger.Info(logger.CategoryAudio, "Available audio devices:")
-			for i, dev := range devices {
-				logger.Info(logger.CategoryAudio, "[%d] %s (in: %v, out: %v)",
-					i, dev.Name, dev.MaxInputChannels > 0, dev.MaxOutputChannels > 0)
+			logger.Info(logger.CategoryAudio, "Available input devices:")
+			for i, name := range devices {
+				logger.Info(logger.CategoryAudio, "[%d] %s", i, name)
 			}
 		}
 	}
@@ -69,7 +99,91 @@ func New(sampleRate float64, debug bool) (*Capture, error) {
 	return capture, nil
 }
 
-// Start begins audio capture, calling the provided callback with audio data
+// SetQueueSeconds changes how many seconds of audio are buffered for Read
+// before the oldest samples are dropped. It can only be called while capture
+// is stopped.
+func (c *Capture) SetQueueSeconds(seconds float64) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.isActive {
+		return fmt.Errorf("cannot resize audio queue while capture is active")
+	}
+	if seconds <= 0 {
+		return fmt.Errorf("invalid audio queue length: %v seconds", seconds)
+	}
+
+	c.ring = NewRingBuffer(int(TargetSampleRate * seconds))
+	return nil
+}
+
+// SetSampleRate changes the rate audio is recorded at, e.g. for microphones
+// that don't support 16kHz. It can only be called while capture is stopped.
+func (c *Capture) SetSampleRate(sampleRate float64) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.isActive {
+		return fmt.Errorf("cannot change sample rate while capture is active")
+	}
+	if sampleRate <= 0 {
+		return fmt.Errorf("invalid sample rate: %v Hz", sampleRate)
+	}
+
+	c.sampleRate = sampleRate
+	c.resampler = nil
+	if int(sampleRate) != TargetSampleRate {
+		c.resampler = NewResampler(int(sampleRate), TargetSampleRate)
+	}
+	return nil
+}
+
+// SetBackend switches the audio system capture records from. It can only be
+// called while capture is stopped.
+func (c *Capture) SetBackend(backend Backend) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.isActive {
+		return fmt.Errorf("cannot change audio backend while capture is active")
+	}
+	if backend.Name() == c.backend.Name() {
+		return nil
+	}
+	if err := backend.Acquire(); err != nil {
+		return fmt.Errorf("failed to initialize %s audio: %w", backend.Name(), err)
+	}
+	if !c.suspended {
+		if err := c.backend.Release(); err != nil {
+			logger.Warning(logger.CategoryAudio, "Failed to release %s audio: %v", c.backend.Name(), err)
+		}
+	}
+	c.backend = backend
+	c.suspended = false
+	return nil
+}
+
+// SetFramesPerBuffer changes how many frames the backend delivers per callback.
+// Smaller buffers lower latency but wake the callback more often. It can only
+// be called while capture is stopped.
+func (c *Capture) SetFramesPerBuffer(frames int) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.isActive {
+		return fmt.Errorf("cannot change buffer size while capture is active")
+	}
+	if frames <= 0 {
+		return fmt.Errorf("invalid buffer size: %d frames", frames)
+	}
+
+	c.framesPerBuffer = frames
+	return nil
+}
+
+// Start begins audio capture. Captured audio is buffered for Read; if
+// callback is non-nil it is also called with each buffer. The slice passed
+// to callback is owned by the backend and must not be retained.
 func (c *Capture) Start(callback func([]float32)) error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -78,32 +192,38 @@ func (c *Capture) Start(callback func([]float32)) error {
 		return fmt.Errorf("audio capture already active")
 	}
 
-	// Store the callback
-	c.onAudio = callback
-
-	// Open the default input stream
-	stream, err := portaudio.OpenDefaultStream(
-		c.channels,        // Input channels
-		0,                 // No output channels
-		c.sampleRate,      // Sample rate
-		c.framesPerBuffer, // Frames per buffer
-		c.processAudio,    // Callback
-	)
+	// Reacquire the audio system if it was released while idle
+	if c.suspended {
+		if err := c.backend.Acquire(); err != nil {
+			return fmt.Errorf("failed to initialize audio: %w", err)
+		}
+		c.suspended = false
+		logger.Debug(logger.CategoryAudio, "Audio system resumed")
+	}
 
-	if err != nil {
-		return fmt.Errorf("failed to open audio stream: %w", err)
+	// Store the callback and discard audio left over from a previous run
+	c.onAudio = callback
+	c.ring.Reset()
+	if c.resampler != nil {
+		c.resampler.Reset()
 	}
 
-	// Start streaming
-	err = stream.Start()
+	// Open and start the default input stream
+	stream, device, err := c.backend.Open("", true, c.sampleRate, c.framesPerBuffer, c.processAudio)
 	if err != nil {
-		stream.Close()
-		return fmt.Errorf("failed to start audio stream: %w", err)
+		return err
 	}
 
 	c.stream = stream
 	c.isActive = true
 
+	// Watch for the device disappearing while recording
+	c.device = device
+	c.lost = false
+	c.lastAudio.Store(time.Now().UnixNano())
+	c.watchStop = make(chan struct{})
+	go c.watchDevice(c.watchStop)
+
 	if c.debug {
 		logger.Info(logger.CategoryAudio, "Audio capture started")
 	}
@@ -116,23 +236,25 @@ func (c *Capture) Stop() error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	if !c.isActive || c.stream == nil {
+	if !c.isActive {
 		return nil
 	}
 
-	// Stop and close the stream
-	err := c.stream.Stop()
-	if err != nil {
-		return fmt.Errorf("failed to stop audio stream: %w", err)
-	}
-
-	err = c.stream.Close()
-	if err != nil {
-		return fmt.Errorf("failed to close audio stream: %w", err)
+	if c.watchStop != nil {
+		close(c.watchStop)
+		c.watchStop = nil
 	}
 
-	c.stream = nil
+	// A lost device leaves no stream to stop
 	c.isActive = false
+	c.lost = false
+	if c.stream != nil {
+		stream := c.stream
+		c.stream = nil
+		if err := stream.Stop(); err != nil {
+			return err
+		}
+	}
 
 	if c.debug {
 		logger.Info(logger.CategoryAudio, "Audio capture stopped")
@@ -141,10 +263,44 @@ func (c *Capture) Stop() error {
 	return nil
 }
 
-// Close performs cleanup, releasing PortAudio resources
+// Suspend releases the audio system while capture is idle so it can drop its
+// device connections and threads. The next Start reinitializes it.
+func (c *Capture) Suspend() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.isActive {
+		return fmt.Errorf("cannot suspend while audio capture is active")
+	}
+	if c.suspended {
+		return nil
+	}
+
+	if err := c.backend.Release(); err != nil {
+		return fmt.Errorf("failed to release audio: %w", err)
+	}
+	c.suspended = true
+	logger.Debug(logger.CategoryAudio, "Audio system suspended")
+	return nil
+}
+
+// IsSuspended returns whether the audio system has been released by Suspend
+func (c *Capture) IsSuspended() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.suspended
+}
+
+// Close performs cleanup, releasing the audio system
 func (c *Capture) Close() error {
 	c.Stop()
-	return portaudio.Terminate()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.suspended {
+		return nil
+	}
+	return c.backend.Release()
 }
 
 // IsActive returns whether audio capture is currently active
@@ -154,18 +310,87 @@ func (c *Capture) IsActive() bool {
 	return c.isActive
 }
 
+// Read copies buffered audio into samples and returns the number of samples
+// copied. It is intended for the transcription goroutine and never blocks;
+// use Ready to wait for new audio.
+func (c *Capture) Read(samples []float32) int {
+	return c.ring.Read(samples)
+}
+
+// Ready returns a channel that is signalled when new audio is available
+func (c *Capture) Ready() <-chan struct{} {
+	return c.ring.Ready()
+}
+
+// DroppedSamples returns how many samples were overwritten before being read
+func (c *Capture) DroppedSamples() uint64 {
+	return c.ring.Dropped()
+}
+
+// QueuedSeconds returns how much captured audio is waiting to be read, in seconds
+func (c *Capture) QueuedSeconds() float64 {
+	return float64(c.ring.Len()) / TargetSampleRate
+}
+
+// QueueCapacitySeconds returns how much audio the queue holds before dropping, in seconds
+func (c *Capture) QueueCapacitySeconds() float64 {
+	return float64(c.ring.Cap()) / TargetSampleRate
+}
+
+// SetGain sets the factor captured audio is multiplied by, e.g. to boost a
+// quiet microphone. It is limited to the range from 0 to MaxGain and takes
+// effect immediately, even while capturing.
+func (c *Capture) SetGain(gain float64) {
+	gain = math.Max(0, math.Min(gain, MaxGain))
+	c.gain.Store(math.Float32bits(float32(gain)))
+}
+
+// Gain returns the input gain
+func (c *Capture) Gain() float64 {
+	return float64(math.Float32frombits(c.gain.Load()))
+}
+
+// DeviceName returns the name of the input device capture uses, or "" if it
+// can't be determined, e.g. while the audio system is suspended
+func (c *Capture) DeviceName() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Capture may have switched devices after the one it started on was lost
+	if c.isActive && c.device != "" {
+		return c.device
+	}
+	if c.suspended {
+		return ""
+	}
+	device, err := c.backend.DefaultDevice()
+	if err != nil {
+		return ""
+	}
+	return device
+}
+
 // Audio callback function
-func (c *Capture) processAudio(input, _ []float32) {
+func (c *Capture) processAudio(input []float32) {
+	c.lastAudio.Store(time.Now().UnixNano())
+	ApplyGain(input, math.Float32frombits(c.gain.Load()))
+
+	// Deliver 16kHz audio; the resampler reuses its buffers once warmed up
+	if c.resampler != nil {
+		input = c.resampler.Process(input)
+	}
+
+	// Buffer the audio for Read without allocating
+	if c.ring != nil {
+		c.ring.Write(input)
+	}
+
 	if c.onAudio == nil {
 		return
 	}
 
-	// Create a copy of the input data
-	audioData := make([]float32, len(input))
-	copy(audioData, input)
-
 	// Send the audio data to the callback
-	c.onAudio(audioData)
+	c.onAudio(input)
 }
 
 // CalculateLevel computes the RMS audio level from a buffer
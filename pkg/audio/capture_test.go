@@ -114,7 +114,7 @@ func TestAudioCallback(t *testing.T) {
 	testData := []float32{0.1, 0.2, 0.3, 0.4}
 
 	// Process the test data
-	capture.processAudio(testData, nil)
+	capture.processAudio(testData)
 
 	// Verify callback execution
 	if !callbackExecuted {
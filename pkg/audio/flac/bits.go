@@ -0,0 +1,118 @@
+package flac
+
+import (
+	"errors"
+	"math/bits"
+)
+
+// errTruncated is returned when the data ends inside a frame
+var errTruncated = errors.New("FLAC data ends early")
+
+// bitWriter packs values most significant bit first into a byte slice
+type bitWriter struct {
+	buf []byte
+	acc uint64
+	n   uint // Bits waiting in acc, always fewer than 8 between calls
+}
+
+// write appends the low count bits of v; count is at most 32
+func (w *bitWriter) write(v uint64, count uint) {
+	if count == 0 {
+		return
+	}
+	w.acc = w.acc<<count | v&(1<<count-1)
+	w.n += count
+	for w.n >= 8 {
+		w.n -= 8
+		w.buf = append(w.buf, byte(w.acc>>w.n))
+	}
+}
+
+// writeSigned appends v as a two's complement number of count bits
+func (w *bitWriter) writeSigned(v int64, count uint) {
+	w.write(uint64(v), count)
+}
+
+// writeUnary appends q zeros followed by a one
+func (w *bitWriter) writeUnary(q uint64) {
+	for ; q >= 32; q -= 32 {
+		w.write(0, 32)
+	}
+	w.write(1, uint(q)+1)
+}
+
+// writeRice appends u as a Rice code with parameter k
+func (w *bitWriter) writeRice(u uint64, k uint) {
+	w.writeUnary(u >> k)
+	w.write(u, k)
+}
+
+// align pads with zeros to a byte boundary
+func (w *bitWriter) align() {
+	if w.n > 0 {
+		w.write(0, 8-w.n)
+	}
+}
+
+// reset empties the writer for reuse
+func (w *bitWriter) reset() {
+	w.buf, w.acc, w.n = w.buf[:0], 0, 0
+}
+
+// bitReader reads values most significant bit first from a byte slice
+type bitReader struct {
+	data []byte
+	pos  int // In bits
+}
+
+// read returns the next count bits; count is at most 64
+func (r *bitReader) read(count uint) (uint64, error) {
+	if r.pos+int(count) > len(r.data)*8 {
+		return 0, errTruncated
+	}
+	var v uint64
+	for count > 0 {
+		offset := uint(r.pos & 7)
+		available := 8 - offset
+		take := min(available, count)
+		v = v<<take | uint64(r.data[r.pos>>3])>>(available-take)&(1<<take-1)
+		count -= take
+		r.pos += int(take)
+	}
+	return v, nil
+}
+
+// readSigned returns the next count bits as a two's complement number
+func (r *bitReader) readSigned(count uint) (int64, error) {
+	v, err := r.read(count)
+	if err != nil || count == 0 {
+		return 0, err
+	}
+	return int64(v<<(64-count)) >> (64 - count), nil
+}
+
+// readUnary returns the number of zeros before the next one
+func (r *bitReader) readUnary() (uint64, error) {
+	var q uint64
+	for {
+		i := r.pos >> 3
+		if i >= len(r.data) {
+			return 0, errTruncated
+		}
+		offset := r.pos & 7
+		b := r.data[i] << offset
+		if b == 0 {
+			q += uint64(8 - offset)
+			r.pos += 8 - offset
+			continue
+		}
+		zeros := bits.LeadingZeros8(b)
+		r.pos += zeros + 1
+		return q + uint64(zeros), nil
+	}
+}
+
+// align skips to the next byte boundary
+func (r *bitReader) align() {
+	r.pos = (r.pos + 7) &^ 7
+}
@@ -0,0 +1,80 @@
+// Package flac reads and writes FLAC files, a lossless compression of PCM
+// audio that stores recorded speech in about half the space of a WAV file.
+// The writer uses FLAC's fixed predictors with independent channels, which
+// is quick and close to the best FLAC can do for 16kHz speech; the reader
+// decodes any FLAC stream, including those from other encoders.
+package flac
+
+// Format describes the samples of a FLAC stream
+type Format struct {
+	Channels      int
+	SampleRate    int
+	BitsPerSample int
+}
+
+// blockSize is the number of samples per channel in each frame written
+const blockSize = 4096
+
+// Limits on streams read, so a corrupt file can't make the reader allocate
+// without bound
+const (
+	maxChannels = 8
+	maxSamples  = 1 << 28 // Per channel; over four hours at 16kHz
+)
+
+// frameSync starts every frame header
+const frameSync = 0x3FFE
+
+// Sample rates with a code of their own in frame headers; other rates refer
+// to the stream info
+var sampleRateCodes = map[int]uint64{
+	88200: 1, 176400: 2, 192000: 3, 8000: 4, 16000: 5, 22050: 6,
+	24000: 7, 32000: 8, 44100: 9, 48000: 10, 96000: 11,
+}
+
+// sampleSizes are the sample sizes of frame header codes; 0 refers to the
+// stream info and -1 is reserved
+var sampleSizes = [8]int{0, 8, 12, -1, 16, 20, 24, 32}
+
+var crc8Table, crc16Table = crcTables()
+
+// crcTables builds the tables of FLAC's CRC-8 (polynomial 0x07), which
+// guards frame headers, and CRC-16 (polynomial 0x8005), which guards frames
+func crcTables() (t8 [256]uint8, t16 [256]uint16) {
+	for i := 0; i < 256; i++ {
+		c8 := uint8(i)
+		c16 := uint16(i) << 8
+		for j := 0; j < 8; j++ {
+			if c8&0x80 != 0 {
+				c8 = c8<<1 ^ 0x07
+			} else {
+				c8 <<= 1
+			}
+			if c16&0x8000 != 0 {
+				c16 = c16<<1 ^ 0x8005
+			} else {
+				c16 <<= 1
+			}
+		}
+		t8[i], t16[i] = c8, c16
+	}
+	return t8, t16
+}
+
+// crc8 returns the CRC-8 of data
+func crc8(data []byte) uint8 {
+	var crc uint8
+	for _, b := range data {
+		crc = crc8Table[crc^b]
+	}
+	return crc
+}
+
+// crc16 returns the CRC-16 of data
+func crc16(data []byte) uint16 {
+	var crc uint16
+	for _, b := range data {
+		crc = crc<<8 ^ crc16Table[byte(crc>>8)^b]
+	}
+	return crc
+}
@@ -0,0 +1,267 @@
+package flac
+
+import (
+	"bytes"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// speech returns a few harmonics of a wandering pitch with a little noise
+func speech(n int, sampleRate int) []float32 {
+	random := rand.New(rand.NewSource(1))
+	samples := make([]float32, n)
+	phase := 0.0
+	for i := range samples {
+		pitch := 150 + 50*math.Sin(float64(i)/float64(sampleRate))
+		phase += 2 * math.Pi * pitch / float64(sampleRate)
+		v := 0.3*math.Sin(phase) + 0.1*math.Sin(2*phase) + 0.05*math.Sin(3*phase)
+		samples[i] = float32(v + 0.002*random.NormFloat64())
+	}
+	return samples
+}
+
+func TestWriterRoundTrip(t *testing.T) {
+	formats := []Format{
+		{Channels: 1, SampleRate: 16000, BitsPerSample: 16},
+		{Channels: 2, SampleRate: 44100, BitsPerSample: 16},
+		{Channels: 1, SampleRate: 11025, BitsPerSample: 8},
+		{Channels: 3, SampleRate: 48000, BitsPerSample: 24},
+	}
+	for _, format := range formats {
+		// Not a whole number of blocks, with silence that codes as constant
+		samples := speech(3*blockSize*format.Channels+123, format.SampleRate)
+		for i := 0; i < blockSize*format.Channels; i++ {
+			samples[i] = 0
+		}
+
+		path := filepath.Join(t.TempDir(), "round.flac")
+		w, err := Create(path, format)
+		if err != nil {
+			t.Fatalf("Failed to create %+v: %v", format, err)
+		}
+		if err := w.WriteSamples(samples[:1000*format.Channels]); err != nil {
+			t.Fatalf("Failed to write: %v", err)
+		}
+		if err := w.WriteSamples(samples[1000*format.Channels:]); err != nil {
+			t.Fatalf("Failed to write: %v", err)
+		}
+		if err := w.Close(); err != nil {
+			t.Fatalf("Failed to close: %v", err)
+		}
+
+		channels, got, err := ReadFile(path)
+		if err != nil {
+			t.Fatalf("Failed to read %+v back: %v", format, err)
+		}
+		if got != format {
+			t.Errorf("Expected %+v, got %+v", format, got)
+		}
+		frames := (len(samples) + format.Channels - 1) / format.Channels
+		if len(channels) != format.Channels || len(channels[0]) != frames {
+			t.Fatalf("Expected %d channels of %d samples, got %d of %d", format.Channels, frames, len(channels), len(channels[0]))
+		}
+
+		// Lossless apart from quantizing to the sample size
+		step := 2 / float64(int(1)<<(format.BitsPerSample-1))
+		for i, want := range samples {
+			v := channels[i%format.Channels][i/format.Channels]
+			if math.Abs(float64(v-want)) > step {
+				t.Fatalf("%+v sample %d: expected %f, got %f", format, i, want, v)
+			}
+		}
+	}
+}
+
+func TestWriterCompresses(t *testing.T) {
+	samples := speech(16000*10, 16000)
+	var out seekBuffer
+	w, err := NewWriter(&out, Format{Channels: 1, SampleRate: 16000, BitsPerSample: 16})
+	if err != nil {
+		t.Fatalf("Failed to create: %v", err)
+	}
+	w.WriteSamples(samples)
+	if err := w.Close(); err != nil {
+		t.Fatalf("Failed to close: %v", err)
+	}
+
+	if raw := len(samples) * 2; len(out.data) > raw*2/3 {
+		t.Errorf("Expected under two thirds of the %d bytes of 16-bit samples, got %d", raw, len(out.data))
+	}
+}
+
+func TestWriterUnflushed(t *testing.T) {
+	// A writer that never updated its stream info, as after a crash, leaves
+	// the length unknown; the frames that reached the file are still read
+	path := filepath.Join(t.TempDir(), "crash.flac")
+	w, err := Create(path, Format{Channels: 1, SampleRate: 16000, BitsPerSample: 16})
+	if err != nil {
+		t.Fatalf("Failed to create: %v", err)
+	}
+	w.WriteSamples(speech(3*blockSize+100, 16000))
+	w.buffer.Flush()
+	w.file.Close()
+
+	// Cut the last frame short too
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read: %v", err)
+	}
+	data = append(data, 0xFF, 0xF8, 0x69)
+
+	channels, _, err := Read(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Failed to read: %v", err)
+	}
+	if len(channels[0]) != 3*blockSize {
+		t.Errorf("Expected the 3 whole blocks, got %d samples", len(channels[0]))
+	}
+}
+
+func TestReadStereoAndLPC(t *testing.T) {
+	// A frame as other encoders write it: mid/side stereo, the mid channel
+	// predicted by LPC with wasted bits and the side channel verbatim
+	left := []int64{100, 104, 110, 118, 128, 140}
+	right := []int64{100, 100, 106, 110, 124, 136}
+
+	var b bitWriter
+	b.buf = append(b.buf, "fLaC"...)
+	b.write(1, 1)
+	b.write(0, 7)
+	b.write(34, 24)
+	b.write(192, 16)
+	b.write(192, 16)
+	b.write(0, 48)
+	b.write(8000, 20)
+	b.write(1, 3)
+	b.write(15, 5)
+	b.write(0, 36)
+	b.buf = append(b.buf, make([]byte, 16)...)
+
+	start := len(b.buf)
+	b.write(frameSync, 14)
+	b.write(0, 2)
+	b.write(6, 4)  // 8-bit block size
+	b.write(4, 4)  // 8kHz
+	b.write(10, 4) // Mid and side
+	b.write(4, 3)  // 16 bits
+	b.write(0, 1)
+	b.write(0, 8) // Frame 0
+	b.write(uint64(len(left)-1), 8)
+	b.buf = append(b.buf, crc8(b.buf[start:]))
+
+	// Mid, even throughout so with one wasted bit
+	mid := make([]int64, len(left))
+	for i := range left {
+		mid[i] = (left[i] + right[i]) >> 1
+	}
+	b.write(0, 1)
+	b.write(32, 6) // LPC, order 1
+	b.write(1, 1)
+	b.write(1, 1) // One wasted bit
+	b.writeSigned(mid[0]>>1, 15)
+	b.write(3, 4)       // 4-bit coefficients
+	b.writeSigned(0, 5) // No shift
+	b.writeSigned(1, 4) // Predict the previous sample
+	b.write(0, 2)       // 4-bit Rice parameters
+	b.write(0, 4)       // One partition
+	b.write(2, 4)       // Rice parameter
+	for i := 1; i < len(mid); i++ {
+		b.writeRice(zigzag(mid[i]>>1-mid[i-1]>>1), 2)
+	}
+
+	// Side, verbatim in 17 bits
+	b.write(1<<1, 8)
+	for i := range left {
+		b.writeSigned(left[i]-right[i], 17)
+	}
+	b.align()
+	crc := crc16(b.buf[start:])
+	b.buf = append(b.buf, byte(crc>>8), byte(crc))
+
+	channels, format, err := Read(bytes.NewReader(b.buf))
+	if err != nil {
+		t.Fatalf("Failed to read: %v", err)
+	}
+	if format != (Format{Channels: 2, SampleRate: 8000, BitsPerSample: 16}) {
+		t.Errorf("Expected stereo 16-bit at 8kHz, got %+v", format)
+	}
+	for i := range left {
+		l, r := int64(channels[0][i]*32768), int64(channels[1][i]*32768)
+		if l != left[i] || r != right[i] {
+			t.Errorf("Sample %d: expected %d/%d, got %d/%d", i, left[i], right[i], l, r)
+		}
+	}
+
+	// A damaged frame fails its checksum
+	b.buf[len(b.buf)-5] ^= 0x10
+	if _, _, err := Read(bytes.NewReader(b.buf)); err == nil {
+		t.Error("Expected a corrupt frame to be reported")
+	}
+}
+
+func TestReadInvalid(t *testing.T) {
+	for _, input := range [][]byte{
+		nil,
+		[]byte("RIFF....WAVE"),
+		[]byte("fLaC"),
+		[]byte("fLaC\x80\x00\x00\x22"),
+		append([]byte("fLaC\x01\x00\x00\x04"), make([]byte, 4)...),
+	} {
+		if _, _, err := Read(bytes.NewReader(input)); err == nil {
+			t.Errorf("Expected an error for %q", input)
+		}
+	}
+}
+
+func FuzzRead(f *testing.F) {
+	var out seekBuffer
+	w, _ := NewWriter(&out, Format{Channels: 2, SampleRate: 16000, BitsPerSample: 16})
+	w.WriteSamples(speech(600, 16000))
+	w.Close()
+	f.Add(out.data)
+	f.Add([]byte("fLaC\x80\x00\x00\x22"))
+
+	f.Fuzz(func(t *testing.T, input []byte) {
+		channels, format, err := Read(bytes.NewReader(input))
+		if err != nil {
+			return
+		}
+		if len(channels) != format.Channels {
+			t.Fatalf("Expected %d channels, got %d", format.Channels, len(channels))
+		}
+		for _, channel := range channels {
+			if len(channel) != len(channels[0]) {
+				t.Fatal("Expected channels of equal length")
+			}
+		}
+	})
+}
+
+// seekBuffer is an in-memory io.WriteSeeker
+type seekBuffer struct {
+	data []byte
+	pos  int
+}
+
+func (s *seekBuffer) Write(p []byte) (int, error) {
+	if end := s.pos + len(p); end > len(s.data) {
+		s.data = append(s.data, make([]byte, end-len(s.data))...)
+	}
+	copy(s.data[s.pos:], p)
+	s.pos += len(p)
+	return len(p), nil
+}
+
+func (s *seekBuffer) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case 1:
+		offset += int64(s.pos)
+	case 2:
+		offset += int64(len(s.data))
+	}
+	s.pos = int(offset)
+	return offset, nil
+}
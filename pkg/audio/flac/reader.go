@@ -0,0 +1,480 @@
+package flac
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/bits"
+	"os"
+)
+
+// ErrNotFLAC is returned for data that isn't a FLAC stream
+var ErrNotFLAC = errors.New("not a valid FLAC file")
+
+// errCorrupt is returned for frames that fail to decode or their checksum
+var errCorrupt = errors.New("corrupt FLAC frame")
+
+// ReadFile reads the FLAC file at path; see Read
+func ReadFile(path string) ([][]float32, Format, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, Format{}, fmt.Errorf("failed to open FLAC file: %w", err)
+	}
+	defer file.Close()
+	return Read(file)
+}
+
+// Read decodes a FLAC stream, returning each channel's samples scaled to
+// [-1, 1]. A stream cut off mid-frame, as left by a recorder that stopped
+// early, returns the frames before the cut, and data after the last frame,
+// such as an ID3v1 tag, is ignored.
+func Read(r io.Reader) ([][]float32, Format, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, Format{}, fmt.Errorf("failed to read FLAC file: %w", err)
+	}
+	data = skipID3(data)
+	if len(data) < 4 || string(data[0:4]) != "fLaC" {
+		return nil, Format{}, ErrNotFLAC
+	}
+
+	format, total, offset, err := readMetadata(data)
+	if err != nil {
+		return nil, format, err
+	}
+
+	// Speech compresses to about a byte a sample, so the data's size bounds
+	// the space reserved for a stream's stated length
+	channels := make([][]float32, format.Channels)
+	for c := range channels {
+		channels[c] = make([]float32, 0, min(total, len(data)))
+	}
+
+	reader := bitReader{data: data, pos: offset * 8}
+	var block [maxChannels][]int64
+	for reader.pos < len(data)*8 {
+		n, bps, err := decodeFrame(&reader, format, &block)
+		if errors.Is(err, errTruncated) || errors.Is(err, errNoFrame) {
+			break
+		}
+		if err != nil {
+			return nil, format, err
+		}
+		if len(channels[0])+n > maxSamples {
+			return nil, format, errors.New("FLAC stream is too long")
+		}
+
+		scale := 1 / float32(uint64(1)<<(bps-1))
+		for c := range channels {
+			for _, v := range block[c][:n] {
+				channels[c] = append(channels[c], float32(v)*scale)
+			}
+		}
+	}
+	return channels, format, nil
+}
+
+// skipID3 strips an ID3v2 tag, which some taggers put before the stream
+func skipID3(data []byte) []byte {
+	if len(data) < 10 || string(data[0:3]) != "ID3" {
+		return data
+	}
+	// The size is stored in 7 bits of each byte
+	size := 10 + int(data[6]&0x7F)<<21 | int(data[7]&0x7F)<<14 | int(data[8]&0x7F)<<7 | int(data[9]&0x7F)
+	if data[5]&0x10 != 0 {
+		size += 10 // Footer
+	}
+	return data[min(size, len(data)):]
+}
+
+// readMetadata parses the stream info and skips the other metadata blocks,
+// returning the offset of the first frame. A total of 0 is unknown.
+func readMetadata(data []byte) (format Format, total int, offset int, err error) {
+	offset = 4
+	for first := true; ; first = false {
+		if offset+4 > len(data) {
+			return format, 0, 0, errors.New("FLAC file has no audio")
+		}
+		last := data[offset]&0x80 != 0
+		kind := data[offset] & 0x7F
+		length := int(data[offset+1])<<16 | int(data[offset+2])<<8 | int(data[offset+3])
+		offset += 4
+		if offset+length > len(data) {
+			return format, 0, 0, errors.New("FLAC metadata is truncated")
+		}
+
+		if first {
+			if kind != 0 || length < 34 {
+				return format, 0, 0, errors.New("FLAC file has no stream info")
+			}
+			info := data[offset:]
+			packed := binary.BigEndian.Uint64(info[10:18])
+			format = Format{
+				SampleRate:    int(packed >> 44),
+				Channels:      int(packed>>41&0x7) + 1,
+				BitsPerSample: int(packed>>36&0x1F) + 1,
+			}
+			total = int(packed & (1<<36 - 1))
+			if format.SampleRate == 0 || format.BitsPerSample < 4 {
+				return format, 0, 0, fmt.Errorf("unsupported FLAC format: %d bits at %d Hz", format.BitsPerSample, format.SampleRate)
+			}
+		}
+
+		offset += length
+		if last {
+			return format, total, offset, nil
+		}
+	}
+}
+
+// errNoFrame is returned where a frame should start but doesn't
+var errNoFrame = errors.New("no FLAC frame")
+
+// decodeFrame decodes the frame at r into block, one slice per channel,
+// returning the samples per channel and their size in bits
+func decodeFrame(r *bitReader, format Format, block *[maxChannels][]int64) (int, int, error) {
+	start := r.pos / 8
+	if sync, err := r.read(14); err != nil || sync != frameSync {
+		return 0, 0, errNoFrame
+	}
+	header, err := r.read(18)
+	if err != nil {
+		return 0, 0, err
+	}
+	if header>>17 != 0 || header&1 != 0 {
+		return 0, 0, errCorrupt // Reserved bits
+	}
+	sizeCode := header >> 12 & 0xF
+	rateCode := header >> 8 & 0xF
+	assignment := int(header >> 4 & 0xF)
+	bps := sampleSizes[header>>1&0x7]
+
+	// The frame or sample number isn't needed, only skipped
+	if err := skipNumber(r); err != nil {
+		return 0, 0, err
+	}
+
+	var n int
+	switch {
+	case sizeCode == 0:
+		return 0, 0, errCorrupt
+	case sizeCode == 1:
+		n = 192
+	case sizeCode <= 5:
+		n = 576 << (sizeCode - 2)
+	case sizeCode == 6:
+		v, err := r.read(8)
+		if err != nil {
+			return 0, 0, err
+		}
+		n = int(v) + 1
+	case sizeCode == 7:
+		v, err := r.read(16)
+		if err != nil {
+			return 0, 0, err
+		}
+		n = int(v) + 1
+	default:
+		n = 256 << (sizeCode - 8)
+	}
+
+	switch rateCode {
+	case 12:
+		_, err = r.read(8)
+	case 13, 14:
+		_, err = r.read(16)
+	case 15:
+		return 0, 0, errCorrupt
+	}
+	if err != nil {
+		return 0, 0, err
+	}
+
+	crc, err := r.read(8)
+	if err != nil {
+		return 0, 0, err
+	}
+	if uint8(crc) != crc8(r.data[start:r.pos/8-1]) {
+		return 0, 0, errCorrupt
+	}
+
+	channels := assignment + 1
+	if assignment > 7 {
+		channels = 2
+	}
+	if assignment > 10 || channels != format.Channels || bps < 0 {
+		return 0, 0, errCorrupt
+	}
+	if bps == 0 {
+		bps = format.BitsPerSample
+	}
+
+	for c := 0; c < channels; c++ {
+		// The side channel of a pair has an extra bit
+		size := bps
+		if assignment == 8 && c == 1 || assignment == 9 && c == 0 || assignment == 10 && c == 1 {
+			size++
+		}
+		if cap(block[c]) < n {
+			block[c] = make([]int64, n)
+		}
+		block[c] = block[c][:n]
+		if err := decodeSubframe(r, block[c], size); err != nil {
+			return 0, 0, err
+		}
+	}
+
+	r.align()
+	end := r.pos / 8
+	check, err := r.read(16)
+	if err != nil {
+		return 0, 0, err
+	}
+	if uint16(check) != crc16(r.data[start:end]) {
+		return 0, 0, errCorrupt
+	}
+
+	first, second := block[0], block[1]
+	switch assignment {
+	case 8: // Left and side
+		for i := range second {
+			second[i] = first[i] - second[i]
+		}
+	case 9: // Side and right
+		for i := range first {
+			first[i] += second[i]
+		}
+	case 10: // Mid and side
+		for i := range first {
+			mid := first[i]<<1 | second[i]&1
+			first[i], second[i] = (mid+second[i])>>1, (mid-second[i])>>1
+		}
+	}
+	return n, bps, nil
+}
+
+// skipNumber skips the UTF-8 style coded frame or sample number
+func skipNumber(r *bitReader) error {
+	first, err := r.read(8)
+	if err != nil {
+		return err
+	}
+	length := bits.LeadingZeros8(^uint8(first))
+	if length == 0 {
+		return nil
+	}
+	if length < 2 || length > 7 {
+		return errCorrupt
+	}
+	for i := 1; i < length; i++ {
+		b, err := r.read(8)
+		if err != nil {
+			return err
+		}
+		if b&0xC0 != 0x80 {
+			return errCorrupt
+		}
+	}
+	return nil
+}
+
+// decodeSubframe decodes one channel of a frame into samples
+func decodeSubframe(r *bitReader, samples []int64, bps int) error {
+	header, err := r.read(8)
+	if err != nil {
+		return err
+	}
+	if header&0x80 != 0 {
+		return errCorrupt
+	}
+	kind := int(header >> 1 & 0x3F)
+
+	// Wasted bits are low bits that are zero in every sample
+	wasted := 0
+	if header&1 != 0 {
+		k, err := r.readUnary()
+		if err != nil {
+			return err
+		}
+		if k+1 >= uint64(bps) {
+			return errCorrupt
+		}
+		wasted = int(k) + 1
+		bps -= wasted
+	}
+
+	switch {
+	case kind == 0: // Constant
+		v, err := r.readSigned(uint(bps))
+		if err != nil {
+			return err
+		}
+		for i := range samples {
+			samples[i] = v
+		}
+	case kind == 1: // Verbatim
+		for i := range samples {
+			if samples[i], err = r.readSigned(uint(bps)); err != nil {
+				return err
+			}
+		}
+	case kind >= 8 && kind <= 12:
+		if err := decodeFixed(r, samples, bps, kind-8); err != nil {
+			return err
+		}
+	case kind >= 32:
+		if err := decodeLPC(r, samples, bps, kind-31); err != nil {
+			return err
+		}
+	default:
+		return errCorrupt
+	}
+
+	if wasted > 0 {
+		for i := range samples {
+			samples[i] <<= wasted
+		}
+	}
+	return nil
+}
+
+// decodeFixed decodes a subframe predicted by one of FLAC's fixed polynomials
+func decodeFixed(r *bitReader, samples []int64, bps, order int) error {
+	if err := readWarmUp(r, samples, bps, order); err != nil {
+		return err
+	}
+	if err := decodeResidual(r, samples, order); err != nil {
+		return err
+	}
+
+	s := samples
+	for i := order; i < len(s); i++ {
+		switch order {
+		case 1:
+			s[i] += s[i-1]
+		case 2:
+			s[i] += 2*s[i-1] - s[i-2]
+		case 3:
+			s[i] += 3*s[i-1] - 3*s[i-2] + s[i-3]
+		case 4:
+			s[i] += 4*s[i-1] - 6*s[i-2] + 4*s[i-3] - s[i-4]
+		}
+	}
+	return nil
+}
+
+// decodeLPC decodes a subframe predicted by linear prediction coefficients
+func decodeLPC(r *bitReader, samples []int64, bps, order int) error {
+	if err := readWarmUp(r, samples, bps, order); err != nil {
+		return err
+	}
+	precision, err := r.read(4)
+	if err != nil {
+		return err
+	}
+	if precision == 15 {
+		return errCorrupt
+	}
+	shift, err := r.readSigned(5)
+	if err != nil {
+		return err
+	}
+	if shift < 0 {
+		return errCorrupt
+	}
+	coefficients := make([]int64, order)
+	for i := range coefficients {
+		if coefficients[i], err = r.readSigned(uint(precision) + 1); err != nil {
+			return err
+		}
+	}
+	if err := decodeResidual(r, samples, order); err != nil {
+		return err
+	}
+
+	for i := order; i < len(samples); i++ {
+		var prediction int64
+		for j, c := range coefficients {
+			prediction += c * samples[i-1-j]
+		}
+		samples[i] += prediction >> shift
+	}
+	return nil
+}
+
+// readWarmUp reads the unpredicted samples that start a subframe
+func readWarmUp(r *bitReader, samples []int64, bps, order int) error {
+	if order > len(samples) {
+		return errCorrupt
+	}
+	for i := 0; i < order; i++ {
+		v, err := r.readSigned(uint(bps))
+		if err != nil {
+			return err
+		}
+		samples[i] = v
+	}
+	return nil
+}
+
+// decodeResidual reads the Rice coded prediction errors into samples after
+// the warm-up
+func decodeResidual(r *bitReader, samples []int64, order int) error {
+	method, err := r.read(2)
+	if err != nil {
+		return err
+	}
+	if method > 1 {
+		return errCorrupt
+	}
+	parameterBits := uint(4 + method)
+	escape := uint64(1)<<parameterBits - 1
+
+	partitionOrder, err := r.read(4)
+	if err != nil {
+		return err
+	}
+	partitions := 1 << partitionOrder
+	size := len(samples) >> partitionOrder
+	if len(samples)%partitions != 0 || size < order {
+		return errCorrupt
+	}
+
+	i := order
+	for p := 0; p < partitions; p++ {
+		end := (p + 1) * size
+		parameter, err := r.read(parameterBits)
+		if err != nil {
+			return err
+		}
+
+		if parameter == escape {
+			// Unencoded, each sample in a given number of bits
+			raw, err := r.read(5)
+			if err != nil {
+				return err
+			}
+			for ; i < end; i++ {
+				if samples[i], err = r.readSigned(uint(raw)); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		for ; i < end; i++ {
+			q, err := r.readUnary()
+			if err != nil {
+				return err
+			}
+			low, err := r.read(uint(parameter))
+			if err != nil {
+				return err
+			}
+			u := q<<parameter | low
+			samples[i] = int64(u>>1) ^ -int64(u&1)
+		}
+	}
+	return nil
+}
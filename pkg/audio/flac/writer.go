@@ -0,0 +1,411 @@
+package flac
+
+import (
+	"bufio"
+	"crypto/md5"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+)
+
+// headerSize is the size of the stream marker and stream info written
+const headerSize = 4 + 4 + 34
+
+// maxPartitionOrder bounds the partitions of the residual tried per subframe
+const maxPartitionOrder = 6
+
+// Writer streams samples to a FLAC file. Samples are encoded a block at a
+// time as they arrive; the stream info at the start of the file is kept by
+// the writer and rewritten by Flush and Close. Until the first Flush it
+// gives the length as unknown, which decoders read as "to the last frame",
+// so a recording cut short by a crash stays readable.
+type Writer struct {
+	out     io.WriteSeeker
+	buffer  *bufio.Writer
+	file    *os.File // Closed by Close if the writer created it
+	format  Format
+	start   int64 // Offset of the stream in out
+	written int64 // Bytes of frames written
+
+	pending  [][]int32 // Samples of the block being filled, per channel
+	frames   uint64
+	samples  uint64 // Per channel, in frames written
+	minFrame int
+	maxFrame int
+	sum      hash.Hash // MD5 of the samples written, part of the stream info
+
+	bits     bitWriter
+	residual []int64
+	best     []int64
+}
+
+// Create creates the file at path, replacing any existing file, and returns
+// a writer for it
+func Create(path string, format Format) (*Writer, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create FLAC file: %w", err)
+	}
+	w, err := NewWriter(file, format)
+	if err != nil {
+		file.Close()
+		os.Remove(path)
+		return nil, err
+	}
+	w.file = file
+	return w, nil
+}
+
+// NewWriter writes a FLAC stream header to out and returns a writer for
+// samples following it. Samples may be 8, 16 or 24 bits.
+func NewWriter(out io.WriteSeeker, format Format) (*Writer, error) {
+	if format.BitsPerSample != 8 && format.BitsPerSample != 16 && format.BitsPerSample != 24 {
+		return nil, fmt.Errorf("unsupported FLAC format: %d bits per sample", format.BitsPerSample)
+	}
+	if format.Channels < 1 || format.Channels > maxChannels || format.SampleRate <= 0 || format.SampleRate >= 1<<20 {
+		return nil, fmt.Errorf("unsupported FLAC format: %d channels at %d Hz", format.Channels, format.SampleRate)
+	}
+
+	start, err := out.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, fmt.Errorf("failed to write FLAC header: %w", err)
+	}
+	w := &Writer{
+		out:      out,
+		buffer:   bufio.NewWriter(out),
+		format:   format,
+		start:    start,
+		pending:  make([][]int32, format.Channels),
+		sum:      md5.New(),
+		residual: make([]int64, blockSize),
+		best:     make([]int64, blockSize),
+	}
+	for c := range w.pending {
+		w.pending[c] = make([]int32, 0, blockSize)
+	}
+	if _, err := w.buffer.Write(w.header(false)); err != nil {
+		return nil, fmt.Errorf("failed to write FLAC header: %w", err)
+	}
+	return w, nil
+}
+
+// header returns the stream marker and stream info. Until known, the
+// length, frame sizes and checksum are left as zero, meaning unknown.
+func (w *Writer) header(known bool) []byte {
+	var b bitWriter
+	b.buf = append(b.buf, "fLaC"...)
+	b.write(1, 1) // The last metadata block
+	b.write(0, 7) // Stream info
+	b.write(34, 24)
+	b.write(blockSize, 16)
+	b.write(blockSize, 16)
+	if !known {
+		b.write(0, 24)
+		b.write(0, 24)
+		b.write(uint64(w.format.SampleRate), 20)
+		b.write(uint64(w.format.Channels-1), 3)
+		b.write(uint64(w.format.BitsPerSample-1), 5)
+		b.write(0, 4)
+		b.write(0, 32)
+		return append(b.buf, make([]byte, md5.Size)...)
+	}
+	b.write(uint64(w.minFrame), 24)
+	b.write(uint64(w.maxFrame), 24)
+	b.write(uint64(w.format.SampleRate), 20)
+	b.write(uint64(w.format.Channels-1), 3)
+	b.write(uint64(w.format.BitsPerSample-1), 5)
+	b.write(w.samples>>32, 4)
+	b.write(w.samples, 32)
+	return w.sum.Sum(b.buf)
+}
+
+// WriteSamples appends samples, interleaved if there are several channels;
+// a partial last frame is padded with silence. Samples are clamped to
+// [-1, 1].
+func (w *Writer) WriteSamples(samples []float32) error {
+	scale := float32(int32(1)<<(w.format.BitsPerSample-1) - 1)
+	channels := w.format.Channels
+	for i := 0; i < len(samples); i += channels {
+		for c := 0; c < channels; c++ {
+			var v float32
+			if i+c < len(samples) {
+				v = max(-1, min(1, samples[i+c]))
+			}
+			w.pending[c] = append(w.pending[c], int32(v*scale))
+		}
+		if len(w.pending[0]) == blockSize {
+			if err := w.writeFrame(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Flush writes the blocks filled so far and updates the stream info to
+// match, so the file is complete up to the last whole block
+func (w *Writer) Flush() error {
+	if err := w.buffer.Flush(); err != nil {
+		return fmt.Errorf("failed to write FLAC frames: %w", err)
+	}
+	if _, err := w.out.Seek(w.start, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to update FLAC header: %w", err)
+	}
+	if _, err := w.out.Write(w.header(true)); err != nil {
+		return fmt.Errorf("failed to update FLAC header: %w", err)
+	}
+	if _, err := w.out.Seek(w.start+headerSize+w.written, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to update FLAC header: %w", err)
+	}
+	return nil
+}
+
+// Close writes the last, partial block, flushes the writer and closes the
+// file if Create opened it
+func (w *Writer) Close() error {
+	var err error
+	if len(w.pending[0]) > 0 {
+		err = w.writeFrame()
+	}
+	if err == nil {
+		err = w.Flush()
+	}
+	if w.file != nil {
+		if closeErr := w.file.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
+
+// writeFrame encodes the pending samples as a frame
+func (w *Writer) writeFrame() error {
+	n := len(w.pending[0])
+	b := &w.bits
+	b.reset()
+
+	// Frame header
+	b.write(frameSync, 14)
+	b.write(0, 1) // Reserved
+	b.write(0, 1) // Fixed block size
+	switch {
+	case n == blockSize:
+		b.write(12, 4) // 256 << 4
+	case n <= 256:
+		b.write(6, 4)
+	default:
+		b.write(7, 4)
+	}
+	b.write(sampleRateCodes[w.format.SampleRate], 4)
+	b.write(uint64(w.format.Channels-1), 4) // Independent channels
+	switch w.format.BitsPerSample {
+	case 8:
+		b.write(1, 3)
+	case 16:
+		b.write(4, 3)
+	default:
+		b.write(6, 3)
+	}
+	b.write(0, 1) // Reserved
+	b.buf = appendNumber(b.buf, w.frames)
+	switch {
+	case n == blockSize:
+	case n <= 256:
+		b.write(uint64(n-1), 8)
+	default:
+		b.write(uint64(n-1), 16)
+	}
+	b.buf = append(b.buf, crc8(b.buf))
+
+	for _, samples := range w.pending {
+		w.writeSubframe(samples)
+	}
+	b.align()
+	crc := crc16(b.buf)
+	b.buf = append(b.buf, byte(crc>>8), byte(crc))
+
+	if _, err := w.buffer.Write(b.buf); err != nil {
+		return fmt.Errorf("failed to write FLAC frames: %w", err)
+	}
+
+	// The checksum covers the samples interleaved, little endian
+	size := w.format.BitsPerSample / 8
+	raw := make([]byte, 0, n*len(w.pending)*size)
+	for i := 0; i < n; i++ {
+		for _, samples := range w.pending {
+			v := samples[i]
+			for j := 0; j < size; j++ {
+				raw = append(raw, byte(v>>(8*j)))
+			}
+		}
+	}
+	w.sum.Write(raw)
+
+	if w.frames == 0 || len(b.buf) < w.minFrame {
+		w.minFrame = len(b.buf)
+	}
+	w.maxFrame = max(w.maxFrame, len(b.buf))
+	w.written += int64(len(b.buf))
+	w.samples += uint64(n)
+	w.frames++
+	for c := range w.pending {
+		w.pending[c] = w.pending[c][:0]
+	}
+	return nil
+}
+
+// appendNumber appends a frame number in FLAC's UTF-8 style coding
+func appendNumber(b []byte, v uint64) []byte {
+	if v < 0x80 {
+		return append(b, byte(v))
+	}
+	length := 2
+	for v >= 1<<(5*length+1) {
+		length++
+	}
+	b = append(b, byte(0xFF<<(8-length))|byte(v>>(6*(length-1))))
+	for i := length - 2; i >= 0; i-- {
+		b = append(b, 0x80|byte(v>>(6*i))&0x3F)
+	}
+	return b
+}
+
+// writeSubframe encodes one channel of a frame: as a constant if it never
+// changes, otherwise with whichever fixed predictor codes it smallest, or
+// verbatim if none saves space
+func (w *Writer) writeSubframe(samples []int32) {
+	b := &w.bits
+	bps := uint(w.format.BitsPerSample)
+
+	constant := true
+	for _, v := range samples[1:] {
+		if v != samples[0] {
+			constant = false
+			break
+		}
+	}
+	if constant {
+		b.write(0, 8)
+		b.writeSigned(int64(samples[0]), bps)
+		return
+	}
+
+	n := len(samples)
+	method, parameterBits := w.riceMethod()
+	bestOrder, bestCost := -1, n*int(bps)
+	for order := 0; order <= 4 && order < n; order++ {
+		residual := w.residual[:n-order]
+		for i := order; i < n; i++ {
+			residual[i-order] = predictionError(samples, i, order)
+		}
+		if _, cost := riceParameters(residual, n, order, parameterBits); cost+order*int(bps) < bestCost {
+			bestOrder, bestCost = order, cost+order*int(bps)
+			w.best, w.residual = w.residual, w.best
+		}
+	}
+
+	if bestOrder < 0 {
+		b.write(1<<1, 8) // Verbatim
+		for _, v := range samples {
+			b.writeSigned(int64(v), bps)
+		}
+		return
+	}
+
+	b.write(uint64(8+bestOrder)<<1, 8)
+	for _, v := range samples[:bestOrder] {
+		b.writeSigned(int64(v), bps)
+	}
+
+	residual := w.best[:n-bestOrder]
+	partitionOrder, _ := riceParameters(residual, n, bestOrder, parameterBits)
+	b.write(method, 2)
+	b.write(uint64(partitionOrder), 4)
+	size := n >> partitionOrder
+	for p, start := 0, 0; p < 1<<partitionOrder; p++ {
+		end := (p+1)*size - bestOrder
+		k := riceParameter(residual[start:end], parameterBits)
+		b.write(uint64(k), parameterBits)
+		for _, r := range residual[start:end] {
+			b.writeRice(zigzag(r), k)
+		}
+		start = end
+	}
+}
+
+// riceMethod returns the residual coding method and its parameter size:
+// 4 bits suffice for 16-bit audio, 24-bit audio needs 5
+func (w *Writer) riceMethod() (uint64, uint) {
+	if w.format.BitsPerSample > 16 {
+		return 1, 5
+	}
+	return 0, 4
+}
+
+// predictionError returns the error of the fixed predictor of order at i
+func predictionError(s []int32, i, order int) int64 {
+	switch order {
+	case 0:
+		return int64(s[i])
+	case 1:
+		return int64(s[i]) - int64(s[i-1])
+	case 2:
+		return int64(s[i]) - 2*int64(s[i-1]) + int64(s[i-2])
+	case 3:
+		return int64(s[i]) - 3*int64(s[i-1]) + 3*int64(s[i-2]) - int64(s[i-3])
+	default:
+		return int64(s[i]) - 4*int64(s[i-1]) + 6*int64(s[i-2]) - 4*int64(s[i-3]) + int64(s[i-4])
+	}
+}
+
+// riceParameters picks the partition order coding residual smallest,
+// returning it with the estimated size in bits. The first partition is
+// short by the predictor order, which no partition may be smaller than.
+func riceParameters(residual []int64, n, order int, parameterBits uint) (int, int) {
+	bestOrder, bestCost := 0, -1
+	for partitionOrder := 0; partitionOrder <= maxPartitionOrder; partitionOrder++ {
+		partitions := 1 << partitionOrder
+		if n%partitions != 0 || n/partitions <= order {
+			break
+		}
+		cost := 6
+		for p, start := 0, 0; p < partitions; p++ {
+			end := (p+1)*(n/partitions) - order
+			cost += int(parameterBits) + riceCost(residual[start:end], riceParameter(residual[start:end], parameterBits))
+			start = end
+		}
+		if bestCost < 0 || cost < bestCost {
+			bestOrder, bestCost = partitionOrder, cost
+		}
+	}
+	return bestOrder, bestCost
+}
+
+// riceParameter estimates the Rice parameter coding residual smallest, from
+// its mean, within what parameterBits can express
+func riceParameter(residual []int64, parameterBits uint) uint {
+	var sum uint64
+	for _, r := range residual {
+		sum += zigzag(r)
+	}
+	k := uint(0)
+	for k < 1<<parameterBits-2 && uint64(len(residual))<<(k+1) < sum {
+		k++
+	}
+	return k
+}
+
+// riceCost estimates the bits coding residual with parameter k
+func riceCost(residual []int64, k uint) int {
+	cost := len(residual) * int(k+1)
+	for _, r := range residual {
+		cost += int(zigzag(r) >> k)
+	}
+	return cost
+}
+
+// zigzag maps signed values to unsigned ones, small magnitudes first
+func zigzag(v int64) uint64 {
+	return uint64(v<<1 ^ v>>63)
+}
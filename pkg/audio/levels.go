@@ -0,0 +1,141 @@
+package audio
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// ClipLevel is the peak level at which a signal is considered clipped
+const ClipLevel = 0.99
+
+// QuietLevel is the peak level below which speech is too quiet to transcribe well
+const QuietLevel = 0.1
+
+// MaxGain is the largest input gain that can be configured
+const MaxGain = 8.0
+
+// LevelStatus diagnoses the input level
+type LevelStatus int
+
+const (
+	// LevelOK means the input level is usable
+	LevelOK LevelStatus = iota
+	// LevelClipping means the signal reached full scale and is distorted
+	LevelClipping
+	// LevelTooQuiet means the signal stayed too quiet for reliable transcription
+	LevelTooQuiet
+)
+
+// Reading is a snapshot of the input level
+type Reading struct {
+	RMS        float32 // RMS of the most recent buffer
+	Peak       float32 // Peak of the most recent buffer
+	WindowPeak float32 // Highest peak within the meter's window
+	Status     LevelStatus
+}
+
+// chunkLevel holds the levels of one buffer
+type chunkLevel struct {
+	samples int
+	rms     float32
+	peak    float32
+}
+
+// LevelMeter measures the input level over a sliding window, e.g. while the
+// user calibrates their microphone. It is safe to add audio from the audio
+// callback while reading from another goroutine.
+type LevelMeter struct {
+	window int // Samples covered by the window
+	chunks []chunkLevel
+	total  int // Samples in chunks
+	mu     sync.Mutex
+}
+
+// NewLevelMeter creates a meter for audio at sampleRate that judges the level
+// over the given window
+func NewLevelMeter(sampleRate float64, window time.Duration) *LevelMeter {
+	return &LevelMeter{window: int(sampleRate * window.Seconds())}
+}
+
+// Add measures a buffer of audio
+func (m *LevelMeter) Add(samples []float32) {
+	if len(samples) == 0 {
+		return
+	}
+	level := chunkLevel{samples: len(samples), rms: CalculateLevel(samples), peak: CalculatePeak(samples)}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.chunks = append(m.chunks, level)
+	m.total += level.samples
+	for len(m.chunks) > 1 && m.total-m.chunks[0].samples >= m.window {
+		m.total -= m.chunks[0].samples
+		m.chunks = m.chunks[1:]
+	}
+}
+
+// Reading returns the current levels and diagnosis
+func (m *LevelMeter) Reading() Reading {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var r Reading
+	if len(m.chunks) == 0 {
+		return r
+	}
+	last := m.chunks[len(m.chunks)-1]
+	r.RMS, r.Peak = last.rms, last.peak
+	for _, chunk := range m.chunks {
+		if chunk.peak > r.WindowPeak {
+			r.WindowPeak = chunk.peak
+		}
+	}
+
+	switch {
+	case r.WindowPeak >= ClipLevel:
+		r.Status = LevelClipping
+	case r.WindowPeak < QuietLevel:
+		r.Status = LevelTooQuiet
+	}
+	return r
+}
+
+// CalculatePeak returns the largest absolute sample value in a buffer
+func CalculatePeak(samples []float32) float32 {
+	var peak float32
+	for _, sample := range samples {
+		if sample < 0 {
+			sample = -sample
+		}
+		if sample > peak {
+			peak = sample
+		}
+	}
+	return peak
+}
+
+// ApplyGain multiplies samples by gain in place, limiting them to full scale
+func ApplyGain(samples []float32, gain float32) {
+	if gain == 1 {
+		return
+	}
+	for i, sample := range samples {
+		sample *= gain
+		if sample > 1 {
+			sample = 1
+		} else if sample < -1 {
+			sample = -1
+		}
+		samples[i] = sample
+	}
+}
+
+// DBFS converts a level to decibels relative to full scale, with silence at -90
+func DBFS(level float32) float64 {
+	if level <= 0 {
+		return -90
+	}
+	return math.Max(-90, 20*math.Log10(float64(level)))
+}
@@ -0,0 +1,61 @@
+package audio
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// TestLevelMeter tests that clipping and quiet input are diagnosed over the window
+func TestLevelMeter(t *testing.T) {
+	m := NewLevelMeter(16000, 300*time.Millisecond)
+	if r := m.Reading(); r.Peak != 0 || r.Status != LevelOK {
+		t.Errorf("Expected an empty reading, got %+v", r)
+	}
+
+	m.Add(chunk(0.01))
+	if r := m.Reading(); r.Status != LevelTooQuiet {
+		t.Errorf("Expected quiet input to be reported, got %+v", r)
+	}
+
+	m.Add(chunk(1))
+	m.Add(chunk(0.3))
+	r := m.Reading()
+	if r.Status != LevelClipping || r.WindowPeak != 1 || r.Peak != 0.3 {
+		t.Errorf("Expected clipping within the window, got %+v", r)
+	}
+
+	// The clipped buffer leaves the window
+	for i := 0; i < 3; i++ {
+		m.Add(chunk(0.3))
+	}
+	if r := m.Reading(); r.Status != LevelOK || r.WindowPeak != 0.3 {
+		t.Errorf("Expected a good level once clipping passed, got %+v", r)
+	}
+}
+
+// TestApplyGain tests that gain is applied and limited to full scale
+func TestApplyGain(t *testing.T) {
+	samples := []float32{0.1, -0.2, 0.6, -0.9}
+	ApplyGain(samples, 2)
+
+	want := []float32{0.2, -0.4, 1, -1}
+	for i := range want {
+		if math.Abs(float64(samples[i]-want[i])) > 1e-6 {
+			t.Errorf("Sample %d: expected %v, got %v", i, want[i], samples[i])
+		}
+	}
+	if CalculatePeak(samples) != 1 {
+		t.Errorf("Expected a peak of 1, got %v", CalculatePeak(samples))
+	}
+}
+
+// TestDBFS tests level conversion to decibels
+func TestDBFS(t *testing.T) {
+	if DBFS(1) != 0 || DBFS(0) != -90 {
+		t.Errorf("Unexpected dBFS for full scale or silence: %v, %v", DBFS(1), DBFS(0))
+	}
+	if db := DBFS(0.5); math.Abs(db+6.02) > 0.01 {
+		t.Errorf("Expected about -6 dBFS, got %v", db)
+	}
+}
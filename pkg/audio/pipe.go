@@ -0,0 +1,201 @@
+package audio
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/jeff-barlow-spady/ramble/pkg/audio/wav"
+)
+
+// ErrInputEnded is returned when opening a pipe whose audio has ended
+var ErrInputEnded = errors.New("audio input ended")
+
+// PipeBackend records from a stream of audio such as standard input, so
+// Ramble can take audio from any capture tool or a remote source without
+// PortAudio. The stream is a WAV file, whose header gives its format, or raw
+// 16-bit little endian PCM. Audio is delivered no faster than real time, so
+// a file piped in is transcribed as if it were being recorded, and the
+// stream is only read while recording.
+type PipeBackend struct {
+	device string // Name shown for the input
+	input  io.Reader
+	raw    wav.Format // Format of input without a WAV header
+
+	start   sync.Once
+	format  wav.Format     // Format of the input, set before the first buffer is sent
+	buffers chan []float32 // Mono buffers at format.SampleRate, closed when the input ends
+	err     error          // Why reading stopped, if not at the end of the input
+
+	end   sync.Once
+	ended chan struct{} // Closed once all the input has been delivered
+}
+
+// NewPipeBackend creates a backend reading input, named device. Raw PCM
+// input is taken to have the given sample rate and channel count.
+func NewPipeBackend(device string, input io.Reader, sampleRate, channels int) *PipeBackend {
+	return &PipeBackend{
+		device:  device,
+		input:   input,
+		raw:     wav.Format{Channels: channels, SampleRate: sampleRate, BitsPerSample: 16},
+		buffers: make(chan []float32, 1),
+		ended:   make(chan struct{}),
+	}
+}
+
+// Name implements Backend
+func (b *PipeBackend) Name() string { return "pipe" }
+
+// Acquire implements Backend; there is no audio system to prepare
+func (b *PipeBackend) Acquire() error { return nil }
+
+// Release implements Backend
+func (b *PipeBackend) Release() error { return nil }
+
+// Refresh implements Backend; the input never changes
+func (b *PipeBackend) Refresh() (bool, error) { return false, nil }
+
+// Devices returns the input as the only device
+func (b *PipeBackend) Devices() ([]string, error) { return []string{b.device}, nil }
+
+// DefaultDevice returns the input
+func (b *PipeBackend) DefaultDevice() (string, error) { return b.device, nil }
+
+// Open delivers the input from where the last stream stopped, starting to
+// read it on the first call
+func (b *PipeBackend) Open(name string, fallback bool, sampleRate float64, framesPerBuffer int, callback func([]float32)) (Stream, string, error) {
+	select {
+	case <-b.ended:
+		return nil, "", ErrInputEnded
+	default:
+	}
+	b.start.Do(func() { go b.read() })
+
+	s := &pipeStream{stop: make(chan struct{}), done: make(chan struct{})}
+	go func() {
+		defer close(s.done)
+		b.deliver(s.stop, int(sampleRate), framesPerBuffer, callback)
+	}()
+	return s, b.device, nil
+}
+
+// Ended is closed once all the input has been delivered
+func (b *PipeBackend) Ended() <-chan struct{} {
+	return b.ended
+}
+
+// Err returns why the input ended early, or nil if it was read to the end
+func (b *PipeBackend) Err() error {
+	select {
+	case <-b.ended:
+		return b.err
+	default:
+		return nil
+	}
+}
+
+// read decodes the input into buffers of about 50ms until it ends
+func (b *PipeBackend) read() {
+	defer close(b.buffers)
+
+	reader := bufio.NewReader(b.input)
+	format := b.raw
+	var data io.Reader = reader
+	if magic, _ := reader.Peek(4); string(magic) == "RIFF" {
+		var err error
+		if format, data, err = wav.ReadHeader(reader); err != nil {
+			b.err = err
+			return
+		}
+	}
+	if format.Channels < 1 || format.SampleRate <= 0 {
+		b.err = errors.New("invalid format of raw audio input")
+		return
+	}
+	b.format = format
+
+	raw := make([]byte, max(format.SampleRate/20, 1)*format.FrameSize())
+	for {
+		n, err := io.ReadFull(data, raw)
+		if frames := n / format.FrameSize(); frames > 0 {
+			b.buffers <- mixDown(wav.Decode(raw[:frames*format.FrameSize()], format))
+		}
+		if err != nil {
+			if err != io.EOF && err != io.ErrUnexpectedEOF {
+				b.err = err
+			}
+			return
+		}
+	}
+}
+
+// deliver passes the input to callback at sampleRate in buffers of up to
+// framesPerBuffer samples, no faster than real time, until stop is closed
+// or the input ends
+func (b *PipeBackend) deliver(stop <-chan struct{}, sampleRate, framesPerBuffer int, callback func([]float32)) {
+	if framesPerBuffer <= 0 {
+		framesPerBuffer = 1024
+	}
+	var resampler *Resampler
+	started := time.Now()
+	delivered := 0
+
+	for {
+		var samples []float32
+		var ok bool
+		select {
+		case <-stop:
+			return
+		case samples, ok = <-b.buffers:
+		}
+		if !ok {
+			b.end.Do(func() { close(b.ended) })
+			return
+		}
+
+		if b.format.SampleRate != sampleRate {
+			if resampler == nil {
+				resampler = NewResampler(b.format.SampleRate, sampleRate)
+			}
+			samples = resampler.Process(samples)
+		}
+		for len(samples) > 0 {
+			// Wait until the audio delivered so far would have been recorded
+			due := started.Add(time.Duration(delivered) * time.Second / time.Duration(sampleRate))
+			if wait := time.Until(due); wait > 0 {
+				select {
+				case <-stop:
+					return
+				case <-time.After(wait):
+				}
+			}
+			n := min(len(samples), framesPerBuffer)
+			callback(samples[:n])
+			delivered += n
+			samples = samples[n:]
+		}
+	}
+}
+
+// pipeStream delivers a PipeBackend's input until stopped
+type pipeStream struct {
+	stop chan struct{}
+	done chan struct{} // Closed once no more audio is delivered
+	once sync.Once
+}
+
+// Stop ends the stream; input not yet read is left for the next one
+func (s *pipeStream) Stop() error {
+	return s.Abort()
+}
+
+// Abort ends the stream and waits until no more audio is delivered
+func (s *pipeStream) Abort() error {
+	s.once.Do(func() {
+		close(s.stop)
+		<-s.done
+	})
+	return nil
+}
@@ -0,0 +1,89 @@
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"os"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/jeff-barlow-spady/ramble/pkg/audio/wav"
+)
+
+// pipeSamples opens a stream on backend at 16kHz and collects what it
+// delivers until the input ends
+func pipeSamples(t *testing.T, backend *PipeBackend) []float32 {
+	var got []float32
+	stream, _, err := backend.Open("", true, TargetSampleRate, 256, func(samples []float32) {
+		if len(samples) > 256 {
+			t.Errorf("Expected at most 256 samples per buffer, got %d", len(samples))
+		}
+		got = append(got, samples...)
+	})
+	if err != nil {
+		t.Fatalf("Failed to open: %v", err)
+	}
+	select {
+	case <-backend.Ended():
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the input to end")
+	}
+	stream.Stop()
+	return got
+}
+
+// TestPipeBackendRaw tests mixing down raw stereo PCM arriving in odd-sized reads
+func TestPipeBackendRaw(t *testing.T) {
+	var raw bytes.Buffer
+	for i := 0; i < 1600; i++ {
+		binary.Write(&raw, binary.LittleEndian, [2]int16{16384, 0})
+	}
+	// A partial frame at the end is dropped
+	raw.WriteByte(1)
+
+	backend := NewPipeBackend("test", iotest.HalfReader(&raw), TargetSampleRate, 2)
+	started := time.Now()
+	got := pipeSamples(t, backend)
+	if len(got) != 1600 || got[0] != 0.25 || got[1599] != 0.25 {
+		t.Fatalf("Expected 1600 samples of 0.25, got %d", len(got))
+	}
+	if elapsed := time.Since(started); elapsed < 80*time.Millisecond {
+		t.Errorf("Expected 100ms of audio to take about as long to deliver, took %v", elapsed)
+	}
+	if backend.Err() != nil {
+		t.Errorf("Expected no error at the end of the input, got %v", backend.Err())
+	}
+	if _, _, err := backend.Open("", true, TargetSampleRate, 256, func([]float32) {}); !errors.Is(err, ErrInputEnded) {
+		t.Errorf("Expected ErrInputEnded opening after the end, got %v", err)
+	}
+}
+
+// TestPipeBackendWAV tests that a WAV header sets the format of the input
+func TestPipeBackendWAV(t *testing.T) {
+	path := t.TempDir() + "/input.wav"
+	w, err := wav.Create(path, wav.Format{Channels: 1, SampleRate: 8000, BitsPerSample: 16})
+	if err != nil {
+		t.Fatalf("Failed to create: %v", err)
+	}
+	w.WriteSamples(make([]float32, 800))
+	w.Close()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read: %v", err)
+	}
+
+	// 100ms at 8kHz is resampled to about 1600 samples at 16kHz
+	got := pipeSamples(t, NewPipeBackend("test", bytes.NewReader(data), 44100, 2))
+	if len(got) < 1500 || len(got) > 1600 {
+		t.Errorf("Expected about 1600 samples, got %d", len(got))
+	}
+
+	// A broken header ends the input with an error
+	backend := NewPipeBackend("test", bytes.NewReader([]byte("RIFF....WAVEjunk")), TargetSampleRate, 1)
+	pipeSamples(t, backend)
+	if backend.Err() == nil {
+		t.Error("Expected an error for a broken WAV header")
+	}
+}
@@ -0,0 +1,126 @@
+package audio
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/gordonklaus/portaudio"
+)
+
+// playerFramesPerBuffer is the size of each buffer sent to the output device
+const playerFramesPerBuffer = 1024
+
+// Player plays 16kHz mono audio through the default output device with
+// PortAudio, e.g. to preview an archived recording. Playback runs from the
+// playhead to the end of its range.
+type Player struct {
+	*Playhead
+
+	mu       sync.Mutex
+	stream   *portaudio.Stream
+	finished func()
+}
+
+// NewPlayer creates a player for samples, positioned at their start
+func NewPlayer(samples []float32) *Player {
+	return &Player{Playhead: NewPlayhead(samples)}
+}
+
+// SetFinishedCallback sets a function called when playback reaches the end
+// of the range
+func (p *Player) SetFinishedCallback(finished func()) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.finished = finished
+}
+
+// Playing reports whether audio is being played
+func (p *Player) Playing() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.stream != nil
+}
+
+// Play starts playback from the playhead, or from the start of the range if
+// the last playback reached its end
+func (p *Player) Play() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.stream != nil {
+		return nil
+	}
+	p.Rewind()
+
+	if err := acquireAudio(); err != nil {
+		return fmt.Errorf("failed to initialize audio output: %w", err)
+	}
+	var stream *portaudio.Stream
+	var err error
+	stream, err = portaudio.OpenDefaultStream(0, 1, TargetSampleRate, playerFramesPerBuffer, func(out []float32) {
+		if p.Fill(out) {
+			// The stream can't be stopped from its own callback
+			go p.finish(stream)
+		}
+	})
+	if err != nil {
+		releaseAudio()
+		return fmt.Errorf("failed to open audio output: %w", err)
+	}
+	if err := stream.Start(); err != nil {
+		stream.Close()
+		releaseAudio()
+		return fmt.Errorf("failed to start audio output: %w", err)
+	}
+	p.stream = stream
+	return nil
+}
+
+// Pause stops playback, keeping the playhead where it is
+func (p *Player) Pause() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.stop()
+}
+
+// Close stops playback and releases the output device
+func (p *Player) Close() error {
+	return p.Pause()
+}
+
+// finish stops the stream once it played to the end of the range, unless
+// it was already stopped
+func (p *Player) finish(stream *portaudio.Stream) {
+	p.mu.Lock()
+	if p.stream != stream {
+		p.mu.Unlock()
+		return
+	}
+	p.stop()
+	finished := p.finished
+	p.mu.Unlock()
+
+	if finished != nil {
+		finished()
+	}
+}
+
+// stop ends the stream, if any; p.mu must be held
+func (p *Player) stop() error {
+	if p.stream == nil {
+		return nil
+	}
+	stream := p.stream
+	p.stream = nil
+
+	// Stopping lets the last buffer play out
+	err := stream.Stop()
+	if closeErr := stream.Close(); err == nil {
+		err = closeErr
+	}
+	releaseAudio()
+	if err != nil {
+		return fmt.Errorf("failed to stop audio output: %w", err)
+	}
+	return nil
+}
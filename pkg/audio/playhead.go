@@ -0,0 +1,88 @@
+package audio
+
+import (
+	"sync"
+	"time"
+)
+
+// Playhead is the position in audio being played back, limited to a range
+// of it, e.g. the part kept by a trim. It is safe for concurrent use, so the
+// output callback can advance it while the UI reads and moves it.
+type Playhead struct {
+	mu         sync.Mutex
+	samples    []float32
+	pos        int // Next sample to play
+	start, end int // Range played, in samples
+}
+
+// NewPlayhead creates a playhead at the start of 16kHz mono samples, playing
+// all of them
+func NewPlayhead(samples []float32) *Playhead {
+	return &Playhead{samples: samples, end: len(samples)}
+}
+
+// Duration returns the length of all the audio
+func (p *Playhead) Duration() time.Duration {
+	return sampleTime(len(p.samples))
+}
+
+// Position returns how far into the audio the playhead is
+func (p *Playhead) Position() time.Duration {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return sampleTime(p.pos)
+}
+
+// SetRange limits playback to the audio between start and end, moving the
+// playhead into the range if it is outside
+func (p *Playhead) SetRange(start, end time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.start, p.end = sampleIndex(start, len(p.samples)), sampleIndex(end, len(p.samples))
+	if p.end < p.start {
+		p.end = p.start
+	}
+	if p.pos < p.start || p.pos >= p.end {
+		p.pos = p.start
+	}
+}
+
+// Rewind moves the playhead to the start of the range if it reached the end
+func (p *Playhead) Rewind() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.pos >= p.end {
+		p.pos = p.start
+	}
+}
+
+// Fill copies the next samples of the range into out, padding with silence
+// past its end, and reports whether the end was reached
+func (p *Playhead) Fill(out []float32) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	n := copy(out, p.samples[p.pos:p.end])
+	p.pos += n
+	clear(out[n:])
+	return p.pos >= p.end
+}
+
+// Range returns the audio between start and end
+func (p *Playhead) Range() []float32 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.samples[p.start:p.end]
+}
+
+// sampleTime converts a count of 16kHz samples to a duration
+func sampleTime(samples int) time.Duration {
+	return time.Duration(samples) * time.Second / 16000
+}
+
+// sampleIndex converts a duration to the index of a 16kHz sample, within
+// length samples
+func sampleIndex(d time.Duration, length int) int {
+	return min(max(int(d*16000/time.Second), 0), length)
+}
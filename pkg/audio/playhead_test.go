@@ -0,0 +1,52 @@
+package audio
+
+import (
+	"testing"
+	"time"
+)
+
+// TestPlayheadRange tests that only the range is played and the playhead
+// starts over once it ends
+func TestPlayheadRange(t *testing.T) {
+	samples := make([]float32, 16000)
+	for i := range samples {
+		samples[i] = float32(i)
+	}
+	p := NewPlayhead(samples)
+	if p.Duration() != time.Second {
+		t.Errorf("Expected 1s of audio, got %v", p.Duration())
+	}
+
+	p.SetRange(250*time.Millisecond, 500*time.Millisecond)
+	if p.Position() != 250*time.Millisecond {
+		t.Errorf("Expected the playhead at the start of the range, got %v", p.Position())
+	}
+	if got := len(p.Range()); got != 4000 {
+		t.Errorf("Expected 4000 samples in the range, got %d", got)
+	}
+
+	out := make([]float32, 3000)
+	if p.Fill(out) {
+		t.Error("Expected the range to continue after the first buffer")
+	}
+	if out[0] != 4000 {
+		t.Errorf("Expected playback to start at sample 4000, got %v", out[0])
+	}
+	if !p.Fill(out) {
+		t.Error("Expected the range to end in the second buffer")
+	}
+	if out[999] != 7999 || out[1000] != 0 {
+		t.Errorf("Expected the range to end at sample 7999 followed by silence, got %v and %v", out[999], out[1000])
+	}
+
+	p.Rewind()
+	if p.Position() != 250*time.Millisecond {
+		t.Errorf("Expected a rewind to the start of the range, got %v", p.Position())
+	}
+
+	// Ranges are kept within the audio and never reversed
+	p.SetRange(2*time.Second, -time.Second)
+	if got := len(p.Range()); got != 0 {
+		t.Errorf("Expected an empty range, got %d samples", got)
+	}
+}
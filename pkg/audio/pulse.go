@@ -0,0 +1,181 @@
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"os/exec"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// pulseBackend records through the PulseAudio server with parec, which
+// PipeWire desktops also provide through pipewire-pulse. It needs no
+// PortAudio or ALSA configuration, and the server handles device changes.
+type pulseBackend struct {
+	recorder string // Command that records raw audio, normally parec
+	control  string // Command that lists devices, normally pactl
+}
+
+// newPulseBackend creates a backend using parec and pactl from PATH
+func newPulseBackend() *pulseBackend {
+	return &pulseBackend{recorder: "parec", control: "pactl"}
+}
+
+// Name implements Backend
+func (b *pulseBackend) Name() string { return "pulse" }
+
+// Acquire checks that the PulseAudio tools are installed
+func (b *pulseBackend) Acquire() error {
+	for _, command := range []string{b.recorder, b.control} {
+		if _, err := exec.LookPath(command); err != nil {
+			return fmt.Errorf("%s not found; install the PulseAudio utilities (pulseaudio-utils): %w", command, err)
+		}
+	}
+	return nil
+}
+
+// Release implements Backend; there is nothing to release between streams
+func (b *pulseBackend) Release() error { return nil }
+
+// Refresh implements Backend; the server always lists current devices
+func (b *pulseBackend) Refresh() (bool, error) { return false, nil }
+
+// Devices lists the PulseAudio sources, leaving out monitors of outputs
+func (b *pulseBackend) Devices() ([]string, error) {
+	out, err := exec.Command(b.control, "list", "short", "sources").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audio sources: %w", err)
+	}
+	return parseSources(out), nil
+}
+
+// DefaultDevice returns the default PulseAudio source
+func (b *pulseBackend) DefaultDevice() (string, error) {
+	out, err := exec.Command(b.control, "get-default-source").Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to get the default audio source: %w", err)
+	}
+	name := strings.TrimSpace(string(out))
+	if name == "" {
+		return "", errDeviceMissing
+	}
+	return name, nil
+}
+
+// Open starts parec on the source and delivers what it records
+func (b *pulseBackend) Open(name string, fallback bool, sampleRate float64, framesPerBuffer int, callback func([]float32)) (Stream, string, error) {
+	if name != "" {
+		sources, err := b.Devices()
+		if err != nil {
+			return nil, "", err
+		}
+		if !slices.Contains(sources, name) {
+			if !fallback {
+				return nil, "", errDeviceMissing
+			}
+			name = ""
+		}
+	} else if !fallback {
+		return nil, "", errDeviceMissing
+	}
+
+	// parec records from the default source when none is given
+	args := []string{
+		"--raw",
+		"--format=float32le",
+		"--channels=1",
+		"--rate=" + strconv.Itoa(int(sampleRate)),
+		"--latency=" + strconv.Itoa(framesPerBuffer*4),
+	}
+	if name != "" {
+		args = append(args, "--device="+name)
+	} else if device, err := b.DefaultDevice(); err == nil {
+		name = device
+	}
+
+	cmd := exec.Command(b.recorder, args...)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to start %s: %w", b.recorder, err)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, "", fmt.Errorf("failed to start %s: %w", b.recorder, err)
+	}
+
+	s := &pulseStream{cmd: cmd, done: make(chan struct{})}
+	go func() {
+		defer close(s.done)
+		readSamples(stdout, framesPerBuffer, callback)
+	}()
+	return s, name, nil
+}
+
+// pulseStream is a running parec process
+type pulseStream struct {
+	cmd  *exec.Cmd
+	done chan struct{} // Closed once the recorded audio has been read
+	once sync.Once
+}
+
+// Stop ends the recording. parec has no audio of its own buffered, so
+// stopping and aborting are the same.
+func (s *pulseStream) Stop() error {
+	return s.Abort()
+}
+
+// Abort kills parec and waits until no more audio is delivered
+func (s *pulseStream) Abort() error {
+	s.once.Do(func() {
+		s.cmd.Process.Kill()
+		<-s.done
+		s.cmd.Wait()
+	})
+	return nil
+}
+
+// readSamples decodes float32 samples from r and passes them to callback in
+// buffers of up to framesPerBuffer samples until r ends. The buffer passed to
+// callback is reused.
+func readSamples(r io.Reader, framesPerBuffer int, callback func([]float32)) {
+	if framesPerBuffer <= 0 {
+		framesPerBuffer = 1024
+	}
+	raw := make([]byte, framesPerBuffer*4)
+	samples := make([]float32, framesPerBuffer)
+	pending := 0 // Bytes of a partial sample left from the last read
+
+	for {
+		n, err := r.Read(raw[pending:])
+		n += pending
+		count := n / 4
+		for i := 0; i < count; i++ {
+			samples[i] = math.Float32frombits(binary.LittleEndian.Uint32(raw[i*4:]))
+		}
+		if count > 0 {
+			callback(samples[:count])
+		}
+		pending = copy(raw, raw[count*4:n])
+		if err != nil {
+			return
+		}
+	}
+}
+
+// parseSources returns the source names in the output of
+// "pactl list short sources", leaving out monitors of output devices
+func parseSources(out []byte) []string {
+	var names []string
+	for _, line := range bytes.Split(out, []byte("\n")) {
+		fields := strings.Split(string(line), "\t")
+		if len(fields) < 2 || strings.HasSuffix(fields[1], ".monitor") {
+			continue
+		}
+		names = append(names, fields[1])
+	}
+	return names
+}
@@ -0,0 +1,40 @@
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"reflect"
+	"testing"
+	"testing/iotest"
+)
+
+// TestReadSamples tests decoding parec output that arrives in odd-sized reads
+func TestReadSamples(t *testing.T) {
+	want := []float32{0.5, -0.25, 1, 0, -1}
+	var raw bytes.Buffer
+	for _, sample := range want {
+		binary.Write(&raw, binary.LittleEndian, math.Float32bits(sample))
+	}
+
+	var got []float32
+	readSamples(iotest.HalfReader(iotest.OneByteReader(&raw)), 2, func(samples []float32) {
+		if len(samples) > 2 {
+			t.Errorf("Expected at most 2 samples per buffer, got %d", len(samples))
+		}
+		got = append(got, samples...)
+	})
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
+
+// TestParseSources tests that monitors of outputs are not offered as inputs
+func TestParseSources(t *testing.T) {
+	out := []byte("47\talsa_output.pci-0000_00_1f.3.analog-stereo.monitor\tPipeWire\ts32le 2ch 48000Hz\tSUSPENDED\n" +
+		"48\talsa_input.usb-Blue_Yeti-00.analog-stereo\tPipeWire\ts16le 2ch 48000Hz\tRUNNING\n")
+	want := []string{"alsa_input.usb-Blue_Yeti-00.analog-stereo"}
+	if got := parseSources(out); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
@@ -0,0 +1,141 @@
+package audio
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jeff-barlow-spady/ramble/pkg/logger"
+)
+
+// deviceTimeout is how long the stream may deliver no audio before the input
+// device is considered gone
+const deviceTimeout = 2 * time.Second
+
+// deviceCheckInterval is how often the watchdog checks the stream
+const deviceCheckInterval = 500 * time.Millisecond
+
+// reconnectInterval is how often a lost device is looked for again
+const reconnectInterval = 2 * time.Second
+
+// DeviceEventKind describes a change of the input device during capture
+type DeviceEventKind int
+
+const (
+	// DeviceLost means the input device stopped delivering audio, e.g. because
+	// it was unplugged
+	DeviceLost DeviceEventKind = iota
+	// DeviceReconnected means capture resumed, on the same or the default device
+	DeviceReconnected
+)
+
+// DeviceEvent reports a change of the input device during capture
+type DeviceEvent struct {
+	Kind   DeviceEventKind
+	Device string // Name of the device lost or reconnected to
+}
+
+// SetDeviceHandling sets how capture recovers from a lost input device. It
+// keeps looking for the device and resumes once it is back, or switches to the
+// default input device if fallback is set. onEvent, if non-nil, is called
+// from a background goroutine when the device is lost or capture resumes.
+func (c *Capture) SetDeviceHandling(fallback bool, onEvent func(DeviceEvent)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.fallback = fallback
+	c.onDeviceEvent = onEvent
+}
+
+// watchDevice reopens the stream when audio stops arriving until stop is closed
+func (c *Capture) watchDevice(stop <-chan struct{}) {
+	ticker := time.NewTicker(deviceCheckInterval)
+	defer ticker.Stop()
+
+	var nextAttempt time.Time
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+
+		c.mu.Lock()
+		// Stop may have run while waiting for the lock
+		select {
+		case <-stop:
+			c.mu.Unlock()
+			return
+		default:
+		}
+
+		var event *DeviceEvent
+		if !c.lost {
+			if time.Since(time.Unix(0, c.lastAudio.Load())) < deviceTimeout {
+				c.mu.Unlock()
+				continue
+			}
+			logger.Warning(logger.CategoryAudio, "No audio from %q; the device may have been disconnected", c.device)
+			c.lost = true
+			c.closeStream()
+			event = &DeviceEvent{Kind: DeviceLost, Device: c.device}
+		} else if time.Now().After(nextAttempt) {
+			if err := c.reopen(); err != nil {
+				if !errors.Is(err, errDeviceMissing) {
+					logger.Debug(logger.CategoryAudio, "Failed to reopen audio stream: %v", err)
+				}
+				nextAttempt = time.Now().Add(reconnectInterval)
+			} else {
+				logger.Info(logger.CategoryAudio, "Audio capture resumed on %q", c.device)
+				event = &DeviceEvent{Kind: DeviceReconnected, Device: c.device}
+			}
+		}
+		onEvent := c.onDeviceEvent
+		c.mu.Unlock()
+
+		if event != nil && onEvent != nil {
+			onEvent(*event)
+		}
+	}
+}
+
+// closeStream abandons the current stream without waiting for buffered audio.
+// The caller must hold c.mu.
+func (c *Capture) closeStream() {
+	if c.stream == nil {
+		return
+	}
+	c.stream.Abort()
+	c.stream = nil
+}
+
+// reopen opens a stream on the recorded device or the default device. If
+// capture is the only user of the audio system, it is restarted first so it
+// sees devices plugged in since. The caller must hold c.mu.
+func (c *Capture) reopen() error {
+	c.closeStream()
+	if c.suspended {
+		if err := c.backend.Acquire(); err != nil {
+			return fmt.Errorf("failed to initialize audio: %w", err)
+		}
+		c.suspended = false
+	}
+	if released, err := c.backend.Refresh(); err != nil {
+		// Without its reference capture is suspended until initialized again
+		c.suspended = released
+		return fmt.Errorf("failed to restart audio: %w", err)
+	}
+
+	if c.resampler != nil {
+		c.resampler.Reset()
+	}
+	c.lastAudio.Store(time.Now().UnixNano())
+	stream, device, err := c.backend.Open(c.device, c.fallback, c.sampleRate, c.framesPerBuffer, c.processAudio)
+	if err != nil {
+		return err
+	}
+
+	c.stream = stream
+	c.device = device
+	c.lost = false
+	return nil
+}
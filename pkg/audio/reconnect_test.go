@@ -0,0 +1,41 @@
+package audio
+
+import (
+	"testing"
+
+	"github.com/gordonklaus/portaudio"
+)
+
+// TestChooseDevice tests which device capture resumes on after losing one
+func TestChooseDevice(t *testing.T) {
+	usb := &portaudio.DeviceInfo{Name: "USB Microphone", MaxInputChannels: 1}
+	builtin := &portaudio.DeviceInfo{Name: "Built-in Microphone", MaxInputChannels: 2}
+	speakers := &portaudio.DeviceInfo{Name: "USB Microphone", MaxOutputChannels: 2}
+
+	tests := []struct {
+		name     string
+		devices  []*portaudio.DeviceInfo
+		fallback *portaudio.DeviceInfo
+		want     *portaudio.DeviceInfo
+	}{
+		{"device returned", []*portaudio.DeviceInfo{builtin, usb}, builtin, usb},
+		{"fallback to default", []*portaudio.DeviceInfo{builtin}, builtin, builtin},
+		{"wait without fallback", []*portaudio.DeviceInfo{builtin}, nil, nil},
+		{"output with the same name", []*portaudio.DeviceInfo{speakers}, nil, nil},
+		{"default without inputs", []*portaudio.DeviceInfo{speakers}, speakers, nil},
+	}
+	for _, tt := range tests {
+		if got := chooseDevice(tt.devices, "USB Microphone", tt.fallback); got != tt.want {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.want, got)
+		}
+	}
+
+	// A device whose name was never known can only be replaced by the default
+	unnamed := &portaudio.DeviceInfo{Name: "", MaxOutputChannels: 2}
+	if got := chooseDevice([]*portaudio.DeviceInfo{unnamed, builtin}, "", builtin); got != builtin {
+		t.Errorf("Expected the default device for an unnamed one, got %v", got)
+	}
+	if got := chooseDevice([]*portaudio.DeviceInfo{unnamed, builtin}, "", nil); got != nil {
+		t.Errorf("Expected to keep waiting without fallback, got %v", got)
+	}
+}
@@ -39,6 +39,7 @@ type Recorder struct {
 	config       Config
 	stream       *portaudio.Stream
 	buffer       []float32
+	ring         *RingBuffer // Preallocated buffer drained by Read
 	isRecording  bool
 	dataCallback func([]float32)
 	mu           sync.Mutex
@@ -50,12 +51,13 @@ func NewRecorder(config Config) (*Recorder, error) {
 	recorder := &Recorder{
 		config:      config,
 		buffer:      make([]float32, config.FramesPerBuffer*config.Channels),
+		ring:        NewRingBuffer(int(config.SampleRate) * config.Channels * defaultRingSeconds),
 		isRecording: false,
 		initialized: false,
 	}
 
 	// Initialize PortAudio with explicit error handling
-	err := portaudio.Initialize()
+	err := acquireAudio()
 	if err != nil {
 		if config.Debug {
 			logger.Error(logger.CategoryAudio, "PortAudio initialization error: %v", err)
@@ -95,7 +97,8 @@ func NewRecorder(config Config) (*Recorder, error) {
 }
 
 // Start begins audio recording
-// The provided callback will be called with audio data
+// Captured audio is buffered for Read. If callback is non-nil it is also
+// called with each buffer; the slice is reused and must not be retained.
 func (r *Recorder) Start(callback func([]float32)) error {
 	r.mu.Lock()
 
@@ -104,8 +107,9 @@ func (r *Recorder) Start(callback func([]float32)) error {
 		return errors.New("recorder is already running")
 	}
 
-	// Store the callback
+	// Store the callback and discard audio left over from a previous run
 	r.dataCallback = callback
+	r.ring.Reset()
 
 	// Debug logging - no need to hold the lock for this
 	if r.config.Debug {
@@ -211,7 +215,7 @@ func (r *Recorder) Terminate() error {
 	}
 
 	if r.initialized {
-		return portaudio.Terminate()
+		return releaseAudio()
 	}
 	return nil
 }
@@ -258,20 +262,44 @@ func (r *Recorder) processAudio(in, _ []float32) {
 		copy(r.buffer, in)
 	}
 
+	// Only pass on as many samples as PortAudio delivered
+	samples := r.buffer
+	if len(in) < len(samples) {
+		samples = samples[:len(in)]
+	}
+
+	// Hand the samples to the consumer without allocating
+	r.ring.Write(samples)
+
 	// If a callback is registered, send the data
 	if r.dataCallback != nil {
-		// Make a copy to avoid race conditions
-		dataCopy := make([]float32, len(r.buffer))
-		copy(dataCopy, r.buffer)
-
-		// Execute callback outside the lock to prevent deadlocks
-		// Since we're already copied the data, it's safe to unlock
+		// Execute callback outside the lock to prevent deadlocks.
+		// PortAudio serialises callbacks, so r.buffer is not rewritten
+		// until this one returns.
+		callback := r.dataCallback
 		r.mu.Unlock()
-		r.dataCallback(dataCopy)
+		callback(samples)
 		r.mu.Lock() // Reacquire the lock to match our defer
 	}
 }
 
+// Read copies buffered audio into samples and returns the number of samples
+// copied. It is intended for the transcription goroutine and never blocks;
+// use Ready to wait for new audio.
+func (r *Recorder) Read(samples []float32) int {
+	return r.ring.Read(samples)
+}
+
+// Ready returns a channel that is signalled when new audio is available
+func (r *Recorder) Ready() <-chan struct{} {
+	return r.ring.Ready()
+}
+
+// DroppedSamples returns how many samples were overwritten before being read
+func (r *Recorder) DroppedSamples() uint64 {
+	return r.ring.Dropped()
+}
+
 // CalculateRMSLevel calculates the Root Mean Square level of audio data
 func CalculateRMSLevel(buffer []float32) float32 {
 	if len(buffer) == 0 {
@@ -0,0 +1,99 @@
+package audio
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jeff-barlow-spady/ramble/pkg/audio/flac"
+	"github.com/jeff-barlow-spady/ramble/pkg/audio/wav"
+	logger "github.com/jeff-barlow-spady/ramble/pkg/logger"
+)
+
+// Formats recordings are archived in, which are also their file extensions
+const (
+	ArchiveWAV  = "wav"  // Uncompressed, about 115MB an hour
+	ArchiveFLAC = "flac" // Lossless, in about half the space
+)
+
+// flacFormat is how recordings are compressed, matching wavFormat
+var flacFormat = flac.Format{Channels: 1, SampleRate: TargetSampleRate, BitsPerSample: 16}
+
+// recordingWriter streams samples to a recording file
+type recordingWriter interface {
+	WriteSamples(samples []float32) error
+	Flush() error
+	Close() error
+}
+
+// createRecording creates a recording file at path in the format named by
+// its extension
+func createRecording(path string) (recordingWriter, error) {
+	if isFLAC(path) {
+		return flac.Create(path, flacFormat)
+	}
+	return wav.Create(path, wavFormat)
+}
+
+// isFLAC reports whether path names a FLAC file
+func isFLAC(path string) bool {
+	return strings.EqualFold(filepath.Ext(path), "."+ArchiveFLAC)
+}
+
+// isRecording reports whether name has the extension of an archive format
+func isRecording(name string) bool {
+	ext := strings.ToLower(filepath.Ext(name))
+	return ext == "."+ArchiveWAV || ext == "."+ArchiveFLAC
+}
+
+// LoadRecording loads a recording saved as WAV or FLAC, going by its
+// extension, as mono 16kHz samples
+func LoadRecording(path string) ([]float32, error) {
+	if !isFLAC(path) {
+		return LoadFromWav(path)
+	}
+
+	channels, format, err := flac.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	logger.Info(logger.CategoryAudio, "FLAC file: %d channels, %d Hz, %.2f seconds",
+		format.Channels, format.SampleRate, float64(len(channels[0]))/float64(format.SampleRate))
+	return ResampleTo16k(mixDown(channels), format.SampleRate), nil
+}
+
+// SaveRecording saves 16kHz mono samples as WAV or FLAC, going by the
+// extension of path
+func SaveRecording(samples []float32, path string) error {
+	if !isFLAC(path) {
+		return SaveToWav(samples, path)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %v", err)
+	}
+	w, err := createRecording(path)
+	if err != nil {
+		return err
+	}
+	if err := w.WriteSamples(samples); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+// mixDown averages channels into one
+func mixDown(channels [][]float32) []float32 {
+	if len(channels) == 1 {
+		return channels[0]
+	}
+	mixed := make([]float32, len(channels[0]))
+	for _, channel := range channels {
+		for i, v := range channel {
+			mixed[i] += v / float32(len(channels))
+		}
+	}
+	return mixed
+}
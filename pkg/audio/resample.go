@@ -0,0 +1,154 @@
+package audio
+
+import "math"
+
+// resampleZeroCrossings is how many zero crossings of the sinc kernel are kept
+// on each side. More give a steeper filter at the cost of speed.
+const resampleZeroCrossings = 16
+
+// resampleRolloff places the filter cutoff just below the Nyquist frequency
+// of the lower rate, leaving room for the transition band
+const resampleRolloff = 0.92
+
+// Resampler converts audio between sample rates with a polyphase
+// windowed-sinc filter. It removes frequencies the target rate can't
+// represent instead of folding them back as aliasing, which linear
+// interpolation does.
+//
+// Process can be called repeatedly on consecutive buffers of a stream.
+type Resampler struct {
+	up, down int         // Output advances by down/up input samples per sample
+	halfTaps int         // Input samples used on each side of an output sample
+	phases   [][]float32 // Filter coefficients for each of the up phases
+
+	history []float32 // Input not yet fully used, starting at input index base
+	base    int64
+	next    int64     // Index of the next output sample
+	out     []float32 // Reused output buffer
+}
+
+// NewResampler creates a resampler from one sample rate to another
+func NewResampler(from, to int) *Resampler {
+	g := gcd(from, to)
+	r := &Resampler{up: to / g, down: from / g}
+
+	// Cut off below the Nyquist frequency of the lower rate, in cycles per input sample
+	cutoff := 0.5 * resampleRolloff
+	if to < from {
+		cutoff *= float64(to) / float64(from)
+	}
+	r.halfTaps = int(math.Ceil(resampleZeroCrossings / (2 * cutoff)))
+
+	// Phase p holds the kernel for output samples that fall p/up of the way
+	// between two input samples. Tap i applies to input sample k-halfTaps+1+i.
+	r.phases = make([][]float32, r.up)
+	for p := range r.phases {
+		taps := make([]float32, 2*r.halfTaps)
+		frac := float64(p) / float64(r.up)
+		var sum float64
+		for i := range taps {
+			t := frac + float64(r.halfTaps-1-i) // Distance from the output sample
+			h := 2 * cutoff * sinc(2*cutoff*t) * blackman(t/float64(r.halfTaps))
+			taps[i] = float32(h)
+			sum += h
+		}
+		// Unity gain at DC for every phase
+		for i := range taps {
+			taps[i] = float32(float64(taps[i]) / sum)
+		}
+		r.phases[p] = taps
+	}
+
+	r.Reset()
+	return r
+}
+
+// Reset forgets previous input so the next Process starts a new stream
+func (r *Resampler) Reset() {
+	// The stream is preceded by silence
+	r.history = append(r.history[:0], make([]float32, r.halfTaps)...)
+	r.base = -int64(r.halfTaps)
+	r.next = 0
+}
+
+// Process resamples the next buffer of a stream. Each output sample needs
+// input from slightly after it, so output lags the input by a few samples
+// until Flush. The returned slice is reused by the next call.
+func (r *Resampler) Process(in []float32) []float32 {
+	r.history = append(r.history, in...)
+	r.out = r.out[:0]
+
+	end := r.base + int64(len(r.history)) // Index after the last input sample
+	for {
+		// The output sample lies between input samples k and k+1
+		k := r.next * int64(r.down) / int64(r.up)
+		phase := r.next * int64(r.down) % int64(r.up)
+		first := k - int64(r.halfTaps) + 1
+		if first+int64(2*r.halfTaps) > end {
+			break
+		}
+
+		window := r.history[first-r.base:]
+		var sum float32
+		for i, tap := range r.phases[phase] {
+			sum += tap * window[i]
+		}
+		r.out = append(r.out, sum)
+		r.next++
+	}
+
+	// Drop input no future output sample needs
+	keepFrom := r.next*int64(r.down)/int64(r.up) - int64(r.halfTaps) + 1
+	if drop := keepFrom - r.base; drop > 0 {
+		n := copy(r.history, r.history[drop:])
+		r.history = r.history[:n]
+		r.base = keepFrom
+	}
+	return r.out
+}
+
+// Flush returns the output still held back at the end of a stream
+func (r *Resampler) Flush() []float32 {
+	return r.Process(make([]float32, r.halfTaps))
+}
+
+// Resample converts a complete recording from one sample rate to another
+func Resample(samples []float32, from, to int) []float32 {
+	if from == to || len(samples) == 0 {
+		return samples
+	}
+
+	r := NewResampler(from, to)
+	resampled := append([]float32(nil), r.Process(samples)...)
+	resampled = append(resampled, r.Flush()...)
+
+	// Flushing pads with silence, which may add a sample past the end
+	if n := int(int64(len(samples)) * int64(to) / int64(from)); len(resampled) > n {
+		resampled = resampled[:n]
+	}
+	return resampled
+}
+
+// sinc is the normalized sinc function sin(pi x) / (pi x)
+func sinc(x float64) float64 {
+	if x == 0 {
+		return 1
+	}
+	return math.Sin(math.Pi*x) / (math.Pi * x)
+}
+
+// blackman is the Blackman window over -1 to 1
+func blackman(x float64) float64 {
+	if x <= -1 || x >= 1 {
+		return 0
+	}
+	return 0.42 + 0.5*math.Cos(math.Pi*x) + 0.08*math.Cos(2*math.Pi*x)
+}
+
+// gcd returns the greatest common divisor of two positive integers
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
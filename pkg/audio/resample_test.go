@@ -0,0 +1,106 @@
+package audio
+
+import (
+	"math"
+	"testing"
+)
+
+// sine generates a tone of the given frequency and amplitude
+func sine(freq, amplitude float64, rate, n int) []float32 {
+	samples := make([]float32, n)
+	for i := range samples {
+		samples[i] = float32(amplitude * math.Sin(2*math.Pi*freq*float64(i)/float64(rate)))
+	}
+	return samples
+}
+
+// toneAmplitude measures the amplitude of one frequency with the Goertzel algorithm
+func toneAmplitude(samples []float32, freq float64, rate int) float64 {
+	coeff := 2 * math.Cos(2*math.Pi*freq/float64(rate))
+	var s1, s2 float64
+	for _, x := range samples {
+		s1, s2 = float64(x)+coeff*s1-s2, s1
+	}
+	power := s1*s1 + s2*s2 - coeff*s1*s2
+	return 2 * math.Sqrt(math.Max(power, 0)) / float64(len(samples))
+}
+
+// middle drops the edges of a signal, where the filter sees the padding
+func middle(samples []float32) []float32 {
+	edge := len(samples) / 10
+	return samples[edge : len(samples)-edge]
+}
+
+// TestResampleSpectralFidelity tests that tones below the new Nyquist
+// frequency keep their level while tones above it are removed instead of
+// aliasing into the speech band
+func TestResampleSpectralFidelity(t *testing.T) {
+	for _, from := range []int{44100, 48000, 22050} {
+		in := sine(1000, 0.5, from, from)
+		noise := sine(10000, 0.5, from, from) // Above the 8 kHz Nyquist frequency of 16 kHz
+		for i := range in {
+			in[i] += noise[i]
+		}
+
+		out := middle(Resample(in, from, 16000))
+		if got := toneAmplitude(out, 1000, 16000); math.Abs(got-0.5) > 0.01 {
+			t.Errorf("%d Hz: expected the 1 kHz tone to keep amplitude 0.5, got %.4f", from, got)
+		}
+		// 10 kHz would alias to 6 kHz
+		if got := toneAmplitude(out, 6000, 16000); got > 0.005 {
+			t.Errorf("%d Hz: expected no aliasing at 6 kHz, got amplitude %.4f", from, got)
+		}
+	}
+}
+
+// TestResampleMatchesSignal tests the resampled signal sample by sample
+func TestResampleMatchesSignal(t *testing.T) {
+	for _, from := range []int{8000, 44100, 48000} {
+		in := sine(440, 0.8, from, from/2)
+		out := Resample(in, from, 16000)
+
+		if want := len(in) * 16000 / from; len(out) != want {
+			t.Errorf("%d Hz: expected %d samples, got %d", from, want, len(out))
+		}
+
+		want := sine(440, 0.8, 16000, len(out))
+		edge := len(out) / 10
+		for i := edge; i < len(out)-edge; i++ {
+			if math.Abs(float64(out[i]-want[i])) > 0.005 {
+				t.Fatalf("%d Hz: sample %d is %.4f, expected %.4f", from, i, out[i], want[i])
+			}
+		}
+	}
+}
+
+// TestResamplerStreaming tests that resampling a stream in buffers gives the
+// same result as resampling it at once
+func TestResamplerStreaming(t *testing.T) {
+	in := sine(700, 0.5, 44100, 44100/4)
+	whole := Resample(in, 44100, 16000)
+
+	r := NewResampler(44100, 16000)
+	var streamed []float32
+	for start := 0; start < len(in); start += 1024 {
+		end := min(start+1024, len(in))
+		streamed = append(streamed, r.Process(in[start:end])...)
+	}
+	streamed = append(streamed, r.Flush()...)
+
+	if len(streamed) < len(whole) {
+		t.Fatalf("Expected at least %d samples, got %d", len(whole), len(streamed))
+	}
+	for i := range whole {
+		if math.Abs(float64(streamed[i]-whole[i])) > 1e-5 {
+			t.Fatalf("Sample %d differs: %v streamed, %v at once", i, streamed[i], whole[i])
+		}
+	}
+}
+
+// TestResampleSameRate tests that audio at the target rate is left alone
+func TestResampleSameRate(t *testing.T) {
+	in := []float32{0.1, 0.2}
+	if out := Resample(in, 16000, 16000); &out[0] != &in[0] {
+		t.Error("Expected the input to be returned unchanged")
+	}
+}
@@ -0,0 +1,126 @@
+package audio
+
+import "sync"
+
+// defaultRingSeconds is how much audio the capture ring buffer holds before
+// the oldest samples are overwritten
+const defaultRingSeconds = 10
+
+// RingBuffer is a fixed-size, preallocated FIFO of audio samples.
+// The PortAudio callback writes into it and a consumer goroutine drains it
+// with Read, so no memory is allocated per audio callback.
+type RingBuffer struct {
+	mu      sync.Mutex
+	data    []float32
+	start   int    // Index of the oldest buffered sample
+	size    int    // Number of buffered samples
+	dropped uint64 // Samples overwritten before they were read
+	ready   chan struct{}
+}
+
+// NewRingBuffer creates a ring buffer holding up to capacity samples
+func NewRingBuffer(capacity int) *RingBuffer {
+	if capacity <= 0 {
+		capacity = 16000 * defaultRingSeconds
+	}
+	return &RingBuffer{
+		data:  make([]float32, capacity),
+		ready: make(chan struct{}, 1),
+	}
+}
+
+// Write appends samples to the buffer. When the buffer is full the oldest
+// samples are overwritten, since stale audio is less useful than fresh audio.
+// It returns the number of samples written.
+func (rb *RingBuffer) Write(samples []float32) int {
+	if len(samples) == 0 {
+		return 0
+	}
+
+	rb.mu.Lock()
+	capacity := len(rb.data)
+
+	// Only the most recent capacity samples can ever be kept
+	if len(samples) > capacity {
+		rb.dropped += uint64(len(samples) - capacity)
+		samples = samples[len(samples)-capacity:]
+	}
+
+	// Make room by discarding the oldest samples
+	if overflow := rb.size + len(samples) - capacity; overflow > 0 {
+		rb.start = (rb.start + overflow) % capacity
+		rb.size -= overflow
+		rb.dropped += uint64(overflow)
+	}
+
+	// Copy in at most two parts to handle wrap-around
+	end := (rb.start + rb.size) % capacity
+	n := copy(rb.data[end:], samples)
+	copy(rb.data, samples[n:])
+	rb.size += len(samples)
+	rb.mu.Unlock()
+
+	// Wake the consumer without blocking the audio thread
+	select {
+	case rb.ready <- struct{}{}:
+	default:
+	}
+
+	return len(samples)
+}
+
+// Read copies up to len(samples) of the oldest buffered samples into samples
+// and returns the number copied. It never blocks; use Ready to wait for data.
+func (rb *RingBuffer) Read(samples []float32) int {
+	rb.mu.Lock()
+	defer rb.mu.Unlock()
+
+	count := len(samples)
+	if count > rb.size {
+		count = rb.size
+	}
+	if count == 0 {
+		return 0
+	}
+
+	capacity := len(rb.data)
+	n := copy(samples[:count], rb.data[rb.start:])
+	copy(samples[n:count], rb.data)
+
+	rb.start = (rb.start + count) % capacity
+	rb.size -= count
+	return count
+}
+
+// Ready returns a channel that receives a value whenever new samples are written
+func (rb *RingBuffer) Ready() <-chan struct{} {
+	return rb.ready
+}
+
+// Len returns the number of samples waiting to be read
+func (rb *RingBuffer) Len() int {
+	rb.mu.Lock()
+	defer rb.mu.Unlock()
+	return rb.size
+}
+
+// Cap returns the maximum number of samples the buffer can hold
+func (rb *RingBuffer) Cap() int {
+	return len(rb.data)
+}
+
+// Dropped returns the total number of samples overwritten before being read
+func (rb *RingBuffer) Dropped() uint64 {
+	rb.mu.Lock()
+	defer rb.mu.Unlock()
+	return rb.dropped
+}
+
+// Reset discards all buffered samples
+func (rb *RingBuffer) Reset() {
+	rb.mu.Lock()
+	defer rb.mu.Unlock()
+	rb.start = 0
+	rb.size = 0
+	rb.dropped = 0
+}
@@ -0,0 +1,118 @@
+package audio
+
+import (
+	"testing"
+)
+
+// TestRingBufferReadWrite tests basic FIFO behaviour
+func TestRingBufferReadWrite(t *testing.T) {
+	rb := NewRingBuffer(8)
+
+	if n := rb.Write([]float32{1, 2, 3}); n != 3 {
+		t.Fatalf("Expected 3 samples written, got %d", n)
+	}
+	if rb.Len() != 3 {
+		t.Errorf("Expected length 3, got %d", rb.Len())
+	}
+
+	out := make([]float32, 2)
+	if n := rb.Read(out); n != 2 || out[0] != 1 || out[1] != 2 {
+		t.Errorf("Expected [1 2], got %v (n=%d)", out[:n], n)
+	}
+
+	// Only one sample left
+	if n := rb.Read(out); n != 1 || out[0] != 3 {
+		t.Errorf("Expected [3], got %v (n=%d)", out[:n], n)
+	}
+
+	// Empty buffer reads nothing
+	if n := rb.Read(out); n != 0 {
+		t.Errorf("Expected 0 samples from empty buffer, got %d", n)
+	}
+}
+
+// TestRingBufferWrapAround tests reads and writes across the end of the buffer
+func TestRingBufferWrapAround(t *testing.T) {
+	rb := NewRingBuffer(4)
+	out := make([]float32, 4)
+
+	rb.Write([]float32{1, 2, 3})
+	rb.Read(out[:2])
+	rb.Write([]float32{4, 5, 6})
+
+	n := rb.Read(out)
+	expected := []float32{3, 4, 5, 6}
+	if n != len(expected) {
+		t.Fatalf("Expected %d samples, got %d", len(expected), n)
+	}
+	for i, sample := range expected {
+		if out[i] != sample {
+			t.Errorf("Sample %d: expected %f, got %f", i, sample, out[i])
+		}
+	}
+}
+
+// TestRingBufferOverwritesOldest tests that a full buffer drops the oldest audio
+func TestRingBufferOverwritesOldest(t *testing.T) {
+	rb := NewRingBuffer(4)
+
+	rb.Write([]float32{1, 2, 3})
+	rb.Write([]float32{4, 5, 6})
+
+	if rb.Dropped() != 2 {
+		t.Errorf("Expected 2 dropped samples, got %d", rb.Dropped())
+	}
+
+	out := make([]float32, 4)
+	n := rb.Read(out)
+	expected := []float32{3, 4, 5, 6}
+	if n != len(expected) {
+		t.Fatalf("Expected %d samples, got %d", len(expected), n)
+	}
+	for i, sample := range expected {
+		if out[i] != sample {
+			t.Errorf("Sample %d: expected %f, got %f", i, sample, out[i])
+		}
+	}
+
+	// A single write larger than the buffer keeps only the newest samples
+	rb.Write([]float32{7, 8, 9, 10, 11, 12})
+	n = rb.Read(out)
+	if n != 4 || out[0] != 9 || out[3] != 12 {
+		t.Errorf("Expected [9 10 11 12], got %v", out[:n])
+	}
+}
+
+// TestRingBufferReady tests that writes signal the consumer
+func TestRingBufferReady(t *testing.T) {
+	rb := NewRingBuffer(4)
+
+	select {
+	case <-rb.Ready():
+		t.Fatal("Ready should not be signalled before any write")
+	default:
+	}
+
+	rb.Write([]float32{1})
+
+	select {
+	case <-rb.Ready():
+	default:
+		t.Fatal("Ready should be signalled after a write")
+	}
+}
+
+// TestRingBufferWriteDoesNotAllocate guards the allocation-free capture path
+func TestRingBufferWriteDoesNotAllocate(t *testing.T) {
+	rb := NewRingBuffer(4096)
+	in := make([]float32, 1024)
+	out := make([]float32, 1024)
+
+	allocs := testing.AllocsPerRun(100, func() {
+		rb.Write(in)
+		rb.Read(out)
+	})
+	if allocs != 0 {
+		t.Errorf("Expected no allocations per write/read, got %f", allocs)
+	}
+}
@@ -0,0 +1,147 @@
+package audio
+
+import (
+	"math"
+	"math/cmplx"
+)
+
+// Defaults for the spectrum shown while recording
+const (
+	// SpectrumSize is the number of samples in each FFT, 32ms at 16kHz
+	SpectrumSize = 512
+	// SpectrumBands is the number of frequency bands the spectrum is grouped into
+	SpectrumBands = 48
+)
+
+// Range of the spectrum
+const (
+	spectrumMinFreq = 60.0  // Below the lowest voices, above mains hum
+	spectrumFloorDB = -90.0 // Band levels at or below this are shown as silence
+)
+
+// SpectrumAnalyzer computes the spectrum of the most recent audio with an
+// FFT, grouped into bands spaced evenly on a logarithmic scale like pitch is
+// heard. It reuses its buffers, so it can run in the audio callback, but it
+// is not safe for concurrent use.
+type SpectrumAnalyzer struct {
+	window  []float32    // The most recent SpectrumSize samples
+	hann    []float64    // Window function against spectral leakage
+	fft     []complex128 // FFT input and output
+	edges   []int        // First FFT bin of each band, then the end
+	bands   []float32    // Level of each band, from 0 (silence) to 1
+	scratch []complex128 // Bit-reversal copy
+}
+
+// NewSpectrumAnalyzer creates an analyzer for audio at sampleRate that groups
+// the spectrum into the given number of bands
+func NewSpectrumAnalyzer(sampleRate float64, bands int) *SpectrumAnalyzer {
+	if sampleRate <= 0 {
+		sampleRate = TargetSampleRate
+	}
+	if bands <= 0 {
+		bands = SpectrumBands
+	}
+
+	s := &SpectrumAnalyzer{
+		window:  make([]float32, SpectrumSize),
+		hann:    make([]float64, SpectrumSize),
+		fft:     make([]complex128, SpectrumSize),
+		scratch: make([]complex128, SpectrumSize),
+		bands:   make([]float32, bands),
+		edges:   spectrumEdges(sampleRate, SpectrumSize, bands),
+	}
+	for i := range s.hann {
+		s.hann[i] = 0.5 - 0.5*math.Cos(2*math.Pi*float64(i)/float64(SpectrumSize-1))
+	}
+	return s
+}
+
+// spectrumEdges returns the first FFT bin of each band and the end of the
+// last, spacing the bands logarithmically from spectrumMinFreq to the
+// Nyquist frequency. Every band covers at least one bin.
+func spectrumEdges(sampleRate float64, size, bands int) []int {
+	nyquist := size / 2
+	binWidth := sampleRate / float64(size)
+	ratio := (sampleRate / 2) / spectrumMinFreq
+
+	edges := make([]int, bands+1)
+	for i := range edges {
+		freq := spectrumMinFreq * math.Pow(ratio, float64(i)/float64(bands))
+		edges[i] = min(max(int(freq/binWidth), 1), nyquist)
+		if i > 0 && edges[i] <= edges[i-1] {
+			edges[i] = edges[i-1] + 1
+		}
+	}
+	// Low bands pushed apart above may have run past the end
+	for i := bands; i >= 0; i-- {
+		limit := nyquist - (bands - i)
+		if edges[i] > limit {
+			edges[i] = limit
+		}
+	}
+	return edges
+}
+
+// Analyze adds samples to the analyzed audio and returns the level of each
+// band, from low to high frequencies. The returned slice is reused by the
+// next call.
+func (s *SpectrumAnalyzer) Analyze(samples []float32) []float32 {
+	if len(samples) >= len(s.window) {
+		copy(s.window, samples[len(samples)-len(s.window):])
+	} else {
+		copy(s.window, s.window[len(samples):])
+		copy(s.window[len(s.window)-len(samples):], samples)
+	}
+
+	for i, sample := range s.window {
+		s.fft[i] = complex(float64(sample)*s.hann[i], 0)
+	}
+	fft(s.fft, s.scratch)
+
+	// Full-scale sine in a bin has magnitude size/4 after the Hann window
+	fullScale := float64(len(s.fft)) / 4
+	for band := range s.bands {
+		var peak float64
+		for bin := s.edges[band]; bin < s.edges[band+1]; bin++ {
+			peak = max(peak, cmplx.Abs(s.fft[bin]))
+		}
+		db := spectrumFloorDB
+		if peak > 0 {
+			db = max(20*math.Log10(peak/fullScale), spectrumFloorDB)
+		}
+		s.bands[band] = float32(1 - db/spectrumFloorDB)
+	}
+	return s.bands
+}
+
+// fft transforms x in place with the iterative radix-2 Cooley-Tukey
+// algorithm. The length of x must be a power of two; scratch must be as long.
+func fft(x, scratch []complex128) {
+	n := len(x)
+	bits := 0
+	for 1<<bits < n {
+		bits++
+	}
+
+	// Reorder the input by bit-reversed index
+	for i := range x {
+		rev := 0
+		for b := 0; b < bits; b++ {
+			rev |= (i >> b & 1) << (bits - 1 - b)
+		}
+		scratch[rev] = x[i]
+	}
+	copy(x, scratch)
+
+	for size := 2; size <= n; size <<= 1 {
+		step := cmplx.Exp(complex(0, -2*math.Pi/float64(size)))
+		for start := 0; start < n; start += size {
+			w := complex(1, 0)
+			for k := 0; k < size/2; k++ {
+				even, odd := x[start+k], w*x[start+k+size/2]
+				x[start+k], x[start+k+size/2] = even+odd, even-odd
+				w *= step
+			}
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package audio
+
+import (
+	"math"
+	"testing"
+)
+
+// TestSpectrumAnalyzer tests that a tone shows up in the band holding its
+// frequency and silence shows nothing
+func TestSpectrumAnalyzer(t *testing.T) {
+	s := NewSpectrumAnalyzer(TargetSampleRate, SpectrumBands)
+
+	for _, level := range s.Analyze(make([]float32, 256)) {
+		if level != 0 {
+			t.Fatalf("Expected silence, got %v", s.bands)
+		}
+	}
+
+	const freq = 1000.0
+	tone := make([]float32, SpectrumSize)
+	for i := range tone {
+		tone[i] = 0.5 * float32(math.Sin(2*math.Pi*freq*float64(i)/TargetSampleRate))
+	}
+	bands := s.Analyze(tone)
+
+	loudest := 0
+	for i, level := range bands {
+		if level > bands[loudest] {
+			loudest = i
+		}
+	}
+	binWidth := TargetSampleRate / float64(SpectrumSize)
+	low, high := float64(s.edges[loudest])*binWidth, float64(s.edges[loudest+1])*binWidth
+	if freq < low-binWidth || freq > high+binWidth {
+		t.Errorf("Expected the loudest band to hold %vHz, got %v-%vHz", freq, low, high)
+	}
+	// A -6dBFS tone is near the top of the 90dB range
+	if bands[loudest] < 0.85 || bands[loudest] > 1 {
+		t.Errorf("Expected a level near full scale, got %v", bands[loudest])
+	}
+	if bands[0] > 0.5 {
+		t.Errorf("Expected little energy far from the tone, got %v", bands[0])
+	}
+}
+
+// TestSpectrumEdges tests that every band covers at least one FFT bin
+func TestSpectrumEdges(t *testing.T) {
+	for _, bands := range []int{8, SpectrumBands, 200} {
+		edges := spectrumEdges(TargetSampleRate, SpectrumSize, bands)
+		if len(edges) != bands+1 || edges[0] < 1 || edges[bands] > SpectrumSize/2 {
+			t.Fatalf("Unexpected edges for %d bands: %v", bands, edges)
+		}
+		for i := 1; i < len(edges); i++ {
+			if edges[i] <= edges[i-1] {
+				t.Fatalf("Expected increasing edges for %d bands, got %v", bands, edges)
+			}
+		}
+	}
+}
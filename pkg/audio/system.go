@@ -0,0 +1,182 @@
+package audio
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/gordonklaus/portaudio"
+)
+
+// PortAudio's initialization is process-wide and counted: each Initialize
+// needs a Terminate, and only the last Terminate shuts it down. Everything in
+// this package initializes it through acquireAudio and releaseAudio so the
+// references held here are known.
+var (
+	audioRefs   int
+	audioRefsMu sync.Mutex
+)
+
+// acquireAudio initializes PortAudio, or takes another reference to it
+func acquireAudio() error {
+	audioRefsMu.Lock()
+	defer audioRefsMu.Unlock()
+
+	if err := portaudio.Initialize(); err != nil {
+		return err
+	}
+	audioRefs++
+	return nil
+}
+
+// releaseAudio drops a reference taken by acquireAudio
+func releaseAudio() error {
+	audioRefsMu.Lock()
+	defer audioRefsMu.Unlock()
+
+	if audioRefs == 0 {
+		return nil
+	}
+	audioRefs--
+	return portaudio.Terminate()
+}
+
+// refreshDevices restarts PortAudio so it lists devices plugged in since it
+// was initialized. Restarting would stop every other stream in the process,
+// so it is only done while the caller holds the only reference; otherwise
+// the devices PortAudio already knows are used. If PortAudio can't be
+// initialized again the caller's reference is gone and released is true.
+func refreshDevices() (released bool, err error) {
+	audioRefsMu.Lock()
+	defer audioRefsMu.Unlock()
+
+	if audioRefs != 1 {
+		return false, nil
+	}
+	if err := portaudio.Terminate(); err != nil {
+		return false, err
+	}
+	if err := portaudio.Initialize(); err != nil {
+		audioRefs = 0
+		return true, err
+	}
+	return false, nil
+}
+
+// portaudioBackend records through PortAudio
+type portaudioBackend struct{}
+
+func (portaudioBackend) Name() string { return "portaudio" }
+
+func (portaudioBackend) Acquire() error { return acquireAudio() }
+
+func (portaudioBackend) Release() error { return releaseAudio() }
+
+func (portaudioBackend) Refresh() (bool, error) { return refreshDevices() }
+
+func (portaudioBackend) Devices() ([]string, error) {
+	devices, err := portaudio.Devices()
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, device := range devices {
+		if device.MaxInputChannels > 0 {
+			names = append(names, device.Name)
+		}
+	}
+	return names, nil
+}
+
+func (portaudioBackend) DefaultDevice() (string, error) {
+	device, err := portaudio.DefaultInputDevice()
+	if err != nil {
+		return "", err
+	}
+	if device == nil {
+		return "", errDeviceMissing
+	}
+	return device.Name, nil
+}
+
+func (portaudioBackend) Open(name string, fallback bool, sampleRate float64, framesPerBuffer int, callback func([]float32)) (Stream, string, error) {
+	process := func(input, _ []float32) {
+		callback(input)
+	}
+
+	// Without a device to look for, let PortAudio pick its default stream
+	var stream *portaudio.Stream
+	if name == "" && fallback {
+		var err error
+		stream, err = portaudio.OpenDefaultStream(1, 0, sampleRate, framesPerBuffer, process)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to open audio stream: %w", err)
+		}
+		if device, err := portaudio.DefaultInputDevice(); err == nil && device != nil {
+			name = device.Name
+		}
+	} else {
+		devices, err := portaudio.Devices()
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to list audio devices: %w", err)
+		}
+		var defaultDevice *portaudio.DeviceInfo
+		if fallback {
+			defaultDevice, _ = portaudio.DefaultInputDevice()
+		}
+		device := chooseDevice(devices, name, defaultDevice)
+		if device == nil {
+			return nil, "", errDeviceMissing
+		}
+
+		params := portaudio.LowLatencyParameters(device, nil)
+		params.Input.Channels = 1
+		params.SampleRate = sampleRate
+		params.FramesPerBuffer = framesPerBuffer
+		stream, err = portaudio.OpenStream(params, process)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to open audio stream on %q: %w", device.Name, err)
+		}
+		name = device.Name
+	}
+
+	if err := stream.Start(); err != nil {
+		stream.Close()
+		return nil, "", fmt.Errorf("failed to start audio stream on %q: %w", name, err)
+	}
+	return portaudioStream{stream}, name, nil
+}
+
+// portaudioStream is a started PortAudio stream
+type portaudioStream struct {
+	stream *portaudio.Stream
+}
+
+func (s portaudioStream) Stop() error {
+	if err := s.stream.Stop(); err != nil {
+		s.stream.Close()
+		return fmt.Errorf("failed to stop audio stream: %w", err)
+	}
+	if err := s.stream.Close(); err != nil {
+		return fmt.Errorf("failed to close audio stream: %w", err)
+	}
+	return nil
+}
+
+func (s portaudioStream) Abort() error {
+	s.stream.Abort()
+	return s.stream.Close()
+}
+
+// chooseDevice picks the input device named name, or defaultDevice if it is
+// missing. defaultDevice is nil unless falling back to it is allowed.
+func chooseDevice(devices []*portaudio.DeviceInfo, name string, defaultDevice *portaudio.DeviceInfo) *portaudio.DeviceInfo {
+	for _, device := range devices {
+		if device.Name == name && device.MaxInputChannels > 0 {
+			return device
+		}
+	}
+	if defaultDevice != nil && defaultDevice.MaxInputChannels > 0 {
+		return defaultDevice
+	}
+	return nil
+}
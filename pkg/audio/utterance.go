@@ -0,0 +1,81 @@
+package audio
+
+import "time"
+
+// SpeechLevel is the RMS level above which audio counts as speech rather than
+// background noise
+const SpeechLevel = 0.015
+
+// UtteranceDetector finds the end of an utterance: speech followed by a pause
+// of at least the configured length
+type UtteranceDetector struct {
+	silenceSamples int  // Samples of silence that end an utterance
+	silent         int  // Consecutive silent samples so far
+	speaking       bool // Speech was heard since the last end of utterance
+}
+
+// NewUtteranceDetector creates a detector for audio at sampleRate that ends an
+// utterance after the given length of silence
+func NewUtteranceDetector(sampleRate float64, silence time.Duration) *UtteranceDetector {
+	return &UtteranceDetector{silenceSamples: int(sampleRate * silence.Seconds())}
+}
+
+// Process examines the next chunk of audio and reports whether it completes
+// the pause that ends an utterance. Each utterance ends only once, so silence
+// without speech in between is never reported.
+func (d *UtteranceDetector) Process(samples []float32) bool {
+	if len(samples) == 0 {
+		return false
+	}
+	if CalculateLevel(samples) >= SpeechLevel {
+		d.speaking = true
+		d.silent = 0
+		return false
+	}
+	if !d.speaking {
+		return false
+	}
+
+	d.silent += len(samples)
+	if d.silent < d.silenceSamples {
+		return false
+	}
+	d.speaking = false
+	d.silent = 0
+	return true
+}
+
+// SilenceDetector finds long silences, e.g. in a recording the user forgot
+// to stop
+type SilenceDetector struct {
+	limitSamples int  // Samples of silence reported
+	silent       int  // Consecutive silent samples so far
+	reported     bool // The silence so far was reported
+}
+
+// NewSilenceDetector creates a detector for audio at sampleRate that reports
+// silence lasting limit
+func NewSilenceDetector(sampleRate float64, limit time.Duration) *SilenceDetector {
+	return &SilenceDetector{limitSamples: int(sampleRate * limit.Seconds())}
+}
+
+// Process examines the next chunk of audio and reports whether it completes
+// the limit of continuous silence. A silence is reported once, however long
+// it lasts.
+func (d *SilenceDetector) Process(samples []float32) bool {
+	if len(samples) == 0 {
+		return false
+	}
+	if CalculateLevel(samples) >= SpeechLevel {
+		d.silent = 0
+		d.reported = false
+		return false
+	}
+
+	d.silent += len(samples)
+	if d.reported || d.silent < d.limitSamples {
+		return false
+	}
+	d.reported = true
+	return true
+}
@@ -0,0 +1,78 @@
+package audio
+
+import (
+	"testing"
+	"time"
+)
+
+// chunk returns 100ms of audio at 16kHz with a constant level
+func chunk(level float32) []float32 {
+	samples := make([]float32, 1600)
+	for i := range samples {
+		samples[i] = level
+	}
+	return samples
+}
+
+// TestUtteranceDetector tests that a pause after speech ends an utterance once
+func TestUtteranceDetector(t *testing.T) {
+	d := NewUtteranceDetector(16000, 300*time.Millisecond)
+
+	// Silence before anyone speaks doesn't end anything
+	for i := 0; i < 5; i++ {
+		if d.Process(chunk(0)) {
+			t.Fatal("Expected no utterance before speech")
+		}
+	}
+
+	// A short pause within speech doesn't end the utterance
+	steps := []struct {
+		level float32
+		ended bool
+	}{
+		{0.2, false},
+		{0, false},
+		{0, false},
+		{0.2, false},
+		{0, false},
+		{0.001, false},
+		{0, true},
+		{0, false},
+		{0, false},
+		{0, false},
+	}
+	for i, step := range steps {
+		if ended := d.Process(chunk(step.level)); ended != step.ended {
+			t.Errorf("Step %d: expected ended=%v, got %v", i, step.ended, ended)
+		}
+	}
+}
+
+// TestSilenceDetector tests that a long silence is reported once, and that
+// speech starts the count again
+func TestSilenceDetector(t *testing.T) {
+	d := NewSilenceDetector(16000, 300*time.Millisecond)
+
+	steps := []struct {
+		level    float32
+		reported bool
+	}{
+		{0, false},
+		{0, false},
+		{0.2, false},
+		{0, false},
+		{0.001, false},
+		{0, true},
+		{0, false},
+		{0, false},
+		{0.2, false},
+		{0, false},
+		{0, false},
+		{0, true},
+	}
+	for i, step := range steps {
+		if reported := d.Process(chunk(step.level)); reported != step.reported {
+			t.Errorf("Step %d: expected reported=%v, got %v", i, step.reported, reported)
+		}
+	}
+}
@@ -0,0 +1,214 @@
+// Package wav reads and writes WAV files. It has no dependency on an audio
+// system, so files can be handled where none is available.
+package wav
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+)
+
+// Sample encodings of the fmt chunk
+const (
+	formatPCM        = 1
+	formatFloat      = 3
+	formatExtensible = 0xFFFE // The real encoding is in the sub-format
+)
+
+// maxChannels bounds the channel count accepted, so a corrupt header can't
+// make the reader allocate a slice per channel by the thousand
+const maxChannels = 64
+
+// ErrNotWAV is returned for data that isn't a RIFF WAVE file
+var ErrNotWAV = errors.New("not a valid WAV file")
+
+// Format describes the samples of a WAV file
+type Format struct {
+	Channels      int
+	SampleRate    int
+	BitsPerSample int  // Size of each sample in the file: 8, 16, 24, 32 or 64
+	Float         bool // Samples are IEEE floats rather than integer PCM
+}
+
+// ReadFile reads the WAV file at path; see Read
+func ReadFile(path string) ([][]float32, Format, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, Format{}, fmt.Errorf("failed to open WAV file: %w", err)
+	}
+	defer file.Close()
+	return Read(file)
+}
+
+// Read parses a WAV file, returning each channel's samples scaled to
+// [-1, 1]. It walks the file's chunks, so LIST, INFO and other chunks are
+// skipped wherever they are, and reads integer PCM of 8, 16, 24 or 32 bits
+// and 32 or 64-bit float samples, plainly or in WAVE_FORMAT_EXTENSIBLE.
+// A data chunk whose size was never filled in, as left by a recorder that
+// stopped early, is read to the end of the file, and a partial last frame
+// is dropped.
+func Read(r io.Reader) ([][]float32, Format, error) {
+	format, data, err := ReadHeader(r)
+	if err != nil {
+		return nil, format, err
+	}
+	raw, err := io.ReadAll(data)
+	if err != nil {
+		return nil, format, fmt.Errorf("failed to read WAV data: %w", err)
+	}
+	return Decode(raw, format), format, nil
+}
+
+// ReadHeader parses a WAV file up to its samples, returning their format and
+// a reader of the data chunk, for reading a stream as it arrives. A data
+// chunk without a size, as written by recorders piping to another program,
+// is read until r ends.
+func ReadHeader(r io.Reader) (Format, io.Reader, error) {
+	reader := bufio.NewReader(r)
+
+	var riff [12]byte
+	if _, err := io.ReadFull(reader, riff[:]); err != nil || string(riff[0:4]) != "RIFF" || string(riff[8:12]) != "WAVE" {
+		return Format{}, nil, ErrNotWAV
+	}
+
+	var format Format
+	haveFormat := false
+	for {
+		var header [8]byte
+		if _, err := io.ReadFull(reader, header[:]); err != nil {
+			if haveFormat {
+				return format, nil, errors.New("WAV file has no data chunk")
+			}
+			return format, nil, errors.New("WAV file has no fmt chunk")
+		}
+		id := string(header[0:4])
+		size := int64(binary.LittleEndian.Uint32(header[4:8]))
+
+		switch id {
+		case "fmt ":
+			body := make([]byte, min(size, 1024))
+			if _, err := io.ReadFull(reader, body); err != nil {
+				return format, nil, fmt.Errorf("failed to read WAV format: %w", err)
+			}
+			var err error
+			if format, err = parseFormat(body); err != nil {
+				return format, nil, err
+			}
+			haveFormat = true
+			if err := skip(reader, size-int64(len(body))+size%2); err != nil {
+				return format, nil, errors.New("WAV file has no data chunk")
+			}
+
+		case "data":
+			if !haveFormat {
+				return format, nil, errors.New("WAV data comes before its format")
+			}
+			// Sizes of 0 or 0xFFFFFFFF are placeholders never filled in
+			if size != 0 && size != math.MaxUint32 {
+				return format, io.LimitReader(reader, size), nil
+			}
+			return format, reader, nil
+
+		default:
+			// Chunks are padded to an even size
+			if err := skip(reader, size+size%2); err != nil {
+				return format, nil, fmt.Errorf("WAV file ends inside its %q chunk", id)
+			}
+		}
+	}
+}
+
+// FrameSize returns the size in bytes of one sample of every channel
+func (f Format) FrameSize() int {
+	return f.BitsPerSample / 8 * f.Channels
+}
+
+// parseFormat reads the body of a fmt chunk
+func parseFormat(body []byte) (Format, error) {
+	if len(body) < 16 {
+		return Format{}, errors.New("WAV format chunk is too short")
+	}
+	encoding := binary.LittleEndian.Uint16(body[0:2])
+	format := Format{
+		Channels:      int(binary.LittleEndian.Uint16(body[2:4])),
+		SampleRate:    int(binary.LittleEndian.Uint32(body[4:8])),
+		BitsPerSample: int(binary.LittleEndian.Uint16(body[14:16])),
+	}
+	if encoding == formatExtensible {
+		// The sub-format GUID starts with the encoding
+		if len(body) < 26 {
+			return format, errors.New("WAV extensible format chunk is too short")
+		}
+		encoding = binary.LittleEndian.Uint16(body[24:26])
+	}
+
+	switch {
+	case encoding == formatFloat && (format.BitsPerSample == 32 || format.BitsPerSample == 64):
+		format.Float = true
+	case encoding == formatPCM && (format.BitsPerSample == 8 || format.BitsPerSample == 16 ||
+		format.BitsPerSample == 24 || format.BitsPerSample == 32):
+	default:
+		return format, fmt.Errorf("unsupported WAV encoding %d with %d bits per sample", encoding, format.BitsPerSample)
+	}
+	if format.Channels < 1 || format.Channels > maxChannels {
+		return format, fmt.Errorf("unsupported WAV channel count: %d", format.Channels)
+	}
+	if format.SampleRate <= 0 {
+		return format, fmt.Errorf("invalid WAV sample rate: %d", format.SampleRate)
+	}
+	return format, nil
+}
+
+// Decode de-interleaves raw sample data into one slice per channel, scaled
+// to [-1, 1]. A partial last frame is dropped.
+func Decode(raw []byte, format Format) [][]float32 {
+	size := format.BitsPerSample / 8
+	frames := len(raw) / format.FrameSize()
+
+	channels := make([][]float32, format.Channels)
+	for c := range channels {
+		channels[c] = make([]float32, frames)
+	}
+	for i := 0; i < frames; i++ {
+		for c := range channels {
+			offset := (i*format.Channels + c) * size
+			channels[c][i] = sample(raw[offset:offset+size], format.Float)
+		}
+	}
+	return channels
+}
+
+// sample converts one little endian sample to [-1, 1]
+func sample(b []byte, float bool) float32 {
+	switch {
+	case float && len(b) == 4:
+		return math.Float32frombits(binary.LittleEndian.Uint32(b))
+	case float:
+		return float32(math.Float64frombits(binary.LittleEndian.Uint64(b)))
+	}
+
+	switch len(b) {
+	case 1:
+		// 8-bit PCM is unsigned
+		return float32(int(b[0])-128) / 128
+	case 2:
+		return float32(int16(binary.LittleEndian.Uint16(b))) / 32768
+	case 3:
+		return float32(int32(uint32(b[0])<<8|uint32(b[1])<<16|uint32(b[2])<<24)>>8) / 8388608
+	default:
+		return float32(int32(binary.LittleEndian.Uint32(b))) / 2147483648
+	}
+}
+
+// skip discards n bytes
+func skip(r io.Reader, n int64) error {
+	if n <= 0 {
+		return nil
+	}
+	_, err := io.CopyN(io.Discard, r, n)
+	return err
+}
@@ -0,0 +1,187 @@
+package wav
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+	"testing"
+)
+
+// chunk builds a chunk with its header and padding
+func chunk(id string, body []byte) []byte {
+	out := append([]byte(id), 0, 0, 0, 0)
+	binary.LittleEndian.PutUint32(out[4:8], uint32(len(body)))
+	out = append(out, body...)
+	if len(body)%2 == 1 {
+		out = append(out, 0)
+	}
+	return out
+}
+
+// formatChunk builds a fmt chunk body
+func formatChunk(encoding, channels, sampleRate, bits int) []byte {
+	body := make([]byte, 16)
+	binary.LittleEndian.PutUint16(body[0:2], uint16(encoding))
+	binary.LittleEndian.PutUint16(body[2:4], uint16(channels))
+	binary.LittleEndian.PutUint32(body[4:8], uint32(sampleRate))
+	binary.LittleEndian.PutUint32(body[8:12], uint32(sampleRate*channels*bits/8))
+	binary.LittleEndian.PutUint16(body[12:14], uint16(channels*bits/8))
+	binary.LittleEndian.PutUint16(body[14:16], uint16(bits))
+	return body
+}
+
+// file builds a RIFF WAVE file from chunks
+func file(chunks ...[]byte) []byte {
+	body := []byte("WAVE")
+	for _, c := range chunks {
+		body = append(body, c...)
+	}
+	return chunk("RIFF", body)
+}
+
+func TestReadEncodings(t *testing.T) {
+	float32s := binary.LittleEndian.AppendUint32(nil, math.Float32bits(0.5))
+	float32s = binary.LittleEndian.AppendUint32(float32s, math.Float32bits(-0.25))
+	float64s := binary.LittleEndian.AppendUint64(nil, math.Float64bits(0.5))
+	float64s = binary.LittleEndian.AppendUint64(float64s, math.Float64bits(-0.25))
+
+	extensible := append(formatChunk(formatExtensible, 1, 48000, 24), 22, 0, 24, 0, 4, 0, 0, 0)
+	extensible = append(extensible, 1, 0, 0, 0, 0, 0, 0x10, 0, 0x80, 0, 0, 0xAA, 0, 0x38, 0x9B, 0x71)
+
+	tests := []struct {
+		name   string
+		format []byte
+		data   []byte
+		float  bool
+	}{
+		{"8-bit", formatChunk(formatPCM, 1, 8000, 8), []byte{192, 96}, false},
+		{"16-bit", formatChunk(formatPCM, 1, 16000, 16), []byte{0x00, 0x40, 0x00, 0xE0}, false},
+		{"24-bit", formatChunk(formatPCM, 1, 44100, 24), []byte{0, 0, 0x40, 0, 0, 0xE0}, false},
+		{"32-bit", formatChunk(formatPCM, 1, 44100, 32), []byte{0, 0, 0, 0x40, 0, 0, 0, 0xE0}, false},
+		{"float", formatChunk(formatFloat, 1, 48000, 32), float32s, true},
+		{"double", formatChunk(formatFloat, 1, 48000, 64), float64s, true},
+		{"extensible", extensible, []byte{0, 0, 0x40, 0, 0, 0xE0}, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			channels, format, err := Read(bytes.NewReader(file(chunk("fmt ", test.format), chunk("data", test.data))))
+			if err != nil {
+				t.Fatalf("Failed to read: %v", err)
+			}
+			if format.Float != test.float || len(channels) != 1 {
+				t.Fatalf("Expected one channel with float %v, got %+v", test.float, format)
+			}
+			expected := []float32{0.5, -0.25}
+			if len(channels[0]) != len(expected) {
+				t.Fatalf("Expected %d samples, got %d", len(expected), len(channels[0]))
+			}
+			for i, want := range expected {
+				if math.Abs(float64(channels[0][i]-want)) > 0.01 {
+					t.Errorf("Sample %d: expected %f, got %f", i, want, channels[0][i])
+				}
+			}
+		})
+	}
+}
+
+func TestReadChunks(t *testing.T) {
+	format := formatChunk(formatPCM, 2, 22050, 16)
+	data := []byte{0x00, 0x40, 0x00, 0xC0, 0x00, 0x20, 0x00, 0xE0}
+
+	// An odd-sized LIST chunk before the format and data is padded and skipped
+	channels, got, err := Read(bytes.NewReader(file(chunk("LIST", []byte("INFOabc")), chunk("fmt ", format), chunk("fact", make([]byte, 4)), chunk("data", data), chunk("id3 ", []byte("tag")))))
+	if err != nil {
+		t.Fatalf("Failed to read: %v", err)
+	}
+	if got != (Format{Channels: 2, SampleRate: 22050, BitsPerSample: 16}) {
+		t.Errorf("Expected stereo 16-bit at 22050 Hz, got %+v", got)
+	}
+	if len(channels) != 2 || len(channels[0]) != 2 || channels[0][1] != 0.25 || channels[1][0] != -0.5 {
+		t.Errorf("Expected two de-interleaved channels, got %v", channels)
+	}
+
+	// A size left at 0 by a recorder that stopped reads to the end, dropping
+	// the partial frame
+	truncated := file(chunk("fmt ", format), chunk("data", nil))
+	truncated = append(truncated, append(data, 0x01)...)
+	channels, _, err = Read(bytes.NewReader(truncated))
+	if err != nil {
+		t.Fatalf("Failed to read a file without a data size: %v", err)
+	}
+	if len(channels[0]) != 2 {
+		t.Errorf("Expected 2 frames, got %d", len(channels[0]))
+	}
+
+	// So does a size beyond the end of the file
+	cut := file(chunk("fmt ", format), chunk("data", data))
+	channels, _, err = Read(bytes.NewReader(cut[:len(cut)-3]))
+	if err != nil {
+		t.Fatalf("Failed to read a cut file: %v", err)
+	}
+	if len(channels[0]) != 1 {
+		t.Errorf("Expected 1 whole frame, got %d", len(channels[0]))
+	}
+}
+
+func TestReadInvalid(t *testing.T) {
+	data := chunk("data", make([]byte, 4))
+	tests := []struct {
+		name  string
+		input []byte
+	}{
+		{"empty", nil},
+		{"not RIFF", append([]byte("RIFX\x00\x00\x00\x00WAVE"), data...)},
+		{"no format", file(data)},
+		{"no data", file(chunk("fmt ", formatChunk(formatPCM, 1, 16000, 16)))},
+		{"short format", file(chunk("fmt ", make([]byte, 10)), data)},
+		{"no channels", file(chunk("fmt ", formatChunk(formatPCM, 0, 16000, 16)), data)},
+		{"too many channels", file(chunk("fmt ", formatChunk(formatPCM, 1000, 16000, 16)), data)},
+		{"no sample rate", file(chunk("fmt ", formatChunk(formatPCM, 1, 0, 16)), data)},
+		{"12-bit", file(chunk("fmt ", formatChunk(formatPCM, 1, 16000, 12)), data)},
+		{"16-bit float", file(chunk("fmt ", formatChunk(formatFloat, 1, 16000, 16)), data)},
+		{"compressed", file(chunk("fmt ", formatChunk(2, 1, 16000, 4)), data)},
+		{"chunk past the end", file(chunk("fmt ", formatChunk(formatPCM, 1, 16000, 16)), []byte("LIST\xff\xff\x00\x00"))},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, _, err := Read(bytes.NewReader(test.input)); err == nil {
+				t.Error("Expected an error")
+			}
+		})
+	}
+
+	if _, _, err := Read(bytes.NewReader([]byte("not a wav file"))); !errors.Is(err, ErrNotWAV) {
+		t.Errorf("Expected ErrNotWAV, got %v", err)
+	}
+}
+
+func FuzzRead(f *testing.F) {
+	format := formatChunk(formatPCM, 2, 16000, 16)
+	f.Add(file(chunk("fmt ", format), chunk("data", make([]byte, 16))))
+	f.Add(file(chunk("LIST", []byte("INFO")), chunk("fmt ", formatChunk(formatFloat, 1, 48000, 32)), chunk("data", make([]byte, 8))))
+	f.Add(file(chunk("fmt ", formatChunk(formatPCM, 1, 8000, 24)), chunk("data", nil), make([]byte, 7)))
+	f.Add([]byte("RIFF\x00\x00\x00\x00WAVE"))
+
+	f.Fuzz(func(t *testing.T, input []byte) {
+		channels, format, err := Read(bytes.NewReader(input))
+		if err != nil {
+			return
+		}
+		if len(channels) != format.Channels || format.Channels < 1 || format.SampleRate <= 0 {
+			t.Fatalf("Inconsistent result: %d channels for %+v", len(channels), format)
+		}
+		for _, channel := range channels {
+			if len(channel) != len(channels[0]) {
+				t.Fatal("Expected channels of equal length")
+			}
+			if !format.Float {
+				for _, v := range channel {
+					if v < -1 || v > 1 {
+						t.Fatalf("Sample %f out of range", v)
+					}
+				}
+			}
+		}
+	})
+}
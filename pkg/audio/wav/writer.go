@@ -0,0 +1,183 @@
+package wav
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+)
+
+// headerSize is the size of the header written: RIFF, fmt and data chunk
+// headers with a 16-byte format
+const headerSize = 44
+
+// maxDataSize is the most sample data a WAV file's 32-bit sizes can describe
+const maxDataSize = math.MaxUint32 - headerSize + 8
+
+// errTooLarge is returned when samples would take a file past 4GB
+var errTooLarge = errors.New("WAV file would exceed 4GB")
+
+// Writer streams samples to a WAV file. Samples are encoded as they are
+// written, so a recording of any length never has to be held in memory, and
+// the header's sizes are kept by the writer and filled in by Flush and Close
+// rather than read back from the file. Until the first Flush the data size
+// is zero, which Read takes as "to the end of the file", so a recording cut
+// short by a crash stays readable.
+type Writer struct {
+	out    io.WriteSeeker
+	buffer *bufio.Writer
+	file   *os.File // Closed by Close if the writer created it
+	format Format
+	start  int64 // Offset of the header in out
+	data   int64 // Bytes of sample data written
+	frame  []byte
+}
+
+// Create creates the file at path, replacing any existing file, and returns
+// a writer for it
+func Create(path string, format Format) (*Writer, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create WAV file: %w", err)
+	}
+	w, err := NewWriter(file, format)
+	if err != nil {
+		file.Close()
+		os.Remove(path)
+		return nil, err
+	}
+	w.file = file
+	return w, nil
+}
+
+// NewWriter writes a WAV header to out and returns a writer for samples
+// following it. The format may be integer PCM of 8, 16, 24 or 32 bits or
+// 32-bit float.
+func NewWriter(out io.WriteSeeker, format Format) (*Writer, error) {
+	if format.Float && format.BitsPerSample != 32 ||
+		!format.Float && format.BitsPerSample != 8 && format.BitsPerSample != 16 &&
+			format.BitsPerSample != 24 && format.BitsPerSample != 32 {
+		return nil, fmt.Errorf("unsupported WAV format: %d bits per sample", format.BitsPerSample)
+	}
+	if format.Channels < 1 || format.Channels > maxChannels || format.SampleRate <= 0 {
+		return nil, fmt.Errorf("unsupported WAV format: %d channels at %d Hz", format.Channels, format.SampleRate)
+	}
+
+	start, err := out.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, fmt.Errorf("failed to write WAV header: %w", err)
+	}
+	w := &Writer{
+		out:    out,
+		buffer: bufio.NewWriter(out),
+		format: format,
+		start:  start,
+		frame:  make([]byte, format.Channels*format.BitsPerSample/8),
+	}
+	if _, err := w.buffer.Write(w.header()); err != nil {
+		return nil, fmt.Errorf("failed to write WAV header: %w", err)
+	}
+	return w, nil
+}
+
+// header returns the file header for the data written so far
+func (w *Writer) header() []byte {
+	encoding := formatPCM
+	if w.format.Float {
+		encoding = formatFloat
+	}
+	blockAlign := len(w.frame)
+
+	h := make([]byte, headerSize)
+	copy(h[0:4], "RIFF")
+	copy(h[8:16], "WAVEfmt ")
+	binary.LittleEndian.PutUint32(h[16:20], 16)
+	binary.LittleEndian.PutUint16(h[20:22], uint16(encoding))
+	binary.LittleEndian.PutUint16(h[22:24], uint16(w.format.Channels))
+	binary.LittleEndian.PutUint32(h[24:28], uint32(w.format.SampleRate))
+	binary.LittleEndian.PutUint32(h[28:32], uint32(w.format.SampleRate*blockAlign))
+	binary.LittleEndian.PutUint16(h[32:34], uint16(blockAlign))
+	binary.LittleEndian.PutUint16(h[34:36], uint16(w.format.BitsPerSample))
+	copy(h[36:40], "data")
+	binary.LittleEndian.PutUint32(h[4:8], uint32(headerSize-8+w.data))
+	binary.LittleEndian.PutUint32(h[40:44], uint32(w.data))
+	return h
+}
+
+// WriteSamples appends samples, interleaved if there are several channels;
+// a partial last frame is padded with silence. Samples are clamped to
+// [-1, 1] unless written as floats.
+func (w *Writer) WriteSamples(samples []float32) error {
+	size := w.format.BitsPerSample / 8
+	if w.data+int64(len(samples)*size) > maxDataSize {
+		return errTooLarge
+	}
+
+	for i := 0; i < len(samples); i += w.format.Channels {
+		frame := w.frame[:0]
+		for c := 0; c < w.format.Channels; c++ {
+			var v float32
+			if i+c < len(samples) {
+				v = samples[i+c]
+			}
+			frame = w.encode(frame, v)
+		}
+		if _, err := w.buffer.Write(frame); err != nil {
+			return fmt.Errorf("failed to write WAV samples: %w", err)
+		}
+		w.data += int64(len(frame))
+	}
+	return nil
+}
+
+// encode appends one sample in the writer's format
+func (w *Writer) encode(b []byte, v float32) []byte {
+	if w.format.Float {
+		return binary.LittleEndian.AppendUint32(b, math.Float32bits(v))
+	}
+
+	v = max(-1, min(1, v))
+	switch w.format.BitsPerSample {
+	case 8:
+		return append(b, byte(128+int(v*127)))
+	case 16:
+		return binary.LittleEndian.AppendUint16(b, uint16(int16(v*math.MaxInt16)))
+	case 24:
+		s := int32(v * 8388607)
+		return append(b, byte(s), byte(s>>8), byte(s>>16))
+	default:
+		return binary.LittleEndian.AppendUint32(b, uint32(int32(float64(v)*math.MaxInt32)))
+	}
+}
+
+// Flush writes buffered samples and updates the header's sizes to match, so
+// the file is complete as far as it goes
+func (w *Writer) Flush() error {
+	if err := w.buffer.Flush(); err != nil {
+		return fmt.Errorf("failed to write WAV samples: %w", err)
+	}
+	if _, err := w.out.Seek(w.start, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to update WAV header: %w", err)
+	}
+	if _, err := w.out.Write(w.header()); err != nil {
+		return fmt.Errorf("failed to update WAV header: %w", err)
+	}
+	if _, err := w.out.Seek(w.start+headerSize+w.data, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to update WAV header: %w", err)
+	}
+	return nil
+}
+
+// Close flushes the writer and closes the file if Create opened it
+func (w *Writer) Close() error {
+	err := w.Flush()
+	if w.file != nil {
+		if closeErr := w.file.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
@@ -0,0 +1,122 @@
+package wav
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriterRoundTrip(t *testing.T) {
+	samples := []float32{0, 0.5, -0.5, 0.25, -1, 1, 0.1, -0.1}
+	formats := []Format{
+		{Channels: 1, SampleRate: 16000, BitsPerSample: 16},
+		{Channels: 2, SampleRate: 44100, BitsPerSample: 8},
+		{Channels: 2, SampleRate: 48000, BitsPerSample: 24},
+		{Channels: 4, SampleRate: 8000, BitsPerSample: 32},
+		{Channels: 1, SampleRate: 22050, BitsPerSample: 32, Float: true},
+	}
+	for _, format := range formats {
+		path := filepath.Join(t.TempDir(), "round.wav")
+		w, err := Create(path, format)
+		if err != nil {
+			t.Fatalf("Failed to create %+v: %v", format, err)
+		}
+		// Written in two parts, as a recording arrives
+		if err := w.WriteSamples(samples[:4]); err != nil {
+			t.Fatalf("Failed to write: %v", err)
+		}
+		if err := w.WriteSamples(samples[4:]); err != nil {
+			t.Fatalf("Failed to write: %v", err)
+		}
+		if err := w.Close(); err != nil {
+			t.Fatalf("Failed to close: %v", err)
+		}
+
+		channels, got, err := ReadFile(path)
+		if err != nil {
+			t.Fatalf("Failed to read %+v back: %v", format, err)
+		}
+		if got != format {
+			t.Errorf("Expected %+v, got %+v", format, got)
+		}
+		tolerance := 0.001
+		if format.BitsPerSample == 8 {
+			tolerance = 0.01
+		}
+		for i, want := range samples {
+			v := channels[i%format.Channels][i/format.Channels]
+			if math.Abs(float64(v-want)) > tolerance {
+				t.Errorf("%d-bit sample %d: expected %f, got %f", format.BitsPerSample, i, want, v)
+			}
+		}
+	}
+}
+
+func TestWriterFlush(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "flush.wav")
+	w, err := Create(path, Format{Channels: 1, SampleRate: 16000, BitsPerSample: 16})
+	if err != nil {
+		t.Fatalf("Failed to create: %v", err)
+	}
+	defer w.Close()
+
+	// Flushed samples are readable while the file is still being written
+	w.WriteSamples(make([]float32, 100))
+	if err := w.Flush(); err != nil {
+		t.Fatalf("Failed to flush: %v", err)
+	}
+	w.WriteSamples(make([]float32, 50))
+	if err := w.Flush(); err != nil {
+		t.Fatalf("Failed to flush: %v", err)
+	}
+	channels, _, err := ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read a flushed file: %v", err)
+	}
+	if len(channels[0]) != 150 {
+		t.Errorf("Expected 150 samples, got %d", len(channels[0]))
+	}
+}
+
+func TestWriterUnflushed(t *testing.T) {
+	// A writer that never flushed its header, as after a crash, leaves the
+	// data size at zero; the samples that reached the file are still read
+	path := filepath.Join(t.TempDir(), "crash.wav")
+	w, err := Create(path, Format{Channels: 1, SampleRate: 16000, BitsPerSample: 16})
+	if err != nil {
+		t.Fatalf("Failed to create: %v", err)
+	}
+	w.WriteSamples(make([]float32, 5000))
+	w.buffer.Flush()
+	w.file.Close()
+
+	info, err := os.Stat(path)
+	if err != nil || info.Size() != headerSize+10000 {
+		t.Fatalf("Expected the samples on disk, got %v, %v", info, err)
+	}
+	channels, _, err := ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read: %v", err)
+	}
+	if len(channels[0]) != 5000 {
+		t.Errorf("Expected 5000 samples, got %d", len(channels[0]))
+	}
+}
+
+func TestWriterInvalidFormat(t *testing.T) {
+	for _, format := range []Format{
+		{Channels: 1, SampleRate: 16000, BitsPerSample: 12},
+		{Channels: 1, SampleRate: 16000, BitsPerSample: 16, Float: true},
+		{Channels: 0, SampleRate: 16000, BitsPerSample: 16},
+		{Channels: 1, SampleRate: 0, BitsPerSample: 16},
+	} {
+		path := filepath.Join(t.TempDir(), "invalid.wav")
+		if _, err := Create(path, format); err == nil {
+			t.Errorf("Expected %+v to be refused", format)
+		}
+		if _, err := os.Stat(path); err == nil {
+			t.Errorf("Expected no file left for %+v", format)
+		}
+	}
+}
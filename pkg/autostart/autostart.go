@@ -0,0 +1,219 @@
+// Package autostart starts Ramble when the user logs in. The entry is a
+// desktop file in the XDG autostart directory on Linux and the BSDs, a
+// LaunchAgent on macOS and a value under the Run key of the registry on
+// Windows.
+package autostart
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// Where the entry is kept on each platform
+const (
+	entryName        = "Ramble"
+	desktopFileName  = "ramble.desktop"
+	launchAgentLabel = "com.github.jeff-barlow-spady.ramble"
+	runKey           = `HKCU\Software\Microsoft\Windows\CurrentVersion\Run`
+)
+
+// Enabled reports whether Ramble is started at login
+func Enabled() bool {
+	if runtime.GOOS == "windows" {
+		return exec.Command("reg", "query", runKey, "/v", entryName).Run() == nil
+	}
+	path, err := entryPath()
+	if err != nil {
+		return false
+	}
+	_, err = os.Stat(path)
+	return err == nil
+}
+
+// Enable runs command at login, replacing any entry added before
+func Enable(command []string) error {
+	if len(command) == 0 {
+		return errors.New("no command to start at login")
+	}
+	if runtime.GOOS == "windows" {
+		if out, err := exec.Command("reg", "add", runKey, "/v", entryName, "/t", "REG_SZ", "/d", windowsCommandLine(command), "/f").CombinedOutput(); err != nil {
+			return fmt.Errorf("failed to add the Run key: %w: %s", err, strings.TrimSpace(string(out)))
+		}
+		return nil
+	}
+
+	path, err := entryPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create autostart directory: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(entryContents(runtime.GOOS, command)), 0644); err != nil {
+		return fmt.Errorf("failed to write autostart entry: %w", err)
+	}
+	return nil
+}
+
+// Disable stops Ramble being started at login
+func Disable() error {
+	if runtime.GOOS == "windows" {
+		if !Enabled() {
+			return nil
+		}
+		if out, err := exec.Command("reg", "delete", runKey, "/v", entryName, "/f").CombinedOutput(); err != nil {
+			return fmt.Errorf("failed to remove the Run key: %w: %s", err, strings.TrimSpace(string(out)))
+		}
+		return nil
+	}
+
+	path, err := entryPath()
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove autostart entry: %w", err)
+	}
+	return nil
+}
+
+// entryPath returns the file holding the entry on this platform
+func entryPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user home directory: %w", err)
+	}
+	return entryFile(runtime.GOOS, home, os.Getenv)
+}
+
+// entryFile returns the file holding the entry on goos for the user with
+// the given home directory
+func entryFile(goos, home string, getenv func(string) string) (string, error) {
+	switch goos {
+	case "darwin":
+		return filepath.Join(home, "Library", "LaunchAgents", launchAgentLabel+".plist"), nil
+	case "linux", "freebsd", "openbsd", "netbsd":
+		configDir := getenv("XDG_CONFIG_HOME")
+		if configDir == "" {
+			configDir = filepath.Join(home, ".config")
+		}
+		return filepath.Join(configDir, "autostart", desktopFileName), nil
+	default:
+		return "", fmt.Errorf("starting at login is not supported on %s", goos)
+	}
+}
+
+// entryContents returns the file running command at login on goos
+func entryContents(goos string, command []string) string {
+	if goos == "darwin" {
+		return launchAgent(command)
+	}
+	return desktopEntry(command)
+}
+
+// desktopEntry returns an XDG desktop file running command
+func desktopEntry(command []string) string {
+	args := make([]string, len(command))
+	for i, arg := range command {
+		args[i] = desktopQuote(arg)
+	}
+	return strings.Join([]string{
+		"[Desktop Entry]",
+		"Type=Application",
+		"Name=" + entryName,
+		"Comment=Speech-to-text transcription",
+		"Exec=" + strings.Join(args, " "),
+		"Terminal=false",
+		"X-GNOME-Autostart-enabled=true",
+		"",
+	}, "\n")
+}
+
+// desktopQuote quotes an argument of a desktop file's Exec key. Field codes
+// start with %, so a literal % is doubled.
+func desktopQuote(arg string) string {
+	arg = strings.ReplaceAll(arg, "%", "%%")
+	if arg != "" && !strings.ContainsAny(arg, " \t\n\"'\\><~|&;$*?#()`") {
+		return arg
+	}
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range arg {
+		if strings.ContainsRune("\"`$\\", r) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	b.WriteByte('"')
+	// The desktop file format escapes backslashes again
+	return strings.ReplaceAll(b.String(), `\`, `\\`)
+}
+
+// launchAgent returns a LaunchAgent property list running command at login
+func launchAgent(command []string) string {
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>Label</key>
+	<string>` + launchAgentLabel + `</string>
+	<key>ProgramArguments</key>
+	<array>
+`)
+	for _, arg := range command {
+		b.WriteString("\t\t<string>" + xmlEscape(arg) + "</string>\n")
+	}
+	b.WriteString(`	</array>
+	<key>RunAtLoad</key>
+	<true/>
+	<key>ProcessType</key>
+	<string>Interactive</string>
+</dict>
+</plist>
+`)
+	return b.String()
+}
+
+// xmlEscape escapes text for an XML element
+func xmlEscape(text string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(text)
+}
+
+// windowsCommandLine joins command into a command line, quoting arguments
+// the way Windows programs split them
+func windowsCommandLine(command []string) string {
+	args := make([]string, len(command))
+	for i, arg := range command {
+		if arg != "" && !strings.ContainsAny(arg, " \t\"") {
+			args[i] = arg
+			continue
+		}
+		var b strings.Builder
+		b.WriteByte('"')
+		backslashes := 0
+		for _, r := range arg {
+			switch r {
+			case '\\':
+				backslashes++
+				continue
+			case '"':
+				// Backslashes before a quote escape each other, and the quote
+				b.WriteString(strings.Repeat(`\`, backslashes*2+1))
+			default:
+				b.WriteString(strings.Repeat(`\`, backslashes))
+			}
+			backslashes = 0
+			b.WriteRune(r)
+		}
+		b.WriteString(strings.Repeat(`\`, backslashes*2))
+		b.WriteByte('"')
+		args[i] = b.String()
+	}
+	return strings.Join(args, " ")
+}
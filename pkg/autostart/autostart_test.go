@@ -0,0 +1,88 @@
+package autostart
+
+import (
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestEntryFile(t *testing.T) {
+	noEnv := func(string) string { return "" }
+	xdg := func(name string) string {
+		if name == "XDG_CONFIG_HOME" {
+			return "/xdg"
+		}
+		return ""
+	}
+
+	tests := []struct {
+		goos   string
+		getenv func(string) string
+		want   string
+	}{
+		{"linux", noEnv, filepath.Join("/home/me", ".config", "autostart", "ramble.desktop")},
+		{"linux", xdg, filepath.Join("/xdg", "autostart", "ramble.desktop")},
+		{"darwin", noEnv, filepath.Join("/home/me", "Library", "LaunchAgents", "com.github.jeff-barlow-spady.ramble.plist")},
+	}
+	for _, tt := range tests {
+		if got, err := entryFile(tt.goos, "/home/me", tt.getenv); err != nil || got != tt.want {
+			t.Errorf("%s: expected %s, got %s (%v)", tt.goos, tt.want, got, err)
+		}
+	}
+	if _, err := entryFile("plan9", "/home/me", noEnv); err == nil {
+		t.Error("Expected an error on an unsupported platform")
+	}
+}
+
+func TestEntryContents(t *testing.T) {
+	command := []string{"/opt/My Apps/ramble", "-minimized", "-profile", "50% & more"}
+
+	desktop := entryContents("linux", command)
+	if want := `Exec="/opt/My Apps/ramble" -minimized -profile "50%% & more"`; !strings.Contains(desktop, want+"\n") {
+		t.Errorf("Expected %s in desktop file:\n%s", want, desktop)
+	}
+	if got := desktopQuote(`C:\a"b`); got != `"C:\\\\a\\"b"` {
+		t.Errorf("Expected backslashes and quotes escaped twice, got %s", got)
+	}
+
+	plist := entryContents("darwin", command)
+	if !strings.Contains(plist, "<string>50% &amp; more</string>") || !strings.Contains(plist, "<string>/opt/My Apps/ramble</string>") {
+		t.Errorf("Expected each argument in the property list:\n%s", plist)
+	}
+
+	line := windowsCommandLine([]string{`C:\Program Files\Ramble\ramble.exe`, "-minimized", `a "b"`, `dir\`})
+	if want := `"C:\Program Files\Ramble\ramble.exe" -minimized "a \"b\"" dir\`; line != want {
+		t.Errorf("Expected %s, got %s", want, line)
+	}
+	if line := windowsCommandLine([]string{`C:\dir with space\`}); line != `"C:\dir with space\\"` {
+		t.Errorf("Expected a trailing backslash doubled before the quote, got %s", line)
+	}
+}
+
+func TestEnable(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Enabling on Windows changes the registry")
+	}
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	if Enabled() {
+		t.Fatal("Expected no entry at first")
+	}
+	if err := Enable([]string{"/usr/bin/ramble", "-minimized"}); err != nil {
+		t.Fatalf("Enable failed: %v", err)
+	}
+	if !Enabled() {
+		t.Error("Expected an entry after enabling")
+	}
+	if err := Disable(); err != nil {
+		t.Fatalf("Disable failed: %v", err)
+	}
+	if Enabled() {
+		t.Error("Expected no entry after disabling")
+	}
+	if err := Disable(); err != nil {
+		t.Errorf("Expected disabling again to succeed, got %v", err)
+	}
+}
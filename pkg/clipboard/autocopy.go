@@ -0,0 +1,69 @@
+package clipboard
+
+import (
+	"strings"
+)
+
+// AutoCopyMode is how much text is copied automatically when a recording ends
+type AutoCopyMode string
+
+const (
+	// AutoCopyOff disables automatic copying
+	AutoCopyOff AutoCopyMode = "off"
+	// AutoCopySegment copies the segment that was just finalized
+	AutoCopySegment AutoCopyMode = "segment"
+	// AutoCopySession copies every segment of the current session
+	AutoCopySession AutoCopyMode = "session"
+)
+
+// AutoCopyPolicy decides what to copy to the clipboard when a segment is finalized
+type AutoCopyPolicy struct {
+	Mode      AutoCopyMode
+	Transient bool // Ask clipboard managers not to keep the text in their history
+
+	// copy writes to the clipboard; replaced in tests
+	copy func(text string, transient bool) error
+}
+
+// NewAutoCopyPolicy creates a policy for the given mode. Unknown modes disable auto-copy.
+func NewAutoCopyPolicy(mode AutoCopyMode, transient bool) *AutoCopyPolicy {
+	switch mode {
+	case AutoCopySegment, AutoCopySession:
+	default:
+		mode = AutoCopyOff
+	}
+	return &AutoCopyPolicy{Mode: mode, Transient: transient, copy: copyText}
+}
+
+// Text returns what the policy copies for a finalized segment and the
+// session it belongs to, or "" if nothing should be copied
+func (p *AutoCopyPolicy) Text(segment string, session []string) string {
+	switch p.Mode {
+	case AutoCopySegment:
+		return segment
+	case AutoCopySession:
+		return strings.Join(session, "\n\n")
+	default:
+		return ""
+	}
+}
+
+// SegmentFinalized copies text according to the policy. It reports whether anything was copied.
+func (p *AutoCopyPolicy) SegmentFinalized(segment string, session []string) (bool, error) {
+	text := p.Text(segment, session)
+	if text == "" {
+		return false, nil
+	}
+	if err := p.copy(text, p.Transient); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// copyText writes text to the clipboard, as a transient entry if requested
+func copyText(text string, transient bool) error {
+	if transient {
+		return SetTransientText(text)
+	}
+	return SetText(text)
+}
@@ -0,0 +1,41 @@
+package clipboard
+
+import "testing"
+
+func TestAutoCopyPolicy(t *testing.T) {
+	session := []string{"First recording.", "Second recording."}
+
+	tests := []struct {
+		mode AutoCopyMode
+		want string
+	}{
+		{AutoCopyOff, ""},
+		{AutoCopySegment, "Second recording."},
+		{AutoCopySession, "First recording.\n\nSecond recording."},
+		{"bogus", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.mode), func(t *testing.T) {
+			policy := NewAutoCopyPolicy(tt.mode, true)
+
+			var copied string
+			var transient bool
+			policy.copy = func(text string, isTransient bool) error {
+				copied, transient = text, isTransient
+				return nil
+			}
+
+			ok, err := policy.SegmentFinalized("Second recording.", session)
+			if err != nil {
+				t.Fatalf("SegmentFinalized failed: %v", err)
+			}
+			if ok != (tt.want != "") || copied != tt.want {
+				t.Errorf("Expected %q to be copied, got %q (copied=%v)", tt.want, copied, ok)
+			}
+			if ok && !transient {
+				t.Error("Expected copy to be transient")
+			}
+		})
+	}
+}
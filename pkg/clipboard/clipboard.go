@@ -6,13 +6,34 @@ import (
 	"os"
 	"os/exec"
 	"runtime"
+	"strings"
+	"sync/atomic"
 
 	"github.com/atotto/clipboard"
 	logger "github.com/jeff-barlow-spady/ramble/pkg/logger"
 )
 
-// SetText puts text into the system clipboard
+// alsoPrimary makes copies also set the X11 PRIMARY selection
+var alsoPrimary atomic.Bool
+
+// SetAlsoPrimary sets whether copied text also goes to the PRIMARY selection
+// used for middle-click paste. It only has an effect on Linux.
+func SetAlsoPrimary(enabled bool) {
+	alsoPrimary.Store(enabled)
+}
+
+// SetText puts text into the system clipboard, and into the PRIMARY
+// selection as well if enabled with SetAlsoPrimary
 func SetText(text string) error {
+	if err := setClipboardText(text); err != nil {
+		return err
+	}
+	copyToPrimary(text)
+	return nil
+}
+
+// setClipboardText puts text into the CLIPBOARD selection
+func setClipboardText(text string) error {
 	// Try the primary method first (atotto/clipboard)
 	err := clipboard.WriteAll(text)
 	if err == nil {
@@ -100,6 +121,100 @@ func SetText(text string) error {
 	return err
 }
 
+// SetPrimaryText puts text into the PRIMARY selection, which is pasted with
+// the middle mouse button on Linux. It needs wl-copy on Wayland, or xclip or
+// xsel on X11.
+func SetPrimaryText(text string) error {
+	if runtime.GOOS != "linux" {
+		return fmt.Errorf("primary selection is not supported on %s", runtime.GOOS)
+	}
+
+	var commands [][]string
+	if os.Getenv("WAYLAND_DISPLAY") != "" {
+		commands = append(commands, []string{"wl-copy", "--primary"})
+	}
+	commands = append(commands,
+		[]string{"xclip", "-selection", "primary"},
+		[]string{"xsel", "--primary", "--input"},
+	)
+
+	lastErr := fmt.Errorf("no primary selection tool found (install xclip, xsel or wl-clipboard)")
+	for _, args := range commands {
+		if !hasCommand(args[0]) {
+			continue
+		}
+		cmd := exec.Command(args[0], args[1:]...)
+		cmd.Stdin = strings.NewReader(text)
+		if err := cmd.Run(); err != nil {
+			lastErr = fmt.Errorf("%s failed: %w", args[0], err)
+			continue
+		}
+		logger.Debug(logger.CategoryUI, "Text copied to primary selection using %s", args[0])
+		return nil
+	}
+	return lastErr
+}
+
+// copyToPrimary also sets the PRIMARY selection if enabled. The clipboard
+// copy already succeeded, so a failure here is only logged.
+func copyToPrimary(text string) {
+	if !alsoPrimary.Load() || runtime.GOOS != "linux" {
+		return
+	}
+	if err := SetPrimaryText(text); err != nil {
+		logger.Warning(logger.CategoryUI, "Failed to set primary selection: %v", err)
+	}
+}
+
+// transientScriptMac puts stdin on the pasteboard along with the
+// org.nspasteboard.TransientType marker that clipboard managers honor
+const transientScriptMac = `ObjC.import('AppKit');
+ObjC.import('Foundation');
+var data = $.NSFileHandle.fileHandleWithStandardInput.readDataToEndOfFile;
+var text = $.NSString.alloc.initWithDataEncoding(data, $.NSUTF8StringEncoding);
+var pb = $.NSPasteboard.generalPasteboard;
+pb.clearContents;
+pb.setStringForType(text, 'public.utf8-plain-text');
+pb.setStringForType('', 'org.nspasteboard.TransientType');`
+
+// transientScriptWindows puts stdin on the clipboard with the formats that keep it
+// out of Windows clipboard history and cloud sync
+const transientScriptWindows = `Add-Type -AssemblyName System.Windows.Forms
+$text = [Console]::In.ReadToEnd()
+$data = New-Object System.Windows.Forms.DataObject
+$data.SetText($text)
+$zero = New-Object System.IO.MemoryStream(,[byte[]](0,0,0,0))
+$data.SetData('CanIncludeInClipboardHistory', $zero)
+$data.SetData('CanUploadToCloudClipboard', $zero)
+$data.SetData('ExcludeClipboardContentFromMonitorProcessing', $zero)
+[System.Windows.Forms.Clipboard]::SetDataObject($data, $true)`
+
+// SetTransientText puts text into the clipboard marked as transient, so
+// clipboard history managers don't keep it. This is supported on macOS and
+// Windows; elsewhere the text is copied normally.
+func SetTransientText(text string) error {
+	var cmd *exec.Cmd
+
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("osascript", "-l", "JavaScript", "-e", transientScriptMac)
+	case "windows":
+		cmd = exec.Command("powershell", "-NoProfile", "-STA", "-Command", transientScriptWindows)
+	default:
+		logger.Debug(logger.CategoryUI, "Transient clipboard entries are not supported on %s, copying normally", runtime.GOOS)
+		return SetText(text)
+	}
+
+	cmd.Stdin = strings.NewReader(text)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		logger.Warning(logger.CategoryUI, "Transient copy failed, copying normally: %v %s", err, out)
+		return SetText(text)
+	}
+
+	logger.Debug(logger.CategoryUI, "Text copied to clipboard as a transient entry")
+	return nil
+}
+
 // GetText retrieves text from the system clipboard
 func GetText() (string, error) {
 	return clipboard.ReadAll()
@@ -0,0 +1,215 @@
+// Package commands runs shell commands or keystrokes for spoken phrases in
+// command mode, instead of adding the phrases to the transcript
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"unicode"
+)
+
+// Kinds of action a command can run
+const (
+	KindShell = "shell" // A command line run by the system shell
+	KindKeys  = "keys"  // A key combination pressed in the focused window, e.g. "ctrl+l"
+)
+
+// ErrNoCommand is returned by Dispatcher.Run when no command matches the phrase
+var ErrNoCommand = errors.New("no command for this phrase")
+
+// Command maps a spoken phrase to an action
+type Command struct {
+	Phrase string
+	Kind   string // KindShell or KindKeys
+	Action string
+}
+
+// Dispatcher finds and runs the command for a spoken phrase
+type Dispatcher struct {
+	commands map[string]Command // By normalized phrase
+}
+
+// NewDispatcher creates a dispatcher for commands. A later command with the
+// same phrase replaces an earlier one.
+func NewDispatcher(commands []Command) *Dispatcher {
+	d := &Dispatcher{commands: make(map[string]Command)}
+	for _, c := range commands {
+		if phrase := normalize(c.Phrase); phrase != "" && strings.TrimSpace(c.Action) != "" {
+			d.commands[phrase] = c
+		}
+	}
+	return d
+}
+
+// Match returns the command for spoken text. Case, punctuation and extra
+// spaces are ignored, so "Open browser." matches "open browser".
+func (d *Dispatcher) Match(text string) (Command, bool) {
+	c, ok := d.commands[normalize(text)]
+	return c, ok
+}
+
+// Run runs the command for spoken text and returns it
+func (d *Dispatcher) Run(text string) (Command, error) {
+	c, ok := d.Match(text)
+	if !ok {
+		return Command{}, ErrNoCommand
+	}
+
+	var name string
+	var args []string
+	var err error
+	if c.Kind == KindKeys {
+		name, args, err = keysCommand(runtime.GOOS, os.Getenv, exec.LookPath, c.Action)
+	} else {
+		name, args = ShellCommand(runtime.GOOS, c.Action)
+	}
+	if err != nil {
+		return c, err
+	}
+
+	// Shell commands may start programs that keep running, so don't wait
+	// for them; keystrokes are quick and their errors are worth reporting
+	cmd := exec.Command(name, args...)
+	if c.Kind != KindKeys {
+		if err := cmd.Start(); err != nil {
+			return c, fmt.Errorf("failed to run %q: %w", c.Action, err)
+		}
+		go cmd.Wait()
+		return c, nil
+	}
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return c, fmt.Errorf("%s failed: %w: %s", name, err, strings.TrimSpace(string(out)))
+	}
+	return c, nil
+}
+
+// normalize lowercases text and reduces it to words separated by single spaces
+func normalize(text string) string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '\''
+	})
+	return strings.Join(words, " ")
+}
+
+// ShellCommand returns the command running line with the shell of goos, the
+// platform as in runtime.GOOS
+func ShellCommand(goos, line string) (string, []string) {
+	if goos == "windows" {
+		return "cmd", []string{"/C", line}
+	}
+	return "sh", []string{"-c", line}
+}
+
+// keysCommand returns the command pressing a key combination such as
+// "ctrl+shift+t" on the given platform
+func keysCommand(goos string, getenv func(string) string, lookPath func(string) (string, error), combo string) (string, []string, error) {
+	parts := strings.Split(strings.ToLower(strings.ReplaceAll(combo, " ", "")), "+")
+	key, modifiers := parts[len(parts)-1], parts[:len(parts)-1]
+	if key == "" {
+		return "", nil, fmt.Errorf("invalid key combination %q", combo)
+	}
+
+	switch goos {
+	case "darwin":
+		var using []string
+		for _, m := range modifiers {
+			switch m {
+			case "ctrl":
+				using = append(using, "control down")
+			case "alt":
+				using = append(using, "option down")
+			case "shift":
+				using = append(using, "shift down")
+			case "cmd", "super":
+				using = append(using, "command down")
+			}
+		}
+		script := fmt.Sprintf("tell application \"System Events\" to keystroke %q", key)
+		if len(using) > 0 {
+			script += " using {" + strings.Join(using, ", ") + "}"
+		}
+		return "osascript", []string{"-e", script}, nil
+	case "windows":
+		prefixes := map[string]string{"ctrl": "^", "alt": "%", "shift": "+"}
+		var keys string
+		for _, m := range modifiers {
+			keys += prefixes[m]
+		}
+		if len(key) > 1 {
+			key = "{" + strings.ToUpper(key) + "}"
+		}
+		script := "Add-Type -AssemblyName System.Windows.Forms; " +
+			"[System.Windows.Forms.SendKeys]::SendWait('" + keys + key + "')"
+		return "powershell", []string{"-NoProfile", "-Command", script}, nil
+	}
+
+	// xdotool takes the combination as is; wtype presses each modifier
+	xdotool := []string{"key", "--clearmodifiers", combo}
+	var wtype []string
+	for _, m := range modifiers {
+		wtype = append(wtype, "-M", m)
+	}
+	wtype = append(wtype, "-k", key)
+	for _, m := range modifiers {
+		wtype = append(wtype, "-m", m)
+	}
+
+	tools := []struct {
+		name string
+		args []string
+	}{
+		{"xdotool", xdotool},
+		{"wtype", wtype},
+	}
+	if getenv("WAYLAND_DISPLAY") != "" {
+		tools[0], tools[1] = tools[1], tools[0]
+	}
+	for _, tool := range tools {
+		if _, err := lookPath(tool.name); err == nil {
+			return tool.name, tool.args, nil
+		}
+	}
+	return "", nil, fmt.Errorf("pressing keys needs xdotool or wtype to be installed")
+}
+
+// Parse reads commands written one per line as "phrase => action". An action
+// starting with "keys:" is a key combination; anything else is a shell
+// command. Blank lines and lines starting with # are skipped.
+func Parse(text string) ([]Command, error) {
+	var commands []Command
+	for i, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		phrase, action, ok := strings.Cut(line, "=>")
+		phrase, action = strings.TrimSpace(phrase), strings.TrimSpace(action)
+		if !ok || normalize(phrase) == "" || action == "" {
+			return nil, fmt.Errorf("line %d: expected \"phrase => action\"", i+1)
+		}
+
+		c := Command{Phrase: phrase, Kind: KindShell, Action: action}
+		if keys, found := strings.CutPrefix(action, "keys:"); found {
+			c.Kind, c.Action = KindKeys, strings.TrimSpace(keys)
+		}
+		commands = append(commands, c)
+	}
+	return commands, nil
+}
+
+// Format writes commands in the form Parse reads
+func Format(commands []Command) string {
+	lines := make([]string, len(commands))
+	for i, c := range commands {
+		action := c.Action
+		if c.Kind == KindKeys {
+			action = "keys: " + action
+		}
+		lines[i] = c.Phrase + " => " + action
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,110 @@
+package commands
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestParseFormat(t *testing.T) {
+	text := "# Browser\nOpen browser => firefox\n\nnext track => keys: ctrl+alt+n\n"
+	commands, err := Parse(text)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	want := []Command{
+		{Phrase: "Open browser", Kind: KindShell, Action: "firefox"},
+		{Phrase: "next track", Kind: KindKeys, Action: "ctrl+alt+n"},
+	}
+	if !reflect.DeepEqual(commands, want) {
+		t.Errorf("Parse = %+v, want %+v", commands, want)
+	}
+	if got := Format(commands); got != "Open browser => firefox\nnext track => keys: ctrl+alt+n" {
+		t.Errorf("Unexpected format: %q", got)
+	}
+
+	if _, err := Parse("just a phrase"); err == nil {
+		t.Error("Expected an error for a line without an action")
+	}
+}
+
+func TestMatch(t *testing.T) {
+	d := NewDispatcher([]Command{{Phrase: "Open  browser", Action: "firefox"}, {Phrase: "empty", Action: " "}})
+
+	for _, text := range []string{"open browser", " Open browser. ", "OPEN, BROWSER!"} {
+		if _, ok := d.Match(text); !ok {
+			t.Errorf("Expected %q to match", text)
+		}
+	}
+	for _, text := range []string{"open the browser", "empty", ""} {
+		if _, ok := d.Match(text); ok {
+			t.Errorf("Expected %q not to match", text)
+		}
+	}
+}
+
+func TestRunShell(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	marker := filepath.Join(t.TempDir(), "ran")
+	d := NewDispatcher([]Command{{Phrase: "mark it", Kind: KindShell, Action: "touch " + marker}})
+
+	if _, err := d.Run("Mark it."); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if _, err := os.Stat(marker); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Expected the shell command to run")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if _, err := d.Run("something else"); !errors.Is(err, ErrNoCommand) {
+		t.Errorf("Expected ErrNoCommand, got %v", err)
+	}
+}
+
+func TestKeysCommand(t *testing.T) {
+	installed := func(names ...string) func(string) (string, error) {
+		return func(name string) (string, error) {
+			for _, n := range names {
+				if n == name {
+					return "/usr/bin/" + name, nil
+				}
+			}
+			return "", errors.New("not found")
+		}
+	}
+	env := func(wayland string) func(string) string {
+		return func(string) string { return wayland }
+	}
+
+	name, args, err := keysCommand("linux", env(""), installed("xdotool", "wtype"), "ctrl+l")
+	if err != nil || name != "xdotool" || args[len(args)-1] != "ctrl+l" {
+		t.Errorf("Expected xdotool on X11, got %s %q (%v)", name, args, err)
+	}
+	name, args, _ = keysCommand("linux", env("wayland-0"), installed("xdotool", "wtype"), "ctrl+l")
+	if name != "wtype" || !reflect.DeepEqual(args, []string{"-M", "ctrl", "-k", "l", "-m", "ctrl"}) {
+		t.Errorf("Expected wtype on Wayland, got %s %q", name, args)
+	}
+	if _, _, err := keysCommand("linux", env(""), installed(), "ctrl+l"); err == nil {
+		t.Error("Expected an error without a tool")
+	}
+	_, args, _ = keysCommand("darwin", nil, nil, "cmd+shift+t")
+	if args[1] != `tell application "System Events" to keystroke "t" using {command down, shift down}` {
+		t.Errorf("Unexpected AppleScript: %s", args[1])
+	}
+	_, args, _ = keysCommand("windows", nil, nil, "ctrl+enter")
+	if args[len(args)-1] != "Add-Type -AssemblyName System.Windows.Forms; [System.Windows.Forms.SendKeys]::SendWait('^{ENTER}')" {
+		t.Errorf("Unexpected SendKeys script: %s", args[len(args)-1])
+	}
+}
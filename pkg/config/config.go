@@ -29,28 +29,216 @@ type Config struct {
 	HotKeyKey   string
 
 	// Audio configuration
-	AudioSampleRate int
-	AudioBufferSize int
-	AudioChannels   int
+	AudioBackend      string // Audio system to record from: "portaudio" or "pulse"
+	AudioSampleRate   int
+	AudioBufferSize   int
+	AudioChannels     int
+	AudioQueueSeconds int                // Audio held for the transcriber before the oldest is dropped
+	InputGains        map[string]float64 // Gain applied to each input device, by name
+
+	// Switch to the default input device when the one recording disappears
+	// instead of waiting for it to return
+	AudioFallbackToDefault bool
+
+	// Audio archive configuration
+	ArchiveAudio     bool   // Whether to save the audio of every recording as transcribed: 16kHz mono, after gain
+	ArchiveMaxDays   int    // Delete archived audio older than this (0 = keep forever)
+	ArchiveMaxSizeMB int    // Delete the oldest archived audio above this size (0 = unlimited)
+	ArchiveFormat    string // "wav", or "flac" for lossless compression in about half the space
 
 	// Whisper configuration
-	WhisperModelPath string
-	WhisperModelType string
+	TranscriptionBackend string // One of transcription.Backends, e.g. "whisper" for the built-in whisper.cpp; applies after a restart
+	WhisperServerURL     string // Address of the whisper.cpp server used by the "whisper-server" backend
+	FasterWhisperCommand string // Command running the sidecar used by the "faster-whisper" backend
+	VoskCommand          string // Command running the sidecar used by the "vosk" backend
+	SidecarTimeout       int    // Seconds a sidecar may leave a window unanswered before it is restarted (0 = never)
+	WhisperModelPath     string
+	WhisperModelType     string
+	LiveModel            string // Model for live transcription, loaded at startup ("" = the one LatencyProfile prefers)
+	LatencyProfile       string // "low-latency", "balanced" or "accuracy"; see transcription.ProfileTuning
+	Language             string // Language code such as "en", or "auto" to detect the language of each window
+	RewriteModel         string // Model that re-transcribes each finalized segment while tiny transcribes live ("" = off)
+	CleanupSegments      bool   // Fix the punctuation and casing of each finalized segment
+	CleanupCommand       string // Program that cleans up text read from stdin, e.g. a punctuation model ("" = built-in rules)
+	IdleReleaseMinutes   int    // Unload the model and release audio after this long unused (0 = never)
+	FileWorkers          int    // Chunks of files transcribed at once, each by its own model instance
+	// Whisper decoding settings by transcription backend, e.g. "whisper";
+	// backends without an entry use transcription.DefaultDecoding
+	Decoding map[string]DecodingConfig
+
+	// Named bundles of settings for use cases like dictation or meetings,
+	// applied from the tray menu or with Ctrl+Alt+1 to 9
+	Presets      []Preset
+	ActivePreset string // Name of the preset applied last ("" = none)
+
+	// Finalize a segment after this many seconds of silence while recording (0 = only when recording stops)
+	UtteranceSilenceSeconds float64
+
+	// Split long recordings into a new segment and audio file (0 = no limit)
+	RolloverMinutes    int
+	RolloverCharacters int
+
+	// Stop recording after this many minutes without speech (0 = never)
+	AutoStopSilenceMinutes int
+
+	// Prompt each live window with the end of the text before it, so windows
+	// continue sentences rather than start new ones, in every backend
+	KeepContext bool
+
+	// Hallucination suppression: drop live text whisper invents on silence or noise
+	SuppressHallucinations  bool
+	HallucinationSilenceRMS float64  // Drop text from audio windows quieter than this RMS level
+	HallucinationPhrases    []string // Phrases dropped in addition to the built-in list
 
 	// UI configuration
 	ShowTranscriptionUI bool
 	InsertTextAtCursor  bool
-	MinimizeToTray      bool // Whether to start minimized to system tray
-	TerminalMode        bool // Whether to use terminal UI mode
-	SafeMode            bool // Whether to confirm before inserting text
+	MinimizeToTray      bool    // Whether to start minimized to system tray
+	StartMinimized      bool    // Start with only the tray icon shown, e.g. when started at login
+	HoverOnTop          bool    // Keep the hover window above other windows
+	HoverWidth          float32 // Size of the hover window
+	HoverHeight         float32
+	HoverOpacity        float64 // Opacity of the hover window, from 0.2 to 1
+	HoverClickThrough   bool    // Let clicks through the hover window (Windows)
+	RecordingIndicator  bool    // Show a red dot above other windows while recording
+	TerminalMode        bool    // Whether to use terminal UI mode
+	SafeMode            bool    // Whether to confirm before inserting text
+	AutoCopy            bool    // Whether to copy text automatically when a recording stops
+	AutoCopyMode        string  // "segment" copies the new segment, "session" the whole session
+	AutoCopyTransient   bool    // Keep auto-copied text out of clipboard history where supported
+	CopyToPrimary       bool    // Also set the X11 PRIMARY selection for middle-click paste (Linux)
 	Theme               *ThemeConfig
 	ThemeMode           ThemeMode // The theme mode (light/dark/system)
 
+	// Interface language
+	UILanguage string // Code of the language the interface is shown in, or "" to follow the system
+
+	// Microphone display
+	Visualization string // "waveform" bars or a "spectrogram" of the input
+
+	// Transcript display
+	TranscriptFontSize    float32 // Text size of transcripts, previews and segment cards
+	TranscriptFont        string  // "monospace", "sans" or the path of a font file
+	TranscriptLineSpacing float32 // Multiple of the normal line spacing
+
+	// Redaction configuration
+	RedactProfanity    bool     // Mask common profanity
+	RedactEmails       bool     // Mask email addresses
+	RedactPhoneNumbers bool     // Mask phone numbers
+	RedactCardNumbers  bool     // Mask credit-card-like digit runs
+	RedactPatterns     []string // Additional regular expressions to mask
+	RedactDisplay      bool     // Apply redaction to text shown in the UI
+	RedactClipboard    bool     // Apply redaction to text copied to the clipboard
+	RedactSaved        bool     // Apply redaction to transcripts saved to disk
+
+	// Crash handling configuration
+	RelaunchAfterCrash bool // Whether to restart the app after writing a crash report
+
+	// Transcript follow-up configuration
+	Vocabulary  []string         // Words and names the model should recognize
+	Corrections []CorrectionRule // Replacements applied to transcribed text
+	WebhookURL  string           // Where selected transcript text is sent on request
+
+	// Phrases that tag a finalized segment and show a notification when heard;
+	// /pattern/ is a regular expression
+	WatchKeywords []string
+
+	// Phrases run in command mode instead of being transcribed, written as
+	// "phrase => shell command" or "phrase => keys: ctrl+l"
+	VoiceCommands []string
+
+	// Programs run on each finalized segment, one command line each, that
+	// read its text on stdin and may print replacement text
+	Hooks []string
+
+	// Webhooks notified of every finalized segment
+	SegmentWebhookURLs []string
+	WebhookSecret      string // Signs webhook requests with HMAC-SHA256 if set
+
+	// Note-taking app integration, e.g. an Obsidian vault
+	NotesEnabled  bool   // Append each recording to a Markdown note when it stops
+	NotesPath     string // Note file; may contain {{date}} for daily notes
+	NotesTemplate string // What is appended; {{text}} is the transcript
+
+	// MQTT publishing for home automation
+	MQTTEnabled        bool
+	MQTTBroker         string // host:port of the broker
+	MQTTUsername       string
+	MQTTPassword       string
+	MQTTTopic          string // Events go to <topic>/partial and <topic>/final
+	MQTTPublishPartial bool   // Also publish streaming text before it is final
+
+	// Session summaries written by a language model
+	SummaryEnabled  bool
+	SummaryProvider string // "ollama" or "openai" for any OpenAI-compatible endpoint
+	SummaryURL      string // Base URL of the server
+	SummaryModel    string
+	SummaryAPIKey   string
+	SummaryPrompt   string // Prompt template; {{transcript}} is the session transcript
+
+	// Reading segments aloud
+	TTSEngine string // Speech synthesizer, e.g. "espeak-ng"; empty picks the first one installed
+	TTSVoice  string // Voice name, or the voice model file for piper; empty for the default
+
+	// Destinations every finalized recording is also sent to
+	Outputs      []OutputRule
+	NumberLocale string // How outputs write normalized numbers, dates and times, e.g. "en-GB"
+	// What outputs do while a matching application has focus; the first match applies
+	AppOutputs []AppOutputRule
+
+	// Local usage statistics, never sent anywhere
+	AnalyticsSessions bool // Record sessions, minutes transcribed and models used
+	AnalyticsFeatures bool // Record how often each feature is used
+
+	// Serve Prometheus metrics at this address, e.g. "127.0.0.1:9464" (empty = off)
+	MetricsAddress string
+	// Serve pprof and /debug/status at this address when started with -debug (empty = off)
+	DebugAddress string
+
+	// Logging configuration
+	LogLevel          string            // "debug", "info", "warn", "error" or "silent"
+	LogCategoryLevels map[string]string // Levels overriding LogLevel, by category such as "AUDIO"
+	LogJSON           bool              // Write one JSON object per line instead of text
+	LogToFile         bool              // Also write logs to ramble.log in the log directory
+	LogMaxSizeMB      int               // Start a new log file above this size
+	LogMaxFiles       int               // Older log files kept besides the current one
+
 	// Test mode configuration
 	TestMode               bool
 	TestModeVisualFeedback bool
 }
 
+// CorrectionRule replaces a word or phrase the model keeps getting wrong
+type CorrectionRule struct {
+	From  string
+	To    string
+	Regex bool // From is a regular expression; To may use its groups as $1
+}
+
+// OutputRule enables an output that receives every finalized recording
+type OutputRule struct {
+	Type   string // "clipboard", "file", "type", "webhook", "stdout" or "pipe"
+	Target string // File path, webhook URL or named pipe path
+	Format string // What is written; {{text}} is the transcript
+	// Numbers writes spoken numbers, dates and times as digits
+	Numbers bool
+}
+
+// AppOutputRule changes what outputs do while an application has focus
+type AppOutputRule struct {
+	Match  string // Part of the window class or title, ignoring case
+	Action string // "type" (don't copy), "clipboard" (don't type) or "off" (no outputs)
+}
+
+// DecodingConfig is how whisper decodes speech; see transcription.Decoding
+type DecodingConfig struct {
+	Temperature         float64 // Randomness of the first attempt (0 = likeliest tokens)
+	TemperatureFallback float64 // Added to the temperature for each retry of rejected text (0 = no retries)
+	BeamSize            int     // Candidates searched at once (1 = greedy)
+	EntropyThreshold    float64 // Text this repetitive or more is retried
+	NoSpeechThreshold   float64 // Windows at least this likely to be silence give no text
+}
+
 // ThemeConfig holds the theme configuration
 type ThemeConfig struct {
 	BackgroundColor color.RGBA
@@ -81,23 +269,135 @@ func DefaultConfig() *Config {
 		HotKeyKey:   "s",
 
 		// Default audio settings
-		AudioSampleRate: 16000, // 16kHz sample rate for Whisper
-		AudioBufferSize: 1024,
-		AudioChannels:   1, // Mono
+		AudioBackend:      "portaudio",
+		AudioSampleRate:   16000, // 16kHz sample rate for Whisper
+		AudioBufferSize:   1024,
+		AudioChannels:     1,  // Mono
+		AudioQueueSeconds: 10, // Seconds of audio queued while whisper catches up
+
+		// Keep recording on the default microphone if the one in use is unplugged
+		AudioFallbackToDefault: true,
+
+		// Default audio archive settings - off unless the user opts in
+		ArchiveAudio:     false,
+		ArchiveMaxDays:   30,
+		ArchiveMaxSizeMB: 1024,
+		ArchiveFormat:    "wav",
 
 		// Default Whisper settings
-		WhisperModelPath: modelDir,
-		WhisperModelType: "tiny", // Use tiny model by default
+		TranscriptionBackend: "whisper",
+		WhisperServerURL:     "http://127.0.0.1:8080",
+		FasterWhisperCommand: "ramble-faster-whisper --model base.en --compute-type int8",
+		VoskCommand:          "ramble-vosk",
+		SidecarTimeout:       30,
+		WhisperModelPath:     modelDir,
+		WhisperModelType:     "tiny", // Use tiny model by default
+		LatencyProfile:       "balanced",
+		Language:             "en",
+
+		// Release the model and audio system after 10 idle minutes
+		IdleReleaseMinutes: 10,
+
+		// Transcribe files one chunk at a time, with one model loaded
+		FileWorkers: 1,
+
+		// Offer presets for dictation, meetings and voice commands
+		Presets: DefaultPresets(),
+
+		// Segments are finalized only when recording stops unless a pause length is set
+		UtteranceSilenceSeconds: 0,
+		AutoStopSilenceMinutes:  0,
+		KeepContext:             true,
+
+		// Drop phrases like "thank you for watching" that whisper invents on silence
+		SuppressHallucinations:  true,
+		HallucinationSilenceRMS: 0.005,
 
 		// Default UI settings
 		ShowTranscriptionUI: true,
 		InsertTextAtCursor:  true,
 		MinimizeToTray:      false, // Don't start minimized by default
+		StartMinimized:      false,
+		HoverOnTop:          true,
+		HoverWidth:          300,
+		HoverHeight:         200,
+		HoverOpacity:        1,
+		HoverClickThrough:   false,
+		RecordingIndicator:  false,
 		TerminalMode:        false,
 		SafeMode:            false, // Don't require confirmation by default
+		AutoCopy:            false,
+		AutoCopyMode:        "segment",
+		AutoCopyTransient:   false,
+		CopyToPrimary:       false,
 		Theme:               DefaultTheme(),
 		ThemeMode:           ThemeModeSystem, // Use system theme by default
 
+		// Default interface language - the system's, English if it has no translation
+		UILanguage: "",
+
+		// Default microphone display - the waveform bars
+		Visualization: "waveform",
+
+		// Default transcript display - monospace, readable on high-resolution screens
+		TranscriptFontSize:    18,
+		TranscriptFont:        "monospace",
+		TranscriptLineSpacing: 1,
+
+		// Default redaction - nothing is masked until a rule is enabled,
+		// after which it applies to every destination
+		RedactProfanity:    false,
+		RedactEmails:       false,
+		RedactPhoneNumbers: false,
+		RedactCardNumbers:  false,
+		RedactDisplay:      true,
+		RedactClipboard:    true,
+		RedactSaved:        true,
+
+		// Default note integration - off, appending to a daily note when enabled
+		NotesEnabled:  false,
+		NotesPath:     "~/Documents/Notes/{{date}}.md",
+		NotesTemplate: "## {{time}}\n\n{{text}}\n",
+
+		// Default MQTT settings - off until a broker is configured
+		MQTTEnabled:        false,
+		MQTTBroker:         "localhost:1883",
+		MQTTTopic:          "ramble",
+		MQTTPublishPartial: false,
+
+		// Default summary settings - off, using a local Ollama server when enabled
+		SummaryEnabled:  false,
+		SummaryProvider: "ollama",
+		SummaryURL:      "http://127.0.0.1:11434",
+		SummaryModel:    "llama3.2",
+
+		// Default number formatting for outputs that normalize numbers
+		NumberLocale: "en-US",
+
+		// Default app rules - never send dictation to password managers
+		AppOutputs: []AppOutputRule{
+			{Match: "keepassxc", Action: "off"},
+			{Match: "1password", Action: "off"},
+			{Match: "bitwarden", Action: "off"},
+		},
+
+		// Default usage statistics - nothing is recorded unless the user opts in
+		AnalyticsSessions: false,
+		AnalyticsFeatures: false,
+
+		// Default diagnostics - only served on this machine, and only with -debug
+		DebugAddress: "127.0.0.1:6060",
+
+		// Default crash handling - report only, don't restart
+		RelaunchAfterCrash: false,
+
+		// Default logging - text on stderr, with rotation once file logging is enabled
+		LogLevel:     "info",
+		LogJSON:      false,
+		LogToFile:    false,
+		LogMaxSizeMB: 10,
+		LogMaxFiles:  3,
+
 		// Default test mode settings - should be false for production use
 		// TestMode should only be enabled for the test binary or explicit testing
 		TestMode:               false,
@@ -139,21 +439,41 @@ func GetAppDir() (string, error) {
 
 // GetConfigFilePath returns the path to the config file
 func GetConfigFilePath() (string, error) {
-	appDir, err := GetAppDir()
+	dataDir, err := GetDataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dataDir, "config.json"), nil
+}
+
+// GetRecoveryFilePath returns the path of the journal holding transcript
+// text that has not been saved to a session yet
+func GetRecoveryFilePath() (string, error) {
+	dataDir, err := GetDataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dataDir, "recovery.json"), nil
+}
+
+// GetSocketPath returns the path of the socket through which other
+// processes control the running instance of the active profile
+func GetSocketPath() (string, error) {
+	dataDir, err := GetDataDir()
 	if err != nil {
 		return "", err
 	}
-	return filepath.Join(appDir, "config.json"), nil
+	return filepath.Join(dataDir, "ramble.sock"), nil
 }
 
 // GetAudioBackupDir returns the path to the audio backup directory
 func GetAudioBackupDir() (string, error) {
-	appDir, err := GetAppDir()
+	dataDir, err := GetDataDir()
 	if err != nil {
 		return "", err
 	}
 
-	backupDir := filepath.Join(appDir, "audio_backups")
+	backupDir := filepath.Join(dataDir, "audio_backups")
 	if err := os.MkdirAll(backupDir, 0755); err != nil {
 		return "", fmt.Errorf("failed to create audio backup directory: %w", err)
 	}
@@ -176,6 +496,61 @@ func GetModelDir() (string, error) {
 	return modelDir, nil
 }
 
+// GetSessionDir returns the path to the saved session directory
+func GetSessionDir() (string, error) {
+	dataDir, err := GetDataDir()
+	if err != nil {
+		return "", err
+	}
+
+	sessionDir := filepath.Join(dataDir, "sessions")
+	if err := os.MkdirAll(sessionDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create session directory: %w", err)
+	}
+
+	return sessionDir, nil
+}
+
+// GetCrashDir returns the path to the crash report directory
+func GetCrashDir() (string, error) {
+	appDir, err := GetAppDir()
+	if err != nil {
+		return "", err
+	}
+
+	crashDir := filepath.Join(appDir, "crashes")
+	if err := os.MkdirAll(crashDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create crash report directory: %w", err)
+	}
+
+	return crashDir, nil
+}
+
+// GetLogDir returns the path to the log file directory
+func GetLogDir() (string, error) {
+	appDir, err := GetAppDir()
+	if err != nil {
+		return "", err
+	}
+
+	logDir := filepath.Join(appDir, "logs")
+	if err := os.MkdirAll(logDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create log directory: %w", err)
+	}
+
+	return logDir, nil
+}
+
+// GetTempDir returns the path to the directory holding the temporary file
+// directories of each run
+func GetTempDir() (string, error) {
+	appDir, err := GetAppDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(appDir, "tmp"), nil
+}
+
 // LoadConfig loads the configuration from the config file
 func LoadConfig() error {
 	configPath, err := GetConfigFilePath()
@@ -197,15 +572,16 @@ func LoadConfig() error {
 		return fmt.Errorf("failed to read config file: %w", err)
 	}
 
-	// Parse JSON data
-	var config Config
-	err = json.Unmarshal(data, &config)
+	// Parse JSON data over the defaults so fields added since the file
+	// was written keep their default values
+	config := DefaultConfig()
+	err = json.Unmarshal(data, config)
 	if err != nil {
 		return fmt.Errorf("failed to parse config file: %w", err)
 	}
 
 	// Update current config
-	Current = &config
+	Current = config
 
 	// Ensure theme is set
 	if Current.Theme == nil {
@@ -33,6 +33,12 @@ func TestDefaultConfig(t *testing.T) {
 	if cfg.AudioChannels != 1 {
 		t.Errorf("Expected default AudioChannels to be 1, got %d", cfg.AudioChannels)
 	}
+	if cfg.AudioQueueSeconds != 10 {
+		t.Errorf("Expected default AudioQueueSeconds to be 10, got %d", cfg.AudioQueueSeconds)
+	}
+	if !cfg.AudioFallbackToDefault {
+		t.Error("Expected default AudioFallbackToDefault to be true")
+	}
 
 	// Test Whisper defaults - get expected model path
 	homeDir, err := os.UserHomeDir()
@@ -46,6 +52,13 @@ func TestDefaultConfig(t *testing.T) {
 		t.Errorf("Expected default WhisperModelType to be 'tiny', got '%s'", cfg.WhisperModelType)
 	}
 
+	if !cfg.SuppressHallucinations {
+		t.Error("Expected default SuppressHallucinations to be true")
+	}
+	if !cfg.KeepContext {
+		t.Error("Expected default KeepContext to be true")
+	}
+
 	// Test UI defaults
 	if !cfg.ShowTranscriptionUI {
 		t.Error("Expected default ShowTranscriptionUI to be true")
@@ -0,0 +1,77 @@
+package config
+
+import "fmt"
+
+// Preset is a named bundle of settings for one use case, such as dictating
+// into other windows or transcribing a meeting. Applying it replaces those
+// settings; the rest of the config is left alone.
+type Preset struct {
+	Name                    string
+	Model                   string // Live model, e.g. "tiny" ("" = the one the latency profile prefers)
+	LatencyProfile          string
+	Language                string
+	UtteranceSilenceSeconds float64
+	SuppressHallucinations  bool
+	CommandMode             bool         // Run voice commands instead of transcribing
+	Outputs                 []OutputRule // Replace the configured outputs
+}
+
+// DefaultPresets returns the presets offered until the user changes them
+func DefaultPresets() []Preset {
+	return []Preset{
+		{
+			Name:                    "Dictation",
+			Model:                   "tiny",
+			LatencyProfile:          "low-latency",
+			Language:                "en",
+			UtteranceSilenceSeconds: 1.5,
+			SuppressHallucinations:  true,
+			Outputs:                 []OutputRule{{Type: "type", Format: "{{text}} "}},
+		},
+		{
+			Name:                    "Meeting",
+			Model:                   "medium",
+			LatencyProfile:          "accuracy",
+			Language:                "auto",
+			UtteranceSilenceSeconds: 3,
+			SuppressHallucinations:  true,
+		},
+		{
+			Name:                    "Command",
+			Model:                   "tiny",
+			LatencyProfile:          "low-latency",
+			Language:                "en",
+			UtteranceSilenceSeconds: 1,
+			SuppressHallucinations:  true,
+			CommandMode:             true,
+		},
+	}
+}
+
+// FindPreset returns the preset with the given name
+func (c *Config) FindPreset(name string) (Preset, bool) {
+	for _, preset := range c.Presets {
+		if preset.Name == name {
+			return preset, true
+		}
+	}
+	return Preset{}, false
+}
+
+// ApplyPreset copies the settings of the named preset into c and makes it
+// the active preset. Command mode is not kept in the config; the caller
+// switches it from the preset returned.
+func (c *Config) ApplyPreset(name string) (Preset, error) {
+	preset, ok := c.FindPreset(name)
+	if !ok {
+		return Preset{}, fmt.Errorf("no preset named %q", name)
+	}
+	c.LiveModel = preset.Model
+	c.LatencyProfile = preset.LatencyProfile
+	c.Language = preset.Language
+	c.UtteranceSilenceSeconds = preset.UtteranceSilenceSeconds
+	c.SuppressHallucinations = preset.SuppressHallucinations
+	c.Outputs = append([]OutputRule(nil), preset.Outputs...)
+	c.ActivePreset = preset.Name
+	return preset, nil
+}
@@ -0,0 +1,43 @@
+package config
+
+import "testing"
+
+func TestApplyPreset(t *testing.T) {
+	c := DefaultConfig()
+	c.Outputs = []OutputRule{{Type: "clipboard"}}
+	c.LogLevel = "debug"
+
+	preset, err := c.ApplyPreset("Dictation")
+	if err != nil {
+		t.Fatalf("ApplyPreset failed: %v", err)
+	}
+	if c.ActivePreset != "Dictation" || c.LiveModel != "tiny" || c.LatencyProfile != "low-latency" {
+		t.Errorf("Expected the dictation settings, got preset %q, model %q, profile %q", c.ActivePreset, c.LiveModel, c.LatencyProfile)
+	}
+	if len(c.Outputs) != 1 || c.Outputs[0].Type != "type" {
+		t.Errorf("Expected the preset to replace the outputs, got %+v", c.Outputs)
+	}
+	if c.LogLevel != "debug" {
+		t.Errorf("Expected settings outside the preset to be kept, got log level %q", c.LogLevel)
+	}
+
+	// Changing the config leaves the preset alone
+	c.Outputs[0].Target = "changed"
+	if preset, _ := c.FindPreset("Dictation"); preset.Outputs[0].Target != "" {
+		t.Errorf("Expected the preset's outputs to be copied, got %+v", preset.Outputs)
+	}
+
+	if preset.CommandMode {
+		t.Error("Expected dictation not to be command mode")
+	}
+	if preset, _ := c.ApplyPreset("Command"); !preset.CommandMode || len(c.Outputs) != 0 {
+		t.Errorf("Expected the command preset to switch to command mode without outputs, got %+v", preset)
+	}
+
+	if _, err := c.ApplyPreset("Podcast"); err == nil {
+		t.Error("Expected an error for an unknown preset")
+	}
+	if c.ActivePreset != "Command" {
+		t.Errorf("Expected an unknown preset to leave the active one, got %q", c.ActivePreset)
+	}
+}
@@ -0,0 +1,91 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// activeProfile is the user profile whose settings and history are in use.
+// The empty string is the default profile, stored directly in the .ramble directory.
+var activeProfile string
+
+// ValidateProfileName checks that a profile name is usable as a directory name
+func ValidateProfileName(name string) error {
+	if strings.TrimSpace(name) == "" {
+		return fmt.Errorf("profile name is empty")
+	}
+	if len(name) > 64 {
+		return fmt.Errorf("profile name is longer than 64 characters")
+	}
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != ' ' && r != '-' && r != '_' {
+			return fmt.Errorf("profile name %q may only contain letters, digits, spaces, '-' and '_'", name)
+		}
+	}
+	return nil
+}
+
+// SetProfile selects the profile used by LoadConfig, SaveConfig and the data
+// directory helpers, creating it if needed. An empty name selects the default profile.
+func SetProfile(name string) error {
+	if name != "" {
+		if err := ValidateProfileName(name); err != nil {
+			return err
+		}
+	}
+	activeProfile = name
+	_, err := GetDataDir()
+	return err
+}
+
+// ActiveProfile returns the name of the selected profile, or "" for the default profile
+func ActiveProfile() string {
+	return activeProfile
+}
+
+// ListProfiles returns the names of all named profiles, sorted
+func ListProfiles() ([]string, error) {
+	appDir, err := GetAppDir()
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(filepath.Join(appDir, "profiles"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list profiles: %w", err)
+	}
+
+	var profiles []string
+	for _, entry := range entries {
+		if entry.IsDir() && ValidateProfileName(entry.Name()) == nil {
+			profiles = append(profiles, entry.Name())
+		}
+	}
+	sort.Strings(profiles)
+	return profiles, nil
+}
+
+// GetDataDir returns the directory holding the active profile's config and
+// history. Models are shared by all profiles and stay in the .ramble directory.
+func GetDataDir() (string, error) {
+	appDir, err := GetAppDir()
+	if err != nil {
+		return "", err
+	}
+	if activeProfile == "" {
+		return appDir, nil
+	}
+
+	dataDir := filepath.Join(appDir, "profiles", activeProfile)
+	if err := os.MkdirAll(dataDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create profile directory: %w", err)
+	}
+	return dataDir, nil
+}
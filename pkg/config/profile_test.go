@@ -0,0 +1,75 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestProfiles(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	defer SetProfile("")
+
+	if profiles, err := ListProfiles(); err != nil || len(profiles) != 0 {
+		t.Fatalf("Expected no profiles, got %v (%v)", profiles, err)
+	}
+
+	for _, name := range []string{"Studio B", "alice"} {
+		if err := SetProfile(name); err != nil {
+			t.Fatalf("SetProfile(%q) failed: %v", name, err)
+		}
+	}
+
+	profiles, err := ListProfiles()
+	if err != nil {
+		t.Fatalf("ListProfiles failed: %v", err)
+	}
+	if want := []string{"Studio B", "alice"}; !reflect.DeepEqual(profiles, want) {
+		t.Errorf("Expected profiles %v, got %v", want, profiles)
+	}
+
+	// Each profile keeps its own config and history
+	configPath, err := GetConfigFilePath()
+	if err != nil {
+		t.Fatalf("GetConfigFilePath failed: %v", err)
+	}
+	if want := filepath.Join(home, ".ramble", "profiles", "alice", "config.json"); configPath != want {
+		t.Errorf("Expected config path %s, got %s", want, configPath)
+	}
+	sessionDir, err := GetSessionDir()
+	if err != nil {
+		t.Fatalf("GetSessionDir failed: %v", err)
+	}
+	if want := filepath.Join(home, ".ramble", "profiles", "alice", "sessions"); sessionDir != want {
+		t.Errorf("Expected session dir %s, got %s", want, sessionDir)
+	}
+
+	// Models are shared
+	modelDir, err := GetModelDir()
+	if err != nil {
+		t.Fatalf("GetModelDir failed: %v", err)
+	}
+	if want := filepath.Join(home, ".ramble", "models"); modelDir != want {
+		t.Errorf("Expected model dir %s, got %s", want, modelDir)
+	}
+
+	if err := SetProfile(""); err != nil {
+		t.Fatalf("SetProfile(\"\") failed: %v", err)
+	}
+	if configPath, _ := GetConfigFilePath(); configPath != filepath.Join(home, ".ramble", "config.json") {
+		t.Errorf("Expected default profile config in .ramble, got %s", configPath)
+	}
+	if _, err := os.Stat(filepath.Join(home, ".ramble", "profiles", "alice")); err != nil {
+		t.Errorf("Expected profile directory to exist: %v", err)
+	}
+}
+
+func TestInvalidProfileNames(t *testing.T) {
+	for _, name := range []string{"", "  ", "../etc", "a/b", "dot.name"} {
+		if err := ValidateProfileName(name); err == nil {
+			t.Errorf("Expected %q to be rejected", name)
+		}
+	}
+}
@@ -0,0 +1,285 @@
+// Package crash writes diagnostic reports when the application panics
+package crash
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jeff-barlow-spady/ramble/pkg/config"
+	"github.com/jeff-barlow-spady/ramble/pkg/logger"
+)
+
+// minUptimeForRelaunch stops a crash during startup from relaunching in a loop
+const minUptimeForRelaunch = 30 * time.Second
+
+// reviewedDir is the subdirectory reports are moved to once the user has seen them
+const reviewedDir = "reviewed"
+
+var (
+	mu       sync.Mutex
+	state    = make(map[string]func() string)
+	handlers []func()
+	started  = time.Now()
+)
+
+// AddState registers a function describing part of the backend state for crash reports
+func AddState(name string, describe func() string) {
+	mu.Lock()
+	defer mu.Unlock()
+	state[name] = describe
+}
+
+// OnCrash registers a function to run after a crash report has been written,
+// e.g. to save the interrupted session
+func OnCrash(handler func()) {
+	mu.Lock()
+	defer mu.Unlock()
+	handlers = append(handlers, handler)
+}
+
+// Handle writes a crash report and exits if the goroutine is panicking.
+// It must be deferred directly: defer crash.Handle()
+func Handle() {
+	r := recover()
+	if r == nil {
+		return
+	}
+	stack := debug.Stack()
+
+	fmt.Fprintf(os.Stderr, "panic: %v\n\n%s\n", r, stack)
+
+	dir, err := config.GetCrashDir()
+	if err == nil {
+		var path string
+		path, err = writeReport(dir, r, stack)
+		if err == nil {
+			fmt.Fprintf(os.Stderr, "Crash report written to %s\n", path)
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to write crash report: %v\n", err)
+	}
+
+	runHandlers()
+
+	if config.Current.RelaunchAfterCrash {
+		relaunch()
+	}
+
+	os.Exit(2)
+}
+
+// Go runs fn in a new goroutine that writes a crash report if it panics
+func Go(fn func()) {
+	go func() {
+		defer Handle()
+		fn()
+	}()
+}
+
+// Pending returns the crash reports the user has not reviewed yet, oldest first
+func Pending() ([]string, error) {
+	dir, err := config.GetCrashDir()
+	if err != nil {
+		return nil, err
+	}
+	return pendingIn(dir)
+}
+
+// MarkReviewed moves a crash report out of the pending list
+func MarkReviewed(path string) error {
+	dir := filepath.Join(filepath.Dir(path), reviewedDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create reviewed crash report directory: %w", err)
+	}
+	if err := os.Rename(path, filepath.Join(dir, filepath.Base(path))); err != nil {
+		return fmt.Errorf("failed to mark crash report reviewed: %w", err)
+	}
+	return nil
+}
+
+// pendingIn lists unreviewed crash reports in dir
+func pendingIn(dir string) ([]string, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "crash-*.txt"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list crash reports: %w", err)
+	}
+	// Names are timestamped, so lexical order is chronological
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// writeReport saves a crash report for the panic value r in dir and returns its path
+func writeReport(dir string, r interface{}, stack []byte) (string, error) {
+	now := time.Now()
+	path := filepath.Join(dir, "crash-"+now.Format("20060102-150405")+".txt")
+
+	if err := os.WriteFile(path, []byte(buildReport(now, r, stack)), 0600); err != nil {
+		return "", fmt.Errorf("failed to write crash report: %w", err)
+	}
+	return path, nil
+}
+
+// buildReport formats the crash report text
+func buildReport(now time.Time, r interface{}, stack []byte) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "Ramble crash report\n")
+	fmt.Fprintf(&b, "Time:    %s\n", now.Format(time.RFC3339))
+	fmt.Fprintf(&b, "Uptime:  %s\n", now.Sub(started).Round(time.Second))
+	fmt.Fprintf(&b, "Runtime: %s %s/%s\n", runtime.Version(), runtime.GOOS, runtime.GOARCH)
+	fmt.Fprintf(&b, "Panic:   %v\n", r)
+
+	fmt.Fprintf(&b, "\n== Stack ==\n%s\n", stack)
+
+	fmt.Fprintf(&b, "\n== Backend state ==\n")
+	for _, line := range describeState() {
+		fmt.Fprintln(&b, line)
+	}
+
+	fmt.Fprintf(&b, "\n== Config (sanitized) ==\n%s\n", sanitizedConfig())
+
+	fmt.Fprintf(&b, "\n== Recent log ==\n")
+	for _, line := range logger.Recent() {
+		fmt.Fprintln(&b, line)
+	}
+
+	return b.String()
+}
+
+// describeState collects the registered state descriptions, sorted by name
+func describeState() []string {
+	described := State()
+	names := make([]string, 0, len(described))
+	for name := range described {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	lines := make([]string, 0, len(names))
+	for _, name := range names {
+		lines = append(lines, fmt.Sprintf("%s: %s", name, described[name]))
+	}
+	return lines
+}
+
+// State returns the registered state descriptions by name, as written to
+// crash reports, e.g. for a diagnostics endpoint
+func State() map[string]string {
+	mu.Lock()
+	describers := make(map[string]func() string, len(state))
+	for name, describe := range state {
+		describers[name] = describe
+	}
+	mu.Unlock()
+
+	described := make(map[string]string, len(describers))
+	for name, describe := range describers {
+		described[name] = safeDescribe(describe)
+	}
+	return described
+}
+
+// safeDescribe calls describe, guarding against a second panic while reporting the first
+func safeDescribe(describe func() string) (desc string) {
+	defer func() {
+		if r := recover(); r != nil {
+			desc = fmt.Sprintf("<unavailable: %v>", r)
+		}
+	}()
+	return describe()
+}
+
+// sanitizedConfig returns the current config as JSON with secrets and the
+// user's home directory removed
+func sanitizedConfig() string {
+	data, err := json.Marshal(config.Current)
+	if err != nil {
+		return fmt.Sprintf("<unavailable: %v>", err)
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return fmt.Sprintf("<unavailable: %v>", err)
+	}
+
+	home, _ := os.UserHomeDir()
+	sanitize(fields, home)
+
+	out, err := json.MarshalIndent(fields, "", "  ")
+	if err != nil {
+		return fmt.Sprintf("<unavailable: %v>", err)
+	}
+	return string(out)
+}
+
+// sanitize redacts secret-looking fields and replaces the home directory with ~
+func sanitize(fields map[string]interface{}, home string) {
+	for key, value := range fields {
+		lower := strings.ToLower(key)
+		if strings.Contains(lower, "key") && !strings.HasPrefix(lower, "hotkey") ||
+			strings.Contains(lower, "token") ||
+			strings.Contains(lower, "secret") ||
+			strings.Contains(lower, "password") {
+			fields[key] = "[redacted]"
+			continue
+		}
+
+		switch v := value.(type) {
+		case string:
+			if home != "" {
+				fields[key] = strings.ReplaceAll(v, home, "~")
+			}
+		case map[string]interface{}:
+			sanitize(v, home)
+		}
+	}
+}
+
+// runHandlers calls the registered crash handlers, ignoring any that panic
+func runHandlers() {
+	mu.Lock()
+	pending := append([]func(){}, handlers...)
+	mu.Unlock()
+
+	for _, handler := range pending {
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					fmt.Fprintf(os.Stderr, "Crash handler failed: %v\n", r)
+				}
+			}()
+			handler()
+		}()
+	}
+}
+
+// relaunch starts a new instance of the application with the same arguments
+func relaunch() {
+	if time.Since(started) < minUptimeForRelaunch {
+		fmt.Fprintf(os.Stderr, "Not relaunching: crashed within %s of starting\n", minUptimeForRelaunch)
+		return
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to relaunch: %v\n", err)
+		return
+	}
+
+	cmd := exec.Command(exe, os.Args[1:]...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Start(); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to relaunch: %v\n", err)
+	}
+}
@@ -0,0 +1,84 @@
+package crash
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jeff-barlow-spady/ramble/pkg/logger"
+)
+
+func TestReportContents(t *testing.T) {
+	AddState("recorder", func() string { return "listening" })
+	AddState("broken", func() string { panic("no state") })
+	logger.Info(logger.CategoryApp, "last words")
+
+	report := buildReport(time.Now(), "boom", []byte("goroutine 1 [running]"))
+
+	for _, want := range []string{
+		"Panic:   boom",
+		"goroutine 1 [running]",
+		"recorder: listening",
+		"broken: <unavailable: no state>",
+		"last words",
+		"== Config (sanitized) ==",
+	} {
+		if !strings.Contains(report, want) {
+			t.Errorf("Expected report to contain %q", want)
+		}
+	}
+}
+
+func TestSanitize(t *testing.T) {
+	fields := map[string]interface{}{
+		"APIKey":           "abc123",
+		"AuthToken":        "xyz",
+		"HotKeyKey":        "s",
+		"WhisperModelPath": "/home/user/.ramble/models",
+		"Theme":            map[string]interface{}{"Password": "hunter2"},
+	}
+
+	sanitize(fields, "/home/user")
+
+	if fields["APIKey"] != "[redacted]" || fields["AuthToken"] != "[redacted]" {
+		t.Errorf("Expected secrets to be redacted, got %v", fields)
+	}
+	if fields["HotKeyKey"] != "s" {
+		t.Errorf("Expected hotkey to be kept, got %v", fields["HotKeyKey"])
+	}
+	if fields["WhisperModelPath"] != "~/.ramble/models" {
+		t.Errorf("Expected home directory to be hidden, got %v", fields["WhisperModelPath"])
+	}
+	if nested := fields["Theme"].(map[string]interface{}); nested["Password"] != "[redacted]" {
+		t.Errorf("Expected nested secret to be redacted, got %v", nested)
+	}
+}
+
+func TestPendingAndReviewed(t *testing.T) {
+	dir := t.TempDir()
+
+	path, err := writeReport(dir, "boom", nil)
+	if err != nil {
+		t.Fatalf("writeReport failed: %v", err)
+	}
+
+	pending, err := pendingIn(dir)
+	if err != nil {
+		t.Fatalf("pendingIn failed: %v", err)
+	}
+	if len(pending) != 1 || pending[0] != path {
+		t.Fatalf("Expected [%s] pending, got %v", path, pending)
+	}
+
+	if err := MarkReviewed(path); err != nil {
+		t.Fatalf("MarkReviewed failed: %v", err)
+	}
+	if pending, _ := pendingIn(dir); len(pending) != 0 {
+		t.Errorf("Expected no pending reports after review, got %v", pending)
+	}
+	if _, err := os.Stat(filepath.Join(dir, reviewedDir, filepath.Base(path))); err != nil {
+		t.Errorf("Expected reviewed report to be kept: %v", err)
+	}
+}
@@ -0,0 +1,394 @@
+// Package engine captures speech and transcribes it, the pipeline behind
+// Ramble's window, for Go programs embedding Ramble without its user
+// interface. It doesn't depend on Fyne.
+//
+//	eng, err := engine.New(engine.Options{Pause: time.Second})
+//	if err != nil {
+//		return err
+//	}
+//	defer eng.Close()
+//	go func() {
+//		for event := range eng.Events() {
+//			if event.Type == engine.EventSegment {
+//				fmt.Println(event.Text)
+//			}
+//		}
+//	}()
+//	if err := eng.Start(); err != nil {
+//		return err
+//	}
+//	...
+//	eng.Stop()
+//
+// Sessions, outputs, cleanup and the other features of the application are
+// left to the embedding program, which gets each finished segment as an
+// event.
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jeff-barlow-spady/ramble/pkg/audio"
+	"github.com/jeff-barlow-spady/ramble/pkg/logger"
+	"github.com/jeff-barlow-spady/ramble/pkg/textproc"
+	"github.com/jeff-barlow-spady/ramble/pkg/transcription"
+)
+
+// drainTimeout bounds how long stopping waits for the end of the recording
+const drainTimeout = 5 * time.Second
+
+// eventBuffer is how many events are held for a reader that falls behind
+const eventBuffer = 64
+
+// Options configure an Engine
+type Options struct {
+	// Transcriber turns speech into text. If nil, whisper is loaded with the
+	// installed Model.
+	Transcriber transcription.Transcriber
+	// Model is the whisper model used without a Transcriber; tiny if empty
+	Model transcription.ModelSize
+	// Backend records the audio, e.g. one from audio.NewBackend or
+	// audio.NewPipeBackend. If nil, the default microphone is recorded
+	// through PortAudio.
+	Backend audio.Backend
+	// SampleRate is the rate audio is recorded at; 16kHz if 0
+	SampleRate float64
+	// Language is the language spoken, or transcription.LanguageAuto to
+	// detect it, and decides how text is capitalized. If empty, the
+	// transcriber's language is kept.
+	Language string
+	// Pause ends a segment when the speaker pauses this long. If 0, a
+	// segment ends only when recording stops.
+	Pause time.Duration
+	// OnAudio, if set, is called with each buffer of captured 16kHz audio,
+	// e.g. to show its level. It runs in the audio callback, so it must
+	// return quickly and not keep the samples.
+	OnAudio func(samples []float32)
+}
+
+// EventType tells what an Event reports
+type EventType string
+
+const (
+	EventPartial EventType = "partial" // Text transcribed live, continuing the current segment
+	EventSegment EventType = "segment" // A segment ended, with all of its text
+	EventError   EventType = "error"   // Transcription failed; recording carries on
+)
+
+// Event reports transcribed text or a failure
+type Event struct {
+	Type      EventType
+	Text      string    // The new text of an EventPartial, or the whole text of an EventSegment
+	Segment   int       // Number of the segment, counting from 1 over the engine's life
+	Language  string    // Language most of an EventSegment was spoken in, when detected
+	StartedAt time.Time // When an EventSegment started
+	EndedAt   time.Time // When an EventSegment ended
+	Err       error     // What failed, for an EventError
+}
+
+// Engine records audio and transcribes it live, reporting text as events
+type Engine struct {
+	capture     *audio.Capture
+	transcriber transcription.Transcriber
+	pause       time.Duration
+	onAudio     func(samples []float32)
+	language    string // Language chosen in Options, if any
+
+	events  chan Event
+	done    chan struct{} // Closed by Close, releasing senders waiting on events
+	sending sync.RWMutex  // Held by senders so Close can close events
+
+	mu        sync.Mutex
+	recording bool
+	closed    bool
+	stop      chan struct{} // Closed by Stop to end the audio consumer
+	stopped   chan struct{} // Closed by the audio consumer once it is done
+
+	// The segment being transcribed
+	text      string
+	languages map[string]int // Texts heard in each language
+	spoken    string         // Language detected for the newest text
+	started   time.Time
+	segments  int // Segments ended so far
+}
+
+// New creates an engine, loading the transcription model and opening the
+// audio system. Close releases them.
+func New(options Options) (*Engine, error) {
+	transcriber := options.Transcriber
+	if transcriber == nil {
+		model := options.Model
+		if model == "" {
+			model = transcription.ModelTiny
+		}
+		path := transcription.GetLocalModelPath(model)
+		if path == "" {
+			return nil, fmt.Errorf("the %s model is not installed", model)
+		}
+		manager, err := transcription.NewManager(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize transcriber: %w", err)
+		}
+		transcriber = manager
+	}
+
+	var capture *audio.Capture
+	var err error
+	if options.Backend != nil {
+		capture, err = audio.NewWithBackend(options.Backend, options.SampleRate, false)
+	} else {
+		capture, err = audio.New(options.SampleRate, false)
+	}
+	if err != nil {
+		transcriber.Close()
+		return nil, err
+	}
+
+	e := &Engine{
+		capture:     capture,
+		transcriber: transcriber,
+		pause:       options.Pause,
+		onAudio:     options.OnAudio,
+		language:    options.Language,
+		events:      make(chan Event, eventBuffer),
+		done:        make(chan struct{}),
+	}
+	if options.Language != "" {
+		transcriber.SetLanguage(options.Language)
+	}
+	transcriber.SetLanguageCallback(e.noteLanguage)
+	transcriber.SetStreamingCallback(e.appendText)
+	return e, nil
+}
+
+// Events returns the channel text and failures are reported on, closed by
+// Close. Transcription waits while the channel is full, so it should be
+// read until closed.
+func (e *Engine) Events() <-chan Event {
+	return e.events
+}
+
+// Start begins recording and transcribing, reloading the model first if it
+// was unloaded
+func (e *Engine) Start() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.closed {
+		return errors.New("engine closed")
+	}
+	if e.recording {
+		return errors.New("already recording")
+	}
+	if !e.transcriber.IsLoaded() {
+		if err := e.transcriber.Load(); err != nil {
+			return fmt.Errorf("failed to load the transcription model: %w", err)
+		}
+	}
+
+	e.transcriber.SetRecordingState(true)
+	if err := e.capture.Start(e.onAudio); err != nil {
+		e.transcriber.SetRecordingState(false)
+		return fmt.Errorf("failed to start recording: %w", err)
+	}
+
+	e.recording = true
+	e.text, e.languages, e.started = "", nil, time.Now()
+	e.stop, e.stopped = make(chan struct{}), make(chan struct{})
+	go e.consume(e.stop, e.stopped)
+	return nil
+}
+
+// Stop ends recording once the audio already captured is transcribed, which
+// ends the last segment. It does nothing if not recording.
+func (e *Engine) Stop() error {
+	e.mu.Lock()
+	if !e.recording {
+		e.mu.Unlock()
+		return nil
+	}
+	e.recording = false
+	stop, stopped := e.stop, e.stopped
+	e.stop, e.stopped = nil, nil
+	e.mu.Unlock()
+
+	err := e.capture.Stop()
+	close(stop)
+	<-stopped
+
+	// Transcribe the rest of the recording, so the last sentence isn't lost
+	if drainErr := e.transcriber.Drain(drainTimeout); drainErr != nil {
+		logger.Warning(logger.CategoryTranscription, "Stopped without the end of the recording: %v", drainErr)
+	}
+	e.endSegment()
+	return err
+}
+
+// Close stops recording, releases the audio system and the transcriber,
+// including one passed in Options, and closes the events channel. Events
+// not yet read are dropped, so stop first to read the last segment.
+func (e *Engine) Close() error {
+	e.mu.Lock()
+	if e.closed {
+		e.mu.Unlock()
+		return nil
+	}
+	e.closed = true
+	e.mu.Unlock()
+
+	close(e.done)
+	err := e.Stop()
+
+	e.sending.Lock()
+	close(e.events)
+	e.sending.Unlock()
+
+	return errors.Join(err, e.capture.Close(), e.transcriber.Close())
+}
+
+// consume feeds captured audio to the transcriber until stop is closed,
+// then hands it what is still queued. While the transcriber is busy, audio
+// stays in the capture queue. A pause ends the segment once the transcriber
+// has caught up with the speech before it.
+func (e *Engine) consume(stop <-chan struct{}, stopped chan<- struct{}) {
+	defer close(stopped)
+
+	// Reused for every read so the capture path doesn't allocate
+	samples := make([]float32, 1024)
+	var utterances *audio.UtteranceDetector
+	if e.pause > 0 {
+		utterances = audio.NewUtteranceDetector(audio.TargetSampleRate, e.pause)
+	}
+	paused := false
+
+	feed := func(stopping bool) {
+		for {
+			n := e.capture.Read(samples)
+			if n == 0 {
+				return
+			}
+			if !stopping && utterances != nil && utterances.Process(samples[:n]) {
+				paused = true
+			}
+			e.transcriber.AppendAudio(samples[:n])
+		}
+	}
+
+	for {
+		select {
+		case <-stop:
+			feed(true)
+			return
+		case <-e.capture.Ready():
+		}
+
+		if e.transcriber.IsBusy() {
+			continue
+		}
+		if paused {
+			paused = false
+			e.transcriber.EndUtterance()
+			e.endSegment()
+		}
+
+		feed(false)
+		if _, err := e.transcriber.ProcessAudioChunk(nil); err != nil {
+			logger.Error(logger.CategoryTranscription, "Error processing audio: %v", err)
+			e.emit(Event{Type: EventError, Segment: e.currentSegment(), Err: err})
+		}
+	}
+}
+
+// appendText adds live text to the segment, capitalized by the rules of the
+// language it is in where it starts the segment or a sentence
+func (e *Engine) appendText(text string) {
+	e.mu.Lock()
+	language := e.language
+	if language == "" || language == transcription.LanguageAuto {
+		language = e.spoken
+	}
+	previous := e.text
+	e.mu.Unlock()
+
+	text = transcription.NormalizeLiveText(text, previous, language)
+	if text == "" {
+		return
+	}
+
+	e.mu.Lock()
+	e.text = textproc.Join(e.text, text)
+	segment := e.segments + 1
+	e.mu.Unlock()
+
+	e.emit(Event{Type: EventPartial, Text: text, Segment: segment})
+}
+
+// noteLanguage records the language detected for the next live text
+func (e *Engine) noteLanguage(language string) {
+	if language == "" {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.languages == nil {
+		e.languages = make(map[string]int)
+	}
+	e.languages[language]++
+	e.spoken = language
+}
+
+// endSegment reports the segment so far, if anything was said, and starts
+// the next one
+func (e *Engine) endSegment() {
+	e.mu.Lock()
+	text, started, ended := e.text, e.started, time.Now()
+	var language string
+	for l, count := range e.languages {
+		if count > e.languages[language] || (count == e.languages[language] && l < language) {
+			language = l
+		}
+	}
+	e.text, e.languages, e.started = "", nil, ended
+	if text == "" {
+		e.mu.Unlock()
+		return
+	}
+	e.segments++
+	segment := e.segments
+	e.mu.Unlock()
+
+	e.emit(Event{
+		Type:      EventSegment,
+		Text:      text,
+		Segment:   segment,
+		Language:  language,
+		StartedAt: started,
+		EndedAt:   ended,
+	})
+}
+
+// currentSegment returns the number of the segment being transcribed
+func (e *Engine) currentSegment() int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.segments + 1
+}
+
+// emit sends an event, waiting for the reader unless the engine is closed
+func (e *Engine) emit(event Event) {
+	e.sending.RLock()
+	defer e.sending.RUnlock()
+
+	select {
+	case <-e.done:
+		return
+	default:
+	}
+	select {
+	case e.events <- event:
+	case <-e.done:
+	}
+}
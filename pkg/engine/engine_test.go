@@ -0,0 +1,135 @@
+package engine
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jeff-barlow-spady/ramble/pkg/audio"
+	"github.com/jeff-barlow-spady/ramble/pkg/transcription"
+)
+
+// fakeTranscriber "transcribes" each pass with speech in it as one word
+type fakeTranscriber struct {
+	transcription.Transcriber
+
+	mu       sync.Mutex
+	pending  []float32
+	callback func(string)
+	closed   bool
+}
+
+func (f *fakeTranscriber) SetStreamingCallback(callback func(string)) { f.callback = callback }
+func (f *fakeTranscriber) SetLanguageCallback(func(string))           {}
+func (f *fakeTranscriber) SetRecordingState(bool)                     {}
+func (f *fakeTranscriber) IsLoaded() bool                             { return true }
+func (f *fakeTranscriber) IsBusy() bool                               { return false }
+func (f *fakeTranscriber) EndUtterance()                              {}
+
+func (f *fakeTranscriber) AppendAudio(samples []float32) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.pending = append(f.pending, samples...)
+}
+
+func (f *fakeTranscriber) ProcessAudioChunk([]float32) (string, error) {
+	f.mu.Lock()
+	heard := audio.CalculateLevel(f.pending) >= audio.SpeechLevel
+	f.pending = nil
+	f.mu.Unlock()
+	if heard {
+		f.callback("Word")
+	}
+	return "", nil
+}
+
+func (f *fakeTranscriber) Drain(time.Duration) error {
+	_, err := f.ProcessAudioChunk(nil)
+	return err
+}
+
+func (f *fakeTranscriber) Close() error {
+	f.closed = true
+	return nil
+}
+
+// pcm returns raw 16kHz PCM of a tone for each true in parts and silence
+// for each false, each lasting length
+func pcm(length time.Duration, parts ...bool) []byte {
+	var buf bytes.Buffer
+	n := int(length.Seconds() * audio.TargetSampleRate)
+	for _, tone := range parts {
+		for i := 0; i < n; i++ {
+			var sample int16
+			if tone {
+				sample = int16(8000 * math.Sin(2*math.Pi*440*float64(i)/audio.TargetSampleRate))
+			}
+			binary.Write(&buf, binary.LittleEndian, sample)
+		}
+	}
+	return buf.Bytes()
+}
+
+func TestEngine(t *testing.T) {
+	input := audio.NewPipeBackend("test", bytes.NewReader(pcm(300*time.Millisecond, true, false, true)), audio.TargetSampleRate, 1)
+	transcriber := &fakeTranscriber{}
+	eng, err := New(Options{Transcriber: transcriber, Backend: input, Pause: 150 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	var events []Event
+	read := make(chan struct{})
+	go func() {
+		defer close(read)
+		for event := range eng.Events() {
+			events = append(events, event)
+		}
+	}()
+
+	if err := eng.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	if err := eng.Start(); err == nil {
+		t.Error("Expected starting twice to fail")
+	}
+	<-input.Ended()
+	if err := eng.Stop(); err != nil {
+		t.Errorf("Stop failed: %v", err)
+	}
+	if err := eng.Close(); err != nil {
+		t.Errorf("Close failed: %v", err)
+	}
+	<-read
+
+	// The pause ends the first segment and stopping the second
+	var segments []Event
+	for _, event := range events {
+		switch event.Type {
+		case EventSegment:
+			segments = append(segments, event)
+		case EventPartial:
+			if event.Text != "Word" || event.Segment != len(segments)+1 {
+				t.Errorf("Unexpected partial event %+v", event)
+			}
+		}
+	}
+	if len(segments) != 2 {
+		t.Fatalf("Expected 2 segments, got %+v", events)
+	}
+	for i, segment := range segments {
+		if segment.Segment != i+1 || !strings.HasPrefix(segment.Text, "Word") || segment.EndedAt.Before(segment.StartedAt) {
+			t.Errorf("Unexpected segment %+v", segment)
+		}
+	}
+	if !transcriber.closed {
+		t.Error("Expected Close to close the transcriber")
+	}
+	if err := eng.Start(); err == nil {
+		t.Error("Expected a closed engine not to start")
+	}
+}
@@ -0,0 +1,112 @@
+// Package hooks runs programs the user configured on each finalized
+// segment, so transcripts can be post-processed without changing Ramble.
+// A hook reads the segment's text on stdin, with details about the segment
+// in RAMBLE_ environment variables, and may print replacement text. Hooks
+// run in order, each receiving the text the one before it printed.
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jeff-barlow-spady/ramble/pkg/commands"
+	"github.com/jeff-barlow-spady/ramble/pkg/session"
+)
+
+// timeout bounds how long a hook may take for one segment
+const timeout = 10 * time.Second
+
+// Runner runs the hooks on finalized segments
+type Runner struct {
+	commands []string // Command line of each hook
+}
+
+// NewRunner creates a runner for commands, one hook per command line, run
+// by the system shell like the shell actions of voice commands. Blank lines
+// are skipped.
+func NewRunner(commands []string) *Runner {
+	r := &Runner{}
+	for _, line := range commands {
+		if line = strings.TrimSpace(line); line != "" {
+			r.commands = append(r.commands, line)
+		}
+	}
+	return r
+}
+
+// Enabled reports whether there is any hook to run
+func (r *Runner) Enabled() bool {
+	return r != nil && len(r.commands) > 0
+}
+
+// Run passes text, the text of segment in the session with the given ID as
+// it is after any cleanup, through every hook and returns the result. A hook
+// printing nothing leaves the text as it is, e.g. one that only saves it. A
+// failing hook also leaves it, and its error is returned with the result
+// once the rest have run.
+func (r *Runner) Run(text, sessionID string, segment session.Segment) (string, error) {
+	if !r.Enabled() || strings.TrimSpace(text) == "" {
+		return text, nil
+	}
+
+	var errs []error
+	for _, line := range r.commands {
+		replaced, err := run(line, text, environment(text, sessionID, segment))
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if replaced != "" {
+			text = replaced
+		}
+	}
+	return text, errors.Join(errs...)
+}
+
+// run runs one hook's command line on text and returns what it printed,
+// trimmed
+func run(line, text string, env []string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	name, args := commands.ShellCommand(runtime.GOOS, line)
+	cmd := exec.CommandContext(ctx, name, args...)
+	// Don't wait on programs the shell started that outlive it
+	cmd.WaitDelay = time.Second
+	cmd.Stdin = strings.NewReader(text)
+	cmd.Env = append(os.Environ(), env...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("hook %s failed: %w: %s", line, err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// environment returns the variables describing a segment to a hook
+func environment(text, sessionID string, segment session.Segment) []string {
+	env := []string{
+		"RAMBLE_TEXT=" + text,
+		"RAMBLE_SESSION_ID=" + sessionID,
+		"RAMBLE_SEGMENT_ID=" + strconv.Itoa(segment.ID),
+		"RAMBLE_LANGUAGE=" + segment.Language,
+		"RAMBLE_TAGS=" + strings.Join(segment.Tags, ","),
+		"RAMBLE_AUDIO=" + segment.Audio,
+	}
+	if !segment.StartedAt.IsZero() {
+		env = append(env, "RAMBLE_STARTED_AT="+segment.StartedAt.Format(time.RFC3339))
+	}
+	if !segment.EndedAt.IsZero() {
+		env = append(env, "RAMBLE_ENDED_AT="+segment.EndedAt.Format(time.RFC3339))
+	}
+	return env
+}
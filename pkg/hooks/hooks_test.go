@@ -0,0 +1,66 @@
+package hooks
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jeff-barlow-spady/ramble/pkg/session"
+)
+
+func TestRunner(t *testing.T) {
+	for _, program := range []string{"sh", "tr"} {
+		if _, err := exec.LookPath(program); err != nil {
+			t.Skipf("%s not available", program)
+		}
+	}
+	segment := session.Segment{ID: 3, Language: "en", Tags: []string{"milk", "eggs"}}
+	dir := filepath.Join(t.TempDir(), "hook scripts")
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	script := filepath.Join(dir, "describe.sh")
+	err := os.WriteFile(script, []byte("#!/bin/sh\necho \"$(cat) [$RAMBLE_SESSION_ID#$RAMBLE_SEGMENT_ID $RAMBLE_LANGUAGE $RAMBLE_TAGS]\"\n"), 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Each hook gets the text the one before printed; silent and failing
+	// hooks leave it as it is
+	runner := NewRunner([]string{
+		"tr a-z A-Z",
+		"",
+		"true",
+		"false",
+		"sh '" + script + "'",
+	})
+	if len(runner.commands) != 4 {
+		t.Fatalf("Expected blank lines to be skipped, got %q", runner.commands)
+	}
+	text, err := runner.Run("buy milk", "s1", segment)
+	if err == nil || !strings.Contains(err.Error(), "hook false failed") {
+		t.Errorf("Expected the failing hook to be reported, got %v", err)
+	}
+	if text != "BUY MILK [s1#3 en milk,eggs]" {
+		t.Errorf("Unexpected text %q", text)
+	}
+
+	if text, err := NewRunner(nil).Run("as is", "s1", segment); err != nil || text != "as is" || NewRunner(nil).Enabled() {
+		t.Errorf("Expected no hooks to leave the text, got %q (%v)", text, err)
+	}
+}
+
+func TestRunnerShell(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+
+	// Quoted arguments stay whole, and pipes work
+	runner := NewRunner([]string{`printf '%s|%s' "Ramble done" "$(cat)"`, "tr a-z A-Z | tr -d '|'"})
+	text, err := runner.Run("really", "s1", session.Segment{ID: 1})
+	if err != nil || text != "RAMBLE DONEREALLY" {
+		t.Errorf("Expected the hooks to run in a shell, got %q (%v)", text, err)
+	}
+}
@@ -0,0 +1,165 @@
+// Package i18n translates the text shown in the user interface. Text is
+// written in English in the code and looked up in the bundle of the chosen
+// language, so anything without a translation is still shown in English.
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// LanguageAuto follows the language of the operating system
+const LanguageAuto = ""
+
+// DefaultLanguage is the language the interface is written in
+const DefaultLanguage = "en"
+
+// Language is a language the interface can be shown in
+type Language struct {
+	Code string // ISO 639-1 code, e.g. "de"
+	Name string // Name in the language itself, e.g. "Deutsch"
+}
+
+// Languages lists the languages the interface can be shown in
+var Languages = []Language{
+	{"en", "English"},
+	{"es", "Español"},
+	{"de", "Deutsch"},
+	{"fr", "Français"},
+}
+
+//go:embed locales/*.json
+var locales embed.FS
+
+var (
+	mu       sync.RWMutex
+	language string            // Code of the language in use, "" until chosen
+	bundle   map[string]string // English text to its translation
+)
+
+// SetLanguage shows the interface in a language given by its code, or in the
+// language of the operating system for LanguageAuto. Languages without a
+// bundle fall back to English. Text already shown is not changed.
+func SetLanguage(code string) {
+	code = strings.ToLower(strings.TrimSpace(code))
+	if code == LanguageAuto {
+		code = SystemLanguage()
+	}
+	translations, err := loadBundle(code)
+	if err != nil {
+		code, translations = DefaultLanguage, nil
+	}
+
+	mu.Lock()
+	language, bundle = code, translations
+	mu.Unlock()
+}
+
+// Current returns the code of the language in use
+func Current() string {
+	ensureLanguage()
+	mu.RLock()
+	defer mu.RUnlock()
+	return language
+}
+
+// T returns the translation of English text, or the text itself if the
+// language in use has none
+func T(text string) string {
+	ensureLanguage()
+	mu.RLock()
+	defer mu.RUnlock()
+	if translated, ok := bundle[text]; ok && translated != "" {
+		return translated
+	}
+	return text
+}
+
+// Tf translates a format string and formats it with args like fmt.Sprintf
+func Tf(format string, args ...any) string {
+	return fmt.Sprintf(T(format), args...)
+}
+
+// ensureLanguage follows the operating system if no language was set, e.g.
+// for a window shown before the configuration is loaded
+func ensureLanguage() {
+	mu.RLock()
+	chosen := language != ""
+	mu.RUnlock()
+	if !chosen {
+		SetLanguage(LanguageAuto)
+	}
+}
+
+// loadBundle reads the translations of a language. English needs none.
+func loadBundle(code string) (map[string]string, error) {
+	if code == DefaultLanguage {
+		return nil, nil
+	}
+	data, err := locales.ReadFile("locales/" + code + ".json")
+	if err != nil {
+		return nil, fmt.Errorf("no translations for %q: %w", code, err)
+	}
+	var translations map[string]string
+	if err := json.Unmarshal(data, &translations); err != nil {
+		return nil, fmt.Errorf("invalid translations for %q: %w", code, err)
+	}
+	return translations, nil
+}
+
+// SystemLanguage returns the code of the operating system's language, or
+// DefaultLanguage if it can't be told
+func SystemLanguage() string {
+	return systemLanguage(runtime.GOOS, os.Getenv, func(name string, args ...string) (string, error) {
+		out, err := exec.Command(name, args...).Output()
+		return string(out), err
+	})
+}
+
+// systemLanguage reads the language from the locale environment variables,
+// then from the platform's settings
+func systemLanguage(goos string, getenv func(string) string, run func(name string, args ...string) (string, error)) string {
+	for _, name := range []string{"LC_ALL", "LC_MESSAGES", "LANGUAGE", "LANG"} {
+		if code := languageCode(getenv(name)); code != "" {
+			return code
+		}
+	}
+
+	var out string
+	var err error
+	switch goos {
+	case "darwin":
+		out, err = run("defaults", "read", "-g", "AppleLocale")
+	case "windows":
+		out, err = run("powershell", "-NoProfile", "-Command", "(Get-UICulture).Name")
+	default:
+		return DefaultLanguage
+	}
+	if code := languageCode(out); err == nil && code != "" {
+		return code
+	}
+	return DefaultLanguage
+}
+
+// languageCode returns the language of a locale such as "de_DE.UTF-8",
+// "fr-CA" or "es:en", or "" for none or the C locale
+func languageCode(locale string) string {
+	locale, _, _ = strings.Cut(strings.TrimSpace(locale), ":")
+	fields := strings.FieldsFunc(locale, func(r rune) bool {
+		return r == '_' || r == '-' || r == '.' || r == '@'
+	})
+	if len(fields) == 0 {
+		return ""
+	}
+	code := strings.ToLower(fields[0])
+	if code == "c" || code == "posix" {
+		return ""
+	}
+	return code
+}
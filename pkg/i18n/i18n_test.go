@@ -0,0 +1,88 @@
+package i18n
+
+import (
+	"errors"
+	"regexp"
+	"slices"
+	"testing"
+)
+
+func TestLanguageCode(t *testing.T) {
+	tests := map[string]string{
+		"de_DE.UTF-8": "de",
+		"fr-CA":       "fr",
+		"es:en":       "es",
+		"sr@latin":    "sr",
+		"C.UTF-8":     "",
+		"POSIX":       "",
+		"":            "",
+	}
+	for locale, want := range tests {
+		if got := languageCode(locale); got != want {
+			t.Errorf("languageCode(%q) = %q, want %q", locale, got, want)
+		}
+	}
+}
+
+func TestSystemLanguage(t *testing.T) {
+	env := func(vars map[string]string) func(string) string {
+		return func(name string) string { return vars[name] }
+	}
+	run := func(out string, err error) func(string, ...string) (string, error) {
+		return func(string, ...string) (string, error) { return out, err }
+	}
+
+	if got := systemLanguage("linux", env(map[string]string{"LANG": "fr_FR.UTF-8", "LC_ALL": "de_DE"}), run("", nil)); got != "de" {
+		t.Errorf("Expected LC_ALL to win, got %q", got)
+	}
+	if got := systemLanguage("linux", env(map[string]string{"LANG": "C"}), run("", nil)); got != DefaultLanguage {
+		t.Errorf("Expected the default language for the C locale, got %q", got)
+	}
+	if got := systemLanguage("darwin", env(nil), run("es_MX\n", nil)); got != "es" {
+		t.Errorf("Expected the macOS locale, got %q", got)
+	}
+	if got := systemLanguage("windows", env(nil), run("", errors.New("not found"))); got != DefaultLanguage {
+		t.Errorf("Expected the default language when the locale can't be read, got %q", got)
+	}
+}
+
+func TestTranslate(t *testing.T) {
+	defer SetLanguage(DefaultLanguage)
+
+	SetLanguage("DE")
+	if Current() != "de" || T("Cancel") != "Abbrechen" {
+		t.Errorf("Expected German, got %q translating Cancel to %q", Current(), T("Cancel"))
+	}
+	if got := T("No translation for this"); got != "No translation for this" {
+		t.Errorf("Expected untranslated text in English, got %q", got)
+	}
+	if got := Tf("Delete %s?", "tiny"); got != "tiny löschen?" {
+		t.Errorf("Expected a formatted translation, got %q", got)
+	}
+
+	SetLanguage("tlh")
+	if Current() != DefaultLanguage || T("Cancel") != "Cancel" {
+		t.Errorf("Expected English for a language without a bundle, got %q", Current())
+	}
+}
+
+func TestBundles(t *testing.T) {
+	verbs := regexp.MustCompile(`%[-+# 0]*\d*(\.\d+)?[a-zA-Z%]`)
+	for _, language := range Languages {
+		bundle, err := loadBundle(language.Code)
+		if err != nil {
+			t.Fatalf("Failed to load %s: %v", language.Code, err)
+		}
+		if language.Code != DefaultLanguage && len(bundle) == 0 {
+			t.Errorf("Expected translations for %s", language.Code)
+		}
+		for text, translated := range bundle {
+			want, got := verbs.FindAllString(text, -1), verbs.FindAllString(translated, -1)
+			slices.Sort(want)
+			slices.Sort(got)
+			if !slices.Equal(want, got) {
+				t.Errorf("%s translation of %q has verbs %q, want %q", language.Code, text, got, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,136 @@
+// Package instance lets other processes control the running Ramble through
+// a socket, so launching Ramble again raises its window instead of starting
+// a second copy, and scripts or hotkey daemons can start and stop recording.
+//
+// Each request is a JSON object with the command on one line, answered with
+// a JSON object holding a reply or an error.
+package instance
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"time"
+)
+
+// Commands understood by the running instance
+const (
+	CommandShow     = "show"             // Show the main window
+	CommandToggle   = "toggle-recording" // Start recording, or stop if recording
+	CommandStart    = "start"            // Start recording
+	CommandStop     = "stop"             // Stop recording
+	CommandCopyLast = "copy-last"        // Copy the newest segment, replying with its text
+)
+
+var (
+	// ErrRunning is returned by Listen when another instance is listening
+	ErrRunning = errors.New("Ramble is already running")
+	// ErrNotRunning is returned by Send when no instance is listening
+	ErrNotRunning = errors.New("Ramble is not running")
+)
+
+// requestTimeout bounds how long a request may take, including running it
+const requestTimeout = 10 * time.Second
+
+// request is sent to the running instance
+type request struct {
+	Command string `json:"command"`
+}
+
+// response answers a request
+type response struct {
+	Reply string `json:"reply,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// Server runs the commands sent to the running instance
+type Server struct {
+	listener net.Listener
+	handle   func(command string) (string, error)
+}
+
+// Listen accepts commands on the socket at path, passing each to handle,
+// which returns the reply for the sender. It fails with ErrRunning if
+// another instance already listens there.
+func Listen(path string, handle func(command string) (string, error)) (*Server, error) {
+	if conn, err := net.DialTimeout("unix", path, time.Second); err == nil {
+		conn.Close()
+		return nil, ErrRunning
+	}
+
+	// Replace the socket of an instance that crashed
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to remove stale socket: %w", err)
+	}
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen for commands: %w", err)
+	}
+	if err := os.Chmod(path, 0600); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("failed to restrict socket: %w", err)
+	}
+
+	s := &Server{listener: listener, handle: handle}
+	go s.serve()
+	return s, nil
+}
+
+// Close stops accepting commands and removes the socket
+func (s *Server) Close() error {
+	return s.listener.Close()
+}
+
+// serve accepts connections until the server is closed
+func (s *Server) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.serveConn(conn)
+	}
+}
+
+// serveConn answers the request on conn
+func (s *Server) serveConn(conn net.Conn) {
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(requestTimeout))
+
+	var req request
+	if err := json.NewDecoder(conn).Decode(&req); err != nil {
+		return
+	}
+	var resp response
+	if reply, err := s.handle(req.Command); err != nil {
+		resp.Error = err.Error()
+	} else {
+		resp.Reply = reply
+	}
+	json.NewEncoder(conn).Encode(resp)
+}
+
+// Send runs command in the instance listening on the socket at path and
+// returns its reply. It fails with ErrNotRunning if none is listening.
+func Send(path, command string) (string, error) {
+	conn, err := net.DialTimeout("unix", path, time.Second)
+	if err != nil {
+		return "", ErrNotRunning
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(requestTimeout))
+
+	if err := json.NewEncoder(conn).Encode(request{Command: command}); err != nil {
+		return "", fmt.Errorf("failed to send command: %w", err)
+	}
+	var resp response
+	if err := json.NewDecoder(conn).Decode(&resp); err != nil {
+		return "", fmt.Errorf("failed to read reply: %w", err)
+	}
+	if resp.Error != "" {
+		return "", errors.New(resp.Error)
+	}
+	return resp.Reply, nil
+}
@@ -0,0 +1,51 @@
+package instance
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestServer(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ramble.sock")
+	if _, err := Send(path, CommandToggle); !errors.Is(err, ErrNotRunning) {
+		t.Fatalf("Expected ErrNotRunning before listening, got %v", err)
+	}
+
+	// A socket left by a crashed instance is replaced
+	if err := os.WriteFile(path, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	var received []string
+	server, err := Listen(path, func(command string) (string, error) {
+		received = append(received, command)
+		if command == CommandCopyLast {
+			return "last segment", nil
+		}
+		return "", errors.New("not recording")
+	})
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+
+	if reply, err := Send(path, CommandCopyLast); err != nil || reply != "last segment" {
+		t.Errorf("Expected the reply, got %q (%v)", reply, err)
+	}
+	if _, err := Send(path, CommandStop); err == nil || err.Error() != "not recording" {
+		t.Errorf("Expected the handler's error, got %v", err)
+	}
+	if len(received) != 2 || received[1] != CommandStop {
+		t.Errorf("Expected both commands to be handled, got %v", received)
+	}
+
+	if _, err := Listen(path, nil); !errors.Is(err, ErrRunning) {
+		t.Errorf("Expected ErrRunning for a second instance, got %v", err)
+	}
+
+	server.Close()
+	if _, err := Send(path, CommandShow); !errors.Is(err, ErrNotRunning) {
+		t.Errorf("Expected ErrNotRunning after closing, got %v", err)
+	}
+}
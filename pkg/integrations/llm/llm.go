@@ -0,0 +1,163 @@
+// Package llm summarizes transcripts with a large language model, running
+// locally in Ollama or behind an OpenAI-compatible chat completions endpoint
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Providers Client can talk to
+const (
+	ProviderOllama = "ollama" // Ollama's /api/generate
+	ProviderOpenAI = "openai" // /v1/chat/completions, as served by OpenAI and most local servers
+)
+
+// DefaultOllamaURL is where Ollama listens unless configured otherwise
+const DefaultOllamaURL = "http://127.0.0.1:11434"
+
+// TranscriptPlaceholder is replaced by the transcript in a prompt template
+const TranscriptPlaceholder = "{{transcript}}"
+
+// DefaultPrompt asks for a summary and the action items of a transcript
+const DefaultPrompt = `Summarize the following dictated transcript in a few sentences, then list any action items as bullet points under the heading "Action items". Write "None" if there are no action items. Answer in Markdown.
+
+Transcript:
+{{transcript}}`
+
+// requestTimeout bounds how long a summary may take; local models on a CPU
+// can be slow with long transcripts
+const requestTimeout = 5 * time.Minute
+
+// Options configures a Client
+type Options struct {
+	Provider string // ProviderOllama or ProviderOpenAI
+	URL      string // Base URL of the server, without the API path
+	Model    string // Model name, e.g. "llama3.2"
+	APIKey   string // Sent as a bearer token if set
+}
+
+// Client sends prompts to a language model
+type Client struct {
+	opts   Options
+	client *http.Client
+}
+
+// NewClient creates a client for the model in opts
+func NewClient(opts Options) (*Client, error) {
+	switch opts.Provider {
+	case ProviderOllama:
+		if opts.URL == "" {
+			opts.URL = DefaultOllamaURL
+		}
+	case ProviderOpenAI:
+	default:
+		return nil, fmt.Errorf("unknown LLM provider %q", opts.Provider)
+	}
+	if opts.Model == "" {
+		return nil, errors.New("no LLM model configured")
+	}
+	u, err := url.Parse(opts.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("invalid LLM URL %q", opts.URL)
+	}
+	opts.URL = strings.TrimSuffix(opts.URL, "/")
+	return &Client{opts: opts, client: &http.Client{Timeout: requestTimeout}}, nil
+}
+
+// Prompt fills the transcript into template. A template without the
+// placeholder gets the transcript appended, and an empty one is DefaultPrompt.
+func Prompt(template, transcript string) string {
+	if strings.TrimSpace(template) == "" {
+		template = DefaultPrompt
+	}
+	if !strings.Contains(template, TranscriptPlaceholder) {
+		return template + "\n\n" + transcript
+	}
+	return strings.ReplaceAll(template, TranscriptPlaceholder, transcript)
+}
+
+// Summarize asks the model to answer the prompt template for transcript
+func (c *Client) Summarize(ctx context.Context, template, transcript string) (string, error) {
+	return c.Complete(ctx, Prompt(template, transcript))
+}
+
+// Complete sends prompt to the model and returns its answer
+func (c *Client) Complete(ctx context.Context, prompt string) (string, error) {
+	var answer string
+	var err error
+	if c.opts.Provider == ProviderOllama {
+		var response struct {
+			Response string `json:"response"`
+		}
+		err = c.post(ctx, "/api/generate", map[string]any{
+			"model":  c.opts.Model,
+			"prompt": prompt,
+			"stream": false,
+		}, &response)
+		answer = response.Response
+	} else {
+		var response struct {
+			Choices []struct {
+				Message struct {
+					Content string `json:"content"`
+				} `json:"message"`
+			} `json:"choices"`
+		}
+		err = c.post(ctx, "/v1/chat/completions", map[string]any{
+			"model":    c.opts.Model,
+			"messages": []map[string]string{{"role": "user", "content": prompt}},
+		}, &response)
+		if len(response.Choices) > 0 {
+			answer = response.Choices[0].Message.Content
+		}
+	}
+	if err != nil {
+		return "", err
+	}
+
+	answer = strings.TrimSpace(answer)
+	if answer == "" {
+		return "", errors.New("the model returned an empty answer")
+	}
+	return answer, nil
+}
+
+// post sends body as JSON to path and decodes the JSON response into response
+func (c *Client) post(ctx context.Context, path string, body, response any) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to encode LLM request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.opts.URL+path, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.opts.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.opts.APIKey)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach %s: %w", c.opts.URL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s returned %s: %s", c.opts.URL, resp.Status, strings.TrimSpace(string(detail)))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(response); err != nil {
+		return fmt.Errorf("invalid response from %s: %w", c.opts.URL, err)
+	}
+	return nil
+}
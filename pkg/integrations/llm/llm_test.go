@@ -0,0 +1,86 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestPrompt(t *testing.T) {
+	if got := Prompt("Title for: {{transcript}}", "notes"); got != "Title for: notes" {
+		t.Errorf("Expected the placeholder to be replaced, got %q", got)
+	}
+	if got := Prompt("Summarize.", "notes"); got != "Summarize.\n\nnotes" {
+		t.Errorf("Expected the transcript to be appended, got %q", got)
+	}
+	if got := Prompt("", "notes"); !strings.HasSuffix(got, "Transcript:\nnotes") {
+		t.Errorf("Expected the default prompt, got %q", got)
+	}
+}
+
+func TestOllama(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			Model  string `json:"model"`
+			Prompt string `json:"prompt"`
+			Stream bool   `json:"stream"`
+		}
+		json.NewDecoder(r.Body).Decode(&request)
+		if r.URL.Path != "/api/generate" || request.Model != "llama3.2" || request.Stream || request.Prompt != "Sum: hi" {
+			t.Errorf("Unexpected request to %s: %+v", r.URL.Path, request)
+		}
+		json.NewEncoder(w).Encode(map[string]any{"response": " A greeting. "})
+	}))
+	defer server.Close()
+
+	client, err := NewClient(Options{Provider: ProviderOllama, URL: server.URL + "/", Model: "llama3.2"})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	summary, err := client.Summarize(context.Background(), "Sum: {{transcript}}", "hi")
+	if err != nil || summary != "A greeting." {
+		t.Errorf("Expected the model's answer, got %q (%v)", summary, err)
+	}
+}
+
+func TestOpenAI(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/completions" || r.Header.Get("Authorization") != "Bearer secret" {
+			t.Errorf("Unexpected request to %s with %q", r.URL.Path, r.Header.Get("Authorization"))
+		}
+		w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"- Call Sam"}}]}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(Options{Provider: ProviderOpenAI, URL: server.URL, Model: "gpt-4o-mini", APIKey: "secret"})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	if answer, err := client.Complete(context.Background(), "prompt"); err != nil || answer != "- Call Sam" {
+		t.Errorf("Expected the model's answer, got %q (%v)", answer, err)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	if _, err := NewClient(Options{Provider: "other", Model: "m"}); err == nil {
+		t.Error("Expected an error for an unknown provider")
+	}
+	if _, err := NewClient(Options{Provider: ProviderOpenAI, URL: "localhost", Model: "m"}); err == nil {
+		t.Error("Expected an error for a URL without a scheme")
+	}
+	if _, err := NewClient(Options{Provider: ProviderOllama}); err == nil {
+		t.Error("Expected an error without a model")
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "model not found", http.StatusNotFound)
+	}))
+	defer server.Close()
+	client, _ := NewClient(Options{Provider: ProviderOllama, URL: server.URL, Model: "missing"})
+	if _, err := client.Complete(context.Background(), "prompt"); err == nil || !strings.Contains(err.Error(), "model not found") {
+		t.Errorf("Expected the server's error, got %v", err)
+	}
+}
@@ -0,0 +1,430 @@
+// Package mqtt publishes transcript events to an MQTT broker so home
+// automation such as Home Assistant or Node-RED can react to speech.
+//
+// Only what publishing needs is implemented: MQTT 3.1.1 CONNECT, QoS 0
+// PUBLISH and keep-alive pings over plain TCP.
+package mqtt
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+// dialTimeout bounds connecting to the broker and waiting for it to accept
+const dialTimeout = 5 * time.Second
+
+// writeTimeout bounds sending a single packet
+const writeTimeout = 5 * time.Second
+
+// reconnectDelay is how long to wait after a failed connection before trying again
+const reconnectDelay = 10 * time.Second
+
+// defaultKeepAlive is how often the broker must hear from the client. A
+// broker that hasn't answered for one and a half intervals is given up on.
+const defaultKeepAlive = 60 * time.Second
+
+// queueSize is how many events may wait to be published before new ones are dropped
+const queueSize = 64
+
+// errWaitingToReconnect is returned while a failed connection isn't retried yet
+var errWaitingToReconnect = errors.New("waiting to reconnect to MQTT broker")
+
+// Packet types used by the client
+const (
+	packetConnect    = 0x10
+	packetConnack    = 0x20
+	packetPublish    = 0x30
+	packetPingreq    = 0xC0
+	packetDisconnect = 0xE0
+)
+
+// Options configures the connection to the broker
+type Options struct {
+	Broker      string // host:port, optionally prefixed with tcp:// or mqtt://
+	ClientID    string
+	Username    string
+	Password    string
+	TopicPrefix string        // Events are published to <prefix>/partial and <prefix>/final
+	KeepAlive   time.Duration // How often the connection is checked; defaults to 60s
+
+	// OnError is called from the background publisher when an event can't be
+	// published. Failures while waiting to reconnect are not reported again.
+	OnError func(error)
+}
+
+// Event is the JSON payload of a published transcript event
+type Event struct {
+	Type      string    `json:"type"` // "partial" or "final"
+	Text      string    `json:"text"`
+	SessionID string    `json:"session_id,omitempty"`
+	SegmentID int       `json:"segment_id,omitempty"`
+	Time      time.Time `json:"time"`
+}
+
+// Client publishes events to a broker in the background, connecting when the
+// first event is published and reconnecting after the connection is lost
+type Client struct {
+	opts     Options
+	conn     net.Conn
+	lastSent time.Time // When a packet was last written to conn
+	retryAt  time.Time // No connection attempts before this time
+	mu       sync.Mutex
+
+	queue   chan Event
+	done    chan struct{}
+	closed  bool
+	queueMu sync.Mutex
+
+	stopPing chan struct{} // Closed to stop the keep-alive pings
+}
+
+// NewClient creates a client for the broker in opts
+func NewClient(opts Options) (*Client, error) {
+	opts.Broker = strings.TrimPrefix(strings.TrimPrefix(opts.Broker, "tcp://"), "mqtt://")
+	if opts.Broker == "" {
+		return nil, errors.New("no MQTT broker configured")
+	}
+	if _, _, err := net.SplitHostPort(opts.Broker); err != nil {
+		opts.Broker = net.JoinHostPort(opts.Broker, "1883")
+	}
+	if opts.ClientID == "" {
+		opts.ClientID = "ramble"
+	}
+	opts.TopicPrefix = strings.TrimSuffix(opts.TopicPrefix, "/")
+	if opts.TopicPrefix == "" {
+		opts.TopicPrefix = "ramble"
+	}
+	if opts.KeepAlive <= 0 {
+		opts.KeepAlive = defaultKeepAlive
+	}
+
+	c := &Client{
+		opts:     opts,
+		queue:    make(chan Event, queueSize),
+		done:     make(chan struct{}),
+		stopPing: make(chan struct{}),
+	}
+	go c.run()
+	go c.keepAlive()
+	return c, nil
+}
+
+// PublishPartial queues streaming text that may still change
+func (c *Client) PublishPartial(text string) {
+	c.enqueue(Event{Type: "partial", Text: text, Time: time.Now()})
+}
+
+// PublishFinal queues a finalized segment
+func (c *Client) PublishFinal(sessionID string, segmentID int, text string) {
+	c.enqueue(Event{Type: "final", Text: text, SessionID: sessionID, SegmentID: segmentID, Time: time.Now()})
+}
+
+// enqueue hands an event to the background publisher without blocking the caller
+func (c *Client) enqueue(event Event) {
+	c.queueMu.Lock()
+	defer c.queueMu.Unlock()
+
+	if c.closed {
+		return
+	}
+	select {
+	case c.queue <- event:
+	default:
+		c.reportError(fmt.Errorf("MQTT queue full, dropped %s event", event.Type))
+	}
+}
+
+// run publishes queued events in order until the client is closed
+func (c *Client) run() {
+	defer close(c.done)
+	for event := range c.queue {
+		if err := c.publishEvent(event); err != nil && !errors.Is(err, errWaitingToReconnect) {
+			c.reportError(err)
+		}
+	}
+}
+
+// reportError passes a publishing error to the OnError callback
+func (c *Client) reportError(err error) {
+	if c.opts.OnError != nil {
+		c.opts.OnError(err)
+	}
+}
+
+// publishEvent encodes an event and publishes it to the topic for its type
+func (c *Client) publishEvent(event Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode MQTT event: %w", err)
+	}
+	return c.Publish(c.opts.TopicPrefix+"/"+event.Type, payload)
+}
+
+// Publish sends a message with QoS 0. If the connection was lost it is
+// re-established once before giving up.
+func (c *Client) Publish(topic string, payload []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	packet := encodePublish(topic, payload)
+	for attempt := 1; ; attempt++ {
+		if c.conn == nil {
+			if time.Now().Before(c.retryAt) {
+				return errWaitingToReconnect
+			}
+			if err := c.connect(); err != nil {
+				c.retryAt = time.Now().Add(reconnectDelay)
+				return err
+			}
+		}
+		c.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+		_, err := c.conn.Write(packet)
+		if err == nil {
+			c.lastSent = time.Now()
+			return nil
+		}
+		c.closeConn()
+		if attempt == 2 {
+			return fmt.Errorf("failed to publish to MQTT broker: %w", err)
+		}
+	}
+}
+
+// Close publishes the events still queued and disconnects from the broker
+func (c *Client) Close() error {
+	c.queueMu.Lock()
+	if !c.closed {
+		c.closed = true
+		close(c.queue)
+		close(c.stopPing)
+	}
+	c.queueMu.Unlock()
+	<-c.done
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.conn == nil {
+		return nil
+	}
+	c.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+	c.conn.Write([]byte{packetDisconnect, 0})
+	return c.closeConn()
+}
+
+// connect opens the connection and waits for the broker to accept it.
+// The caller must hold c.mu.
+func (c *Client) connect() error {
+	conn, err := net.DialTimeout("tcp", c.opts.Broker, dialTimeout)
+	if err != nil {
+		return fmt.Errorf("failed to connect to MQTT broker: %w", err)
+	}
+	conn.SetDeadline(time.Now().Add(dialTimeout))
+
+	if _, err := conn.Write(encodeConnect(c.opts)); err != nil {
+		conn.Close()
+		return fmt.Errorf("failed to connect to MQTT broker: %w", err)
+	}
+
+	var ack [4]byte
+	if _, err := io.ReadFull(conn, ack[:]); err != nil {
+		conn.Close()
+		return fmt.Errorf("no answer from MQTT broker: %w", err)
+	}
+	if ack[0] != packetConnack || ack[1] != 2 {
+		conn.Close()
+		return fmt.Errorf("unexpected answer from MQTT broker")
+	}
+	if ack[3] != 0 {
+		conn.Close()
+		return fmt.Errorf("MQTT broker refused the connection: %s", connackReason(ack[3]))
+	}
+
+	// Publishing never waits for answers; the broker's only replies are to
+	// keep-alive pings, which readLoop watches for
+	conn.SetDeadline(time.Time{})
+	c.conn = conn
+	c.lastSent = time.Now()
+	go c.readLoop(conn)
+	return nil
+}
+
+// keepAlive pings the broker when nothing else has been sent for half the
+// keep-alive interval, so it doesn't drop an idle connection
+func (c *Client) keepAlive() {
+	ticker := time.NewTicker(c.opts.KeepAlive / 4)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.stopPing:
+			return
+		case <-ticker.C:
+			c.ping()
+		}
+	}
+}
+
+// ping sends a PINGREQ if the connection has been idle
+func (c *Client) ping() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.conn == nil || time.Since(c.lastSent) < c.opts.KeepAlive/2 {
+		return
+	}
+	c.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+	if _, err := c.conn.Write([]byte{packetPingreq, 0}); err != nil {
+		c.closeConn()
+		return
+	}
+	c.lastSent = time.Now()
+}
+
+// readLoop reads what the broker sends on conn until it fails. A broker
+// that sends nothing, not even ping responses, for one and a half keep-alive
+// intervals is treated as gone, so the next event reconnects instead of
+// being written to a dead connection.
+func (c *Client) readLoop(conn net.Conn) {
+	r := bufio.NewReader(conn)
+	for {
+		conn.SetReadDeadline(time.Now().Add(c.opts.KeepAlive * 3 / 2))
+		_, err := r.ReadByte()
+		if err == nil {
+			var length int
+			if length, err = readLength(r); err == nil {
+				_, err = r.Discard(length)
+			}
+		}
+		if err != nil {
+			c.connectionLost(conn, err)
+			return
+		}
+	}
+}
+
+// connectionLost drops conn after reading from it failed, unless the client
+// already closed or replaced it
+func (c *Client) connectionLost(conn net.Conn, err error) {
+	c.mu.Lock()
+	current := c.conn == conn
+	if current {
+		c.closeConn()
+	}
+	c.mu.Unlock()
+
+	if current {
+		c.reportError(fmt.Errorf("lost connection to MQTT broker: %w", err))
+	}
+}
+
+// closeConn drops the connection. The caller must hold c.mu.
+func (c *Client) closeConn() error {
+	if c.conn == nil {
+		return nil
+	}
+	err := c.conn.Close()
+	c.conn = nil
+	return err
+}
+
+// encodeConnect builds a CONNECT packet with a clean session
+func encodeConnect(opts Options) []byte {
+	var body bytes.Buffer
+	writeString(&body, "MQTT")
+	body.WriteByte(4) // Protocol level 3.1.1
+
+	flags := byte(0x02) // Clean session
+	if opts.Username != "" {
+		flags |= 0x80
+		if opts.Password != "" {
+			flags |= 0x40
+		}
+	}
+	body.WriteByte(flags)
+	binary.Write(&body, binary.BigEndian, uint16(opts.KeepAlive/time.Second))
+
+	writeString(&body, opts.ClientID)
+	if opts.Username != "" {
+		writeString(&body, opts.Username)
+		if opts.Password != "" {
+			writeString(&body, opts.Password)
+		}
+	}
+	return packet(packetConnect, body.Bytes())
+}
+
+// encodePublish builds a QoS 0 PUBLISH packet
+func encodePublish(topic string, payload []byte) []byte {
+	var body bytes.Buffer
+	writeString(&body, topic)
+	body.Write(payload)
+	return packet(packetPublish, body.Bytes())
+}
+
+// packet prefixes a packet body with its fixed header
+func packet(packetType byte, body []byte) []byte {
+	out := []byte{packetType}
+	// The remaining length uses 7 bits per byte, least significant first
+	length := len(body)
+	for {
+		b := byte(length % 128)
+		length /= 128
+		if length > 0 {
+			b |= 0x80
+		}
+		out = append(out, b)
+		if length == 0 {
+			break
+		}
+	}
+	return append(out, body...)
+}
+
+// readLength reads the remaining length of a packet's fixed header
+func readLength(r io.ByteReader) (int, error) {
+	length, multiplier := 0, 1
+	for i := 0; i < 4; i++ {
+		b, err := r.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		length += int(b&0x7F) * multiplier
+		if b&0x80 == 0 {
+			return length, nil
+		}
+		multiplier *= 128
+	}
+	return 0, errors.New("malformed packet length")
+}
+
+// writeString writes a length-prefixed UTF-8 string
+func writeString(buf *bytes.Buffer, s string) {
+	binary.Write(buf, binary.BigEndian, uint16(len(s)))
+	buf.WriteString(s)
+}
+
+// connackReason describes a CONNACK return code
+func connackReason(code byte) string {
+	switch code {
+	case 1:
+		return "unsupported protocol version"
+	case 2:
+		return "client ID rejected"
+	case 3:
+		return "server unavailable"
+	case 4:
+		return "bad user name or password"
+	case 5:
+		return "not authorized"
+	}
+	return fmt.Sprintf("return code %d", code)
+}
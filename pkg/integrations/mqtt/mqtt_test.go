@@ -0,0 +1,228 @@
+package mqtt
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// readPacket reads one packet and returns its type and body
+func readPacket(t *testing.T, r *bufio.Reader) (byte, []byte) {
+	t.Helper()
+	packetType, err := r.ReadByte()
+	if err != nil {
+		t.Fatalf("Failed to read packet: %v", err)
+	}
+	length, multiplier := 0, 1
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			t.Fatalf("Failed to read packet length: %v", err)
+		}
+		length += int(b&0x7F) * multiplier
+		multiplier *= 128
+		if b&0x80 == 0 {
+			break
+		}
+	}
+	body := make([]byte, length)
+	if _, err := io.ReadFull(r, body); err != nil {
+		t.Fatalf("Failed to read packet body: %v", err)
+	}
+	return packetType, body
+}
+
+func TestPublishFinal(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	type published struct {
+		connect []byte
+		topic   string
+		payload []byte
+	}
+	done := make(chan published, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+
+		var p published
+		_, p.connect = readPacket(t, r)
+		conn.Write([]byte{packetConnack, 2, 0, 0})
+
+		packetType, body := readPacket(t, r)
+		if packetType != packetPublish {
+			t.Errorf("Expected PUBLISH, got %#x", packetType)
+		}
+		topicLen := int(body[0])<<8 | int(body[1])
+		p.topic = string(body[2 : 2+topicLen])
+		p.payload = body[2+topicLen:]
+		done <- p
+	}()
+
+	client, err := NewClient(Options{
+		Broker:      "tcp://" + listener.Addr().String(),
+		ClientID:    "test",
+		Username:    "user",
+		Password:    "secret",
+		TopicPrefix: "home/ramble/",
+	})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	defer client.Close()
+
+	client.PublishFinal("s1", 2, "turn on the lights")
+	p := <-done
+
+	if !bytes.Contains(p.connect, []byte("MQTT")) || !bytes.HasSuffix(p.connect, []byte("\x00\x04test\x00\x04user\x00\x06secret")) {
+		t.Errorf("Unexpected CONNECT body %q", p.connect)
+	}
+	if p.topic != "home/ramble/final" {
+		t.Errorf("Unexpected topic %q", p.topic)
+	}
+	var event Event
+	if err := json.Unmarshal(p.payload, &event); err != nil {
+		t.Fatalf("Failed to decode payload: %v", err)
+	}
+	if event.Type != "final" || event.Text != "turn on the lights" || event.SessionID != "s1" || event.SegmentID != 2 {
+		t.Errorf("Unexpected event %+v", event)
+	}
+}
+
+func TestConnectRefused(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		readPacket(t, bufio.NewReader(conn))
+		conn.Write([]byte{packetConnack, 2, 0, 5})
+	}()
+
+	errs := make(chan error, 2)
+	client, _ := NewClient(Options{
+		Broker:  listener.Addr().String(),
+		OnError: func(err error) { errs <- err },
+	})
+	client.PublishPartial("hello")
+	client.PublishPartial("again") // Not retried right away, so not reported
+	client.Close()
+
+	if len(errs) != 1 {
+		t.Errorf("Expected one error when the broker refuses the connection, got %d", len(errs))
+	}
+}
+
+func TestKeepAliveNoticesLostConnection(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	published := make(chan byte, 1)
+	go func() {
+		// The first connection is pinged while idle, then dropped by the broker
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		r := bufio.NewReader(conn)
+		readPacket(t, r)
+		conn.Write([]byte{packetConnack, 2, 0, 0})
+		if packetType, _ := readPacket(t, r); packetType != packetPingreq {
+			t.Errorf("Expected PINGREQ on an idle connection, got %#x", packetType)
+		}
+		conn.Close()
+
+		// The next event reconnects
+		conn, err = listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r = bufio.NewReader(conn)
+		readPacket(t, r)
+		conn.Write([]byte{packetConnack, 2, 0, 0})
+		packetType, _ := readPacket(t, r)
+		published <- packetType
+	}()
+
+	errs := make(chan error, 4)
+	client, err := NewClient(Options{
+		Broker:    listener.Addr().String(),
+		KeepAlive: 200 * time.Millisecond,
+		OnError:   func(err error) { errs <- err },
+	})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	defer client.Close()
+
+	// Connect without publishing anything the broker would wait for
+	client.mu.Lock()
+	if err := client.connect(); err != nil {
+		t.Fatalf("connect failed: %v", err)
+	}
+	client.mu.Unlock()
+
+	select {
+	case err := <-errs:
+		if !strings.Contains(err.Error(), "lost connection") {
+			t.Errorf("Expected a lost connection error, got %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Dropped connection was not noticed")
+	}
+
+	client.PublishFinal("s1", 1, "still there?")
+	select {
+	case packetType := <-published:
+		if packetType != packetPublish {
+			t.Errorf("Expected PUBLISH after reconnecting, got %#x", packetType)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Client did not reconnect")
+	}
+}
+
+func TestPacketLength(t *testing.T) {
+	p := packet(packetPublish, make([]byte, 321))
+	if p[1] != 0xC1 || p[2] != 0x02 || len(p) != 3+321 {
+		t.Errorf("Unexpected fixed header % x", p[:3])
+	}
+}
+
+func TestNewClientDefaults(t *testing.T) {
+	client, err := NewClient(Options{Broker: "localhost"})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	if client.opts.Broker != "localhost:1883" || client.opts.TopicPrefix != "ramble" {
+		t.Errorf("Unexpected defaults %+v", client.opts)
+	}
+	client.Close()
+	if _, err := NewClient(Options{}); err == nil {
+		t.Error("Expected an error without a broker")
+	}
+}
@@ -0,0 +1,124 @@
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// SignatureHeader carries the HMAC-SHA256 of the request body when a secret is
+// configured, as "sha256=" followed by the hex digest
+const SignatureHeader = "X-Ramble-Signature"
+
+// maxAttempts is how often a segment is sent before giving up
+const maxAttempts = 3
+
+// retryDelay is the wait before the first retry; it doubles for each further attempt
+var retryDelay = time.Second
+
+// SegmentPayload is the JSON body posted for each finalized segment
+type SegmentPayload struct {
+	Event     string    `json:"event"` // Always "segment"
+	SessionID string    `json:"session_id"`
+	SegmentID int       `json:"segment_id"`
+	Text      string    `json:"text"`
+	StartMs   int64     `json:"start_ms,omitempty"` // Position in the recording, if known
+	EndMs     int64     `json:"end_ms,omitempty"`
+	Finalized time.Time `json:"finalized"`
+}
+
+// Notifier posts finalized segments to one or more webhooks
+type Notifier struct {
+	urls   []string
+	secret string
+}
+
+// NewNotifier creates a notifier for the given URLs. If secret is not empty,
+// every request is signed with it.
+func NewNotifier(urls []string, secret string) (*Notifier, error) {
+	for _, u := range urls {
+		if err := Validate(u); err != nil {
+			return nil, fmt.Errorf("%s: %w", u, err)
+		}
+	}
+	return &Notifier{urls: urls, secret: secret}, nil
+}
+
+// Enabled reports whether any webhook is configured
+func (n *Notifier) Enabled() bool {
+	return len(n.urls) > 0
+}
+
+// Notify posts the segment to every webhook, retrying failed requests, and
+// returns the errors of the webhooks that could not be reached
+func (n *Notifier) Notify(payload SegmentPayload) error {
+	payload.Event = "segment"
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook payload: %w", err)
+	}
+
+	var errs []error
+	for _, u := range n.urls {
+		if err := n.deliver(u, body); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", u, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// deliver posts body to one webhook, retrying network errors and server errors
+func (n *Notifier) deliver(webhookURL string, body []byte) error {
+	delay := retryDelay
+	var err error
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		var retry bool
+		retry, err = n.post(webhookURL, body)
+		if err == nil || !retry {
+			return err
+		}
+		if attempt < maxAttempts {
+			time.Sleep(delay)
+			delay *= 2
+		}
+	}
+	return err
+}
+
+// post sends one request and reports whether a failure is worth retrying
+func (n *Notifier) post(webhookURL string, body []byte) (bool, error) {
+	req, err := http.NewRequest(http.MethodPost, webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return false, fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if n.secret != "" {
+		req.Header.Set(SignatureHeader, Sign(n.secret, body))
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return true, fmt.Errorf("failed to send to webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		// Client errors won't go away by sending the same request again
+		retry := resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests
+		return retry, fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return false, nil
+}
+
+// Sign returns the value of the signature header for body
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,58 @@
+// Package webhook sends transcript text to user-configured HTTP endpoints
+package webhook
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// sendTimeout bounds how long a webhook request may take
+const sendTimeout = 10 * time.Second
+
+// Payload is the JSON body posted to the webhook
+type Payload struct {
+	Text string    `json:"text"`
+	Sent time.Time `json:"sent"`
+}
+
+// client is shared by all requests
+var client = &http.Client{Timeout: sendTimeout}
+
+// Validate checks that a webhook URL is an absolute http or https URL
+func Validate(webhookURL string) error {
+	u, err := url.Parse(webhookURL)
+	if err != nil {
+		return fmt.Errorf("invalid webhook URL: %w", err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("webhook URL must start with http:// or https://")
+	}
+	return nil
+}
+
+// Send posts text to the webhook as JSON and fails unless it answers with a 2xx status
+func Send(webhookURL, text string) error {
+	if err := Validate(webhookURL); err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(Payload{Text: text, Sent: time.Now()})
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook payload: %w", err)
+	}
+
+	resp, err := client.Post(webhookURL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to send to webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package webhook
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestSend(t *testing.T) {
+	var received Payload
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("Expected JSON content type, got %q", r.Header.Get("Content-Type"))
+		}
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			t.Errorf("Failed to decode payload: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	if err := Send(server.URL, "follow up with Sam"); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	if received.Text != "follow up with Sam" || received.Sent.IsZero() {
+		t.Errorf("Unexpected payload: %+v", received)
+	}
+}
+
+func TestSendErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "nope", http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	if err := Send(server.URL, "text"); err == nil {
+		t.Error("Expected an error for a 403 response")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	for _, u := range []string{"", "example.com/hook", "ftp://example.com", "https://"} {
+		if err := Validate(u); err == nil {
+			t.Errorf("Expected %q to be rejected", u)
+		}
+	}
+	if err := Validate("https://example.com/hook"); err != nil {
+		t.Errorf("Expected valid URL, got %v", err)
+	}
+}
+
+func TestNotifierSignsAndRetries(t *testing.T) {
+	retryDelay = time.Millisecond
+	var attempts int
+	var received SegmentPayload
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts == 1 {
+			http.Error(w, "busy", http.StatusServiceUnavailable)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		if got := r.Header.Get(SignatureHeader); got != Sign("secret", body) {
+			t.Errorf("Unexpected signature %q", got)
+		}
+		if err := json.Unmarshal(body, &received); err != nil {
+			t.Errorf("Failed to decode payload: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	notifier, err := NewNotifier([]string{server.URL}, "secret")
+	if err != nil {
+		t.Fatalf("NewNotifier failed: %v", err)
+	}
+	if err := notifier.Notify(SegmentPayload{SessionID: "s1", SegmentID: 3, Text: "hello"}); err != nil {
+		t.Fatalf("Notify failed: %v", err)
+	}
+
+	if attempts != 2 {
+		t.Errorf("Expected 2 attempts, got %d", attempts)
+	}
+	if received.Event != "segment" || received.SessionID != "s1" || received.SegmentID != 3 || received.Text != "hello" {
+		t.Errorf("Unexpected payload: %+v", received)
+	}
+}
+
+func TestNotifierDoesNotRetryClientErrors(t *testing.T) {
+	retryDelay = time.Millisecond
+	var attempts int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		http.Error(w, "gone", http.StatusGone)
+	}))
+	defer server.Close()
+
+	notifier, err := NewNotifier([]string{server.URL}, "")
+	if err != nil {
+		t.Fatalf("NewNotifier failed: %v", err)
+	}
+	if err := notifier.Notify(SegmentPayload{Text: "hello"}); err == nil {
+		t.Error("Expected an error for a 410 response")
+	}
+	if attempts != 1 {
+		t.Errorf("Expected 1 attempt, got %d", attempts)
+	}
+}
+
+func TestNewNotifierRejectsInvalidURLs(t *testing.T) {
+	if _, err := NewNotifier([]string{"https://example.com/a", "example.com/b"}, ""); err == nil {
+		t.Error("Expected an error for a URL without scheme")
+	}
+}
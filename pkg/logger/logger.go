@@ -1,6 +1,7 @@
 package logger
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"log"
@@ -42,6 +43,9 @@ const (
 	CategorySystem Category = "SYSTEM"
 )
 
+// Categories lists every category, in the order settings show them
+var Categories = []Category{CategoryAudio, CategoryTranscription, CategoryUI, CategoryApp, CategorySystem}
+
 var (
 	// Default log level
 	currentLevel LogLevel = LevelInfo
@@ -49,9 +53,19 @@ var (
 	// Protect level changes
 	mu sync.Mutex
 
+	// Levels overriding currentLevel for single categories
+	categoryLevels = make(map[Category]LogLevel)
+
 	// Default output is stderr
 	output io.Writer = os.Stderr
 
+	// Optional second destination, such as a RotatingFile, which always
+	// receives lines without colors
+	fileOutput io.Writer
+
+	// Write JSON objects instead of text lines
+	jsonOutput bool
+
 	// Color support
 	useColors = true
 
@@ -59,8 +73,40 @@ var (
 	lastError     string
 	errorCount    int
 	suppressLines bool
+
+	// Most recent log entries, kept for diagnostics such as crash reports
+	// and the log viewer
+	recentMu    sync.Mutex
+	recent      = make([]Entry, maxRecentEntries)
+	recentStart int
+	recentCount int
+
+	// Functions called with every entry, by subscription
+	listeners    = make(map[int]func(Entry))
+	nextListener int
+)
+
+// maxRecentEntries is the number of log entries kept in memory for
+// RecentEntries, and maxRecentLines how many of them Recent returns
+const (
+	maxRecentEntries = 1000
+	maxRecentLines   = 200
 )
 
+// Entry is a single logged message
+type Entry struct {
+	Time     time.Time
+	Level    LogLevel
+	Category Category
+	Message  string
+}
+
+// String formats the entry as a log line without colors
+func (e Entry) String() string {
+	return fmt.Sprintf("%s [%s] [%s] %s",
+		e.Time.Format("2006/01/02 15:04:05"), levelName(e.Level), e.Category, e.Message)
+}
+
 // Colors for different log levels (ANSI escape codes)
 var (
 	colorReset  = "\033[0m"
@@ -80,6 +126,54 @@ func SetLevel(level LogLevel) {
 	currentLevel = level
 }
 
+// SetCategoryLevel overrides the logging level for one category
+func SetCategoryLevel(category Category, level LogLevel) {
+	mu.Lock()
+	defer mu.Unlock()
+	categoryLevels[category] = level
+}
+
+// ClearCategoryLevels removes every category override so all categories use
+// the level set with SetLevel
+func ClearCategoryLevels() {
+	mu.Lock()
+	defer mu.Unlock()
+	clear(categoryLevels)
+}
+
+// SetJSON switches between text lines and one JSON object per line, which
+// log collectors can parse without guessing at the format
+func SetJSON(enable bool) {
+	mu.Lock()
+	defer mu.Unlock()
+	jsonOutput = enable
+}
+
+// SetFileOutput also writes logs to w, or stops if w is nil
+func SetFileOutput(w io.Writer) {
+	mu.Lock()
+	defer mu.Unlock()
+	fileOutput = w
+}
+
+// ParseLevel returns the level with the given name, as used in settings:
+// "debug", "info", "warn", "error" or "silent"
+func ParseLevel(name string) (LogLevel, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "debug":
+		return LevelDebug, nil
+	case "info":
+		return LevelInfo, nil
+	case "warn", "warning":
+		return LevelWarning, nil
+	case "error":
+		return LevelError, nil
+	case "silent", "off":
+		return LevelSilent, nil
+	}
+	return LevelInfo, fmt.Errorf("unknown log level %q", name)
+}
+
 // SetOutput changes where logs are written
 func SetOutput(w io.Writer) {
 	mu.Lock()
@@ -147,10 +241,14 @@ func formatLog(level LogLevel, category Category, message string) string {
 		timestamp, levelStr, category, message)
 }
 
-// shouldLog determines if a message should be logged based on current level
-func shouldLog(level LogLevel) bool {
+// shouldLog determines if a message should be logged based on the level of
+// its category
+func shouldLog(level LogLevel, category Category) bool {
 	mu.Lock()
 	defer mu.Unlock()
+	if categoryLevel, ok := categoryLevels[category]; ok {
+		return level >= categoryLevel
+	}
 	return level >= currentLevel
 }
 
@@ -175,39 +273,158 @@ func isSupressedALSALine(message string) bool {
 	return false
 }
 
+// jsonLine is a log line in JSON output mode
+type jsonLine struct {
+	Time     time.Time `json:"time"`
+	Level    string    `json:"level"`
+	Category Category  `json:"category"`
+	Message  string    `json:"message"`
+}
+
+// formatJSON formats a log line as a JSON object
+func formatJSON(now time.Time, level LogLevel, category Category, message string) string {
+	data, err := json.Marshal(jsonLine{Time: now, Level: levelName(level), Category: category, Message: message})
+	if err != nil {
+		return message
+	}
+	return string(data)
+}
+
+// emit writes a log line, remembers it for Recent and passes it to listeners
+func emit(level LogLevel, category Category, message string) {
+	now := time.Now()
+	entry := Entry{Time: now, Level: level, Category: category, Message: message}
+	line := entry.String()
+
+	mu.Lock()
+	asJSON, file := jsonOutput, fileOutput
+	mu.Unlock()
+
+	written := line
+	if asJSON {
+		written = formatJSON(now, level, category, message)
+		log.Println(written)
+	} else {
+		log.Println(formatLog(level, category, message))
+	}
+	if file != nil {
+		fmt.Fprintln(file, written)
+	}
+
+	// Remember the entry and pass it on; listeners are called without the
+	// lock so they may log themselves
+	recentMu.Lock()
+	if recentCount < maxRecentEntries {
+		recent[(recentStart+recentCount)%maxRecentEntries] = entry
+		recentCount++
+	} else {
+		recent[recentStart] = entry
+		recentStart = (recentStart + 1) % maxRecentEntries
+	}
+	called := make([]func(Entry), 0, len(listeners))
+	for _, listener := range listeners {
+		called = append(called, listener)
+	}
+	recentMu.Unlock()
+
+	for _, listener := range called {
+		listener(entry)
+	}
+}
+
+// Recent returns the most recently logged lines, oldest first
+func Recent() []string {
+	entries := RecentEntries()
+	if len(entries) > maxRecentLines {
+		entries = entries[len(entries)-maxRecentLines:]
+	}
+	lines := make([]string, len(entries))
+	for i, entry := range entries {
+		lines[i] = entry.String()
+	}
+	return lines
+}
+
+// RecentEntries returns the most recently logged entries, oldest first
+func RecentEntries() []Entry {
+	recentMu.Lock()
+	defer recentMu.Unlock()
+
+	entries := make([]Entry, recentCount)
+	for i := range entries {
+		entries[i] = recent[(recentStart+i)%maxRecentEntries]
+	}
+	return entries
+}
+
+// Subscribe calls listener with every entry logged from now on, from the
+// goroutine that logged it, until the returned function is called
+func Subscribe(listener func(Entry)) (unsubscribe func()) {
+	recentMu.Lock()
+	defer recentMu.Unlock()
+
+	id := nextListener
+	nextListener++
+	listeners[id] = listener
+	return func() {
+		recentMu.Lock()
+		defer recentMu.Unlock()
+		delete(listeners, id)
+	}
+}
+
+// LevelName returns the label used for a level in log lines, e.g. "WARN"
+func LevelName(level LogLevel) string {
+	return levelName(level)
+}
+
+// levelName returns the label used for a level in log lines
+func levelName(level LogLevel) string {
+	switch level {
+	case LevelDebug:
+		return "DEBUG"
+	case LevelWarning:
+		return "WARN"
+	case LevelError:
+		return "ERROR"
+	default:
+		return "INFO"
+	}
+}
+
 // Debug logs at debug level
 func Debug(category Category, format string, args ...interface{}) {
-	if shouldLog(LevelDebug) {
+	if shouldLog(LevelDebug, category) {
 		message := fmt.Sprintf(format, args...)
 		if !isSupressedALSALine(message) {
-			log.Println(formatLog(LevelDebug, category, message))
+			emit(LevelDebug, category, message)
 		}
 	}
 }
 
 // Info logs at info level
 func Info(category Category, format string, args ...interface{}) {
-	if shouldLog(LevelInfo) {
+	if shouldLog(LevelInfo, category) {
 		message := fmt.Sprintf(format, args...)
 		if !isSupressedALSALine(message) {
-			log.Println(formatLog(LevelInfo, category, message))
+			emit(LevelInfo, category, message)
 		}
 	}
 }
 
 // Warning logs at warning level
 func Warning(category Category, format string, args ...interface{}) {
-	if shouldLog(LevelWarning) {
+	if shouldLog(LevelWarning, category) {
 		message := fmt.Sprintf(format, args...)
 		if !isSupressedALSALine(message) {
-			log.Println(formatLog(LevelWarning, category, message))
+			emit(LevelWarning, category, message)
 		}
 	}
 }
 
 // Error logs at error level
 func Error(category Category, format string, args ...interface{}) {
-	if shouldLog(LevelError) {
+	if shouldLog(LevelError, category) {
 		message := fmt.Sprintf(format, args...)
 
 		// Detect repeated errors
@@ -223,7 +440,7 @@ func Error(category Category, format string, args ...interface{}) {
 			errorCount = 1
 		}
 
-		log.Println(formatLog(LevelError, category, message))
+		emit(LevelError, category, message)
 	}
 }
 
@@ -251,10 +468,10 @@ type logWriter struct {
 
 // Write implements io.Writer
 func (w *logWriter) Write(p []byte) (n int, err error) {
-	if shouldLog(w.level) {
+	if shouldLog(w.level, w.category) {
 		message := strings.TrimSpace(string(p))
 		if !isSupressedALSALine(message) {
-			log.Println(formatLog(w.level, w.category, message))
+			emit(w.level, w.category, message)
 		}
 	}
 	return len(p), nil
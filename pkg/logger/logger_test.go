@@ -0,0 +1,115 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCategoryLevels(t *testing.T) {
+	var buf bytes.Buffer
+	SetFileOutput(&buf)
+	SetLevel(LevelWarning)
+	SetCategoryLevel(CategoryAudio, LevelDebug)
+	defer func() {
+		SetFileOutput(nil)
+		SetLevel(LevelInfo)
+		ClearCategoryLevels()
+	}()
+
+	Debug(CategoryAudio, "audio detail")
+	Debug(CategoryUI, "ui detail")
+
+	if !strings.Contains(buf.String(), "audio detail") {
+		t.Errorf("Expected the overridden category to log debug lines, got %q", buf.String())
+	}
+	if strings.Contains(buf.String(), "ui detail") {
+		t.Errorf("Expected other categories to use the overall level, got %q", buf.String())
+	}
+}
+
+func TestJSONOutput(t *testing.T) {
+	var buf bytes.Buffer
+	SetFileOutput(&buf)
+	SetJSON(true)
+	defer func() {
+		SetFileOutput(nil)
+		SetJSON(false)
+	}()
+
+	Warning(CategoryTranscription, "stream %d stalled", 2)
+
+	var line jsonLine
+	if err := json.Unmarshal(buf.Bytes(), &line); err != nil {
+		t.Fatalf("Expected a JSON line, got %q: %v", buf.String(), err)
+	}
+	if line.Level != "WARN" || line.Category != CategoryTranscription || line.Message != "stream 2 stalled" {
+		t.Errorf("Unexpected JSON line: %+v", line)
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	for name, want := range map[string]LogLevel{
+		"debug": LevelDebug, "INFO": LevelInfo, "warning": LevelWarning, "error": LevelError, "off": LevelSilent,
+	} {
+		if level, err := ParseLevel(name); err != nil || level != want {
+			t.Errorf("ParseLevel(%q) = %v, %v; want %v", name, level, err, want)
+		}
+	}
+	if _, err := ParseLevel("loud"); err == nil {
+		t.Error("Expected an error for an unknown level")
+	}
+}
+
+func TestRotatingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ramble.log")
+	file, err := NewRotatingFile(path, 10, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	for _, line := range []string{"first\n", "second\n", "third\n", "fourth\n"} {
+		if _, err := file.Write([]byte(line)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for suffix, want := range map[string]string{"": "fourth\n", ".1": "third\n", ".2": "second\n"} {
+		data, err := os.ReadFile(path + suffix)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != want {
+			t.Errorf("Expected %s%s to contain %q, got %q", filepath.Base(path), suffix, want, data)
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Errorf("Expected only two rotated files to be kept")
+	}
+}
+
+func TestSubscribe(t *testing.T) {
+	var got []Entry
+	unsubscribe := Subscribe(func(entry Entry) {
+		got = append(got, entry)
+	})
+
+	Warning(CategoryUI, "seen %d", 1)
+	unsubscribe()
+	Warning(CategoryUI, "not seen")
+
+	if len(got) != 1 || got[0].Level != LevelWarning || got[0].Message != "seen 1" {
+		t.Errorf("Expected one warning entry, got %+v", got)
+	}
+	entries := RecentEntries()
+	if last := entries[len(entries)-1]; last.Message != "not seen" {
+		t.Errorf("Expected entries to be kept after unsubscribing, last was %q", last.Message)
+	}
+	if lines := Recent(); !strings.HasSuffix(lines[len(lines)-1], "[WARN] [UI] not seen") {
+		t.Errorf("Unexpected recent line %q", lines[len(lines)-1])
+	}
+}
@@ -0,0 +1,60 @@
+package metrics
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRegistryWrite(t *testing.T) {
+	r := NewRegistry()
+	chunks := r.Counter("ramble_chunks_total", "Audio chunks processed.")
+	rtf := r.Gauge("ramble_rtf", "Real-time factor.")
+	latency := r.Histogram("ramble_latency_seconds", "Pass latency.", []float64{1, 0.5})
+
+	chunks.Inc()
+	chunks.Add(2)
+	chunks.Add(-5) // Ignored, counters only go up
+	rtf.Set(0.25)
+	latency.Observe(0.2)
+	latency.Observe(0.7)
+	latency.Observe(3)
+
+	var out strings.Builder
+	if err := r.Write(&out); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	expected := `# HELP ramble_chunks_total Audio chunks processed.
+# TYPE ramble_chunks_total counter
+ramble_chunks_total 3
+# HELP ramble_rtf Real-time factor.
+# TYPE ramble_rtf gauge
+ramble_rtf 0.25
+# HELP ramble_latency_seconds Pass latency.
+# TYPE ramble_latency_seconds histogram
+ramble_latency_seconds_bucket{le="0.5"} 1
+ramble_latency_seconds_bucket{le="1"} 2
+ramble_latency_seconds_bucket{le="+Inf"} 3
+ramble_latency_seconds_sum 3.9
+ramble_latency_seconds_count 3
+`
+	if out.String() != expected {
+		t.Errorf("Unexpected output:\n%s\nExpected:\n%s", out.String(), expected)
+	}
+}
+
+func TestRegistryServeHTTP(t *testing.T) {
+	r := NewRegistry()
+	r.Counter("ramble_test_total", "A test counter.").Inc()
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain") {
+		t.Errorf("Expected a text content type, got %q", ct)
+	}
+	if !strings.Contains(rec.Body.String(), "ramble_test_total 1\n") {
+		t.Errorf("Expected the counter in the response, got:\n%s", rec.Body.String())
+	}
+}
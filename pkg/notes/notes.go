@@ -0,0 +1,98 @@
+// Package notes appends transcripts to Markdown notes, such as the daily
+// notes of an Obsidian vault
+package notes
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// DefaultTemplate adds each transcript under a heading with its time. It is
+// used when no template is configured.
+const DefaultTemplate = "## {{time}}\n\n{{text}}\n"
+
+// Entry is a transcript to add to a note
+type Entry struct {
+	Text      string
+	SessionID string
+	Time      time.Time
+}
+
+// placeholders returns the replacements for the placeholders in paths and templates
+func placeholders(entry Entry, text string) *strings.Replacer {
+	return strings.NewReplacer(
+		"{{date}}", entry.Time.Format("2006-01-02"),
+		"{{time}}", entry.Time.Format("15:04"),
+		"{{datetime}}", entry.Time.Format("2006-01-02 15:04"),
+		"{{session}}", entry.SessionID,
+		"{{text}}", text,
+	)
+}
+
+// NotePath returns the note file an entry is added to. The path may contain
+// {{date}} and other placeholders and may start with ~ for the home directory.
+func NotePath(pathTemplate string, entry Entry) (string, error) {
+	if strings.TrimSpace(pathTemplate) == "" {
+		return "", fmt.Errorf("no note file configured")
+	}
+
+	// The text can't be part of a file name
+	path := placeholders(entry, "").Replace(pathTemplate)
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to get user home directory: %w", err)
+		}
+		path = filepath.Join(home, path[1:])
+	}
+	if filepath.Ext(path) == "" {
+		path += ".md"
+	}
+	return path, nil
+}
+
+// Render fills in the template for an entry
+func Render(template string, entry Entry) string {
+	if template == "" {
+		template = DefaultTemplate
+	}
+	return placeholders(entry, strings.TrimSpace(entry.Text)).Replace(template)
+}
+
+// Append adds an entry to the end of its note, creating the note and its
+// folders if needed, and returns the note's path
+func Append(pathTemplate, template string, entry Entry) (string, error) {
+	path, err := NotePath(pathTemplate, entry)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", fmt.Errorf("failed to create note folder: %w", err)
+	}
+
+	// Keep a blank line between the existing note and the new entry
+	separator := ""
+	if existing, err := os.ReadFile(path); err == nil && len(existing) > 0 {
+		switch {
+		case strings.HasSuffix(string(existing), "\n\n"):
+		case strings.HasSuffix(string(existing), "\n"):
+			separator = "\n"
+		default:
+			separator = "\n\n"
+		}
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return "", fmt.Errorf("failed to open note: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := f.WriteString(separator + Render(template, entry)); err != nil {
+		return "", fmt.Errorf("failed to write note: %w", err)
+	}
+	return path, nil
+}
@@ -0,0 +1,62 @@
+package notes
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestAppendToDailyNote(t *testing.T) {
+	dir := t.TempDir()
+	entry := Entry{
+		Text:      " Call the plumber. ",
+		SessionID: "20250301-101500.000",
+		Time:      time.Date(2025, 3, 1, 10, 17, 0, 0, time.Local),
+	}
+
+	path, err := Append(filepath.Join(dir, "Daily", "{{date}}"), "", entry)
+	if err != nil {
+		t.Fatalf("Append failed: %v", err)
+	}
+	if path != filepath.Join(dir, "Daily", "2025-03-01.md") {
+		t.Errorf("Unexpected note path %s", path)
+	}
+
+	entry.Text = "Buy milk."
+	entry.Time = entry.Time.Add(time.Hour)
+	if _, err := Append(filepath.Join(dir, "Daily", "{{date}}"), "", entry); err != nil {
+		t.Fatalf("Append failed: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read note: %v", err)
+	}
+	expected := "## 10:17\n\nCall the plumber.\n\n## 11:17\n\nBuy milk.\n"
+	if string(data) != expected {
+		t.Errorf("Unexpected note:\n%q", data)
+	}
+}
+
+func TestAppendSeparatesFromExistingText(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "Inbox.md")
+	if err := os.WriteFile(path, []byte("# Inbox"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Append(path, "- {{text}} ({{session}})\n", Entry{Text: "idea", SessionID: "s1"}); err != nil {
+		t.Fatalf("Append failed: %v", err)
+	}
+
+	data, _ := os.ReadFile(path)
+	if string(data) != "# Inbox\n\n- idea (s1)\n" {
+		t.Errorf("Unexpected note:\n%q", data)
+	}
+}
+
+func TestNotePathRequiresPath(t *testing.T) {
+	if _, err := NotePath(" ", Entry{}); err == nil {
+		t.Error("Expected an error for an empty path")
+	}
+}
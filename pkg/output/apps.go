@@ -0,0 +1,170 @@
+package output
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// Actions of an app rule, replacing what the outputs do while the
+// application it matches has focus
+const (
+	AppType      = "type"      // Type at the cursor, even if typing is off, but don't copy
+	AppClipboard = "clipboard" // Copy to the clipboard, even if copying is off, but don't type
+	AppOff       = "off"       // Send the text to no output at all
+)
+
+// AppActions lists every action in the order they are offered to the user
+var AppActions = []string{AppType, AppClipboard, AppOff}
+
+// appKinds are the sinks acting on the focused application, by the action
+// of the rule choosing each
+var appKinds = map[string]Kind{AppType: KindType, AppClipboard: KindClipboard}
+
+// focused reports whether a sink of this kind acts on the focused application
+func (k Kind) focused() bool {
+	return k == KindType || k == KindClipboard
+}
+
+// AppRule changes what happens to finalized text while an application has
+// focus, e.g. typing it into editors but never into a password manager
+type AppRule struct {
+	Match  string // Part of the window class or title, ignoring case
+	Action string // AppType, AppClipboard or AppOff
+}
+
+// Window describes the window that has focus
+type Window struct {
+	Class string // Application, e.g. "firefox", or its process name on Windows
+	Title string
+}
+
+// MatchApp returns the first rule matching window
+func MatchApp(rules []AppRule, window Window) (AppRule, bool) {
+	class, title := strings.ToLower(window.Class), strings.ToLower(window.Title)
+	for _, rule := range rules {
+		match := strings.ToLower(strings.TrimSpace(rule.Match))
+		if match != "" && (strings.Contains(class, match) || strings.Contains(title, match)) {
+			return rule, true
+		}
+	}
+	return AppRule{}, false
+}
+
+// FocusedWindow returns the window that has focus. It uses xprop on X11,
+// System Events on macOS and user32 through PowerShell on Windows. Wayland
+// doesn't let applications see other windows, so only XWayland windows are
+// found there.
+func FocusedWindow() (Window, error) {
+	switch runtime.GOOS {
+	case "darwin", "windows":
+		name, args := focusedWindowScript(runtime.GOOS)
+		out, err := exec.Command(name, args...).Output()
+		if err != nil {
+			return Window{}, fmt.Errorf("%s failed: %w", name, err)
+		}
+		class, title, _ := strings.Cut(strings.TrimSpace(string(out)), "\n")
+		return Window{Class: strings.TrimSpace(class), Title: strings.TrimSpace(title)}, nil
+	}
+
+	if os.Getenv("DISPLAY") == "" {
+		return Window{}, errors.New("finding the focused application needs an X11 display")
+	}
+	if _, err := exec.LookPath("xprop"); err != nil {
+		return Window{}, errors.New("finding the focused application needs xprop to be installed")
+	}
+	out, err := exec.Command("xprop", "-root", "_NET_ACTIVE_WINDOW").Output()
+	if err != nil {
+		return Window{}, fmt.Errorf("xprop failed: %w", err)
+	}
+	id, err := activeWindowID(string(out))
+	if err != nil {
+		return Window{}, err
+	}
+	out, err = exec.Command("xprop", "-id", id, "WM_CLASS", "_NET_WM_NAME").Output()
+	if err != nil {
+		return Window{}, fmt.Errorf("xprop failed: %w", err)
+	}
+	return windowProperties(string(out)), nil
+}
+
+// focusedWindowScript returns the command printing the application and title
+// of the focused window on separate lines on macOS or Windows
+func focusedWindowScript(goos string) (string, []string) {
+	if goos == "darwin" {
+		return "osascript", []string{
+			"-e", `tell application "System Events"`,
+			"-e", `set p to first application process whose frontmost is true`,
+			"-e", `set t to ""`,
+			"-e", `try`,
+			"-e", `set t to name of front window of p`,
+			"-e", `end try`,
+			"-e", `return (name of p) & linefeed & t`,
+			"-e", `end tell`,
+		}
+	}
+	return "powershell", []string{"-NoProfile", "-Command", strings.Join([]string{
+		`$sig = '[DllImport("user32.dll")] public static extern IntPtr GetForegroundWindow();` +
+			` [DllImport("user32.dll", CharSet = CharSet.Unicode)] public static extern int GetWindowText(IntPtr h, System.Text.StringBuilder s, int n);` +
+			` [DllImport("user32.dll")] public static extern uint GetWindowThreadProcessId(IntPtr h, out uint p);'`,
+		`$w = Add-Type -MemberDefinition $sig -Name Focus -Namespace Ramble -PassThru`,
+		`$h = $w::GetForegroundWindow()`,
+		`$s = New-Object System.Text.StringBuilder 512`,
+		`[void]$w::GetWindowText($h, $s, 512)`,
+		`$id = 0`,
+		`[void]$w::GetWindowThreadProcessId($h, [ref]$id)`,
+		`(Get-Process -Id $id).ProcessName`,
+		`$s.ToString()`,
+	}, "; ")}
+}
+
+// activeWindowID returns the window id in xprop's output for the root
+// window's _NET_ACTIVE_WINDOW, e.g. "_NET_ACTIVE_WINDOW(WINDOW): window id # 0x3a00007"
+func activeWindowID(out string) (string, error) {
+	_, id, found := strings.Cut(out, "#")
+	id = strings.TrimSpace(id)
+	if !found || id == "" {
+		return "", fmt.Errorf("unexpected xprop output %q", strings.TrimSpace(out))
+	}
+	if id == "0x0" {
+		return "", errors.New("no window has focus")
+	}
+	return id, nil
+}
+
+// windowProperties reads the class and title from xprop's output for a
+// window's WM_CLASS and _NET_WM_NAME. The class is both names in WM_CLASS,
+// e.g. "Navigator firefox", so a rule can match either.
+func windowProperties(out string) Window {
+	var window Window
+	for _, line := range strings.Split(out, "\n") {
+		name, value, found := strings.Cut(line, " = ")
+		if !found {
+			continue
+		}
+		switch {
+		case strings.HasPrefix(name, "WM_CLASS"):
+			var names []string
+			for _, part := range strings.Split(value, ", ") {
+				names = append(names, unquote(part))
+			}
+			window.Class = strings.Join(names, " ")
+		case strings.HasPrefix(name, "_NET_WM_NAME"):
+			window.Title = unquote(value)
+		}
+	}
+	return window
+}
+
+// unquote removes the quotes xprop puts around a string value
+func unquote(value string) string {
+	value = strings.TrimSpace(value)
+	if unquoted, err := strconv.Unquote(value); err == nil {
+		return unquoted
+	}
+	return strings.Trim(value, `"`)
+}
@@ -0,0 +1,94 @@
+package output
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestMatchApp(t *testing.T) {
+	rules := []AppRule{
+		{Match: "KeePassXC", Action: AppOff},
+		{Match: "firefox", Action: AppClipboard},
+		{Match: " ", Action: AppType},
+	}
+
+	if rule, ok := MatchApp(rules, Window{Class: "keepassxc KeePassXC", Title: "Passwords.kdbx"}); !ok || rule.Action != AppOff {
+		t.Errorf("Expected the class to match ignoring case, got %+v", rule)
+	}
+	if rule, ok := MatchApp(rules, Window{Class: "Navigator", Title: "News - Mozilla Firefox"}); !ok || rule.Action != AppClipboard {
+		t.Errorf("Expected the title to match, got %+v", rule)
+	}
+	if _, ok := MatchApp(rules, Window{Class: "code", Title: "main.go"}); ok {
+		t.Error("Expected no rule to match, and an empty match to match nothing")
+	}
+}
+
+func TestRouterAppliesAppRules(t *testing.T) {
+	clipboard, typer, file := &recorder{}, &recorder{}, &recorder{}
+	router := &Router{}
+	router.Add(KindClipboard, clipboard, "", false)
+	router.Add(KindType, typer, "", false)
+	router.Add(KindFile, file, "", false)
+
+	window := Window{}
+	router.SetAppRules([]AppRule{
+		{Match: "editor", Action: AppType},
+		{Match: "browser", Action: AppClipboard},
+		{Match: "vault", Action: AppOff},
+	}, func() (Window, error) { return window, nil })
+
+	for _, step := range []struct {
+		class                    string
+		clipboard, typer, stored int
+	}{
+		{"terminal", 1, 1, 1},
+		{"editor", 1, 2, 2},
+		{"browser", 2, 2, 3},
+		{"vault", 2, 2, 3},
+	} {
+		window.Class = step.class
+		if err := router.Write(Event{Text: "hello"}); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+		if len(clipboard.texts) != step.clipboard || len(typer.texts) != step.typer || len(file.texts) != step.stored {
+			t.Errorf("%s: unexpected deliveries: clipboard %d, typing %d, file %d", step.class,
+				len(clipboard.texts), len(typer.texts), len(file.texts))
+		}
+	}
+
+	// A rule that types or copies is enough to deliver
+	typing := &Router{}
+	if typing.SetAppRules([]AppRule{{Match: "vault", Action: AppOff}}, nil); typing.Enabled() {
+		t.Error("Expected a router that only turns outputs off to be disabled")
+	}
+	if typing.SetAppRules([]AppRule{{Match: "editor", Action: AppType}}, nil); !typing.Enabled() {
+		t.Error("Expected a router with a typing rule to be enabled")
+	}
+
+	// Without the focused application, text is delivered as configured and
+	// the failure reported once
+	router.SetAppRules(router.apps, func() (Window, error) { return Window{}, errors.New("no display") })
+	if err := router.Write(Event{Text: "hello"}); err == nil {
+		t.Error("Expected the failure to be reported")
+	}
+	if err := router.Write(Event{Text: "hello"}); err != nil {
+		t.Errorf("Expected the failure to be reported once, got %v", err)
+	}
+	if len(file.texts) != 5 {
+		t.Errorf("Expected text to be delivered, got %d", len(file.texts))
+	}
+}
+
+func TestXpropOutput(t *testing.T) {
+	if id, err := activeWindowID("_NET_ACTIVE_WINDOW(WINDOW): window id # 0x3a00007\n"); err != nil || id != "0x3a00007" {
+		t.Errorf("Unexpected window id %q (%v)", id, err)
+	}
+	if _, err := activeWindowID("_NET_ACTIVE_WINDOW(WINDOW): window id # 0x0\n"); err == nil {
+		t.Error("Expected an error when no window has focus")
+	}
+
+	window := windowProperties("WM_CLASS(STRING) = \"Navigator\", \"firefox\"\n_NET_WM_NAME(UTF8_STRING) = \"Say \\\"hi\\\" - Mozilla Firefox\"\n")
+	if window.Class != "Navigator firefox" || window.Title != `Say "hi" - Mozilla Firefox` {
+		t.Errorf("Unexpected window %+v", window)
+	}
+}
@@ -0,0 +1,59 @@
+package output
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+)
+
+// Types of JSONEvent
+const (
+	EventPartial = "partial" // Streaming text that may still change
+	EventFinal   = "final"   // A finalized segment
+)
+
+// JSONEvent is a transcript event written by JSONLines
+type JSONEvent struct {
+	Type      string    `json:"type"` // EventPartial or EventFinal
+	Text      string    `json:"text"`
+	SessionID string    `json:"session_id,omitempty"`
+	SegmentID int       `json:"segment_id,omitempty"`
+	Language  string    `json:"language,omitempty"`
+	Time      time.Time `json:"time"`
+}
+
+// JSONLines writes transcript events to a stream as one JSON object per
+// line, so they can be piped into jq or a script. A nil JSONLines writes
+// nothing. It is safe for concurrent use.
+type JSONLines struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+// NewJSONLines creates a JSONLines writing to w
+func NewJSONLines(w io.Writer) *JSONLines {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	return &JSONLines{enc: enc}
+}
+
+// Partial writes streaming text
+func (j *JSONLines) Partial(text string) error {
+	return j.write(JSONEvent{Type: EventPartial, Text: text, Time: time.Now()})
+}
+
+// Final writes a finalized segment
+func (j *JSONLines) Final(sessionID string, segmentID int, text, language string) error {
+	return j.write(JSONEvent{Type: EventFinal, Text: text, SessionID: sessionID, SegmentID: segmentID, Language: language, Time: time.Now()})
+}
+
+// write encodes event as a line
+func (j *JSONLines) write(event JSONEvent) error {
+	if j == nil {
+		return nil
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.enc.Encode(event)
+}
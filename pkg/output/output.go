@@ -0,0 +1,226 @@
+// Package output delivers finalized transcripts to every destination the user
+// enabled, such as the clipboard, a file or the window that has focus
+package output
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Kind identifies a type of sink
+type Kind string
+
+const (
+	// KindClipboard copies text to the clipboard
+	KindClipboard Kind = "clipboard"
+	// KindFile appends text to a file
+	KindFile Kind = "file"
+	// KindType types text into the window that has focus
+	KindType Kind = "type"
+	// KindWebhook posts text to a webhook
+	KindWebhook Kind = "webhook"
+	// KindStdout prints text to standard output
+	KindStdout Kind = "stdout"
+	// KindPipe writes text to a named pipe
+	KindPipe Kind = "pipe"
+)
+
+// Kinds lists every kind of sink in the order they are offered to the user
+var Kinds = []Kind{KindClipboard, KindFile, KindType, KindWebhook, KindStdout, KindPipe}
+
+// DefaultFormat writes just the transcript. It is used when a sink has no format.
+const DefaultFormat = "{{text}}"
+
+// Event is a finalized transcript to deliver
+type Event struct {
+	Text      string
+	SessionID string
+	SegmentID int
+	Time      time.Time
+}
+
+// Sink is a destination for transcripts
+type Sink interface {
+	// Name describes the sink in messages to the user
+	Name() string
+	// Write delivers formatted text
+	Write(text string) error
+}
+
+// Config describes a sink to create and how to format what it receives
+type Config struct {
+	Kind   Kind
+	Target string // File path, webhook URL or named pipe path; unused by other kinds
+	Format string // Template for what is written; {{text}} is the transcript
+	// Numbers writes spoken numbers, dates and times in the transcript as digits
+	Numbers bool
+}
+
+// Format fills in a template for an event. Placeholders are {{text}},
+// {{date}}, {{time}}, {{datetime}}, {{session}} and {{segment}}.
+func Format(template string, event Event) string {
+	if template == "" {
+		template = DefaultFormat
+	}
+	return strings.NewReplacer(
+		"{{text}}", strings.TrimSpace(event.Text),
+		"{{date}}", event.Time.Format("2006-01-02"),
+		"{{time}}", event.Time.Format("15:04"),
+		"{{datetime}}", event.Time.Format("2006-01-02 15:04"),
+		"{{session}}", event.SessionID,
+		"{{segment}}", strconv.Itoa(event.SegmentID),
+	).Replace(template)
+}
+
+// route pairs a sink with the format of the text it receives
+type route struct {
+	kind    Kind
+	sink    Sink
+	format  string
+	numbers bool // Normalize numbers before formatting
+}
+
+// Router delivers each event to several sinks, formatting it for each one
+type Router struct {
+	routes    []route
+	normalize func(text string) string // Writes spoken numbers as digits
+	privacy   *Privacy                 // Skips sinks that keep text while incognito
+
+	// Rules for the application that has focus, and how to find it
+	apps        []AppRule
+	focused     func() (Window, error)
+	focusFailed atomic.Bool // Finding the focused application failed and was reported
+}
+
+// NewRouter creates the sinks in configs. Sinks that can't be created are
+// left out and reported in the returned error, while the others still work.
+// normalize rewrites spoken numbers for sinks that ask for it; nil leaves
+// the text as it is.
+func NewRouter(configs []Config, normalize func(text string) string) (*Router, error) {
+	r := &Router{normalize: normalize}
+	var errs []error
+	for _, cfg := range configs {
+		sink, err := New(cfg.Kind, cfg.Target)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		r.Add(cfg.Kind, sink, cfg.Format, cfg.Numbers)
+	}
+	return r, errors.Join(errs...)
+}
+
+// Add sends events to sink, a sink of the given kind, formatted with format.
+// If numbers is set, spoken numbers are written as digits first.
+func (r *Router) Add(kind Kind, sink Sink, format string, numbers bool) {
+	r.routes = append(r.routes, route{kind: kind, sink: sink, format: format, numbers: numbers})
+}
+
+// SetPrivacy sets the incognito switch. While incognito, sinks that keep
+// text, such as files and webhooks, are skipped.
+func (r *Router) SetPrivacy(privacy *Privacy) {
+	r.privacy = privacy
+}
+
+// SetAppRules changes what is delivered while an application matching one
+// of rules has focus, finding that application with focused, e.g.
+// FocusedWindow
+func (r *Router) SetAppRules(rules []AppRule, focused func() (Window, error)) {
+	r.apps = rules
+	r.focused = focused
+}
+
+// appAction returns the action of the rule for the application that has
+// focus, or "" if there is none. Only the first failure to find the
+// application is returned, since later ones would fail the same way.
+func (r *Router) appAction() (string, error) {
+	if len(r.apps) == 0 || r.focused == nil {
+		return "", nil
+	}
+	window, err := r.focused()
+	if err != nil {
+		if r.focusFailed.Swap(true) {
+			return "", nil
+		}
+		return "", fmt.Errorf("app rules skipped: %w", err)
+	}
+	rule, _ := MatchApp(r.apps, window)
+	return rule.Action, nil
+}
+
+// Enabled reports whether there is any sink to deliver to, counting those
+// app rules deliver to
+func (r *Router) Enabled() bool {
+	if r == nil {
+		return false
+	}
+	if len(r.routes) > 0 {
+		return true
+	}
+	for _, rule := range r.apps {
+		if _, ok := appKinds[rule.Action]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Write delivers an event to every sink, as changed by the rule for the
+// application that has focus. A failing sink doesn't keep the event from
+// the others; all failures are returned together.
+func (r *Router) Write(event Event) error {
+	if r == nil {
+		return nil
+	}
+	action, err := r.appAction()
+	if action == AppOff {
+		return nil
+	}
+	errs := []error{err}
+
+	// The rule's sink gets this event even if it isn't enabled, and the
+	// other one that acts on the focused application doesn't
+	routes := r.routes
+	ruleKind, ruled := appKinds[action]
+	if ruled && !r.has(ruleKind) {
+		sink, _ := New(ruleKind, "") // Never fails for these kinds
+		routes = append(routes[:len(routes):len(routes)], route{kind: ruleKind, sink: sink})
+	}
+
+	// Normalize once, however many sinks want it
+	normalized := event
+	for _, route := range r.routes {
+		if route.numbers && r.normalize != nil {
+			normalized.Text = r.normalize(event.Text)
+			break
+		}
+	}
+
+	for _, route := range routes {
+		if !r.privacy.Allows(route.kind) || (ruled && route.kind.focused() && route.kind != ruleKind) {
+			continue
+		}
+		e := event
+		if route.numbers {
+			e = normalized
+		}
+		if err := route.sink.Write(Format(route.format, e)); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", route.sink.Name(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// has reports whether events are delivered to a sink of the given kind
+func (r *Router) has(kind Kind) bool {
+	for _, route := range r.routes {
+		if route.kind == kind {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,244 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// recorder is a sink that remembers what it was given
+type recorder struct {
+	texts []string
+	err   error
+}
+
+func (r *recorder) Name() string { return "recorder" }
+
+func (r *recorder) Write(text string) error {
+	r.texts = append(r.texts, text)
+	return r.err
+}
+
+func TestFormat(t *testing.T) {
+	event := Event{
+		Text:      " Buy milk. ",
+		SessionID: "s1",
+		SegmentID: 3,
+		Time:      time.Date(2025, 3, 1, 10, 17, 0, 0, time.Local),
+	}
+
+	if got := Format("", event); got != "Buy milk." {
+		t.Errorf("Expected the text by default, got %q", got)
+	}
+	got := Format("- [{{datetime}}] {{text}} ({{session}}#{{segment}})", event)
+	if got != "- [2025-03-01 10:17] Buy milk. (s1#3)" {
+		t.Errorf("Unexpected formatted text %q", got)
+	}
+}
+
+func TestRouterFormatsPerSink(t *testing.T) {
+	plain, quoted := &recorder{}, &recorder{err: errors.New("offline")}
+	router := &Router{}
+	router.Add(KindStdout, plain, "", false)
+	router.Add(KindStdout, quoted, "> {{text}}", false)
+
+	err := router.Write(Event{Text: "hello"})
+	if err == nil || !strings.Contains(err.Error(), "recorder: offline") {
+		t.Errorf("Expected the failing sink to be reported, got %v", err)
+	}
+	if len(plain.texts) != 1 || plain.texts[0] != "hello" {
+		t.Errorf("Unexpected text for the plain sink: %q", plain.texts)
+	}
+	if len(quoted.texts) != 1 || quoted.texts[0] != "> hello" {
+		t.Errorf("Unexpected text for the quoted sink: %q", quoted.texts)
+	}
+}
+
+func TestRouterNormalizesNumbersPerSink(t *testing.T) {
+	words, digits := &recorder{}, &recorder{}
+	router := &Router{normalize: strings.ToUpper}
+	router.Add(KindStdout, words, "", false)
+	router.Add(KindStdout, digits, "", true)
+
+	if err := router.Write(Event{Text: "twenty three percent"}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if words.texts[0] != "twenty three percent" {
+		t.Errorf("Expected the text as spoken, got %q", words.texts[0])
+	}
+	if digits.texts[0] != "TWENTY THREE PERCENT" {
+		t.Errorf("Expected normalized text, got %q", digits.texts[0])
+	}
+}
+
+func TestRouterSkipsKeepingSinksWhenIncognito(t *testing.T) {
+	clipboard, file := &recorder{}, &recorder{}
+	privacy := &Privacy{}
+	router := &Router{}
+	router.SetPrivacy(privacy)
+	router.Add(KindClipboard, clipboard, "", false)
+	router.Add(KindFile, file, "", false)
+
+	privacy.SetIncognito(true)
+	if err := router.Write(Event{Text: "secret"}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if len(clipboard.texts) != 1 {
+		t.Errorf("Expected the clipboard to get the text, got %q", clipboard.texts)
+	}
+	if len(file.texts) != 0 {
+		t.Errorf("Expected nothing written to the file while incognito, got %q", file.texts)
+	}
+
+	privacy.SetIncognito(false)
+	router.Write(Event{Text: "public"})
+	if len(file.texts) != 1 || file.texts[0] != "public" {
+		t.Errorf("Expected the file to get text again, got %q", file.texts)
+	}
+}
+
+func TestPrivacyAllows(t *testing.T) {
+	var none *Privacy
+	if none.Incognito() || !none.Allows(KindHistory) {
+		t.Errorf("Expected a nil privacy switch to allow everything")
+	}
+
+	privacy := &Privacy{}
+	privacy.SetIncognito(true)
+	for _, kind := range []Kind{KindFile, KindWebhook, KindHistory, KindArchive, KindNote, KindMQTT, KindHook, Kind("new")} {
+		if privacy.Allows(kind) {
+			t.Errorf("Expected %s to be refused while incognito", kind)
+		}
+	}
+	for _, kind := range []Kind{KindClipboard, KindType, KindStdout} {
+		if !privacy.Allows(kind) {
+			t.Errorf("Expected %s to be allowed while incognito", kind)
+		}
+	}
+}
+
+func TestNewRouterSkipsInvalidSinks(t *testing.T) {
+	router, err := NewRouter([]Config{
+		{Kind: KindStdout},
+		{Kind: KindFile},
+		{Kind: KindWebhook, Target: "ftp://example.com"},
+		{Kind: "fax"},
+	}, nil)
+	if err == nil {
+		t.Error("Expected errors for the invalid sinks")
+	}
+	if len(router.routes) != 1 || !router.Enabled() {
+		t.Errorf("Expected only the valid sink, got %d", len(router.routes))
+	}
+
+	var empty *Router
+	if empty.Enabled() || empty.Write(Event{Text: "x"}) != nil {
+		t.Error("Expected a nil router to do nothing")
+	}
+}
+
+func TestFileAppendsLines(t *testing.T) {
+	dir := t.TempDir()
+	sink, err := New(KindFile, filepath.Join(dir, "logs", "transcripts.txt"))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	for _, text := range []string{"first", "second\n"} {
+		if err := sink.Write(text); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "logs", "transcripts.txt"))
+	if err != nil {
+		t.Fatalf("Failed to read file: %v", err)
+	}
+	if string(data) != "first\nsecond\n" {
+		t.Errorf("Unexpected file contents %q", data)
+	}
+}
+
+func TestWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter("buffer", &buf)
+	w.Write("one")
+	w.Write("two")
+	if buf.String() != "one\ntwo\n" {
+		t.Errorf("Unexpected output %q", buf.String())
+	}
+}
+
+func TestJSONLines(t *testing.T) {
+	var buf bytes.Buffer
+	j := NewJSONLines(&buf)
+	j.Partial("hello <world>")
+	j.Final("20250301-101500.000", 3, "Hello world.", "en")
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 lines, got %q", buf.String())
+	}
+	var partial, final JSONEvent
+	if err := json.Unmarshal([]byte(lines[0]), &partial); err != nil || partial.Type != EventPartial || partial.Text != "hello <world>" {
+		t.Errorf("Unexpected partial event %q (%v)", lines[0], err)
+	}
+	if !strings.Contains(lines[0], "<world>") || strings.Contains(lines[0], "session_id") {
+		t.Errorf("Expected unescaped text and no session in %q", lines[0])
+	}
+	if err := json.Unmarshal([]byte(lines[1]), &final); err != nil || final.Type != EventFinal || final.SegmentID != 3 || final.Language != "en" {
+		t.Errorf("Unexpected final event %q (%v)", lines[1], err)
+	}
+
+	var none *JSONLines
+	if err := none.Partial("ignored"); err != nil {
+		t.Errorf("Expected a nil JSONLines to write nothing, got %v", err)
+	}
+}
+
+func TestTypeCommand(t *testing.T) {
+	installed := func(names ...string) func(string) (string, error) {
+		return func(name string) (string, error) {
+			for _, n := range names {
+				if n == name {
+					return "/usr/bin/" + name, nil
+				}
+			}
+			return "", errors.New("not found")
+		}
+	}
+	env := func(wayland string) func(string) string {
+		return func(key string) string {
+			if key == "WAYLAND_DISPLAY" {
+				return wayland
+			}
+			return ""
+		}
+	}
+
+	name, args, err := typeCommand("linux", env(""), installed("xdotool", "wtype"), "hi")
+	if err != nil || name != "xdotool" || args[len(args)-1] != "hi" {
+		t.Errorf("Expected xdotool on X11, got %s %q (%v)", name, args, err)
+	}
+	name, _, _ = typeCommand("linux", env("wayland-0"), installed("xdotool", "wtype"), "hi")
+	if name != "wtype" {
+		t.Errorf("Expected wtype on Wayland, got %s", name)
+	}
+	if _, _, err := typeCommand("linux", env(""), installed(), "hi"); err == nil {
+		t.Error("Expected an error without a typing tool")
+	}
+
+	_, args, _ = typeCommand("darwin", env(""), installed(), `say "hi"`)
+	if args[1] != `tell application "System Events" to keystroke "say \"hi\""` {
+		t.Errorf("Unexpected AppleScript %q", args[1])
+	}
+	_, args, _ = typeCommand("windows", env(""), installed(), "50% (it's)")
+	if !strings.Contains(args[2], "SendWait('50{%} {(}it''s{)}')") {
+		t.Errorf("Unexpected SendKeys script %q", args[2])
+	}
+}
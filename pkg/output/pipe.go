@@ -0,0 +1,77 @@
+package output
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// pipeTimeout bounds how long a write waits for a reader that stopped reading
+const pipeTimeout = 2 * time.Second
+
+// errNoReader is returned by openPipe when no program has the pipe open
+var errNoReader = errors.New("named pipe has no reader")
+
+// Pipe writes text to a named pipe, one entry per line, so window managers,
+// status bars and other local programs can follow transcripts as they are
+// finalized. The pipe is created if it doesn't exist. Text written while no
+// program is reading is dropped rather than waiting for one, and the pipe is
+// kept open between entries so a reader doesn't see the end after each one.
+type Pipe struct {
+	Path string
+
+	mu   sync.Mutex
+	file *os.File // Open while a reader has the pipe open
+}
+
+// NewPipe creates a sink writing to the named pipe at path, which may start
+// with ~ for the home directory, creating the pipe if needed
+func NewPipe(path string) (*Pipe, error) {
+	path, err := expandPath(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := makePipe(path); err != nil {
+		return nil, err
+	}
+	return &Pipe{Path: path}, nil
+}
+
+// Name describes the sink
+func (p *Pipe) Name() string { return "pipe " + p.Path }
+
+// Write writes text followed by a newline if a program is reading the pipe
+func (p *Pipe) Write(text string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	// A reader that went away may have been replaced, so try a second time
+	for attempt := 0; attempt < 2; attempt++ {
+		if p.file == nil {
+			file, err := openPipe(p.Path)
+			if errors.Is(err, errNoReader) {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			p.file = file
+		}
+
+		p.file.SetWriteDeadline(time.Now().Add(pipeTimeout))
+		_, err := io.WriteString(p.file, line(text))
+		if err == nil {
+			return nil
+		}
+		p.file.Close()
+		p.file = nil
+		if !errors.Is(err, syscall.EPIPE) {
+			return fmt.Errorf("failed to write named pipe: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+//go:build !windows
+
+package output
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+)
+
+func TestPipe(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ramble", "transcript")
+	pipe, err := NewPipe(path)
+	if err != nil {
+		t.Fatalf("Failed to create: %v", err)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode()&os.ModeNamedPipe == 0 {
+		t.Fatalf("Expected a named pipe to be created, got %v", err)
+	}
+
+	// Without a reader the text is dropped
+	if err := pipe.Write("nobody listening"); err != nil {
+		t.Errorf("Expected no error without a reader, got %v", err)
+	}
+
+	// A reader gets each entry as a line, even after another one left
+	for _, text := range []string{"first", "second"} {
+		file, err := os.OpenFile(path, os.O_RDONLY|syscall.O_NONBLOCK, 0)
+		if err != nil {
+			t.Fatalf("Failed to open the pipe: %v", err)
+		}
+		if err := pipe.Write(text); err != nil {
+			t.Fatalf("Failed to write: %v", err)
+		}
+		got, err := bufio.NewReader(file).ReadString('\n')
+		if err != nil || got != text+"\n" {
+			t.Errorf("Expected %q, got %q (%v)", text+"\n", got, err)
+		}
+		file.Close()
+	}
+
+	// Another kind of file is refused
+	if err := os.WriteFile(path+".txt", nil, 0644); err != nil {
+		t.Fatalf("Failed to write: %v", err)
+	}
+	if _, err := NewPipe(path + ".txt"); err == nil {
+		t.Error("Expected an error for a regular file")
+	}
+}
@@ -0,0 +1,42 @@
+//go:build !windows
+
+package output
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+// makePipe creates a named pipe at path unless one is there already
+func makePipe(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create folder: %w", err)
+	}
+	if err := syscall.Mkfifo(path, 0600); err != nil && !errors.Is(err, os.ErrExist) {
+		return fmt.Errorf("failed to create named pipe: %w", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("failed to create named pipe: %w", err)
+	}
+	if info.Mode()&os.ModeNamedPipe == 0 {
+		return fmt.Errorf("%s exists and is not a named pipe", path)
+	}
+	return nil
+}
+
+// openPipe opens the named pipe at path for writing without waiting for a
+// reader, returning errNoReader if there is none
+func openPipe(path string) (*os.File, error) {
+	file, err := os.OpenFile(path, os.O_WRONLY|syscall.O_NONBLOCK, 0)
+	if errors.Is(err, syscall.ENXIO) {
+		return nil, errNoReader
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open named pipe: %w", err)
+	}
+	return file, nil
+}
@@ -0,0 +1,22 @@
+//go:build windows
+
+package output
+
+import (
+	"errors"
+	"os"
+)
+
+// errPipeUnsupported is returned for named pipe outputs on Windows, whose
+// named pipes aren't files programs can open by path
+var errPipeUnsupported = errors.New("named pipe outputs are not supported on Windows")
+
+// makePipe implements named pipes on Windows, which are unsupported
+func makePipe(path string) error {
+	return errPipeUnsupported
+}
+
+// openPipe implements named pipes on Windows, which are unsupported
+func openPipe(path string) (*os.File, error) {
+	return nil, errPipeUnsupported
+}
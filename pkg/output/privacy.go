@@ -0,0 +1,54 @@
+package output
+
+import "sync/atomic"
+
+// Destinations that aren't sinks but keep or send transcripts and
+// recordings. They are kinds too, so Privacy covers them like sinks.
+const (
+	// KindHistory is the session history and the crash recovery journal
+	KindHistory Kind = "history"
+	// KindArchive is the archive of recordings
+	KindArchive Kind = "archive"
+	// KindNote is the note finished recordings are appended to
+	KindNote Kind = "note"
+	// KindMQTT is the MQTT broker transcripts are published to
+	KindMQTT Kind = "mqtt"
+	// KindHook is the user's programs run on each finalized segment
+	KindHook Kind = "hook"
+)
+
+// Keeps reports whether a destination keeps what it receives or sends it off
+// this computer. Only the clipboard, typing and standard output hand text to
+// the user without keeping it; kinds added later keep it until shown otherwise.
+func (k Kind) Keeps() bool {
+	switch k {
+	case KindClipboard, KindType, KindStdout:
+		return false
+	}
+	return true
+}
+
+// Privacy is the switch for incognito dictation. While it is on, nothing is
+// delivered to a destination that keeps it, so a transcript is only shown,
+// copied or typed. Routers skip such sinks by themselves; every other
+// destination asks Allows before writing. A nil Privacy is never incognito.
+// It is safe for concurrent use.
+type Privacy struct {
+	incognito atomic.Bool
+}
+
+// SetIncognito turns incognito dictation on or off
+func (p *Privacy) SetIncognito(incognito bool) {
+	p.incognito.Store(incognito)
+}
+
+// Incognito reports whether incognito dictation is on
+func (p *Privacy) Incognito() bool {
+	return p != nil && p.incognito.Load()
+}
+
+// Allows reports whether a destination of the given kind may receive
+// transcripts and recordings
+func (p *Privacy) Allows(kind Kind) bool {
+	return !p.Incognito() || !kind.Keeps()
+}
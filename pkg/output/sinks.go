@@ -0,0 +1,221 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jeff-barlow-spady/ramble/pkg/clipboard"
+	"github.com/jeff-barlow-spady/ramble/pkg/integrations/webhook"
+)
+
+// New creates a sink of the given kind. target is the file path for file
+// sinks, the URL for webhook sinks and the path of the pipe for pipe sinks.
+func New(kind Kind, target string) (Sink, error) {
+	target = strings.TrimSpace(target)
+	switch kind {
+	case KindClipboard:
+		return Clipboard{}, nil
+	case KindFile:
+		if target == "" {
+			return nil, fmt.Errorf("no file set for file output")
+		}
+		return &File{Path: target}, nil
+	case KindType:
+		return Typer{}, nil
+	case KindWebhook:
+		if err := webhook.Validate(target); err != nil {
+			return nil, err
+		}
+		return Webhook{URL: target}, nil
+	case KindStdout:
+		return NewWriter("standard output", os.Stdout), nil
+	case KindPipe:
+		if target == "" {
+			return nil, fmt.Errorf("no path set for named pipe output")
+		}
+		return NewPipe(target)
+	}
+	return nil, fmt.Errorf("unknown output %q", kind)
+}
+
+// Clipboard copies text to the system clipboard
+type Clipboard struct{}
+
+// Name describes the sink
+func (Clipboard) Name() string { return "clipboard" }
+
+// Write replaces the clipboard contents with text
+func (Clipboard) Write(text string) error {
+	return clipboard.SetText(text)
+}
+
+// File appends text to a file, one entry per line. The path may contain
+// {{date}} and the other placeholders, and may start with ~ for the home directory.
+type File struct {
+	Path string
+	mu   sync.Mutex
+}
+
+// Name describes the sink
+func (f *File) Name() string { return "file " + f.Path }
+
+// Write appends text to the file, creating it and its folder if needed
+func (f *File) Write(text string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	path, err := expandPath(Format(f.Path, Event{Time: time.Now()}))
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create folder: %w", err)
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := io.WriteString(file, line(text)); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	return nil
+}
+
+// expandPath replaces a leading ~ with the home directory
+func expandPath(path string) (string, error) {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user home directory: %w", err)
+	}
+	return filepath.Join(home, path[1:]), nil
+}
+
+// Writer prints text to a stream such as standard output, one entry per line
+type Writer struct {
+	name string
+	w    io.Writer
+	mu   sync.Mutex
+}
+
+// NewWriter creates a sink that writes to w
+func NewWriter(name string, w io.Writer) *Writer {
+	return &Writer{name: name, w: w}
+}
+
+// Name describes the sink
+func (w *Writer) Name() string { return w.name }
+
+// Write prints text followed by a newline
+func (w *Writer) Write(text string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	_, err := io.WriteString(w.w, line(text))
+	return err
+}
+
+// line ends text with a newline unless it already has one
+func line(text string) string {
+	if strings.HasSuffix(text, "\n") {
+		return text
+	}
+	return text + "\n"
+}
+
+// Webhook posts text to a URL as JSON
+type Webhook struct {
+	URL string
+}
+
+// Name describes the sink
+func (w Webhook) Name() string { return "webhook " + w.URL }
+
+// Write posts text to the webhook
+func (w Webhook) Write(text string) error {
+	return webhook.Send(w.URL, text)
+}
+
+// Typer types text into the window that has focus, as if typed on the keyboard.
+// It uses wtype or ydotool on Wayland, xdotool on X11, System Events on macOS
+// and SendKeys on Windows.
+type Typer struct{}
+
+// Name describes the sink
+func (Typer) Name() string { return "typing" }
+
+// Write types text at the cursor
+func (Typer) Write(text string) error {
+	name, args, err := typeCommand(runtime.GOOS, os.Getenv, exec.LookPath, text)
+	if err != nil {
+		return err
+	}
+	if out, err := exec.Command(name, args...).CombinedOutput(); err != nil {
+		return fmt.Errorf("%s failed: %w: %s", name, err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// typeCommand returns the command that types text on the given platform
+func typeCommand(goos string, getenv func(string) string, lookPath func(string) (string, error), text string) (string, []string, error) {
+	switch goos {
+	case "darwin":
+		script := fmt.Sprintf("tell application \"System Events\" to keystroke %s", appleScriptString(text))
+		return "osascript", []string{"-e", script}, nil
+	case "windows":
+		script := "Add-Type -AssemblyName System.Windows.Forms; " +
+			"[System.Windows.Forms.SendKeys]::SendWait('" + strings.ReplaceAll(sendKeysEscape(text), "'", "''") + "')"
+		return "powershell", []string{"-NoProfile", "-Command", script}, nil
+	}
+
+	// Prefer the tools for the display server in use
+	tools := []struct {
+		name string
+		args []string
+	}{
+		{"xdotool", []string{"type", "--clearmodifiers", "--", text}},
+		{"wtype", []string{"--", text}},
+		{"ydotool", []string{"type", "--", text}},
+	}
+	if getenv("WAYLAND_DISPLAY") != "" {
+		tools = append(tools[1:], tools[0])
+	}
+	for _, tool := range tools {
+		if _, err := lookPath(tool.name); err == nil {
+			return tool.name, tool.args, nil
+		}
+	}
+	return "", nil, fmt.Errorf("typing needs xdotool, wtype or ydotool to be installed")
+}
+
+// appleScriptString quotes text as an AppleScript string literal
+func appleScriptString(text string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(text) + `"`
+}
+
+// sendKeysEscape escapes the characters SendKeys treats as commands
+func sendKeysEscape(text string) string {
+	var b strings.Builder
+	for _, r := range text {
+		switch r {
+		case '+', '^', '%', '~', '(', ')', '{', '}', '[', ']':
+			b.WriteString("{" + string(r) + "}")
+		case '\n':
+			b.WriteString("{ENTER}")
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
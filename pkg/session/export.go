@@ -0,0 +1,275 @@
+package session
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// ExportSchema identifies session export files
+const ExportSchema = "ramble.session"
+
+// ExportVersion is the current version of the export format. Fields may be
+// added without changing it, so readers must ignore fields they don't know.
+// Any other change bumps the version and adds an upgrade step from the
+// previous version, so every older file can still be imported.
+const ExportVersion = 1
+
+// Export is the versioned JSON representation of a session used for
+// exporting, importing and third-party tools. See docs/SESSION_EXPORT.md.
+type Export struct {
+	Schema   string            `json:"schema"`
+	Version  int               `json:"version"`
+	ID       string            `json:"id"`
+	Created  time.Time         `json:"created"`
+	Exported time.Time         `json:"exported"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+	Speakers []ExportSpeaker   `json:"speakers,omitempty"`
+	Segments []ExportSegment   `json:"segments"`
+}
+
+// ExportSpeaker is a speaker in an export file
+type ExportSpeaker struct {
+	ID   string `json:"id"`
+	Name string `json:"name,omitempty"`
+}
+
+// ExportSegment is a segment in an export file. Times are milliseconds from
+// the start of the segment's recording, and StartedAt and EndedAt are the
+// wall-clock times it was spoken, if known.
+type ExportSegment struct {
+	ID        int          `json:"id"`
+	Text      string       `json:"text"`
+	StartMS   int64        `json:"start_ms,omitempty"`
+	EndMS     int64        `json:"end_ms,omitempty"`
+	StartedAt *time.Time   `json:"started_at,omitempty"`
+	EndedAt   *time.Time   `json:"ended_at,omitempty"`
+	Speaker   string       `json:"speaker,omitempty"`
+	Audio     string       `json:"audio,omitempty"`
+	Words     []ExportWord `json:"words,omitempty"`
+	Tags      []string     `json:"tags,omitempty"`
+	Language  string       `json:"language,omitempty"`
+	SpeechMS  int64        `json:"speech_ms,omitempty"`
+}
+
+// ExportWord is a word in an export file
+type ExportWord struct {
+	Text       string  `json:"text"`
+	StartMS    int64   `json:"start_ms"`
+	EndMS      int64   `json:"end_ms"`
+	Confidence float32 `json:"confidence,omitempty"`
+}
+
+// upgrades convert an export of version N (the key) to version N+1
+var upgrades = map[int]func(data []byte) ([]byte, error){
+	0: upgradeFromSessionFile,
+}
+
+// ExportJSON encodes the session in the current export format.
+// Undo history is not exported.
+func ExportJSON(s *Session) ([]byte, error) {
+	s.mu.Lock()
+	export := Export{
+		Schema:   ExportSchema,
+		Version:  ExportVersion,
+		ID:       s.ID,
+		Created:  s.Created,
+		Exported: time.Now(),
+		Metadata: s.Metadata,
+		Segments: make([]ExportSegment, len(s.Segments)),
+	}
+	for _, speaker := range s.Speakers {
+		export.Speakers = append(export.Speakers, ExportSpeaker{ID: speaker.ID, Name: speaker.Name})
+	}
+	for i, seg := range s.Segments {
+		export.Segments[i] = exportSegment(seg)
+	}
+	s.mu.Unlock()
+
+	data, err := json.MarshalIndent(export, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode session export: %w", err)
+	}
+	return data, nil
+}
+
+// ImportJSON decodes an export of any supported version into a new session
+// without undo history. Files saved by the session store, which predate the
+// export format, are read as version 0.
+func ImportJSON(data []byte) (*Session, error) {
+	var header struct {
+		Schema  string `json:"schema"`
+		Version int    `json:"version"`
+	}
+	if err := json.Unmarshal(data, &header); err != nil {
+		return nil, fmt.Errorf("failed to parse session export: %w", err)
+	}
+
+	if header.Schema != "" && header.Schema != ExportSchema {
+		return nil, fmt.Errorf("not a Ramble session export (schema %q)", header.Schema)
+	}
+	if header.Version > ExportVersion {
+		return nil, fmt.Errorf("session export version %d is newer than supported version %d; please update Ramble",
+			header.Version, ExportVersion)
+	}
+
+	// Bring older files up to the current version one step at a time
+	for version := header.Version; version < ExportVersion; version++ {
+		upgrade, ok := upgrades[version]
+		if !ok {
+			return nil, fmt.Errorf("unsupported session export version %d", version)
+		}
+		var err error
+		if data, err = upgrade(data); err != nil {
+			return nil, fmt.Errorf("failed to upgrade session export from version %d: %w", version, err)
+		}
+	}
+
+	var export Export
+	if err := json.Unmarshal(data, &export); err != nil {
+		return nil, fmt.Errorf("failed to parse session export: %w", err)
+	}
+	return importExport(&export), nil
+}
+
+// ImportFile reads a session export file
+func ImportFile(path string) (*Session, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read session export: %w", err)
+	}
+	return ImportJSON(data)
+}
+
+// importExport converts a current-version export into a session
+func importExport(export *Export) *Session {
+	s := New()
+	if ValidID(export.ID) {
+		s.ID = export.ID
+	}
+	if !export.Created.IsZero() {
+		s.Created = export.Created
+	}
+	s.Metadata = export.Metadata
+	for _, speaker := range export.Speakers {
+		s.Speakers = append(s.Speakers, Speaker{ID: speaker.ID, Name: speaker.Name})
+	}
+
+	// Keep the file's segment IDs unless they can't identify segments uniquely
+	seen := make(map[int]bool)
+	renumber := false
+	for _, seg := range export.Segments {
+		if seg.ID < 1 || seen[seg.ID] {
+			renumber = true
+			break
+		}
+		seen[seg.ID] = true
+	}
+
+	for i, exported := range export.Segments {
+		seg := importSegment(exported)
+		if renumber {
+			seg.ID = i + 1
+		}
+		if seg.ID >= s.NextID {
+			s.NextID = seg.ID + 1
+		}
+		s.Segments = append(s.Segments, seg)
+	}
+
+	return s
+}
+
+// exportSegment converts a segment to its export form
+func exportSegment(seg Segment) ExportSegment {
+	exported := ExportSegment{
+		ID:       seg.ID,
+		Text:     seg.Text,
+		StartMS:  seg.Start.Milliseconds(),
+		EndMS:    seg.End.Milliseconds(),
+		Speaker:  seg.Speaker,
+		Audio:    seg.Audio,
+		Tags:     seg.Tags,
+		Language: seg.Language,
+		SpeechMS: seg.Speech.Milliseconds(),
+	}
+	if !seg.StartedAt.IsZero() {
+		exported.StartedAt = &seg.StartedAt
+	}
+	if !seg.EndedAt.IsZero() {
+		exported.EndedAt = &seg.EndedAt
+	}
+	for _, word := range seg.Words {
+		exported.Words = append(exported.Words, ExportWord{
+			Text:       word.Text,
+			StartMS:    word.Start.Milliseconds(),
+			EndMS:      word.End.Milliseconds(),
+			Confidence: word.Confidence,
+		})
+	}
+	return exported
+}
+
+// importSegment converts an exported segment back to a segment
+func importSegment(exported ExportSegment) Segment {
+	seg := Segment{
+		ID:       exported.ID,
+		Text:     exported.Text,
+		Start:    time.Duration(exported.StartMS) * time.Millisecond,
+		End:      time.Duration(exported.EndMS) * time.Millisecond,
+		Speaker:  exported.Speaker,
+		Audio:    exported.Audio,
+		Tags:     exported.Tags,
+		Language: exported.Language,
+		Speech:   time.Duration(exported.SpeechMS) * time.Millisecond,
+	}
+	if exported.StartedAt != nil {
+		seg.StartedAt = *exported.StartedAt
+	}
+	if exported.EndedAt != nil {
+		seg.EndedAt = *exported.EndedAt
+	}
+	for _, word := range exported.Words {
+		seg.Words = append(seg.Words, Word{
+			Text:       word.Text,
+			Start:      time.Duration(word.StartMS) * time.Millisecond,
+			End:        time.Duration(word.EndMS) * time.Millisecond,
+			Confidence: word.Confidence,
+		})
+	}
+	return seg
+}
+
+// upgradeFromSessionFile converts a session store file (version 0) to version 1
+func upgradeFromSessionFile(data []byte) ([]byte, error) {
+	var saved struct {
+		ID       string            `json:"id"`
+		Created  time.Time         `json:"created"`
+		Segments []Segment         `json:"segments"`
+		Speakers []Speaker         `json:"speakers"`
+		Metadata map[string]string `json:"metadata"`
+	}
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return nil, err
+	}
+	if saved.Segments == nil {
+		return nil, fmt.Errorf("no segments found")
+	}
+
+	export := Export{
+		Schema:   ExportSchema,
+		Version:  1,
+		ID:       saved.ID,
+		Created:  saved.Created,
+		Metadata: saved.Metadata,
+		Segments: make([]ExportSegment, len(saved.Segments)),
+	}
+	for _, speaker := range saved.Speakers {
+		export.Speakers = append(export.Speakers, ExportSpeaker{ID: speaker.ID, Name: speaker.Name})
+	}
+	for i, seg := range saved.Segments {
+		export.Segments[i] = exportSegment(seg)
+	}
+	return json.Marshal(export)
+}
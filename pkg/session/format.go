@@ -0,0 +1,251 @@
+package session
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"strings"
+	"time"
+)
+
+// TranscriptFormat is a document format a session's transcript can be written in
+type TranscriptFormat string
+
+const (
+	// FormatMarkdown writes a Markdown document with a heading per segment
+	FormatMarkdown TranscriptFormat = "markdown"
+	// FormatText writes plain text with one paragraph per segment
+	FormatText TranscriptFormat = "text"
+	// FormatHTML writes a standalone HTML page
+	FormatHTML TranscriptFormat = "html"
+)
+
+// TranscriptFormats lists the supported transcript formats
+var TranscriptFormats = []TranscriptFormat{FormatMarkdown, FormatText, FormatHTML}
+
+// lowConfidence is the word confidence below which words are marked as uncertain
+const lowConfidence = 0.5
+
+// ParseTranscriptFormat returns the format with the given name or file extension
+func ParseTranscriptFormat(name string) (TranscriptFormat, error) {
+	switch strings.ToLower(strings.TrimPrefix(name, ".")) {
+	case "markdown", "md":
+		return FormatMarkdown, nil
+	case "text", "txt":
+		return FormatText, nil
+	case "html", "htm":
+		return FormatHTML, nil
+	}
+	return "", fmt.Errorf("unknown transcript format %q (use markdown, text or html)", name)
+}
+
+// Extension returns the usual file extension for the format, including the dot
+func (f TranscriptFormat) Extension() string {
+	switch f {
+	case FormatMarkdown:
+		return ".md"
+	case FormatHTML:
+		return ".html"
+	}
+	return ".txt"
+}
+
+// RenderTranscript writes the session's transcript in the given format,
+// including timestamps, speaker names and uncertain words where known
+func RenderTranscript(s *Session, format TranscriptFormat) ([]byte, error) {
+	s.mu.Lock()
+	segments := append([]Segment(nil), s.Segments...)
+	speakers := make(map[string]string, len(s.Speakers))
+	for _, sp := range s.Speakers {
+		speakers[sp.ID] = sp.Name
+	}
+	title := s.Metadata["title"]
+	created := s.Created
+	s.mu.Unlock()
+
+	if title == "" {
+		title = "Transcript " + created.Format("2006-01-02 15:04")
+	}
+
+	// Only show timestamps if the segments have timing
+	timed := false
+	for _, seg := range segments {
+		if seg.End > 0 {
+			timed = true
+			break
+		}
+	}
+
+	speakerName := func(id string) string {
+		if name := speakers[id]; name != "" {
+			return name
+		}
+		return id
+	}
+
+	var buf bytes.Buffer
+	switch format {
+	case FormatMarkdown:
+		fmt.Fprintf(&buf, "# %s\n\n", title)
+		fmt.Fprintf(&buf, "Recorded %s\n", created.Format("Monday, 2 January 2006 15:04"))
+		uncertain := false
+		for i, seg := range segments {
+			heading := segmentTime(seg, timed)
+			if heading == "" {
+				heading = fmt.Sprintf("Segment %d", i+1)
+			}
+			if seg.Speaker != "" {
+				heading += " - " + speakerName(seg.Speaker)
+			}
+			if seg.Language != "" {
+				heading += " [" + languageBadge(seg.Language) + "]"
+			}
+			text := markWords(seg, func(word string) string {
+				uncertain = true
+				return "_" + word + "_"
+			})
+			fmt.Fprintf(&buf, "\n## %s\n\n%s\n", heading, text)
+		}
+		if uncertain {
+			buf.WriteString("\n---\n\n_Words in italics were transcribed with low confidence._\n")
+		}
+
+	case FormatText:
+		fmt.Fprintf(&buf, "%s\n", title)
+		fmt.Fprintf(&buf, "Recorded %s\n", created.Format("Monday, 2 January 2006 15:04"))
+		for _, seg := range segments {
+			prefix := ""
+			if at := segmentTime(seg, timed); at != "" {
+				prefix = "[" + at + "] "
+			}
+			if seg.Language != "" {
+				prefix += "[" + languageBadge(seg.Language) + "] "
+			}
+			if seg.Speaker != "" {
+				prefix += speakerName(seg.Speaker) + ": "
+			}
+			text := markWords(seg, func(word string) string {
+				return word + "(?)"
+			})
+			fmt.Fprintf(&buf, "\n%s%s\n", prefix, text)
+		}
+
+	case FormatHTML:
+		buf.WriteString("<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n")
+		fmt.Fprintf(&buf, "<title>%s</title>\n", html.EscapeString(title))
+		buf.WriteString("<style>\n" +
+			"body { font-family: sans-serif; max-width: 45em; margin: 2em auto; line-height: 1.5; }\n" +
+			".meta, .time { color: #666; }\n" +
+			".speaker { font-weight: bold; }\n" +
+			".language { font-size: smaller; color: #666; border: 1px solid #ccc; border-radius: 3px; padding: 0 3px; }\n" +
+			".low-confidence { text-decoration: underline dotted; color: #a35200; }\n" +
+			"</style>\n</head>\n<body>\n")
+		fmt.Fprintf(&buf, "<h1>%s</h1>\n", html.EscapeString(title))
+		fmt.Fprintf(&buf, "<p class=\"meta\">Recorded %s</p>\n",
+			html.EscapeString(created.Format("Monday, 2 January 2006 15:04")))
+		for _, seg := range segments {
+			buf.WriteString("<p>")
+			if at := segmentTime(seg, timed); at != "" {
+				fmt.Fprintf(&buf, "<span class=\"time\">[%s]</span> ", at)
+			}
+			if seg.Language != "" {
+				fmt.Fprintf(&buf, "<span class=\"language\" lang=\"%[1]s\">%[2]s</span> ",
+					html.EscapeString(seg.Language), html.EscapeString(languageBadge(seg.Language)))
+			}
+			if seg.Speaker != "" {
+				fmt.Fprintf(&buf, "<span class=\"speaker\">%s:</span> ", html.EscapeString(speakerName(seg.Speaker)))
+			}
+			buf.WriteString(markWordsHTML(seg))
+			buf.WriteString("</p>\n")
+		}
+		buf.WriteString("</body>\n</html>\n")
+
+	default:
+		return nil, fmt.Errorf("unknown transcript format %q", format)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// languageBadge is the short label for a segment's language, e.g. "FR"
+func languageBadge(code string) string {
+	return strings.ToUpper(code)
+}
+
+// segmentTime returns when a segment starts: its offset into the recording
+// if the transcript is timed, or else the time of day it was spoken, if known
+func segmentTime(seg Segment, timed bool) string {
+	switch {
+	case timed:
+		return formatTimestamp(seg.Start)
+	case !seg.StartedAt.IsZero():
+		return seg.StartedAt.Format("15:04:05")
+	}
+	return ""
+}
+
+// markWords returns the segment text with low-confidence words passed through
+// mark. Words are only marked while they still match the text, since editing
+// a segment leaves its word timings behind.
+func markWords(seg Segment, mark func(word string) string) string {
+	if !wordsMatchText(seg) {
+		return seg.Text
+	}
+
+	var parts []string
+	for _, w := range seg.Words {
+		word := strings.TrimSpace(w.Text)
+		if word == "" {
+			continue
+		}
+		if w.Confidence > 0 && w.Confidence < lowConfidence {
+			word = mark(word)
+		}
+		parts = append(parts, word)
+	}
+	return strings.Join(parts, " ")
+}
+
+// markWordsHTML returns the escaped segment text with low-confidence words highlighted
+func markWordsHTML(seg Segment) string {
+	if !wordsMatchText(seg) {
+		return html.EscapeString(seg.Text)
+	}
+
+	var parts []string
+	for _, w := range seg.Words {
+		word := html.EscapeString(strings.TrimSpace(w.Text))
+		if word == "" {
+			continue
+		}
+		if w.Confidence > 0 && w.Confidence < lowConfidence {
+			word = fmt.Sprintf("<span class=\"low-confidence\" title=\"%.0f%% confidence\">%s</span>",
+				w.Confidence*100, word)
+		}
+		parts = append(parts, word)
+	}
+	return strings.Join(parts, " ")
+}
+
+// wordsMatchText reports whether the segment's words spell out its text
+func wordsMatchText(seg Segment) bool {
+	if len(seg.Words) == 0 {
+		return false
+	}
+	words := make([]string, len(seg.Words))
+	for i, w := range seg.Words {
+		words[i] = strings.TrimSpace(w.Text)
+	}
+	return strings.Join(strings.Fields(strings.Join(words, " ")), " ") ==
+		strings.Join(strings.Fields(seg.Text), " ")
+}
+
+// formatTimestamp formats an offset as HH:MM:SS
+func formatTimestamp(d time.Duration) string {
+	d = d.Round(time.Second)
+	h := d / time.Hour
+	d -= h * time.Hour
+	m := d / time.Minute
+	d -= m * time.Minute
+	return fmt.Sprintf("%02d:%02d:%02d", h, m, d/time.Second)
+}
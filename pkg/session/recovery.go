@@ -0,0 +1,93 @@
+package session
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Pending is transcript text that was recognized but not yet finalized into
+// a segment, as journaled for recovery after a crash
+type Pending struct {
+	SessionID string    `json:"session_id"`
+	Started   time.Time `json:"started"`
+	Updated   time.Time `json:"updated"`
+	Text      string    `json:"text"`
+}
+
+// Journal keeps the pending text of a recording in a file so it can be
+// offered for restoring if Ramble exits before the text is finalized
+type Journal struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewJournal creates a journal writing to the file at path
+func NewJournal(path string) *Journal {
+	return &Journal{path: path}
+}
+
+// Write replaces the journaled text. The file is replaced atomically so a
+// crash while writing leaves the previous text.
+func (j *Journal) Write(pending Pending) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if pending.Updated.IsZero() {
+		pending.Updated = time.Now()
+	}
+	data, err := json.Marshal(pending)
+	if err != nil {
+		return fmt.Errorf("failed to encode recovery journal: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(j.path), 0755); err != nil {
+		return fmt.Errorf("failed to create recovery directory: %w", err)
+	}
+	tmpPath := j.path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0600); err != nil {
+		return fmt.Errorf("failed to write recovery journal: %w", err)
+	}
+	if err := os.Rename(tmpPath, j.path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to replace recovery journal: %w", err)
+	}
+	return nil
+}
+
+// Clear removes the journaled text once it has been saved or discarded
+func (j *Journal) Clear() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if err := os.Remove(j.path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove recovery journal: %w", err)
+	}
+	return nil
+}
+
+// Read returns the journaled text left by a previous run, or nil if there is
+// none
+func (j *Journal) Read() (*Pending, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	data, err := os.ReadFile(j.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read recovery journal: %w", err)
+	}
+
+	var pending Pending
+	if err := json.Unmarshal(data, &pending); err != nil {
+		return nil, fmt.Errorf("failed to parse recovery journal: %w", err)
+	}
+	if pending.Text == "" {
+		return nil, nil
+	}
+	return &pending, nil
+}
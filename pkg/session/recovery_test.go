@@ -0,0 +1,40 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestJournal(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "recovery.json")
+	journal := NewJournal(path)
+
+	if pending, err := journal.Read(); err != nil || pending != nil {
+		t.Fatalf("Expected nothing to recover at first, got %v, %v", pending, err)
+	}
+
+	journal.Write(Pending{SessionID: "20250101-120000.000", Text: "first draft"})
+	if err := journal.Write(Pending{SessionID: "20250101-120000.000", Text: "first draft and more"}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	// A new journal on the same file, as after a restart, sees the newest text
+	pending, err := NewJournal(path).Read()
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if pending == nil || pending.Text != "first draft and more" || pending.Updated.IsZero() {
+		t.Errorf("Expected the newest text to be recovered, got %+v", pending)
+	}
+
+	if err := journal.Clear(); err != nil {
+		t.Fatalf("Clear failed: %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("Expected the journal file to be removed")
+	}
+	if err := journal.Clear(); err != nil {
+		t.Errorf("Expected clearing twice to succeed, got %v", err)
+	}
+}
@@ -0,0 +1,27 @@
+package session
+
+import "time"
+
+// Rollover decides when a long recording is split into a new segment, so
+// all-day dictation is kept in manageable pieces
+type Rollover struct {
+	MaxAge   time.Duration // Start a new segment after this long (0 = no limit)
+	MaxChars int           // Start a new segment after this many characters (0 = no limit)
+}
+
+// Enabled reports whether any limit is set
+func (r Rollover) Enabled() bool {
+	return r.MaxAge > 0 || r.MaxChars > 0
+}
+
+// Due reports whether a segment started at started holding chars characters
+// of text should be finalized at now. Segments without text never are.
+func (r Rollover) Due(started time.Time, chars int, now time.Time) bool {
+	if chars == 0 {
+		return false
+	}
+	if r.MaxChars > 0 && chars >= r.MaxChars {
+		return true
+	}
+	return r.MaxAge > 0 && !started.IsZero() && now.Sub(started) >= r.MaxAge
+}
@@ -0,0 +1,42 @@
+package session
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRolloverDue(t *testing.T) {
+	start := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	r := Rollover{MaxAge: 30 * time.Minute, MaxChars: 5000}
+
+	tests := []struct {
+		name  string
+		chars int
+		after time.Duration
+		due   bool
+	}{
+		{"short segment", 100, time.Minute, false},
+		{"too old", 100, 30 * time.Minute, true},
+		{"too long", 5000, time.Minute, true},
+		{"no text yet", 0, time.Hour, false},
+	}
+	for _, tt := range tests {
+		if got := r.Due(start, tt.chars, start.Add(tt.after)); got != tt.due {
+			t.Errorf("%s: expected due=%v, got %v", tt.name, tt.due, got)
+		}
+	}
+}
+
+func TestRolloverDisabled(t *testing.T) {
+	var r Rollover
+	if r.Enabled() {
+		t.Error("Expected a zero Rollover to be disabled")
+	}
+	start := time.Now()
+	if r.Due(start, 1_000_000, start.Add(24*time.Hour)) {
+		t.Error("Expected a disabled Rollover never to be due")
+	}
+	if !(Rollover{MaxChars: 10}).Enabled() {
+		t.Error("Expected a character limit to enable rollover")
+	}
+}
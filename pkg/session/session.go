@@ -0,0 +1,523 @@
+// Package session provides the transcript session model and its edit history
+package session
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// maxHistory is the maximum number of edits kept on the undo stack
+const maxHistory = 100
+
+var (
+	// ErrSegmentNotFound is returned when an edit refers to an unknown segment
+	ErrSegmentNotFound = errors.New("segment not found")
+	// ErrNoPreviousSegment is returned when merging the first segment
+	ErrNoPreviousSegment = errors.New("no previous segment to merge with")
+	// ErrInvalidSplit is returned when a split would leave an empty segment
+	ErrInvalidSplit = errors.New("split position must be inside the text")
+	// ErrSegmentChanged is returned when a revision is based on outdated text
+	ErrSegmentChanged = errors.New("segment text has changed")
+)
+
+// Segment is a single finalized piece of transcript text
+type Segment struct {
+	ID      int           `json:"id"`
+	Text    string        `json:"text"`
+	Audio   string        `json:"audio,omitempty"`   // Archived recording, if audio archiving was enabled
+	Start   time.Duration `json:"start,omitempty"`   // Offset into the recording, if known
+	End     time.Duration `json:"end,omitempty"`     // End offset into the recording, if known
+	Speaker string        `json:"speaker,omitempty"` // Speaker ID, see Session.Speakers
+	Words   []Word        `json:"words,omitempty"`   // Word-level timing, if known
+	Tags    []string      `json:"tags,omitempty"`    // Watched keywords heard in the segment
+
+	// Code of the language spoken, e.g. "fr", if it was detected
+	Language string `json:"language,omitempty"`
+
+	// Wall-clock time the segment was spoken, zero if unknown
+	StartedAt time.Time `json:"started_at"`
+	EndedAt   time.Time `json:"ended_at"`
+
+	// Time the speaker was heard during the segment, zero if unknown
+	Speech time.Duration `json:"speech,omitempty"`
+}
+
+// Word is a single word of a segment with its timing
+type Word struct {
+	Text       string        `json:"text"`
+	Start      time.Duration `json:"start"`
+	End        time.Duration `json:"end"`
+	Confidence float32       `json:"confidence,omitempty"`
+}
+
+// Speaker identifies a voice in the session
+type Speaker struct {
+	ID   string `json:"id"`
+	Name string `json:"name,omitempty"`
+}
+
+// EditKind describes what a recorded edit did to the segment list
+type EditKind string
+
+const (
+	// EditDelete removes a segment
+	EditDelete EditKind = "delete"
+	// EditUpdate replaces the text of a segment
+	EditUpdate EditKind = "update"
+	// EditMerge joins adjacent segments into one
+	EditMerge EditKind = "merge"
+	// EditSplit divides a segment into several
+	EditSplit EditKind = "split"
+	// EditInsert adds a segment after an existing one
+	EditInsert EditKind = "insert"
+)
+
+// Edit is a reversible change to the segment list. Applying it replaces the
+// Before segments starting at Index with the After segments; undoing it does
+// the reverse. Every kind of edit is expressed this way so the history can be
+// persisted and replayed without knowing about individual operations.
+type Edit struct {
+	Kind   EditKind  `json:"kind"`
+	Index  int       `json:"index"`
+	Before []Segment `json:"before"`
+	After  []Segment `json:"after"`
+	Time   time.Time `json:"time"`
+}
+
+// Session holds the finalized segments of a transcript and its undo/redo history
+type Session struct {
+	ID       string    `json:"id"`
+	Created  time.Time `json:"created"`
+	Segments []Segment `json:"segments"`
+	Undo     []Edit    `json:"undo"`
+	Redo     []Edit    `json:"redo"`
+	NextID   int       `json:"next_id"`
+	Spilled  int       `json:"spilled,omitempty"` // Oldest segments moved out of Segments by Store.Spill
+
+	Speakers []Speaker         `json:"speakers,omitempty"`
+	Metadata map[string]string `json:"metadata,omitempty"` // Free-form details such as the model used
+
+	mu sync.Mutex
+}
+
+// idLayout formats a session's creation time as its ID
+const idLayout = "20060102-150405.000"
+
+// New creates an empty session identified by its creation time
+func New() *Session {
+	now := time.Now()
+	return &Session{
+		ID:       now.Format(idLayout),
+		Created:  now,
+		Segments: make([]Segment, 0),
+		NextID:   1,
+	}
+}
+
+// ValidID reports whether id has the form of a session ID. IDs name session
+// files, so anything else, such as a path, must not be used as one.
+func ValidID(id string) bool {
+	created, err := time.Parse(idLayout, id)
+	return err == nil && created.Format(idLayout) == id
+}
+
+// Append adds a newly finalized segment and returns it.
+// Appends are not recorded in the edit history since they come from
+// transcription rather than from the user.
+func (s *Session) Append(text string) Segment {
+	return s.AppendWithAudio(text, "")
+}
+
+// AppendWithAudio adds a newly finalized segment along with the path of its archived audio
+func (s *Session) AppendWithAudio(text, audioPath string) Segment {
+	return s.AppendSegment(Segment{Text: text, Audio: audioPath})
+}
+
+// AppendSegment adds a finalized segment with its timing and speaker details,
+// assigning it a new ID, and returns it
+func (s *Session) AppendSegment(seg Segment) Segment {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	seg.ID = s.NextID
+	s.NextID++
+	s.Segments = append(s.Segments, seg)
+	return seg
+}
+
+// List returns a copy of the current segments
+func (s *Session) List() []Segment {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	segments := make([]Segment, len(s.Segments))
+	copy(segments, s.Segments)
+	return segments
+}
+
+// Len returns the number of segments, including spilled ones
+func (s *Session) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.Spilled + len(s.Segments)
+}
+
+// Texts returns the text of every segment in order
+func (s *Session) Texts() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	texts := make([]string, len(s.Segments))
+	for i, seg := range s.Segments {
+		texts[i] = seg.Text
+	}
+	return texts
+}
+
+// SetMeta sets a metadata value, such as a summary of the session; an empty
+// value removes it
+func (s *Session) SetMeta(key, value string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if value == "" {
+		delete(s.Metadata, key)
+		return
+	}
+	if s.Metadata == nil {
+		s.Metadata = make(map[string]string)
+	}
+	s.Metadata[key] = value
+}
+
+// Meta returns a metadata value, or "" if it isn't set
+func (s *Session) Meta(key string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.Metadata[key]
+}
+
+// Delete removes the segment with the given ID
+func (s *Session) Delete(id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.indexOf(id)
+	if i < 0 {
+		return ErrSegmentNotFound
+	}
+
+	s.record(Edit{Kind: EditDelete, Index: i, Before: s.Segments[i : i+1]})
+	return nil
+}
+
+// Update replaces the text of the segment with the given ID
+func (s *Session) Update(id int, text string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.indexOf(id)
+	if i < 0 {
+		return ErrSegmentNotFound
+	}
+	if s.Segments[i].Text == text {
+		return nil
+	}
+
+	updated := s.Segments[i]
+	updated.Text = text
+	s.record(Edit{Kind: EditUpdate, Index: i, Before: s.Segments[i : i+1], After: []Segment{updated}})
+	return nil
+}
+
+// Revise replaces the text of the segment with the given ID only if it is
+// still from, e.g. a more accurate transcript replacing live text the user
+// hasn't edited since
+func (s *Session) Revise(id int, from, to string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.indexOf(id)
+	if i < 0 {
+		return ErrSegmentNotFound
+	}
+	if s.Segments[i].Text != from {
+		return ErrSegmentChanged
+	}
+	if from == to {
+		return nil
+	}
+
+	updated := s.Segments[i]
+	updated.Text = to
+	s.record(Edit{Kind: EditUpdate, Index: i, Before: s.Segments[i : i+1], After: []Segment{updated}})
+	return nil
+}
+
+// SetAudio links the segment with the given ID to another recording, e.g. a
+// trimmed copy of its audio
+func (s *Session) SetAudio(id int, audioPath string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.indexOf(id)
+	if i < 0 {
+		return ErrSegmentNotFound
+	}
+	if s.Segments[i].Audio == audioPath {
+		return nil
+	}
+
+	updated := s.Segments[i]
+	updated.Audio = audioPath
+	s.record(Edit{Kind: EditUpdate, Index: i, Before: s.Segments[i : i+1], After: []Segment{updated}})
+	return nil
+}
+
+// InsertAfter adds a segment directly after the segment with the given ID, e.g.
+// an alternative transcript of the same audio, and returns the new segment
+func (s *Session) InsertAfter(id int, text, audioPath string) (Segment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.indexOf(id)
+	if i < 0 {
+		return Segment{}, ErrSegmentNotFound
+	}
+
+	seg := Segment{ID: s.NextID, Text: text, Audio: audioPath}
+	s.NextID++
+	s.record(Edit{Kind: EditInsert, Index: i + 1, After: []Segment{seg}})
+	return seg, nil
+}
+
+// MergeWithPrevious joins the segment with the given ID onto the end of the
+// segment before it. The merged segment keeps the previous segment's ID.
+func (s *Session) MergeWithPrevious(id int) (Segment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.indexOf(id)
+	if i < 0 {
+		return Segment{}, ErrSegmentNotFound
+	}
+	if i == 0 {
+		return Segment{}, ErrNoPreviousSegment
+	}
+
+	prev, cur := s.Segments[i-1], s.Segments[i]
+	merged := prev
+	merged.Text = strings.TrimSpace(prev.Text + " " + cur.Text)
+	merged.Words = append(append([]Word(nil), prev.Words...), cur.Words...)
+	if cur.End > merged.End {
+		merged.End = cur.End
+	}
+	if cur.EndedAt.After(merged.EndedAt) {
+		merged.EndedAt = cur.EndedAt
+	}
+	merged.Speech = prev.Speech + cur.Speech
+	// Segments from different recordings no longer map onto a single file
+	if cur.Audio != prev.Audio {
+		merged.Audio = ""
+	}
+	if cur.Speaker != prev.Speaker {
+		merged.Speaker = ""
+	}
+
+	s.record(Edit{Kind: EditMerge, Index: i - 1, Before: s.Segments[i-1 : i+1], After: []Segment{merged}})
+	return merged, nil
+}
+
+// Split divides the segment with the given ID at a byte offset into its text.
+// The first part keeps the ID and the second part gets a new one. Timings are
+// divided using word timings if known, or in proportion to the text otherwise.
+// Wall-clock times and speech are always divided in proportion to the text.
+func (s *Session) Split(id int, offset int) (Segment, Segment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.indexOf(id)
+	if i < 0 {
+		return Segment{}, Segment{}, ErrSegmentNotFound
+	}
+
+	seg := s.Segments[i]
+	if offset <= 0 || offset >= len(seg.Text) || !utf8.RuneStart(seg.Text[offset]) {
+		return Segment{}, Segment{}, ErrInvalidSplit
+	}
+	firstText := strings.TrimSpace(seg.Text[:offset])
+	secondText := strings.TrimSpace(seg.Text[offset:])
+	if firstText == "" || secondText == "" {
+		return Segment{}, Segment{}, ErrInvalidSplit
+	}
+
+	first, second := seg, seg
+	first.Text, second.Text = firstText, secondText
+	second.ID = s.NextID
+	first.Words, second.Words = splitWords(seg.Words, len(strings.Fields(firstText)))
+
+	// Pick the time both parts meet at
+	switch {
+	case len(first.Words) > 0 && len(second.Words) > 0:
+		first.End = first.Words[len(first.Words)-1].End
+		second.Start = second.Words[0].Start
+	case seg.End > seg.Start:
+		at := seg.Start + time.Duration(int64(seg.End-seg.Start)*int64(offset)/int64(len(seg.Text)))
+		first.End, second.Start = at, at
+	}
+	if !seg.StartedAt.IsZero() && seg.EndedAt.After(seg.StartedAt) {
+		at := seg.StartedAt.Add(time.Duration(int64(seg.EndedAt.Sub(seg.StartedAt)) * int64(offset) / int64(len(seg.Text))))
+		first.EndedAt, second.StartedAt = at, at
+	}
+	if seg.Speech > 0 {
+		first.Speech = time.Duration(int64(seg.Speech) * int64(offset) / int64(len(seg.Text)))
+		second.Speech = seg.Speech - first.Speech
+	}
+
+	s.NextID++
+	s.record(Edit{Kind: EditSplit, Index: i, Before: s.Segments[i : i+1], After: []Segment{first, second}})
+	return first, second, nil
+}
+
+// splitWords divides word timings after the first n words
+func splitWords(words []Word, n int) ([]Word, []Word) {
+	if len(words) == 0 {
+		return nil, nil
+	}
+	if n > len(words) {
+		n = len(words)
+	}
+	return append([]Word(nil), words[:n]...), append([]Word(nil), words[n:]...)
+}
+
+// CanUndo reports whether there is an edit to undo
+func (s *Session) CanUndo() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.Undo) > 0
+}
+
+// CanRedo reports whether there is an undone edit to redo
+func (s *Session) CanRedo() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.Redo) > 0
+}
+
+// UndoLast reverts the most recent edit. It returns false if there was nothing to undo.
+func (s *Session) UndoLast() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.Undo) == 0 {
+		return false
+	}
+
+	edit := s.Undo[len(s.Undo)-1]
+	s.Undo = s.Undo[:len(s.Undo)-1]
+	s.replace(edit.Index, len(edit.After), edit.Before)
+	s.Redo = append(s.Redo, edit)
+	return true
+}
+
+// RedoLast re-applies the most recently undone edit. It returns false if there was nothing to redo.
+func (s *Session) RedoLast() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.Redo) == 0 {
+		return false
+	}
+
+	edit := s.Redo[len(s.Redo)-1]
+	s.Redo = s.Redo[:len(s.Redo)-1]
+	s.replace(edit.Index, len(edit.Before), edit.After)
+	s.Undo = append(s.Undo, edit)
+	return true
+}
+
+// record applies a new edit and pushes it on the undo stack.
+// The caller must hold s.mu.
+func (s *Session) record(edit Edit) {
+	// Keep our own copies so later changes to the segment list don't alter history
+	edit.Before = append([]Segment(nil), edit.Before...)
+	edit.After = append([]Segment(nil), edit.After...)
+	edit.Time = time.Now()
+
+	s.replace(edit.Index, len(edit.Before), edit.After)
+
+	s.Undo = append(s.Undo, edit)
+	if len(s.Undo) > maxHistory {
+		s.Undo = s.Undo[len(s.Undo)-maxHistory:]
+	}
+
+	// A new edit invalidates anything that was undone
+	s.Redo = s.Redo[:0]
+}
+
+// replace swaps count segments starting at index for the given segments.
+// The caller must hold s.mu.
+func (s *Session) replace(index, count int, with []Segment) {
+	if index > len(s.Segments) {
+		index = len(s.Segments)
+	}
+	if index+count > len(s.Segments) {
+		count = len(s.Segments) - index
+	}
+
+	tail := append([]Segment(nil), s.Segments[index+count:]...)
+	s.Segments = append(append(s.Segments[:index], with...), tail...)
+}
+
+// dropOldest removes the n oldest segments from memory once they have been
+// spilled. Edits touching them can no longer be undone or redone, so history
+// is cut back to the edits after the spilled segments, which are shifted to
+// stay in place. The caller must hold s.mu.
+func (s *Session) dropOldest(n int) {
+	if n <= 0 {
+		return
+	}
+	n = min(n, len(s.Segments))
+
+	s.Segments = append([]Segment(nil), s.Segments[n:]...)
+	s.Spilled += n
+
+	// Undo entries apply in order, so keep only the newest run that stays
+	// clear of the spilled segments
+	keep := len(s.Undo)
+	for keep > 0 && s.Undo[keep-1].Index >= n {
+		keep--
+	}
+	s.Undo = shiftEdits(s.Undo[keep:], n)
+	for _, edit := range s.Redo {
+		if edit.Index < n {
+			s.Redo = s.Redo[:0]
+			break
+		}
+	}
+	s.Redo = shiftEdits(s.Redo, n)
+}
+
+// shiftEdits returns copies of the edits with their index moved back by n
+func shiftEdits(edits []Edit, n int) []Edit {
+	shifted := make([]Edit, len(edits))
+	for i, edit := range edits {
+		edit.Index -= n
+		shifted[i] = edit
+	}
+	return shifted
+}
+
+// indexOf returns the position of the segment with the given ID, or -1.
+// The caller must hold s.mu.
+func (s *Session) indexOf(id int) int {
+	for i, seg := range s.Segments {
+		if seg.ID == id {
+			return i
+		}
+	}
+	return -1
+}
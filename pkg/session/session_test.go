@@ -0,0 +1,645 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDeleteUndoRedo(t *testing.T) {
+	s := New()
+	s.Append("first")
+	second := s.Append("second")
+	s.Append("third")
+
+	if err := s.Delete(second.ID); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if got := s.Texts(); !reflect.DeepEqual(got, []string{"first", "third"}) {
+		t.Errorf("Expected [first third] after delete, got %v", got)
+	}
+
+	if !s.UndoLast() {
+		t.Fatal("Expected undo to succeed")
+	}
+	if got := s.Texts(); !reflect.DeepEqual(got, []string{"first", "second", "third"}) {
+		t.Errorf("Expected [first second third] after undo, got %v", got)
+	}
+
+	if !s.RedoLast() {
+		t.Fatal("Expected redo to succeed")
+	}
+	if got := s.Texts(); !reflect.DeepEqual(got, []string{"first", "third"}) {
+		t.Errorf("Expected [first third] after redo, got %v", got)
+	}
+}
+
+func TestUpdateClearsRedo(t *testing.T) {
+	s := New()
+	seg := s.Append("helo")
+
+	if err := s.Update(seg.ID, "hello"); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	s.UndoLast()
+	if got := s.Texts(); !reflect.DeepEqual(got, []string{"helo"}) {
+		t.Errorf("Expected [helo] after undo, got %v", got)
+	}
+
+	// A new edit after undo discards the redo stack
+	if err := s.Update(seg.ID, "hullo"); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if s.CanRedo() {
+		t.Error("Expected redo stack to be cleared by a new edit")
+	}
+}
+
+func TestUndoAfterAppend(t *testing.T) {
+	s := New()
+	first := s.Append("first")
+	s.Delete(first.ID)
+
+	// Segments finalized after an edit must survive undoing it
+	s.Append("second")
+	s.UndoLast()
+
+	if got := s.Texts(); !reflect.DeepEqual(got, []string{"first", "second"}) {
+		t.Errorf("Expected [first second], got %v", got)
+	}
+}
+
+func TestInsertAfterUndo(t *testing.T) {
+	s := New()
+	first := s.Append("first")
+	s.Append("last")
+
+	if _, err := s.InsertAfter(first.ID, "better first", "take.wav"); err != nil {
+		t.Fatalf("InsertAfter failed: %v", err)
+	}
+	if got := s.Texts(); !reflect.DeepEqual(got, []string{"first", "better first", "last"}) {
+		t.Errorf("Expected inserted segment after first, got %v", got)
+	}
+
+	s.UndoLast()
+	if got := s.Texts(); !reflect.DeepEqual(got, []string{"first", "last"}) {
+		t.Errorf("Expected insert to be undone, got %v", got)
+	}
+}
+
+func TestReviseOnlyUnchangedText(t *testing.T) {
+	s := New()
+	seg := s.Append("live text")
+
+	if err := s.Revise(seg.ID, "live text", "Accurate text."); err != nil {
+		t.Fatalf("Revise failed: %v", err)
+	}
+	if err := s.Revise(seg.ID, "live text", "Other text."); err != ErrSegmentChanged {
+		t.Errorf("Expected ErrSegmentChanged for outdated text, got %v", err)
+	}
+	if got := s.Texts(); !reflect.DeepEqual(got, []string{"Accurate text."}) {
+		t.Errorf("Expected the revised text, got %v", got)
+	}
+
+	s.UndoLast()
+	if got := s.Texts(); !reflect.DeepEqual(got, []string{"live text"}) {
+		t.Errorf("Expected revision to be undone, got %v", got)
+	}
+}
+
+func TestSetAudioUndo(t *testing.T) {
+	s := New()
+	seg := s.AppendWithAudio("hello", "/archive/full.wav")
+
+	if err := s.SetAudio(seg.ID, "/archive/full-trimmed.wav"); err != nil {
+		t.Fatalf("SetAudio failed: %v", err)
+	}
+	if got := s.List()[0].Audio; got != "/archive/full-trimmed.wav" {
+		t.Errorf("Expected the trimmed audio, got %q", got)
+	}
+
+	s.UndoLast()
+	if got := s.List()[0].Audio; got != "/archive/full.wav" {
+		t.Errorf("Expected the original audio after undo, got %q", got)
+	}
+	if err := s.SetAudio(99, "/archive/other.wav"); err != ErrSegmentNotFound {
+		t.Errorf("Expected ErrSegmentNotFound, got %v", err)
+	}
+}
+
+func TestMeta(t *testing.T) {
+	s := New()
+	s.SetMeta("summary", "Short meeting.")
+	if got := s.Meta("summary"); got != "Short meeting." {
+		t.Errorf("Expected the stored summary, got %q", got)
+	}
+	s.SetMeta("summary", "")
+	if _, ok := s.Metadata["summary"]; ok {
+		t.Error("Expected an empty value to remove the key")
+	}
+}
+
+func TestDeleteUnknownSegment(t *testing.T) {
+	s := New()
+	if err := s.Delete(42); err != ErrSegmentNotFound {
+		t.Errorf("Expected ErrSegmentNotFound, got %v", err)
+	}
+	if s.CanUndo() {
+		t.Error("Failed edit should not be recorded")
+	}
+}
+
+func TestStorePersistsHistory(t *testing.T) {
+	store, err := NewStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewStore failed: %v", err)
+	}
+
+	s := New()
+	s.Append("keep me")
+	doomed := s.Append("delete me")
+	s.Delete(doomed.ID)
+
+	if err := store.Save(s); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	// Simulate a restart by loading the latest session
+	restored, err := store.Latest()
+	if err != nil {
+		t.Fatalf("Latest failed: %v", err)
+	}
+	if restored == nil || restored.ID != s.ID {
+		t.Fatalf("Expected session %s to be restored", s.ID)
+	}
+
+	// The deletion made before "closing" can still be undone
+	if !restored.UndoLast() {
+		t.Fatal("Expected restored session to have undo history")
+	}
+	if got := restored.Texts(); !reflect.DeepEqual(got, []string{"keep me", "delete me"}) {
+		t.Errorf("Expected deleted segment to be recovered, got %v", got)
+	}
+
+	// New segments must not reuse IDs from the saved session
+	if next := restored.Append("new"); next.ID <= doomed.ID {
+		t.Errorf("Expected new ID greater than %d, got %d", doomed.ID, next.ID)
+	}
+}
+
+func TestStoreSpill(t *testing.T) {
+	store, err := NewStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewStore failed: %v", err)
+	}
+
+	s := New()
+	var segments []Segment
+	for _, text := range []string{"one", "two", "three", "four", "five", "six"} {
+		segments = append(segments, s.Append(text))
+	}
+	s.Delete(segments[1].ID)
+	s.Update(segments[4].ID, "FIVE")
+
+	if err := store.Spill(s, 3); err != nil {
+		t.Fatalf("Spill failed: %v", err)
+	}
+	if got := s.Texts(); !reflect.DeepEqual(got, []string{"four", "FIVE", "six"}) {
+		t.Errorf("Expected only the newest segments in memory, got %v", got)
+	}
+	if s.Len() != 5 {
+		t.Errorf("Expected 5 segments in total, got %d", s.Len())
+	}
+
+	// The delete touched a spilled segment, the update did not
+	if !s.UndoLast() || !reflect.DeepEqual(s.Texts(), []string{"four", "five", "six"}) {
+		t.Errorf("Expected the update to be undone in place, got %v", s.Texts())
+	}
+	if s.UndoLast() {
+		t.Error("Expected the delete of a spilled segment to be dropped from history")
+	}
+
+	s.Append("seven")
+	if err := store.Spill(s, 2); err != nil {
+		t.Fatalf("Spill failed: %v", err)
+	}
+	all, err := store.Segments(s)
+	if err != nil {
+		t.Fatalf("Segments failed: %v", err)
+	}
+	var texts []string
+	for _, seg := range all {
+		texts = append(texts, seg.Text)
+	}
+	want := []string{"one", "three", "four", "five", "six", "seven"}
+	if !reflect.DeepEqual(texts, want) {
+		t.Errorf("Expected %v from the store, got %v", want, texts)
+	}
+
+	// Loading brings every segment back into memory
+	loaded, err := store.Load(s.ID)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if !reflect.DeepEqual(loaded.Texts(), want) || loaded.Spilled != 0 {
+		t.Errorf("Expected %v after loading, got %v with %d spilled", want, loaded.Texts(), loaded.Spilled)
+	}
+	if err := store.Spill(loaded, 1); err != nil {
+		t.Fatalf("Spill failed: %v", err)
+	}
+	if all, _ := store.Segments(loaded); len(all) != len(want) {
+		t.Errorf("Expected spilling a loaded session to start a new segments file, got %d segments", len(all))
+	}
+}
+
+func TestStoreFilter(t *testing.T) {
+	store, err := NewStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewStore failed: %v", err)
+	}
+	store.SetFilter(func(text string) string { return "[filtered]" })
+
+	s := New()
+	seg := s.Append("secret")
+	s.Update(seg.ID, "also secret")
+	if err := store.Save(s); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	// The in-memory session is untouched
+	if got := s.Texts(); !reflect.DeepEqual(got, []string{"also secret"}) {
+		t.Errorf("Expected in-memory text unchanged, got %v", got)
+	}
+
+	// Neither the segments nor the undo history are saved unfiltered
+	restored, err := store.Load(s.ID)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if got := restored.Texts(); !reflect.DeepEqual(got, []string{"[filtered]"}) {
+		t.Errorf("Expected filtered segment, got %v", got)
+	}
+	restored.UndoLast()
+	if got := restored.Texts(); !reflect.DeepEqual(got, []string{"[filtered]"}) {
+		t.Errorf("Expected filtered undo history, got %v", got)
+	}
+}
+
+func TestExportImportRoundTrip(t *testing.T) {
+	s := New()
+	s.Speakers = []Speaker{{ID: "S1", Name: "Host"}}
+	s.Metadata = map[string]string{"model": "tiny"}
+	seg := s.AppendWithAudio("hello world", "take.wav")
+	s.Segments[0].Speaker = "S1"
+	s.Segments[0].Tags = []string{"action item"}
+	s.Segments[0].Language = "en"
+	s.Segments[0].Start = 1500 * time.Millisecond
+	s.Segments[0].End = 2500 * time.Millisecond
+	s.Segments[0].StartedAt = time.Date(2025, 3, 1, 10, 15, 1, 0, time.UTC)
+	s.Segments[0].EndedAt = time.Date(2025, 3, 1, 10, 15, 3, 0, time.UTC)
+	s.Segments[0].Words = []Word{
+		{Text: "hello", Start: 1500 * time.Millisecond, End: 2000 * time.Millisecond, Confidence: 0.9},
+		{Text: "world", Start: 2000 * time.Millisecond, End: 2500 * time.Millisecond, Confidence: 0.8},
+	}
+
+	data, err := ExportJSON(s)
+	if err != nil {
+		t.Fatalf("ExportJSON failed: %v", err)
+	}
+	imported, err := ImportJSON(data)
+	if err != nil {
+		t.Fatalf("ImportJSON failed: %v", err)
+	}
+
+	if imported.ID != s.ID || !imported.Created.Equal(s.Created) {
+		t.Errorf("Expected session %s to keep its identity, got %s", s.ID, imported.ID)
+	}
+	if !reflect.DeepEqual(imported.Segments, s.Segments) {
+		t.Errorf("Segments changed in round trip:\n got %+v\nwant %+v", imported.Segments, s.Segments)
+	}
+	if !reflect.DeepEqual(imported.Speakers, s.Speakers) || !reflect.DeepEqual(imported.Metadata, s.Metadata) {
+		t.Errorf("Speakers or metadata changed in round trip")
+	}
+	if next := imported.Append("next"); next.ID <= seg.ID {
+		t.Errorf("Expected new ID greater than %d, got %d", seg.ID, next.ID)
+	}
+}
+
+func TestImportSessionFile(t *testing.T) {
+	// Session store files predate the export format and import as version 0
+	data := []byte(`{
+		"id": "20250101-120000.000",
+		"created": "2025-01-01T12:00:00Z",
+		"segments": [{"id": 3, "text": "from the store", "audio": "a.wav"}],
+		"undo": [], "redo": [], "next_id": 4
+	}`)
+
+	imported, err := ImportJSON(data)
+	if err != nil {
+		t.Fatalf("ImportJSON failed: %v", err)
+	}
+	if got := imported.Texts(); !reflect.DeepEqual(got, []string{"from the store"}) {
+		t.Errorf("Expected store segment, got %v", got)
+	}
+	if imported.Segments[0].ID != 3 || imported.Segments[0].Audio != "a.wav" {
+		t.Errorf("Expected segment ID and audio kept, got %+v", imported.Segments[0])
+	}
+}
+
+func TestImportRejectsUnknownFiles(t *testing.T) {
+	tests := map[string]string{
+		"newer version": `{"schema": "ramble.session", "version": 99, "segments": []}`,
+		"other schema":  `{"schema": "something.else", "version": 1, "segments": []}`,
+		"not a session": `{"hello": "world"}`,
+	}
+	for name, data := range tests {
+		if _, err := ImportJSON([]byte(data)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestImportRejectsUnsafeIDs(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "sessions")
+	store, err := NewStore(dir)
+	if err != nil {
+		t.Fatalf("NewStore failed: %v", err)
+	}
+
+	for _, id := range []string{"../../escaped", "20250101-120000.000/../x", `..\\x`, "notes"} {
+		data := []byte(`{"schema": "ramble.session", "version": 1, "id": "` + id + `", "segments": []}`)
+		imported, err := ImportJSON(data)
+		if err != nil {
+			t.Fatalf("ImportJSON failed: %v", err)
+		}
+		if imported.ID == id || !ValidID(imported.ID) {
+			t.Errorf("Expected %q to be replaced by a new ID, got %q", id, imported.ID)
+		}
+
+		// The store doesn't trust IDs from other sources either
+		imported.ID = id
+		if err := store.Import(imported); err != nil {
+			t.Fatalf("Import failed: %v", err)
+		}
+		if _, err := os.Stat(filepath.Join(dir, imported.ID+".json")); err != nil {
+			t.Errorf("Expected %q to be saved in the session directory: %v", id, err)
+		}
+	}
+
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("Expected nothing written outside the session directory, got %d entries", len(entries))
+	}
+}
+
+func TestImportIgnoresUnknownFields(t *testing.T) {
+	data := []byte(`{
+		"schema": "ramble.session", "version": 1, "future_field": {"x": 1},
+		"segments": [{"id": 1, "text": "still readable", "sentiment": "happy"}]
+	}`)
+	imported, err := ImportJSON(data)
+	if err != nil {
+		t.Fatalf("ImportJSON failed: %v", err)
+	}
+	if got := imported.Texts(); !reflect.DeepEqual(got, []string{"still readable"}) {
+		t.Errorf("Expected segment text, got %v", got)
+	}
+}
+
+func TestAppendSegment(t *testing.T) {
+	s := New()
+	s.Append("first")
+	seg := s.AppendSegment(Segment{ID: 99, Text: "second", Start: time.Second, End: 2 * time.Second, Speaker: "S1"})
+
+	if seg.ID != 2 {
+		t.Errorf("Expected AppendSegment to assign ID 2, got %d", seg.ID)
+	}
+	if got := s.List()[1]; got.Start != time.Second || got.End != 2*time.Second || got.Speaker != "S1" {
+		t.Errorf("Expected timing and speaker kept, got %+v", got)
+	}
+	if s.CanUndo() {
+		t.Error("Expected appends not to be recorded in the edit history")
+	}
+}
+
+func TestStoreSearch(t *testing.T) {
+	store, err := NewStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewStore failed: %v", err)
+	}
+
+	older := New()
+	older.ID = "20250101-090000.000"
+	older.Append("Call the Dentist on Monday")
+	older.Append("buy milk")
+	newer := New()
+	newer.ID = "20250102-090000.000"
+	newer.Append("dentist moved to Tuesday")
+	for _, s := range []*Session{older, newer} {
+		if err := store.Save(s); err != nil {
+			t.Fatalf("Save failed: %v", err)
+		}
+	}
+
+	results, err := store.Search("DENTIST", 0)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(results) != 2 || results[0].SessionID != newer.ID || results[1].Segment.Text != "Call the Dentist on Monday" {
+		t.Errorf("Expected both matches newest first, got %+v", results)
+	}
+
+	if results, _ := store.Search("dentist", 1); len(results) != 1 {
+		t.Errorf("Expected limit to apply, got %d results", len(results))
+	}
+	if results, _ := store.Search("  ", 0); len(results) != 0 {
+		t.Errorf("Expected no results for an empty query, got %d", len(results))
+	}
+}
+
+func TestMergeWithPrevious(t *testing.T) {
+	s := New()
+	spoken := time.Date(2025, 3, 1, 10, 17, 0, 0, time.UTC)
+	first := s.AppendSegment(Segment{Text: "The quick brown", Start: 0, End: time.Second, Audio: "a.wav",
+		StartedAt: spoken, EndedAt: spoken.Add(time.Second)})
+	second := s.AppendSegment(Segment{Text: "fox jumps.", Start: time.Second, End: 2 * time.Second, Audio: "a.wav",
+		StartedAt: spoken.Add(time.Second), EndedAt: spoken.Add(2 * time.Second)})
+
+	if _, err := s.MergeWithPrevious(first.ID); err != ErrNoPreviousSegment {
+		t.Errorf("Expected ErrNoPreviousSegment, got %v", err)
+	}
+
+	merged, err := s.MergeWithPrevious(second.ID)
+	if err != nil {
+		t.Fatalf("MergeWithPrevious failed: %v", err)
+	}
+	if merged.ID != first.ID || merged.Text != "The quick brown fox jumps." || merged.End != 2*time.Second || merged.Audio != "a.wav" {
+		t.Errorf("Unexpected merged segment %+v", merged)
+	}
+	if !merged.StartedAt.Equal(spoken) || !merged.EndedAt.Equal(spoken.Add(2*time.Second)) {
+		t.Errorf("Expected the merged segment to span both, got %v to %v", merged.StartedAt, merged.EndedAt)
+	}
+	if got := s.Texts(); !reflect.DeepEqual(got, []string{"The quick brown fox jumps."}) {
+		t.Errorf("Expected one merged segment, got %v", got)
+	}
+
+	s.UndoLast()
+	if got := s.Texts(); !reflect.DeepEqual(got, []string{"The quick brown", "fox jumps."}) {
+		t.Errorf("Expected undo to restore both segments, got %v", got)
+	}
+}
+
+func TestSplit(t *testing.T) {
+	s := New()
+	spoken := time.Date(2025, 3, 1, 10, 17, 0, 0, time.UTC)
+	seg := s.AppendSegment(Segment{Text: "Hello there. How are you?", Start: 0, End: 5 * time.Second,
+		StartedAt: spoken, EndedAt: spoken.Add(5 * time.Second)})
+
+	for _, offset := range []int{0, -1, len(seg.Text), 5 + len(seg.Text)} {
+		if _, _, err := s.Split(seg.ID, offset); err != ErrInvalidSplit {
+			t.Errorf("Split at %d: expected ErrInvalidSplit, got %v", offset, err)
+		}
+	}
+
+	first, second, err := s.Split(seg.ID, len("Hello there."))
+	if err != nil {
+		t.Fatalf("Split failed: %v", err)
+	}
+	if first.ID != seg.ID || second.ID == seg.ID {
+		t.Errorf("Expected first part to keep ID %d, got %d and %d", seg.ID, first.ID, second.ID)
+	}
+	if first.Text != "Hello there." || second.Text != "How are you?" {
+		t.Errorf("Unexpected split texts %q and %q", first.Text, second.Text)
+	}
+	if first.End != second.Start || first.End <= 0 || first.End >= 5*time.Second {
+		t.Errorf("Expected parts to meet inside the segment, got %v and %v", first.End, second.Start)
+	}
+	if !first.EndedAt.Equal(second.StartedAt) || !first.EndedAt.After(spoken) || !second.EndedAt.Equal(seg.EndedAt) {
+		t.Errorf("Expected wall-clock times to be divided, got %v and %v", first.EndedAt, second.StartedAt)
+	}
+
+	s.UndoLast()
+	if got := s.Texts(); !reflect.DeepEqual(got, []string{"Hello there. How are you?"}) {
+		t.Errorf("Expected undo to restore the segment, got %v", got)
+	}
+}
+
+func TestSplitWords(t *testing.T) {
+	s := New()
+	seg := s.AppendSegment(Segment{
+		Text: "one two three",
+		End:  3 * time.Second,
+		Words: []Word{
+			{Text: "one", Start: 0, End: time.Second},
+			{Text: "two", Start: time.Second, End: 2 * time.Second},
+			{Text: "three", Start: 2500 * time.Millisecond, End: 3 * time.Second},
+		},
+	})
+
+	first, second, err := s.Split(seg.ID, len("one two"))
+	if err != nil {
+		t.Fatalf("Split failed: %v", err)
+	}
+	if len(first.Words) != 2 || len(second.Words) != 1 {
+		t.Fatalf("Expected words divided 2/1, got %d/%d", len(first.Words), len(second.Words))
+	}
+	if first.End != 2*time.Second || second.Start != 2500*time.Millisecond {
+		t.Errorf("Expected timings from words, got %v and %v", first.End, second.Start)
+	}
+}
+
+func TestRenderTranscriptMarkdown(t *testing.T) {
+	s := New()
+	s.Created = time.Date(2025, 3, 1, 10, 15, 0, 0, time.UTC)
+	s.Metadata = map[string]string{"title": "Weekly sync"}
+	s.Speakers = []Speaker{{ID: "S1", Name: "Host"}}
+	s.AppendSegment(Segment{
+		Text:    "welcome back",
+		Start:   65 * time.Second,
+		End:     67 * time.Second,
+		Speaker: "S1",
+		Words: []Word{
+			{Text: "welcome", Confidence: 0.9},
+			{Text: " back", Confidence: 0.3},
+		},
+	})
+
+	data, err := RenderTranscript(s, FormatMarkdown)
+	if err != nil {
+		t.Fatalf("RenderTranscript failed: %v", err)
+	}
+
+	expected := "# Weekly sync\n\n" +
+		"Recorded Saturday, 1 March 2025 10:15\n" +
+		"\n## 00:01:05 - Host\n\nwelcome _back_\n" +
+		"\n---\n\n_Words in italics were transcribed with low confidence._\n"
+	if string(data) != expected {
+		t.Errorf("Unexpected Markdown:\n%s", data)
+	}
+}
+
+func TestRenderTranscriptText(t *testing.T) {
+	s := New()
+	s.Append("first")
+	s.Append("second")
+
+	data, err := RenderTranscript(s, FormatText)
+	if err != nil {
+		t.Fatalf("RenderTranscript failed: %v", err)
+	}
+
+	// Without timing there are no timestamps
+	if !strings.HasSuffix(string(data), "\nfirst\n\nsecond\n") {
+		t.Errorf("Unexpected text:\n%s", data)
+	}
+
+	// Live recordings show the time of day they were spoken
+	s.AppendSegment(Segment{Text: "third", StartedAt: time.Date(2025, 3, 1, 10, 17, 42, 0, time.UTC)})
+	data, _ = RenderTranscript(s, FormatText)
+	if !strings.HasSuffix(string(data), "\nsecond\n\n[10:17:42] third\n") {
+		t.Errorf("Expected the time of day:\n%s", data)
+	}
+
+	// Detected languages are shown as badges
+	s.AppendSegment(Segment{Text: "quatre", Language: "fr"})
+	data, _ = RenderTranscript(s, FormatText)
+	if !strings.HasSuffix(string(data), "\n[FR] quatre\n") {
+		t.Errorf("Expected a language badge:\n%s", data)
+	}
+}
+
+func TestRenderTranscriptHTML(t *testing.T) {
+	s := New()
+	s.AppendSegment(Segment{
+		Text:  "a < b",
+		Words: []Word{{Text: "stale", Confidence: 0.1}}, // Left behind by an edit
+	})
+
+	data, err := RenderTranscript(s, FormatHTML)
+	if err != nil {
+		t.Fatalf("RenderTranscript failed: %v", err)
+	}
+	if !strings.Contains(string(data), "<p>a &lt; b</p>") {
+		t.Errorf("Expected escaped text without stale words:\n%s", data)
+	}
+}
+
+func TestParseTranscriptFormat(t *testing.T) {
+	for name, want := range map[string]TranscriptFormat{
+		"md": FormatMarkdown, ".MD": FormatMarkdown, "text": FormatText, "htm": FormatHTML,
+	} {
+		if got, err := ParseTranscriptFormat(name); err != nil || got != want {
+			t.Errorf("ParseTranscriptFormat(%q) = %q, %v; want %q", name, got, err, want)
+		}
+	}
+	if _, err := ParseTranscriptFormat("pdf"); err == nil {
+		t.Error("Expected an error for an unknown format")
+	}
+}
@@ -0,0 +1,105 @@
+package session
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Metadata keys holding the statistics saved by SetStats
+const (
+	MetaStatsWords          = "stats_words"
+	MetaStatsWordsPerMinute = "stats_words_per_minute"
+	MetaStatsDuration       = "stats_duration_seconds"
+	MetaStatsSpeech         = "stats_speech_seconds"
+	MetaStatsPauses         = "stats_pause_seconds"
+	MetaStatsLongest        = "stats_longest_utterance_seconds"
+)
+
+// minRateSpeech is the least speech a speaking rate is given for, since a
+// few words in a second or two would give a meaningless rate
+const minRateSpeech = 5 * time.Second
+
+// Stats describes how a session was spoken
+type Stats struct {
+	Words    int
+	Duration time.Duration // Time recorded
+	Speech   time.Duration // Time the speaker was heard
+	Pauses   time.Duration // Time recorded without speech
+	Longest  time.Duration // Speech in the longest segment
+}
+
+// ComputeStats returns the statistics of the given segments. Segments
+// without measured speech, such as those of transcribed files, count as
+// speech throughout.
+func ComputeStats(segments []Segment) Stats {
+	var stats Stats
+	for _, seg := range segments {
+		stats.Words += len(strings.Fields(seg.Text))
+
+		length := seg.length()
+		speech := seg.Speech
+		if speech <= 0 || (length > 0 && speech > length) {
+			speech = length
+		}
+		stats.Duration += length
+		stats.Speech += speech
+		stats.Longest = max(stats.Longest, speech)
+	}
+	stats.Pauses = stats.Duration - stats.Speech
+	return stats
+}
+
+// WordsPerMinute returns the speaking rate, or 0 if too little speech was
+// heard to tell
+func (s Stats) WordsPerMinute() float64 {
+	if s.Speech < minRateSpeech {
+		return 0
+	}
+	return float64(s.Words) / s.Speech.Minutes()
+}
+
+// length returns how long the segment took to say, from its wall-clock
+// times if known or its position in the recording otherwise
+func (seg Segment) length() time.Duration {
+	if !seg.StartedAt.IsZero() && seg.EndedAt.After(seg.StartedAt) {
+		return seg.EndedAt.Sub(seg.StartedAt)
+	}
+	if seg.End > seg.Start {
+		return seg.End - seg.Start
+	}
+	return 0
+}
+
+// SetStats saves statistics in the session's metadata, so they are kept in
+// the session history and exported with the session
+func (s *Session) SetStats(stats Stats) {
+	seconds := func(d time.Duration) string {
+		return strconv.FormatFloat(d.Seconds(), 'f', 1, 64)
+	}
+	s.SetMeta(MetaStatsWords, strconv.Itoa(stats.Words))
+	s.SetMeta(MetaStatsWordsPerMinute, strconv.FormatFloat(stats.WordsPerMinute(), 'f', 0, 64))
+	s.SetMeta(MetaStatsDuration, seconds(stats.Duration))
+	s.SetMeta(MetaStatsSpeech, seconds(stats.Speech))
+	s.SetMeta(MetaStatsPauses, seconds(stats.Pauses))
+	s.SetMeta(MetaStatsLongest, seconds(stats.Longest))
+}
+
+// Stats returns the statistics saved by SetStats, and false if there are none
+func (s *Session) Stats() (Stats, bool) {
+	words, err := strconv.Atoi(s.Meta(MetaStatsWords))
+	if err != nil {
+		return Stats{}, false
+	}
+	seconds := func(key string) time.Duration {
+		value, _ := strconv.ParseFloat(s.Meta(key), 64)
+		return time.Duration(value * float64(time.Second))
+	}
+	return Stats{
+		Words:    words,
+		Duration: seconds(MetaStatsDuration),
+		Speech:   seconds(MetaStatsSpeech),
+		Pauses:   seconds(MetaStatsPauses),
+		Longest:  seconds(MetaStatsLongest),
+	}, true
+}
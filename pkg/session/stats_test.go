@@ -0,0 +1,62 @@
+package session
+
+import (
+	"testing"
+	"time"
+)
+
+// TestComputeStats tests that speech, pauses and the speaking rate come from
+// the measured speech of each segment
+func TestComputeStats(t *testing.T) {
+	start := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	segments := []Segment{
+		{Text: "one two three four", StartedAt: start, EndedAt: start.Add(10 * time.Second), Speech: 6 * time.Second},
+		{Text: "five six", StartedAt: start.Add(10 * time.Second), EndedAt: start.Add(30 * time.Second), Speech: 14 * time.Second},
+		// Speech is never more than the segment took
+		{Text: "seven", StartedAt: start.Add(30 * time.Second), EndedAt: start.Add(40 * time.Second), Speech: time.Minute},
+		// Without measured speech the whole segment counts
+		{Text: "eight nine", Start: 0, End: 20 * time.Second},
+	}
+
+	stats := ComputeStats(segments)
+	if stats.Words != 9 {
+		t.Errorf("Expected 9 words, got %d", stats.Words)
+	}
+	if stats.Duration != time.Minute {
+		t.Errorf("Expected 1m recorded, got %v", stats.Duration)
+	}
+	if stats.Speech != 50*time.Second {
+		t.Errorf("Expected 50s of speech, got %v", stats.Speech)
+	}
+	if stats.Pauses != 10*time.Second {
+		t.Errorf("Expected 10s of pauses, got %v", stats.Pauses)
+	}
+	if stats.Longest != 20*time.Second {
+		t.Errorf("Expected a longest utterance of 20s, got %v", stats.Longest)
+	}
+	if wpm := stats.WordsPerMinute(); wpm < 10.79 || wpm > 10.81 {
+		t.Errorf("Expected 10.8 words per minute, got %v", wpm)
+	}
+
+	if wpm := ComputeStats(nil).WordsPerMinute(); wpm != 0 {
+		t.Errorf("Expected no rate without speech, got %v", wpm)
+	}
+}
+
+// TestSessionStats tests that statistics survive the session's metadata
+func TestSessionStats(t *testing.T) {
+	s := New()
+	if _, ok := s.Stats(); ok {
+		t.Error("Expected no statistics in a new session")
+	}
+
+	want := Stats{Words: 120, Duration: 90 * time.Second, Speech: 60 * time.Second, Pauses: 30 * time.Second, Longest: 12500 * time.Millisecond}
+	s.SetStats(want)
+	got, ok := s.Stats()
+	if !ok || got != want {
+		t.Errorf("Expected %+v, got %+v", want, got)
+	}
+	if wpm := s.Meta(MetaStatsWordsPerMinute); wpm != "120" {
+		t.Errorf("Expected 120 words per minute saved, got %q", wpm)
+	}
+}
@@ -0,0 +1,400 @@
+package session
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jeff-barlow-spady/ramble/pkg/config"
+)
+
+// Store persists sessions as JSON files in a directory
+type Store struct {
+	dir    string
+	filter func(string) string // Applied to segment text when saving, e.g. for redaction
+}
+
+// NewStore creates a store backed by the given directory
+func NewStore(dir string) (*Store, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create session directory: %w", err)
+	}
+	return &Store{dir: dir}, nil
+}
+
+// DefaultStore returns a store in the .ramble sessions directory
+func DefaultStore() (*Store, error) {
+	dir, err := config.GetSessionDir()
+	if err != nil {
+		return nil, err
+	}
+	return NewStore(dir)
+}
+
+// SetFilter sets a function applied to all segment text, including undo
+// history, before it is written to disk
+func (st *Store) SetFilter(filter func(string) string) {
+	st.filter = filter
+}
+
+// FilterText applies the store's filter to text saved outside a session
+func (st *Store) FilterText(text string) string {
+	if st.filter == nil {
+		return text
+	}
+	return st.filter(text)
+}
+
+// Save writes the session, including its undo history, to disk
+func (st *Store) Save(s *Session) error {
+	s.mu.Lock()
+	data, err := json.MarshalIndent(st.filtered(s), "", "  ")
+	spilled := s.Spilled
+	s.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to marshal session: %w", err)
+	}
+
+	// Write to a temporary file first so a crash can't leave a truncated session
+	path := st.path(s.ID)
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write session file: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to replace session file: %w", err)
+	}
+
+	// A session with nothing spilled holds all of its segments, so any
+	// spilled segments left from before it was loaded are stale
+	if spilled == 0 {
+		os.Remove(st.spillPath(s.ID))
+	}
+
+	return nil
+}
+
+// Spill moves all but the newest keep segments of a session out of memory
+// into a segments file next to the session, then saves the session. Spilled
+// segments are read back by Load, Segments and the exports, but can no
+// longer be edited.
+func (st *Store) Spill(s *Session, keep int) error {
+	s.mu.Lock()
+	n := len(s.Segments) - keep
+	if n <= 0 {
+		s.mu.Unlock()
+		return nil
+	}
+
+	// Start a new segments file if nothing is spilled yet, e.g. for a
+	// session loaded back into memory
+	flags := os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	if s.Spilled == 0 {
+		flags |= os.O_TRUNC
+	}
+	file, err := os.OpenFile(st.spillPath(s.ID), flags, 0644)
+	if err != nil {
+		s.mu.Unlock()
+		return fmt.Errorf("failed to open segments file: %w", err)
+	}
+	writer := bufio.NewWriter(file)
+	encoder := json.NewEncoder(writer)
+	for _, seg := range s.Segments[:n] {
+		if st.filter != nil {
+			seg.Text = st.filter(seg.Text)
+			seg.Words = nil
+		}
+		if err = encoder.Encode(seg); err != nil {
+			break
+		}
+	}
+	if err == nil {
+		err = writer.Flush()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		s.mu.Unlock()
+		return fmt.Errorf("failed to write segments file: %w", err)
+	}
+
+	s.dropOldest(n)
+	s.mu.Unlock()
+	return st.Save(s)
+}
+
+// Spilled reads the spilled segments of a session, oldest first
+func (st *Store) Spilled(s *Session) ([]Segment, error) {
+	s.mu.Lock()
+	id, spilled := s.ID, s.Spilled
+	s.mu.Unlock()
+	return st.readSpilled(id, spilled)
+}
+
+// Segments returns every segment of a session, reading spilled ones from disk
+func (st *Store) Segments(s *Session) ([]Segment, error) {
+	spilled, err := st.Spilled(s)
+	if err != nil {
+		return nil, err
+	}
+	return append(spilled, s.List()...), nil
+}
+
+// readSpilled reads the first count segments from a session's segments file.
+// Segments past the count were written by a spill whose session was never
+// saved, and are ignored.
+func (st *Store) readSpilled(id string, count int) ([]Segment, error) {
+	if count == 0 {
+		return nil, nil
+	}
+	file, err := os.Open(st.spillPath(id))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read segments file: %w", err)
+	}
+	defer file.Close()
+
+	segments := make([]Segment, 0, count)
+	decoder := json.NewDecoder(bufio.NewReader(file))
+	for len(segments) < count {
+		var seg Segment
+		if err := decoder.Decode(&seg); err != nil {
+			return nil, fmt.Errorf("failed to parse segments file: %w", err)
+		}
+		segments = append(segments, seg)
+	}
+	return segments, nil
+}
+
+// complete returns the session as it should be exported, with its spilled
+// segments read back in front of the filtered in-memory ones
+func (st *Store) complete(s *Session) (*Session, error) {
+	spilled, err := st.Spilled(s)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	filtered := st.filtered(s)
+	if len(spilled) == 0 {
+		return filtered, nil
+	}
+	return &Session{
+		ID:       filtered.ID,
+		Created:  filtered.Created,
+		Segments: append(spilled, filtered.Segments...),
+		NextID:   filtered.NextID,
+		Speakers: filtered.Speakers,
+		Metadata: filtered.Metadata,
+	}, nil
+}
+
+// Export encodes the session in the export format, applying the store's
+// filter so exports are redacted like saved sessions
+func (st *Store) Export(s *Session) ([]byte, error) {
+	complete, err := st.complete(s)
+	if err != nil {
+		return nil, err
+	}
+	return ExportJSON(complete)
+}
+
+// RenderTranscript writes the session's transcript as a document, applying
+// the store's filter like Export does
+func (st *Store) RenderTranscript(s *Session, format TranscriptFormat) ([]byte, error) {
+	complete, err := st.complete(s)
+	if err != nil {
+		return nil, err
+	}
+	return RenderTranscript(complete, format)
+}
+
+// Import adds an imported session to the store. If a session with the same
+// ID already exists, or the ID is not a valid session ID, the import gets a
+// new ID instead.
+func (st *Store) Import(s *Session) error {
+	if !ValidID(s.ID) {
+		s.ID = New().ID
+	}
+	if _, err := os.Stat(st.path(s.ID)); err == nil {
+		s.ID = New().ID
+	}
+	return st.Save(s)
+}
+
+// Load reads a previously saved session
+func (st *Store) Load(id string) (*Session, error) {
+	data, err := os.ReadFile(st.path(id))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read session file: %w", err)
+	}
+
+	var s Session
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("failed to parse session file: %w", err)
+	}
+
+	// Loaded sessions hold all of their segments again
+	if s.Spilled > 0 {
+		spilled, err := st.readSpilled(s.ID, s.Spilled)
+		if err != nil {
+			return nil, err
+		}
+		s.Segments = append(spilled, s.Segments...)
+		s.Spilled = 0
+	}
+
+	// Sessions saved before any segment existed may have no ID counter
+	if s.NextID < 1 {
+		s.NextID = 1
+	}
+	for _, seg := range s.Segments {
+		if seg.ID >= s.NextID {
+			s.NextID = seg.ID + 1
+		}
+	}
+
+	return &s, nil
+}
+
+// List returns the IDs of all saved sessions, newest first
+func (st *Store) List() ([]string, error) {
+	entries, err := os.ReadDir(st.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+
+	ids := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".json") {
+			continue
+		}
+		ids = append(ids, strings.TrimSuffix(name, ".json"))
+	}
+
+	// IDs are timestamps, so reverse lexical order is newest first
+	sort.Sort(sort.Reverse(sort.StringSlice(ids)))
+	return ids, nil
+}
+
+// Latest loads the most recently created session, or returns nil if there is none
+func (st *Store) Latest() (*Session, error) {
+	ids, err := st.List()
+	if err != nil || len(ids) == 0 {
+		return nil, err
+	}
+	return st.Load(ids[0])
+}
+
+// SearchResult is a saved segment that matches a history search
+type SearchResult struct {
+	SessionID string
+	Created   time.Time
+	Segment   Segment
+}
+
+// Search returns up to limit segments from saved sessions whose text contains
+// query, ignoring case, newest session first. A limit of 0 means no limit.
+// Sessions that can't be read are skipped.
+func (st *Store) Search(query string, limit int) ([]SearchResult, error) {
+	query = strings.ToLower(strings.TrimSpace(query))
+	if query == "" {
+		return nil, nil
+	}
+
+	ids, err := st.List()
+	if err != nil {
+		return nil, err
+	}
+
+	var results []SearchResult
+	for _, id := range ids {
+		s, err := st.Load(id)
+		if err != nil {
+			continue
+		}
+		for _, seg := range s.Segments {
+			if !strings.Contains(strings.ToLower(seg.Text), query) {
+				continue
+			}
+			results = append(results, SearchResult{SessionID: s.ID, Created: s.Created, Segment: seg})
+			if limit > 0 && len(results) >= limit {
+				return results, nil
+			}
+		}
+	}
+	return results, nil
+}
+
+// Delete removes a saved session
+func (st *Store) Delete(id string) error {
+	if err := os.Remove(st.path(id)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete session: %w", err)
+	}
+	if err := os.Remove(st.spillPath(id)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete session: %w", err)
+	}
+	return nil
+}
+
+// filtered returns the session as it should be written, with the filter
+// applied to every segment. The caller must hold s.mu.
+func (st *Store) filtered(s *Session) *Session {
+	if st.filter == nil {
+		return s
+	}
+
+	filterSegments := func(segments []Segment) []Segment {
+		if segments == nil {
+			return nil
+		}
+		out := make([]Segment, len(segments))
+		for i, seg := range segments {
+			seg.Text = st.filter(seg.Text)
+			// Words are dropped rather than filtered one by one, since a
+			// pattern can span several words
+			seg.Words = nil
+			out[i] = seg
+		}
+		return out
+	}
+	filterEdits := func(edits []Edit) []Edit {
+		out := make([]Edit, len(edits))
+		for i, edit := range edits {
+			edit.Before = filterSegments(edit.Before)
+			edit.After = filterSegments(edit.After)
+			out[i] = edit
+		}
+		return out
+	}
+
+	return &Session{
+		ID:       s.ID,
+		Created:  s.Created,
+		Segments: filterSegments(s.Segments),
+		Undo:     filterEdits(s.Undo),
+		Redo:     filterEdits(s.Redo),
+		NextID:   s.NextID,
+		Spilled:  s.Spilled,
+		Speakers: s.Speakers,
+		Metadata: s.Metadata,
+	}
+}
+
+// path returns the file path for a session ID
+func (st *Store) path(id string) string {
+	return filepath.Join(st.dir, id+".json")
+}
+
+// spillPath returns the path of the file holding a session's spilled segments
+func (st *Store) spillPath(id string) string {
+	return filepath.Join(st.dir, id+".segments.jsonl")
+}
@@ -0,0 +1,286 @@
+// Package soak runs live transcription for hours on looped audio, watching
+// memory, buffers and restarts, so leaks and unbounded growth show up before
+// a long dictation session runs into them
+package soak
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jeff-barlow-spady/ramble/pkg/audio"
+	"github.com/jeff-barlow-spady/ramble/pkg/transcription"
+)
+
+// sampleRate is the rate the transcriber takes audio at
+const sampleRate = 16000
+
+// chunkSamples is how much audio is fed at a time, as a capture callback
+// would deliver it
+const chunkSamples = 1024
+
+// drainTimeout bounds the wait for the end of each recording
+const drainTimeout = 30 * time.Second
+
+// Options configures a soak test
+type Options struct {
+	Duration     time.Duration // How long to run
+	Speed        float64       // Seconds of audio fed per second; 1 is real time
+	Recording    time.Duration // Audio in each recording before it is stopped and the next started
+	Interval     time.Duration // Time between samples of memory and buffers
+	QueueSeconds float64       // Audio the queue in front of the transcriber holds, like the capture queue
+	Pause        time.Duration // Silence ending an utterance; 0 never ends one
+
+	// Limits; exceeding one fails the test
+	MaxHeapGrowth      uint64  // Heap growth after the first quarter of the run, in bytes
+	MaxGoroutineGrowth int     // Goroutines added after the first quarter of the run
+	MaxBufferSeconds   float64 // Audio the transcriber may hold at once
+
+	// Progress, if set, is called with each sample as it is taken
+	Progress func(Sample)
+}
+
+// DefaultOptions returns options for an hour in real time
+func DefaultOptions() Options {
+	return Options{
+		Duration:           time.Hour,
+		Speed:              1,
+		Recording:          10 * time.Minute,
+		Interval:           time.Minute,
+		QueueSeconds:       30,
+		Pause:              800 * time.Millisecond,
+		MaxHeapGrowth:      64 << 20,
+		MaxGoroutineGrowth: 20,
+		MaxBufferSeconds:   60,
+	}
+}
+
+// Sample is the state of the pipeline at one point of the run. The heap is
+// measured after a garbage collection, so it holds only live memory.
+type Sample struct {
+	Elapsed        time.Duration
+	Audio          time.Duration // Audio fed so far
+	HeapBytes      uint64
+	Goroutines     int
+	QueueSeconds   float64 // Audio waiting for the transcriber
+	BufferSeconds  float64 // Audio held by the transcriber
+	DroppedSeconds float64 // Audio dropped because transcription fell behind
+	Texts          int     // Text sent by the transcriber
+	Recordings     int
+	Restarts       int // Backends restarted after they stopped responding
+}
+
+// String formats the sample as one line of progress
+func (s Sample) String() string {
+	return fmt.Sprintf("%v: %v of audio, heap %.1fMB, %d goroutines, queue %.1fs, buffer %.1fs, dropped %.1fs, %d texts, %d recordings, %d restarts",
+		s.Elapsed.Round(time.Second), s.Audio.Round(time.Second), float64(s.HeapBytes)/(1<<20), s.Goroutines,
+		s.QueueSeconds, s.BufferSeconds, s.DroppedSeconds, s.Texts, s.Recordings, s.Restarts)
+}
+
+// Result is the outcome of a soak test
+type Result struct {
+	Samples  []Sample
+	Failures []string // Why the test failed; empty if it passed
+}
+
+// Passed reports whether no limit was exceeded
+func (r Result) Passed() bool {
+	return len(r.Failures) == 0
+}
+
+// counters are updated by the pipeline and read by the sampler
+type counters struct {
+	fed        atomic.Int64 // Samples
+	texts      atomic.Int64
+	recordings atomic.Int64
+	restarts   atomic.Int64
+}
+
+// Run feeds source, looped, to transcriber until opts.Duration has passed or
+// ctx is done, the way the app feeds captured audio, then checks the samples
+// taken along the way against the limits. The transcriber's streaming and
+// restart callbacks are replaced.
+func Run(ctx context.Context, transcriber transcription.Transcriber, source []float32, opts Options) Result {
+	if len(source) == 0 {
+		return Result{Failures: []string{"no audio to feed"}}
+	}
+	ctx, cancel := context.WithTimeout(ctx, opts.Duration)
+	defer cancel()
+
+	var count counters
+	transcriber.SetStreamingCallback(func(string) { count.texts.Add(1) })
+	if restarter, ok := transcriber.(transcription.Restarter); ok {
+		restarter.SetRestartCallback(func(error) { count.restarts.Add(1) })
+	}
+
+	queue := audio.NewRingBuffer(int(opts.QueueSeconds * sampleRate))
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		feed(ctx, queue, source, opts.Speed, &count)
+	}()
+	go func() {
+		defer wg.Done()
+		consume(ctx, transcriber, queue, opts, &count)
+	}()
+
+	started := time.Now()
+	var result Result
+	ticker := time.NewTicker(opts.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			result.Failures = check(result.Samples, opts)
+			return result
+		case <-ticker.C:
+		}
+
+		sample := take(started, transcriber, queue, &count)
+		result.Samples = append(result.Samples, sample)
+		if opts.Progress != nil {
+			opts.Progress(sample)
+		}
+	}
+}
+
+// feed writes source to queue at speed times real time, looping it
+func feed(ctx context.Context, queue *audio.RingBuffer, source []float32, speed float64, count *counters) {
+	chunk := make([]float32, chunkSamples)
+	ticker := time.NewTicker(time.Duration(float64(chunkSamples) / sampleRate / speed * float64(time.Second)))
+	defer ticker.Stop()
+
+	position := 0
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		for i := range chunk {
+			chunk[i] = source[position]
+			position = (position + 1) % len(source)
+		}
+		queue.Write(chunk)
+		count.fed.Add(chunkSamples)
+	}
+}
+
+// consume hands queued audio to the transcriber as the app does: held back
+// while a pass runs, with pauses ending utterances and each recording
+// drained before the next starts
+func consume(ctx context.Context, transcriber transcription.Transcriber, queue *audio.RingBuffer, opts Options, count *counters) {
+	samples := make([]float32, chunkSamples)
+	var utterances *audio.UtteranceDetector
+	if opts.Pause > 0 {
+		utterances = audio.NewUtteranceDetector(sampleRate, opts.Pause)
+	}
+	utteranceEnded := false
+	recordingSamples := int(opts.Recording.Seconds() * sampleRate)
+	recorded := 0
+
+	transcriber.SetRecordingState(true)
+	count.recordings.Add(1)
+	for {
+		select {
+		case <-ctx.Done():
+			transcriber.Drain(drainTimeout)
+			return
+		case <-queue.Ready():
+		}
+
+		if transcriber.IsBusy() {
+			continue
+		}
+		if utteranceEnded {
+			utteranceEnded = false
+			transcriber.EndUtterance()
+		}
+		if recordingSamples > 0 && recorded >= recordingSamples {
+			transcriber.Drain(drainTimeout)
+			transcriber.SetRecordingState(true)
+			count.recordings.Add(1)
+			recorded = 0
+		}
+
+		for {
+			n := queue.Read(samples)
+			if n == 0 {
+				break
+			}
+			if utterances != nil && utterances.Process(samples[:n]) {
+				utteranceEnded = true
+			}
+			transcriber.AppendAudio(samples[:n])
+			recorded += n
+		}
+		transcriber.ProcessAudioChunk(nil)
+	}
+}
+
+// take samples the state of the pipeline
+func take(started time.Time, transcriber transcription.Transcriber, queue *audio.RingBuffer, count *counters) Sample {
+	runtime.GC()
+	var memory runtime.MemStats
+	runtime.ReadMemStats(&memory)
+
+	return Sample{
+		Elapsed:        time.Since(started),
+		Audio:          time.Duration(count.fed.Load()) * time.Second / sampleRate,
+		HeapBytes:      memory.HeapAlloc,
+		Goroutines:     runtime.NumGoroutine(),
+		QueueSeconds:   float64(queue.Len()) / sampleRate,
+		BufferSeconds:  float64(transcriber.BufferedSamples()) / sampleRate,
+		DroppedSeconds: float64(queue.Dropped()) / sampleRate,
+		Texts:          int(count.texts.Load()),
+		Recordings:     int(count.recordings.Load()),
+		Restarts:       int(count.restarts.Load()),
+	}
+}
+
+// check compares the samples with the limits. The first quarter of the run
+// is warm-up, while the model and buffers reach their working size; growth
+// is measured from the smallest heap after it to the smallest heap in the
+// last quarter, so a collection that happened to run late isn't a leak.
+func check(samples []Sample, opts Options) []string {
+	if len(samples) < 4 {
+		return []string{fmt.Sprintf("only %d samples; run for at least four sample intervals", len(samples))}
+	}
+
+	var failures []string
+	for _, s := range samples {
+		if s.BufferSeconds > opts.MaxBufferSeconds {
+			failures = append(failures, fmt.Sprintf("transcriber held %.1fs of audio at %v, above %.1fs",
+				s.BufferSeconds, s.Elapsed.Round(time.Second), opts.MaxBufferSeconds))
+			break
+		}
+	}
+
+	steady := samples[len(samples)/4:]
+	last := samples[len(samples)*3/4:]
+	baseline, final := smallestHeap(steady), smallestHeap(last)
+	if final > baseline && final-baseline > opts.MaxHeapGrowth {
+		failures = append(failures, fmt.Sprintf("heap grew from %.1fMB to %.1fMB, more than %.1fMB",
+			float64(baseline)/(1<<20), float64(final)/(1<<20), float64(opts.MaxHeapGrowth)/(1<<20)))
+	}
+
+	added := samples[len(samples)-1].Goroutines - steady[0].Goroutines
+	if added > opts.MaxGoroutineGrowth {
+		failures = append(failures, fmt.Sprintf("%d goroutines added, more than %d", added, opts.MaxGoroutineGrowth))
+	}
+	return failures
+}
+
+// smallestHeap returns the smallest heap among samples
+func smallestHeap(samples []Sample) uint64 {
+	smallest := samples[0].HeapBytes
+	for _, s := range samples[1:] {
+		smallest = min(smallest, s.HeapBytes)
+	}
+	return smallest
+}
@@ -0,0 +1,102 @@
+package soak
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jeff-barlow-spady/ramble/pkg/audio"
+	"github.com/jeff-barlow-spady/ramble/pkg/transcription"
+)
+
+// echoEngine transcribes every window as the same text
+type echoEngine struct{}
+
+func (echoEngine) Transcribe(samples []float32, prompt, language string) ([]transcription.Segment, error) {
+	return []transcription.Segment{{Text: "hello"}}, nil
+}
+func (echoEngine) IsLoaded() bool { return true }
+func (echoEngine) Load() error    { return nil }
+func (echoEngine) Unload() error  { return nil }
+func (echoEngine) Close() error   { return nil }
+
+func TestSpeechLike(t *testing.T) {
+	samples := SpeechLike(20*time.Second, 1)
+	if len(samples) != 20*sampleRate {
+		t.Fatalf("Expected 20 seconds, got %d samples", len(samples))
+	}
+	if level := audio.CalculateLevel(samples); level < audio.SpeechLevel {
+		t.Errorf("Expected the level of speech, got %.4f", level)
+	}
+
+	utterances := audio.NewUtteranceDetector(sampleRate, 500*time.Millisecond)
+	ended := 0
+	for i := 0; i < len(samples); i += chunkSamples {
+		if utterances.Process(samples[i:min(i+chunkSamples, len(samples))]) {
+			ended++
+		}
+	}
+	if ended < 3 {
+		t.Errorf("Expected pauses between phrases, got %d", ended)
+	}
+
+	again := SpeechLike(20*time.Second, 1)
+	for i := range samples {
+		if samples[i] != again[i] {
+			t.Fatal("Expected the same seed to give the same audio")
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	opts := DefaultOptions()
+	steady := func(heap uint64, goroutines int) Sample {
+		return Sample{HeapBytes: heap, Goroutines: goroutines, BufferSeconds: 5}
+	}
+
+	// Growth during warm-up and a late collection don't count
+	samples := []Sample{steady(10<<20, 10), steady(200<<20, 12), steady(200<<20, 12), steady(250<<20, 12), steady(201<<20, 12), steady(205<<20, 13)}
+	if failures := check(samples, opts); len(failures) != 0 {
+		t.Errorf("Expected a steady run to pass, got %v", failures)
+	}
+
+	samples = []Sample{steady(10<<20, 10), steady(100<<20, 12), steady(150<<20, 12), steady(200<<20, 40)}
+	samples[2].BufferSeconds = 90
+	failures := strings.Join(check(samples, opts), "\n")
+	for _, expected := range []string{"heap grew", "goroutines added", "held 90.0s"} {
+		if !strings.Contains(failures, expected) {
+			t.Errorf("Expected a failure for %q, got %q", expected, failures)
+		}
+	}
+
+	if failures := check(samples[:2], opts); len(failures) != 1 {
+		t.Errorf("Expected a run too short to judge to fail, got %v", failures)
+	}
+}
+
+func TestRun(t *testing.T) {
+	opts := DefaultOptions()
+	opts.Duration = 500 * time.Millisecond
+	opts.Interval = 50 * time.Millisecond
+	opts.Speed = 20
+	opts.Recording = 2 * time.Second
+	opts.MaxHeapGrowth = 1 << 30
+	var progress []Sample
+	opts.Progress = func(s Sample) { progress = append(progress, s) }
+
+	transcriber := transcription.NewEngineTranscriber(echoEngine{})
+	transcriber.SetSuppressor(nil)
+	result := Run(context.Background(), transcriber, SpeechLike(5*time.Second, 1), opts)
+
+	if !result.Passed() {
+		t.Errorf("Expected the run to pass, got %v", result.Failures)
+	}
+	if len(progress) != len(result.Samples) || len(result.Samples) < 4 {
+		t.Fatalf("Expected samples to be reported, got %d of %d", len(progress), len(result.Samples))
+	}
+	last := result.Samples[len(result.Samples)-1]
+	if last.Audio < 5*time.Second || last.Texts == 0 || last.Recordings < 2 {
+		t.Errorf("Expected audio fed, transcribed and recorded again, got %s", last)
+	}
+}
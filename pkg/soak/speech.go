@@ -0,0 +1,43 @@
+package soak
+
+import (
+	"math"
+	"math/rand"
+	"time"
+)
+
+// SpeechLike returns 16kHz audio shaped like speech: phrases of voiced
+// syllables, each a few harmonics of a wandering pitch under a smooth
+// envelope, separated by pauses long enough to end an utterance. It won't
+// transcribe to words, but it loads the pipeline the way dictation does,
+// including the pauses. The same seed gives the same audio.
+func SpeechLike(length time.Duration, seed int64) []float32 {
+	random := rand.New(rand.NewSource(seed))
+	samples := make([]float32, int(length.Seconds()*sampleRate))
+
+	i := 0
+	for i < len(samples) {
+		// A phrase of 3 to 12 syllables
+		syllables := 3 + random.Intn(10)
+		pitch := 100 + random.Float64()*120
+		for s := 0; s < syllables && i < len(samples); s++ {
+			n := int((0.12 + random.Float64()*0.2) * sampleRate)
+			amplitude := 0.1 + random.Float64()*0.2
+			pitch *= 0.95 + random.Float64()*0.1
+			for j := 0; j < n && i+j < len(samples); j++ {
+				t := float64(j) / sampleRate
+				envelope := math.Sin(math.Pi * float64(j) / float64(n))
+				var v float64
+				for harmonic := 1.0; harmonic <= 4; harmonic++ {
+					v += math.Sin(2*math.Pi*pitch*harmonic*t) / harmonic
+				}
+				samples[i+j] = float32(amplitude * envelope * v / 2)
+			}
+			// A short gap between syllables
+			i += n + int((0.02+random.Float64()*0.06)*sampleRate)
+		}
+		// A pause between phrases
+		i += int((0.6 + random.Float64()*1.2) * sampleRate)
+	}
+	return samples
+}
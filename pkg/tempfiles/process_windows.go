@@ -0,0 +1,16 @@
+//go:build windows
+
+package tempfiles
+
+import "os"
+
+// processAlive reports whether a process with the given ID is running.
+// FindProcess opens the process on Windows, so it fails once it has exited.
+func processAlive(pid int) bool {
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	process.Release()
+	return true
+}
@@ -0,0 +1,149 @@
+// Package tempfiles keeps the temporary files of a run of Ramble, such as
+// audio handed to external programs, in a directory of that run. The
+// directory is removed when the run ends, and directories left by runs that
+// crashed are swept at the next start.
+package tempfiles
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// lockName is the file in each run directory holding the process ID of the
+// run that owns it
+const lockName = "owner.lock"
+
+// runPattern names run directories, so nothing else under the root is swept
+const runPattern = "run-*"
+
+// Dir is the temporary directory of a run
+type Dir struct {
+	path string
+}
+
+// Open creates a run directory under root, locked by the current process
+func Open(root string) (*Dir, error) {
+	if err := os.MkdirAll(root, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create temporary file directory: %w", err)
+	}
+	path, err := os.MkdirTemp(root, runPattern)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary file directory: %w", err)
+	}
+	lock := filepath.Join(path, lockName)
+	if err := os.WriteFile(lock, []byte(strconv.Itoa(os.Getpid())), 0600); err != nil {
+		os.RemoveAll(path)
+		return nil, fmt.Errorf("failed to lock temporary file directory: %w", err)
+	}
+	return &Dir{path: path}, nil
+}
+
+// Path returns the directory's path
+func (d *Dir) Path() string {
+	return d.path
+}
+
+// Create creates a new temporary file in the directory, named like
+// os.CreateTemp names it from pattern
+func (d *Dir) Create(pattern string) (*os.File, error) {
+	return os.CreateTemp(d.path, pattern)
+}
+
+// Close removes the directory and every file in it
+func (d *Dir) Close() error {
+	return os.RemoveAll(d.path)
+}
+
+// Sweep removes the run directories under root whose run has ended without
+// removing them, e.g. because it crashed, and returns how many it removed
+func Sweep(root string) (int, error) {
+	return sweep(root, processAlive)
+}
+
+// sweep removes the run directories under root that have no lock or whose
+// owner alive reports as no longer running
+func sweep(root string, alive func(pid int) bool) (int, error) {
+	paths, err := filepath.Glob(filepath.Join(root, runPattern))
+	if err != nil {
+		return 0, err
+	}
+	removed := 0
+	var errs []error
+	for _, path := range paths {
+		if pid, err := owner(path); err == nil && alive(pid) {
+			continue
+		}
+		if err := os.RemoveAll(path); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		removed++
+	}
+	return removed, errors.Join(errs...)
+}
+
+// owner returns the process ID in a run directory's lock
+func owner(path string) (int, error) {
+	data, err := os.ReadFile(filepath.Join(path, lockName))
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(strings.TrimSpace(string(data)))
+}
+
+var (
+	mu      sync.Mutex
+	current *Dir
+)
+
+// Start sweeps the directories left under root by earlier runs and opens the
+// directory of this run, which Create then uses. It returns how many
+// directories were swept.
+func Start(root string) (int, error) {
+	swept, err := Sweep(root)
+	if err != nil {
+		err = fmt.Errorf("failed to remove old temporary files: %w", err)
+	}
+	dir, openErr := Open(root)
+	if openErr != nil {
+		return swept, errors.Join(err, openErr)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if current != nil {
+		current.Close()
+	}
+	current = dir
+	return swept, err
+}
+
+// Create creates a new temporary file in the directory of this run, or in
+// the system's temporary directory if Start was not called or failed
+func Create(pattern string) (*os.File, error) {
+	mu.Lock()
+	dir := current
+	mu.Unlock()
+	if dir == nil {
+		return os.CreateTemp("", pattern)
+	}
+	return dir.Create(pattern)
+}
+
+// Stop removes the directory of this run with any temporary files still in
+// it. It is safe to call more than once.
+func Stop() error {
+	mu.Lock()
+	dir := current
+	current = nil
+	mu.Unlock()
+	if dir == nil {
+		return nil
+	}
+	return dir.Close()
+}
@@ -0,0 +1,88 @@
+package tempfiles
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDirLifecycle(t *testing.T) {
+	root := t.TempDir()
+	dir, err := Open(root)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+
+	f, err := dir.Create("audio-*.wav")
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	f.Close()
+	if filepath.Dir(f.Name()) != dir.Path() {
+		t.Errorf("Expected the file in %s, got %s", dir.Path(), f.Name())
+	}
+	if pid, err := owner(dir.Path()); err != nil || pid != os.Getpid() {
+		t.Errorf("Expected the directory locked by this process, got %d (%v)", pid, err)
+	}
+
+	if err := dir.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if _, err := os.Stat(dir.Path()); !os.IsNotExist(err) {
+		t.Errorf("Expected the directory to be removed, got %v", err)
+	}
+}
+
+func TestSweepRemovesOrphanedDirs(t *testing.T) {
+	root := t.TempDir()
+	running, _ := Open(root)
+	crashed, _ := Open(root)
+	os.WriteFile(filepath.Join(crashed.Path(), lockName), []byte("99"), 0600)
+	unlocked := filepath.Join(root, "run-unlocked")
+	os.Mkdir(unlocked, 0700)
+	other := filepath.Join(root, "keep")
+	os.Mkdir(other, 0700)
+
+	removed, err := sweep(root, func(pid int) bool { return pid == os.Getpid() })
+	if err != nil {
+		t.Fatalf("sweep failed: %v", err)
+	}
+	if removed != 2 {
+		t.Errorf("Expected 2 directories removed, got %d", removed)
+	}
+	for _, path := range []string{running.Path(), other} {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("Expected %s to be kept, got %v", path, err)
+		}
+	}
+	for _, path := range []string{crashed.Path(), unlocked} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("Expected %s to be removed, got %v", path, err)
+		}
+	}
+}
+
+func TestStartAndStop(t *testing.T) {
+	root := t.TempDir()
+	if _, err := Start(root); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	f, err := Create("speech-*.wav")
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	f.Close()
+	if filepath.Dir(filepath.Dir(f.Name())) != root {
+		t.Errorf("Expected the file in a run directory under %s, got %s", root, f.Name())
+	}
+
+	if err := Stop(); err != nil {
+		t.Fatalf("Stop failed: %v", err)
+	}
+	if _, err := os.Stat(f.Name()); !os.IsNotExist(err) {
+		t.Errorf("Expected the file to be removed, got %v", err)
+	}
+	if err := Stop(); err != nil {
+		t.Errorf("Expected a second Stop to do nothing, got %v", err)
+	}
+}
@@ -0,0 +1,99 @@
+package textproc
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+var (
+	spaceRun         = regexp.MustCompile(`\s+`)
+	spaceBeforePunct = regexp.MustCompile(`\s+([,.!?;:])`)
+	repeatedPunct    = regexp.MustCompile(`([,;:])[,;:]+|\.{2}(?:\s*\.)*|[!?](?:[!?]|\s*\.)+`)
+	lowercasePronoun = regexp.MustCompile(`\bi('(?:m|ve|ll|d))?\b`)
+	sentenceBoundary = regexp.MustCompile(`(^|[^.])[.!?]\s+\p{Ll}`) // An ellipsis doesn't end a sentence
+)
+
+// cleanupTimeout bounds how long a cleanup command may take for one segment
+const cleanupTimeout = 10 * time.Second
+
+// Cleanup fixes the punctuation and casing whisper gets wrong in short
+// segments: stray spaces before punctuation, doubled punctuation, a lowercase
+// "i", sentences starting in lowercase and a missing final full stop
+func Cleanup(text string) string {
+	text = strings.TrimSpace(spaceRun.ReplaceAllString(text, " "))
+	if text == "" {
+		return ""
+	}
+
+	text = spaceBeforePunct.ReplaceAllString(text, "$1")
+	text = repeatedPunct.ReplaceAllStringFunc(text, func(match string) string {
+		if strings.HasPrefix(match, "..") {
+			// Keep an ellipsis, but not a longer run of dots
+			if strings.Count(match, ".") >= 3 {
+				return "..."
+			}
+			return "."
+		}
+		return match[:1]
+	})
+	text = lowercasePronoun.ReplaceAllStringFunc(text, func(match string) string {
+		return "I" + match[1:]
+	})
+	text = sentenceBoundary.ReplaceAllStringFunc(text, func(match string) string {
+		r, size := utf8.DecodeLastRuneInString(match)
+		return match[:len(match)-size] + string(unicode.ToUpper(r))
+	})
+
+	first, size := utf8.DecodeRuneInString(text)
+	text = string(unicode.ToUpper(first)) + text[size:]
+
+	last, _ := utf8.DecodeLastRuneInString(text)
+	if unicode.IsLetter(last) || unicode.IsDigit(last) {
+		text += "."
+	}
+	return text
+}
+
+// Cleaner cleans up finalized segments with an external program, such as a
+// punctuation and truecasing model, or with Cleanup if none is configured
+type Cleaner struct {
+	command []string // Program and arguments reading text on stdin; nil to use Cleanup
+}
+
+// NewCleaner creates a cleaner running command, the program followed by its
+// arguments. An empty command uses the built-in rules.
+func NewCleaner(command []string) *Cleaner {
+	return &Cleaner{command: command}
+}
+
+// Clean returns the cleaned-up text. If the program fails the built-in rules
+// are used and the error is returned with their result.
+func (c *Cleaner) Clean(text string) (string, error) {
+	if len(c.command) == 0 || strings.TrimSpace(text) == "" {
+		return Cleanup(text), nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), cleanupTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, c.command[0], c.command[1:]...)
+	cmd.Stdin = strings.NewReader(text)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return Cleanup(text), fmt.Errorf("cleanup command %s failed: %w: %s", c.command[0], err, strings.TrimSpace(stderr.String()))
+	}
+	cleaned := strings.TrimSpace(string(out))
+	if cleaned == "" {
+		return Cleanup(text), fmt.Errorf("cleanup command %s returned no text", c.command[0])
+	}
+	return cleaned, nil
+}
@@ -0,0 +1,44 @@
+package textproc
+
+import (
+	"os/exec"
+	"testing"
+)
+
+func TestCleanup(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"", ""},
+		{"  hello   there ", "Hello there."},
+		{"i think i'm done , right ?", "I think I'm done, right?"},
+		{"first part.. second part", "First part. Second part."},
+		{"wait... what", "Wait... what."},
+		{"really?. yes!! ok", "Really? Yes! Ok."},
+		{"one. two! three? four", "One. Two! Three? Four."},
+		{"it's in the wiki,, see it:", "It's in the wiki, see it:"},
+		{"élan vital", "Élan vital."},
+	}
+	for _, tt := range tests {
+		if got := Cleanup(tt.input); got != tt.want {
+			t.Errorf("Cleanup(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestCleanerCommand(t *testing.T) {
+	if _, err := exec.LookPath("tr"); err != nil {
+		t.Skip("tr not available")
+	}
+
+	cleaned, err := NewCleaner([]string{"tr", "a-z", "A-Z"}).Clean("shout this")
+	if err != nil || cleaned != "SHOUT THIS" {
+		t.Errorf("Expected the command's output, got %q (%v)", cleaned, err)
+	}
+
+	cleaned, err = NewCleaner([]string{"false"}).Clean("keep going")
+	if err == nil || cleaned != "Keep going." {
+		t.Errorf("Expected the built-in rules and an error when the command fails, got %q (%v)", cleaned, err)
+	}
+}
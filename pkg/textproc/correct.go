@@ -0,0 +1,125 @@
+package textproc
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Correction replaces a word or phrase with another
+type Correction struct {
+	From  string
+	To    string
+	Regex bool // From is a regular expression and To may refer to its groups as $1
+}
+
+// Corrector applies correction rules to transcribed text
+type Corrector struct {
+	rules []compiledCorrection
+}
+
+type compiledCorrection struct {
+	pattern *regexp.Regexp
+	to      string
+	expand  bool // Expand $1 and similar in to
+}
+
+// NewCorrector creates a corrector. Literal rules match whole words, ignoring
+// case; regular expressions match as written. Rules are applied in order.
+func NewCorrector(rules []Correction) (*Corrector, error) {
+	c := &Corrector{}
+	for _, rule := range rules {
+		from := strings.TrimSpace(rule.From)
+		if from == "" {
+			continue
+		}
+		if rule.Regex {
+			pattern, err := regexp.Compile(from)
+			if err != nil {
+				return nil, fmt.Errorf("invalid replacement pattern %s: %w", from, err)
+			}
+			c.rules = append(c.rules, compiledCorrection{pattern: pattern, to: rule.To, expand: true})
+			continue
+		}
+
+		pattern := regexp.QuoteMeta(from)
+		// Only anchor at word characters; \b next to punctuation never matches
+		if isWordByte(from[0]) {
+			pattern = `\b` + pattern
+		}
+		if isWordByte(from[len(from)-1]) {
+			pattern += `\b`
+		}
+		c.rules = append(c.rules, compiledCorrection{
+			pattern: regexp.MustCompile(`(?i)` + pattern),
+			to:      rule.To,
+		})
+	}
+	return c, nil
+}
+
+// Enabled reports whether the corrector has any rules
+func (c *Corrector) Enabled() bool {
+	return c != nil && len(c.rules) > 0
+}
+
+// Correct returns text with every rule applied
+func (c *Corrector) Correct(text string) string {
+	if !c.Enabled() {
+		return text
+	}
+	for _, rule := range c.rules {
+		if rule.expand {
+			text = rule.pattern.ReplaceAllString(text, rule.to)
+		} else {
+			text = rule.pattern.ReplaceAllLiteralString(text, rule.to)
+		}
+	}
+	return text
+}
+
+// ParseCorrections reads rules written one per line as "from => to". A from
+// written as /pattern/ is a regular expression. Blank lines and lines
+// starting with # are skipped.
+func ParseCorrections(text string) ([]Correction, error) {
+	var rules []Correction
+	for i, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		from, to, ok := strings.Cut(line, "=>")
+		from, to = strings.TrimSpace(from), strings.TrimSpace(to)
+		if !ok || from == "" {
+			return nil, fmt.Errorf("line %d: expected \"from => to\"", i+1)
+		}
+
+		rule := Correction{From: from, To: to}
+		if len(from) > 2 && strings.HasPrefix(from, "/") && strings.HasSuffix(from, "/") {
+			rule.From, rule.Regex = from[1:len(from)-1], true
+			if _, err := regexp.Compile(rule.From); err != nil {
+				return nil, fmt.Errorf("line %d: invalid pattern: %w", i+1, err)
+			}
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// FormatCorrections writes rules in the form ParseCorrections reads
+func FormatCorrections(rules []Correction) string {
+	lines := make([]string, len(rules))
+	for i, rule := range rules {
+		from := rule.From
+		if rule.Regex {
+			from = "/" + from + "/"
+		}
+		lines[i] = from + " => " + rule.To
+	}
+	return strings.Join(lines, "\n")
+}
+
+// isWordByte reports whether b is a character \b treats as part of a word
+func isWordByte(b byte) bool {
+	return b == '_' || ('0' <= b && b <= '9') || ('a' <= b && b <= 'z') || ('A' <= b && b <= 'Z')
+}
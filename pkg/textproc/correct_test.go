@@ -0,0 +1,71 @@
+package textproc
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCorrect(t *testing.T) {
+	c, err := NewCorrector([]Correction{
+		{From: "kubernetes", To: "Kubernetes"},
+		{From: "get hub", To: "GitHub"},
+		{From: "c++", To: "C++"},
+		{From: "  ", To: "ignored"},
+		{From: `(\d+) percent`, To: "$1%", Regex: true},
+	})
+	if err != nil {
+		t.Fatalf("NewCorrector failed: %v", err)
+	}
+
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"deploy to KUBERNETES today", "deploy to Kubernetes today"},
+		{"push it to get hub.", "push it to GitHub."},
+		{"forget hub caps", "forget hub caps"},
+		{"written in c++ mostly", "written in C++ mostly"},
+		{"nothing to fix", "nothing to fix"},
+		{"up 12 percent", "up 12%"},
+		{"up 12 Percent", "up 12 Percent"},
+	}
+	for _, tt := range tests {
+		if got := c.Correct(tt.input); got != tt.want {
+			t.Errorf("Correct(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestCorrectorDisabled(t *testing.T) {
+	if c, _ := NewCorrector(nil); c.Enabled() {
+		t.Error("Expected corrector without rules to be disabled")
+	}
+	if _, err := NewCorrector([]Correction{{From: "(", Regex: true}}); err == nil {
+		t.Error("Expected an error for an invalid pattern")
+	}
+}
+
+func TestParseFormatCorrections(t *testing.T) {
+	text := "# Names\nramble => Ramble\n\n/\\bbrb\\b/ => be right back\nuh =>\n"
+	rules, err := ParseCorrections(text)
+	if err != nil {
+		t.Fatalf("ParseCorrections failed: %v", err)
+	}
+	want := []Correction{
+		{From: "ramble", To: "Ramble"},
+		{From: `\bbrb\b`, To: "be right back", Regex: true},
+		{From: "uh", To: ""},
+	}
+	if !reflect.DeepEqual(rules, want) {
+		t.Errorf("ParseCorrections = %+v, want %+v", rules, want)
+	}
+	if got := FormatCorrections(rules); got != "ramble => Ramble\n/\\bbrb\\b/ => be right back\nuh => " {
+		t.Errorf("Unexpected format: %q", got)
+	}
+
+	for _, bad := range []string{"no arrow", " => nothing", "/(/ => x"} {
+		if _, err := ParseCorrections(bad); err == nil {
+			t.Errorf("Expected an error for %q", bad)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package textproc
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// attached are the characters that start a segment joined without a space
+const attached = ",.!?;:)]}%…"
+
+// stops are the characters that end a sentence
+const stops = ".!?…。！？"
+
+// Join appends next, a newly transcribed piece of text, to text. Each live
+// window is decoded on its own, so whisper often leaves a sentence open and
+// starts the next window with a capital letter, and plain spacing gives
+// "hello world This is". Join looks at where text ends and how next starts:
+//   - text ending in a line break, punctuation or a closing bracket at the
+//     start of next, or Chinese or Japanese on both sides, is attached directly
+//   - a capitalized word after a sentence left open starts a new sentence, so
+//     a full stop is added, except for "I" and words in capitals like "NASA"
+//   - anything else is separated by a space
+//
+// A name starting the window after an open sentence is taken as a new
+// sentence too; turning on KeepContext makes that rarer.
+func Join(text, next string) string {
+	text = strings.TrimRight(text, " \t")
+	next = strings.TrimLeftFunc(next, unicode.IsSpace)
+	if text == "" || next == "" || strings.HasSuffix(text, "\n") {
+		return text + next
+	}
+
+	last, _ := utf8.DecodeLastRuneInString(text)
+	first, _ := utf8.DecodeRuneInString(next)
+	switch {
+	case strings.ContainsRune(attached, first), unspaced(last) && unspaced(first):
+		return text + next
+	case (unicode.IsLetter(last) || unicode.IsDigit(last)) && StartsSentence(next):
+		return text + ". " + next
+	}
+	return text + " " + next
+}
+
+// StartsSentence reports whether text starts with a capitalized word that
+// isn't "I" or written in capitals
+func StartsSentence(text string) bool {
+	first, _ := utf8.DecodeRuneInString(text)
+	if !unicode.IsUpper(first) {
+		return false
+	}
+	word := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})[0]
+	return word != strings.ToUpper(word)
+}
+
+// EndsSentence reports whether text is empty or ends with a line break or
+// the end of a sentence, so text joined to it starts a sentence
+func EndsSentence(text string) bool {
+	text = strings.TrimRight(text, " \t")
+	if text == "" || strings.HasSuffix(text, "\n") {
+		return true
+	}
+	last, _ := utf8.DecodeLastRuneInString(text)
+	return strings.ContainsRune(stops, last)
+}
+
+// unspaced reports whether r is written without spaces between words, as in
+// Chinese and Japanese, including their punctuation
+func unspaced(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana) ||
+		(r >= 0x3000 && r <= 0x303F) || (r >= 0xFF00 && r <= 0xFFEF)
+}
@@ -0,0 +1,52 @@
+package textproc
+
+import "testing"
+
+func TestJoin(t *testing.T) {
+	tests := []struct {
+		text, next string
+		want       string
+	}{
+		{"", "Hello", "Hello"},
+		{"Hello", "", "Hello"},
+		{"hello world", "This is", "hello world. This is"},
+		{"hello world ", " this is", "hello world this is"},
+		{"It works.", "This is", "It works. This is"},
+		{"Is it?", "yes", "Is it? yes"},
+		{"Wait,", "What", "Wait, What"},
+		{"and then", "I said", "and then I said"},
+		{"and then", "I'm done", "and then I'm done"},
+		{"we called", "NASA today", "we called NASA today"},
+		{"the answer", ", I think", "the answer, I think"},
+		{"the end", ".", "the end."},
+		{"First paragraph\n\n", "Second", "First paragraph\n\nSecond"},
+		{"about 20", "%", "about 20%"},
+		{"in 2024", "We moved", "in 2024. We moved"},
+		{"今日は", "いい天気", "今日はいい天気"},
+		{"晴れです。", "明日は", "晴れです。明日は"},
+		{"ça va", "Élan", "ça va. Élan"},
+	}
+	for _, tt := range tests {
+		if got := Join(tt.text, tt.next); got != tt.want {
+			t.Errorf("Join(%q, %q) = %q, want %q", tt.text, tt.next, got, tt.want)
+		}
+	}
+}
+
+func TestEndsSentence(t *testing.T) {
+	tests := map[string]bool{
+		"":               true,
+		"It works. ":     true,
+		"Is it?":         true,
+		"First line\n":   true,
+		"晴れです。":          true,
+		"and then we":    false,
+		"the answer, ":   false,
+		"we called NASA": false,
+	}
+	for text, want := range tests {
+		if got := EndsSentence(text); got != want {
+			t.Errorf("EndsSentence(%q) = %v, want %v", text, got, want)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package textproc
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Spotter finds watched keywords in transcribed text
+type Spotter struct {
+	rules []keywordRule
+}
+
+type keywordRule struct {
+	tag     string
+	pattern *regexp.Regexp
+}
+
+// NewSpotter creates a spotter for keywords. A keyword matches as a whole
+// phrase, ignoring case, and tags segments with its lowercase form. A keyword
+// written as /pattern/ is a regular expression, also matched ignoring case,
+// and tags segments with the pattern.
+func NewSpotter(keywords []string) (*Spotter, error) {
+	s := &Spotter{}
+	for _, keyword := range keywords {
+		keyword = strings.TrimSpace(keyword)
+		if keyword == "" {
+			continue
+		}
+
+		if len(keyword) > 2 && strings.HasPrefix(keyword, "/") && strings.HasSuffix(keyword, "/") {
+			expr := keyword[1 : len(keyword)-1]
+			pattern, err := regexp.Compile(`(?i)` + expr)
+			if err != nil {
+				return nil, fmt.Errorf("invalid keyword pattern %s: %w", keyword, err)
+			}
+			s.rules = append(s.rules, keywordRule{tag: expr, pattern: pattern})
+			continue
+		}
+
+		// Spoken phrases may be split by any whitespace
+		words := strings.Fields(keyword)
+		for i, word := range words {
+			words[i] = regexp.QuoteMeta(word)
+		}
+		pattern := strings.Join(words, `\s+`)
+		if isWordByte(keyword[0]) {
+			pattern = `\b` + pattern
+		}
+		if isWordByte(keyword[len(keyword)-1]) {
+			pattern += `\b`
+		}
+		s.rules = append(s.rules, keywordRule{
+			tag:     strings.ToLower(strings.Join(strings.Fields(keyword), " ")),
+			pattern: regexp.MustCompile(`(?i)` + pattern),
+		})
+	}
+	return s, nil
+}
+
+// Enabled reports whether the spotter watches for any keywords
+func (s *Spotter) Enabled() bool {
+	return s != nil && len(s.rules) > 0
+}
+
+// Match returns the tags of the keywords found in text, in the order the
+// keywords were given, each only once
+func (s *Spotter) Match(text string) []string {
+	if !s.Enabled() {
+		return nil
+	}
+	var tags []string
+	for _, rule := range s.rules {
+		if rule.pattern.MatchString(text) && !slices.Contains(tags, rule.tag) {
+			tags = append(tags, rule.tag)
+		}
+	}
+	return tags
+}
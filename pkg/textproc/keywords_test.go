@@ -0,0 +1,38 @@
+package textproc
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSpotter(t *testing.T) {
+	s, err := NewSpotter([]string{"Action Item", "  ", "Sam", "/project (blue|red)bird/", "action   item"})
+	if err != nil {
+		t.Fatalf("NewSpotter failed: %v", err)
+	}
+
+	tests := []struct {
+		input string
+		want  []string
+	}{
+		{"New action\nitem: call the bank", []string{"action item"}},
+		{"Ask sam about Project Bluebird.", []string{"sam", "project (blue|red)bird"}},
+		{"Samantha has the transactional items", nil},
+		{"", nil},
+	}
+	for _, tt := range tests {
+		if got := s.Match(tt.input); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Match(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestSpotterInvalidPattern(t *testing.T) {
+	if _, err := NewSpotter([]string{"/(unclosed/"}); err == nil {
+		t.Error("Expected an error for an invalid pattern")
+	}
+	s, _ := NewSpotter(nil)
+	if s.Enabled() {
+		t.Error("Expected a spotter without keywords to be disabled")
+	}
+}
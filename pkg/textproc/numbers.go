@@ -0,0 +1,599 @@
+package textproc
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// DefaultNumberLocale formats numbers unless another locale is chosen
+const DefaultNumberLocale = "en-US"
+
+// NumberLocale is how a locale writes numbers, dates and times
+type NumberLocale struct {
+	Code      string // e.g. "en-US"
+	Decimal   string // Decimal separator
+	Thousands string // Separator between groups of three digits
+	Percent   string // Written between a number and the % sign
+	DayFirst  bool   // "5 March 2024" rather than "March 5, 2024"
+	Clock24   bool   // "15:30" rather than "3:30 PM"
+	AM, PM    string // Written after 12-hour times
+}
+
+// NumberLocales lists the locales numbers can be formatted for
+var NumberLocales = []NumberLocale{
+	{Code: "en-US", Decimal: ".", Thousands: ",", AM: "AM", PM: "PM"},
+	{Code: "en-GB", Decimal: ".", Thousands: ",", DayFirst: true, AM: "am", PM: "pm"},
+	{Code: "de-DE", Decimal: ",", Thousands: ".", Percent: " ", DayFirst: true, Clock24: true},
+	{Code: "fr-FR", Decimal: ",", Thousands: " ", Percent: " ", DayFirst: true, Clock24: true},
+}
+
+// LookupNumberLocale returns the locale with a code, or the default locale
+// if it isn't known
+func LookupNumberLocale(code string) NumberLocale {
+	for _, l := range NumberLocales {
+		if strings.EqualFold(l.Code, strings.TrimSpace(code)) {
+			return l
+		}
+	}
+	return NumberLocales[0]
+}
+
+var (
+	// Words and whisper's spellings of a.m. and p.m.
+	numberToken = regexp.MustCompile(`(?i)\b[ap]\.m\.?|[a-z]+(?:['’][a-z]+)?`)
+
+	units = map[string]int{
+		"zero": 0, "one": 1, "two": 2, "three": 3, "four": 4, "five": 5, "six": 6,
+		"seven": 7, "eight": 8, "nine": 9, "ten": 10, "eleven": 11, "twelve": 12,
+		"thirteen": 13, "fourteen": 14, "fifteen": 15, "sixteen": 16,
+		"seventeen": 17, "eighteen": 18, "nineteen": 19,
+	}
+	tens = map[string]int{
+		"twenty": 20, "thirty": 30, "forty": 40, "fifty": 50, "sixty": 60,
+		"seventy": 70, "eighty": 80, "ninety": 90,
+	}
+	scales = map[string]int{"thousand": 1_000, "million": 1_000_000, "billion": 1_000_000_000}
+
+	// Ordinals that aren't just the cardinal followed by "th"
+	irregularOrdinals = map[string]string{
+		"first": "one", "second": "two", "third": "three", "fifth": "five",
+		"eighth": "eight", "ninth": "nine", "twelfth": "twelve",
+	}
+
+	months = []string{
+		"January", "February", "March", "April", "May", "June", "July",
+		"August", "September", "October", "November", "December",
+	}
+)
+
+// numberWord is a word of the text being normalized
+type numberWord struct {
+	start, end int
+	text       string // As written
+	word       string // Lowercase, with ordinals turned into their cardinal
+	ordinal    bool
+	gap        string // Text between the previous word and this one
+	joined     bool   // Only spaces or a hyphen separate it from the previous word
+}
+
+// NumberNormalizer writes spoken numbers, percentages, dates and times as
+// digits, e.g. "twenty three percent" as "23%"
+type NumberNormalizer struct {
+	locale NumberLocale
+}
+
+// NewNumberNormalizer creates a normalizer formatting for a locale code such
+// as "en-GB". Unknown codes use DefaultNumberLocale.
+func NewNumberNormalizer(locale string) *NumberNormalizer {
+	return &NumberNormalizer{locale: LookupNumberLocale(locale)}
+}
+
+// Normalize rewrites the spoken numbers in text. Single words below ten are
+// kept as words unless they are part of a larger number, a date, a time or a
+// percentage, so "one of them" stays as it is.
+func (n *NumberNormalizer) Normalize(text string) string {
+	words := splitNumberWords(text)
+	var b strings.Builder
+	last := 0
+	for i := 0; i < len(words); {
+		replacement, used := n.match(text, words, i)
+		if used == 0 {
+			i++
+			continue
+		}
+		b.WriteString(text[last:words[i].start])
+		b.WriteString(replacement)
+		last = words[i+used-1].end
+		i += used
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
+
+// splitNumberWords finds the words in text
+func splitNumberWords(text string) []numberWord {
+	var words []numberWord
+	prev := 0
+	for _, loc := range numberToken.FindAllStringIndex(text, -1) {
+		w := numberWord{start: loc[0], end: loc[1], text: text[loc[0]:loc[1]], gap: text[prev:loc[0]]}
+		w.word = strings.ReplaceAll(strings.ToLower(w.text), "’", "'")
+		w.joined = w.gap == "-" || (w.gap != "" && strings.TrimSpace(w.gap) == "")
+		w.word, w.ordinal = cardinalOf(w.word)
+		words = append(words, w)
+		prev = loc[1]
+	}
+	return words
+}
+
+// cardinalOf returns the cardinal of an ordinal word such as "twentieth"
+func cardinalOf(word string) (string, bool) {
+	if cardinal, ok := irregularOrdinals[word]; ok {
+		return cardinal, true
+	}
+	if base, ok := strings.CutSuffix(word, "ieth"); ok {
+		if _, ok := tens[base+"y"]; ok {
+			return base + "y", true
+		}
+	}
+	if base, ok := strings.CutSuffix(word, "th"); ok {
+		if _, ok := units[base]; ok {
+			return base, true
+		}
+		if _, ok := scales[base]; ok || base == "hundred" {
+			return base, true
+		}
+	}
+	return word, false
+}
+
+// match returns the replacement for the words starting at i and how many
+// words it replaces, or 0 if they aren't a number
+func (n *NumberNormalizer) match(text string, words []numberWord, i int) (string, int) {
+	if s, used := n.matchDate(words, i); used > 0 {
+		return s, used
+	}
+	if s, used := n.matchTime(text, words, i); used > 0 {
+		return s, used
+	}
+	if year, used := yearAt(words, i); used > 0 {
+		return strconv.Itoa(year), used
+	}
+	return n.matchNumber(words, i)
+}
+
+// matchNumber matches a cardinal or ordinal number, a decimal or a percentage
+func (n *NumberNormalizer) matchNumber(words []numberWord, i int) (string, int) {
+	v, used, ordinal := cardinalAt(words, i)
+	if used == 0 {
+		return "", 0
+	}
+	if ordinal {
+		if used == 1 && v < 10 {
+			return "", 0
+		}
+		return n.formatInt(v) + n.ordinalSuffix(v), used
+	}
+
+	out := n.formatInt(v)
+	plain := true
+	k := i + used
+	if joinedWord(words, k, "point") {
+		var digits string
+		for j := k + 1; j < len(words) && words[j].joined && !words[j].ordinal; j++ {
+			d, ok := units[words[j].word]
+			if !ok || d > 9 {
+				break
+			}
+			digits += strconv.Itoa(d)
+		}
+		if digits != "" {
+			out = n.formatInt(v) + n.locale.Decimal + digits
+			k += 1 + len(digits)
+			plain = false
+		}
+	}
+
+	// Round millions and billions read better as "2 million"
+	for _, scale := range []string{"billion", "million"} {
+		if plain && v >= scales[scale] && v%scales[scale] == 0 {
+			out = n.formatInt(v/scales[scale]) + " " + scale
+			break
+		}
+		if !plain && joinedWord(words, k, scale) && !words[k].ordinal {
+			out += " " + scale
+			k++
+			break
+		}
+	}
+
+	switch {
+	case joinedWord(words, k, "percent"):
+		out += n.locale.Percent + "%"
+		k++
+		plain = false
+	case joinedWord(words, k, "per") && joinedWord(words, k+1, "cent"):
+		out += n.locale.Percent + "%"
+		k += 2
+		plain = false
+	}
+
+	if plain && used == 1 && v < 10 && !nextToNumber(words, i, used) {
+		return "", 0
+	}
+	return out, k - i
+}
+
+// matchTime matches a time such as "three thirty pm", "seven o'clock" or,
+// after "at", "ten fifteen"
+func (n *NumberNormalizer) matchTime(text string, words []numberWord, i int) (string, int) {
+	hour, used, ordinal := belowHundredAt(words, i)
+	if used != 1 || ordinal || hour < 1 || hour > 12 {
+		return "", 0
+	}
+
+	k := i + 1
+	minute := -1
+	oclock := false
+	switch {
+	case joinedWord(words, k, "oh"):
+		if d, ok := digitAt(words, k+1); ok && d > 0 {
+			minute = d
+			k += 2
+		}
+	case joinedWord(words, k, "o'clock") || joinedWord(words, k, "oclock"):
+		minute, oclock = 0, true
+		k++
+	default:
+		if m, mUsed, mOrdinal := belowHundredAt(words, k); mUsed > 0 && !mOrdinal && m >= 10 && m < 60 && words[k].joined {
+			minute = m
+			k += mUsed
+		}
+	}
+
+	meridiem := ""
+	if k < len(words) && words[k].joined {
+		switch strings.ReplaceAll(words[k].word, ".", "") {
+		case "am":
+			meridiem = "am"
+		case "pm":
+			meridiem = "pm"
+		}
+	}
+	afterAt := i > 0 && words[i].joined && words[i-1].word == "at"
+	if meridiem == "" && !oclock && !(afterAt && minute >= 0) {
+		return "", 0
+	}
+
+	out := n.formatTime(hour, minute, meridiem)
+	if meridiem != "" {
+		// Keep a full stop that ended the sentence as well as "p.m."
+		if strings.HasSuffix(words[k].text, ".") && sentenceEnds(text[words[k].end:]) {
+			out += "."
+		}
+		k++
+	}
+	return out, k - i
+}
+
+// matchDate matches a date such as "March fifth twenty twenty four" or "the
+// fifth of March". Month names must be capitalized, so "may" stays a verb.
+func (n *NumberNormalizer) matchDate(words []numberWord, i int) (string, int) {
+	k := i
+	if month := monthAt(words, k); month > 0 {
+		k++
+		if joinedWord(words, k, "the") {
+			k++
+		}
+		if _, used := yearAt(words, k); used > 0 || k >= len(words) || !words[k].joined {
+			return "", 0
+		}
+		day, used, _ := belowHundredAt(words, k)
+		if used == 0 || day < 1 || day > 31 {
+			return "", 0
+		}
+		k += used
+		year, yearUsed := dateYearAt(words, k)
+		return n.formatDate(day, month, year), k + yearUsed - i
+	}
+
+	if words[k].word == "the" {
+		k++
+		if k >= len(words) || !words[k].joined {
+			return "", 0
+		}
+	}
+	day, used, ordinal := belowHundredAt(words, k)
+	if used == 0 || !ordinal || day < 1 || day > 31 {
+		return "", 0
+	}
+	k += used
+	if !joinedWord(words, k, "of") || k+1 >= len(words) || !words[k+1].joined {
+		return "", 0
+	}
+	month := monthAt(words, k+1)
+	if month == 0 {
+		return "", 0
+	}
+	k += 2
+	year, yearUsed := dateYearAt(words, k)
+	return n.formatDate(day, month, year), k + yearUsed - i
+}
+
+// monthAt returns the month named by a capitalized word, or 0
+func monthAt(words []numberWord, i int) int {
+	if i >= len(words) {
+		return 0
+	}
+	first, _ := utf8.DecodeRuneInString(words[i].text)
+	if !unicode.IsUpper(first) {
+		return 0
+	}
+	for m, name := range months {
+		if strings.EqualFold(words[i].text, name) {
+			return m + 1
+		}
+	}
+	return 0
+}
+
+// dateYearAt matches the year that may follow the day of a date, after a
+// space or a comma
+func dateYearAt(words []numberWord, i int) (int, int) {
+	if i >= len(words) || !(words[i].joined || words[i].gap == ", ") {
+		return 0, 0
+	}
+	if year, used := yearAt(words, i); used > 0 {
+		return year, used
+	}
+	if year, used, ordinal := cardinalAt(words, i); used > 0 && !ordinal && year >= 1000 && year < 3000 {
+		return year, used
+	}
+	return 0, 0
+}
+
+// yearAt matches a year read as two pairs of digits, such as "nineteen
+// eighty four" or "twenty oh five"
+func yearAt(words []numberWord, i int) (int, int) {
+	century, used, ordinal := belowHundredAt(words, i)
+	if used != 1 || ordinal || (century != 19 && century != 20) {
+		return 0, 0
+	}
+	k := i + 1
+	if joinedWord(words, k, "oh") {
+		if d, ok := digitAt(words, k+1); ok && d > 0 {
+			return century*100 + d, 3
+		}
+		return 0, 0
+	}
+	if k >= len(words) || !words[k].joined {
+		return 0, 0
+	}
+	rest, restUsed, restOrdinal := belowHundredAt(words, k)
+	if restUsed == 0 || restOrdinal || rest < 10 {
+		return 0, 0
+	}
+	return century*100 + rest, 1 + restUsed
+}
+
+// cardinalAt matches a number such as "two thousand three hundred and five".
+// It returns the number, how many words it took and whether the last word
+// was an ordinal.
+func cardinalAt(words []numberWord, i int) (int, int, bool) {
+	total, used := 0, 0
+	lastScale := 0
+	for {
+		k := i + used
+		if used > 0 && (k >= len(words) || !words[k].joined) {
+			break
+		}
+		group, groupUsed, ordinal := belowThousandAt(words, k)
+		if groupUsed == 0 {
+			break
+		}
+		used += groupUsed
+		if ordinal {
+			return total + group, used, true
+		}
+
+		s := k + groupUsed
+		scale, ok := 0, false
+		if s < len(words) && words[s].joined {
+			scale, ok = scales[words[s].word]
+		}
+		if !ok || group == 0 || (lastScale > 0 && scale >= lastScale) {
+			total += group
+			break
+		}
+		total += group * scale
+		lastScale = scale
+		used++
+		if words[s].ordinal {
+			return total, used, true
+		}
+		// "two thousand and five"
+		if joinedWord(words, s+1, "and") && s+2 < len(words) && words[s+2].joined {
+			if _, rest, _ := belowThousandAt(words, s+2); rest > 0 {
+				used++
+			}
+		}
+	}
+	return total, used, false
+}
+
+// belowThousandAt matches a number below a thousand, such as "a hundred" or
+// "three hundred and twelve"
+func belowThousandAt(words []numberWord, i int) (int, int, bool) {
+	v, used, ordinal := belowHundredAt(words, i)
+	if used == 0 && i < len(words) && words[i].word == "a" && i+1 < len(words) && words[i+1].joined {
+		if _, ok := scales[words[i+1].word]; ok || words[i+1].word == "hundred" {
+			v, used = 1, 1
+		}
+	}
+	if used == 0 || ordinal || v == 0 {
+		return v, used, ordinal
+	}
+
+	k := i + used
+	if !joinedWord(words, k, "hundred") {
+		return v, used, false
+	}
+	v *= 100
+	used++
+	if words[k].ordinal {
+		return v, used, true
+	}
+	rest := k + 1
+	if joinedWord(words, rest, "and") {
+		rest++
+	}
+	if rest < len(words) && words[rest].joined {
+		if r, rUsed, rOrdinal := belowHundredAt(words, rest); rUsed > 0 && r > 0 {
+			return v + r, rest - i + rUsed, rOrdinal
+		}
+	}
+	return v, used, false
+}
+
+// belowHundredAt matches a number below a hundred, such as "forty two"
+func belowHundredAt(words []numberWord, i int) (int, int, bool) {
+	if i >= len(words) {
+		return 0, 0, false
+	}
+	w := words[i]
+	if v, ok := tens[w.word]; ok {
+		if !w.ordinal && i+1 < len(words) && words[i+1].joined {
+			if u, ok := units[words[i+1].word]; ok && u > 0 && u < 10 {
+				return v + u, 2, words[i+1].ordinal
+			}
+		}
+		return v, 1, w.ordinal
+	}
+	if v, ok := units[w.word]; ok {
+		return v, 1, w.ordinal
+	}
+	return 0, 0, false
+}
+
+// digitAt matches a single digit word that follows the previous word
+func digitAt(words []numberWord, i int) (int, bool) {
+	if i >= len(words) || !words[i].joined || words[i].ordinal {
+		return 0, false
+	}
+	d, ok := units[words[i].word]
+	return d, ok && d < 10
+}
+
+// joinedWord reports whether words[i] is word and follows the previous word
+func joinedWord(words []numberWord, i int, word string) bool {
+	return i < len(words) && words[i].joined && words[i].word == word
+}
+
+// nextToNumber reports whether the number in words[i:i+used] follows or is
+// followed by another number, as in "one two three"
+func nextToNumber(words []numberWord, i, used int) bool {
+	isNumber := func(j int) bool {
+		if j < 0 || j >= len(words) || words[j].ordinal {
+			return false
+		}
+		_, unit := units[words[j].word]
+		_, ten := tens[words[j].word]
+		return unit || ten
+	}
+	return (words[i].joined && isNumber(i-1)) || (i+used < len(words) && words[i+used].joined && isNumber(i+used))
+}
+
+// sentenceEnds reports whether the text after a full stop starts a new sentence
+func sentenceEnds(rest string) bool {
+	rest = strings.TrimLeft(rest, " ")
+	if rest == "" {
+		return true
+	}
+	first, _ := utf8.DecodeRuneInString(rest)
+	return unicode.IsUpper(first) || unicode.IsSpace(first)
+}
+
+// formatInt writes a whole number, grouping digits from 10,000 up so years
+// and other four-digit numbers stay as they are
+func (n *NumberNormalizer) formatInt(v int) string {
+	s := strconv.Itoa(v)
+	if v < 10_000 || n.locale.Thousands == "" {
+		return s
+	}
+	var b strings.Builder
+	for i, r := range s {
+		if i > 0 && (len(s)-i)%3 == 0 {
+			b.WriteString(n.locale.Thousands)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// ordinalSuffix returns what follows the digits of an ordinal, e.g. "st" in "21st"
+func (n *NumberNormalizer) ordinalSuffix(v int) string {
+	switch {
+	case strings.HasPrefix(n.locale.Code, "de"):
+		return "."
+	case strings.HasPrefix(n.locale.Code, "fr"):
+		if v == 1 {
+			return "er"
+		}
+		return "e"
+	}
+	if v%100 >= 11 && v%100 <= 13 {
+		return "th"
+	}
+	switch v % 10 {
+	case 1:
+		return "st"
+	case 2:
+		return "nd"
+	case 3:
+		return "rd"
+	}
+	return "th"
+}
+
+// formatTime writes a time; minute is -1 when none was said and meridiem is
+// "am", "pm" or ""
+func (n *NumberNormalizer) formatTime(hour, minute int, meridiem string) string {
+	if n.locale.Clock24 {
+		if meridiem == "pm" && hour < 12 {
+			hour += 12
+		} else if meridiem == "am" && hour == 12 {
+			hour = 0
+		}
+		return fmt.Sprintf("%02d:%02d", hour, max(minute, 0))
+	}
+
+	s := strconv.Itoa(hour)
+	if minute >= 0 {
+		s += fmt.Sprintf(":%02d", minute)
+	}
+	switch meridiem {
+	case "am":
+		s += " " + n.locale.AM
+	case "pm":
+		s += " " + n.locale.PM
+	}
+	return s
+}
+
+// formatDate writes a date with the month's name; year is 0 when none was said
+func (n *NumberNormalizer) formatDate(day, month, year int) string {
+	name := months[month-1]
+	if n.locale.DayFirst {
+		if year > 0 {
+			return fmt.Sprintf("%d %s %d", day, name, year)
+		}
+		return fmt.Sprintf("%d %s", day, name)
+	}
+	if year > 0 {
+		return fmt.Sprintf("%s %d, %d", name, day, year)
+	}
+	return fmt.Sprintf("%s %d", name, day)
+}
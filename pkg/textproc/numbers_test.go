@@ -0,0 +1,64 @@
+package textproc
+
+import "testing"
+
+func TestNormalizeNumbers(t *testing.T) {
+	n := NewNumberNormalizer("en-US")
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"sales rose twenty three percent", "sales rose 23%"},
+		{"a hundred per cent sure", "100% sure"},
+		{"three point one four is pi", "3.14 is pi"},
+		{"one of them said so", "one of them said so"},
+		{"wait a second", "wait a second"},
+		{"seven percent", "7%"},
+		{"twenty-five people and one hundred and five chairs", "25 people and 105 chairs"},
+		{"two thousand three hundred and twelve", "2312"},
+		{"forty five thousand six hundred", "45,600"},
+		{"two million users", "2 million users"},
+		{"one point five million", "1.5 million"},
+		{"the twenty first century", "the 21st century"},
+		{"first and second place", "first and second place"},
+		{"dial one two three", "dial 1 2 3"},
+		{"back in nineteen ninety nine", "back in 1999"},
+		{"due March fifth twenty twenty four.", "due March 5, 2024."},
+		{"on the third of July", "on July 3"},
+		{"March twenty twenty four", "March 2024"},
+		{"you may second that", "you may second that"},
+		{"meet at three thirty pm", "meet at 3:30 PM"},
+		{"at ten fifteen we left", "at 10:15 we left"},
+		{"ten fifteen people", "10 15 people"},
+		{"seven o'clock", "7:00"},
+		{"by nine oh five a.m. Then lunch", "by 9:05 AM. Then lunch"},
+		{"at nine a.m. and noon", "at 9 AM and noon"},
+		{"nothing to see", "nothing to see"},
+	}
+	for _, tt := range tests {
+		if got := n.Normalize(tt.input); got != tt.want {
+			t.Errorf("Normalize(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeNumbersLocales(t *testing.T) {
+	tests := []struct {
+		locale string
+		input  string
+		want   string
+	}{
+		{"en-GB", "due March fifth twenty twenty four", "due 5 March 2024"},
+		{"en-GB", "at three thirty pm", "at 3:30 pm"},
+		{"de-DE", "three point five percent of twelve thousand five hundred", "3,5 % of 12.500"},
+		{"de-DE", "at three thirty pm", "at 15:30"},
+		{"de-DE", "the twenty first", "the 21."},
+		{"fr-FR", "the first of May", "1 May"},
+		{"xx", "twelve percent", "12%"},
+	}
+	for _, tt := range tests {
+		if got := NewNumberNormalizer(tt.locale).Normalize(tt.input); got != tt.want {
+			t.Errorf("%s: Normalize(%q) = %q, want %q", tt.locale, tt.input, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+// Package textproc post-processes transcribed text before it is shown, copied or saved
+package textproc
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// RedactOptions selects what a Redactor masks
+type RedactOptions struct {
+	Profanity    bool     // Mask common profanity, keeping the first letter
+	Emails       bool     // Replace email addresses with [email]
+	PhoneNumbers bool     // Replace phone numbers with [phone]
+	CardNumbers  bool     // Replace credit-card-like digit runs with [card]
+	Patterns     []string // User-defined regular expressions, replaced with [redacted]
+}
+
+// profanityWords is the built-in word list; matches are whole words, case-insensitive
+var profanityWords = []string{
+	"arse", "arsehole", "ass", "asshole", "bastard", "bitch", "bollocks",
+	"bullshit", "crap", "cunt", "damn", "dick", "dickhead", "fuck", "fucked",
+	"fucker", "fucking", "goddamn", "motherfucker", "piss", "pissed", "prick",
+	"shit", "shitty", "slut", "twat", "wanker", "whore",
+}
+
+var (
+	emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+
+	// North American and international numbers with common separators
+	phonePattern = regexp.MustCompile(`(?:\+\d{1,3}[\s.-]?)?(?:\(\d{3}\)|\b\d{3})[\s.-]?\d{3}[\s.-]?\d{4}\b`)
+
+	// 13-19 digits, optionally grouped with spaces or dashes
+	cardPattern = regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`)
+
+	profanityPattern = regexp.MustCompile(`(?i)\b(?:` + strings.Join(profanityWords, "|") + `)\b`)
+)
+
+// Redactor masks sensitive or unwanted text
+type Redactor struct {
+	opts     RedactOptions
+	patterns []*regexp.Regexp
+}
+
+// NewRedactor creates a redactor, compiling any user-defined patterns
+func NewRedactor(opts RedactOptions) (*Redactor, error) {
+	r := &Redactor{opts: opts}
+	for _, p := range opts.Patterns {
+		if p == "" {
+			continue
+		}
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redaction pattern %q: %w", p, err)
+		}
+		r.patterns = append(r.patterns, re)
+	}
+	return r, nil
+}
+
+// Enabled reports whether the redactor masks anything at all
+func (r *Redactor) Enabled() bool {
+	return r != nil && (r.opts.Profanity || r.opts.Emails || r.opts.PhoneNumbers ||
+		r.opts.CardNumbers || len(r.patterns) > 0)
+}
+
+// Redact returns text with everything selected by the options masked
+func (r *Redactor) Redact(text string) string {
+	if !r.Enabled() || text == "" {
+		return text
+	}
+
+	// User patterns first, so they can target text the built-in rules would rewrite
+	for _, re := range r.patterns {
+		text = re.ReplaceAllString(text, "[redacted]")
+	}
+
+	if r.opts.Emails {
+		text = emailPattern.ReplaceAllString(text, "[email]")
+	}
+
+	// Card numbers before phone numbers, which would otherwise match part of them
+	if r.opts.CardNumbers {
+		text = cardPattern.ReplaceAllStringFunc(text, func(match string) string {
+			if !luhnValid(match) {
+				return match
+			}
+			return "[card]"
+		})
+	}
+
+	if r.opts.PhoneNumbers {
+		text = phonePattern.ReplaceAllString(text, "[phone]")
+	}
+
+	if r.opts.Profanity {
+		text = profanityPattern.ReplaceAllStringFunc(text, maskWord)
+	}
+
+	return text
+}
+
+// maskWord keeps the first letter of a word and replaces the rest with asterisks
+func maskWord(word string) string {
+	runes := []rune(word)
+	return string(runes[0]) + strings.Repeat("*", len(runes)-1)
+}
+
+// luhnValid reports whether the digits in s pass the Luhn checksum used by card numbers
+func luhnValid(s string) bool {
+	sum := 0
+	double := false
+	for i := len(s) - 1; i >= 0; i-- {
+		c := rune(s[i])
+		if !unicode.IsDigit(c) {
+			continue
+		}
+		d := int(c - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
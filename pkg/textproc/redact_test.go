@@ -0,0 +1,59 @@
+package textproc
+
+import "testing"
+
+func TestRedact(t *testing.T) {
+	r, err := NewRedactor(RedactOptions{
+		Profanity:    true,
+		Emails:       true,
+		PhoneNumbers: true,
+		CardNumbers:  true,
+		Patterns:     []string{`(?i)project \w+`},
+	})
+	if err != nil {
+		t.Fatalf("NewRedactor failed: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"email", "Mail jane.doe@example.com today", "Mail [email] today"},
+		{"phone", "Call (555) 123-4567 or +1 555.987.6543", "Call [phone] or [phone]"},
+		{"card", "Card 4111 1111 1111 1111 expires soon", "Card [card] expires soon"},
+		{"not a card", "Order 1234567890123 shipped", "Order 1234567890123 shipped"},
+		{"profanity", "Well Shit, that damn thing", "Well S***, that d*** thing"},
+		{"no partial words", "Assess the classic scrapbook", "Assess the classic scrapbook"},
+		{"custom pattern", "Status of Project Falcon", "Status of [redacted]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := r.Redact(tt.input); got != tt.want {
+				t.Errorf("Redact(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRedactDisabled(t *testing.T) {
+	r, err := NewRedactor(RedactOptions{})
+	if err != nil {
+		t.Fatalf("NewRedactor failed: %v", err)
+	}
+	if r.Enabled() {
+		t.Error("Expected redactor with no options to be disabled")
+	}
+
+	text := "Call 555-123-4567, damn it"
+	if got := r.Redact(text); got != text {
+		t.Errorf("Expected text unchanged, got %q", got)
+	}
+}
+
+func TestInvalidPattern(t *testing.T) {
+	if _, err := NewRedactor(RedactOptions{Patterns: []string{"("}}); err == nil {
+		t.Error("Expected an error for an invalid pattern")
+	}
+}
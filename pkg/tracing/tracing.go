@@ -0,0 +1,259 @@
+// Package tracing records how long audio spends in each stage of live
+// transcription - waiting in the capture queue, waiting for a pass, being
+// transcribed and being shown - and writes it as a Chrome trace, which can
+// be opened in chrome://tracing or https://ui.perfetto.dev to see where
+// latency comes from.
+package tracing
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// maxEvents bounds the events kept, so tracing a long session can't use up
+// memory; events past it are dropped
+const maxEvents = 200000
+
+// Tracks the stages are drawn on, one per row of the trace
+const (
+	trackQueue = iota + 1
+	trackTranscriber
+	trackUI
+)
+
+var trackNames = map[int]string{
+	trackQueue:       "Capture queue",
+	trackTranscriber: "Transcriber",
+	trackUI:          "UI",
+}
+
+// Event is one entry of the Chrome trace event format. Times are in
+// microseconds from the start of the trace.
+type Event struct {
+	Name     string         `json:"name"`
+	Category string         `json:"cat,omitempty"`
+	Phase    string         `json:"ph"`
+	Time     float64        `json:"ts"`
+	Duration float64        `json:"dur,omitempty"`
+	Process  int            `json:"pid"`
+	Thread   int            `json:"tid"`
+	Scope    string         `json:"s,omitempty"`
+	Args     map[string]any `json:"args,omitempty"`
+}
+
+// block is audio delivered by one capture callback
+type block struct {
+	samples int
+	at      time.Time
+}
+
+// chunk is audio handed to the transcriber at once
+type chunk struct {
+	id       int
+	captured time.Time // When its oldest sample was captured
+	handed   time.Time // When it was handed to the transcriber
+}
+
+// Tracer records the stages of each chunk of audio. The stages are reported
+// in pipeline order: Captured from the capture callback, Dequeued as the
+// audio is handed to the transcriber, Pass once a pass ends and Delivered
+// once its text is shown. A nil Tracer records nothing, so tracing costs
+// nothing when off. It is safe for concurrent use.
+type Tracer struct {
+	mu      sync.Mutex
+	start   time.Time
+	events  []Event
+	dropped int     // Events past maxEvents
+	queue   []block // Captured audio not yet dequeued, oldest first
+	pending []chunk // Chunks handed to the transcriber but not yet transcribed
+	chunks  int
+}
+
+// New starts a trace
+func New() *Tracer {
+	t := &Tracer{start: time.Now()}
+	for track, name := range trackNames {
+		t.events = append(t.events, Event{Name: "thread_name", Phase: "M", Process: 1, Thread: track, Args: map[string]any{"name": name}})
+	}
+	return t
+}
+
+// Begin marks the start of a recording, forgetting audio of the last one
+func (t *Tracer) Begin() {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.queue, t.pending = nil, nil
+	t.add(Event{Name: "Recording", Phase: "i", Time: t.micros(time.Now()), Process: 1, Thread: trackQueue, Scope: "p"})
+}
+
+// Captured notes samples delivered by the capture callback
+func (t *Tracer) Captured(samples int) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.queue = append(t.queue, block{samples: samples, at: time.Now()})
+}
+
+// Dropped notes the oldest samples dropped from a full capture queue
+func (t *Tracer) Dropped(samples int) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.take(samples)
+}
+
+// Dequeued notes samples read from the capture queue and handed to the
+// transcriber, as one chunk
+func (t *Tracer) Dequeued(samples int) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	captured := t.take(samples)
+	if captured.IsZero() {
+		captured = now
+	}
+	t.chunks++
+	t.pending = append(t.pending, chunk{id: t.chunks, captured: captured, handed: now})
+	t.span("Queued", trackQueue, captured, now, map[string]any{"chunk": t.chunks, "samples": samples})
+}
+
+// Pass notes a transcription pass that just ended after elapsed. It covers
+// the chunks handed to the transcriber before it started.
+func (t *Tracer) Pass(window, elapsed time.Duration) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	end := time.Now()
+	start := end.Add(-elapsed)
+	covered := 0
+	for covered < len(t.pending) && !t.pending[covered].handed.After(start) {
+		covered++
+	}
+	args := map[string]any{"window_ms": window.Milliseconds()}
+	if covered > 0 {
+		first, last := t.pending[0], t.pending[covered-1]
+		args["chunks"] = fmt.Sprintf("%d-%d", first.id, last.id)
+		args["latency_ms"] = end.Sub(first.captured).Milliseconds()
+		t.span("Waiting", trackTranscriber, first.handed, start, map[string]any{"chunks": args["chunks"]})
+		t.pending = append(t.pending[:0], t.pending[covered:]...)
+	}
+	t.span("Transcribing", trackTranscriber, start, end, args)
+}
+
+// Delivered notes text sent by the transcriber at started that has now been
+// shown
+func (t *Tracer) Delivered(started time.Time, chars int) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.span("Showing", trackUI, started, time.Now(), map[string]any{"chars": chars})
+}
+
+// take removes samples from the front of the capture queue, returning when
+// the oldest of them was captured
+func (t *Tracer) take(samples int) time.Time {
+	var oldest time.Time
+	for samples > 0 && len(t.queue) > 0 {
+		front := &t.queue[0]
+		if oldest.IsZero() {
+			oldest = front.at
+		}
+		if front.samples > samples {
+			front.samples -= samples
+			break
+		}
+		samples -= front.samples
+		t.queue = t.queue[1:]
+	}
+	return oldest
+}
+
+// span adds a complete event from start to end
+func (t *Tracer) span(name string, track int, start, end time.Time, args map[string]any) {
+	t.add(Event{
+		Name:     name,
+		Category: "latency",
+		Phase:    "X",
+		Time:     t.micros(start),
+		Duration: float64(end.Sub(start).Microseconds()),
+		Process:  1,
+		Thread:   track,
+		Args:     args,
+	})
+}
+
+// add keeps an event unless the trace is full
+func (t *Tracer) add(event Event) {
+	if len(t.events) >= maxEvents {
+		t.dropped++
+		return
+	}
+	t.events = append(t.events, event)
+}
+
+// micros converts a time to microseconds from the start of the trace
+func (t *Tracer) micros(at time.Time) float64 {
+	return float64(at.Sub(t.start).Microseconds())
+}
+
+// Write writes the trace in the Chrome trace JSON format
+func (t *Tracer) Write(w io.Writer) error {
+	t.mu.Lock()
+	trace := struct {
+		Events   []Event           `json:"traceEvents"`
+		Unit     string            `json:"displayTimeUnit"`
+		Metadata map[string]string `json:"otherData"`
+	}{
+		Events:   append([]Event(nil), t.events...),
+		Unit:     "ms",
+		Metadata: map[string]string{"started": t.start.Format(time.RFC3339)},
+	}
+	if t.dropped > 0 {
+		trace.Metadata["dropped_events"] = fmt.Sprint(t.dropped)
+	}
+	t.mu.Unlock()
+
+	buf := bufio.NewWriter(w)
+	if err := json.NewEncoder(buf).Encode(trace); err != nil {
+		return err
+	}
+	return buf.Flush()
+}
+
+// WriteFile writes the trace to path, replacing what an earlier call wrote
+func (t *Tracer) WriteFile(path string) error {
+	if t == nil {
+		return nil
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create trace file: %w", err)
+	}
+	if err := t.Write(file); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write trace file: %w", err)
+	}
+	return file.Close()
+}
@@ -0,0 +1,91 @@
+package tracing
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+// decode writes the trace and returns its events by name
+func decode(t *testing.T, tracer *Tracer) map[string][]Event {
+	var out strings.Builder
+	if err := tracer.Write(&out); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	var trace struct {
+		Events []Event `json:"traceEvents"`
+	}
+	if err := json.Unmarshal([]byte(out.String()), &trace); err != nil {
+		t.Fatalf("Expected valid JSON, got %v", err)
+	}
+	byName := map[string][]Event{}
+	for _, event := range trace.Events {
+		byName[event.Name] = append(byName[event.Name], event)
+	}
+	return byName
+}
+
+func TestTracerStages(t *testing.T) {
+	tracer := New()
+	tracer.Begin()
+	tracer.Captured(100)
+	tracer.Captured(100)
+	time.Sleep(2 * time.Millisecond)
+	tracer.Dropped(50)
+	tracer.Dequeued(150) // Both blocks, after the dropped samples
+	tracer.Captured(100)
+	tracer.Dequeued(100)
+	time.Sleep(2 * time.Millisecond)
+	tracer.Pass(time.Second, time.Millisecond)
+	tracer.Delivered(time.Now(), 5)
+
+	events := decode(t, tracer)
+	if len(events["thread_name"]) != 3 || len(events["Recording"]) != 1 {
+		t.Errorf("Expected track names and the recording start, got %v", events)
+	}
+	queued := events["Queued"]
+	if len(queued) != 2 || queued[0].Duration < 2000 || queued[0].Args["chunk"] != 1.0 {
+		t.Errorf("Expected the first chunk to have waited in the queue, got %+v", queued)
+	}
+	transcribing := events["Transcribing"]
+	if len(transcribing) != 1 || transcribing[0].Args["chunks"] != "1-2" || transcribing[0].Thread != trackTranscriber {
+		t.Errorf("Expected one pass covering both chunks, got %+v", transcribing)
+	}
+	if latency := transcribing[0].Args["latency_ms"].(float64); latency < 4 {
+		t.Errorf("Expected the latency from capture, got %vms", latency)
+	}
+	if len(events["Waiting"]) != 1 || len(events["Showing"]) != 1 {
+		t.Errorf("Expected the wait for the pass and the UI update, got %v", events)
+	}
+}
+
+func TestTracerPassCoversEarlierChunks(t *testing.T) {
+	tracer := New()
+	tracer.Captured(100)
+	tracer.Dequeued(100)
+	time.Sleep(5 * time.Millisecond)
+	tracer.Captured(100)
+	tracer.Dequeued(100) // Handed while the pass ran
+
+	tracer.Pass(time.Second, 3*time.Millisecond)
+	time.Sleep(3 * time.Millisecond)
+	tracer.Pass(time.Second, time.Millisecond)
+
+	transcribing := decode(t, tracer)["Transcribing"]
+	if len(transcribing) != 2 || transcribing[0].Args["chunks"] != "1-1" || transcribing[1].Args["chunks"] != "2-2" {
+		t.Errorf("Expected each pass to cover the chunks handed before it, got %+v", transcribing)
+	}
+}
+
+func TestTracerNil(t *testing.T) {
+	var tracer *Tracer
+	tracer.Begin()
+	tracer.Captured(1)
+	tracer.Dequeued(1)
+	tracer.Pass(time.Second, time.Second)
+	tracer.Delivered(time.Now(), 1)
+	if err := tracer.WriteFile("/nonexistent/trace.json"); err != nil {
+		t.Errorf("Expected a nil tracer to write nothing, got %v", err)
+	}
+}
@@ -0,0 +1,148 @@
+package transcription
+
+import (
+	"bufio"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// modelVariants lists the quantizations published for each model size, as
+// offered for download
+var modelVariants = map[ModelSize][]string{
+	ModelTiny:   {"q8_0", "q5_1"},
+	ModelBase:   {"q8_0", "q5_1"},
+	ModelSmall:  {"q8_0", "q5_1"},
+	ModelMedium: {"q8_0", "q5_0"},
+	ModelLarge:  {"q5_0"},
+}
+
+// ModelVariants lists every downloadable model, smallest size first and
+// full precision before its quantized variants
+func ModelVariants() []ModelSize {
+	var variants []ModelSize
+	for _, size := range ModelSizes {
+		variants = append(variants, size)
+		for _, quantization := range modelVariants[size] {
+			variants = append(variants, Quantized(size, quantization))
+		}
+	}
+	return variants
+}
+
+// Approximate sizes from the whisper.cpp README: the full precision model
+// file, and the memory whisper needs beyond the weights while transcribing
+var (
+	modelFileMB = map[ModelSize]uint64{
+		ModelTiny: 75, ModelBase: 142, ModelSmall: 466, ModelMedium: 1500, ModelLarge: 2900,
+	}
+	modelOverheadMB = map[ModelSize]uint64{
+		ModelTiny: 200, ModelBase: 250, ModelSmall: 390, ModelMedium: 600, ModelLarge: 1000,
+	}
+)
+
+// assumedMemoryMB is used where available memory can't be read
+const assumedMemoryMB = 2048
+
+// modelMemoryMB estimates the memory needed to transcribe with a model whose
+// file is fileMB large
+func modelMemoryMB(modelSize ModelSize, fileMB uint64) uint64 {
+	return fileMB + modelOverheadMB[modelSize.Base()]
+}
+
+// estimatedFileMB estimates the file size of a model that isn't installed
+func estimatedFileMB(modelSize ModelSize) uint64 {
+	mb := modelFileMB[modelSize.Base()]
+	switch {
+	case strings.HasPrefix(modelSize.Quantization(), "q8"):
+		return mb * 55 / 100
+	case strings.HasPrefix(modelSize.Quantization(), "q5"):
+		return mb * 37 / 100
+	}
+	return mb
+}
+
+// modelRank orders models by expected accuracy: by size, then full precision
+// before q8 before q5
+func modelRank(modelSize ModelSize) int {
+	rank := slices.Index(ModelSizes, modelSize.Base()) * 10
+	switch {
+	case modelSize.Quantization() == "":
+		rank += 2
+	case strings.HasPrefix(modelSize.Quantization(), "q8"):
+		rank++
+	}
+	return rank
+}
+
+// AutoModel returns the most accurate installed model that fits in available
+// memory, or the smallest installed model if none fits. It returns "" when no
+// model is installed.
+func AutoModel() ModelSize {
+	return pickModel(FindModels(), availableMemory())
+}
+
+// pickModel chooses among installed models for availableMB of memory
+func pickModel(models []ModelFile, availableMB uint64) ModelSize {
+	var best, smallest ModelFile
+	for _, model := range models {
+		if !slices.Contains(ModelSizes, model.Size.Base()) {
+			continue
+		}
+		if smallest.Size == "" || model.Bytes < smallest.Bytes {
+			smallest = model
+		}
+		fits := modelMemoryMB(model.Size, uint64(model.Bytes)>>20) <= availableMB
+		if fits && (best.Size == "" || modelRank(model.Size) > modelRank(best.Size)) {
+			best = model
+		}
+	}
+	if best.Size == "" {
+		return smallest.Size
+	}
+	return best.Size
+}
+
+// pickDownload chooses the most accurate downloadable model for availableMB
+// of memory, or the smallest one if none fits
+func pickDownload(availableMB uint64) ModelSize {
+	variants := ModelVariants()
+	best := variants[0]
+	for _, variant := range variants {
+		if estimatedFileMB(variant) < estimatedFileMB(best) {
+			best = variant
+		}
+	}
+	fits := false
+	for _, variant := range variants {
+		if modelMemoryMB(variant, estimatedFileMB(variant)) > availableMB {
+			continue
+		}
+		if !fits || modelRank(variant) > modelRank(best) {
+			best, fits = variant, true
+		}
+	}
+	return best
+}
+
+// availableMemory returns the memory available to a new model in megabytes,
+// read from /proc/meminfo, or assumedMemoryMB where that isn't available
+func availableMemory() uint64 {
+	f, err := os.Open("/proc/meminfo")
+	if err != nil {
+		return assumedMemoryMB
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) >= 2 && fields[0] == "MemAvailable:" {
+			if kb, err := strconv.ParseUint(fields[1], 10, 64); err == nil {
+				return kb >> 10
+			}
+		}
+	}
+	return assumedMemoryMB
+}
@@ -0,0 +1,64 @@
+package transcription
+
+import "testing"
+
+// TestQuantizedFileNames tests that quantized models round trip through their
+// file names
+func TestQuantizedFileNames(t *testing.T) {
+	for _, size := range ModelVariants() {
+		name := ModelFileName(size)
+		if got := modelSizeFromName(name); got != size {
+			t.Errorf("Expected %s to parse as %s, got %s", name, size, got)
+		}
+	}
+	if name := ModelFileName(Quantized(ModelSmall, "q5_1")); name != "ggml-small.en-q5_1.bin" {
+		t.Errorf("Unexpected quantized file name %s", name)
+	}
+	if url := modelURL(Quantized(ModelLarge, "q5_0")); url != modelBaseURL+"ggml-large-v3-q5_0.bin" {
+		t.Errorf("Unexpected large model URL %s", url)
+	}
+}
+
+// TestPickModel tests that the most accurate installed model that fits is
+// chosen, falling back to the smallest
+func TestPickModel(t *testing.T) {
+	const mb = 1 << 20
+	models := []ModelFile{
+		{Size: ModelTiny, Bytes: 75 * mb},
+		{Size: ModelSmall, Bytes: 466 * mb},
+		{Size: Quantized(ModelSmall, "q5_1"), Bytes: 180 * mb},
+		{Size: ModelMedium, Bytes: 1500 * mb},
+		{Size: "custom", Bytes: 10 * mb},
+	}
+
+	tests := []struct {
+		availableMB uint64
+		want        ModelSize
+	}{
+		{16384, ModelMedium},
+		{900, ModelSmall},
+		{600, Quantized(ModelSmall, "q5_1")},
+		{100, ModelTiny},
+	}
+	for _, test := range tests {
+		if got := pickModel(models, test.availableMB); got != test.want {
+			t.Errorf("With %d MB expected %s, got %s", test.availableMB, test.want, got)
+		}
+	}
+	if got := pickModel(nil, 16384); got != "" {
+		t.Errorf("Expected no model when none is installed, got %s", got)
+	}
+}
+
+// TestPickDownload tests that low memory machines download smaller models
+func TestPickDownload(t *testing.T) {
+	if got := pickDownload(64 << 10); got != ModelLarge {
+		t.Errorf("Expected the large model with plenty of memory, got %s", got)
+	}
+	if got := pickDownload(1024); modelRank(got) >= modelRank(ModelMedium) {
+		t.Errorf("Expected a model smaller than medium in 1 GB, got %s", got)
+	}
+	if got := pickDownload(10); got != Quantized(ModelTiny, "q5_1") {
+		t.Errorf("Expected the smallest model when nothing fits, got %s", got)
+	}
+}
@@ -0,0 +1,152 @@
+package transcription
+
+import (
+	_ "embed"
+	"encoding/base64"
+	"encoding/binary"
+	"runtime"
+	"unicode/utf16"
+)
+
+// Transcription backends selectable in the config
+const (
+	// BackendWhisper runs whisper.cpp in process through its Go bindings
+	BackendWhisper = "whisper"
+	// BackendServer sends audio to a local whisper.cpp server; see ServerEngine
+	BackendServer = "whisper-server"
+	// BackendFasterWhisper runs faster-whisper as a sidecar; see SidecarEngine
+	BackendFasterWhisper = "faster-whisper"
+	// BackendVosk runs Vosk (Kaldi) as a sidecar. Its small models run in
+	// real time on Raspberry Pi-class devices where whisper is too heavy.
+	BackendVosk = "vosk"
+	// BackendWindowsSpeech uses the recognizer built into Windows, so it
+	// works without downloading a model
+	BackendWindowsSpeech = "windows-speech"
+)
+
+// DefaultFasterWhisperCommand runs the faster-whisper sidecar in scripts/
+const DefaultFasterWhisperCommand = "ramble-faster-whisper --model base.en --compute-type int8"
+
+// DefaultVoskCommand runs the Vosk sidecar in scripts/ with its default
+// small English model
+const DefaultVoskCommand = "ramble-vosk"
+
+// BackendInfo describes what a transcription backend can do, so the UI can
+// explain the choice and hide settings that don't apply
+type BackendInfo struct {
+	Name        string // Config value, e.g. BackendWhisper
+	Label       string // Shown in Preferences
+	Description string
+
+	// StreamingNative backends recognize audio as it arrives; the others
+	// transcribe overlapping windows of it again and again
+	StreamingNative bool
+	// Punctuation is set when the text comes punctuated and capitalized
+	Punctuation bool
+	// LocalModel is set when the backend loads the installed whisper model
+	// of the selected size; the others choose their own model
+	LocalModel bool
+	// Command is set for sidecar backends, run by a configured command
+	Command bool
+	// URL is set for backends reached at a configured address
+	URL bool
+	// Decoding is set for backends running whisper, which take Decoding
+	// settings
+	Decoding bool
+	// OS is set for backends only available on one operating system, as
+	// named by runtime.GOOS
+	OS string
+}
+
+// Backends lists the transcription backends, the default first
+var Backends = []BackendInfo{
+	{
+		Name:        BackendWhisper,
+		Label:       "Whisper (built in)",
+		Description: "whisper.cpp running inside Ramble with the selected model.",
+		Punctuation: true,
+		LocalModel:  true,
+		Decoding:    true,
+	},
+	{
+		Name:        BackendServer,
+		Label:       "whisper-server",
+		Description: "A whisper.cpp server running on this machine.",
+		Punctuation: true,
+		URL:         true,
+		Decoding:    true,
+	},
+	{
+		Name:        BackendFasterWhisper,
+		Label:       "faster-whisper",
+		Description: "faster-whisper in a Python sidecar; often much faster on CPUs.",
+		Punctuation: true,
+		Command:     true,
+		Decoding:    true,
+	},
+	{
+		Name:            BackendVosk,
+		Label:           "Vosk (low power)",
+		Description:     "Vosk in a Python sidecar; light enough for a Raspberry Pi, but less accurate.",
+		StreamingNative: true,
+		Command:         true,
+	},
+	{
+		Name:            BackendWindowsSpeech,
+		Label:           "Windows speech recognition",
+		Description:     "The recognizer built into Windows; nothing to download, but less accurate than whisper.",
+		StreamingNative: true,
+		OS:              "windows",
+	},
+}
+
+// AvailableBackends lists the backends usable on this operating system
+func AvailableBackends() []BackendInfo {
+	var available []BackendInfo
+	for _, backend := range Backends {
+		if backend.OS == "" || backend.OS == runtime.GOOS {
+			available = append(available, backend)
+		}
+	}
+	return available
+}
+
+// NativeBackend returns the backend built into the operating system, used
+// when no whisper model is installed. ok is false where there is none.
+func NativeBackend() (backend BackendInfo, ok bool) {
+	if runtime.GOOS == "windows" {
+		return LookupBackend(BackendWindowsSpeech)
+	}
+	return BackendInfo{}, false
+}
+
+// windowsSpeechScript is the sidecar for BackendWindowsSpeech
+//
+//go:embed sidecars/windows-speech.ps1
+var windowsSpeechScript string
+
+// WindowsSpeechCommand returns the command running the Windows speech
+// sidecar. The script is passed to PowerShell encoded, so nothing needs to be
+// installed next to the binary.
+func WindowsSpeechCommand() []string {
+	units := utf16.Encode([]rune(windowsSpeechScript))
+	encoded := make([]byte, len(units)*2)
+	for i, unit := range units {
+		binary.LittleEndian.PutUint16(encoded[i*2:], unit)
+	}
+	return []string{"powershell", "-NoProfile", "-NonInteractive", "-EncodedCommand", base64.StdEncoding.EncodeToString(encoded)}
+}
+
+// LookupBackend returns the backend with the given name. An empty name is
+// the default backend.
+func LookupBackend(name string) (BackendInfo, bool) {
+	if name == "" {
+		return Backends[0], true
+	}
+	for _, backend := range Backends {
+		if backend.Name == name {
+			return backend, true
+		}
+	}
+	return BackendInfo{}, false
+}
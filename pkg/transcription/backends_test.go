@@ -0,0 +1,54 @@
+package transcription
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"runtime"
+	"strings"
+	"testing"
+	"unicode/utf16"
+)
+
+// TestLookupBackend tests that backends are found by name, with the built-in
+// whisper as the default
+func TestLookupBackend(t *testing.T) {
+	if backend, ok := LookupBackend(""); !ok || backend.Name != BackendWhisper || !backend.LocalModel {
+		t.Errorf("Expected the built-in whisper backend by default, got %+v", backend)
+	}
+	if backend, ok := LookupBackend(BackendVosk); !ok || !backend.StreamingNative || backend.Punctuation || !backend.Command {
+		t.Errorf("Unexpected Vosk capabilities %+v", backend)
+	}
+	if _, ok := LookupBackend("sphinx"); ok {
+		t.Error("Expected an unknown backend not to be found")
+	}
+}
+
+// TestAvailableBackends tests that backends for other operating systems are
+// left out
+func TestAvailableBackends(t *testing.T) {
+	for _, backend := range AvailableBackends() {
+		if backend.OS != "" && backend.OS != runtime.GOOS {
+			t.Errorf("Expected %s to be left out on %s", backend.Name, runtime.GOOS)
+		}
+	}
+	if _, ok := NativeBackend(); ok != (runtime.GOOS == "windows") {
+		t.Errorf("Unexpected native backend on %s", runtime.GOOS)
+	}
+}
+
+// TestWindowsSpeechCommand tests that the script is passed to PowerShell
+// encoded as UTF-16
+func TestWindowsSpeechCommand(t *testing.T) {
+	command := WindowsSpeechCommand()
+	raw, err := base64.StdEncoding.DecodeString(command[len(command)-1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	units := make([]uint16, len(raw)/2)
+	for i := range units {
+		units[i] = binary.LittleEndian.Uint16(raw[i*2:])
+	}
+	if string(utf16.Decode(units)) != windowsSpeechScript || !strings.Contains(windowsSpeechScript, "System.Speech") {
+		t.Error("Expected the encoded command to be the Windows speech script")
+	}
+}
@@ -0,0 +1,68 @@
+package transcription
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// contextChars bounds the earlier text added to the prompt of a live pass.
+// Whisper reads at most the last 224 tokens of a prompt, about this many
+// characters of English.
+const contextChars = 600
+
+// textContext is the end of the text transcribed so far in a recording.
+// Passed on in the prompt of the next live window, it lets the window
+// continue the sentence rather than start a new one, which otherwise
+// capitalizes words mid-sentence and repeats phrases across windows.
+type textContext struct {
+	keep bool   // Carry text over at all
+	text string // Newest text, at most contextChars bytes
+}
+
+// setKeep turns carrying text over on or off, forgetting the text if off
+func (c *textContext) setKeep(keep bool) {
+	c.keep = keep
+	if !keep {
+		c.text = ""
+	}
+}
+
+// add appends text sent to the streaming callback, keeping only the end
+func (c *textContext) add(text string) {
+	text = strings.TrimSpace(text)
+	if !c.keep || text == "" {
+		return
+	}
+	c.text = strings.TrimSpace(c.text + " " + text)
+	if len(c.text) <= contextChars {
+		return
+	}
+
+	// Start at a whole word, or at least a whole character
+	start := len(c.text) - contextChars
+	for start < len(c.text) && !utf8.RuneStart(c.text[start]) {
+		start++
+	}
+	kept := c.text[start:]
+	if i := strings.IndexByte(kept, ' '); i >= 0 {
+		kept = kept[i+1:]
+	}
+	c.text = kept
+}
+
+// reset forgets the text, e.g. when a new recording starts
+func (c *textContext) reset() {
+	c.text = ""
+}
+
+// prompt returns the prompt for the next live pass: the vocabulary prompt
+// followed by the text carried over
+func (c *textContext) prompt(vocabulary string) string {
+	switch {
+	case !c.keep || c.text == "":
+		return vocabulary
+	case vocabulary == "":
+		return c.text
+	}
+	return vocabulary + " " + c.text
+}
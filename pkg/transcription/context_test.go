@@ -0,0 +1,42 @@
+package transcription
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestTextContext(t *testing.T) {
+	c := textContext{keep: true}
+	if got := c.prompt("Glossary: Ramble."); got != "Glossary: Ramble." {
+		t.Errorf("Expected only the vocabulary before any text, got %q", got)
+	}
+
+	c.add("so what I was saying")
+	c.add(" is that ")
+	if got := c.prompt("Glossary: Ramble."); got != "Glossary: Ramble. so what I was saying is that" {
+		t.Errorf("Unexpected prompt %q", got)
+	}
+	if got := c.prompt(""); got != "so what I was saying is that" {
+		t.Errorf("Unexpected prompt without vocabulary %q", got)
+	}
+
+	// Only the end is kept, starting at a word
+	c.add(strings.Repeat("wörd ", 200))
+	if len(c.text) > contextChars || !utf8.ValidString(c.text) || !strings.HasPrefix(c.text, "wörd") {
+		t.Errorf("Expected the end of the text from a whole word, got %d bytes %q...", len(c.text), c.text[:10])
+	}
+
+	c.reset()
+	if got := c.prompt("Glossary: Ramble."); got != "Glossary: Ramble." {
+		t.Errorf("Expected a new recording to start without context, got %q", got)
+	}
+
+	// Nothing is carried over when off
+	c.add("before")
+	c.setKeep(false)
+	c.add("after")
+	if got := c.prompt(""); got != "" {
+		t.Errorf("Expected no context when off, got %q", got)
+	}
+}
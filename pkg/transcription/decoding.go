@@ -0,0 +1,61 @@
+package transcription
+
+import "fmt"
+
+// Decoding is how whisper turns audio into text: how tokens are chosen, and
+// when a window's text is rejected as a hallucination or as silence.
+// Backends that don't run whisper ignore it.
+type Decoding struct {
+	Temperature float64 // Randomness of the first attempt; 0 takes the likeliest tokens
+	// Added to the temperature for each new attempt at a window whose text
+	// fails the thresholds, up to 1; 0 never tries again
+	TemperatureFallback float64
+	BeamSize            int     // Candidates searched at once; 1 decodes greedily, which is fastest
+	EntropyThreshold    float64 // Text this repetitive or more is taken as a loop and tried again
+	NoSpeechThreshold   float64 // Windows at least this likely to be silence give no text
+}
+
+// defaultDecoding is whisper's own defaults, which whisper.cpp, its server
+// and faster-whisper all start from. Beam search is left off, as live
+// transcription can't afford its cost on most machines.
+var defaultDecoding = Decoding{
+	Temperature:         0,
+	TemperatureFallback: 0.2,
+	BeamSize:            1,
+	EntropyThreshold:    2.4,
+	NoSpeechThreshold:   0.6,
+}
+
+// Decoder is implemented by transcribers and engines whose decoding can be
+// configured
+type Decoder interface {
+	// SetDecoding sets how later windows are decoded
+	SetDecoding(decoding Decoding)
+}
+
+// DefaultDecoding returns the decoding settings a backend uses unless
+// configured otherwise. ok is false for backends that don't run whisper.
+func DefaultDecoding(backend string) (decoding Decoding, ok bool) {
+	info, found := LookupBackend(backend)
+	if !found || !info.Decoding {
+		return Decoding{}, false
+	}
+	return defaultDecoding, true
+}
+
+// Validate reports settings whisper can't use
+func (d Decoding) Validate() error {
+	switch {
+	case d.Temperature < 0 || d.Temperature > 1:
+		return fmt.Errorf("temperature %v is not between 0 and 1", d.Temperature)
+	case d.TemperatureFallback < 0 || d.TemperatureFallback > 1:
+		return fmt.Errorf("temperature fallback %v is not between 0 and 1", d.TemperatureFallback)
+	case d.BeamSize < 1 || d.BeamSize > 16:
+		return fmt.Errorf("beam size %d is not between 1 and 16", d.BeamSize)
+	case d.EntropyThreshold < 0:
+		return fmt.Errorf("entropy threshold %v is negative", d.EntropyThreshold)
+	case d.NoSpeechThreshold < 0 || d.NoSpeechThreshold > 1:
+		return fmt.Errorf("no-speech threshold %v is not between 0 and 1", d.NoSpeechThreshold)
+	}
+	return nil
+}
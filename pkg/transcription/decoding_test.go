@@ -0,0 +1,35 @@
+package transcription
+
+import "testing"
+
+// TestDefaultDecoding tests that only backends running whisper have decoding
+// settings, and that their defaults are valid
+func TestDefaultDecoding(t *testing.T) {
+	for _, backend := range Backends {
+		decoding, ok := DefaultDecoding(backend.Name)
+		if ok != backend.Decoding {
+			t.Errorf("%s: expected decoding settings %v, got %v", backend.Name, backend.Decoding, ok)
+		}
+		if ok {
+			if err := decoding.Validate(); err != nil {
+				t.Errorf("%s: expected valid defaults, got %v", backend.Name, err)
+			}
+		}
+	}
+	if _, ok := DefaultDecoding(BackendVosk); ok {
+		t.Error("Expected Vosk to have no decoding settings")
+	}
+
+	invalid := []Decoding{
+		{Temperature: 1.5, BeamSize: 1},
+		{TemperatureFallback: -0.2, BeamSize: 1},
+		{BeamSize: 0},
+		{BeamSize: 1, EntropyThreshold: -1},
+		{BeamSize: 1, NoSpeechThreshold: 2},
+	}
+	for _, decoding := range invalid {
+		if decoding.Validate() == nil {
+			t.Errorf("Expected %+v to be invalid", decoding)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+// Package dedup drops text that streaming transcription has already emitted.
+// Live transcription runs whisper over overlapping windows of audio, so each
+// pass repeats much of what the previous one recognized.
+package dedup
+
+import "strings"
+
+// Policy sets how similar text must be to recently emitted text to be dropped
+type Policy struct {
+	Similarity  float64 // Drop text sharing more than this fraction of its words with a recent segment
+	Containment float64 // Drop text with more than this fraction of its words contained in a recent segment
+	History     int     // Number of recent segments compared against
+	MinLength   int     // Drop text shorter than this many characters
+}
+
+// DefaultPolicy returns the policy tuned for whisper's overlapping windows
+func DefaultPolicy() Policy {
+	return Policy{
+		Similarity:  0.6,
+		Containment: 0.7,
+		History:     10,
+		MinLength:   3,
+	}
+}
+
+// Filter remembers recently emitted segments and rejects repeats of them.
+// It is not safe for concurrent use.
+type Filter struct {
+	policy Policy
+	recent []string // Lowercased, oldest first
+}
+
+// New creates a filter that applies policy
+func New(policy Policy) *Filter {
+	return &Filter{policy: policy}
+}
+
+// Accept reports whether text is new and should be emitted. Accepted text is
+// remembered so later repeats of it are rejected.
+func (f *Filter) Accept(text string) bool {
+	text = strings.TrimSpace(text)
+	if text == "" || len(text) < f.policy.MinLength {
+		return false
+	}
+
+	lower := strings.ToLower(text)
+	for _, prev := range f.recent {
+		if lower == prev || Similarity(lower, prev) > f.policy.Similarity {
+			return false
+		}
+		// Skip if this segment is mostly contained in a previous segment
+		if ContainsOverlap(prev, lower, f.policy.Containment) {
+			return false
+		}
+	}
+
+	f.recent = append(f.recent, lower)
+	if len(f.recent) > f.policy.History {
+		f.recent = f.recent[len(f.recent)-f.policy.History:]
+	}
+	return true
+}
+
+// Reset forgets the emitted segments, e.g. when a new recording starts
+func (f *Filter) Reset() {
+	f.recent = f.recent[:0]
+}
+
+// Similarity returns the fraction of a's words that also appear in b, from
+// 0 to 1. Phrases of three words or fewer score 0.4, since a few shared
+// words say little about whether they are repeats.
+func Similarity(a, b string) float64 {
+	wordsA := strings.Fields(a)
+	wordsB := strings.Fields(b)
+	if len(wordsA) <= 3 || len(wordsB) <= 3 {
+		return 0.4
+	}
+	return float64(sharedWords(wordsB, wordsA)) / float64(len(wordsA))
+}
+
+// ContainsOverlap reports whether more than threshold of b's words appear in
+// a. Texts of very different lengths and b shorter than four words never
+// overlap, so short new phrases aren't lost.
+func ContainsOverlap(a, b string, threshold float64) bool {
+	if len(a) > 2*len(b) || len(b) > 2*len(a) {
+		return false
+	}
+
+	wordsA := strings.Fields(a)
+	wordsB := strings.Fields(b)
+	if len(wordsB) < 4 {
+		return false
+	}
+	return float64(sharedWords(wordsA, wordsB))/float64(len(wordsB)) > threshold
+}
+
+// sharedWords counts the words of words that appear in in, each occurrence
+// in in matching at most once
+func sharedWords(in, words []string) int {
+	counts := make(map[string]int, len(in))
+	for _, word := range in {
+		counts[word]++
+	}
+
+	matches := 0
+	for _, word := range words {
+		if counts[word] > 0 {
+			matches++
+			counts[word]--
+		}
+	}
+	return matches
+}
@@ -0,0 +1,98 @@
+package dedup
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files")
+
+// TestGolden feeds the segments of each testdata/*.txt file through a filter
+// pass by pass and compares what is emitted with the matching .golden file
+func TestGolden(t *testing.T) {
+	inputs, err := filepath.Glob(filepath.Join("testdata", "*.txt"))
+	if err != nil || len(inputs) == 0 {
+		t.Fatalf("No test inputs found: %v", err)
+	}
+
+	for _, input := range inputs {
+		name := strings.TrimSuffix(filepath.Base(input), ".txt")
+		t.Run(name, func(t *testing.T) {
+			data, err := os.ReadFile(input)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			filter := New(DefaultPolicy())
+			var emitted strings.Builder
+			for _, pass := range strings.Split(string(data), "---\n") {
+				for _, line := range strings.Split(pass, "\n") {
+					if line == "" || strings.HasPrefix(line, "#") {
+						continue
+					}
+					if filter.Accept(line) {
+						emitted.WriteString(line + "\n")
+					}
+				}
+			}
+
+			golden := strings.TrimSuffix(input, ".txt") + ".golden"
+			if *update {
+				if err := os.WriteFile(golden, []byte(emitted.String()), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if emitted.String() != string(want) {
+				t.Errorf("Emitted:\n%s\nExpected:\n%s", emitted.String(), want)
+			}
+		})
+	}
+}
+
+func TestFilterHistory(t *testing.T) {
+	filter := New(Policy{Similarity: 0.6, Containment: 0.7, History: 2, MinLength: 3})
+	for _, text := range []string{"first", "second", "third"} {
+		if !filter.Accept(text) {
+			t.Fatalf("Expected %q to be accepted", text)
+		}
+	}
+	if !filter.Accept("first") {
+		t.Error("Expected text older than the history to be accepted again")
+	}
+	if filter.Accept("third") {
+		t.Error("Expected recent text to be rejected")
+	}
+
+	filter.Reset()
+	if !filter.Accept("third") {
+		t.Error("Expected text to be accepted after Reset")
+	}
+}
+
+func TestSimilarity(t *testing.T) {
+	if got := Similarity("the quick brown fox jumps", "the quick brown fox sleeps"); got != 0.8 {
+		t.Errorf("Expected similarity 0.8, got %v", got)
+	}
+	if got := Similarity("yes it is", "yes it is"); got != 0.4 {
+		t.Errorf("Expected short phrases to score 0.4, got %v", got)
+	}
+}
+
+func TestContainsOverlap(t *testing.T) {
+	if !ContainsOverlap("we should start with travel today", "we should start with travel", 0.7) {
+		t.Error("Expected a window's tail to overlap the previous segment")
+	}
+	if ContainsOverlap("we should start", "we should start", 0.7) {
+		t.Error("Expected phrases under four words never to overlap")
+	}
+	if ContainsOverlap("a much much longer segment than the other one here", "short one here ok", 0.7) {
+		t.Error("Expected texts of very different lengths not to overlap")
+	}
+}
@@ -0,0 +1,82 @@
+package transcription
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+
+	logger "github.com/jeff-barlow-spady/ramble/pkg/logger"
+)
+
+// embeddedModel is a tiny model compiled into the binary, if any
+var (
+	embeddedModel     []byte
+	embeddedModelPath string // Where embeddedModel was extracted to
+	embeddedModelMu   sync.Mutex
+)
+
+// SetEmbeddedModel registers the tiny model compiled into the binary. It is
+// extracted the first time the tiny model is needed and none is installed,
+// so transcription always works out of the box.
+func SetEmbeddedModel(data []byte) {
+	embeddedModelMu.Lock()
+	defer embeddedModelMu.Unlock()
+	embeddedModel = data
+	embeddedModelPath = ""
+}
+
+// extractEmbeddedModel writes the embedded model to the user's cache
+// directory and returns its path, or "" if there is no usable embedded model
+func extractEmbeddedModel() string {
+	embeddedModelMu.Lock()
+	defer embeddedModelMu.Unlock()
+
+	if embeddedModelPath != "" || len(embeddedModel) == 0 {
+		return embeddedModelPath
+	}
+	if !isModelData(embeddedModel) {
+		logger.Warning(logger.CategoryTranscription, "The embedded model is not a whisper model; ignoring it")
+		embeddedModel = nil
+		return ""
+	}
+
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		logger.Warning(logger.CategoryTranscription, "No cache directory to extract the embedded model to: %v", err)
+		return ""
+	}
+	dir := filepath.Join(cacheDir, "ramble", "models")
+	path := filepath.Join(dir, ModelFileName(ModelTiny))
+
+	// Extracted on an earlier run
+	if info, err := os.Stat(path); err == nil && info.Size() == int64(len(embeddedModel)) {
+		embeddedModelPath = path
+		return path
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		logger.Warning(logger.CategoryTranscription, "Failed to create %s: %v", dir, err)
+		return ""
+	}
+	tmp, err := os.CreateTemp(dir, ModelFileName(ModelTiny)+".*.part")
+	if err != nil {
+		logger.Warning(logger.CategoryTranscription, "Failed to extract the embedded model: %v", err)
+		return ""
+	}
+	defer os.Remove(tmp.Name())
+	_, err = tmp.Write(embeddedModel)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		logger.Warning(logger.CategoryTranscription, "Failed to extract the embedded model: %v", err)
+		return ""
+	}
+
+	logger.Info(logger.CategoryTranscription, "Extracted the embedded tiny model to %s", path)
+	embeddedModelPath = path
+	return path
+}
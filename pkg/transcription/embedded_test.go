@@ -0,0 +1,43 @@
+package transcription
+
+import (
+	"os"
+	"testing"
+)
+
+// TestEmbeddedModelFallback tests that the embedded model is extracted when
+// no tiny model is installed, and reused once extracted
+func TestEmbeddedModelFallback(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	wd, _ := os.Getwd()
+	os.Chdir(t.TempDir())
+	defer os.Chdir(wd)
+	defer SetEmbeddedModel(nil)
+
+	if path := GetLocalModelPath(ModelTiny); path != "" {
+		t.Fatalf("Expected no tiny model without an embedded one, got %s", path)
+	}
+
+	model := []byte{0x6c, 0x6d, 0x67, 0x67, 1, 2, 3}
+	SetEmbeddedModel(model)
+	path := GetLocalModelPath(ModelTiny)
+	if path == "" {
+		t.Fatal("Expected the embedded model to be extracted")
+	}
+	if data, err := os.ReadFile(path); err != nil || string(data) != string(model) {
+		t.Errorf("Expected the extracted model to match the embedded one, got %v", err)
+	}
+	if got := GetLocalModelPath(ModelAuto); got != path {
+		t.Errorf("Expected auto to fall back to the embedded model, got %q", got)
+	}
+	if got := GetLocalModelPath(ModelBase); got != "" {
+		t.Errorf("Expected the embedded model only to stand in for tiny, got %q", got)
+	}
+
+	// A placeholder file is not used
+	SetEmbeddedModel([]byte("placeholder"))
+	if got := GetLocalModelPath(ModelTiny); got != "" {
+		t.Errorf("Expected a placeholder embedded model to be ignored, got %q", got)
+	}
+}
@@ -0,0 +1,432 @@
+package transcription
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	logger "github.com/jeff-barlow-spady/ramble/pkg/logger"
+	"github.com/jeff-barlow-spady/ramble/pkg/transcription/dedup"
+)
+
+// Engine recognizes speech in complete windows of 16kHz audio. Backends that
+// run outside the process implement it; NewEngineTranscriber adds the live
+// transcription WhisperTranscriber does for the built-in whisper.cpp.
+type Engine interface {
+	// Transcribe returns the segments spoken in samples, primed with prompt,
+	// in language or, for LanguageAuto, the language the engine detects.
+	// Segment.Words and Segment.Language are filled in where the engine
+	// reports them.
+	Transcribe(samples []float32, prompt, language string) ([]Segment, error)
+	// IsLoaded reports whether the engine is ready to transcribe
+	IsLoaded() bool
+	// Load readies the engine after Unload
+	Load() error
+	// Unload frees what the engine holds while idle
+	Unload() error
+	// Close releases all resources
+	Close() error
+}
+
+// Restarter is implemented by what restarts a backend that stopped
+// responding, so the user can be told why no text arrived for a while
+type Restarter interface {
+	// SetRestartCallback sets the function told why the backend was restarted
+	SetRestartCallback(callback func(reason error))
+}
+
+// EngineTranscriber implements Transcriber on top of an Engine
+type EngineTranscriber struct {
+	engine             Engine
+	buffer             liveBuffer
+	minSamples         int // Minimum samples needed before the first pass
+	maxWindowSamples   int // Most recent samples transcribed in each pass
+	contextSamples     int // Samples kept between passes as context
+	life               lifecycle
+	textCallback       func(string)
+	wordCallback       func([]Word) // Receives the timing of each word sent to textCallback
+	languageCallback   func(string) // Receives the detected language of text sent to textCallback
+	passCallback       func(window, elapsed time.Duration)
+	mu                 sync.Mutex
+	lastProcessTime    time.Time
+	dedup              *dedup.Filter // Drops text repeated by overlapping windows
+	suppressor         *Suppressor   // Drops text the engine invented on silence or noise
+	processingInterval time.Duration // Time between processing cycles
+	initialPrompt      string        // Primes the engine with vocabulary it should recognize
+	language           string        // Language code, or LanguageAuto to detect it in each window
+	carryover          textContext   // Text carried over to the prompt of the next pass
+}
+
+var (
+	_ Transcriber = (*EngineTranscriber)(nil)
+	_ Restarter   = (*EngineTranscriber)(nil)
+	_ Restarter   = (*SidecarEngine)(nil)
+	_ Decoder     = (*EngineTranscriber)(nil)
+	_ Decoder     = (*ServerEngine)(nil)
+	_ Decoder     = (*SidecarEngine)(nil)
+)
+
+// NewEngineTranscriber creates a transcriber that recognizes speech with engine
+func NewEngineTranscriber(engine Engine) *EngineTranscriber {
+	t := &EngineTranscriber{
+		engine:          engine,
+		lastProcessTime: time.Now(),
+		dedup:           dedup.New(dedup.DefaultPolicy()),
+		suppressor:      NewSuppressor(DefaultSilenceRMS, DefaultHallucinations),
+		language:        DefaultLanguage,
+	}
+	t.SetTuning(ProfileTuning(ProfileBalanced))
+	return t
+}
+
+// SetTuning changes how often and how much audio is transcribed while
+// recording. Tuning.Model and Tuning.FramesPerBuffer are left to the caller.
+func (t *EngineTranscriber) SetTuning(tuning Tuning) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.processingInterval = tuning.ProcessingInterval
+	t.minSamples = sampleCount(tuning.MinAudio)
+	t.maxWindowSamples = sampleCount(tuning.MaxWindow)
+	t.contextSamples = sampleCount(tuning.ContextRetention)
+}
+
+// AppendAudio adds audio to the live transcription without starting a pass
+func (t *EngineTranscriber) AppendAudio(audioData []float32) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.life.accepting() {
+		t.buffer.append(audioData)
+	}
+}
+
+// ProcessAudioChunk adds a chunk of audio data and starts a transcription
+// pass if one is due. audioData may be empty to only start a pass.
+func (t *EngineTranscriber) ProcessAudioChunk(audioData []float32) (string, error) {
+	t.mu.Lock()
+
+	if !t.life.accepting() {
+		t.mu.Unlock()
+		return "", nil
+	}
+	t.buffer.append(audioData)
+
+	// A backlog larger than one window is worked through without waiting
+	// for the interval
+	backlog := t.buffer.pending() > t.maxWindowSamples
+	due := t.buffer.pending() > 0 &&
+		(backlog || time.Since(t.lastProcessTime) >= t.processingInterval) &&
+		t.buffer.length() >= t.minSamples
+	if !due {
+		t.mu.Unlock()
+		return "", nil
+	}
+	t.startPass()
+	t.mu.Unlock()
+	return "", nil // Results are sent via callback
+}
+
+// startPass starts transcribing the next window unless a pass is running.
+// The caller must hold t.mu.
+func (t *EngineTranscriber) startPass() {
+	recording, ok := t.life.beginPass()
+	if !ok {
+		return
+	}
+
+	t.lastProcessTime = time.Now()
+	window := t.buffer.next(t.maxWindowSamples)
+	go t.pass(recording, window, t.carryover.prompt(t.initialPrompt), t.language)
+}
+
+// pass transcribes one live window of a recording and sends the new text to
+// the callbacks, unless the recording stopped meanwhile
+func (t *EngineTranscriber) pass(recording int, window []float32, prompt, language string) {
+	// Text from a near-silent window is the engine making things up
+	rms := windowRMS(window)
+
+	started := time.Now()
+	segments, err := t.engine.Transcribe(window, prompt, language)
+	elapsed := time.Since(started)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	wanted := t.life.wanted(recording)
+	t.life.endPass()
+
+	if err != nil {
+		logger.Warning(logger.CategoryTranscription, "Error processing audio: %v", err)
+		return
+	}
+	if t.passCallback != nil {
+		t.passCallback(time.Duration(len(window))*time.Second/16000, elapsed)
+	}
+	if !wanted {
+		logger.Debug(logger.CategoryTranscription, "Dropping the last pass of a stopped recording")
+		return
+	}
+
+	for _, segment := range segments {
+		text := strings.TrimSpace(segment.Text)
+		if text == "" || t.textCallback == nil {
+			continue
+		}
+		if t.suppressor.Suppress(text, rms) {
+			logger.Debug(logger.CategoryTranscription, "Suppressing likely hallucination: %s (level %.4f)", text, rms)
+			continue
+		}
+		if !t.dedup.Accept(text) {
+			logger.Debug(logger.CategoryTranscription, "Skipping duplicate segment: %s", text)
+			continue
+		}
+
+		logger.Debug(logger.CategoryTranscription, "Sending segment: %s", text)
+		if t.languageCallback != nil && segment.Language != "" {
+			t.languageCallback(segment.Language)
+		}
+		t.textCallback(text)
+		t.carryover.add(text)
+		if t.wordCallback != nil && len(segment.Words) > 0 {
+			t.wordCallback(segment.Words)
+		}
+	}
+
+	// Keep a sliding window of audio for context
+	t.buffer.trim(t.contextSamples)
+}
+
+// SetRestartCallback sets the function told when the engine restarted its
+// backend because it stopped responding. Engines that don't restart
+// anything never call it.
+func (t *EngineTranscriber) SetRestartCallback(callback func(reason error)) {
+	if restarter, ok := t.engine.(Restarter); ok {
+		restarter.SetRestartCallback(callback)
+	}
+}
+
+// SetDecoding implements Decoder for engines running whisper; others
+// ignore it
+func (t *EngineTranscriber) SetDecoding(decoding Decoding) {
+	if decoder, ok := t.engine.(Decoder); ok {
+		decoder.SetDecoding(decoding)
+	}
+}
+
+// IsBusy reports whether the previous window is still being transcribed
+func (t *EngineTranscriber) IsBusy() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.life.busy()
+}
+
+// BufferedSamples reports how much live audio is held between passes
+func (t *EngineTranscriber) BufferedSamples() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.buffer.length()
+}
+
+// TranscribeSamples transcribes a complete recording at 16kHz. It cannot run
+// while live transcription is active.
+func (t *EngineTranscriber) TranscribeSamples(samples []float32) ([]Segment, error) {
+	return t.TranscribeSamplesWithProgress(samples, nil)
+}
+
+// TranscribeSamplesWithProgress is TranscribeSamples calling progress with the
+// percentage done. Engines report no progress of their own, so it is only
+// called once the recording is transcribed.
+func (t *EngineTranscriber) TranscribeSamplesWithProgress(samples []float32, progress func(percent int)) ([]Segment, error) {
+	t.mu.Lock()
+	if err := t.life.beginBatch(); err != nil {
+		t.mu.Unlock()
+		return nil, err
+	}
+	prompt, language := t.initialPrompt, t.language
+	t.mu.Unlock()
+
+	defer func() {
+		t.mu.Lock()
+		t.life.endPass()
+		t.mu.Unlock()
+	}()
+
+	transcribed, err := t.engine.Transcribe(samples, prompt, language)
+	if err != nil {
+		return nil, fmt.Errorf("failed to transcribe audio: %w", err)
+	}
+
+	var segments []Segment
+	for _, segment := range transcribed {
+		segment.Text = NormalizeTranscriptionTextIn(strings.TrimSpace(segment.Text), textLanguage(segment.Language, language))
+		if segment.Text != "" {
+			segments = append(segments, segment)
+		}
+	}
+	if progress != nil {
+		progress(100)
+	}
+	return segments, nil
+}
+
+// TranscribeChannels transcribes each channel on its own and interleaves the
+// results by time, labelling each channel with its speaker
+func (t *EngineTranscriber) TranscribeChannels(channels [][]float32, speakers []string) ([]Segment, error) {
+	perChannel := make([][]Segment, len(channels))
+	for i, samples := range channels {
+		segments, err := t.TranscribeSamples(samples)
+		if err != nil {
+			return nil, fmt.Errorf("channel %d: %w", i+1, err)
+		}
+		perChannel[i] = segments
+	}
+	return InterleaveSpeakers(speakers, perChannel), nil
+}
+
+// SetStreamingCallback sets the function to call with transcription results
+func (t *EngineTranscriber) SetStreamingCallback(callback func(string)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.textCallback = callback
+}
+
+// SetWordCallback sets a function called with the words of each segment sent
+// to the streaming callback, when the engine reports word timing
+func (t *EngineTranscriber) SetWordCallback(callback func([]Word)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.wordCallback = callback
+}
+
+// SetPassCallback sets a function called after each live transcription pass
+// with the length of audio transcribed and how long the engine took
+func (t *EngineTranscriber) SetPassCallback(callback func(window, elapsed time.Duration)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.passCallback = callback
+}
+
+// EndUtterance drops the transcribed audio once the speaker has paused.
+// Audio not yet transcribed is kept.
+func (t *EngineTranscriber) EndUtterance() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.buffer.trim(0)
+}
+
+// SetSuppressor sets how hallucinated text is dropped before reaching the
+// streaming callback. nil turns suppression off.
+func (t *EngineTranscriber) SetSuppressor(suppressor *Suppressor) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.suppressor = suppressor
+}
+
+// SetVocabulary primes the engine with words and names it should recognize
+func (t *EngineTranscriber) SetVocabulary(words []string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.initialPrompt = VocabularyPrompt(words)
+}
+
+// SetKeepContext sets whether each live window is prompted with the end of
+// the text before it. Engines that take no prompt ignore it.
+func (t *EngineTranscriber) SetKeepContext(keep bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.carryover.setKeep(keep)
+}
+
+// SetLanguage sets the language transcribed, or LanguageAuto to detect it in
+// each window. Engines that don't support a language ignore it.
+func (t *EngineTranscriber) SetLanguage(language string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.language = language
+}
+
+// SetLanguageCallback sets a function called with the language detected for
+// each segment sent to the streaming callback, when the engine reports it
+func (t *EngineTranscriber) SetLanguageCallback(callback func(language string)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.languageCallback = callback
+}
+
+// SetRecordingState starts or stops live transcription. A pass still
+// running when a recording stops finishes, but its text is dropped, and the
+// next recording's passes wait for it.
+func (t *EngineTranscriber) SetRecordingState(isRecording bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if isRecording {
+		if began, _ := t.life.start(); !began {
+			logger.Debug(logger.CategoryTranscription, "Ignoring a start while %s", t.life.state)
+			return
+		}
+	} else {
+		t.life.stop()
+	}
+	t.buffer.reset()
+	t.dedup.Reset()
+	t.carryover.reset()
+}
+
+// Drain stops live transcription once the audio already added is
+// transcribed and its text sent, so the end of a recording isn't lost.
+// Audio added meanwhile is ignored. After timeout the recording stops
+// anyway, dropping the text of the pass still running.
+func (t *EngineTranscriber) Drain(timeout time.Duration) error {
+	defer t.SetRecordingState(false)
+
+	return drainUntil(timeout, func() (bool, error) {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+
+		switch {
+		case t.life.closed():
+			return false, errStreamClosed
+		case !t.life.drain():
+			return true, nil // Not recording
+		case t.life.busy():
+			return false, nil
+		case t.buffer.pending() == 0:
+			return true, nil
+		}
+		t.startPass()
+		return false, nil
+	})
+}
+
+// IsLoaded reports whether the engine is ready
+func (t *EngineTranscriber) IsLoaded() bool {
+	return t.engine.IsLoaded()
+}
+
+// Load readies the engine after Unload
+func (t *EngineTranscriber) Load() error {
+	return t.engine.Load()
+}
+
+// Unload frees what the engine holds while the transcriber is idle
+func (t *EngineTranscriber) Unload() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if !t.life.idle() {
+		return fmt.Errorf("cannot unload the model while transcribing")
+	}
+	t.buffer = liveBuffer{}
+	return t.engine.Unload()
+}
+
+// Close stops live transcription for good and releases resources
+func (t *EngineTranscriber) Close() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.life.close()
+	t.buffer = liveBuffer{}
+	return t.engine.Close()
+}
@@ -0,0 +1,173 @@
+package transcription
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeEngine returns the same segment for every window, in French if asked
+// to detect the language
+type fakeEngine struct {
+	mu      sync.Mutex
+	text    string
+	windows int
+	prompts []string
+}
+
+func (e *fakeEngine) Transcribe(samples []float32, prompt, language string) ([]Segment, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.windows++
+	e.prompts = append(e.prompts, prompt)
+	segment := Segment{Text: e.text, Words: []Word{{Text: e.text}}}
+	if language == LanguageAuto {
+		segment.Language = "fr"
+	}
+	return []Segment{segment}, nil
+}
+func (e *fakeEngine) IsLoaded() bool { return true }
+func (e *fakeEngine) Load() error    { return nil }
+func (e *fakeEngine) Unload() error  { return nil }
+func (e *fakeEngine) Close() error   { return nil }
+
+// loudAudio returns a second of audio loud enough not to be suppressed
+func loudAudio() []float32 {
+	samples := make([]float32, 16000)
+	for i := range samples {
+		samples[i] = 0.1
+	}
+	return samples
+}
+
+// TestEngineTranscriberLive tests that live passes send text and words to
+// the callbacks, dropping text repeated by overlapping windows
+func TestEngineTranscriberLive(t *testing.T) {
+	engine := &fakeEngine{text: "hello there"}
+	transcriber := NewEngineTranscriber(engine)
+	transcriber.SetVocabulary([]string{"Ramble"})
+
+	var mu sync.Mutex
+	var texts []string
+	var words int
+	transcriber.SetStreamingCallback(func(text string) {
+		mu.Lock()
+		texts = append(texts, text)
+		mu.Unlock()
+	})
+	transcriber.SetWordCallback(func(w []Word) {
+		mu.Lock()
+		words += len(w)
+		mu.Unlock()
+	})
+	transcriber.SetTuning(Tuning{MinAudio: time.Second, MaxWindow: 10 * time.Second, ContextRetention: 10 * time.Second})
+	transcriber.SetRecordingState(true)
+
+	for i := 0; i < 2; i++ {
+		transcriber.ProcessAudioChunk(loudAudio())
+		for transcriber.IsBusy() {
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if engine.windows != 2 {
+		t.Errorf("Expected 2 passes, got %d", engine.windows)
+	}
+	if len(texts) != 1 || texts[0] != "hello there" || words != 1 {
+		t.Errorf("Expected the text once with its words, got %q and %d words", texts, words)
+	}
+	if engine.prompts[0] == "" {
+		t.Error("Expected the vocabulary to be passed to the engine")
+	}
+}
+
+// TestEngineTranscriberKeepContext tests that a live pass is prompted with
+// the text of the passes before it in the same recording
+func TestEngineTranscriberKeepContext(t *testing.T) {
+	engine := &fakeEngine{text: "and then we"}
+	transcriber := NewEngineTranscriber(engine)
+	transcriber.SetVocabulary([]string{"Ramble"})
+	transcriber.SetKeepContext(true)
+	transcriber.SetStreamingCallback(func(string) {})
+	transcriber.SetTuning(Tuning{MinAudio: time.Second, MaxWindow: 10 * time.Second})
+
+	pass := func() {
+		transcriber.ProcessAudioChunk(loudAudio())
+		for transcriber.IsBusy() {
+			time.Sleep(time.Millisecond)
+		}
+	}
+	transcriber.SetRecordingState(true)
+	pass()
+	pass()
+	transcriber.SetRecordingState(false)
+	transcriber.SetRecordingState(true)
+	pass()
+
+	engine.mu.Lock()
+	defer engine.mu.Unlock()
+	vocabulary := VocabularyPrompt([]string{"Ramble"})
+	if engine.prompts[0] != vocabulary || engine.prompts[1] != vocabulary+" and then we" {
+		t.Errorf("Expected the second pass to continue the first, got %q", engine.prompts)
+	}
+	if engine.prompts[2] != vocabulary {
+		t.Errorf("Expected a new recording to start without context, got %q", engine.prompts[2])
+	}
+}
+
+// TestEngineTranscriberBusy tests that a recording can't be transcribed
+// during a live one
+func TestEngineTranscriberBusy(t *testing.T) {
+	transcriber := NewEngineTranscriber(&fakeEngine{text: "text"})
+	transcriber.SetRecordingState(true)
+	if _, err := transcriber.TranscribeSamples(loudAudio()); err == nil {
+		t.Error("Expected an error while recording")
+	}
+
+	transcriber.SetRecordingState(false)
+	segments, err := transcriber.TranscribeSamples(loudAudio())
+	if err != nil || len(segments) != 1 {
+		t.Errorf("Expected one segment, got %+v (%v)", segments, err)
+	}
+}
+
+// TestEngineTranscriberLanguage tests that the detected language is reported
+// for live text and returned with transcribed segments
+func TestEngineTranscriberLanguage(t *testing.T) {
+	transcriber := NewEngineTranscriber(&fakeEngine{text: "bonjour"})
+	transcriber.SetLanguage(LanguageAuto)
+
+	var mu sync.Mutex
+	var languages []string
+	transcriber.SetStreamingCallback(func(string) {})
+	transcriber.SetLanguageCallback(func(language string) {
+		mu.Lock()
+		languages = append(languages, language)
+		mu.Unlock()
+	})
+	transcriber.SetTuning(Tuning{MinAudio: time.Second, MaxWindow: 10 * time.Second})
+	transcriber.SetRecordingState(true)
+	transcriber.ProcessAudioChunk(loudAudio())
+	for transcriber.IsBusy() {
+		time.Sleep(time.Millisecond)
+	}
+
+	mu.Lock()
+	if len(languages) != 1 || languages[0] != "fr" {
+		t.Errorf("Expected French to be reported once, got %q", languages)
+	}
+	mu.Unlock()
+
+	transcriber.SetRecordingState(false)
+	segments, err := transcriber.TranscribeSamples(loudAudio())
+	if err != nil || len(segments) != 1 || segments[0].Language != "fr" {
+		t.Errorf("Expected a French segment, got %+v (%v)", segments, err)
+	}
+
+	transcriber.SetLanguage(DefaultLanguage)
+	if segments, _ := transcriber.TranscribeSamples(loudAudio()); len(segments) != 1 || segments[0].Language != "" {
+		t.Errorf("Expected no language unless detecting it, got %+v", segments)
+	}
+}
@@ -0,0 +1,82 @@
+package transcription
+
+import (
+	"math"
+	"strings"
+	"unicode"
+)
+
+// DefaultSilenceRMS is the window level below which segments are dropped as
+// hallucinations. Speech, even quiet, stays well above it.
+const DefaultSilenceRMS = 0.005
+
+// DefaultHallucinations are phrases whisper tends to invent on silence or
+// noise, mostly from the video subtitles it was trained on
+var DefaultHallucinations = []string{
+	"thank you for watching",
+	"thanks for watching",
+	"thank you so much for watching",
+	"thank you very much for watching",
+	"please subscribe",
+	"subscribe to my channel",
+	"like and subscribe",
+	"see you in the next video",
+	"subtitles by the amara org community",
+	"blank audio",
+	"silence",
+	"music",
+	"applause",
+}
+
+// Suppressor drops segments whisper invented rather than heard: those from
+// near-silent audio and those matching known hallucinated phrases
+type Suppressor struct {
+	minRMS  float64
+	phrases map[string]bool
+}
+
+// NewSuppressor creates a suppressor that drops segments from audio windows
+// quieter than minRMS and segments consisting only of one of phrases.
+// Phrases are matched ignoring case and punctuation.
+func NewSuppressor(minRMS float64, phrases []string) *Suppressor {
+	s := &Suppressor{minRMS: minRMS, phrases: make(map[string]bool, len(phrases))}
+	for _, phrase := range phrases {
+		if key := phraseKey(phrase); key != "" {
+			s.phrases[key] = true
+		}
+	}
+	return s
+}
+
+// Suppress reports whether text, transcribed from a window with the given
+// RMS level, should be dropped. A nil Suppressor drops nothing.
+func (s *Suppressor) Suppress(text string, rms float64) bool {
+	if s == nil {
+		return false
+	}
+	if rms < s.minRMS {
+		return true
+	}
+	return s.phrases[phraseKey(text)]
+}
+
+// phraseKey lowercases text and reduces punctuation and brackets, as in
+// "[BLANK_AUDIO]" or "Thank you for watching!", to single spaces
+func phraseKey(text string) string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '\''
+	})
+	return strings.Join(words, " ")
+}
+
+// windowRMS returns the RMS level of a window of audio
+func windowRMS(samples []float32) float64 {
+	if len(samples) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, sample := range samples {
+		sum += float64(sample) * float64(sample)
+	}
+	return math.Sqrt(sum / float64(len(samples)))
+}
@@ -0,0 +1,48 @@
+package transcription
+
+import "testing"
+
+func TestSuppressorPhrases(t *testing.T) {
+	s := NewSuppressor(DefaultSilenceRMS, DefaultHallucinations)
+
+	tests := []struct {
+		text     string
+		suppress bool
+	}{
+		{"Thank you for watching!", true},
+		{"[BLANK_AUDIO]", true},
+		{"(Music)", true},
+		{"  thanks for watching.  ", true},
+		{"Thank you for watching the demo yesterday.", false},
+		{"Please send the report.", false},
+	}
+	for _, tt := range tests {
+		if got := s.Suppress(tt.text, 0.1); got != tt.suppress {
+			t.Errorf("Suppress(%q) = %v, expected %v", tt.text, got, tt.suppress)
+		}
+	}
+}
+
+func TestSuppressorSilence(t *testing.T) {
+	s := NewSuppressor(0.01, nil)
+	if !s.Suppress("Hello there.", 0.002) {
+		t.Error("Expected text from a silent window to be suppressed")
+	}
+	if s.Suppress("Hello there.", 0.05) {
+		t.Error("Expected text from speech to be kept")
+	}
+
+	var off *Suppressor
+	if off.Suppress("Thank you for watching", 0) {
+		t.Error("Expected a nil suppressor to keep everything")
+	}
+}
+
+func TestWindowRMS(t *testing.T) {
+	if got := windowRMS([]float32{0.5, -0.5, 0.5, -0.5}); got != 0.5 {
+		t.Errorf("Expected RMS 0.5, got %v", got)
+	}
+	if got := windowRMS(nil); got != 0 {
+		t.Errorf("Expected RMS 0 for no audio, got %v", got)
+	}
+}
@@ -0,0 +1,79 @@
+package transcription
+
+import "strings"
+
+// Language settings besides the codes in Languages
+const (
+	// DefaultLanguage is transcribed unless another language is chosen
+	DefaultLanguage = "en"
+	// LanguageAuto detects the language of each window, so it may change
+	// within a recording
+	LanguageAuto = "auto"
+)
+
+// Language is a language whisper can transcribe
+type Language struct {
+	Code string // ISO 639-1 code whisper uses, e.g. "fr"
+	Name string // English name, e.g. "French"
+}
+
+// Languages lists the languages whisper can transcribe, most common first
+var Languages = []Language{
+	{"en", "English"}, {"zh", "Chinese"}, {"de", "German"}, {"es", "Spanish"},
+	{"ru", "Russian"}, {"ko", "Korean"}, {"fr", "French"}, {"ja", "Japanese"},
+	{"pt", "Portuguese"}, {"tr", "Turkish"}, {"pl", "Polish"}, {"ca", "Catalan"},
+	{"nl", "Dutch"}, {"ar", "Arabic"}, {"sv", "Swedish"}, {"it", "Italian"},
+	{"id", "Indonesian"}, {"hi", "Hindi"}, {"fi", "Finnish"}, {"vi", "Vietnamese"},
+	{"he", "Hebrew"}, {"uk", "Ukrainian"}, {"el", "Greek"}, {"ms", "Malay"},
+	{"cs", "Czech"}, {"ro", "Romanian"}, {"da", "Danish"}, {"hu", "Hungarian"},
+	{"ta", "Tamil"}, {"no", "Norwegian"}, {"th", "Thai"}, {"ur", "Urdu"},
+	{"hr", "Croatian"}, {"bg", "Bulgarian"}, {"lt", "Lithuanian"}, {"la", "Latin"},
+	{"mi", "Maori"}, {"ml", "Malayalam"}, {"cy", "Welsh"}, {"sk", "Slovak"},
+	{"te", "Telugu"}, {"fa", "Persian"}, {"lv", "Latvian"}, {"bn", "Bengali"},
+	{"sr", "Serbian"}, {"az", "Azerbaijani"}, {"sl", "Slovenian"}, {"kn", "Kannada"},
+	{"et", "Estonian"}, {"mk", "Macedonian"}, {"br", "Breton"}, {"eu", "Basque"},
+	{"is", "Icelandic"}, {"hy", "Armenian"}, {"ne", "Nepali"}, {"mn", "Mongolian"},
+	{"bs", "Bosnian"}, {"kk", "Kazakh"}, {"sq", "Albanian"}, {"sw", "Swahili"},
+	{"gl", "Galician"}, {"mr", "Marathi"}, {"pa", "Punjabi"}, {"si", "Sinhala"},
+	{"km", "Khmer"}, {"sn", "Shona"}, {"yo", "Yoruba"}, {"so", "Somali"},
+	{"af", "Afrikaans"}, {"oc", "Occitan"}, {"ka", "Georgian"}, {"be", "Belarusian"},
+	{"tg", "Tajik"}, {"sd", "Sindhi"}, {"gu", "Gujarati"}, {"am", "Amharic"},
+	{"yi", "Yiddish"}, {"lo", "Lao"}, {"uz", "Uzbek"}, {"fo", "Faroese"},
+	{"ht", "Haitian Creole"}, {"ps", "Pashto"}, {"tk", "Turkmen"}, {"nn", "Nynorsk"},
+	{"mt", "Maltese"}, {"sa", "Sanskrit"}, {"lb", "Luxembourgish"}, {"my", "Myanmar"},
+	{"bo", "Tibetan"}, {"tl", "Tagalog"}, {"mg", "Malagasy"}, {"as", "Assamese"},
+	{"tt", "Tatar"}, {"haw", "Hawaiian"}, {"ln", "Lingala"}, {"ha", "Hausa"},
+	{"ba", "Bashkir"}, {"jw", "Javanese"}, {"su", "Sundanese"}, {"yue", "Cantonese"},
+}
+
+// LanguageCode returns the code of a language given by its code or English
+// name, as backends report it, or "" if it isn't known
+func LanguageCode(language string) string {
+	language = strings.ToLower(strings.TrimSpace(language))
+	for _, l := range Languages {
+		if language == l.Code || language == strings.ToLower(l.Name) {
+			return l.Code
+		}
+	}
+	return ""
+}
+
+// textLanguage returns the language text is in: the detected language if
+// known, otherwise the chosen one, which may be LanguageAuto
+func textLanguage(detected, chosen string) string {
+	if detected != "" {
+		return detected
+	}
+	return chosen
+}
+
+// LanguageName returns the English name of a language code, or the code
+// itself if it isn't known
+func LanguageName(code string) string {
+	for _, l := range Languages {
+		if l.Code == code {
+			return l.Name
+		}
+	}
+	return code
+}
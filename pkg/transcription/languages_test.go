@@ -0,0 +1,22 @@
+package transcription
+
+import "testing"
+
+func TestLanguageCode(t *testing.T) {
+	tests := map[string]string{
+		"en":      "en",
+		"French":  "fr",
+		" german": "de",
+		"YUE":     "yue",
+		"klingon": "",
+		"":        "",
+	}
+	for input, want := range tests {
+		if got := LanguageCode(input); got != want {
+			t.Errorf("LanguageCode(%q) = %q, want %q", input, got, want)
+		}
+	}
+	if LanguageName("pt") != "Portuguese" || LanguageName("xx") != "xx" {
+		t.Error("Unexpected language names")
+	}
+}
@@ -0,0 +1,181 @@
+package transcription
+
+import (
+	"errors"
+	"time"
+)
+
+// streamState is where live transcription is in its lifecycle:
+//
+//	Idle → Streaming → Idle
+//	Idle → Streaming → Draining → Idle, when stopped with Drain
+//	Idle → Starting → Streaming, when started while a pass is running
+//	any → Stopped, once the transcriber is closed
+//
+// Only one pass runs at a time, live or over a whole recording, since
+// backends such as whisper.cpp can't transcribe twice at once. A recording
+// started while a pass is still running waits in Starting until it ends.
+// A pass still running once its recording stopped leaves the state Idle,
+// and its text is dropped.
+type streamState int
+
+const (
+	streamIdle      streamState = iota // Not recording
+	streamStarting                     // Recording, waiting for the last pass of the previous recording
+	streamStreaming                    // Recording; passes run as audio arrives
+	streamDraining                     // Stopped recording; the audio not yet transcribed still is
+	streamStopped                      // Closed; nothing runs again
+)
+
+var streamStateNames = [...]string{"idle", "starting", "streaming", "draining", "stopped"}
+
+// String names the state for logs
+func (s streamState) String() string {
+	return streamStateNames[s]
+}
+
+// errStreamBusy is returned when a whole recording can't be transcribed
+// because live transcription is running
+var errStreamBusy = errors.New("transcriber is busy with a live recording")
+
+// errStreamClosed is returned once the transcriber is closed
+var errStreamClosed = errors.New("transcriber is closed")
+
+// errDrainTimeout is returned when the end of a recording isn't transcribed
+// in the time given to Drain
+var errDrainTimeout = errors.New("timed out transcribing the end of the recording")
+
+// drainPoll is how often Drain checks whether the pass it waits for ended
+const drainPoll = 5 * time.Millisecond
+
+// lifecycle is the state machine of live transcription shared by the
+// transcribers. It isn't safe for concurrent use; callers hold their mutex.
+type lifecycle struct {
+	state streamState
+	// recording counts recordings, so a pass can tell whether the recording
+	// it belongs to is still running
+	recording int
+	passing   bool // A pass is running
+}
+
+// start begins a recording, reporting whether it began and whether passes
+// can run right away; otherwise they run once the running pass ends.
+// Starting a recording that is already running does nothing.
+func (l *lifecycle) start() (began, ready bool) {
+	switch l.state {
+	case streamStarting, streamStreaming, streamDraining, streamStopped:
+		return false, false
+	}
+	l.recording++
+	if l.passing {
+		l.state = streamStarting
+		return true, false
+	}
+	l.state = streamStreaming
+	return true, true
+}
+
+// stop ends the recording. The text of a pass still running is dropped.
+func (l *lifecycle) stop() {
+	switch l.state {
+	case streamStarting, streamStreaming, streamDraining:
+		l.state = streamIdle
+	}
+}
+
+// drain stops taking audio so what the recording already has can be
+// transcribed to its end. It reports whether there is a recording to drain;
+// one still waiting in Starting drains once it streams.
+func (l *lifecycle) drain() bool {
+	switch l.state {
+	case streamStreaming:
+		l.state = streamDraining
+		return true
+	case streamStarting, streamDraining:
+		return true
+	}
+	return false
+}
+
+// close stops everything for good
+func (l *lifecycle) close() {
+	l.state = streamStopped
+}
+
+// accepting reports whether audio belongs to a recording, including one
+// waiting for the previous pass to end
+func (l *lifecycle) accepting() bool {
+	return l.state == streamStarting || l.state == streamStreaming
+}
+
+// beginPass starts a live pass if none is running, returning the recording
+// it belongs to
+func (l *lifecycle) beginPass() (int, bool) {
+	if (l.state != streamStreaming && l.state != streamDraining) || l.passing {
+		return 0, false
+	}
+	l.passing = true
+	return l.recording, true
+}
+
+// beginBatch starts transcribing a whole recording, which can only happen
+// while not recording
+func (l *lifecycle) beginBatch() error {
+	switch {
+	case l.state == streamStopped:
+		return errStreamClosed
+	case l.state != streamIdle || l.passing:
+		return errStreamBusy
+	}
+	l.passing = true
+	return nil
+}
+
+// wanted reports whether the text of a pass of the given recording is
+// still wanted, i.e. that recording is still streaming or draining
+func (l *lifecycle) wanted(recording int) bool {
+	return (l.state == streamStreaming || l.state == streamDraining) && l.recording == recording
+}
+
+// endPass ends the running pass. It reports whether a recording that was
+// waiting for it can now run passes.
+func (l *lifecycle) endPass() bool {
+	l.passing = false
+	if l.state == streamStarting {
+		l.state = streamStreaming
+		return true
+	}
+	return false
+}
+
+// closed reports whether the transcriber was closed
+func (l *lifecycle) closed() bool {
+	return l.state == streamStopped
+}
+
+// busy reports whether a pass is running
+func (l *lifecycle) busy() bool {
+	return l.passing
+}
+
+// idle reports whether nothing is recorded or transcribed, so resources can
+// be released
+func (l *lifecycle) idle() bool {
+	return (l.state == streamIdle || l.state == streamStopped) && !l.passing
+}
+
+// drainUntil calls step until it reports the recording transcribed to its
+// end, checking every drainPoll for at most timeout
+func drainUntil(timeout time.Duration, step func() (bool, error)) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		done, err := step()
+		if done || err != nil {
+			return err
+		}
+		if time.Now().After(deadline) {
+			return errDrainTimeout
+		}
+		time.Sleep(drainPoll)
+	}
+}
@@ -0,0 +1,239 @@
+package transcription
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLifecycleTransitions(t *testing.T) {
+	var l lifecycle
+	if began, ready := l.start(); !began || !ready || l.state != streamStreaming {
+		t.Fatalf("Expected to stream at once, got %s", l.state)
+	}
+	if began, _ := l.start(); began {
+		t.Error("Expected a second start to be ignored")
+	}
+
+	first, ok := l.beginPass()
+	if !ok {
+		t.Fatal("Expected a pass to begin")
+	}
+	if _, ok := l.beginPass(); ok {
+		t.Error("Expected only one pass at a time")
+	}
+
+	// Stopping and starting again while the pass runs
+	l.stop()
+	if l.state != streamIdle || l.wanted(first) || l.idle() {
+		t.Errorf("Expected to stop without wanting the pass, got %s", l.state)
+	}
+	if began, ready := l.start(); !began || ready || l.state != streamStarting {
+		t.Errorf("Expected to wait for the pass, got %s", l.state)
+	}
+	if !l.accepting() {
+		t.Error("Expected audio to be accepted while starting")
+	}
+	if _, ok := l.beginPass(); ok {
+		t.Error("Expected no pass while the previous one runs")
+	}
+	if !l.endPass() || l.state != streamStreaming {
+		t.Errorf("Expected to stream once the pass ended, got %s", l.state)
+	}
+	if l.wanted(first) {
+		t.Error("Expected the text of the previous recording not to be wanted")
+	}
+	second, _ := l.beginPass()
+	if !l.wanted(second) {
+		t.Error("Expected the text of the current recording to be wanted")
+	}
+	l.endPass()
+
+	// Draining keeps the text of the recording's passes but takes no audio
+	if !l.drain() || l.state != streamDraining || l.accepting() {
+		t.Errorf("Expected to drain, got %s", l.state)
+	}
+	third, ok := l.beginPass()
+	if !ok || !l.wanted(third) {
+		t.Error("Expected the passes of a draining recording to be wanted")
+	}
+	if began, _ := l.start(); began {
+		t.Error("Expected no start while draining")
+	}
+	l.endPass()
+
+	if err := l.beginBatch(); err != errStreamBusy {
+		t.Errorf("Expected a batch to be refused while recording, got %v", err)
+	}
+	l.stop()
+	if l.state != streamIdle || !l.idle() {
+		t.Errorf("Expected to be idle, got %s", l.state)
+	}
+	if l.drain() {
+		t.Error("Expected nothing to drain once stopped")
+	}
+	if err := l.beginBatch(); err != nil {
+		t.Fatalf("Expected a batch to begin, got %v", err)
+	}
+	if l.idle() {
+		t.Error("Expected not to be idle during a batch")
+	}
+	l.endPass()
+
+	l.close()
+	if began, _ := l.start(); began || l.state != streamStopped {
+		t.Errorf("Expected nothing to start once closed, got %s", l.state)
+	}
+	if err := l.beginBatch(); err != errStreamClosed {
+		t.Errorf("Expected a batch to be refused once closed, got %v", err)
+	}
+}
+
+// slowEngine takes delay to transcribe, recording how many windows it
+// transcribed at once
+type slowEngine struct {
+	delay      time.Duration
+	mu         sync.Mutex
+	running    int
+	mostAtOnce int
+}
+
+func (e *slowEngine) Transcribe(samples []float32, prompt, language string) ([]Segment, error) {
+	e.mu.Lock()
+	e.running++
+	e.mostAtOnce = max(e.mostAtOnce, e.running)
+	e.mu.Unlock()
+
+	time.Sleep(e.delay)
+
+	e.mu.Lock()
+	e.running--
+	e.mu.Unlock()
+	return []Segment{{Text: prompt}}, nil
+}
+func (e *slowEngine) IsLoaded() bool { return true }
+func (e *slowEngine) Load() error    { return nil }
+func (e *slowEngine) Unload() error  { return nil }
+func (e *slowEngine) Close() error   { return nil }
+
+// TestEngineTranscriberRapidStartStop tests that starting and stopping
+// quickly, from several goroutines, never runs two passes at once or sends
+// the text of a stopped recording. Run it with -race.
+func TestEngineTranscriberRapidStartStop(t *testing.T) {
+	engine := &slowEngine{delay: 2 * time.Millisecond}
+	transcriber := NewEngineTranscriber(engine)
+	transcriber.SetSuppressor(nil)
+	transcriber.SetTuning(Tuning{MinAudio: time.Millisecond, MaxWindow: time.Second})
+
+	// Each recording primes the engine with its own number, which the
+	// engine returns as the text
+	var mu sync.Mutex
+	current := ""
+	var stale []string
+	transcriber.SetStreamingCallback(func(text string) {
+		mu.Lock()
+		defer mu.Unlock()
+		if text != current {
+			stale = append(stale, text)
+		}
+	})
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 200; i++ {
+			transcriber.ProcessAudioChunk(loudAudio()[:160])
+			time.Sleep(100 * time.Microsecond)
+		}
+	}()
+	for i := 0; i < 50; i++ {
+		// Nothing is sent between stopping and starting
+		vocabulary := []string{string(rune('a' + i%26))}
+		transcriber.SetRecordingState(false)
+		transcriber.SetVocabulary(vocabulary)
+		mu.Lock()
+		current = VocabularyPrompt(vocabulary)
+		mu.Unlock()
+		transcriber.SetRecordingState(true)
+		transcriber.SetRecordingState(true)
+		time.Sleep(time.Millisecond)
+	}
+	wg.Wait()
+	transcriber.SetRecordingState(false)
+	for transcriber.IsBusy() {
+		time.Sleep(time.Millisecond)
+	}
+
+	if engine.mostAtOnce != 1 {
+		t.Errorf("Expected one pass at a time, got %d at once", engine.mostAtOnce)
+	}
+	if len(stale) > 0 {
+		t.Errorf("Expected no text from stopped recordings, got %q", stale)
+	}
+	if err := transcriber.Unload(); err != nil {
+		t.Errorf("Expected to unload once drained, got %v", err)
+	}
+}
+
+// TestEngineTranscriberDrain tests that the audio not yet transcribed when a
+// recording stops is transcribed and sent before Drain returns
+func TestEngineTranscriberDrain(t *testing.T) {
+	engine := &slowEngine{delay: 2 * time.Millisecond}
+	transcriber := NewEngineTranscriber(engine)
+	transcriber.SetSuppressor(nil)
+	transcriber.SetTuning(Tuning{MinAudio: time.Second, MaxWindow: time.Second, ProcessingInterval: time.Hour})
+	transcriber.SetVocabulary([]string{"last"})
+
+	var mu sync.Mutex
+	var sent []string
+	transcriber.SetStreamingCallback(func(text string) {
+		mu.Lock()
+		defer mu.Unlock()
+		sent = append(sent, text)
+	})
+
+	transcriber.SetRecordingState(true)
+	// Too little audio for a pass while recording, and a second and a half
+	// that takes two passes once stopped
+	transcriber.ProcessAudioChunk(loudAudio()[:8000])
+	transcriber.AppendAudio(loudAudio()[:16000])
+	if err := transcriber.Drain(time.Second); err != nil {
+		t.Fatalf("Expected to drain, got %v", err)
+	}
+
+	mu.Lock()
+	if len(sent) != 1 || sent[0] != VocabularyPrompt([]string{"last"}) {
+		t.Errorf("Expected the end of the recording to be sent once, got %q", sent)
+	}
+	mu.Unlock()
+	if transcriber.IsBusy() || transcriber.life.state != streamIdle {
+		t.Errorf("Expected to be stopped once drained, got %s", transcriber.life.state)
+	}
+	transcriber.AppendAudio(loudAudio())
+	if transcriber.buffer.length() != 0 {
+		t.Error("Expected no audio to be taken once drained")
+	}
+}
+
+// TestEngineTranscriberDrainTimeout tests that Drain gives up after its
+// timeout, dropping the text of the pass still running
+func TestEngineTranscriberDrainTimeout(t *testing.T) {
+	transcriber := NewEngineTranscriber(&slowEngine{delay: 50 * time.Millisecond})
+	transcriber.SetSuppressor(nil)
+	transcriber.SetTuning(Tuning{MinAudio: time.Millisecond, MaxWindow: time.Second, ProcessingInterval: time.Hour})
+
+	sent := 0
+	transcriber.SetStreamingCallback(func(string) { sent++ })
+	transcriber.SetRecordingState(true)
+	transcriber.AppendAudio(loudAudio())
+	if err := transcriber.Drain(0); err != errDrainTimeout {
+		t.Errorf("Expected the drain to time out, got %v", err)
+	}
+	for transcriber.IsBusy() {
+		time.Sleep(time.Millisecond)
+	}
+	if sent != 0 {
+		t.Errorf("Expected no text after the drain timed out, got %d", sent)
+	}
+}
@@ -13,25 +13,39 @@ import (
 
 	"github.com/ggerganov/whisper.cpp/bindings/go/pkg/whisper"
 	logger "github.com/jeff-barlow-spady/ramble/pkg/logger"
+	"github.com/jeff-barlow-spady/ramble/pkg/transcription/dedup"
 )
 
 // WhisperTranscriber implements direct access to whisper.cpp Go bindings with proper buffer management
 type WhisperTranscriber struct {
+	modelPath          string // Kept so the model can be reloaded after Unload
 	model              whisper.Model
 	context            whisper.Context
-	buffer             []float32
+	buffer             liveBuffer
 	minSamples         int // Minimum samples needed (16000 = 1 second at 16kHz)
-	recordingActive    bool
+	maxWindowSamples   int // Most recent samples transcribed in each pass
+	contextSamples     int // Samples kept between passes as context
+	life               lifecycle
 	textCallback       func(string)
+	wordCallback       func([]Word) // Receives the timing of each word sent to textCallback
+	languageCallback   func(string) // Receives the detected language of text sent to textCallback
+	passCallback       func(window, elapsed time.Duration)
 	mu                 sync.Mutex
 	lastProcessTime    time.Time
-	processingActive   bool
-	lastText           string        // Store the last text segment to avoid duplicates
-	recentSegments     []string      // Store several recent segments for better deduplication
-	maxSegments        int           // Maximum number of segments to remember
+	dedup              *dedup.Filter // Drops text repeated by overlapping windows
+	suppressor         *Suppressor   // Drops text whisper invented on silence or noise
 	processingInterval time.Duration // Time between processing cycles
+	initialPrompt      string        // Primes the model with vocabulary it should recognize
+	language           string        // Language code, or LanguageAuto to detect it in each window
+	decoding           Decoding
+	carryover          textContext // Text carried over to the prompt of the next pass
 }
 
+var (
+	_ Transcriber = (*WhisperTranscriber)(nil)
+	_ Decoder     = (*WhisperTranscriber)(nil)
+)
+
 // NewManager creates a new whisper transcriber
 func NewManager(modelPath string) (*WhisperTranscriber, error) {
 	// Load the whisper model
@@ -47,64 +61,99 @@ func NewManager(modelPath string) (*WhisperTranscriber, error) {
 		return nil, fmt.Errorf("failed to create whisper context: %w", err)
 	}
 
-	return &WhisperTranscriber{
-		model:              model,
-		context:            context,
-		buffer:             make([]float32, 0, 16000*5), // Pre-allocate 5 seconds
-		minSamples:         16000,                       // 1 second minimum (16kHz)
-		textCallback:       nil,
-		lastProcessTime:    time.Now(),
-		recentSegments:     make([]string, 0, 10),
-		maxSegments:        10,                      // Remember last 10 segments for deduplication
-		processingInterval: 1200 * time.Millisecond, // Process every 1.2 seconds instead of 500ms
-	}, nil
+	t := &WhisperTranscriber{
+		modelPath:       modelPath,
+		model:           model,
+		context:         context,
+		buffer:          liveBuffer{samples: make([]float32, 0, 16000*5)}, // Pre-allocate 5 seconds
+		textCallback:    nil,
+		lastProcessTime: time.Now(),
+		dedup:           dedup.New(dedup.DefaultPolicy()),
+		suppressor:      NewSuppressor(DefaultSilenceRMS, DefaultHallucinations),
+		language:        DefaultLanguage,
+		decoding:        defaultDecoding,
+	}
+	t.SetTuning(ProfileTuning(ProfileBalanced))
+	return t, nil
 }
 
-// ProcessAudioChunk processes a chunk of audio data
-func (t *WhisperTranscriber) ProcessAudioChunk(audioData []float32) (string, error) {
-	if len(audioData) == 0 {
-		return "", nil
+// SetTuning changes how often and how much audio is transcribed while
+// recording. Tuning.Model and Tuning.FramesPerBuffer are left to the caller.
+func (t *WhisperTranscriber) SetTuning(tuning Tuning) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.processingInterval = tuning.ProcessingInterval
+	t.minSamples = sampleCount(tuning.MinAudio)
+	t.maxWindowSamples = sampleCount(tuning.MaxWindow)
+	t.contextSamples = sampleCount(tuning.ContextRetention)
+}
+
+// AppendAudio adds audio to the live transcription without starting a pass,
+// so a queued backlog can be buffered whole before it is transcribed
+func (t *WhisperTranscriber) AppendAudio(audioData []float32) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.life.accepting() && t.context != nil {
+		t.buffer.append(audioData)
 	}
+}
 
+// ProcessAudioChunk adds a chunk of audio data and starts a transcription
+// pass if one is due. audioData may be empty to only start a pass.
+func (t *WhisperTranscriber) ProcessAudioChunk(audioData []float32) (string, error) {
 	t.mu.Lock()
 
-	// Exit early if not recording
-	if !t.recordingActive {
+	// Exit early if not recording or the model is unloaded
+	if !t.life.accepting() || t.context == nil {
 		t.mu.Unlock()
 		return "", nil
 	}
 
 	// Add new audio to buffer
-	t.buffer = append(t.buffer, audioData...)
-
-	// Check if we should process now, if not exit early
-	shouldProcess := !t.processingActive &&
-		time.Since(t.lastProcessTime) >= t.processingInterval &&
-		len(t.buffer) >= t.minSamples
-
-	if !shouldProcess {
+	t.buffer.append(audioData)
+
+	// Check if we should process now, if not exit early. A backlog larger
+	// than one window is worked through without waiting for the interval.
+	backlog := t.buffer.pending() > t.maxWindowSamples
+	due := t.buffer.pending() > 0 &&
+		(backlog || time.Since(t.lastProcessTime) >= t.processingInterval) &&
+		t.buffer.length() >= t.minSamples
+	if !due {
 		t.mu.Unlock()
 		return "", nil
 	}
 
-	// We will be processing, so mark as active and update timestamp
-	t.processingActive = true
-	t.lastProcessTime = time.Now()
+	t.startPass()
+	t.mu.Unlock()
+	return "", nil // Results are sent via callback
+}
 
-	// Make a copy of just the part of the buffer we need to process
-	// This is more memory efficient than copying the entire buffer
-	// Limit to ~10 seconds maximum to reduce CPU load on long recordings
-	maxProcessingSamples := 16000 * 10 // 10 seconds
-	processLen := len(t.buffer)
-	if processLen > maxProcessingSamples {
-		processLen = maxProcessingSamples
+// startPass starts transcribing the next window in the background. Only one
+// pass runs at a time, and none while the previous recording's last pass
+// finishes. The caller must hold t.mu.
+func (t *WhisperTranscriber) startPass() {
+	recording, ok := t.life.beginPass()
+	if !ok {
+		return
 	}
+	t.lastProcessTime = time.Now()
+
+	// Copy just the window to process, limited to reduce CPU load on long
+	// recordings. Audio past the window stays pending for the next pass.
+	bufferToProcess := t.buffer.next(t.maxWindowSamples)
 
-	bufferToProcess := make([]float32, processLen)
-	// Copy from the most recent part of the buffer
-	copy(bufferToProcess, t.buffer[len(t.buffer)-processLen:])
+	// Continue the text of the previous windows
+	t.context.SetInitialPrompt(t.carryover.prompt(t.initialPrompt))
 
-	t.mu.Unlock() // Release lock before starting async processing
+	suppressor := t.suppressor
+	passCallback := t.passCallback
+	detect := t.detectsLanguage()
+	context := t.context // Kept until the pass ends, even if closed meanwhile
+
+	// Text from a near-silent window is whisper making things up
+	rms := windowRMS(bufferToProcess)
 
 	// Process the audio buffer in a goroutine to avoid blocking
 	go func() {
@@ -114,79 +163,57 @@ func (t *WhisperTranscriber) ProcessAudioChunk(audioData []float32) (string, err
 				return
 			}
 
-			// Process text outside the lock when possible
 			text := strings.TrimSpace(segment.Text)
 
-			// Skip empty text or very short segments
-			if text == "" || len(text) < 3 {
-				return
-			}
-
 			// Now lock to check against recent segments and update state
 			t.mu.Lock()
 			defer t.mu.Unlock()
 
-			// Skip if no callback or not recording anymore
-			if t.textCallback == nil || !t.recordingActive {
+			// Skip if no callback or the recording stopped
+			if t.textCallback == nil || !t.life.wanted(recording) {
 				return
 			}
 
-			// More sophisticated deduplication logic
-			shouldSend := true
-
-			// Convert to lowercase for better matching
-			textLower := strings.ToLower(text)
-
-			// Check against recent segments for duplicates or significant overlaps
-			for _, prevSegment := range t.recentSegments {
-				prevLower := strings.ToLower(prevSegment)
-
-				// More aggressive similarity threshold (0.6 vs 0.7)
-				if textLower == prevLower || similarityScore(textLower, prevLower) > 0.6 {
-					shouldSend = false
-					break
-				}
-
-				// Skip if this segment is mostly contained in a previous segment
-				// More aggressive containment check (70% vs 75%)
-				if containsSubstantialOverlap(prevLower, textLower, 0.7) {
-					shouldSend = false
-					break
-				}
+			if suppressor.Suppress(text, rms) {
+				logger.Debug(logger.CategoryTranscription, "Suppressing likely hallucination: %s (level %.4f)", text, rms)
+				return
 			}
 
-			if shouldSend {
-				// Add to recent segments before sending
-				t.recentSegments = append(t.recentSegments, text)
-
-				// Trim if exceeded max size
-				if len(t.recentSegments) > t.maxSegments {
-					t.recentSegments = t.recentSegments[1:]
-				}
-
+			if t.dedup.Accept(text) {
 				// Log the segment for debugging
 				logger.Debug(logger.CategoryTranscription, "Sending segment: %s", text)
 
-				// Send text to UI
+				// Send text to UI, after the language it is in
+				if detect && t.languageCallback != nil {
+					t.languageCallback(LanguageCode(context.DetectedLanguage()))
+				}
 				t.textCallback(text)
+				t.carryover.add(text)
+				if t.wordCallback != nil {
+					t.wordCallback(segmentWords(segment))
+				}
 			} else {
 				logger.Debug(logger.CategoryTranscription, "Skipping duplicate segment: %s", text)
 			}
 		}
 
 		// Process the audio buffer
-		err := t.context.Process(
+		started := time.Now()
+		err := context.Process(
 			bufferToProcess,
 			nil,             // No encoder begin callback needed
 			segmentCallback, // Handle text segments
 			nil,             // No progress callback needed
 		)
+		if err == nil && passCallback != nil {
+			window := time.Duration(len(bufferToProcess)) * time.Second / 16000
+			passCallback(window, time.Since(started))
+		}
 
 		t.mu.Lock()
 		defer t.mu.Unlock()
-
-		// Mark that we're done processing
-		t.processingActive = false
+		wanted := t.life.wanted(recording)
+		t.endPass()
 
 		if err != nil {
 			logger.Warning(logger.CategoryTranscription,
@@ -194,144 +221,284 @@ func (t *WhisperTranscriber) ProcessAudioChunk(audioData []float32) (string, err
 			return
 		}
 
-		// Keep a sliding window of audio for context
-		// 15 seconds maximum instead of 30 to reduce memory usage
-		const maxBufferSeconds = 15
-		maxBufferLen := 16000 * maxBufferSeconds
-		if len(t.buffer) > maxBufferLen {
-			t.buffer = t.buffer[len(t.buffer)-maxBufferLen:]
+		// Keep a sliding window of audio for context, unless the buffer
+		// already belongs to the next recording
+		if wanted {
+			t.buffer.trim(t.contextSamples)
 		}
 	}()
+}
 
-	return "", nil // Results are sent via callback
+// IsBusy reports whether whisper is still processing the previous window.
+// Callers can hold back new audio while busy instead of growing the buffer.
+func (t *WhisperTranscriber) IsBusy() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.life.busy()
+}
+
+// BufferedSamples reports how much live audio is held between passes
+func (t *WhisperTranscriber) BufferedSamples() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.buffer.length()
 }
 
-// similarityScore calculates how similar two strings are (0-1 scale)
-// Uses a simple word overlap approach for efficiency
-func similarityScore(a, b string) float64 {
-	// Split into words
-	wordsA := strings.Fields(a)
-	wordsB := strings.Fields(b)
+// TranscribeSamples transcribes a complete recording at 16kHz and returns its
+// segments with timestamps. It cannot run while streaming transcription is active.
+func (t *WhisperTranscriber) TranscribeSamples(samples []float32) ([]Segment, error) {
+	return t.TranscribeSamplesWithProgress(samples, nil)
+}
 
-	// If either text has very few words, require higher match threshold
-	if len(wordsA) <= 3 || len(wordsB) <= 3 {
-		return 0.4 // Return a lower similarity for very short phrases
+// TranscribeSamplesWithProgress is TranscribeSamples for long recordings,
+// calling progress (if not nil) with the percentage done as the model runs
+func (t *WhisperTranscriber) TranscribeSamplesWithProgress(samples []float32, progress func(percent int)) ([]Segment, error) {
+	t.mu.Lock()
+	if t.context == nil && !t.life.closed() {
+		t.mu.Unlock()
+		return nil, fmt.Errorf("whisper model is not loaded")
 	}
+	if err := t.life.beginBatch(); err != nil {
+		t.mu.Unlock()
+		return nil, err
+	}
+	t.configureContext()
+	detect := t.detectsLanguage()
+	language := t.language
+	context := t.context
+	t.mu.Unlock()
 
-	// Count matching words
-	matches := 0
+	defer func() {
+		t.mu.Lock()
+		t.endPass()
+		t.mu.Unlock()
+	}()
 
-	// Create map of words in B for faster lookup
-	wordMapB := make(map[string]int)
-	for _, word := range wordsB {
-		wordMapB[word]++
+	var segments []Segment
+	err := context.Process(samples, nil, func(segment whisper.Segment) {
+		var detected string
+		if detect {
+			detected = LanguageCode(context.DetectedLanguage())
+		}
+		text := NormalizeTranscriptionTextIn(strings.TrimSpace(segment.Text), textLanguage(detected, language))
+		if text == "" {
+			return
+		}
+		segments = append(segments, Segment{Start: segment.Start, End: segment.End, Text: text, Language: detected})
+	}, progress)
+	if err != nil {
+		return nil, fmt.Errorf("failed to transcribe audio: %w", err)
 	}
 
-	// Count words from A that appear in B
-	for _, word := range wordsA {
-		if count, exists := wordMapB[word]; exists && count > 0 {
-			matches++
-			wordMapB[word]--
+	return segments, nil
+}
+
+// TranscribeChannels transcribes each channel of a recording on its own and
+// interleaves the results by time, labelling each channel with its speaker.
+// This is far more accurate than diarizing a mixed track when every speaker
+// has their own channel, as in two-person interview recordings.
+func (t *WhisperTranscriber) TranscribeChannels(channels [][]float32, speakers []string) ([]Segment, error) {
+	perChannel := make([][]Segment, len(channels))
+	for i, samples := range channels {
+		logger.Info(logger.CategoryTranscription, "Transcribing channel %d of %d", i+1, len(channels))
+
+		segments, err := t.TranscribeSamples(samples)
+		if err != nil {
+			return nil, fmt.Errorf("channel %d: %w", i+1, err)
 		}
+		perChannel[i] = segments
 	}
 
-	// Calculate similarity based on relative match percentage
-	totalWords := len(wordsA)
-	if totalWords == 0 {
-		return 0
-	}
+	return InterleaveSpeakers(speakers, perChannel), nil
+}
 
-	return float64(matches) / float64(totalWords)
+// SetStreamingCallback sets the function to call with transcription results
+func (t *WhisperTranscriber) SetStreamingCallback(callback func(string)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.textCallback = callback
 }
 
-// containsSubstantialOverlap checks if string a contains a substantial part of string b
-// Uses a threshold parameter to adjust sensitivity
-func containsSubstantialOverlap(a, b string, threshold float64) bool {
-	// If one string is much longer than the other, they're probably different
-	if len(a) > 2*len(b) || len(b) > 2*len(a) {
-		return false
-	}
+// SetWordCallback sets a function called with the words of each segment
+// sent to the streaming callback, timed relative to the transcribed window
+func (t *WhisperTranscriber) SetWordCallback(callback func([]Word)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.wordCallback = callback
+}
 
-	// Split into words
-	wordsA := strings.Fields(a)
-	wordsB := strings.Fields(b)
+// SetPassCallback sets a function called after each live transcription pass
+// with the length of audio transcribed and how long whisper took
+func (t *WhisperTranscriber) SetPassCallback(callback func(window, elapsed time.Duration)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.passCallback = callback
+}
 
-	// If B is very short, be more conservative to avoid losing content
-	if len(wordsB) < 4 {
-		return false
+// segmentWords returns the words of a segment with their timing
+func segmentWords(segment whisper.Segment) []Word {
+	tokens := make([]Token, len(segment.Tokens))
+	for i, token := range segment.Tokens {
+		tokens[i] = Token{Text: token.Text, Start: token.Start, End: token.End}
 	}
+	return JoinTokens(tokens)
+}
 
-	// Count words from B that appear in A
-	matches := 0
+// EndUtterance drops the transcribed audio once the speaker has paused, so
+// the next utterance starts fresh instead of transcribing the previous one
+// again. Audio not yet transcribed is kept.
+func (t *WhisperTranscriber) EndUtterance() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.buffer.trim(0)
+}
 
-	// Create map of words in A for faster lookup
-	wordMapA := make(map[string]int)
-	for _, word := range wordsA {
-		wordMapA[word]++
-	}
+// SetKeepContext sets whether each live window is prompted with the end of
+// the text before it. It applies from the next pass.
+func (t *WhisperTranscriber) SetKeepContext(keep bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.carryover.setKeep(keep)
+}
 
-	// Count words from B that appear in A
-	for _, word := range wordsB {
-		if count, exists := wordMapA[word]; exists && count > 0 {
-			matches++
-			wordMapA[word]--
-		}
-	}
+// SetLanguage sets the language transcribed, or LanguageAuto to detect it in
+// each window. It applies from the next recording or transcription, and only
+// to multilingual models.
+func (t *WhisperTranscriber) SetLanguage(language string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.language = language
+}
 
-	// Return true if overlap exceeds threshold
-	return float64(matches)/float64(len(wordsB)) > threshold
+// SetDecoding implements Decoder; it applies from the next pass
+func (t *WhisperTranscriber) SetDecoding(decoding Decoding) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.decoding = decoding
 }
 
-// SetStreamingCallback sets the function to call with transcription results
-func (t *WhisperTranscriber) SetStreamingCallback(callback func(string)) {
+// SetLanguageCallback sets a function called with the language detected for
+// each segment sent to the streaming callback, while detecting the language
+func (t *WhisperTranscriber) SetLanguageCallback(callback func(language string)) {
 	t.mu.Lock()
 	defer t.mu.Unlock()
-	t.textCallback = callback
+	t.languageCallback = callback
 }
 
-// SetRecordingState updates internal state for recording
-func (t *WhisperTranscriber) SetRecordingState(isRecording bool) {
+// detectsLanguage reports whether whisper detects the language of each
+// window. The caller must hold t.mu.
+func (t *WhisperTranscriber) detectsLanguage() bool {
+	return t.language == LanguageAuto && t.model != nil && t.model.IsMultilingual()
+}
+
+// SetSuppressor sets how hallucinated text is dropped before reaching the
+// streaming callback. nil turns suppression off.
+func (t *WhisperTranscriber) SetSuppressor(suppressor *Suppressor) {
 	t.mu.Lock()
 	defer t.mu.Unlock()
+	t.suppressor = suppressor
+}
 
-	t.recordingActive = isRecording
+// SetVocabulary primes the model with words and names it should recognize.
+// It applies from the next recording or transcription.
+func (t *WhisperTranscriber) SetVocabulary(words []string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.initialPrompt = VocabularyPrompt(words)
+}
+
+// SetRecordingState starts or stops live transcription. A pass still
+// running when a recording stops finishes, but its text is dropped, and the
+// next recording's passes wait for it, since whisper can't run two at once.
+// Starting twice doesn't start a second stream.
+func (t *WhisperTranscriber) SetRecordingState(isRecording bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
 
 	if isRecording {
-		// Clear buffer and set up for new recording
-		t.buffer = t.buffer[:0]
-		t.processingActive = false
-		t.lastText = ""
-		t.recentSegments = t.recentSegments[:0] // Clear segment history
+		began, ready := t.life.start()
+		if !began {
+			logger.Debug(logger.CategoryTranscription, "Ignoring a start while %s", t.life.state)
+			return
+		}
 
-		// Configure the context with optimal settings
-		t.configureContext()
+		// Clear buffer and set up for new recording
+		t.buffer.reset()
+		t.dedup.Reset() // Clear segment history
+		t.carryover.reset()
+
+		// Configure the context with optimal settings, once the previous
+		// pass no longer uses it
+		if ready {
+			t.configureContext()
+		}
 
 		logger.Info(logger.CategoryTranscription, "Starting whisper transcription")
 	} else {
+		t.life.stop()
+
 		// Clear buffer when stopping
-		t.buffer = t.buffer[:0]
-		t.processingActive = false
-		t.lastText = ""
-		t.recentSegments = t.recentSegments[:0] // Clear segment history
+		t.buffer.reset()
+		t.dedup.Reset() // Clear segment history
 
 		logger.Info(logger.CategoryTranscription, "Stopping whisper transcription")
 	}
 }
 
+// Drain stops live transcription once the audio already added is
+// transcribed and its text sent, so the last sentence of a dictation isn't
+// lost. Audio added meanwhile is ignored. After timeout the recording stops
+// anyway, dropping the text of the pass whisper is still running.
+func (t *WhisperTranscriber) Drain(timeout time.Duration) error {
+	defer t.SetRecordingState(false)
+
+	return drainUntil(timeout, func() (bool, error) {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+
+		switch {
+		case t.life.closed():
+			return false, errStreamClosed
+		case !t.life.drain() || t.context == nil:
+			return true, nil // Not recording
+		case t.life.busy():
+			return false, nil
+		case t.buffer.pending() == 0:
+			return true, nil
+		}
+		logger.Debug(logger.CategoryTranscription, "Transcribing the last %d samples of the recording", t.buffer.pending())
+		t.startPass()
+		return false, nil
+	})
+}
+
+// endPass ends the running pass, configuring the context for a recording
+// that waited for it and closing the model if the transcriber was closed
+// meanwhile. The caller must hold t.mu.
+func (t *WhisperTranscriber) endPass() {
+	if t.life.endPass() {
+		t.configureContext()
+	}
+	if t.life.closed() {
+		t.closeModel()
+	}
+}
+
 // configureContext sets optimal parameters for streaming transcription
 func (t *WhisperTranscriber) configureContext() {
-	// Basic configuration - language, performance settings
-	_ = t.context.SetLanguage("en")
+	if t.context == nil {
+		return
+	}
+
+	// Basic configuration - language, performance settings. English-only
+	// models refuse any language, including English.
+	if err := t.context.SetLanguage(t.language); err != nil && t.language != DefaultLanguage {
+		logger.Warning(logger.CategoryTranscription, "Transcribing in the model's language instead of %q: %v", t.language, err)
+	}
 
 	// Dynamically set thread count based on available CPU cores
 	numCPU := runtime.NumCPU()
-	// Use at most half of available cores to leave resources for UI
-	threadCount := numCPU / 2
-	if threadCount < 2 {
-		threadCount = 2 // Minimum of 2 threads
-	} else if threadCount > 6 {
-		threadCount = 6 // Maximum of 6 threads to avoid excessive CPU usage
-	}
+	threadCount := whisperThreads(numCPU) // At most half, to leave resources for the UI
 	t.context.SetThreads(uint(threadCount))
 
 	logger.Info(logger.CategoryTranscription,
@@ -342,19 +509,94 @@ func (t *WhisperTranscriber) configureContext() {
 	t.context.SetSplitOnWord(true)
 	t.context.SetMaxSegmentLength(0)   // Don't artificially limit segments
 	t.context.SetTokenTimestamps(true) // Enable timestamps for words
+
+	// How tokens are chosen, and when text is rejected and decoded again
+	t.context.SetTemperature(float32(t.decoding.Temperature))
+	t.context.SetTemperatureFallback(float32(t.decoding.TemperatureFallback))
+	t.context.SetBeamSize(t.decoding.BeamSize)
+	t.context.SetEntropyThold(float32(t.decoding.EntropyThreshold))
+	// Only newer bindings can set the no-speech threshold
+	if context, ok := t.context.(interface{ SetNoSpeechThold(float32) }); ok {
+		context.SetNoSpeechThold(float32(t.decoding.NoSpeechThreshold))
+	}
+
+	// Bias recognition towards the user's vocabulary
+	t.context.SetInitialPrompt(t.initialPrompt)
+}
+
+// IsLoaded reports whether the whisper model is in memory
+func (t *WhisperTranscriber) IsLoaded() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.model != nil
+}
+
+// Load reloads the whisper model after Unload. It does nothing if the model is loaded.
+func (t *WhisperTranscriber) Load() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.model != nil {
+		return nil
+	}
+
+	model, err := whisper.New(t.modelPath)
+	if err != nil {
+		return fmt.Errorf("failed to load whisper model: %w", err)
+	}
+	context, err := model.NewContext()
+	if err != nil {
+		model.Close()
+		return fmt.Errorf("failed to create whisper context: %w", err)
+	}
+
+	t.model = model
+	t.context = context
+	logger.Info(logger.CategoryTranscription, "Whisper model loaded")
+	return nil
 }
 
-// Close releases resources
+// Unload frees the whisper model's memory while the transcriber is idle.
+// Call Load before transcribing again.
+func (t *WhisperTranscriber) Unload() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if !t.life.idle() {
+		return fmt.Errorf("cannot unload the model while transcribing")
+	}
+	if t.model == nil {
+		return nil
+	}
+
+	t.model.Close()
+	t.model = nil
+	t.context = nil
+	t.buffer = liveBuffer{samples: make([]float32, 0, 16000*5)} // Drop any large buffer grown during recording
+	logger.Info(logger.CategoryTranscription, "Whisper model unloaded")
+	return nil
+}
+
+// Close stops live transcription for good and releases resources. A pass
+// still running keeps the model until it ends.
 func (t *WhisperTranscriber) Close() error {
 	t.mu.Lock()
 	defer t.mu.Unlock()
 
+	t.life.close()
+	if !t.life.busy() {
+		t.closeModel()
+	}
+	t.buffer = liveBuffer{}
+
+	return nil
+}
+
+// closeModel frees the model. The caller must hold t.mu.
+func (t *WhisperTranscriber) closeModel() {
 	if t.model != nil {
 		t.model.Close()
 		t.model = nil
 	}
 	t.context = nil
-	t.buffer = nil
-
-	return nil
 }
@@ -0,0 +1,201 @@
+package transcription
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	logger "github.com/jeff-barlow-spady/ramble/pkg/logger"
+)
+
+// manifestVersion is the version of the manifest format written
+const manifestVersion = 1
+
+// Manifest records how far a batch of files is through transcription, so
+// an interrupted batch can resume where it stopped instead of starting over.
+// It is saved as JSON after every chunk. Files are recognized by their
+// absolute path, and started afresh if their size or modification time has
+// changed since.
+type Manifest struct {
+	path string
+
+	mu   sync.Mutex
+	data manifestData
+}
+
+// manifestData is what is saved in a manifest file
+type manifestData struct {
+	Version int
+	Model   string
+	Files   map[string]*manifestFile
+}
+
+// manifestFile is the progress of one file of the batch
+type manifestFile struct {
+	Size     int64
+	Modified time.Time
+	Chunks   int               // Number of chunks the file was split into
+	Done     map[int][]Segment // Segments of each chunk transcribed, by index
+	Complete bool              // The file's transcript has been written
+}
+
+// OpenManifest opens the manifest at path, or starts a new one if there is
+// none. model is the model the batch is transcribed with; resuming with
+// another model is refused, as the transcripts would be a mix of both.
+func OpenManifest(path, model string) (*Manifest, error) {
+	m := &Manifest{
+		path: path,
+		data: manifestData{Version: manifestVersion, Model: model, Files: make(map[string]*manifestFile)},
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return m, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+	if err := json.Unmarshal(data, &m.data); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest %s: %w", path, err)
+	}
+	if m.data.Version != manifestVersion {
+		return nil, fmt.Errorf("manifest %s has unsupported version %d", path, m.data.Version)
+	}
+	if m.data.Model != model {
+		return nil, fmt.Errorf("manifest %s was started with the %s model; resume with the same model or use a new manifest", path, m.data.Model)
+	}
+	if m.data.Files == nil {
+		m.data.Files = make(map[string]*manifestFile)
+	}
+	return m, nil
+}
+
+// Complete reports whether the transcript of file was written by an earlier
+// run and the file hasn't changed since
+func (m *Manifest) Complete(file string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	record, _, err := m.record(file)
+	return err == nil && record.Complete
+}
+
+// Pending returns the chunks of file still to be transcribed, starting the
+// file afresh if it changed or was split differently. Each chunk records
+// its segments in the manifest once it is transcribed.
+func (m *Manifest) Pending(file string, chunks []Chunk) ([]Chunk, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	record, key, err := m.record(file)
+	if err != nil {
+		return nil, err
+	}
+	if record.Chunks != len(chunks) {
+		record.Chunks = len(chunks)
+		record.Done = nil
+	}
+	if record.Done == nil {
+		record.Done = make(map[int][]Segment)
+	}
+
+	var pending []Chunk
+	for i, chunk := range chunks {
+		if _, ok := record.Done[i]; ok {
+			continue
+		}
+		chunk.Done = func(segments []Segment) {
+			m.mu.Lock()
+			defer m.mu.Unlock()
+			record.Done[i] = segments
+			if err := m.save(); err != nil {
+				logger.Warning(logger.CategoryTranscription, "Failed to save progress of %s: %v", key, err)
+			}
+		}
+		pending = append(pending, chunk)
+	}
+	if len(pending) < len(chunks) {
+		logger.Info(logger.CategoryTranscription, "Resuming %s with %d of %d chunks done", key, len(chunks)-len(pending), len(chunks))
+	}
+	return pending, m.save()
+}
+
+// Segments returns the segments of every chunk of file transcribed so far,
+// in order
+func (m *Manifest) Segments(file string) []Segment {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	record, _, err := m.record(file)
+	if err != nil {
+		return nil
+	}
+	indexes := make([]int, 0, len(record.Done))
+	for i := range record.Done {
+		indexes = append(indexes, i)
+	}
+	sort.Ints(indexes)
+
+	var segments []Segment
+	for _, i := range indexes {
+		segments = append(segments, record.Done[i]...)
+	}
+	return segments
+}
+
+// MarkComplete records that the transcript of file has been written, and
+// drops its segments from the manifest
+func (m *Manifest) MarkComplete(file string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	record, _, err := m.record(file)
+	if err != nil {
+		return err
+	}
+	record.Complete = true
+	record.Done = nil
+	return m.save()
+}
+
+// record returns the progress of file and its key in the manifest, starting
+// it afresh if the file changed; the manifest must be locked
+func (m *Manifest) record(file string) (*manifestFile, string, error) {
+	key, err := filepath.Abs(file)
+	if err != nil {
+		return nil, "", err
+	}
+	info, err := os.Stat(file)
+	if err != nil {
+		return nil, "", err
+	}
+
+	record := m.data.Files[key]
+	if record == nil || record.Size != info.Size() || !record.Modified.Equal(info.ModTime()) {
+		record = &manifestFile{Size: info.Size(), Modified: info.ModTime()}
+		m.data.Files[key] = record
+	}
+	return record, key, nil
+}
+
+// save replaces the manifest file atomically, so an interruption while
+// writing leaves the previous progress; the manifest must be locked
+func (m *Manifest) save() error {
+	data, err := json.MarshalIndent(m.data, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode manifest: %w", err)
+	}
+	tmpPath := m.path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	if err := os.Rename(tmpPath, m.path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to replace manifest: %w", err)
+	}
+	return nil
+}
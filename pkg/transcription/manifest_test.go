@@ -0,0 +1,73 @@
+package transcription
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestManifestResume(t *testing.T) {
+	dir := t.TempDir()
+	audio := filepath.Join(dir, "talk.wav")
+	if err := os.WriteFile(audio, []byte("audio"), 0644); err != nil {
+		t.Fatalf("Failed to write: %v", err)
+	}
+	path := filepath.Join(dir, "batch.json")
+	chunks := testChunks(20*time.Second, 25*time.Second, 30*time.Second)
+
+	// The first run is interrupted after the second chunk
+	manifest, err := OpenManifest(path, "small")
+	if err != nil {
+		t.Fatalf("Failed to open: %v", err)
+	}
+	pending, err := manifest.Pending(audio, chunks)
+	if err != nil || len(pending) != 3 {
+		t.Fatalf("Expected 3 chunks pending, got %d (%v)", len(pending), err)
+	}
+	pending[1].Done([]Segment{{Start: 21 * time.Second, Text: "second"}})
+
+	// The next run only transcribes the rest
+	manifest, err = OpenManifest(path, "small")
+	if err != nil {
+		t.Fatalf("Failed to reopen: %v", err)
+	}
+	if manifest.Complete(audio) {
+		t.Error("Expected the file not to be complete")
+	}
+	pending, err = manifest.Pending(audio, chunks)
+	if err != nil || len(pending) != 2 || pending[1].Offset != 45*time.Second {
+		t.Fatalf("Expected the first and last chunks pending, got %d (%v)", len(pending), err)
+	}
+	pending[1].Done([]Segment{{Start: 46 * time.Second, Text: "third"}})
+	pending[0].Done([]Segment{{Start: time.Second, Text: "first"}})
+
+	segments := manifest.Segments(audio)
+	if len(segments) != 3 || segments[0].Text != "first" || segments[2].Text != "third" || segments[1].Start != 21*time.Second {
+		t.Errorf("Expected the segments of all 3 chunks in order, got %+v", segments)
+	}
+
+	if err := manifest.MarkComplete(audio); err != nil {
+		t.Fatalf("Failed to mark complete: %v", err)
+	}
+	manifest, _ = OpenManifest(path, "small")
+	if !manifest.Complete(audio) {
+		t.Error("Expected the file to be complete after reopening")
+	}
+
+	// A changed file starts over
+	if err := os.WriteFile(audio, []byte("longer audio"), 0644); err != nil {
+		t.Fatalf("Failed to write: %v", err)
+	}
+	if manifest.Complete(audio) {
+		t.Error("Expected a changed file not to be complete")
+	}
+	if pending, _ := manifest.Pending(audio, chunks); len(pending) != 3 {
+		t.Errorf("Expected a changed file to start over, got %d chunks pending", len(pending))
+	}
+
+	// Another model can't resume the batch
+	if _, err := OpenManifest(path, "tiny"); err == nil {
+		t.Error("Expected an error resuming with another model")
+	}
+}
@@ -0,0 +1,170 @@
+package transcription
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ModelFile is a model found in one of the model directories
+type ModelFile struct {
+	Size  ModelSize // Parsed from the file name; may not be one of ModelSizes
+	Path  string
+	Bytes int64
+}
+
+// ggmlMagic starts every model file whisper.cpp can load
+const ggmlMagic = 0x67676d6c
+
+// modelBaseURL is where whisper.cpp publishes its converted models
+const modelBaseURL = "https://huggingface.co/ggerganov/whisper.cpp/resolve/main/"
+
+// errNotModel is returned for files that are not whisper models
+var errNotModel = errors.New("not a whisper model file")
+
+// FindModels lists the models in every model directory, in order of
+// preference. Where a size is installed twice, the first is the one loaded.
+func FindModels() []ModelFile {
+	var models []ModelFile
+	seen := make(map[string]bool)
+	for _, dir := range ModelDirs() {
+		paths, _ := filepath.Glob(filepath.Join(dir, "ggml-*.bin"))
+		for _, path := range paths {
+			abs, err := filepath.Abs(path)
+			if err != nil || seen[abs] {
+				continue
+			}
+			seen[abs] = true
+
+			info, err := os.Stat(path)
+			if err != nil || info.IsDir() {
+				continue
+			}
+			models = append(models, ModelFile{Size: modelSizeFromName(info.Name()), Path: path, Bytes: info.Size()})
+		}
+	}
+	return models
+}
+
+// modelSizeFromName returns the size in a ggml-{model}.en.bin or
+// ggml-{model}.en-{quantization}.bin file name
+func modelSizeFromName(name string) ModelSize {
+	name = strings.TrimPrefix(name, "ggml-")
+	name = strings.TrimSuffix(name, ".bin")
+	if base, quantization, ok := strings.Cut(name, ".en-"); ok {
+		return Quantized(ModelSize(base), quantization)
+	}
+	return ModelSize(strings.TrimSuffix(name, ".en"))
+}
+
+// VerifyModel checks that the file at path is a whisper model, so a
+// truncated or failed download isn't mistaken for one
+func VerifyModel(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	header := make([]byte, 4)
+	if _, err := io.ReadFull(f, header); err != nil || !isModelData(header) {
+		return fmt.Errorf("%s: %w", path, errNotModel)
+	}
+	return nil
+}
+
+// isModelData reports whether data starts like a whisper model file
+func isModelData(data []byte) bool {
+	return len(data) >= 4 && binary.LittleEndian.Uint32(data) == ggmlMagic
+}
+
+// modelURL returns where the model of the given size is downloaded from.
+// There is no English-only large model, so the multilingual one is used.
+func modelURL(modelSize ModelSize) string {
+	if modelSize.Base() == ModelLarge {
+		if quantization := modelSize.Quantization(); quantization != "" {
+			return modelBaseURL + "ggml-large-v3-" + quantization + ".bin"
+		}
+		return modelBaseURL + "ggml-large-v3.bin"
+	}
+	return modelBaseURL + ModelFileName(modelSize)
+}
+
+// DownloadModel downloads the model of the given size into UserModelDir and
+// returns its path. ModelAuto downloads the largest model that fits in
+// available memory. progress is called with the fraction downloaded when the
+// size is known. The model only replaces an existing file once it is complete
+// and verified.
+func DownloadModel(ctx context.Context, modelSize ModelSize, progress func(fraction float64)) (string, error) {
+	if modelSize == ModelAuto {
+		modelSize = pickDownload(availableMemory())
+	}
+	dir := UserModelDir()
+	if dir == "" {
+		return "", fmt.Errorf("no home directory to store models in")
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create model directory: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, modelURL(modelSize), nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to download the %s model: %w", modelSize, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to download the %s model: %s", modelSize, resp.Status)
+	}
+
+	path := filepath.Join(dir, ModelFileName(modelSize))
+	tmp, err := os.CreateTemp(dir, ModelFileName(modelSize)+".*.part")
+	if err != nil {
+		return "", fmt.Errorf("failed to create model file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	var body io.Reader = resp.Body
+	if progress != nil && resp.ContentLength > 0 {
+		body = &progressReader{r: resp.Body, total: resp.ContentLength, progress: progress}
+	}
+	_, err = io.Copy(tmp, body)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to download the %s model: %w", modelSize, err)
+	}
+
+	if err := VerifyModel(tmp.Name()); err != nil {
+		return "", fmt.Errorf("downloaded %s model is invalid: %w", modelSize, err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return "", fmt.Errorf("failed to save the %s model: %w", modelSize, err)
+	}
+	return path, nil
+}
+
+// progressReader reports how much of a body of known length has been read
+type progressReader struct {
+	r        io.Reader
+	read     int64
+	total    int64
+	progress func(fraction float64)
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	p.read += int64(n)
+	p.progress(float64(p.read) / float64(p.total))
+	return n, err
+}
@@ -0,0 +1,60 @@
+package transcription
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestFindModels tests that models in the user's model directory are listed
+// with their sizes and that GetLocalModelPath finds them
+func TestFindModels(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	dir := UserModelDir()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, ModelFileName(ModelBase))
+	if err := os.WriteFile(path, []byte{0x6c, 0x6d, 0x67, 0x67, 0, 0}, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var found *ModelFile
+	for _, model := range FindModels() {
+		if model.Path == path {
+			found = &model
+		}
+	}
+	if found == nil {
+		t.Fatalf("Expected %s to be listed", path)
+	}
+	if found.Size != ModelBase || found.Bytes != 6 {
+		t.Errorf("Expected a 6 byte base model, got %d bytes of %q", found.Bytes, found.Size)
+	}
+	if got := GetLocalModelPath(ModelBase); got != path {
+		t.Errorf("Expected GetLocalModelPath to find %s, got %q", path, got)
+	}
+}
+
+// TestVerifyModel tests that only files starting with the ggml magic are
+// accepted as models
+func TestVerifyModel(t *testing.T) {
+	dir := t.TempDir()
+	model := filepath.Join(dir, "model.bin")
+	os.WriteFile(model, []byte{0x6c, 0x6d, 0x67, 0x67, 1, 2, 3}, 0644)
+	if err := VerifyModel(model); err != nil {
+		t.Errorf("Expected a valid model, got %v", err)
+	}
+
+	for name, content := range map[string][]byte{
+		"page.bin":  []byte("<!DOCTYPE html>"),
+		"short.bin": {0x6c, 0x6d},
+	} {
+		path := filepath.Join(dir, name)
+		os.WriteFile(path, content, 0644)
+		if err := VerifyModel(path); err == nil {
+			t.Errorf("Expected %s to be rejected", name)
+		}
+	}
+}
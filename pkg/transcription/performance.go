@@ -0,0 +1,77 @@
+package transcription
+
+import (
+	"runtime"
+	"sync"
+	"time"
+)
+
+// rtfSmoothing is the weight of the newest pass in the smoothed real-time
+// factor, so one slow pass doesn't flash the indicator
+const rtfSmoothing = 0.3
+
+// Performance is how fast live transcription keeps up, and what with
+type Performance struct {
+	Backend string // Label of the backend, e.g. "Whisper (built in)"
+	Model   string // Model size, empty when the backend chooses its own
+	Threads int    // CPU threads transcribing, 0 when the backend runs elsewhere
+	// RTF is the real-time factor: time spent transcribing over the length
+	// of the audio transcribed, smoothed over recent passes. Above 1 the
+	// machine can't keep up.
+	RTF float64
+}
+
+// Lagging reports whether transcription is slower than real time
+func (p Performance) Lagging() bool {
+	return p.RTF > 1
+}
+
+// PerformanceMeter tracks the performance of live transcription from the
+// pass callback. It is safe for concurrent use.
+type PerformanceMeter struct {
+	mu      sync.Mutex
+	current Performance
+}
+
+// NewPerformanceMeter creates a meter for transcription with the given
+// backend label, model and CPU threads
+func NewPerformanceMeter(backend, model string, threads int) *PerformanceMeter {
+	return &PerformanceMeter{current: Performance{Backend: backend, Model: model, Threads: threads}}
+}
+
+// Pass records a live pass that transcribed window of audio in elapsed and
+// returns the updated performance
+func (m *PerformanceMeter) Pass(window, elapsed time.Duration) Performance {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if window <= 0 {
+		return m.current
+	}
+	rtf := elapsed.Seconds() / window.Seconds()
+	if m.current.RTF == 0 {
+		m.current.RTF = rtf
+	} else {
+		m.current.RTF += rtfSmoothing * (rtf - m.current.RTF)
+	}
+	return m.current
+}
+
+// Current returns the performance so far
+func (m *PerformanceMeter) Current() Performance {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.current
+}
+
+// WhisperThreads returns the number of CPU threads the built-in whisper
+// backend transcribes with on this machine
+func WhisperThreads() int {
+	return whisperThreads(runtime.NumCPU())
+}
+
+// whisperThreads returns the number of threads whisper uses on a machine
+// with numCPU cores: half of them to leave room for the UI, from 2 to 6
+func whisperThreads(numCPU int) int {
+	return min(max(numCPU/2, 2), 6)
+}
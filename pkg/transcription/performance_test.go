@@ -0,0 +1,48 @@
+package transcription
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// TestPerformanceMeter tests that the real-time factor starts at the first
+// pass and follows slower passes gradually
+func TestPerformanceMeter(t *testing.T) {
+	m := NewPerformanceMeter("Whisper (built in)", "small", 4)
+
+	if perf := m.Pass(0, time.Second); perf.RTF != 0 {
+		t.Errorf("Expected an empty window to be ignored, got RTF %v", perf.RTF)
+	}
+
+	perf := m.Pass(10*time.Second, 4*time.Second)
+	if math.Abs(perf.RTF-0.4) > 1e-9 || perf.Lagging() {
+		t.Errorf("Expected RTF 0.4, got %v", perf.RTF)
+	}
+	if perf.Backend != "Whisper (built in)" || perf.Model != "small" || perf.Threads != 4 {
+		t.Errorf("Expected the backend, model and threads to be kept, got %+v", perf)
+	}
+
+	perf = m.Pass(time.Second, 2*time.Second)
+	if perf.RTF <= 0.4 || perf.RTF >= 2 {
+		t.Errorf("Expected RTF between 0.4 and 2 after a slow pass, got %v", perf.RTF)
+	}
+	for i := 0; i < 20; i++ {
+		perf = m.Pass(time.Second, 2*time.Second)
+	}
+	if !perf.Lagging() {
+		t.Errorf("Expected lagging after many slow passes, got RTF %v", perf.RTF)
+	}
+	if current := m.Current(); current != perf {
+		t.Errorf("Expected the current performance to be the last returned, got %+v", current)
+	}
+}
+
+// TestWhisperThreads tests that whisper uses half the cores, from 2 to 6
+func TestWhisperThreads(t *testing.T) {
+	for _, tc := range []struct{ cores, threads int }{{1, 2}, {4, 2}, {8, 4}, {12, 6}, {64, 6}} {
+		if got := whisperThreads(tc.cores); got != tc.threads {
+			t.Errorf("Expected %d threads for %d cores, got %d", tc.threads, tc.cores, got)
+		}
+	}
+}
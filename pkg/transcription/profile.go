@@ -0,0 +1,68 @@
+package transcription
+
+import "time"
+
+// LatencyProfile trades how quickly text appears against how accurate it is
+type LatencyProfile string
+
+const (
+	// ProfileLowLatency shows text soonest, transcribing short windows often
+	ProfileLowLatency LatencyProfile = "low-latency"
+	// ProfileBalanced suits most dictation
+	ProfileBalanced LatencyProfile = "balanced"
+	// ProfileAccuracy gives whisper longer windows and more context, and
+	// prefers a larger model, at the cost of text appearing later
+	ProfileAccuracy LatencyProfile = "accuracy"
+)
+
+// LatencyProfiles lists the profiles from fastest to most accurate
+var LatencyProfiles = []LatencyProfile{ProfileLowLatency, ProfileBalanced, ProfileAccuracy}
+
+// Tuning holds the streaming settings chosen by a latency profile
+type Tuning struct {
+	FramesPerBuffer    int           // Audio frames delivered per capture callback
+	ProcessingInterval time.Duration // Time between transcription passes
+	MinAudio           time.Duration // Audio needed before the first pass
+	MaxWindow          time.Duration // Most recent audio transcribed in each pass
+	ContextRetention   time.Duration // Audio kept between passes as context
+	Model              ModelSize     // Model loaded for live transcription
+}
+
+// ProfileTuning returns the settings for a latency profile. Unknown profiles
+// get the balanced settings.
+func ProfileTuning(profile LatencyProfile) Tuning {
+	switch profile {
+	case ProfileLowLatency:
+		return Tuning{
+			FramesPerBuffer:    512,
+			ProcessingInterval: 600 * time.Millisecond,
+			MinAudio:           800 * time.Millisecond,
+			MaxWindow:          6 * time.Second,
+			ContextRetention:   8 * time.Second,
+			Model:              ModelTiny,
+		}
+	case ProfileAccuracy:
+		return Tuning{
+			FramesPerBuffer:    2048,
+			ProcessingInterval: 2500 * time.Millisecond,
+			MinAudio:           2 * time.Second,
+			MaxWindow:          20 * time.Second,
+			ContextRetention:   25 * time.Second,
+			Model:              ModelBase,
+		}
+	default:
+		return Tuning{
+			FramesPerBuffer:    1024,
+			ProcessingInterval: 1200 * time.Millisecond,
+			MinAudio:           time.Second,
+			MaxWindow:          10 * time.Second,
+			ContextRetention:   15 * time.Second,
+			Model:              ModelTiny,
+		}
+	}
+}
+
+// sampleCount converts a duration of 16kHz audio to a number of samples
+func sampleCount(d time.Duration) int {
+	return int(d.Seconds() * 16000)
+}
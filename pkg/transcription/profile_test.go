@@ -0,0 +1,51 @@
+package transcription
+
+import (
+	"testing"
+	"time"
+)
+
+// TestProfileTuningOrder tests that faster profiles transcribe shorter windows
+// more often than more accurate ones
+func TestProfileTuningOrder(t *testing.T) {
+	for i := 1; i < len(LatencyProfiles); i++ {
+		faster, slower := ProfileTuning(LatencyProfiles[i-1]), ProfileTuning(LatencyProfiles[i])
+		if faster.ProcessingInterval >= slower.ProcessingInterval {
+			t.Errorf("Expected %s to process more often than %s", LatencyProfiles[i-1], LatencyProfiles[i])
+		}
+		if faster.MaxWindow >= slower.MaxWindow || faster.FramesPerBuffer >= slower.FramesPerBuffer {
+			t.Errorf("Expected %s to use smaller buffers than %s", LatencyProfiles[i-1], LatencyProfiles[i])
+		}
+	}
+
+	for _, profile := range LatencyProfiles {
+		tuning := ProfileTuning(profile)
+		if tuning.ContextRetention < tuning.MaxWindow {
+			t.Errorf("%s: expected at least one window of context, got %v for a %v window",
+				profile, tuning.ContextRetention, tuning.MaxWindow)
+		}
+	}
+}
+
+// TestProfileTuningDefault tests that unknown profiles keep the balanced
+// settings, which match the transcriber's original behavior
+func TestProfileTuningDefault(t *testing.T) {
+	if ProfileTuning("") != ProfileTuning(ProfileBalanced) {
+		t.Error("Expected an empty profile to be balanced")
+	}
+	if ProfileTuning("fastest") != ProfileTuning(ProfileBalanced) {
+		t.Error("Expected an unknown profile to be balanced")
+	}
+
+	balanced := ProfileTuning(ProfileBalanced)
+	if balanced.ProcessingInterval != 1200*time.Millisecond || balanced.Model != ModelTiny {
+		t.Errorf("Expected balanced to process every 1.2s with the tiny model, got %v with %s",
+			balanced.ProcessingInterval, balanced.Model)
+	}
+}
+
+func TestSampleCount(t *testing.T) {
+	if got := sampleCount(1500 * time.Millisecond); got != 24000 {
+		t.Errorf("Expected 24000 samples in 1.5s, got %d", got)
+	}
+}
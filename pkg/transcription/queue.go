@@ -0,0 +1,285 @@
+package transcription
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrQueueClosed is returned for jobs not finished when the queue closed
+var ErrQueueClosed = errors.New("transcription queue closed")
+
+// Queue transcribes batch jobs, such as files split into chunks, with a pool
+// of workers that each have their own transcriber. Chunks are taken in the
+// order they were queued, so a single long file is spread over every worker
+// and several files are worked through together. Workers open their
+// transcriber when there is work and close it once the queue is empty, so
+// models are only held while jobs run.
+type Queue struct {
+	open    func() (Transcriber, error) // Creates each worker's transcriber
+	workers int
+
+	mu       sync.Mutex
+	pending  []queuedChunk
+	running  int            // Workers started and not yet stopped
+	idle     sync.WaitGroup // Done once running reaches 0
+	jobs     []*Job         // Jobs since the queue was last empty, for progress
+	progress func(QueueProgress)
+	closed   bool
+}
+
+// queuedChunk is a chunk of a job waiting for a worker
+type queuedChunk struct {
+	job   *Job
+	index int
+}
+
+// Job is a recording queued to be transcribed
+type Job struct {
+	chunks    []Chunk
+	results   [][]Segment
+	remaining int // Chunks not yet transcribed
+	length    time.Duration
+	finished  time.Duration // Length of the chunks transcribed
+	progress  func(percent int)
+	err       error
+	done      chan struct{}
+}
+
+// QueueProgress is how far the queue is through the jobs added since it was
+// last empty
+type QueueProgress struct {
+	Jobs     int           // Jobs queued
+	JobsDone int           // Jobs finished, including failed ones
+	Length   time.Duration // Audio queued
+	Finished time.Duration // Audio transcribed
+}
+
+// Percent returns the percentage of the queued audio transcribed
+func (p QueueProgress) Percent() int {
+	if p.Length <= 0 {
+		return 100
+	}
+	return int(p.Finished * 100 / p.Length)
+}
+
+// NewQueue creates a queue transcribing with up to workers transcribers at a
+// time, each created by open. Every transcriber loads its own model, so
+// memory use grows with the number of workers.
+func NewQueue(workers int, open func() (Transcriber, error)) *Queue {
+	return &Queue{open: open, workers: max(1, workers)}
+}
+
+// SetProgressCallback sets the function called with the progress of all jobs
+// each time a chunk is transcribed. It is called with the queue locked, so
+// it must not add jobs.
+func (q *Queue) SetProgressCallback(callback func(QueueProgress)) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.progress = callback
+}
+
+// Add queues the chunks of a recording to be transcribed, starting workers
+// as needed. progress, if not nil, is called with the percentage of the
+// recording transcribed.
+func (q *Queue) Add(chunks []Chunk, progress func(percent int)) *Job {
+	job := &Job{
+		chunks:    chunks,
+		results:   make([][]Segment, len(chunks)),
+		remaining: len(chunks),
+		progress:  progress,
+		done:      make(chan struct{}),
+	}
+	for _, chunk := range chunks {
+		job.length += sampleDuration(len(chunk.Samples))
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		job.finish(ErrQueueClosed)
+		return job
+	}
+	if len(chunks) == 0 {
+		job.finish(nil)
+		return job
+	}
+
+	q.jobs = append(q.jobs, job)
+	for i := range chunks {
+		q.pending = append(q.pending, queuedChunk{job: job, index: i})
+	}
+	for q.running < min(q.workers, len(q.pending)) {
+		q.running++
+		q.idle.Add(1)
+		go q.work()
+	}
+	return job
+}
+
+// work transcribes queued chunks until there are none left
+func (q *Queue) work() {
+	defer q.idle.Done()
+	transcriber, err := q.open()
+
+	q.mu.Lock()
+	if err != nil {
+		// Other workers carry on with the queue; the last one fails it
+		q.running--
+		if q.running == 0 {
+			q.failPending(fmt.Errorf("failed to open transcriber: %w", err))
+			q.jobs = nil
+		}
+		q.mu.Unlock()
+		return
+	}
+
+	for len(q.pending) > 0 && !q.closed {
+		next := q.pending[0]
+		q.pending = q.pending[1:]
+		if next.job.isDone() {
+			continue // An earlier chunk failed
+		}
+
+		q.mu.Unlock()
+		chunk := next.job.chunks[next.index]
+		segments, err := transcriber.TranscribeSamples(chunk.Samples)
+		segments = offsetSegments(segments, chunk.Offset)
+		if err == nil && chunk.Done != nil {
+			chunk.Done(segments)
+		}
+		q.mu.Lock()
+		q.finishChunk(next, segments, err)
+	}
+
+	q.running--
+	if q.running == 0 {
+		q.jobs = nil
+	}
+	q.mu.Unlock()
+	transcriber.Close()
+}
+
+// finishChunk records the result of a chunk and reports progress; the queue
+// must be locked
+func (q *Queue) finishChunk(next queuedChunk, segments []Segment, err error) {
+	job := next.job
+	chunk := job.chunks[next.index]
+	if job.isDone() {
+		return // Failed while this chunk was transcribed
+	}
+
+	job.finished += sampleDuration(len(chunk.Samples))
+	if err != nil {
+		job.finish(fmt.Errorf("chunk at %v: %w", chunk.Offset.Round(time.Second), err))
+	} else {
+		job.results[next.index] = segments
+		job.remaining--
+		if job.progress != nil {
+			job.progress(job.percent())
+		}
+		if job.remaining == 0 {
+			job.finish(nil)
+		}
+	}
+	q.reportProgress()
+}
+
+// failPending fails the jobs of the chunks still queued and reports
+// progress if there were any; the queue must be locked
+func (q *Queue) failPending(err error) {
+	failed := false
+	for _, next := range q.pending {
+		if !next.job.isDone() {
+			next.job.finish(err)
+			failed = true
+		}
+	}
+	q.pending = nil
+	if failed {
+		q.reportProgress()
+	}
+}
+
+// reportProgress calls the progress callback with the progress of the jobs
+// since the queue was last empty; the queue must be locked
+func (q *Queue) reportProgress() {
+	if q.progress == nil {
+		return
+	}
+	var p QueueProgress
+	for _, job := range q.jobs {
+		p.Jobs++
+		p.Length += job.length
+		if job.isDone() {
+			p.JobsDone++
+			p.Finished += job.length
+		} else {
+			p.Finished += job.finished
+		}
+	}
+	q.progress(p)
+}
+
+// Close fails the jobs still queued with ErrQueueClosed and waits for the
+// chunks being transcribed to finish
+func (q *Queue) Close() {
+	q.mu.Lock()
+	q.closed = true
+	q.failPending(ErrQueueClosed)
+	q.mu.Unlock()
+	q.idle.Wait()
+}
+
+// finish ends the job with err, or its segments if err is nil
+func (j *Job) finish(err error) {
+	j.err = err
+	close(j.done)
+}
+
+// isDone reports whether the job has finished
+func (j *Job) isDone() bool {
+	select {
+	case <-j.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// percent returns the percentage of the job's audio transcribed
+func (j *Job) percent() int {
+	if j.length <= 0 {
+		return 100
+	}
+	return int(j.finished * 100 / j.length)
+}
+
+// Wait waits for the job to finish and returns its segments in order, with
+// times from the start of the recording
+func (j *Job) Wait() ([]Segment, error) {
+	<-j.done
+	if j.err != nil {
+		return nil, j.err
+	}
+
+	var segments []Segment
+	for _, result := range j.results {
+		segments = append(segments, result...)
+	}
+	return segments, nil
+}
+
+// offsetSegments moves the times of segments and their words by offset
+func offsetSegments(segments []Segment, offset time.Duration) []Segment {
+	for i := range segments {
+		segments[i].Start += offset
+		segments[i].End += offset
+		for j := range segments[i].Words {
+			segments[i].Words[j].Start += offset
+			segments[i].Words[j].End += offset
+		}
+	}
+	return segments
+}
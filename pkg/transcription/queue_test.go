@@ -0,0 +1,172 @@
+package transcription
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// chunkEngine returns a segment named after the length of audio it was
+// given, failing audio of the length in fail
+type chunkEngine struct {
+	fail   time.Duration
+	closed *counter
+}
+
+// counter counts under a lock, for engines used by several workers
+type counter struct {
+	mu sync.Mutex
+	n  int
+}
+
+func (c *counter) add(n int) {
+	c.mu.Lock()
+	c.n += n
+	c.mu.Unlock()
+}
+
+func (c *counter) get() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.n
+}
+
+func (e *chunkEngine) Transcribe(samples []float32, prompt, language string) ([]Segment, error) {
+	end := sampleDuration(len(samples))
+	if end == e.fail {
+		return nil, errors.New("out of memory")
+	}
+	return []Segment{{
+		Start: time.Second,
+		End:   end,
+		Text:  end.String(),
+		Words: []Word{{Text: "word", Start: time.Second, End: 2 * time.Second}},
+	}}, nil
+}
+func (e *chunkEngine) IsLoaded() bool { return true }
+func (e *chunkEngine) Load() error    { return nil }
+func (e *chunkEngine) Unload() error  { return nil }
+func (e *chunkEngine) Close() error {
+	if e.closed != nil {
+		e.closed.add(1)
+	}
+	return nil
+}
+
+// testChunks returns silent chunks of the given lengths, one after another
+func testChunks(lengths ...time.Duration) []Chunk {
+	var chunks []Chunk
+	var offset time.Duration
+	for _, length := range lengths {
+		chunks = append(chunks, Chunk{Offset: offset, Samples: make([]float32, sampleCount(length))})
+		offset += length
+	}
+	return chunks
+}
+
+func TestQueue(t *testing.T) {
+	// Workers wait to open their transcribers until both jobs are queued
+	var opened, closed counter
+	start := make(chan struct{})
+	queue := NewQueue(2, func() (Transcriber, error) {
+		<-start
+		opened.add(1)
+		return NewEngineTranscriber(&chunkEngine{closed: &closed}), nil
+	})
+	var progress []QueueProgress
+	queue.SetProgressCallback(func(p QueueProgress) {
+		progress = append(progress, p)
+	})
+
+	lengths := [][]time.Duration{
+		{20 * time.Second, 25 * time.Second, 30 * time.Second, 5 * time.Second},
+		{10 * time.Second},
+	}
+	var jobs []*Job
+	var percents [2][]int
+	var mu sync.Mutex
+	var done []Segment
+	for i := range lengths {
+		chunks := testChunks(lengths[i]...)
+		chunks[len(chunks)-1].Done = func(segments []Segment) {
+			mu.Lock()
+			done = append(done, segments...)
+			mu.Unlock()
+		}
+		jobs = append(jobs, queue.Add(chunks, func(percent int) {
+			percents[i] = append(percents[i], percent)
+		}))
+	}
+	close(start)
+
+	for i, job := range jobs {
+		segments, err := job.Wait()
+		if err != nil {
+			t.Fatalf("Job %d failed: %v", i, err)
+		}
+		if len(segments) != len(lengths[i]) {
+			t.Fatalf("Job %d: expected %d segments, got %d", i, len(lengths[i]), len(segments))
+		}
+
+		var offset time.Duration
+		for j, seg := range segments {
+			length := lengths[i][j]
+			if seg.Text != length.String() {
+				t.Errorf("Job %d segment %d: expected the chunk of %v, got %q", i, j, length, seg.Text)
+			}
+			if want := offset + time.Second; seg.Start != want || seg.Words[0].Start != want {
+				t.Errorf("Job %d segment %d: expected it and its words to start at %v, got %v and %v", i, j, want, seg.Start, seg.Words[0].Start)
+			}
+			if want := offset + length; seg.End != want {
+				t.Errorf("Job %d segment %d: expected it to end at %v, got %v", i, j, want, seg.End)
+			}
+			offset += length
+		}
+	}
+
+	queue.Close()
+	if opened.get() != 2 || closed.get() != 2 {
+		t.Errorf("Expected 2 transcribers opened and closed, got %d and %d", opened.get(), closed.get())
+	}
+	for i := range percents {
+		if len(percents[i]) != len(lengths[i]) || percents[i][len(percents[i])-1] != 100 {
+			t.Errorf("Job %d: expected progress for each chunk ending at 100, got %v", i, percents[i])
+		}
+	}
+	if len(done) != 2 || done[0].Start+done[1].Start != 77*time.Second {
+		t.Errorf("Expected the last chunk of each job to be done with its offset, got %+v", done)
+	}
+	last := progress[len(progress)-1]
+	if len(progress) != 5 || last.Jobs != 2 || last.JobsDone != 2 || last.Percent() != 100 {
+		t.Errorf("Expected progress for 5 chunks ending with both jobs done, got %d ending %+v", len(progress), last)
+	}
+}
+
+func TestQueueErrors(t *testing.T) {
+	// A failing chunk fails its own job only
+	queue := NewQueue(2, func() (Transcriber, error) {
+		return NewEngineTranscriber(&chunkEngine{fail: 7 * time.Second}), nil
+	})
+	failing := queue.Add(testChunks(20*time.Second, 7*time.Second, 20*time.Second), nil)
+	working := queue.Add(testChunks(20*time.Second), nil)
+	if _, err := failing.Wait(); err == nil {
+		t.Error("Expected an error from the failing chunk")
+	}
+	if _, err := working.Wait(); err != nil {
+		t.Errorf("Expected the other job to succeed, got %v", err)
+	}
+	queue.Close()
+	if _, err := queue.Add(testChunks(time.Second), nil).Wait(); !errors.Is(err, ErrQueueClosed) {
+		t.Errorf("Expected ErrQueueClosed after closing, got %v", err)
+	}
+
+	// Jobs fail if no transcriber opens
+	queue = NewQueue(2, func() (Transcriber, error) {
+		return nil, errors.New("model not found")
+	})
+	if _, err := queue.Add(testChunks(time.Second, time.Second), nil).Wait(); err == nil {
+		t.Error("Expected an error when no transcriber opens")
+	}
+	queue.Close()
+}
@@ -0,0 +1,123 @@
+package transcription
+
+import (
+	"errors"
+	"strings"
+	"sync"
+)
+
+// rewriteQueueSize is how many utterances can wait to be rewritten
+const rewriteQueueSize = 32
+
+// ErrRewriterClosed is passed to a job's callback if the rewriter closed
+// before the job ran
+var ErrRewriterClosed = errors.New("rewriter closed")
+
+// Rewriter transcribes finalized utterances again in the background, one at a
+// time in the order they were queued. For two-pass transcription a fast model
+// shows text live and the rewriter's more accurate model replaces it once its
+// result arrives.
+type Rewriter struct {
+	open  func() (Transcriber, error) // Creates the transcriber on first use
+	queue chan rewriteJob
+	done  chan struct{} // Closed once the worker has stopped
+
+	mu          sync.Mutex
+	closed      bool
+	transcriber Transcriber
+}
+
+// rewriteJob is an utterance waiting to be rewritten
+type rewriteJob struct {
+	samples []float32
+	apply   func(text string, err error)
+}
+
+// NewRewriter creates a rewriter using the transcriber open returns, which is
+// called when the first utterance is rewritten
+func NewRewriter(open func() (Transcriber, error)) *Rewriter {
+	r := &Rewriter{
+		open:  open,
+		queue: make(chan rewriteJob, rewriteQueueSize),
+		done:  make(chan struct{}),
+	}
+	go r.run()
+	return r
+}
+
+// Enqueue queues 16kHz samples to be transcribed again and calls apply with
+// the text from the worker goroutine. It reports false without queueing if the
+// queue is full or the rewriter is closed.
+func (r *Rewriter) Enqueue(samples []float32, apply func(text string, err error)) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		return false
+	}
+	select {
+	case r.queue <- rewriteJob{samples: samples, apply: apply}:
+		return true
+	default:
+		return false
+	}
+}
+
+// run rewrites queued utterances until the rewriter is closed
+func (r *Rewriter) run() {
+	defer close(r.done)
+	for job := range r.queue {
+		if r.isClosed() {
+			job.apply("", ErrRewriterClosed)
+			continue
+		}
+		job.apply(r.rewrite(job.samples))
+	}
+}
+
+// rewrite transcribes samples, opening the transcriber if needed
+func (r *Rewriter) rewrite(samples []float32) (string, error) {
+	if r.transcriber == nil {
+		transcriber, err := r.open()
+		if err != nil {
+			return "", err
+		}
+		r.transcriber = transcriber
+	}
+
+	segments, err := r.transcriber.TranscribeSamples(samples)
+	if err != nil {
+		return "", err
+	}
+	texts := make([]string, len(segments))
+	for i, segment := range segments {
+		texts[i] = segment.Text
+	}
+	return strings.Join(texts, " "), nil
+}
+
+// isClosed reports whether Close has been called
+func (r *Rewriter) isClosed() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.closed
+}
+
+// Close stops the rewriter once the utterance in progress is done, failing
+// the rest of the queue with ErrRewriterClosed, and closes the transcriber
+func (r *Rewriter) Close() error {
+	r.mu.Lock()
+	if r.closed {
+		r.mu.Unlock()
+		return nil
+	}
+	r.closed = true
+	close(r.queue)
+	r.mu.Unlock()
+
+	<-r.done
+	if r.transcriber != nil {
+		return r.transcriber.Close()
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package transcription
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestRewriterOrder tests that utterances are rewritten in the order they
+// were queued, opening the transcriber once
+func TestRewriterOrder(t *testing.T) {
+	engine := &fakeEngine{text: " accurate text "}
+	opened := 0
+	rewriter := NewRewriter(func() (Transcriber, error) {
+		opened++
+		return NewEngineTranscriber(engine), nil
+	})
+
+	results := make(chan int, 3)
+	for i := 0; i < 3; i++ {
+		i := i
+		ok := rewriter.Enqueue(loudAudio(), func(text string, err error) {
+			if err != nil || text != "Accurate text" {
+				t.Errorf("Expected the engine's text, got %q (%v)", text, err)
+			}
+			results <- i
+		})
+		if !ok {
+			t.Fatalf("Expected utterance %d to be queued", i)
+		}
+	}
+	for want := 0; want < 3; want++ {
+		if got := <-results; got != want {
+			t.Errorf("Expected utterance %d to be rewritten next, got %d", want, got)
+		}
+	}
+
+	rewriter.Close()
+	if opened != 1 {
+		t.Errorf("Expected the transcriber to be opened once, got %d", opened)
+	}
+	if rewriter.Enqueue(loudAudio(), func(string, error) {}) {
+		t.Error("Expected a closed rewriter to refuse utterances")
+	}
+}
+
+// TestRewriterOpenError tests that a transcriber that fails to open fails
+// the job and is tried again for the next one
+func TestRewriterOpenError(t *testing.T) {
+	failure := errors.New("model missing")
+	rewriter := NewRewriter(func() (Transcriber, error) { return nil, failure })
+	defer rewriter.Close()
+
+	for i := 0; i < 2; i++ {
+		errs := make(chan error, 1)
+		rewriter.Enqueue(loudAudio(), func(text string, err error) { errs <- err })
+		if err := <-errs; !errors.Is(err, failure) {
+			t.Errorf("Expected the open error, got %v", err)
+		}
+	}
+}
@@ -0,0 +1,210 @@
+package transcription
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultServerURL is where whisper.cpp's server example listens by default
+const DefaultServerURL = "http://127.0.0.1:8080"
+
+// serverTimeout bounds each request, long enough for a long recording
+const serverTimeout = 10 * time.Minute
+
+// ServerEngine transcribes with a locally running whisper.cpp server
+// (whisper-server), posting each window as a WAV file to its /inference
+// endpoint. This needs neither cgo nor a whisper process fed over stdin.
+type ServerEngine struct {
+	url    string
+	client *http.Client
+
+	mu       sync.Mutex
+	decoding Decoding
+}
+
+// NewServerEngine creates an engine for the whisper-server at url, such as
+// DefaultServerURL
+func NewServerEngine(url string) *ServerEngine {
+	if url == "" {
+		url = DefaultServerURL
+	}
+	return &ServerEngine{
+		url:      strings.TrimSuffix(url, "/"),
+		client:   &http.Client{Timeout: serverTimeout},
+		decoding: defaultDecoding,
+	}
+}
+
+// SetDecoding implements Decoder
+func (e *ServerEngine) SetDecoding(decoding Decoding) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.decoding = decoding
+}
+
+// NewServerTranscriber creates a transcriber using the whisper-server at url
+func NewServerTranscriber(url string) *EngineTranscriber {
+	return NewEngineTranscriber(NewServerEngine(url))
+}
+
+// serverResponse is the verbose_json response of whisper-server. Times are
+// in seconds.
+type serverResponse struct {
+	Text     string `json:"text"`
+	Language string `json:"language"` // English name of the language transcribed
+	Segments []struct {
+		Text  string  `json:"text"`
+		Start float64 `json:"start"`
+		End   float64 `json:"end"`
+		Words []struct {
+			Word  string  `json:"word"`
+			Start float64 `json:"start"`
+			End   float64 `json:"end"`
+		} `json:"words"`
+	} `json:"segments"`
+	Error string `json:"error"`
+}
+
+// Transcribe implements Engine
+func (e *ServerEngine) Transcribe(samples []float32, prompt, language string) ([]Segment, error) {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	file, err := form.CreateFormFile("file", "audio.wav")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := file.Write(encodeWav(samples)); err != nil {
+		return nil, err
+	}
+	e.mu.Lock()
+	decoding := e.decoding
+	e.mu.Unlock()
+	form.WriteField("response_format", "verbose_json")
+	form.WriteField("temperature", formatFloat(decoding.Temperature))
+	form.WriteField("temperature_inc", formatFloat(decoding.TemperatureFallback))
+	form.WriteField("beam_size", strconv.Itoa(decoding.BeamSize))
+	form.WriteField("entropy_thold", formatFloat(decoding.EntropyThreshold))
+	form.WriteField("no_speech_thold", formatFloat(decoding.NoSpeechThreshold))
+	form.WriteField("language", language)
+	if prompt != "" {
+		form.WriteField("prompt", prompt)
+	}
+	if err := form.Close(); err != nil {
+		return nil, err
+	}
+
+	resp, err := e.client.Post(e.url+"/inference", form.FormDataContentType(), &body)
+	if err != nil {
+		return nil, fmt.Errorf("whisper-server request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read whisper-server response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("whisper-server returned %s: %s", resp.Status, strings.TrimSpace(string(data)))
+	}
+	segments, detected, err := parseServerResponse(data)
+	if language == LanguageAuto {
+		for i := range segments {
+			segments[i].Language = detected
+		}
+	}
+	return segments, err
+}
+
+// formatFloat writes a setting as the server parses it
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// parseServerResponse converts a whisper-server response to segments and the
+// code of the language transcribed. Older servers send only the text, which
+// becomes a single untimed segment.
+func parseServerResponse(data []byte) ([]Segment, string, error) {
+	var response serverResponse
+	if err := json.Unmarshal(data, &response); err != nil {
+		return nil, "", fmt.Errorf("invalid whisper-server response: %w", err)
+	}
+	if response.Error != "" {
+		return nil, "", fmt.Errorf("whisper-server: %s", response.Error)
+	}
+	language := LanguageCode(response.Language)
+
+	if len(response.Segments) == 0 {
+		if text := strings.TrimSpace(response.Text); text != "" {
+			return []Segment{{Text: text}}, language, nil
+		}
+		return nil, language, nil
+	}
+
+	segments := make([]Segment, len(response.Segments))
+	for i, s := range response.Segments {
+		segments[i] = Segment{Text: s.Text, Start: seconds(s.Start), End: seconds(s.End)}
+		for _, w := range s.Words {
+			if text := strings.TrimSpace(w.Word); text != "" {
+				segments[i].Words = append(segments[i].Words, Word{Text: text, Start: seconds(w.Start), End: seconds(w.End)})
+			}
+		}
+	}
+	return segments, language, nil
+}
+
+// seconds converts a time in seconds to a duration
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
+
+// IsLoaded implements Engine; the server holds the model
+func (e *ServerEngine) IsLoaded() bool { return true }
+
+// Load implements Engine; the server holds the model
+func (e *ServerEngine) Load() error { return nil }
+
+// Unload implements Engine; the server holds the model
+func (e *ServerEngine) Unload() error { return nil }
+
+// Close implements Engine
+func (e *ServerEngine) Close() error {
+	e.client.CloseIdleConnections()
+	return nil
+}
+
+// encodeWav encodes 16kHz mono samples as a 16-bit PCM WAV file
+func encodeWav(samples []float32) []byte {
+	const sampleRate = 16000
+	dataSize := len(samples) * 2
+
+	var buf bytes.Buffer
+	buf.Grow(44 + dataSize)
+	buf.WriteString("RIFF")
+	binary.Write(&buf, binary.LittleEndian, uint32(36+dataSize))
+	buf.WriteString("WAVEfmt ")
+	binary.Write(&buf, binary.LittleEndian, uint32(16))           // Format chunk size
+	binary.Write(&buf, binary.LittleEndian, uint16(1))            // PCM
+	binary.Write(&buf, binary.LittleEndian, uint16(1))            // Mono
+	binary.Write(&buf, binary.LittleEndian, uint32(sampleRate))   // Sample rate
+	binary.Write(&buf, binary.LittleEndian, uint32(sampleRate*2)) // Byte rate
+	binary.Write(&buf, binary.LittleEndian, uint16(2))            // Block align
+	binary.Write(&buf, binary.LittleEndian, uint16(16))           // Bits per sample
+	buf.WriteString("data")
+	binary.Write(&buf, binary.LittleEndian, uint32(dataSize))
+
+	for _, sample := range samples {
+		sample = float32(math.Max(-1, math.Min(1, float64(sample))))
+		binary.Write(&buf, binary.LittleEndian, int16(sample*math.MaxInt16))
+	}
+	return buf.Bytes()
+}
@@ -0,0 +1,74 @@
+package transcription
+
+import (
+	"encoding/binary"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// TestServerEngine tests that audio is posted to whisper-server as a WAV file
+// and its segments are returned with their timing
+func TestServerEngine(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/inference" {
+			t.Errorf("Expected a request to /inference, got %s", r.URL.Path)
+		}
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			t.Fatalf("Expected an audio file: %v", err)
+		}
+		header := make([]byte, 44)
+		file.Read(header)
+		if string(header[:4]) != "RIFF" || binary.LittleEndian.Uint32(header[40:]) != 8 {
+			t.Errorf("Expected a WAV file of 4 samples, got header %q", header)
+		}
+		if r.FormValue("prompt") != "Ramble" {
+			t.Errorf("Expected the vocabulary prompt, got %q", r.FormValue("prompt"))
+		}
+		if r.FormValue("language") != LanguageAuto {
+			t.Errorf("Expected language detection, got %q", r.FormValue("language"))
+		}
+		if r.FormValue("beam_size") != "5" || r.FormValue("no_speech_thold") != "0.45" || r.FormValue("temperature_inc") != "0" {
+			t.Errorf("Expected the decoding settings, got beam size %q, no-speech threshold %q and temperature fallback %q",
+				r.FormValue("beam_size"), r.FormValue("no_speech_thold"), r.FormValue("temperature_inc"))
+		}
+		w.Write([]byte(`{"text":" Hello world.","language":"english","segments":[{"text":" Hello world.","start":0.5,"end":1.25,
+			"words":[{"word":" Hello","start":0.5,"end":0.8},{"word":" world.","start":0.8,"end":1.25}]}]}`))
+	}))
+	defer server.Close()
+
+	engine := NewServerEngine(server.URL + "/")
+	engine.SetDecoding(Decoding{BeamSize: 5, EntropyThreshold: 2.4, NoSpeechThreshold: 0.45})
+	segments, err := engine.Transcribe([]float32{0, 0.5, -0.5, 1}, "Ramble", LanguageAuto)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(segments) != 1 || segments[0].Text != " Hello world." {
+		t.Fatalf("Unexpected segments %+v", segments)
+	}
+	if segments[0].Language != "en" {
+		t.Errorf("Expected the detected language, got %q", segments[0].Language)
+	}
+	if segments[0].Start != 500*time.Millisecond || segments[0].End != 1250*time.Millisecond {
+		t.Errorf("Unexpected segment timing %v to %v", segments[0].Start, segments[0].End)
+	}
+	if words := segments[0].Words; len(words) != 2 || words[1].Text != "world." || words[1].Start != 800*time.Millisecond {
+		t.Errorf("Unexpected words %+v", words)
+	}
+}
+
+// TestParseServerResponse tests responses without segments and with errors
+func TestParseServerResponse(t *testing.T) {
+	segments, _, err := parseServerResponse([]byte(`{"text":" Just text."}`))
+	if err != nil || len(segments) != 1 || segments[0].Text != "Just text." {
+		t.Errorf("Expected a single untimed segment, got %+v (%v)", segments, err)
+	}
+	if segments, _, err := parseServerResponse([]byte(`{"text":""}`)); err != nil || len(segments) != 0 {
+		t.Errorf("Expected no segments for silence, got %+v (%v)", segments, err)
+	}
+	if _, _, err := parseServerResponse([]byte(`{"error":"failed to read WAV file"}`)); err == nil {
+		t.Error("Expected the server's error to be returned")
+	}
+}
@@ -0,0 +1,446 @@
+package transcription
+
+import (
+	"bufio"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	logger "github.com/jeff-barlow-spady/ramble/pkg/logger"
+)
+
+// The sidecar protocol connects Ramble to a speech recognizer running as a
+// child process, such as scripts/ramble-faster-whisper for faster-whisper.
+// Messages are JSON objects, one per line. The sidecar reads requests on
+// stdin and writes responses on stdout; anything it writes to stderr is
+// logged.
+//
+// Once its model is loaded the sidecar writes a sidecarReady message. Ramble
+// then sends one sidecarRequest at a time and waits for the sidecarResponse
+// with the same ID before sending the next. The sidecar exits when stdin is
+// closed.
+
+// sidecarReady is the first message a sidecar writes
+type sidecarReady struct {
+	Ready       bool   `json:"ready"`
+	Model       string `json:"model"`        // Description of the loaded model, for the log
+	AudioFormat string `json:"audio_format"` // Sample encoding the sidecar wants; formatFloat32 if empty
+	Error       string `json:"error"`        // Why the sidecar could not start
+}
+
+// Sample encodings a sidecar can ask for in sidecarReady
+const (
+	formatFloat32 = "f32le" // 32-bit little endian floats
+	formatInt16   = "s16le" // 16-bit little endian signed integers
+)
+
+// sidecarRequest asks the sidecar to transcribe a window of audio
+type sidecarRequest struct {
+	ID         int              `json:"id"`
+	Audio      string           `json:"audio"`       // Base64 of the samples, encoded as asked for in sidecarReady
+	SampleRate int              `json:"sample_rate"` // Always 16000
+	Prompt     string           `json:"prompt,omitempty"`
+	Language   string           `json:"language,omitempty"` // Language code, or "auto" to detect it
+	Decoding   *sidecarDecoding `json:"decoding,omitempty"` // Whisper decoding settings, for sidecars running whisper
+}
+
+// sidecarDecoding is Decoding as sent to a sidecar. Sidecars that don't run
+// whisper ignore it.
+type sidecarDecoding struct {
+	Temperature         float64 `json:"temperature"`
+	TemperatureFallback float64 `json:"temperature_fallback"`
+	BeamSize            int     `json:"beam_size"`
+	EntropyThreshold    float64 `json:"entropy_threshold"`
+	NoSpeechThreshold   float64 `json:"no_speech_threshold"`
+}
+
+// sidecarResponse answers a sidecarRequest. Times are in seconds from the
+// start of the window.
+type sidecarResponse struct {
+	ID       int    `json:"id"`
+	Language string `json:"language"` // Code of the language detected, if asked to detect it
+	Segments []struct {
+		Text  string  `json:"text"`
+		Start float64 `json:"start"`
+		End   float64 `json:"end"`
+		Words []struct {
+			Word  string  `json:"word"`
+			Start float64 `json:"start"`
+			End   float64 `json:"end"`
+		} `json:"words"`
+	} `json:"segments"`
+	Error string `json:"error"`
+}
+
+// sidecarStartTimeout bounds how long a sidecar may take to load its model
+const sidecarStartTimeout = 2 * time.Minute
+
+// DefaultSidecarTimeout is how long a sidecar may leave a request
+// unanswered before it is taken as hung and restarted
+const DefaultSidecarTimeout = 30 * time.Second
+
+// errSidecarHung is returned when the sidecar didn't answer in time
+var errSidecarHung = errors.New("sidecar stopped responding")
+
+// SidecarEngine transcribes with a recognizer running as a child process,
+// speaking the sidecar protocol. The process is started on first use and
+// stopped by Unload, which frees its model's memory. A watchdog kills a
+// sidecar that stops answering and transparently retries the request with a
+// new one, so a hung recognizer doesn't silently end transcription.
+type SidecarEngine struct {
+	command []string
+
+	// timeout is how long a request may go unanswered, on top of twice the
+	// length of its audio, so long recordings get the time they need
+	timeout   time.Duration
+	restarted func(reason error) // Told when a hung sidecar was restarted
+	decoding  *Decoding          // Sent with each request; nil leaves the sidecar's own settings
+
+	mu      sync.Mutex
+	cmd     *exec.Cmd
+	stdin   io.WriteCloser
+	stdout  *bufio.Scanner
+	exited  chan struct{} // Closed when the process exits
+	format  string        // Sample encoding the running sidecar asked for
+	nextID  int
+	started time.Time
+}
+
+// NewSidecarEngine creates an engine running command, the program followed by
+// its arguments
+func NewSidecarEngine(command []string) *SidecarEngine {
+	return &SidecarEngine{command: command, timeout: DefaultSidecarTimeout}
+}
+
+// SetResponseTimeout sets how long the sidecar may leave a request
+// unanswered before it is restarted. 0 waits forever.
+func (e *SidecarEngine) SetResponseTimeout(timeout time.Duration) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.timeout = timeout
+}
+
+// SetRestartCallback implements Restarter. The callback runs on the
+// transcribing goroutine.
+func (e *SidecarEngine) SetRestartCallback(callback func(reason error)) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.restarted = callback
+}
+
+// SetDecoding implements Decoder; the settings are sent with each request
+func (e *SidecarEngine) SetDecoding(decoding Decoding) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.decoding = &decoding
+}
+
+// NewSidecarTranscriber creates a transcriber using the sidecar run by command
+func NewSidecarTranscriber(command []string) *EngineTranscriber {
+	return NewEngineTranscriber(NewSidecarEngine(command))
+}
+
+// Transcribe implements Engine, starting the sidecar if it isn't running.
+// A sidecar that hangs is restarted and asked once more.
+func (e *SidecarEngine) Transcribe(samples []float32, prompt, language string) ([]Segment, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	response, err := e.request(samples, prompt, language)
+	if errors.Is(err, errSidecarHung) {
+		logger.Warning(logger.CategoryTranscription, "Sidecar %s %v; restarting it", e.command[0], err)
+		if e.restarted != nil {
+			e.restarted(err)
+		}
+		response, err = e.request(samples, prompt, language)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	segments := make([]Segment, len(response.Segments))
+	for i, s := range response.Segments {
+		segments[i] = Segment{Text: s.Text, Start: seconds(s.Start), End: seconds(s.End), Language: LanguageCode(response.Language)}
+		for _, w := range s.Words {
+			if text := strings.TrimSpace(w.Word); text != "" {
+				segments[i].Words = append(segments[i].Words, Word{Text: text, Start: seconds(w.Start), End: seconds(w.End)})
+			}
+		}
+	}
+	return segments, nil
+}
+
+// request sends samples to the sidecar, starting it if it isn't running,
+// and waits for the answer. A sidecar that doesn't answer in time is killed.
+// The caller must hold e.mu.
+func (e *SidecarEngine) request(samples []float32, prompt, language string) (*sidecarResponse, error) {
+	if e.cmd == nil {
+		if err := e.start(); err != nil {
+			return nil, err
+		}
+	}
+
+	e.nextID++
+	request := sidecarRequest{ID: e.nextID, Audio: encodeSamples(samples, e.format), SampleRate: 16000, Prompt: prompt, Language: language}
+	if d := e.decoding; d != nil {
+		request.Decoding = &sidecarDecoding{
+			Temperature:         d.Temperature,
+			TemperatureFallback: d.TemperatureFallback,
+			BeamSize:            d.BeamSize,
+			EntropyThreshold:    d.EntropyThreshold,
+			NoSpeechThreshold:   d.NoSpeechThreshold,
+		}
+	}
+	line, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+
+	// Sending the request and reading the answer share one deadline, since a
+	// sidecar that stops reading blocks the write once the pipe is full
+	timeout := e.timeout
+	if timeout > 0 {
+		timeout += 2 * time.Duration(len(samples)) * time.Second / 16000
+	}
+	sent := time.Now()
+	if err := e.writeWithin(append(line, '\n'), timeout); err != nil {
+		if errors.Is(err, errSidecarHung) {
+			e.kill()
+		} else {
+			e.stop()
+		}
+		return nil, err
+	}
+	if timeout > 0 {
+		timeout = max(timeout-time.Since(sent), time.Millisecond)
+	}
+
+	response := &sidecarResponse{}
+	if err := e.readWithin(response, timeout); err != nil {
+		if errors.Is(err, errSidecarHung) {
+			e.kill()
+		} else {
+			e.stop()
+		}
+		return nil, err
+	}
+	if response.ID != request.ID {
+		e.stop()
+		return nil, fmt.Errorf("sidecar answered request %d instead of %d", response.ID, request.ID)
+	}
+	if response.Error != "" {
+		return nil, fmt.Errorf("sidecar: %s", response.Error)
+	}
+	return response, nil
+}
+
+// start runs the sidecar and waits until it has loaded its model. The caller
+// must hold e.mu.
+func (e *SidecarEngine) start() error {
+	if len(e.command) == 0 {
+		return errors.New("no sidecar command configured")
+	}
+
+	cmd := exec.Command(e.command[0], e.command[1:]...)
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start sidecar %s: %w", e.command[0], err)
+	}
+
+	// Responses carry a whole window of segments, so allow long lines
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	e.cmd, e.stdin, e.stdout, e.exited = cmd, stdin, scanner, make(chan struct{})
+	e.started = time.Now()
+
+	go logSidecarOutput(stderr)
+	go func(exited chan struct{}) {
+		cmd.Wait()
+		close(exited)
+	}(e.exited)
+
+	var ready sidecarReady
+	err = e.readWithin(&ready, sidecarStartTimeout)
+	if errors.Is(err, errSidecarHung) {
+		err = fmt.Errorf("sidecar did not start within %v", sidecarStartTimeout)
+	}
+	if err == nil && !ready.Ready {
+		err = fmt.Errorf("sidecar failed to start: %s", ready.Error)
+	}
+	if err == nil && ready.AudioFormat != "" && ready.AudioFormat != formatFloat32 && ready.AudioFormat != formatInt16 {
+		err = fmt.Errorf("sidecar asked for unknown audio format %q", ready.AudioFormat)
+	}
+	if err != nil {
+		e.stop()
+		return err
+	}
+	e.format = ready.AudioFormat
+
+	logger.Info(logger.CategoryTranscription, "Sidecar %s started with %s in %v",
+		e.command[0], ready.Model, time.Since(e.started).Round(time.Millisecond))
+	return nil
+}
+
+// writeWithin sends a message to the sidecar, giving up with errSidecarHung
+// after timeout, or never if it is 0. The write only ends after a timeout
+// once the sidecar is killed.
+func (e *SidecarEngine) writeWithin(line []byte, timeout time.Duration) error {
+	stdin := e.stdin
+	write := func() error {
+		if _, err := stdin.Write(line); err != nil {
+			return fmt.Errorf("sidecar stopped: %w", err)
+		}
+		return nil
+	}
+	if timeout <= 0 {
+		return write()
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- write() }()
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case err := <-done:
+		return err
+	case <-timer.C:
+		return fmt.Errorf("%w: request not read within %v", errSidecarHung, timeout)
+	}
+}
+
+// readWithin decodes the next message from the sidecar, giving up with
+// errSidecarHung after timeout, or never if it is 0. message must not be used
+// after a timeout, since the sidecar may still answer into it.
+func (e *SidecarEngine) readWithin(message any, timeout time.Duration) error {
+	stdout := e.stdout
+	if timeout <= 0 {
+		return readMessage(stdout, message)
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- readMessage(stdout, message) }()
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case err := <-done:
+		return err
+	case <-timer.C:
+		return fmt.Errorf("%w: no answer within %v", errSidecarHung, timeout)
+	}
+}
+
+// readMessage decodes the next message written to stdout
+func readMessage(stdout *bufio.Scanner, message any) error {
+	if !stdout.Scan() {
+		if err := stdout.Err(); err != nil {
+			return fmt.Errorf("failed to read from sidecar: %w", err)
+		}
+		return errors.New("sidecar exited")
+	}
+	if err := json.Unmarshal(stdout.Bytes(), message); err != nil {
+		return fmt.Errorf("invalid message from sidecar: %w", err)
+	}
+	return nil
+}
+
+// stop closes the sidecar's stdin so it exits, killing it if it doesn't do
+// so promptly. The caller must hold e.mu.
+func (e *SidecarEngine) stop() {
+	if e.cmd == nil {
+		return
+	}
+	e.stdin.Close()
+	select {
+	case <-e.exited:
+	case <-time.After(5 * time.Second):
+		e.cmd.Process.Kill()
+		<-e.exited
+	}
+	e.cmd, e.stdin, e.stdout = nil, nil, nil
+}
+
+// kill ends a sidecar that stopped responding. The caller must hold e.mu.
+func (e *SidecarEngine) kill() {
+	if e.cmd == nil {
+		return
+	}
+	e.cmd.Process.Kill()
+	<-e.exited
+	e.stdin.Close()
+	e.cmd, e.stdin, e.stdout = nil, nil, nil
+}
+
+// logSidecarOutput logs what the sidecar writes to stderr
+func logSidecarOutput(stderr io.Reader) {
+	scanner := bufio.NewScanner(stderr)
+	for scanner.Scan() {
+		logger.Debug(logger.CategoryTranscription, "Sidecar: %s", scanner.Text())
+	}
+}
+
+// IsLoaded reports whether the sidecar is running
+func (e *SidecarEngine) IsLoaded() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.cmd != nil
+}
+
+// Load starts the sidecar if it isn't running
+func (e *SidecarEngine) Load() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.cmd != nil {
+		return nil
+	}
+	return e.start()
+}
+
+// Unload stops the sidecar, freeing its model
+func (e *SidecarEngine) Unload() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.stop()
+	return nil
+}
+
+// Close stops the sidecar
+func (e *SidecarEngine) Close() error {
+	return e.Unload()
+}
+
+// encodeSamples encodes samples as base64 in the given format
+func encodeSamples(samples []float32, format string) string {
+	if format == formatInt16 {
+		raw := make([]byte, len(samples)*2)
+		for i, sample := range samples {
+			sample = float32(math.Max(-1, math.Min(1, float64(sample))))
+			binary.LittleEndian.PutUint16(raw[i*2:], uint16(int16(sample*math.MaxInt16)))
+		}
+		return base64.StdEncoding.EncodeToString(raw)
+	}
+
+	raw := make([]byte, len(samples)*4)
+	for i, sample := range samples {
+		binary.LittleEndian.PutUint32(raw[i*4:], math.Float32bits(sample))
+	}
+	return base64.StdEncoding.EncodeToString(raw)
+}
@@ -0,0 +1,185 @@
+package transcription
+
+import (
+	"bufio"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestSidecarHelper is run as a fake sidecar by the tests below. It answers
+// every request with the number of samples it received. Asked to "hang", it
+// stops responding unless the marker file says it already did. Made deaf, it
+// never reads a request.
+func TestSidecarHelper(t *testing.T) {
+	if os.Getenv("RAMBLE_SIDECAR_HELPER") != "1" {
+		return
+	}
+	fmt.Println(`{"ready":true,"model":"fake"}`)
+	if os.Getenv("RAMBLE_SIDECAR_DEAF") == "1" {
+		time.Sleep(time.Hour)
+	}
+	scanner := bufio.NewScanner(os.Stdin)
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		var request sidecarRequest
+		json.Unmarshal(scanner.Bytes(), &request)
+		if request.Prompt == "hang" {
+			if _, err := os.Stat(os.Getenv("RAMBLE_SIDECAR_MARKER")); err != nil {
+				os.WriteFile(os.Getenv("RAMBLE_SIDECAR_MARKER"), nil, 0o644)
+				time.Sleep(time.Hour)
+			}
+		}
+		if request.Prompt == "fail" {
+			fmt.Printf(`{"id":%d,"error":"model failed"}`+"\n", request.ID)
+			continue
+		}
+		language := ""
+		if request.Language == LanguageAuto {
+			language = "de"
+		}
+		fmt.Printf(`{"id":%d,"language":%q,"segments":[{"text":" %d samples","start":0,"end":1.5,"words":[{"word":" %d","start":0,"end":0.5}]}]}`+"\n",
+			request.ID, language, len(request.Audio)/4*3/4, len(request.Audio)/4*3/4)
+	}
+	os.Exit(0)
+}
+
+// helperSidecar returns an engine running TestSidecarHelper
+func helperSidecar(t *testing.T) *SidecarEngine {
+	t.Setenv("RAMBLE_SIDECAR_HELPER", "1")
+	return NewSidecarEngine([]string{os.Args[0], "-test.run=^TestSidecarHelper$"})
+}
+
+// TestSidecarEngine tests that the sidecar is started on first use, answers
+// requests in turn, and is restarted after Unload
+func TestSidecarEngine(t *testing.T) {
+	engine := helperSidecar(t)
+	defer engine.Close()
+
+	if engine.IsLoaded() {
+		t.Error("Expected the sidecar not to run before it is used")
+	}
+	for i := 0; i < 2; i++ {
+		segments, err := engine.Transcribe(make([]float32, 300), "", LanguageAuto)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(segments) != 1 || segments[0].Text != " 300 samples" || segments[0].End != 1500*time.Millisecond {
+			t.Fatalf("Unexpected segments %+v", segments)
+		}
+		if words := segments[0].Words; len(words) != 1 || words[0].Text != "300" {
+			t.Errorf("Unexpected words %+v", words)
+		}
+		if segments[0].Language != "de" {
+			t.Errorf("Expected the detected language, got %q", segments[0].Language)
+		}
+	}
+
+	if _, err := engine.Transcribe(nil, "fail", DefaultLanguage); err == nil {
+		t.Error("Expected the sidecar's error to be returned")
+	}
+
+	engine.Unload()
+	if engine.IsLoaded() {
+		t.Error("Expected Unload to stop the sidecar")
+	}
+	if _, err := engine.Transcribe(make([]float32, 10), "", DefaultLanguage); err != nil {
+		t.Errorf("Expected the sidecar to restart, got %v", err)
+	}
+}
+
+// TestSidecarWatchdog tests that a hung sidecar is killed and the request
+// retried with a new one
+func TestSidecarWatchdog(t *testing.T) {
+	t.Setenv("RAMBLE_SIDECAR_MARKER", filepath.Join(t.TempDir(), "hung"))
+	engine := helperSidecar(t)
+	defer engine.Close()
+	engine.SetResponseTimeout(200 * time.Millisecond)
+	var reasons []error
+	engine.SetRestartCallback(func(reason error) { reasons = append(reasons, reason) })
+
+	segments, err := engine.Transcribe(make([]float32, 300), "hang", DefaultLanguage)
+	if err != nil {
+		t.Fatalf("Expected the request to be retried, got %v", err)
+	}
+	if len(segments) != 1 || segments[0].Text != " 300 samples" {
+		t.Errorf("Unexpected segments %+v", segments)
+	}
+	if len(reasons) != 1 || !errors.Is(reasons[0], errSidecarHung) {
+		t.Errorf("Expected to be told of one restart, got %v", reasons)
+	}
+}
+
+// TestSidecarWatchdogWrite tests that a sidecar that stops reading requests
+// is killed rather than blocking the request, and everything waiting on it,
+// forever
+func TestSidecarWatchdogWrite(t *testing.T) {
+	t.Setenv("RAMBLE_SIDECAR_DEAF", "1")
+	engine := helperSidecar(t)
+	engine.SetResponseTimeout(100 * time.Millisecond)
+	var reasons []error
+	engine.SetRestartCallback(func(reason error) { reasons = append(reasons, reason) })
+
+	// A second of audio is more than a pipe buffers, so the write blocks
+	done := make(chan error, 1)
+	go func() {
+		_, err := engine.Transcribe(make([]float32, 16000), "", DefaultLanguage)
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if !errors.Is(err, errSidecarHung) {
+			t.Errorf("Expected the sidecar to be reported hung, got %v", err)
+		}
+	case <-time.After(20 * time.Second):
+		t.Fatal("Transcribe blocked writing to a sidecar that doesn't read")
+	}
+	if len(reasons) != 1 {
+		t.Errorf("Expected to be told of one restart, got %v", reasons)
+	}
+
+	closed := make(chan struct{})
+	go func() {
+		engine.Close()
+		close(closed)
+	}()
+	select {
+	case <-closed:
+	case <-time.After(10 * time.Second):
+		t.Fatal("Close blocked behind the hung request")
+	}
+	if engine.IsLoaded() {
+		t.Error("Expected the hung sidecar not to be running")
+	}
+}
+
+// TestEncodeSamples tests both sample encodings sidecars can ask for
+func TestEncodeSamples(t *testing.T) {
+	samples := []float32{0.5, -2}
+	raw, _ := base64.StdEncoding.DecodeString(encodeSamples(samples, formatFloat32))
+	if len(raw) != 8 || math.Float32frombits(binary.LittleEndian.Uint32(raw)) != 0.5 {
+		t.Errorf("Unexpected float encoding %v", raw)
+	}
+	raw, _ = base64.StdEncoding.DecodeString(encodeSamples(samples, formatInt16))
+	if len(raw) != 4 || int16(binary.LittleEndian.Uint16(raw[2:])) != -math.MaxInt16 {
+		t.Errorf("Expected 16-bit samples clipped to full scale, got %v", raw)
+	}
+}
+
+// TestSidecarMissing tests that a missing sidecar program is reported
+func TestSidecarMissing(t *testing.T) {
+	engine := NewSidecarEngine([]string{"ramble-no-such-sidecar"})
+	if err := engine.Load(); err == nil {
+		t.Error("Expected an error starting a missing sidecar")
+	}
+	if engine.IsLoaded() {
+		t.Error("Expected the engine not to be loaded")
+	}
+}
@@ -0,0 +1,45 @@
+# Sidecar that transcribes for Ramble with the speech recognizer built into
+# Windows (System.Speech), so no model has to be downloaded. Ramble runs it
+# through PowerShell. It speaks the sidecar protocol described in
+# pkg/transcription/sidecar.go, asking for 16-bit samples.
+
+$ErrorActionPreference = 'Stop'
+
+function Send($message) {
+    [Console]::Out.WriteLine(($message | ConvertTo-Json -Compress -Depth 5))
+    [Console]::Out.Flush()
+}
+
+try {
+    Add-Type -AssemblyName System.Speech
+    $engine = New-Object System.Speech.Recognition.SpeechRecognitionEngine
+    $engine.LoadGrammar((New-Object System.Speech.Recognition.DictationGrammar))
+} catch {
+    Send @{ ready = $false; error = $_.Exception.Message }
+    exit 1
+}
+$format = New-Object System.Speech.AudioFormat.SpeechAudioFormatInfo(16000,
+    [System.Speech.AudioFormat.AudioBitsPerSample]::Sixteen,
+    [System.Speech.AudioFormat.AudioChannel]::Mono)
+Send @{ ready = $true; model = "Windows speech recognition ($($engine.RecognizerInfo.Culture))"; audio_format = 's16le' }
+
+while ($null -ne ($line = [Console]::In.ReadLine())) {
+    $request = $line | ConvertFrom-Json
+    try {
+        $stream = New-Object System.IO.MemoryStream(, [Convert]::FromBase64String($request.audio))
+        $engine.SetInputToAudioStream($stream, $format)
+        $segments = @()
+        while ($null -ne ($result = $engine.Recognize())) {
+            $start = $result.Audio.AudioPosition
+            $segments += @{
+                text  = ' ' + $result.Text
+                start = $start.TotalSeconds
+                end   = ($start + $result.Audio.Duration).TotalSeconds
+            }
+        }
+        $engine.SetInputToNull()
+        Send @{ id = $request.id; segments = $segments }
+    } catch {
+        Send @{ id = $request.id; error = $_.Exception.Message }
+    }
+}
@@ -0,0 +1,67 @@
+package transcription
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Segment is a piece of transcribed text and its position in the audio
+type Segment struct {
+	Start   time.Duration
+	End     time.Duration
+	Speaker string
+	Text    string
+	Words   []Word // Timing of each word, where the backend reports it
+
+	// Code of the language detected, if the language was detected
+	Language string
+}
+
+// InterleaveSpeakers merges segments transcribed separately for each speaker
+// into a single timeline. channels[i] holds the segments for speakers[i].
+func InterleaveSpeakers(speakers []string, channels [][]Segment) []Segment {
+	var merged []Segment
+	for i, segments := range channels {
+		speaker := fmt.Sprintf("Speaker %d", i+1)
+		if i < len(speakers) && speakers[i] != "" {
+			speaker = speakers[i]
+		}
+		for _, seg := range segments {
+			seg.Speaker = speaker
+			merged = append(merged, seg)
+		}
+	}
+
+	// Stable so a speaker's own segments keep their order on equal start times
+	sort.SliceStable(merged, func(i, j int) bool {
+		return merged[i].Start < merged[j].Start
+	})
+	return merged
+}
+
+// FormatSpeakerTranscript renders segments as one labelled line per speaker turn,
+// joining consecutive segments from the same speaker
+func FormatSpeakerTranscript(segments []Segment) string {
+	var b strings.Builder
+	for i := 0; i < len(segments); {
+		turn := segments[i]
+		texts := []string{turn.Text}
+
+		j := i + 1
+		for ; j < len(segments) && segments[j].Speaker == turn.Speaker; j++ {
+			texts = append(texts, segments[j].Text)
+		}
+
+		fmt.Fprintf(&b, "[%s] %s: %s\n", formatTimestamp(turn.Start), turn.Speaker, strings.Join(texts, " "))
+		i = j
+	}
+	return b.String()
+}
+
+// formatTimestamp formats an offset into the audio as HH:MM:SS
+func formatTimestamp(d time.Duration) string {
+	seconds := int(d / time.Second)
+	return fmt.Sprintf("%02d:%02d:%02d", seconds/3600, seconds/60%60, seconds%60)
+}
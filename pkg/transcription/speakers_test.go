@@ -0,0 +1,43 @@
+package transcription
+
+import (
+	"testing"
+	"time"
+)
+
+func TestInterleaveSpeakers(t *testing.T) {
+	left := []Segment{
+		{Start: 0, End: 2 * time.Second, Text: "How did you start?"},
+		{Start: 9 * time.Second, End: 11 * time.Second, Text: "Interesting."},
+	}
+	right := []Segment{
+		{Start: 3 * time.Second, End: 5 * time.Second, Text: "By accident."},
+		{Start: 5 * time.Second, End: 8 * time.Second, Text: "I was fixing a radio."},
+	}
+
+	segments := InterleaveSpeakers([]string{"Host"}, [][]Segment{left, right})
+
+	expected := []struct{ speaker, text string }{
+		{"Host", "How did you start?"},
+		{"Speaker 2", "By accident."},
+		{"Speaker 2", "I was fixing a radio."},
+		{"Host", "Interesting."},
+	}
+	if len(segments) != len(expected) {
+		t.Fatalf("Expected %d segments, got %d", len(expected), len(segments))
+	}
+	for i, want := range expected {
+		if segments[i].Speaker != want.speaker || segments[i].Text != want.text {
+			t.Errorf("Segment %d: expected %s: %q, got %s: %q",
+				i, want.speaker, want.text, segments[i].Speaker, segments[i].Text)
+		}
+	}
+
+	got := FormatSpeakerTranscript(segments)
+	want := "[00:00:00] Host: How did you start?\n" +
+		"[00:00:03] Speaker 2: By accident. I was fixing a radio.\n" +
+		"[00:00:09] Host: Interesting.\n"
+	if got != want {
+		t.Errorf("Unexpected transcript:\n%s\nwant:\n%s", got, want)
+	}
+}
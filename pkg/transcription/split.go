@@ -0,0 +1,81 @@
+package transcription
+
+import "time"
+
+// Lengths of the chunks long recordings are split into. Whisper attends to
+// 30 seconds at a time, so longer chunks gain nothing, and much shorter ones
+// lose the context that helps it.
+const (
+	ChunkMinLength = 15 * time.Second
+	ChunkMaxLength = 30 * time.Second
+)
+
+// splitFrame is the length over which loudness is measured when looking for
+// a pause, and splitSmoothing the number of frames averaged, so a cut falls
+// in a pause rather than in the gap between two syllables
+const (
+	splitFrame     = 20 * time.Millisecond
+	splitSmoothing = 5
+)
+
+// Chunk is part of a long recording, transcribed on its own
+type Chunk struct {
+	Offset  time.Duration // Where the chunk starts in the recording
+	Samples []float32
+
+	// Done, if not nil, is called from a Queue worker with the chunk's
+	// segments, timed from the start of the recording, once it is transcribed
+	Done func(segments []Segment)
+}
+
+// SplitAtSilence splits a 16kHz recording into chunks no longer than
+// maxLength. Each cut is made at the quietest moment at least minLength into
+// the chunk, so it falls in a pause wherever there is one. The chunks share
+// the recording's samples.
+func SplitAtSilence(samples []float32, minLength, maxLength time.Duration) []Chunk {
+	frame := sampleCount(splitFrame)
+	minSamples, maxSamples := sampleCount(minLength), sampleCount(maxLength)
+
+	// Loudness of each frame, averaged with its neighbours
+	energy := make([]float64, len(samples)/frame)
+	for i := range energy {
+		for _, v := range samples[i*frame : (i+1)*frame] {
+			energy[i] += float64(v) * float64(v)
+		}
+	}
+	smoothed := make([]float64, len(energy))
+	for i := range energy {
+		for j := max(0, i-splitSmoothing/2); j <= min(len(energy)-1, i+splitSmoothing/2); j++ {
+			smoothed[i] += energy[j]
+		}
+	}
+
+	var chunks []Chunk
+	start := 0
+	for len(samples)-start > maxSamples {
+		// The quietest frame in range; the latest of equals keeps chunks long
+		first, last := (start+minSamples)/frame, (start+maxSamples)/frame-1
+		cut := last
+		for i := last; i >= first; i-- {
+			if smoothed[i] < smoothed[cut] {
+				cut = i
+			}
+		}
+		end := cut*frame + frame/2
+		if end <= start {
+			end = start + maxSamples // Lengths too short to search
+		}
+
+		chunks = append(chunks, Chunk{Offset: sampleDuration(start), Samples: samples[start:end]})
+		start = end
+	}
+	if start < len(samples) {
+		chunks = append(chunks, Chunk{Offset: sampleDuration(start), Samples: samples[start:]})
+	}
+	return chunks
+}
+
+// sampleDuration converts a number of 16kHz samples to a duration
+func sampleDuration(n int) time.Duration {
+	return time.Duration(n) * time.Second / 16000
+}
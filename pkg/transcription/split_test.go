@@ -0,0 +1,78 @@
+package transcription
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// speechWithPauses returns loud audio with a half-second pause every period
+func speechWithPauses(length, period time.Duration) ([]float32, []time.Duration) {
+	samples := make([]float32, sampleCount(length))
+	var pauses []time.Duration
+	for at := period; at < length; at += period {
+		pauses = append(pauses, at)
+	}
+	for i := range samples {
+		at := sampleDuration(i)
+		quiet := false
+		for _, pause := range pauses {
+			if at >= pause-250*time.Millisecond && at < pause+250*time.Millisecond {
+				quiet = true
+			}
+		}
+		if !quiet {
+			samples[i] = float32(0.3 * math.Sin(float64(i)/8))
+		}
+	}
+	return samples, pauses
+}
+
+func TestSplitAtSilence(t *testing.T) {
+	samples, pauses := speechWithPauses(100*time.Second, 12*time.Second)
+	chunks := SplitAtSilence(samples, ChunkMinLength, ChunkMaxLength)
+
+	if len(chunks) < 4 {
+		t.Fatalf("Expected at least 4 chunks of 100 seconds, got %d", len(chunks))
+	}
+	total := 0
+	for i, chunk := range chunks {
+		if chunk.Offset != sampleDuration(total) {
+			t.Errorf("Chunk %d: expected offset %v, got %v", i, sampleDuration(total), chunk.Offset)
+		}
+		total += len(chunk.Samples)
+
+		length := sampleDuration(len(chunk.Samples))
+		if length > ChunkMaxLength || (i < len(chunks)-1 && length < ChunkMinLength) {
+			t.Errorf("Chunk %d: expected %v to %v, got %v", i, ChunkMinLength, ChunkMaxLength, length)
+		}
+
+		// Every cut falls in a pause
+		if i == 0 {
+			continue
+		}
+		inPause := false
+		for _, pause := range pauses {
+			if (chunk.Offset - pause).Abs() < 250*time.Millisecond {
+				inPause = true
+			}
+		}
+		if !inPause {
+			t.Errorf("Chunk %d: expected a cut in a pause, got one at %v", i, chunk.Offset)
+		}
+	}
+	if total != len(samples) {
+		t.Errorf("Expected chunks covering %d samples, got %d", len(samples), total)
+	}
+
+	// Without pauses, chunks are cut at the longest length
+	chunks = SplitAtSilence(make([]float32, sampleCount(70*time.Second)), ChunkMinLength, ChunkMaxLength)
+	if len(chunks) != 3 || chunks[1].Offset < ChunkMaxLength-time.Second {
+		t.Errorf("Expected 3 chunks of about %v, got %d", ChunkMaxLength, len(chunks))
+	}
+
+	// Short recordings are left whole
+	if chunks := SplitAtSilence(samples[:sampleCount(10*time.Second)], ChunkMinLength, ChunkMaxLength); len(chunks) != 1 {
+		t.Errorf("Expected a short recording in one chunk, got %d", len(chunks))
+	}
+}
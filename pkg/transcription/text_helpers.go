@@ -3,10 +3,62 @@ package transcription
 import (
 	"regexp"
 	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/jeff-barlow-spady/ramble/pkg/textproc"
+)
+
+var (
+	asteriskPattern = regexp.MustCompile(`\*[^*]+\*`)
+	parenPattern    = regexp.MustCompile(`\([^)]*(?i)(music|noise|applause|laughter|sighs|sigh)[^)]*\)`)
+	bracketPattern  = regexp.MustCompile(`\[(?i)(?:MUSIC|APPLAUSE|LAUGHTER|INAUDIBLE|NOISE|CROSSTALK|SILENCE|SPEAKING FOREIGN LANGUAGE|SPEAKING NON-ENGLISH|SIGH|SIGHS)\]`)
+	sentenceEnd     = regexp.MustCompile(`[.!?…。！？]\s+`)
 )
 
+// VocabularyPrompt builds a whisper initial prompt from vocabulary words.
+// Whisper tends to reuse spellings it has seen in the prompt.
+func VocabularyPrompt(words []string) string {
+	var kept []string
+	for _, word := range words {
+		if word = strings.TrimSpace(word); word != "" {
+			kept = append(kept, word)
+		}
+	}
+	if len(kept) == 0 {
+		return ""
+	}
+	return "Glossary: " + strings.Join(kept, ", ") + "."
+}
+
 // NormalizeTranscriptionText cleans up transcription text for better quality
 func NormalizeTranscriptionText(text string) string {
+	return NormalizeTranscriptionTextIn(text, "")
+}
+
+// NormalizeTranscriptionTextIn cleans up text transcribed in language, a
+// language code, capitalizing it by that language's rules. An empty or
+// unknown language, or LanguageAuto, uses the default casing.
+func NormalizeTranscriptionTextIn(text, language string) string {
+	return capitalize(normalize(text), language)
+}
+
+// NormalizeLiveText cleans up the text of a live window like
+// NormalizeTranscriptionTextIn, but only capitalizes it where it starts the
+// segment or a sentence after previous, the segment's text so far. A window
+// continuing a sentence keeps whisper's casing, so textproc.Join doesn't take
+// it for a new sentence.
+func NormalizeLiveText(text, previous, language string) string {
+	text = normalize(text)
+	if !textproc.EndsSentence(previous) {
+		return text
+	}
+	return capitalize(text, language)
+}
+
+// normalize removes noise markers and repeated fillers from text and tidies
+// its spacing and punctuation
+func normalize(text string) string {
 	if text == "" {
 		return ""
 	}
@@ -36,37 +88,53 @@ func NormalizeTranscriptionText(text string) string {
 	text = strings.TrimSpace(text)
 
 	// Remove sound effects in asterisks (like *Boom* *Boom*)
-	asteriskPattern := regexp.MustCompile(`\*[^*]+\*`)
 	text = asteriskPattern.ReplaceAllString(text, "")
 
 	// Remove parenthetical content (noise markers and hesitations)
-	parenPattern := regexp.MustCompile(`\([^)]*(?i)(music|noise|applause|laughter|sighs|sigh)[^)]*\)`)
 	text = parenPattern.ReplaceAllString(text, "")
 
 	// Remove bracketed noise markers - expanded pattern to include more token types
-	bracketPattern := regexp.MustCompile(`\[(?i)(?:MUSIC|APPLAUSE|LAUGHTER|INAUDIBLE|NOISE|CROSSTALK|SILENCE|SPEAKING FOREIGN LANGUAGE|SPEAKING NON-ENGLISH|SIGH|SIGHS)\]`)
 	text = bracketPattern.ReplaceAllString(text, "")
 
 	// Remove repeated short phrases that commonly occur in real-time transcription
 	// (words like "Hmm", "Uhh", etc. or repeated correction attempts)
 	text = cleanRepeatedPhrases(text)
 
-	// Normalize spaces
-	spacePattern := regexp.MustCompile(`\s+`)
-	text = spacePattern.ReplaceAllString(text, " ")
+	// Normalize spaces, including non-breaking and ideographic ones
+	text = strings.Join(strings.Fields(text), " ")
 
 	// Fix punctuation
 	text = strings.ReplaceAll(text, " .", ".")
 	text = strings.ReplaceAll(text, " ,", ",")
 	text = strings.ReplaceAll(text, " ?", "?")
 	text = strings.ReplaceAll(text, " !", "!")
+	return text
+}
 
-	// Capitalize first letter
-	if len(text) > 0 {
-		text = strings.ToUpper(text[:1]) + text[1:]
+// capitalize upper-cases the first letter of text by the casing rules of
+// language, after any opening punctuation such as "¿". Text starting with a
+// digit or a letter without case, as in Chinese, is returned as is.
+func capitalize(text, language string) string {
+	for i, r := range text {
+		switch {
+		case unicode.IsLetter(r):
+			upper := casing(language).ToTitle(r)
+			return text[:i] + string(upper) + text[i+utf8.RuneLen(r):]
+		case !unicode.IsPunct(r) && !unicode.IsSymbol(r):
+			return text
+		}
 	}
+	return text
+}
 
-	return strings.TrimSpace(text)
+// casing returns the casing rules of a language, e.g. the dotted capital İ of
+// Turkish. Languages without special rules get nil, the Unicode defaults.
+func casing(language string) unicode.SpecialCase {
+	switch language {
+	case "tr", "az":
+		return unicode.TurkishCase
+	}
+	return nil
 }
 
 // cleanRepeatedPhrases removes common repetitive patterns in real-time transcription
@@ -81,8 +149,14 @@ func cleanRepeatedPhrases(text string) string {
 	}
 
 	// Look for repeated short phrases (3-5 words) that are characteristic of transcription corrections
-	// Split into sentences or fragments
-	fragments := strings.Split(text, ".")
+	// Split into sentences, keeping their punctuation and numbers like 3.5 whole
+	var fragments []string
+	start := 0
+	for _, end := range sentenceEnd.FindAllStringIndex(text, -1) {
+		fragments = append(fragments, text[start:end[1]])
+		start = end[1]
+	}
+	fragments = append(fragments, text[start:])
 	var cleanedFragments []string
 
 	for _, fragment := range fragments {
@@ -91,7 +165,9 @@ func cleanRepeatedPhrases(text string) string {
 			continue
 		}
 
-		words := strings.Fields(fragment)
+		// Compare the words without the punctuation ending the sentence
+		body := strings.TrimRightFunc(fragment, unicode.IsPunct)
+		words := strings.Fields(body)
 		if len(words) < 6 { // Skip very short fragments
 			cleanedFragments = append(cleanedFragments, fragment)
 			continue
@@ -129,8 +205,8 @@ func cleanRepeatedPhrases(text string) string {
 			}
 		}
 
-		cleanedFragments = append(cleanedFragments, strings.Join(cleanedText, " "))
+		cleanedFragments = append(cleanedFragments, strings.Join(cleanedText, " ")+fragment[len(body):])
 	}
 
-	return strings.Join(cleanedFragments, ". ")
+	return strings.Join(cleanedFragments, " ")
 }
@@ -0,0 +1,57 @@
+package transcription
+
+import (
+	"testing"
+
+	"github.com/jeff-barlow-spady/ramble/pkg/textproc"
+)
+
+func TestVocabularyPrompt(t *testing.T) {
+	if got := VocabularyPrompt(nil); got != "" {
+		t.Errorf("Expected no prompt without vocabulary, got %q", got)
+	}
+	if got := VocabularyPrompt([]string{" Ramble ", "", "whisper.cpp"}); got != "Glossary: Ramble, whisper.cpp." {
+		t.Errorf("Unexpected prompt %q", got)
+	}
+}
+
+func TestNormalizeTranscriptionText(t *testing.T) {
+	tests := []struct {
+		input    string
+		language string
+		want     string
+	}{
+		{"", "", ""},
+		{"  hello   world ", "", "Hello world"},
+		{"It ends here.", "", "It ends here."},
+		{"pi is 3.14 , roughly", "", "Pi is 3.14, roughly"},
+		{"élan vital", "fr", "Élan vital"},
+		{"über alles", "", "Über alles"},
+		{"¿qué tal?", "es", "¿Qué tal?"},
+		{"日本語です。", "ja", "日本語です。"},
+		{"привет  мир", "ru", "Привет мир"},
+		{"istanbul", "tr", "İstanbul"},
+		{"istanbul", "", "Istanbul"},
+		{"ǆungla", "", "ǅungla"},
+		{"3 apples", "", "3 apples"},
+		{"[MUSIC]", "", ""},
+		{"we went home we went home. Then we slept.", "", "We went home. Then we slept."},
+	}
+	for _, tt := range tests {
+		if got := NormalizeTranscriptionTextIn(tt.input, tt.language); got != tt.want {
+			t.Errorf("NormalizeTranscriptionTextIn(%q, %q) = %q, want %q", tt.input, tt.language, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeLiveText(t *testing.T) {
+	// Only the windows starting the segment or a sentence are capitalized, so
+	// joining windows doesn't end a sentence whisper left open
+	var text string
+	for _, window := range []string{"and then we", " went home.", "istanbul was", "lovely"} {
+		text = textproc.Join(text, NormalizeLiveText(window, text, "tr"))
+	}
+	if want := "And then we went home. İstanbul was lovely"; text != want {
+		t.Errorf("Expected %q, got %q", want, text)
+	}
+}
@@ -0,0 +1,60 @@
+package transcription
+
+import "time"
+
+// Transcriber turns speech into text, both live while recording and for
+// complete recordings. WhisperTranscriber, created by NewManager, is the
+// implementation; callers should depend on this interface instead.
+type Transcriber interface {
+	// SetStreamingCallback sets the function called with text transcribed while recording
+	SetStreamingCallback(callback func(string))
+	// SetWordCallback sets the function called with the timed words of that text
+	SetWordCallback(callback func([]Word))
+	// SetRecordingState starts or stops live transcription
+	SetRecordingState(isRecording bool)
+	// Drain stops live transcription after transcribing the audio already
+	// added, sending its text to the callbacks, for at most timeout
+	Drain(timeout time.Duration) error
+	// AppendAudio adds 16kHz audio to the live transcription without starting a pass
+	AppendAudio(audioData []float32)
+	// ProcessAudioChunk adds 16kHz audio to the live transcription and starts a pass if due
+	ProcessAudioChunk(audioData []float32) (string, error)
+	// SetPassCallback sets the function told how long each live pass took
+	SetPassCallback(callback func(window, elapsed time.Duration))
+	// IsBusy reports whether the previous window is still being transcribed
+	IsBusy() bool
+	// BufferedSamples reports how much live audio is held between passes
+	BufferedSamples() int
+	// EndUtterance starts the next live window afresh after a pause
+	EndUtterance()
+	// SetTuning changes how often and how much audio is transcribed live
+	SetTuning(tuning Tuning)
+	// SetSuppressor sets how hallucinated live text is dropped; nil turns it off
+	SetSuppressor(suppressor *Suppressor)
+	// SetVocabulary primes recognition with words and names
+	SetVocabulary(words []string)
+	// SetKeepContext sets whether each live window is prompted with the end
+	// of the text transcribed before it
+	SetKeepContext(keep bool)
+	// SetLanguage sets the language transcribed, or LanguageAuto to detect it
+	SetLanguage(language string)
+	// SetLanguageCallback sets the function called with the language detected
+	// for text sent to the streaming callback, when detecting it
+	SetLanguageCallback(callback func(language string))
+
+	// TranscribeSamples transcribes a complete 16kHz recording
+	TranscribeSamples(samples []float32) ([]Segment, error)
+	// TranscribeSamplesWithProgress is TranscribeSamples reporting percentage done
+	TranscribeSamplesWithProgress(samples []float32, progress func(percent int)) ([]Segment, error)
+	// TranscribeChannels transcribes each channel as its own speaker
+	TranscribeChannels(channels [][]float32, speakers []string) ([]Segment, error)
+
+	// IsLoaded reports whether the model is in memory
+	IsLoaded() bool
+	// Load reloads the model after Unload
+	Load() error
+	// Unload frees the model while idle
+	Unload() error
+	// Close releases all resources
+	Close() error
+}
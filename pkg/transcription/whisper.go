@@ -4,6 +4,7 @@ package transcription
 import (
 	"os"
 	"path/filepath"
+	"strings"
 )
 
 // ModelSize options for Whisper
@@ -15,42 +16,98 @@ const (
 	ModelSmall  ModelSize = "small"
 	ModelMedium ModelSize = "medium"
 	ModelLarge  ModelSize = "large"
+
+	// ModelAuto picks the largest installed model that fits in available
+	// memory; see AutoModel
+	ModelAuto ModelSize = "auto"
 )
 
-// GetLocalModelPath returns the path to a model file either in the system location
-// or in the user's home directory
-func GetLocalModelPath(modelSize ModelSize) string {
-	if modelSize == "" {
-		modelSize = ModelTiny
-	}
+// ModelSizes lists every model size, smallest first
+var ModelSizes = []ModelSize{ModelTiny, ModelBase, ModelSmall, ModelMedium, ModelLarge}
 
-	// Look in standard locations
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return ""
+// Quantized returns the variant of a model size with quantized weights, e.g.
+// "small-q5_1" for the q5_1 variant of small. Quantized models are smaller
+// and faster at a small cost in accuracy.
+func Quantized(modelSize ModelSize, quantization string) ModelSize {
+	if quantization == "" {
+		return modelSize
 	}
+	return modelSize + ModelSize("-"+quantization)
+}
+
+// Base returns the model size without its quantization
+func (m ModelSize) Base() ModelSize {
+	base, _, _ := strings.Cut(string(m), "-")
+	return ModelSize(base)
+}
+
+// Quantization returns the quantization of a model, e.g. "q5_1", or "" for
+// full precision
+func (m ModelSize) Quantization() string {
+	_, quantization, _ := strings.Cut(string(m), "-")
+	return quantization
+}
 
-	// Standard file naming pattern: ggml-{model}.bin
-	modelFileName := "ggml-" + string(modelSize) + ".en.bin"
+// ModelFileName returns the file name a model is stored under, following the
+// ggml-{model}.en.bin naming pattern, or ggml-{model}.en-{quantization}.bin
+// for quantized models
+func ModelFileName(modelSize ModelSize) string {
+	if quantization := modelSize.Quantization(); quantization != "" {
+		return "ggml-" + string(modelSize.Base()) + ".en-" + quantization + ".bin"
+	}
+	return "ggml-" + string(modelSize) + ".en.bin"
+}
 
-	// Locations to check (in order of preference)
-	locations := []string{
+// ModelDirs returns the directories searched for models, in order of
+// preference
+func ModelDirs() []string {
+	dirs := []string{
 		// Current directory
-		filepath.Join(".", modelFileName),
+		".",
 		// Models directory in current directory
-		filepath.Join(".", "models", modelFileName),
+		filepath.Join(".", "models"),
+	}
+	if dir := UserModelDir(); dir != "" {
 		// User's home directory models
-		filepath.Join(homeDir, ".local", "share", "ramble", "models", modelFileName),
-		// Global models directory
-		filepath.Join("/usr", "local", "share", "ramble", "models", modelFileName),
+		dirs = append(dirs, dir)
+	}
+	// Global models directory
+	return append(dirs, filepath.Join("/usr", "local", "share", "ramble", "models"))
+}
+
+// UserModelDir returns the models directory in the user's home directory,
+// where downloaded models are stored
+func UserModelDir() string {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(homeDir, ".local", "share", "ramble", "models")
+}
+
+// GetLocalModelPath returns the path to a model file either in the system location
+// or in the user's home directory. ModelAuto finds the model AutoModel picks.
+// The tiny model falls back to the one embedded in the binary, if any.
+func GetLocalModelPath(modelSize ModelSize) string {
+	if modelSize == "" {
+		modelSize = ModelTiny
+	}
+	if modelSize == ModelAuto {
+		if modelSize = AutoModel(); modelSize == "" {
+			modelSize = ModelTiny
+		}
 	}
 
 	// Check each location
-	for _, location := range locations {
+	for _, dir := range ModelDirs() {
+		location := filepath.Join(dir, ModelFileName(modelSize))
 		if _, err := os.Stat(location); err == nil {
 			return location
 		}
 	}
 
+	if modelSize == ModelTiny {
+		return extractEmbeddedModel()
+	}
 	return ""
 }
@@ -0,0 +1,61 @@
+package transcription
+
+// liveBuffer holds the audio of a live recording between transcription
+// passes. It remembers how much of it has been transcribed so a backlog
+// larger than one window is worked through in order instead of skipped.
+type liveBuffer struct {
+	samples []float32
+	end     int // Samples before end have been included in a pass
+}
+
+// append adds new audio to the buffer
+func (b *liveBuffer) append(audioData []float32) {
+	b.samples = append(b.samples, audioData...)
+}
+
+// length returns the number of buffered samples
+func (b *liveBuffer) length() int {
+	return len(b.samples)
+}
+
+// pending returns the number of samples no pass has included yet
+func (b *liveBuffer) pending() int {
+	return len(b.samples) - b.end
+}
+
+// next copies the window for the next pass and marks its audio as
+// transcribed. The window is the newest maxWindow samples unless more than
+// that is pending, in which case it ends maxWindow samples into the pending
+// audio so the rest is left for following passes.
+func (b *liveBuffer) next(maxWindow int) []float32 {
+	end := len(b.samples)
+	if b.pending() > maxWindow {
+		end = b.end + maxWindow
+	}
+	start := end - maxWindow
+	if start < 0 {
+		start = 0
+	}
+
+	window := make([]float32, end-start)
+	copy(window, b.samples[start:end])
+	b.end = end
+	return window
+}
+
+// trim drops transcribed audio, keeping contextSamples of it before the
+// pending audio as context for the next pass
+func (b *liveBuffer) trim(contextSamples int) {
+	drop := b.end - contextSamples
+	if drop <= 0 {
+		return
+	}
+	b.samples = append(b.samples[:0], b.samples[drop:]...)
+	b.end -= drop
+}
+
+// reset empties the buffer, keeping its capacity
+func (b *liveBuffer) reset() {
+	b.samples = b.samples[:0]
+	b.end = 0
+}
@@ -0,0 +1,75 @@
+package transcription
+
+import "testing"
+
+// TestLiveBufferBacklog tests that a backlog longer than the window and the
+// kept context is transcribed in full, in order
+func TestLiveBufferBacklog(t *testing.T) {
+	const maxWindow, contextSamples = 100, 150
+
+	var b liveBuffer
+	backlog := make([]float32, 1000)
+	for i := range backlog {
+		backlog[i] = float32(i)
+	}
+	b.append(backlog)
+
+	// Track the newest sample each pass saw; together the passes must
+	// cover every sample of the backlog without skipping any
+	covered := -1
+	for passes := 0; b.pending() > 0; passes++ {
+		if passes > 100 {
+			t.Fatal("Backlog was never worked through")
+		}
+		window := b.next(maxWindow)
+		if len(window) == 0 || len(window) > maxWindow {
+			t.Fatalf("Expected a window of up to %d samples, got %d", maxWindow, len(window))
+		}
+		if first := int(window[0]); first > covered+1 {
+			t.Fatalf("Samples %d to %d were never transcribed", covered+1, first-1)
+		}
+		covered = int(window[len(window)-1])
+		b.trim(contextSamples)
+
+		// New audio arriving during the pass waits for a later one
+		if passes == 2 {
+			b.append([]float32{1000, 1001})
+		}
+	}
+
+	if covered != 1001 {
+		t.Errorf("Expected every sample to be transcribed, last was %d", covered)
+	}
+	if b.length() > contextSamples {
+		t.Errorf("Expected at most %d samples of context to be kept, got %d", contextSamples, b.length())
+	}
+}
+
+// TestLiveBufferSlidingWindow tests that without a backlog each pass sees the
+// newest audio with the kept context before it
+func TestLiveBufferSlidingWindow(t *testing.T) {
+	var b liveBuffer
+	for i := 0; i < 5; i++ {
+		chunk := make([]float32, 40)
+		for j := range chunk {
+			chunk[j] = float32(i*40 + j)
+		}
+		b.append(chunk)
+
+		window := b.next(100)
+		if last := int(window[len(window)-1]); last != i*40+39 {
+			t.Errorf("Pass %d: expected the window to end with the newest sample, got %d", i, last)
+		}
+		if i > 1 && len(window) != 100 {
+			t.Errorf("Pass %d: expected a full window including context, got %d samples", i, len(window))
+		}
+		b.trim(150)
+	}
+
+	// After a pause only untranscribed audio is kept
+	b.append([]float32{200})
+	b.trim(0)
+	if b.length() != 1 || b.pending() != 1 {
+		t.Errorf("Expected only the pending sample to be kept, got %d samples", b.length())
+	}
+}
@@ -0,0 +1,75 @@
+package transcription
+
+import (
+	"strings"
+	"time"
+)
+
+// Token is a piece of text as whisper emits it, often part of a word, with
+// its timing in the audio that was transcribed
+type Token struct {
+	Text       string
+	Start, End time.Duration
+}
+
+// Word is a transcribed word with its timing in the audio that was transcribed
+type Word struct {
+	Text       string
+	Start, End time.Duration
+}
+
+// JoinTokens merges whisper's tokens into words. A token starting with a
+// space begins a new word; others, including punctuation, continue the
+// previous one. Special tokens such as [_BEG_] are dropped.
+func JoinTokens(tokens []Token) []Word {
+	var words []Word
+	for _, token := range tokens {
+		if isSpecialToken(token.Text) {
+			continue
+		}
+		text := strings.TrimSpace(token.Text)
+		if text == "" {
+			continue
+		}
+
+		if len(words) == 0 || strings.HasPrefix(token.Text, " ") {
+			words = append(words, Word{Text: text, Start: token.Start, End: token.End})
+			continue
+		}
+		last := &words[len(words)-1]
+		last.Text += text
+		last.End = token.End
+	}
+	return words
+}
+
+// isSpecialToken reports whether a token is a control token like [_TT_50]
+// rather than text
+func isSpecialToken(text string) bool {
+	return strings.HasPrefix(text, "[_") && strings.HasSuffix(text, "]")
+}
+
+// RetimeWords times the words of text, a rewritten version of words such as
+// after corrections or redaction. Words keep their timing when the count is
+// unchanged; otherwise text is spread evenly over the time words took.
+func RetimeWords(words []Word, text string) []Word {
+	fields := strings.Fields(text)
+	if len(fields) == 0 || len(words) == 0 {
+		return nil
+	}
+
+	retimed := make([]Word, len(fields))
+	if len(fields) == len(words) {
+		for i, field := range fields {
+			retimed[i] = Word{Text: field, Start: words[i].Start, End: words[i].End}
+		}
+		return retimed
+	}
+
+	start, end := words[0].Start, words[len(words)-1].End
+	step := (end - start) / time.Duration(len(fields))
+	for i, field := range fields {
+		retimed[i] = Word{Text: field, Start: start + time.Duration(i)*step, End: start + time.Duration(i+1)*step}
+	}
+	return retimed
+}
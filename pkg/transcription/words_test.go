@@ -0,0 +1,62 @@
+package transcription
+
+import (
+	"testing"
+	"time"
+)
+
+func TestJoinTokens(t *testing.T) {
+	ms := func(n int) time.Duration { return time.Duration(n) * time.Millisecond }
+	tokens := []Token{
+		{Text: "[_BEG_]", Start: 0, End: 0},
+		{Text: " Hel", Start: ms(0), End: ms(200)},
+		{Text: "lo", Start: ms(200), End: ms(350)},
+		{Text: ",", Start: ms(350), End: ms(360)},
+		{Text: " world", Start: ms(400), End: ms(800)},
+		{Text: ".", Start: ms(800), End: ms(810)},
+		{Text: "[_TT_41]", Start: ms(810), End: ms(810)},
+	}
+
+	words := JoinTokens(tokens)
+	expected := []Word{
+		{Text: "Hello,", Start: ms(0), End: ms(360)},
+		{Text: "world.", Start: ms(400), End: ms(810)},
+	}
+	if len(words) != len(expected) {
+		t.Fatalf("Expected %d words, got %d: %v", len(expected), len(words), words)
+	}
+	for i, want := range expected {
+		if words[i] != want {
+			t.Errorf("Word %d: expected %+v, got %+v", i, want, words[i])
+		}
+	}
+}
+
+func TestJoinTokensLeadingFragment(t *testing.T) {
+	// Segments after the first often start without a space
+	words := JoinTokens([]Token{{Text: "And"}, {Text: " so"}})
+	if len(words) != 2 || words[0].Text != "And" || words[1].Text != "so" {
+		t.Errorf("Expected [And so], got %v", words)
+	}
+}
+
+func TestRetimeWords(t *testing.T) {
+	words := []Word{
+		{Text: "call", Start: 0, End: time.Second},
+		{Text: "555-1234", Start: time.Second, End: 3 * time.Second},
+	}
+
+	same := RetimeWords(words, "call [PHONE]")
+	if len(same) != 2 || same[1] != (Word{Text: "[PHONE]", Start: time.Second, End: 3 * time.Second}) {
+		t.Errorf("Expected redacted words to keep their timing, got %v", same)
+	}
+
+	spread := RetimeWords(words, "please call [PHONE]")
+	if len(spread) != 3 || spread[1].Start != time.Second || spread[2].End != 3*time.Second {
+		t.Errorf("Expected three words spread over 3s, got %v", spread)
+	}
+
+	if RetimeWords(nil, "text") != nil {
+		t.Error("Expected no words without timing")
+	}
+}
@@ -0,0 +1,191 @@
+// Package tts reads text aloud with a speech synthesizer installed on the
+// system, so dictation can be checked by ear
+package tts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/jeff-barlow-spady/ramble/pkg/tempfiles"
+)
+
+// Engines New accepts
+const (
+	EngineAuto   = ""          // The first engine installed, in the order of Engines
+	EngineEspeak = "espeak-ng" // eSpeak NG, common on Linux
+	EnginePiper  = "piper"     // Piper neural voices, played with paplay or aplay
+	EngineSAPI   = "sapi"      // Windows speech through PowerShell
+	EngineSay    = "say"       // The macOS say command
+)
+
+// Engine speaks text
+type Engine interface {
+	// Name identifies the engine in the config, e.g. EngineEspeak
+	Name() string
+	// Speak reads text aloud, returning once it was spoken. Cancelling ctx
+	// stops it.
+	Speak(ctx context.Context, text string) error
+}
+
+// EngineInfo describes an engine for Preferences
+type EngineInfo struct {
+	Name  string
+	Label string
+	OS    string // Only available on this GOOS, if set
+}
+
+// Engines lists the engines in the order EngineAuto tries them
+var Engines = []EngineInfo{
+	{Name: EngineSAPI, Label: "Windows speech", OS: "windows"},
+	{Name: EngineSay, Label: "macOS say", OS: "darwin"},
+	{Name: EnginePiper, Label: "Piper"},
+	{Name: EngineEspeak, Label: "eSpeak NG"},
+}
+
+// ErrNoEngine is returned by New when no engine is installed
+var ErrNoEngine = errors.New("no speech synthesizer found; install espeak-ng or piper")
+
+// sapiScript speaks standard input with the Windows speech synthesizer, in
+// the voice named by RAMBLE_TTS_VOICE if set
+const sapiScript = `Add-Type -AssemblyName System.Speech
+$s = New-Object System.Speech.Synthesis.SpeechSynthesizer
+if ($env:RAMBLE_TTS_VOICE) { $s.SelectVoice($env:RAMBLE_TTS_VOICE) }
+$s.Speak([Console]::In.ReadToEnd())`
+
+// New returns the named engine speaking with voice, or the first installed
+// engine for EngineAuto. The voice is engine specific: a voice name for
+// espeak-ng, SAPI and say, and the path of a voice model for piper. An empty
+// voice is the engine's default, except for piper, which needs one.
+func New(name, voice string) (Engine, error) {
+	return newEngine(name, voice, runtime.GOOS, exec.LookPath)
+}
+
+// newEngine is New for the given GOOS, finding commands with lookPath
+func newEngine(name, voice, goos string, lookPath func(string) (string, error)) (Engine, error) {
+	if name != EngineAuto {
+		return commandFor(name, voice, goos, lookPath)
+	}
+	for _, info := range Engines {
+		if info.OS != "" && info.OS != goos {
+			continue
+		}
+		if engine, err := commandFor(info.Name, voice, goos, lookPath); err == nil {
+			return engine, nil
+		}
+	}
+	return nil, ErrNoEngine
+}
+
+// commandFor returns the engine with the given name if its commands are
+// installed
+func commandFor(name, voice, goos string, lookPath func(string) (string, error)) (*commandEngine, error) {
+	var e *commandEngine
+	switch name {
+	case EngineEspeak:
+		e = &commandEngine{name: name, command: []string{"espeak-ng", "--stdin"}}
+		if voice != "" {
+			e.command = append(e.command, "-v", voice)
+		}
+	case EnginePiper:
+		if voice == "" {
+			return nil, errors.New("piper needs a voice model; set its path as the voice")
+		}
+		e = &commandEngine{name: name, command: []string{"piper", "--model", voice, "--output_file"}}
+		for _, player := range [][]string{{"paplay"}, {"aplay", "-q"}} {
+			if _, err := lookPath(player[0]); err == nil {
+				e.player = player
+				break
+			}
+		}
+		if e.player == nil {
+			return nil, errors.New("piper needs paplay or aplay to play its audio")
+		}
+	case EngineSAPI:
+		if goos != "windows" {
+			return nil, errors.New("the Windows speech synthesizer is only available on Windows")
+		}
+		e = &commandEngine{name: name, command: []string{"powershell", "-NoProfile", "-NonInteractive", "-Command", sapiScript}}
+		if voice != "" {
+			e.env = []string{"RAMBLE_TTS_VOICE=" + voice}
+		}
+	case EngineSay:
+		e = &commandEngine{name: name, command: []string{"say", "-f", "-"}}
+		if voice != "" {
+			e.command = append(e.command, "-v", voice)
+		}
+	default:
+		return nil, fmt.Errorf("unknown speech synthesizer %q", name)
+	}
+
+	if _, err := lookPath(e.command[0]); err != nil {
+		return nil, fmt.Errorf("%s not found: %w", e.command[0], err)
+	}
+	return e, nil
+}
+
+// commandEngine speaks by running a command with the text on its standard
+// input. Engines that write audio to a file instead of playing it have the
+// file name appended to their command and play it with player.
+type commandEngine struct {
+	name    string
+	command []string
+	env     []string // Added to the environment of the command
+	player  []string // Plays the file written by the command; nil if it plays the audio itself
+}
+
+// Name implements Engine
+func (e *commandEngine) Name() string { return e.name }
+
+// Speak implements Engine
+func (e *commandEngine) Speak(ctx context.Context, text string) error {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil
+	}
+
+	command := e.command
+	var file string
+	if e.player != nil {
+		f, err := tempfiles.Create("tts-*.wav")
+		if err != nil {
+			return fmt.Errorf("failed to create speech file: %w", err)
+		}
+		f.Close()
+		file = f.Name()
+		defer os.Remove(file)
+		command = append(command[:len(command):len(command)], file)
+	}
+
+	cmd := exec.CommandContext(ctx, command[0], command[1:]...)
+	cmd.Stdin = strings.NewReader(text)
+	if e.env != nil {
+		cmd.Env = append(os.Environ(), e.env...)
+	}
+	if err := run(ctx, cmd, e.name); err != nil || e.player == nil {
+		return err
+	}
+
+	play := exec.CommandContext(ctx, e.player[0], append(e.player[1:len(e.player):len(e.player)], file)...)
+	return run(ctx, play, e.player[0])
+}
+
+// run runs cmd, describing its failure with its output. Being stopped by
+// ctx is not a failure.
+func run(ctx context.Context, cmd *exec.Cmd, name string) error {
+	out, err := cmd.CombinedOutput()
+	if ctx.Err() != nil {
+		return nil
+	}
+	if err != nil {
+		if detail := strings.TrimSpace(string(out)); detail != "" {
+			return fmt.Errorf("%s failed: %w: %s", name, err, detail)
+		}
+		return fmt.Errorf("%s failed: %w", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package tts
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// installed returns a lookPath finding only the given commands
+func installed(commands ...string) func(string) (string, error) {
+	return func(command string) (string, error) {
+		for _, c := range commands {
+			if c == command {
+				return "/usr/bin/" + command, nil
+			}
+		}
+		return "", errors.New("not found")
+	}
+}
+
+// TestNewAuto tests that the first engine installed for the OS is chosen
+func TestNewAuto(t *testing.T) {
+	tests := []struct {
+		goos      string
+		installed []string
+		voice     string
+		want      string
+	}{
+		{"linux", []string{"espeak-ng"}, "", EngineEspeak},
+		// Piper needs a voice model and a player
+		{"linux", []string{"espeak-ng", "piper", "aplay"}, "", EngineEspeak},
+		{"linux", []string{"espeak-ng", "piper"}, "/voices/en.onnx", EngineEspeak},
+		{"linux", []string{"espeak-ng", "piper", "aplay"}, "/voices/en.onnx", EnginePiper},
+		{"windows", []string{"powershell", "espeak-ng"}, "", EngineSAPI},
+		{"darwin", []string{"say", "powershell"}, "", EngineSay},
+		// PowerShell on Linux has no Windows speech
+		{"linux", []string{"powershell"}, "", ""},
+	}
+	for _, tt := range tests {
+		engine, err := newEngine(EngineAuto, tt.voice, tt.goos, installed(tt.installed...))
+		if tt.want == "" {
+			if err != ErrNoEngine {
+				t.Errorf("%s with %v: expected ErrNoEngine, got %v", tt.goos, tt.installed, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s with %v: unexpected error: %v", tt.goos, tt.installed, err)
+			continue
+		}
+		if engine.Name() != tt.want {
+			t.Errorf("%s with %v: expected %s, got %s", tt.goos, tt.installed, tt.want, engine.Name())
+		}
+	}
+}
+
+// TestEngineCommands tests the commands run for each engine and voice
+func TestEngineCommands(t *testing.T) {
+	lookPath := installed("espeak-ng", "piper", "paplay", "aplay", "powershell", "say")
+
+	e, err := commandFor(EngineEspeak, "de", "linux", lookPath)
+	if err != nil || !reflect.DeepEqual(e.command, []string{"espeak-ng", "--stdin", "-v", "de"}) {
+		t.Errorf("Unexpected espeak-ng command %v (%v)", e.command, err)
+	}
+
+	e, err = commandFor(EnginePiper, "/voices/en.onnx", "linux", lookPath)
+	if err != nil || !reflect.DeepEqual(e.command, []string{"piper", "--model", "/voices/en.onnx", "--output_file"}) ||
+		!reflect.DeepEqual(e.player, []string{"paplay"}) {
+		t.Errorf("Unexpected piper command %v playing with %v (%v)", e.command, e.player, err)
+	}
+
+	e, err = commandFor(EngineSAPI, "Microsoft Zira Desktop", "windows", lookPath)
+	if err != nil || e.command[0] != "powershell" || !reflect.DeepEqual(e.env, []string{"RAMBLE_TTS_VOICE=Microsoft Zira Desktop"}) {
+		t.Errorf("Unexpected SAPI command %v with %v (%v)", e.command, e.env, err)
+	}
+
+	if _, err := commandFor("festival", "", "linux", lookPath); err == nil {
+		t.Error("Expected an unknown engine to be rejected")
+	}
+	if _, err := commandFor(EngineEspeak, "", "linux", installed()); err == nil {
+		t.Error("Expected a missing command to be rejected")
+	}
+}
+
+// TestSpeakEmpty tests that nothing is run for empty text
+func TestSpeakEmpty(t *testing.T) {
+	e := &commandEngine{name: "missing", command: []string{"/nonexistent/tts"}}
+	if err := e.Speak(context.Background(), "  \n"); err != nil {
+		t.Errorf("Expected nothing to be spoken, got %v", err)
+	}
+	if err := e.Speak(context.Background(), "hello"); err == nil {
+		t.Error("Expected an error from a missing command")
+	}
+}
@@ -0,0 +1,165 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/jeff-barlow-spady/ramble/pkg/analytics"
+	"github.com/jeff-barlow-spady/ramble/pkg/i18n"
+	"github.com/jeff-barlow-spady/ramble/pkg/logger"
+)
+
+// recentDays is how far back the dashboard summary looks
+const recentDays = 30
+
+// SetAnalytics sets the tracker that counts feature use and is shown in the
+// usage statistics dashboard
+func (a *App) SetAnalytics(tracker *analytics.Tracker) {
+	a.analytics = tracker
+}
+
+// recordFeature counts a use of a feature if usage statistics are enabled
+func (a *App) recordFeature(feature string) {
+	if a.analytics == nil {
+		return
+	}
+	if err := a.analytics.RecordFeature(feature); err != nil {
+		logger.Warning(logger.CategoryUI, "Failed to record usage statistics: %v", err)
+	}
+}
+
+// showAnalyticsDashboard shows the usage statistics recorded on this computer
+func (a *App) showAnalyticsDashboard() {
+	if a.analytics == nil {
+		dialog.ShowError(errors.New(i18n.T("Usage statistics are unavailable")), a.mainWindow)
+		return
+	}
+
+	days := a.analytics.Days()
+	recent := analytics.Summarize(analytics.Since(days, time.Now().AddDate(0, 0, -recentDays+1)))
+	all := analytics.Summarize(days)
+
+	note := i18n.T("Statistics are stored only on this computer and are never sent anywhere.")
+	if options := a.analytics.Options(); !options.Sessions && !options.Features {
+		note = i18n.T("Nothing is being recorded. Turn on usage statistics under Preferences > Privacy.") + "\n" + note
+	}
+
+	avgMinutes := 0.0
+	if recent.Sessions > 0 {
+		avgMinutes = recent.Minutes / float64(recent.Sessions)
+	}
+	summary := widget.NewForm(
+		widget.NewFormItem(i18n.T("Sessions"), widget.NewLabel(fmt.Sprintf("%d", recent.Sessions))),
+		widget.NewFormItem(i18n.T("Minutes transcribed"), widget.NewLabel(fmt.Sprintf("%.1f", recent.Minutes))),
+		widget.NewFormItem(i18n.T("Days with sessions"), widget.NewLabel(i18n.Tf("%d of %d", recent.ActiveDays, recentDays))),
+		widget.NewFormItem(i18n.T("Average session"), widget.NewLabel(i18n.Tf("%.1f minutes", avgMinutes))),
+	)
+
+	// Newest day first
+	var dayLines []string
+	for i := len(days) - 1; i >= 0; i-- {
+		day := days[i]
+		dayLines = append(dayLines, i18n.Tf("%s    %d sessions    %.1f minutes", day.Date, day.Sessions, day.Minutes()))
+	}
+
+	tabs := container.NewAppTabs(
+		container.NewTabItem(i18n.T("By Day"), newLineList(dayLines)),
+		container.NewTabItem(i18n.T("Models"), newLineList(countLines(all.Models, i18n.T("sessions")))),
+		container.NewTabItem(i18n.T("Features"), newLineList(countLines(all.Features, i18n.T("uses")))),
+	)
+
+	var dlg dialog.Dialog
+	exportButton := widget.NewButton(i18n.T("Export CSV..."), func() {
+		a.exportAnalytics(days)
+	})
+	clearButton := widget.NewButton(i18n.T("Clear Statistics"), func() {
+		dialog.ShowConfirm(i18n.T("Clear Statistics"), i18n.T("Delete all recorded usage statistics?"), func(confirmed bool) {
+			if !confirmed {
+				return
+			}
+			if err := a.analytics.Clear(); err != nil {
+				dialog.ShowError(err, a.mainWindow)
+				return
+			}
+			dlg.Hide()
+			a.ShowTemporaryStatus(i18n.T("Usage statistics cleared"), 2*time.Second)
+		}, a.mainWindow)
+	})
+	clearButton.Importance = widget.WarningImportance
+
+	content := container.NewBorder(
+		container.NewVBox(
+			widget.NewLabel(note),
+			widget.NewLabelWithStyle(i18n.Tf("Last %d days", recentDays), fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+			summary,
+		),
+		container.NewHBox(exportButton, clearButton),
+		nil, nil,
+		tabs,
+	)
+	dlg = dialog.NewCustom(i18n.T("Usage Statistics"), i18n.T("Close"), content, a.mainWindow)
+	dlg.Resize(fyne.NewSize(550, 500))
+	dlg.Show()
+}
+
+// exportAnalytics saves the statistics as a CSV file with one row per day
+func (a *App) exportAnalytics(days []analytics.Day) {
+	saveDialog := dialog.NewFileSave(func(writer fyne.URIWriteCloser, err error) {
+		if err != nil {
+			dialog.ShowError(err, a.mainWindow)
+			return
+		}
+		if writer == nil {
+			return // Cancelled
+		}
+		defer writer.Close()
+		if err := analytics.WriteCSV(writer, days); err != nil {
+			dialog.ShowError(fmt.Errorf(i18n.T("Failed to export usage statistics: %v"), err), a.mainWindow)
+			return
+		}
+		a.ShowTemporaryStatus(i18n.T("Usage statistics exported"), 2*time.Second)
+	}, a.mainWindow)
+	saveDialog.SetFileName("ramble-usage.csv")
+	saveDialog.Show()
+}
+
+// newLineList shows lines of text in a scrolling list
+func newLineList(lines []string) fyne.CanvasObject {
+	if len(lines) == 0 {
+		return widget.NewLabel(i18n.T("Nothing recorded yet."))
+	}
+	return widget.NewList(
+		func() int { return len(lines) },
+		func() fyne.CanvasObject { return widget.NewLabel("") },
+		func(id widget.ListItemID, obj fyne.CanvasObject) {
+			obj.(*widget.Label).SetText(lines[id])
+		},
+	)
+}
+
+// countLines formats counts as lines, most used first
+func countLines(counts map[string]int, unit string) []string {
+	names := make([]string, 0, len(counts))
+	for name := range counts {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if counts[names[i]] != counts[names[j]] {
+			return counts[names[i]] > counts[names[j]]
+		}
+		return names[i] < names[j]
+	})
+
+	lines := make([]string, len(names))
+	for i, name := range names {
+		lines[i] = fmt.Sprintf("%s    %d %s", name, counts[name], unit)
+	}
+	return lines
+}
@@ -1,25 +1,37 @@
 package ui
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"image/color"
+	"io"
 	"os"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"fyne.io/fyne/v2"
-	"fyne.io/fyne/v2/app"
 	"fyne.io/fyne/v2/canvas"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/driver/desktop"
 	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/storage"
 	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
 
+	"github.com/jeff-barlow-spady/ramble/pkg/analytics"
+	"github.com/jeff-barlow-spady/ramble/pkg/audio"
 	"github.com/jeff-barlow-spady/ramble/pkg/clipboard"
+	"github.com/jeff-barlow-spady/ramble/pkg/config"
+	"github.com/jeff-barlow-spady/ramble/pkg/i18n"
 	"github.com/jeff-barlow-spady/ramble/pkg/logger"
+	"github.com/jeff-barlow-spady/ramble/pkg/output"
 	"github.com/jeff-barlow-spady/ramble/pkg/resources"
+	"github.com/jeff-barlow-spady/ramble/pkg/session"
+	"github.com/jeff-barlow-spady/ramble/pkg/textproc"
+	"github.com/jeff-barlow-spady/ramble/pkg/transcription"
 )
 
 // AppState represents the current state of the application
@@ -30,43 +42,105 @@ const (
 	StateListening
 	StateTranscribing
 	StateError
+	StateWarmingUp    // Reloading resources released while idle
+	StateReconnecting // Recording, but waiting for the microphone to come back
 )
 
+// isRecordingState returns whether a recording is in progress in state
+func isRecordingState(state AppState) bool {
+	return state == StateListening || state == StateTranscribing || state == StateReconnecting
+}
+
 // App manages the Fyne application and UI components
 type App struct {
-	fyneApp                    fyne.App
-	mainWindow                 fyne.Window
-	transcriptBox              *widget.Entry
-	streamingPreview           *widget.Entry
-	finalizedSegmentsContainer *fyne.Container
-	statusLabel                *canvas.Text
-	listenButton               *widget.Button
-	waveform                   *WaveformVisualizer
-	systray                    *SystemTray
-	appTitle                   *canvas.Text
-	state                      AppState
-	isTestMode                 bool
-	currentPreferences         Preferences
-	keyHandlerEnabled          bool
+	fyneApp            fyne.App
+	mainWindow         fyne.Window
+	statusLabel        *canvas.Text
+	lagLabel           *canvas.Text
+	filesLabel         *canvas.Text
+	performanceLabel   *canvas.Text
+	listenButton       *widget.Button
+	waveform           *WaveformVisualizer
+	spectrogram        *SpectrogramVisualizer
+	systray            *SystemTray
+	appTitle           *canvas.Text
+	state              AppState
+	isTestMode         bool
+	currentPreferences Preferences
+	keyHandlerEnabled  bool
 
 	// Hover window for compact UI
 	hoverWindow *HoverWindow
 	isHoverMode bool
 
+	// Red dot shown above other windows while recording, created when
+	// first needed
+	indicator *recordingIndicator
+
 	// Start hidden in system tray
 	startHidden bool
 
+	// Command mode, in which phrases heard run voice commands instead of
+	// being transcribed; read from the audio goroutine
+	commandMode   atomic.Bool
+	commandButton *widget.Button
+
+	// Whether the spectrogram is shown instead of the waveform; read from
+	// the audio callback
+	spectrogramShown atomic.Bool
+
 	// Callbacks for UI events
 	onStartListening     func()
 	onStopListening      func()
 	onClearTranscript    func()
 	onQuit               func()
 	onPreferencesChanged func(Preferences)
-
-	// For managing finalized segments
-	pendingSegment        string
-	finalizedSegmentTexts []string
-	currentSessionText    string // Accumulates text for the current recording session
+	onRetranscribe       func(audioPath, modelSize string) (string, error)
+	onTranscribeFile     func(path string, progress func(fraction float64)) ([]session.Segment, error)
+	onAddVocabulary      func(word string) error
+	onAddCorrection      func(from, to string) error
+	onSendToWebhook      func(text string) error
+	onSegmentFinalized   func(sessionID string, segment session.Segment)
+	onTakeSegmentAudio   func() []float32
+	onTakeSegmentSpeech  func() time.Duration
+	onRewrite            func(sessionID string, segment session.Segment, samples []float32, apply func(text string))
+	onSummarize          func(transcript string) (string, error)
+	onReadAloud          func(ctx context.Context, text string) error
+	keywordMatcher       func(text string) []string
+	onCommand            func(text string) (string, error)
+	onStartCalibration   func(onReading func(audio.Reading)) error
+	onStopCalibration    func()
+	onGainChanged        func(gain float64)
+	onHoverChanged       func(settings HoverSettings)
+
+	// Applied to text before it is copied to the clipboard, e.g. for redaction
+	clipboardFilter func(string) string
+
+	// Local usage statistics, shown in the dashboard
+	analytics *analytics.Tracker
+
+	// Text being read aloud, if any
+	reading reading
+
+	// Incognito dictation, which keeps the live session from being saved or
+	// sent anywhere that keeps it
+	privacy         *output.Privacy
+	incognitoButton *widget.Button
+	incognitoLabel  *canvas.Text
+
+	// Sessions, each in its own tab. The live session receives microphone
+	// transcription; others hold file transcription jobs and imports.
+	sessionTabs        *container.DocTabs
+	logs               *logView // The Logs tab, while open
+	live               *sessionView
+	views              []*sessionView
+	transcriptTheme    *transcriptTheme // Font, text size and line spacing of transcripts
+	pendingSegment     string
+	sessionStore       *session.Store   // Persists sessions so history survives restarts
+	currentSessionText string           // Accumulates text for the current recording session
+	journal            *session.Journal // Keeps currentSessionText until it is saved, for crash recovery
+	segmentStarted     time.Time        // When the segment being recorded started, zero if unknown
+	segmentLanguages   map[string]int   // Text detected in each language in the segment being recorded
 }
 
 // New creates a new UI application
@@ -76,18 +150,14 @@ func New() *App {
 
 // NewWithOptions creates a new UI application with customizable options
 func NewWithOptions(testMode bool) *App {
-	fyneApp := app.New()
-
-	// Apply our custom theme immediately for better visibility
-	fyneApp.Settings().SetTheme(NewRambleTheme(true)) // Default to dark theme
+	// The shared application has our custom theme applied for better visibility
+	fyneApp := fyneApplication()
 
 	mainWindow := fyneApp.NewWindow("Ramble")
 	mainWindow.Resize(fyne.NewSize(700, 500))
 
-	// Set application icon
-	appIcon := resources.LoadAppIcon()
-	if appIcon != nil {
-		fyneApp.SetIcon(appIcon)
+	// Set window icon
+	if appIcon := resources.LoadAppIcon(); appIcon != nil {
 		mainWindow.SetIcon(appIcon)
 	}
 
@@ -100,14 +170,31 @@ func NewWithOptions(testMode bool) *App {
 	prefs.DarkTheme = true // Default to dark theme
 
 	app := &App{
-		fyneApp:               fyneApp,
-		mainWindow:            mainWindow,
-		systray:               systray,
-		state:                 StateIdle,
-		isTestMode:            testMode,
-		currentPreferences:    prefs,
-		keyHandlerEnabled:     true,
-		finalizedSegmentTexts: make([]string, 0),
+		fyneApp:            fyneApp,
+		mainWindow:         mainWindow,
+		systray:            systray,
+		state:              StateIdle,
+		isTestMode:         testMode,
+		currentPreferences: prefs,
+		keyHandlerEnabled:  true,
+		transcriptTheme:    newTranscriptTheme(),
+	}
+
+	// Reopen the most recent session so its segments and undo history survive restarts
+	liveSession := session.New()
+	store, err := session.DefaultStore()
+	if err != nil {
+		logger.Warning(logger.CategoryUI, "Session history unavailable: %v", err)
+	} else {
+		app.sessionStore = store
+		if path, err := config.GetRecoveryFilePath(); err == nil {
+			app.journal = session.NewJournal(path)
+		}
+		if latest, err := store.Latest(); err != nil {
+			logger.Warning(logger.CategoryUI, "Failed to restore last session: %v", err)
+		} else if latest != nil {
+			liveSession = latest
+		}
 	}
 
 	// Set up window close event to minimize instead of quit
@@ -124,7 +211,7 @@ func NewWithOptions(testMode bool) *App {
 		app.showMainWindow,
 	)
 
-	app.setupUI()
+	app.setupUI(liveSession)
 
 	// Start system tray after UI is set up
 	systray.Start()
@@ -133,7 +220,7 @@ func NewWithOptions(testMode bool) *App {
 }
 
 // setupUI initializes all UI components with improved styling
-func (a *App) setupUI() {
+func (a *App) setupUI(liveSession *session.Session) {
 	// Create a simpler, more visible banner with plain text that will show reliably
 	bannerText := "RAMBLE"
 	asciiBanner := canvas.NewText(bannerText, color.NRGBA{R: 150, G: 180, B: 255, A: 255})
@@ -142,7 +229,7 @@ func (a *App) setupUI() {
 	asciiBanner.Alignment = fyne.TextAlignCenter
 
 	// Create subtitle directly under the banner
-	subtitle := canvas.NewText("Speech-to-Text Transcription", color.NRGBA{R: 200, G: 200, B: 200, A: 255})
+	subtitle := canvas.NewText(i18n.T("Speech-to-Text Transcription"), color.NRGBA{R: 200, G: 200, B: 200, A: 255})
 	subtitle.TextSize = 16
 	subtitle.TextStyle = fyne.TextStyle{Italic: true}
 	subtitle.Alignment = fyne.TextAlignCenter
@@ -155,29 +242,19 @@ func (a *App) setupUI() {
 	a.waveform.StartListening()
 	a.waveform.SetAmplitude(0.1) // Set initial amplitude for visibility
 
-	// Create the transcript box with improved readability
-	a.transcriptBox = widget.NewMultiLineEntry()
-	a.transcriptBox.Disable() // Make read-only but still selectable
-	a.transcriptBox.SetPlaceHolder("Your transcription will appear here...")
-	a.transcriptBox.Wrapping = fyne.TextWrapWord
-	a.transcriptBox.SetMinRowsVisible(12)
-	a.transcriptBox.TextStyle = fyne.TextStyle{Monospace: true} // Monospace for better readability
-
-	// Create the streaming preview area
-	a.streamingPreview = widget.NewMultiLineEntry()
-	a.streamingPreview.Disable() // Make read-only but still selectable
-	a.streamingPreview.SetPlaceHolder("Live transcription will appear here...")
-	a.streamingPreview.Wrapping = fyne.TextWrapWord
-	a.streamingPreview.TextStyle = fyne.TextStyle{Italic: true} // Indicate this is not final text
-
-	// Create the finalized segments container
-	segmentsBox := container.NewVBox()
-	a.finalizedSegmentsContainer = container.NewMax(container.NewVScroll(segmentsBox))
+	// The spectrogram replaces the waveform when chosen in the preferences
+	a.spectrogram = NewSpectrogramVisualizer()
+	a.spectrogram.Hide()
+
+	// Create the live session; other sessions are added as tabs next to it
+	a.live = newSessionView(a, i18n.T("Live Session"), liveSession, true)
+	a.views = []*sessionView{a.live}
 
 	// Create a frame around the waveform with centered content that fills the width
 	waveformContainer := container.New(layout.NewMaxLayout(),
 		canvas.NewRectangle(color.NRGBA{R: 30, G: 36, B: 66, A: 255}),
 		a.waveform, // Place waveform directly without additional centering container
+		a.spectrogram,
 	)
 
 	// Create a fixed size container for the waveform to ensure adequate height
@@ -194,18 +271,44 @@ func (a *App) setupUI() {
 	waveformPadded := container.NewPadded(waveformWithHeight)
 
 	// Create the buttons
-	a.listenButton = widget.NewButtonWithIcon("Start Recording", theme.MediaRecordIcon(), a.toggleListening)
+	a.listenButton = widget.NewButtonWithIcon(i18n.T("Start Recording"), theme.MediaRecordIcon(), a.toggleListening)
 	a.listenButton.Importance = widget.HighImportance
 
-	copyButton := widget.NewButtonWithIcon("Copy to Clipboard", theme.ContentCopyIcon(), a.copyTranscript)
-	clearButton := widget.NewButtonWithIcon("Clear", theme.ContentClearIcon(), a.clearTranscript)
+	copyButton := widget.NewButtonWithIcon(i18n.T("Copy to Clipboard"), theme.ContentCopyIcon(), a.copyTranscript)
+	clearButton := widget.NewButtonWithIcon(i18n.T("Clear"), theme.ContentClearIcon(), a.clearTranscript)
+	undoButton := widget.NewButtonWithIcon("", theme.ContentUndoIcon(), a.undoEdit)
+	redoButton := widget.NewButtonWithIcon("", theme.ContentRedoIcon(), a.redoEdit)
+	var exportButton *widget.Button
+	exportButton = widget.NewButtonWithIcon("", theme.DocumentSaveIcon(), func() {
+		a.showExportMenu(exportButton)
+	})
+	importButton := widget.NewButtonWithIcon("", theme.FolderOpenIcon(), a.importSession)
+	transcribeFileButton := widget.NewButtonWithIcon(i18n.T("Transcribe File"), theme.FileAudioIcon(), a.showTranscribeFileDialog)
 
 	// Create status label with styling
-	a.statusLabel = canvas.NewText("Ready", color.NRGBA{R: 100, G: 200, B: 100, A: 255})
+	a.statusLabel = canvas.NewText(i18n.T("Ready"), color.NRGBA{R: 100, G: 200, B: 100, A: 255})
 	a.statusLabel.TextSize = 16 // Larger text for better visibility
+
+	// Lag indicator, shown only while the transcriber can't keep up
+	a.lagLabel = canvas.NewText("", color.NRGBA{R: 255, G: 165, B: 0, A: 255})
+	a.lagLabel.TextSize = 14
+
+	// Progress of file transcription, shown only while files are transcribed
+	a.filesLabel = canvas.NewText("", color.NRGBA{R: 150, G: 150, B: 150, A: 255})
+	a.filesLabel.TextSize = 12
+
+	// Backend, model and real-time factor, shown once transcription runs
+	a.performanceLabel = canvas.NewText("", color.NRGBA{R: 150, G: 150, B: 150, A: 255})
+	a.performanceLabel.TextSize = 12
+
 	statusContainer := container.NewHBox(
 		canvas.NewCircle(color.NRGBA{R: 100, G: 200, B: 100, A: 255}),
 		a.statusLabel,
+		a.lagLabel,
+		a.filesLabel,
+		a.newIncognitoLabel(),
+		layout.NewSpacer(),
+		a.performanceLabel,
 	)
 
 	// Create banner container with proper spacing
@@ -221,12 +324,16 @@ func (a *App) setupUI() {
 
 	// Create a settings button
 	settingsButton := widget.NewButtonWithIcon("", theme.SettingsIcon(), a.showPreferencesDialog)
+	analyticsButton := widget.NewButtonWithIcon("", theme.ListIcon(), a.showAnalyticsDashboard)
+	logsButton := widget.NewButtonWithIcon("", theme.DocumentIcon(), a.showLogs)
 
 	// Arrange header with banner at top
 	header := container.NewVBox(
 		paddedBanner,
 		container.NewHBox(
 			layout.NewSpacer(),
+			logsButton,
+			analyticsButton,
 			settingsButton,
 		),
 		widget.NewSeparator(),
@@ -237,6 +344,13 @@ func (a *App) setupUI() {
 		container.NewPadded(a.listenButton),
 		layout.NewSpacer(),
 		container.NewHBox(
+			undoButton,
+			redoButton,
+			exportButton,
+			importButton,
+			transcribeFileButton,
+			a.newCommandButton(),
+			a.newIncognitoButton(),
 			copyButton,
 			clearButton,
 		),
@@ -249,37 +363,10 @@ func (a *App) setupUI() {
 		container.NewHBox(layout.NewSpacer(), statusContainer, layout.NewSpacer()),
 	)
 
-	// Create the tabbed container to separate the different views
-	tabs := container.NewAppTabs(
-		container.NewTabItem("Live Session",
-			container.NewBorder(
-				nil,
-				nil,
-				nil,
-				nil,
-				container.NewVSplit(
-					a.streamingPreview,
-					a.finalizedSegmentsContainer,
-				),
-			),
-		),
-		container.NewTabItem("Full Transcript",
-			container.NewBorder(
-				nil,
-				nil,
-				nil,
-				nil,
-				a.transcriptBox,
-			),
-		),
-	)
-
-	// Set the tab location to the top
-	tabs.SetTabLocation(container.TabLocationTop)
-
-	// Set the split ratio - give more space to the finalized segments
-	vsplit := tabs.Items[0].Content.(*fyne.Container).Objects[0].(*container.Split)
-	vsplit.Offset = 0.25 // 25% for streaming, 75% for finalized segments
+	// One tab per session; the toolbar acts on the selected one
+	a.sessionTabs = container.NewDocTabs(a.live.tab)
+	a.sessionTabs.SetTabLocation(container.TabLocationTop)
+	a.sessionTabs.CloseIntercept = a.closeSessionTab
 
 	// Create main content with border layout
 	content := container.NewBorder(
@@ -292,7 +379,7 @@ func (a *App) setupUI() {
 		// Right - none
 		nil,
 		// Center - tabbed container
-		tabs,
+		a.sessionTabs,
 	)
 
 	// Set the window content
@@ -300,6 +387,11 @@ func (a *App) setupUI() {
 
 	// Create the hover window
 	a.hoverWindow = NewHoverWindow(a.fyneApp)
+	a.hoverWindow.ApplySettings(a.currentPreferences.hoverSettings())
+	a.hoverWindow.SetPinCallback(func(onTop bool) {
+		a.currentPreferences.HoverOnTop = onTop
+		a.saveHoverSettings()
+	})
 	a.hoverWindow.SetCallbacks(
 		a.toggleListening,
 		a.copyTranscript,
@@ -328,6 +420,28 @@ func (a *App) setupHotkeys() {
 		}
 	})
 
+	// Register Ctrl+Z / Ctrl+Shift+Z for undoing segment edits
+	a.mainWindow.Canvas().AddShortcut(&desktop.CustomShortcut{
+		KeyName:  fyne.KeyZ,
+		Modifier: fyne.KeyModifierControl,
+	}, func(shortcut fyne.Shortcut) {
+		a.undoEdit()
+	})
+	a.mainWindow.Canvas().AddShortcut(&desktop.CustomShortcut{
+		KeyName:  fyne.KeyZ,
+		Modifier: fyne.KeyModifierControl | fyne.KeyModifierShift,
+	}, func(shortcut fyne.Shortcut) {
+		a.redoEdit()
+	})
+
+	// Register Ctrl+Shift+M for switching between dictation and command mode
+	a.mainWindow.Canvas().AddShortcut(&desktop.CustomShortcut{
+		KeyName:  fyne.KeyM,
+		Modifier: fyne.KeyModifierControl | fyne.KeyModifierShift,
+	}, func(shortcut fyne.Shortcut) {
+		a.toggleCommandMode()
+	})
+
 	// Register key handler for space key to toggle recording
 	a.mainWindow.Canvas().SetOnTypedKey(func(ke *fyne.KeyEvent) {
 		// Skip keyboard handling if disabled or in test mode
@@ -360,6 +474,14 @@ func (a *App) setupHotkeys() {
 
 // Run starts the UI event loop
 func (a *App) Run() {
+	a.Show()
+	a.fyneApp.Run()
+}
+
+// Show applies the theme and shows the main window unless it should start
+// hidden, without running the event loop. Use it when the loop is already
+// running, e.g. after ChooseProfile.
+func (a *App) Show() {
 	// Apply theme based on preferences
 	if a.currentPreferences.DarkTheme {
 		a.fyneApp.Settings().SetTheme(NewRambleTheme(true))
@@ -368,11 +490,11 @@ func (a *App) Run() {
 	}
 
 	// Start hidden or visible based on preferences
-	if a.currentPreferences.StartMinimized || a.startHidden {
-		a.fyneApp.Run()
-	} else {
-		a.mainWindow.ShowAndRun()
+	if !a.currentPreferences.StartMinimized && !a.startHidden {
+		a.mainWindow.Show()
 	}
+
+	a.offerRecovery()
 }
 
 // toggleListening switches between listening and idle states
@@ -380,7 +502,12 @@ func (a *App) toggleListening() {
 	// Focus the window for key events
 	a.mainWindow.RequestFocus()
 
-	if a.state == StateListening || a.state == StateTranscribing {
+	// Recording starts by itself once warm-up finishes
+	if a.state == StateWarmingUp {
+		return
+	}
+
+	if isRecordingState(a.state) {
 		// Stop listening
 		a.SetState(StateIdle)
 		if a.onStopListening != nil {
@@ -411,51 +538,77 @@ func (a *App) toggleListening() {
 	}
 }
 
+// SetListening starts or stops recording as the record button does, unless
+// the app already is in that state
+func (a *App) SetListening(listening bool) {
+	if isRecordingState(a.state) != listening {
+		a.toggleListening()
+	}
+}
+
 // SetState updates the application state and UI elements
 func (a *App) SetState(state AppState) {
+	wasRecording := isRecordingState(a.state)
 	a.state = state
-	a.systray.UpdateRecordingState(state == StateListening || state == StateTranscribing)
+	a.systray.UpdateRecordingState(isRecordingState(state))
+	a.updateRecordingIndicator()
 
 	// Update hover window if active
 	if a.isHoverMode && a.hoverWindow != nil {
-		a.hoverWindow.SetRecordingState(state == StateListening || state == StateTranscribing)
+		a.hoverWindow.SetRecordingState(isRecordingState(state))
 	}
 
 	// Update UI based on state
 	switch state {
 	case StateIdle:
-		a.statusLabel.Text = "Ready"
+		a.statusLabel.Text = i18n.T("Ready")
 		a.statusLabel.Color = color.NRGBA{R: 100, G: 200, B: 100, A: 255}
 		a.statusLabel.Refresh()
 		a.mainWindow.SetTitle("Ramble")
-		a.listenButton.SetText("Start Recording")
+		a.listenButton.SetText(i18n.T("Start Recording"))
 		a.listenButton.SetIcon(theme.MediaRecordIcon())
+		if wasRecording {
+			a.updateSessionStats(a.live)
+		}
 	case StateListening:
-		a.statusLabel.Text = "● RECORDING"
+		a.statusLabel.Text = i18n.T("● RECORDING")
 		a.statusLabel.Color = color.RGBA{R: 255, G: 50, B: 50, A: 255}
 		a.statusLabel.Refresh()
-		a.mainWindow.SetTitle("Ramble - Recording...")
-		a.listenButton.SetText("Stop Recording")
+		a.mainWindow.SetTitle(i18n.T("Ramble - Recording..."))
+		a.listenButton.SetText(i18n.T("Stop Recording"))
 		a.listenButton.SetIcon(theme.MediaStopIcon())
 	case StateTranscribing:
-		a.statusLabel.Text = "Transcribing..."
+		a.statusLabel.Text = i18n.T("Transcribing...")
+		a.statusLabel.Color = color.RGBA{R: 255, G: 165, B: 0, A: 255}
+		a.statusLabel.Refresh()
+		a.listenButton.SetText(i18n.T("Stop Recording"))
+		a.listenButton.SetIcon(theme.MediaStopIcon())
+	case StateWarmingUp:
+		a.statusLabel.Text = i18n.T("Warming up…")
+		a.statusLabel.Color = color.RGBA{R: 255, G: 165, B: 0, A: 255}
+		a.statusLabel.Refresh()
+		a.listenButton.SetText(i18n.T("Warming up…"))
+		a.listenButton.SetIcon(theme.MediaRecordIcon())
+	case StateReconnecting:
+		a.statusLabel.Text = i18n.T("Microphone disconnected…")
 		a.statusLabel.Color = color.RGBA{R: 255, G: 165, B: 0, A: 255}
 		a.statusLabel.Refresh()
-		a.listenButton.SetText("Stop Recording")
+		a.mainWindow.SetTitle(i18n.T("Ramble - Reconnecting..."))
+		a.listenButton.SetText(i18n.T("Stop Recording"))
 		a.listenButton.SetIcon(theme.MediaStopIcon())
 	case StateError:
-		a.statusLabel.Text = "Error"
+		a.statusLabel.Text = i18n.T("Error")
 		a.statusLabel.Color = color.RGBA{R: 255, G: 0, B: 0, A: 255}
 		a.statusLabel.Refresh()
-		a.mainWindow.SetTitle("Ramble - Error")
-		a.listenButton.SetText("Start Recording")
+		a.mainWindow.SetTitle(i18n.T("Ramble - Error"))
+		a.listenButton.SetText(i18n.T("Start Recording"))
 		a.listenButton.SetIcon(theme.MediaRecordIcon())
 	}
 }
 
 // UpdateTranscript updates the transcript text
 func (a *App) UpdateTranscript(text string) {
-	a.transcriptBox.SetText(text)
+	a.live.transcriptBox.SetText(text)
 }
 
 // AppendTranscript adds text to the transcript
@@ -470,26 +623,16 @@ func (a *App) AppendTranscript(text string) {
 	a.UpdateStreamingPreview(trimmedText)
 
 	// For backward compatibility, also update the classic transcriptBox
-	current := a.transcriptBox.Text
-	if current == "" || current == "Your transcription will appear here..." {
-		a.transcriptBox.SetText(trimmedText)
+	transcriptBox := a.live.transcriptBox
+	current := transcriptBox.Text
+	if current == "" || current == i18n.T(transcriptPlaceholder) {
+		transcriptBox.SetText(trimmedText)
 	} else {
-		// Check if we need to add punctuation
-		lastChar := current[len(current)-1]
-		needsPunctuation := lastChar != '.' && lastChar != '?' &&
-			lastChar != '!' && lastChar != '\n' && lastChar != ' '
-
-		if needsPunctuation {
-			current += ". "
-		} else if lastChar != ' ' && lastChar != '\n' {
-			current += " "
-		}
-
-		a.transcriptBox.SetText(current + trimmedText)
+		transcriptBox.SetText(textproc.Join(current, trimmedText))
 	}
 
 	// Auto-scroll to bottom when new text is added
-	a.transcriptBox.CursorRow = len(strings.Split(a.transcriptBox.Text, "\n")) - 1
+	transcriptBox.CursorRow = len(strings.Split(transcriptBox.Text, "\n")) - 1
 
 	// Update hover window if active
 	if a.isHoverMode && a.hoverWindow != nil {
@@ -497,6 +640,19 @@ func (a *App) AppendTranscript(text string) {
 	}
 }
 
+// SetResourcesReleased pauses the idle waveform animation while the model and
+// audio system are released, and resumes it when they are back
+func (a *App) SetResourcesReleased(released bool) {
+	if a.waveform == nil {
+		return
+	}
+	if released {
+		a.waveform.StopListening()
+	} else {
+		a.waveform.StartListening()
+	}
+}
+
 // UpdateAudioLevel updates the audio level in the waveform
 func (a *App) UpdateAudioLevel(level float32) {
 	// Set a minimum amplitude for visual feedback
@@ -516,6 +672,96 @@ func (a *App) UpdateAudioLevel(level float32) {
 	}
 }
 
+// UpdateSpectrum adds the spectrum of the latest audio, as levels of
+// frequency bands from low to high, to the spectrogram
+func (a *App) UpdateSpectrum(bands []float32) {
+	if a.spectrogram != nil && a.spectrogramShown.Load() {
+		a.spectrogram.AddSpectrum(bands)
+	}
+}
+
+// SpectrogramShown reports whether the spectrogram is shown, so the spectrum
+// only needs computing then. It is safe to call from the audio callback.
+func (a *App) SpectrogramShown() bool {
+	return a.spectrogramShown.Load()
+}
+
+// applyVisualization shows the waveform or the spectrogram
+func (a *App) applyVisualization(prefs Preferences) {
+	if a.waveform == nil || a.spectrogram == nil {
+		return
+	}
+	spectrogram := prefs.Visualization == VisualizationSpectrogram
+	if spectrogram == a.spectrogramShown.Load() {
+		return
+	}
+	a.spectrogramShown.Store(spectrogram)
+	if spectrogram {
+		a.waveform.Hide()
+		a.spectrogram.Clear()
+		a.spectrogram.Show()
+	} else {
+		a.spectrogram.Hide()
+		a.waveform.Show()
+	}
+}
+
+// SetTranscriptionLagging shows or hides the "transcription lagging" indicator
+func (a *App) SetTranscriptionLagging(lagging bool) {
+	if a.lagLabel == nil {
+		return
+	}
+
+	if lagging {
+		a.lagLabel.Text = i18n.T("⚠ Transcription lagging")
+	} else {
+		a.lagLabel.Text = ""
+	}
+	a.lagLabel.Refresh()
+}
+
+// SetFileProgress shows how far file transcription is through the queued
+// files in the status bar, and hides it once they are all done
+func (a *App) SetFileProgress(p transcription.QueueProgress) {
+	if a.filesLabel == nil {
+		return
+	}
+
+	if p.JobsDone < p.Jobs {
+		a.filesLabel.Text = i18n.Tf("Files: %d of %d done, %d%%", p.JobsDone, p.Jobs, p.Percent())
+	} else {
+		a.filesLabel.Text = ""
+	}
+	a.filesLabel.Refresh()
+}
+
+// SetPerformance shows the transcription backend, model and real-time
+// factor in the status bar, in orange when transcription can't keep up
+func (a *App) SetPerformance(perf transcription.Performance) {
+	if a.performanceLabel == nil {
+		return
+	}
+
+	parts := []string{perf.Backend}
+	if perf.Model != "" {
+		parts = append(parts, perf.Model)
+	}
+	if perf.Threads > 0 {
+		parts = append(parts, i18n.Tf("CPU, %d threads", perf.Threads))
+	}
+	if perf.RTF > 0 {
+		parts = append(parts, i18n.Tf("RTF %.1fx", perf.RTF))
+	}
+	a.performanceLabel.Text = strings.Join(parts, " · ")
+
+	if perf.Lagging() {
+		a.performanceLabel.Color = color.NRGBA{R: 255, G: 165, B: 0, A: 255}
+	} else {
+		a.performanceLabel.Color = color.NRGBA{R: 150, G: 150, B: 150, A: 255}
+	}
+	a.performanceLabel.Refresh()
+}
+
 // ShowTemporaryStatus shows a status message that disappears after a delay
 func (a *App) ShowTemporaryStatus(message string, duration time.Duration) {
 	prevText := a.statusLabel.Text
@@ -544,6 +790,11 @@ func (a *App) ShowTemporaryStatus(message string, duration time.Duration) {
 
 // showMainWindow shows the main application window
 func (a *App) showMainWindow() {
+	// The hover window may let clicks through, so this is the way back
+	if a.isHoverMode {
+		a.toggleHoverWindow()
+		return
+	}
 	a.mainWindow.Show()
 	a.mainWindow.RequestFocus()
 }
@@ -551,13 +802,16 @@ func (a *App) showMainWindow() {
 // showAboutDialog shows the about dialog
 func (a *App) showAboutDialog() {
 	a.mainWindow.Show()
-	dialog.ShowInformation("About Ramble",
-		"Ramble Speech-to-Text\nVersion 0.1.0\n\nTranscribe speech to text quickly and easily.",
+	dialog.ShowInformation(i18n.T("About Ramble"),
+		i18n.T("Ramble Speech-to-Text\nVersion 0.1.0\n\nTranscribe speech to text quickly and easily."),
 		a.mainWindow)
 }
 
 // doQuit properly exits the application
 func (a *App) doQuit() {
+	// Make sure the latest edits are on disk
+	a.SaveSession()
+
 	// Call any user-defined quit handlers
 	if a.onQuit != nil {
 		a.onQuit()
@@ -580,46 +834,54 @@ func (a *App) doQuit() {
 	}()
 }
 
-// copyTranscript copies the transcript text to clipboard
+// copyTranscript copies the selected session's transcript text to clipboard
 func (a *App) copyTranscript() {
-	text := a.transcriptBox.Text
-	if text == "" || text == "Your transcription will appear here..." {
-		a.ShowTemporaryStatus("Nothing to copy!", 2*time.Second)
+	// The Full Transcript tab only shows the segments still in memory
+	text := strings.Join(a.sessionTexts(a.currentView().session), "\n\n")
+	if text == "" {
+		a.ShowTemporaryStatus(i18n.T("Nothing to copy!"), 2*time.Second)
 		return
 	}
 
-	err := clipboard.SetText(text)
+	err := clipboard.SetText(a.filterClipboardText(text))
 	if err != nil {
 		logger.Error(logger.CategoryUI, "Failed to copy text to clipboard: %v", err)
-		dialog.ShowError(fmt.Errorf("Failed to copy text: %v", err), a.mainWindow)
+		dialog.ShowError(fmt.Errorf(i18n.T("Failed to copy text: %v"), err), a.mainWindow)
 	} else {
-		a.ShowTemporaryStatus("Copied to clipboard", 2*time.Second)
+		a.recordFeature(analytics.FeatureCopy)
+		a.ShowTemporaryStatus(i18n.T("Copied to clipboard"), 2*time.Second)
 	}
 }
 
-// clearTranscript clears the transcript text and all finalized segments
+// clearTranscript clears the selected session's transcript and finalized segments
 func (a *App) clearTranscript() {
-	// Clear the classic view transcript
-	a.transcriptBox.SetText("")
+	v := a.currentView()
+	if v.busy {
+		a.ShowTemporaryStatus(i18n.T("Wait for the transcription to finish"), 2*time.Second)
+		return
+	}
 
-	// Clear the streaming preview
-	a.streamingPreview.SetText("")
-	a.pendingSegment = ""
-	a.currentSessionText = ""
+	// Start a new session; the previous one stays in the session history
+	a.saveView(v)
+	v.session = session.New()
+	v.rebuild()
 
-	// Clear the finalized segments
-	a.finalizedSegmentTexts = make([]string, 0)
+	a.ShowTemporaryStatus(i18n.T("All transcriptions cleared"), 2*time.Second)
 
-	// Clear the finalized segments container
-	if a.finalizedSegmentsContainer != nil {
-		scrollContainer := a.finalizedSegmentsContainer.Objects[0].(*container.Scroll)
-		if segmentsBox, ok := scrollContainer.Content.(*fyne.Container); ok {
-			segmentsBox.Objects = nil
-			scrollContainer.Refresh()
-		}
+	if v.live {
+		a.clearLiveText()
 	}
+}
 
-	a.ShowTemporaryStatus("All transcriptions cleared", 2*time.Second)
+// clearLiveText drops the text recognized for the live session but not yet
+// finalized
+func (a *App) clearLiveText() {
+	// Clear the streaming preview
+	a.live.streamingPreview.SetText("")
+	a.live.karaoke.Clear()
+	a.pendingSegment = ""
+	a.currentSessionText = ""
+	a.clearJournal()
 
 	if a.onClearTranscript != nil {
 		a.onClearTranscript()
@@ -639,6 +901,13 @@ func (a *App) showPreferencesDialog() {
 			a.fyneApp.Settings().SetTheme(NewRambleTheme(false))
 		}
 
+		if a.hoverWindow != nil {
+			a.hoverWindow.ApplySettings(prefs.hoverSettings())
+		}
+		a.applyTranscriptStyle(prefs)
+		a.applyVisualization(prefs)
+		a.updateRecordingIndicator()
+
 		// Notify callback if set
 		if a.onPreferencesChanged != nil {
 			a.onPreferencesChanged(prefs)
@@ -646,18 +915,31 @@ func (a *App) showPreferencesDialog() {
 	})
 }
 
+// SetHoverCallback sets the function saving the hover window settings when
+// they change outside the preferences dialog, by pinning or resizing the window
+func (a *App) SetHoverCallback(onChanged func(settings HoverSettings)) {
+	a.onHoverChanged = onChanged
+}
+
+// saveHoverSettings passes changed hover window settings to be saved
+func (a *App) saveHoverSettings() {
+	if a.onHoverChanged != nil {
+		a.onHoverChanged(a.currentPreferences.hoverSettings())
+	}
+}
+
 // toggleHoverWindow toggles the hover window UI mode
 func (a *App) toggleHoverWindow() {
 	a.isHoverMode = !a.isHoverMode
 
 	if a.isHoverMode {
 		// Set the hover window's transcript to match the main window
-		if a.transcriptBox.Text != "Your transcription will appear here..." {
-			a.hoverWindow.UpdateTranscript(a.transcriptBox.Text)
+		if text := a.live.transcriptBox.Text; text != i18n.T(transcriptPlaceholder) {
+			a.hoverWindow.UpdateTranscript(text)
 		}
 
 		// Set the recording state to match
-		isRecording := (a.state == StateListening || a.state == StateTranscribing)
+		isRecording := isRecordingState(a.state)
 		a.hoverWindow.SetRecordingState(isRecording)
 
 		// Show hover window and hide main window
@@ -666,10 +948,16 @@ func (a *App) toggleHoverWindow() {
 
 		// Log and show status
 		logger.Info(logger.CategoryUI, "Hover window activated")
-		a.hoverWindow.ShowTemporaryStatus("Compact mode active", 1500*time.Millisecond)
+		a.hoverWindow.ShowTemporaryStatus(i18n.T("Compact mode active"), 1500*time.Millisecond)
 	} else {
-		// Hide hover window and show main window
+		// Hide hover window and show main window, keeping the size the
+		// user gave it
 		if a.hoverWindow != nil {
+			if size := a.hoverWindow.Size(); size.Width > 0 && size.Height > 0 &&
+				(size.Width != a.currentPreferences.HoverWidth || size.Height != a.currentPreferences.HoverHeight) {
+				a.currentPreferences.HoverWidth, a.currentPreferences.HoverHeight = size.Width, size.Height
+				a.saveHoverSettings()
+			}
 			a.hoverWindow.Hide()
 		}
 		a.mainWindow.Show()
@@ -705,6 +993,137 @@ func (a *App) GetPreferences() Preferences {
 	return a.currentPreferences
 }
 
+// SetPreferences replaces the current preferences, e.g. with values loaded from the config file
+func (a *App) SetPreferences(prefs Preferences) {
+	a.currentPreferences = prefs
+	if a.hoverWindow != nil {
+		a.hoverWindow.ApplySettings(prefs.hoverSettings())
+	}
+	a.applyTranscriptStyle(prefs)
+	a.applyVisualization(prefs)
+	a.updateRecordingIndicator()
+}
+
+// updateRecordingIndicator shows the recording indicator while recording, if
+// the preferences ask for it
+func (a *App) updateRecordingIndicator() {
+	show := a.currentPreferences.RecordingIndicator && isRecordingState(a.state)
+	if a.indicator == nil {
+		if !show {
+			return
+		}
+		a.indicator = newRecordingIndicator(a.fyneApp, func() { a.SetListening(false) })
+	}
+	a.indicator.SetVisible(show)
+}
+
+// applyTranscriptStyle sets the font, text size and line spacing of the
+// transcripts, live preview and segment cards of every session
+func (a *App) applyTranscriptStyle(prefs Preferences) {
+	a.transcriptTheme.set(prefs.TranscriptFontSize, prefs.TranscriptLineSpacing, prefs.TranscriptFont)
+	for _, v := range a.views {
+		v.restyle()
+	}
+}
+
+// ShowCrashReport lets the user review a crash report from a previous run and
+// save a copy of it. onClosed is called when the dialog is dismissed.
+func (a *App) ShowCrashReport(report string, onClosed func()) {
+	a.mainWindow.Show()
+
+	reportText := widget.NewMultiLineEntry()
+	reportText.SetText(report)
+	reportText.Wrapping = fyne.TextWrapOff
+	reportText.Disable() // Read-only, but still selectable for copying
+
+	saveButton := widget.NewButtonWithIcon(i18n.T("Save As..."), theme.DocumentSaveIcon(), func() {
+		dialog.ShowFileSave(func(writer fyne.URIWriteCloser, err error) {
+			if err != nil {
+				dialog.ShowError(err, a.mainWindow)
+				return
+			}
+			if writer == nil {
+				return // Cancelled
+			}
+			defer writer.Close()
+			if _, err := writer.Write([]byte(report)); err != nil {
+				dialog.ShowError(fmt.Errorf(i18n.T("Failed to save crash report: %v"), err), a.mainWindow)
+				return
+			}
+			a.ShowTemporaryStatus(i18n.T("Crash report saved"), 2*time.Second)
+		}, a.mainWindow)
+	})
+
+	content := container.NewBorder(
+		widget.NewLabel(i18n.T("Ramble closed unexpectedly last time. This report was saved locally and has not been sent anywhere.")),
+		container.NewHBox(layout.NewSpacer(), saveButton),
+		nil, nil,
+		reportText,
+	)
+
+	dlg := dialog.NewCustom(i18n.T("Crash Report"), i18n.T("Close"), content, a.mainWindow)
+	dlg.Resize(fyne.NewSize(650, 450))
+	dlg.SetOnClosed(func() {
+		if onClosed != nil {
+			onClosed()
+		}
+	})
+	dlg.Show()
+}
+
+// SetClipboardFilter sets a function applied to text before it is copied to the clipboard
+func (a *App) SetClipboardFilter(filter func(string) string) {
+	a.clipboardFilter = filter
+}
+
+// SetSavedTextFilter sets a function applied to transcript text before it is saved to disk
+func (a *App) SetSavedTextFilter(filter func(string) string) {
+	if a.sessionStore != nil {
+		a.sessionStore.SetFilter(filter)
+	}
+}
+
+// autoCopy copies a newly finalized segment, or the whole session, according
+// to the auto-copy preferences
+func (a *App) autoCopy(segment string) {
+	if !a.currentPreferences.AutoCopy {
+		return
+	}
+
+	texts := a.sessionTexts(a.live.session)
+	for i, text := range texts {
+		texts[i] = a.filterClipboardText(text)
+	}
+
+	policy := clipboard.NewAutoCopyPolicy(
+		clipboard.AutoCopyMode(a.currentPreferences.AutoCopyMode),
+		a.currentPreferences.AutoCopyTransient,
+	)
+	copied, err := policy.SegmentFinalized(a.filterClipboardText(segment), texts)
+	if err != nil {
+		logger.Error(logger.CategoryUI, "Auto-copy failed: %v", err)
+		return
+	}
+	if copied {
+		a.ShowTemporaryStatus(i18n.T("Copied to clipboard"), 2*time.Second)
+	}
+}
+
+// filterClipboardText applies the clipboard filter, if any
+func (a *App) filterClipboardText(text string) string {
+	if a.clipboardFilter == nil {
+		return text
+	}
+	return a.clipboardFilter(text)
+}
+
+// SaveSession persists all open sessions, e.g. before the application exits unexpectedly
+func (a *App) SaveSession() {
+	for _, v := range a.views {
+		a.saveView(v)
+	}
+}
+
 // ShowErrorDialog displays an error dialog with title and message
 func (a *App) ShowErrorDialog(title, message string) {
 	dialog.ShowError(fmt.Errorf("%s", message), a.mainWindow)
@@ -713,16 +1132,17 @@ func (a *App) ShowErrorDialog(title, message string) {
 // UpdateStreamingPreview updates the streaming preview text with raw transcription
 // DEPRECATED: No longer needed as the streaming preview is handled directly in AppendSessionText
 func (a *App) UpdateStreamingPreview(text string) {
-	if a.streamingPreview == nil {
+	if a.live == nil {
 		return
 	}
+	preview := a.live.streamingPreview
 
 	// Simply set the text in the streaming preview
-	a.streamingPreview.SetText(text)
+	preview.SetText(text)
 
 	// Auto-scroll to bottom when new text is added
-	if a.streamingPreview.CursorRow < len(strings.Split(a.streamingPreview.Text, "\n"))-1 {
-		a.streamingPreview.CursorRow = len(strings.Split(a.streamingPreview.Text, "\n")) - 1
+	if preview.CursorRow < len(strings.Split(preview.Text, "\n"))-1 {
+		preview.CursorRow = len(strings.Split(preview.Text, "\n")) - 1
 	}
 }
 
@@ -733,20 +1153,91 @@ func (a *App) AppendSessionText(text string) {
 	}
 
 	// Show raw model output in the streaming preview (what the model is currently processing)
-	a.streamingPreview.SetText(text)
+	a.live.streamingPreview.SetText(text)
 
-	// For the current session, simply accumulate text with proper spacing
-	if a.currentSessionText == "" {
-		a.currentSessionText = text
-	} else {
-		// Trust the manager.go's output and just append with spacing
-		a.currentSessionText += " " + text
+	// Join the text to the session, closing a sentence the last window left open
+	a.currentSessionText = textproc.Join(a.currentSessionText, text)
+	a.journalPending()
+}
+
+// NoteSpokenLanguage records the language detected for the next text passed
+// to AppendSessionText. The segment is tagged with the language most of its
+// text was in.
+func (a *App) NoteSpokenLanguage(language string) {
+	if language == "" {
+		return
+	}
+	if a.segmentLanguages == nil {
+		a.segmentLanguages = make(map[string]int)
+	}
+	a.segmentLanguages[language]++
+}
+
+// takeSegmentLanguage returns the language most of the segment being
+// finalized was in, or "" if it wasn't detected, and starts counting afresh
+func (a *App) takeSegmentLanguage() string {
+	var language string
+	for l, count := range a.segmentLanguages {
+		if count > a.segmentLanguages[language] || (count == a.segmentLanguages[language] && l < language) {
+			language = l
+		}
 	}
+	a.segmentLanguages = nil
+	return language
+}
+
+// ShowSpokenWords shows the words of the newest live text in the live
+// session, highlighting each at the pace it was spoken
+func (a *App) ShowSpokenWords(words []transcription.Word) {
+	if a.live == nil || a.live.karaoke == nil {
+		return
+	}
+	a.live.karaoke.Show(words)
+}
+
+// BeginTranscriptionSegment records that a new segment starts now. It should
+// be called when a recording starts; later segments of the same recording
+// start when the previous one is finalized.
+func (a *App) BeginTranscriptionSegment() {
+	a.segmentStarted = time.Now()
+}
+
+// PendingSegment returns how much text the segment being recorded holds and
+// when it started
+func (a *App) PendingSegment() (chars int, started time.Time) {
+	return len(a.currentSessionText), a.segmentStarted
+}
+
+// PendingText returns the text of the segment being recorded so far
+func (a *App) PendingText() string {
+	return a.currentSessionText
 }
 
 // FinalizeTranscriptionSegment adds the current session text to the finalized segments
 // This should be called when a recording session ends
 func (a *App) FinalizeTranscriptionSegment() {
+	a.FinalizeTranscriptionSegmentWithAudio("")
+}
+
+// FinalizeTranscriptionSegmentWithAudio finalizes the current session text and
+// links it to the archived recording it was transcribed from
+func (a *App) FinalizeTranscriptionSegmentWithAudio(audioPath string) {
+	// The next segment of the same recording starts where this one ends
+	started, ended := a.segmentStarted, time.Now()
+	a.segmentStarted = ended
+
+	// The segment's audio is taken even if it has no text, so it isn't
+	// rewritten as part of the next one
+	var samples []float32
+	if a.onTakeSegmentAudio != nil {
+		samples = a.onTakeSegmentAudio()
+	}
+	var speech time.Duration
+	if a.onTakeSegmentSpeech != nil {
+		speech = a.onTakeSegmentSpeech()
+	}
+	language := a.takeSegmentLanguage()
+
 	// If there's no session text, nothing to finalize
 	if a.currentSessionText == "" {
 		return
@@ -756,102 +1247,668 @@ func (a *App) FinalizeTranscriptionSegment() {
 	a.currentSessionText = "" // Reset for the next session
 
 	// Clear the streaming preview
-	a.streamingPreview.SetText("")
+	a.live.streamingPreview.SetText("")
+	a.live.karaoke.Clear()
 	a.pendingSegment = ""
 
-	// Add the text to the finalized segments array
-	a.finalizedSegmentTexts = append(a.finalizedSegmentTexts, finalText)
+	// In command mode the phrase runs a command and isn't kept
+	if a.CommandMode() && a.onCommand != nil {
+		a.clearJournal()
+		a.runVoiceCommand(finalText)
+		return
+	}
+
+	// Add the text to the session, tagged with the watched keywords heard in it
+	var tags []string
+	if a.keywordMatcher != nil {
+		tags = a.keywordMatcher(finalText)
+	}
+	segment := a.live.session.AppendSegment(session.Segment{
+		Text:      finalText,
+		Audio:     audioPath,
+		StartedAt: started,
+		EndedAt:   ended,
+		Tags:      tags,
+		Language:  language,
+		Speech:    speech,
+	})
+	a.saveView(a.live)
+	a.clearJournal()
+	if len(tags) > 0 {
+		a.notifyKeywords(segment)
+	}
+
+	// Copy automatically if enabled in preferences
+	a.autoCopy(finalText)
+
+	// Text leaving the app is redacted like text copied to the clipboard
+	if a.onSegmentFinalized != nil {
+		notified := segment
+		notified.Text = a.filterClipboardText(segment.Text)
+		a.onSegmentFinalized(a.live.session.ID, notified)
+	}
 
-	// Create a new segment card
-	segmentCard := createTranscriptionSegmentCard(
-		finalText,
+	// Show the new segment card and update the full transcript
+	a.live.addSegment(segment)
+	a.spillView(a.live)
+
+	if a.onRewrite != nil {
+		a.rewriteSegment(a.live, segment, samples)
+	}
+}
+
+// deleteTranscriptionSegment removes a segment from a session's finalized segments
+func (a *App) deleteTranscriptionSegment(v *sessionView, id int) {
+	if err := v.session.Delete(id); err != nil {
+		logger.Warning(logger.CategoryUI, "Failed to delete segment %d: %v", id, err)
+		return
+	}
+	a.saveView(v)
+
+	// Rebuild the UI container (simpler than trying to find and remove a specific card)
+	v.rebuild()
+	a.ShowTemporaryStatus(i18n.T("Segment deleted (Ctrl+Z to undo)"), 2*time.Second)
+}
+
+// undoEdit reverts the most recent segment edit in the selected session
+func (a *App) undoEdit() {
+	v := a.currentView()
+	if !v.session.UndoLast() {
+		a.ShowTemporaryStatus(i18n.T("Nothing to undo"), 2*time.Second)
+		return
+	}
+	a.saveView(v)
+	v.rebuild()
+	a.ShowTemporaryStatus(i18n.T("Undone"), 2*time.Second)
+}
+
+// redoEdit re-applies the most recently undone segment edit in the selected session
+func (a *App) redoEdit() {
+	v := a.currentView()
+	if !v.session.RedoLast() {
+		a.ShowTemporaryStatus(i18n.T("Nothing to redo"), 2*time.Second)
+		return
+	}
+	a.saveView(v)
+	v.rebuild()
+	a.ShowTemporaryStatus(i18n.T("Redone"), 2*time.Second)
+}
+
+// spillView moves the oldest segments of a long session to the session
+// history, keeping only the newest in memory and in the segment list
+func (a *App) spillView(v *sessionView) {
+	if !a.keepsView(v) || len(v.segments) <= sessionMaxSegments {
+		return
+	}
+	if err := a.sessionStore.Spill(v.session, sessionKeepSegments); err != nil {
+		logger.Warning(logger.CategoryUI, "Failed to move old segments to the session history: %v", err)
+		return
+	}
+	v.rebuild()
+}
+
+// sessionTexts returns the text of every segment of a session, including
+// segments spilled to the session history
+func (a *App) sessionTexts(s *session.Session) []string {
+	if a.sessionStore == nil {
+		return s.Texts()
+	}
+	segments, err := a.sessionStore.Segments(s)
+	if err != nil {
+		logger.Warning(logger.CategoryUI, "Failed to read earlier segments: %v", err)
+		return s.Texts()
+	}
+	texts := make([]string, len(segments))
+	for i, segment := range segments {
+		texts[i] = segment.Text
+	}
+	return texts
+}
+
+// saveView persists a session and its undo history
+func (a *App) saveView(v *sessionView) {
+	if !a.keepsView(v) {
+		return
+	}
+	if err := a.sessionStore.Save(v.session); err != nil {
+		logger.Warning(logger.CategoryUI, "Failed to save session: %v", err)
+	}
+}
+
+// keepsView reports whether a session is saved in the session history. The
+// live session isn't while incognito.
+func (a *App) keepsView(v *sessionView) bool {
+	return a.sessionStore != nil && (!v.live || a.privacy.Allows(output.KindHistory))
+}
+
+// currentView returns the session in the selected tab
+func (a *App) currentView() *sessionView {
+	selected := a.sessionTabs.Selected()
+	for _, v := range a.views {
+		if v.tab == selected {
+			return v
+		}
+	}
+	return a.live
+}
+
+// addView opens a session in a new tab and selects it
+func (a *App) addView(v *sessionView) {
+	a.views = append(a.views, v)
+	a.sessionTabs.Append(v.tab)
+	a.sessionTabs.Select(v.tab)
+}
+
+// closeSessionTab closes a session's tab. The live session stays open, and a
+// file job must finish first. Closed sessions remain in the session history.
+func (a *App) closeSessionTab(tab *container.TabItem) {
+	if a.logs != nil && tab == a.logs.tab {
+		a.logs.close()
+		a.logs = nil
+		a.sessionTabs.Remove(tab)
+		return
+	}
+	for i, v := range a.views {
+		if v.tab != tab {
+			continue
+		}
+		if v.live {
+			a.ShowTemporaryStatus(i18n.T("The live session can't be closed"), 2*time.Second)
+			return
+		}
+		if v.busy {
+			a.ShowTemporaryStatus(i18n.T("Wait for the transcription to finish"), 2*time.Second)
+			return
+		}
+		a.saveView(v)
+		a.views = append(a.views[:i], a.views[i+1:]...)
+		a.sessionTabs.Remove(tab)
+		return
+	}
+}
+
+// showExportMenu offers the export formats below the export button
+func (a *App) showExportMenu(button fyne.CanvasObject) {
+	items := []*fyne.MenuItem{
+		fyne.NewMenuItem(i18n.T("Session (JSON)..."), a.exportSession),
+		fyne.NewMenuItemSeparator(),
+	}
+	for _, format := range []struct {
+		label  string
+		format session.TranscriptFormat
+	}{
+		{"Markdown...", session.FormatMarkdown},
+		{"Plain Text...", session.FormatText},
+		{"HTML...", session.FormatHTML},
+	} {
+		format := format
+		items = append(items, fyne.NewMenuItem(i18n.T(format.label), func() {
+			a.exportTranscript(format.format)
+		}))
+	}
+
+	pos := a.fyneApp.Driver().AbsolutePositionForObject(button)
+	pos = pos.Add(fyne.NewPos(0, button.Size().Height))
+	widget.ShowPopUpMenuAtPosition(fyne.NewMenu("", items...), a.mainWindow.Canvas(), pos)
+}
+
+// exportTranscript writes the selected session's transcript as a document
+func (a *App) exportTranscript(format session.TranscriptFormat) {
+	s := a.currentView().session
+	var data []byte
+	var err error
+	if a.sessionStore != nil {
+		data, err = a.sessionStore.RenderTranscript(s, format)
+	} else {
+		data, err = session.RenderTranscript(s, format)
+	}
+	if err != nil {
+		dialog.ShowError(fmt.Errorf(i18n.T("Failed to export transcript: %v"), err), a.mainWindow)
+		return
+	}
+
+	saveDialog := dialog.NewFileSave(func(writer fyne.URIWriteCloser, err error) {
+		if err != nil {
+			dialog.ShowError(err, a.mainWindow)
+			return
+		}
+		if writer == nil {
+			return // Cancelled
+		}
+		defer writer.Close()
+		if _, err := writer.Write(data); err != nil {
+			dialog.ShowError(fmt.Errorf(i18n.T("Failed to export transcript: %v"), err), a.mainWindow)
+			return
+		}
+		a.recordFeature(analytics.FeatureExport)
+		a.ShowTemporaryStatus(i18n.T("Transcript exported"), 2*time.Second)
+	}, a.mainWindow)
+	saveDialog.SetFileName("ramble-" + s.ID + format.Extension())
+	saveDialog.Show()
+}
+
+// exportSession writes the selected session to a file in the session export format
+func (a *App) exportSession() {
+	s := a.currentView().session
+	var data []byte
+	var err error
+	if a.sessionStore != nil {
+		data, err = a.sessionStore.Export(s)
+	} else {
+		data, err = session.ExportJSON(s)
+	}
+	if err != nil {
+		dialog.ShowError(fmt.Errorf(i18n.T("Failed to export session: %v"), err), a.mainWindow)
+		return
+	}
+
+	saveDialog := dialog.NewFileSave(func(writer fyne.URIWriteCloser, err error) {
+		if err != nil {
+			dialog.ShowError(err, a.mainWindow)
+			return
+		}
+		if writer == nil {
+			return // Cancelled
+		}
+		defer writer.Close()
+		if _, err := writer.Write(data); err != nil {
+			dialog.ShowError(fmt.Errorf(i18n.T("Failed to export session: %v"), err), a.mainWindow)
+			return
+		}
+		a.recordFeature(analytics.FeatureExport)
+		a.ShowTemporaryStatus(i18n.T("Session exported"), 2*time.Second)
+	}, a.mainWindow)
+	saveDialog.SetFileName("ramble-" + s.ID + ".json")
+	saveDialog.Show()
+}
+
+// importSession opens a session read from an export file in a new tab
+func (a *App) importSession() {
+	dialog.ShowFileOpen(func(reader fyne.URIReadCloser, err error) {
+		if err != nil {
+			dialog.ShowError(err, a.mainWindow)
+			return
+		}
+		if reader == nil {
+			return // Cancelled
+		}
+		defer reader.Close()
+
+		data, err := io.ReadAll(reader)
+		if err != nil {
+			dialog.ShowError(fmt.Errorf(i18n.T("Failed to read session file: %v"), err), a.mainWindow)
+			return
+		}
+		imported, err := session.ImportJSON(data)
+		if err != nil {
+			dialog.ShowError(fmt.Errorf(i18n.T("Failed to import session: %v"), err), a.mainWindow)
+			return
+		}
+
+		if a.sessionStore != nil {
+			if err := a.sessionStore.Import(imported); err != nil {
+				logger.Warning(logger.CategoryUI, "Failed to save imported session: %v", err)
+			}
+		}
+		a.addView(newSessionView(a, reader.URI().Name(), imported, false))
+		a.recordFeature(analytics.FeatureImport)
+		a.ShowTemporaryStatus(i18n.T("Session imported"), 2*time.Second)
+	}, a.mainWindow)
+}
+
+// SetSegmentFinalizedCallback sets a function called with each segment
+// finalized in the live session
+func (a *App) SetSegmentFinalizedCallback(onSegmentFinalized func(sessionID string, segment session.Segment)) {
+	a.onSegmentFinalized = onSegmentFinalized
+}
+
+// SetRewriteCallbacks sets how finalized segments are improved in the
+// background. takeAudio returns the audio of the segment being finalized, or
+// nil if segments aren't transcribed again; rewrite is called with the
+// segment, the ID of its session and its audio and calls apply with better
+// text, if any.
+func (a *App) SetRewriteCallbacks(takeAudio func() []float32, rewrite func(sessionID string, segment session.Segment, samples []float32, apply func(text string))) {
+	a.onTakeSegmentAudio = takeAudio
+	a.onRewrite = rewrite
+}
+
+// SetKeywordMatcher sets the function returning the watched keywords heard in
+// a finalized segment's text
+func (a *App) SetKeywordMatcher(match func(text string) []string) {
+	a.keywordMatcher = match
+}
+
+// notifyKeywords shows a desktop notification for a segment in which watched
+// keywords were heard
+func (a *App) notifyKeywords(segment session.Segment) {
+	title := i18n.Tf("Heard %s", strings.Join(segment.Tags, ", "))
+	logger.Info(logger.CategoryUI, "%s in segment %d", title, segment.ID)
+	a.fyneApp.SendNotification(fyne.NewNotification(title, a.filterClipboardText(segment.Text)))
+}
+
+// SetTranscribeFileCallback sets the function used to transcribe an audio
+// file. It reports progress as a fraction between 0 and 1 and returns the
+// transcribed segments.
+func (a *App) SetTranscribeFileCallback(onTranscribeFile func(path string, progress func(fraction float64)) ([]session.Segment, error)) {
+	a.onTranscribeFile = onTranscribeFile
+}
+
+// showTranscribeFileDialog asks for an audio file to transcribe in a new session tab
+func (a *App) showTranscribeFileDialog() {
+	if a.onTranscribeFile == nil {
+		a.ShowTemporaryStatus(i18n.T("File transcription is not available"), 2*time.Second)
+		return
+	}
+
+	openDialog := dialog.NewFileOpen(func(reader fyne.URIReadCloser, err error) {
+		if err != nil {
+			dialog.ShowError(err, a.mainWindow)
+			return
+		}
+		if reader == nil {
+			return // Cancelled
+		}
+		reader.Close()
+		a.transcribeFile(reader.URI().Path(), reader.URI().Name())
+	}, a.mainWindow)
+	openDialog.SetFilter(storage.NewExtensionFileFilter([]string{".wav", ".flac"}))
+	openDialog.Show()
+}
+
+// transcribeFile runs a file transcription job in its own session tab while
+// the live session stays usable
+func (a *App) transcribeFile(path, name string) {
+	s := session.New()
+	s.Metadata = map[string]string{"source": path}
+	v := newSessionView(a, name, s, false)
+	v.busy = true
+	v.setProgress(i18n.Tf("Transcribing %s...", name), 0)
+	a.addView(v)
+
+	go func() {
+		segments, err := a.onTranscribeFile(path, func(fraction float64) {
+			v.setProgress(i18n.Tf("Transcribing %s... %d%%", name, int(fraction*100)), fraction)
+		})
+		if err != nil {
+			logger.Error(logger.CategoryUI, "File transcription failed: %v", err)
+			v.finishProgress(i18n.Tf("Transcription failed: %v", err))
+			return
+		}
+
+		for _, segment := range segments {
+			s.AppendSegment(segment)
+		}
+		a.saveView(v)
+		v.rebuild()
+		a.spillView(v)
+		v.finishProgress(i18n.Tf("Transcribed %s (%d segments)", name, len(segments)))
+		a.recordFeature(analytics.FeatureTranscribeFile)
+		a.ShowTemporaryStatus(i18n.T("File transcription finished"), 3*time.Second)
+	}()
+}
+
+// newSegmentCard creates the card widget for a finalized segment of a session.
+// Segments spilled to the session history are not editable and can only be copied
+// and played.
+func (a *App) newSegmentCard(v *sessionView, segment session.Segment, editable bool) *fyne.Container {
+	onSave := func() {
+		a.saveTranscriptionSegment(segment.Text)
+	}
+
+	// Archived recordings can be played back
+	var onPlay func()
+	if segment.Audio != "" {
+		onPlay = func() {
+			a.showAudioPreview(v, segment, editable)
+		}
+	}
+	var onRead func()
+	if a.onReadAloud != nil {
+		onRead = func() {
+			a.readAloud(segment.Text)
+		}
+	}
+	if !editable {
+		return createTranscriptionSegmentCard(segment.Text, segmentHeading(segment), nil, onSave, onPlay, onRead, nil, nil, nil)
+	}
+
+	// Segments can only be re-run if their audio was archived
+	var onRerun func()
+	if segment.Audio != "" && a.onRetranscribe != nil {
+		onRerun = func() {
+			a.showRetranscribeDialog(v, segment)
+		}
+	}
+
+	// The first segment in memory has nothing to merge into
+	var onMerge func()
+	if len(v.segments) > 0 && v.segments[0].ID != segment.ID {
+		onMerge = func() {
+			a.mergeSegment(v, segment.ID)
+		}
+	}
+
+	return createTranscriptionSegmentCard(
+		segment.Text,
+		segmentHeading(segment),
 		func() {
-			// Delete segment
-			a.deleteTranscriptionSegment(finalText)
+			a.deleteTranscriptionSegment(v, segment.ID)
 		},
+		onSave,
+		onPlay,
+		onRead,
+		onRerun,
+		onMerge,
 		func() {
-			// Save segment
-			a.saveTranscriptionSegment(finalText)
+			a.showSplitDialog(v, segment)
 		},
 	)
+}
 
-	// Add the card to the UI container
-	// First, we need to extract the VBox from inside the scroll container
-	scrollContainer := a.finalizedSegmentsContainer.Objects[0].(*container.Scroll)
-	segmentsBox := scrollContainer.Content.(*fyne.Container)
+// segmentHeading is shown above a segment's text: when it was spoken, the
+// language detected and the watched keywords heard in it
+func segmentHeading(segment session.Segment) string {
+	parts := []string{segmentTimeRange(segment)}
+	if parts[0] == "" {
+		parts = nil
+	}
+	if segment.Language != "" {
+		parts = append(parts, "["+strings.ToUpper(segment.Language)+"]")
+	}
+	for _, tag := range segment.Tags {
+		parts = append(parts, "#"+tag)
+	}
+	return strings.Join(parts, "  ")
+}
 
-	// Add the new card to the segments box
-	segmentsBox.Add(segmentCard)
+// segmentTimeRange describes when a segment was spoken, e.g. "10:17:03 - 10:17:41",
+// or returns "" if it isn't known
+func segmentTimeRange(segment session.Segment) string {
+	if segment.StartedAt.IsZero() {
+		return ""
+	}
+	start := segment.StartedAt.Format("15:04:05")
+	if segment.EndedAt.IsZero() {
+		return start
+	}
+	if !sameDay(segment.StartedAt, segment.EndedAt) || !sameDay(segment.StartedAt, time.Now()) {
+		start = segment.StartedAt.Format("2006-01-02 15:04:05")
+	}
+	return start + " - " + segment.EndedAt.Format("15:04:05")
+}
 
-	// Refresh the container to reflect changes
-	segmentsBox.Refresh()
-	scrollContainer.Refresh()
+// sameDay reports whether two times fall on the same calendar day
+func sameDay(a, b time.Time) bool {
+	ay, am, ad := a.Date()
+	by, bm, bd := b.Date()
+	return ay == by && am == bm && ad == bd
+}
 
-	// Also update the classic view transcriptBox
-	if a.transcriptBox.Text == "" || a.transcriptBox.Text == "Your transcription will appear here..." {
-		a.transcriptBox.SetText(finalText)
-	} else {
-		current := a.transcriptBox.Text
-		a.transcriptBox.SetText(current + "\n\n" + finalText)
+// mergeSegment joins a segment onto the one before it
+func (a *App) mergeSegment(v *sessionView, id int) {
+	if _, err := v.session.MergeWithPrevious(id); err != nil {
+		logger.Warning(logger.CategoryUI, "Failed to merge segment %d: %v", id, err)
+		return
 	}
-
-	// Auto-scroll to bottom of the finalized segments
-	scrollContainer.ScrollToBottom()
+	a.saveView(v)
+	v.rebuild()
+	a.recordFeature(analytics.FeatureMerge)
+	a.ShowTemporaryStatus(i18n.T("Segments merged (Ctrl+Z to undo)"), 2*time.Second)
 }
 
-// deleteTranscriptionSegment removes a segment from the finalized segments
-func (a *App) deleteTranscriptionSegment(text string) {
-	// Remove from internal storage
-	for i, segment := range a.finalizedSegmentTexts {
-		if segment == text {
-			a.finalizedSegmentTexts = append(a.finalizedSegmentTexts[:i], a.finalizedSegmentTexts[i+1:]...)
-			break
+// showSplitDialog lets the user place the cursor where a segment should be split
+func (a *App) showSplitDialog(v *sessionView, segment session.Segment) {
+	entry := widget.NewMultiLineEntry()
+	entry.Wrapping = fyne.TextWrapWord
+	entry.SetText(segment.Text)
+	entry.SetMinRowsVisible(6)
+
+	content := container.NewBorder(
+		widget.NewLabel(i18n.T("Place the cursor where the segment should be split.")),
+		nil, nil, nil,
+		entry,
+	)
+
+	d := dialog.NewCustomConfirm(i18n.T("Split Segment"), i18n.T("Split"), i18n.T("Cancel"), content, func(confirmed bool) {
+		if !confirmed {
+			return
 		}
+		// Changes to the text in the dialog are ignored; only the cursor matters
+		offset := cursorOffset(segment.Text, entry.CursorRow, entry.CursorColumn)
+		if _, _, err := v.session.Split(segment.ID, offset); err != nil {
+			if errors.Is(err, session.ErrInvalidSplit) {
+				dialog.ShowInformation(i18n.T("Split Segment"), i18n.T("Place the cursor between two words to split the segment."), a.mainWindow)
+				return
+			}
+			logger.Warning(logger.CategoryUI, "Failed to split segment %d: %v", segment.ID, err)
+			return
+		}
+		a.saveView(v)
+		v.rebuild()
+		a.recordFeature(analytics.FeatureSplit)
+		a.ShowTemporaryStatus(i18n.T("Segment split (Ctrl+Z to undo)"), 2*time.Second)
+	}, a.mainWindow)
+	d.Resize(fyne.NewSize(500, 300))
+	d.Show()
+}
+
+// cursorOffset converts an entry's cursor row and column (in runes) to a byte
+// offset into text
+func cursorOffset(text string, row, column int) int {
+	lines := strings.Split(text, "\n")
+	if row >= len(lines) {
+		return len(text)
 	}
+	offset := 0
+	for _, line := range lines[:row] {
+		offset += len(line) + 1
+	}
+	runes := []rune(lines[row])
+	if column > len(runes) {
+		column = len(runes)
+	}
+	return offset + len(string(runes[:column]))
+}
 
-	// Rebuild the UI container (simpler than trying to find and remove a specific card)
-	scrollContainer := a.finalizedSegmentsContainer.Objects[0].(*container.Scroll)
-	segmentsBox := scrollContainer.Content.(*fyne.Container)
+// SetRetranscribeCallback sets the function used to transcribe a segment's
+// archived audio again with a different model size
+func (a *App) SetRetranscribeCallback(onRetranscribe func(audioPath, modelSize string) (string, error)) {
+	a.onRetranscribe = onRetranscribe
+	for _, v := range a.views {
+		v.rebuild()
+	}
+}
+
+// showRetranscribeDialog asks which model to re-run a segment's audio with and
+// whether the new transcript replaces the segment or is added after it
+func (a *App) showRetranscribeDialog(v *sessionView, segment session.Segment) {
+	if _, err := os.Stat(segment.Audio); err != nil {
+		dialog.ShowError(errors.New(i18n.T("The recorded audio for this segment is no longer available")), a.mainWindow)
+		return
+	}
 
-	// Clear the current segments
-	segmentsBox.Objects = nil
+	modelSelect := widget.NewSelect(modelOptions(), nil)
+	modelSelect.SetSelected(a.currentPreferences.ModelSize)
 
-	// Rebuild with the remaining segments
-	for _, segment := range a.finalizedSegmentTexts {
-		segmentCard := createTranscriptionSegmentCard(
-			segment,
-			func() {
-				a.deleteTranscriptionSegment(segment)
-			},
-			func() {
-				a.saveTranscriptionSegment(segment)
-			},
-		)
-		segmentsBox.Add(segmentCard)
+	replaceOption := i18n.T("Replace this segment")
+	appendOption := i18n.T("Add as a new segment")
+	modeRadio := widget.NewRadioGroup([]string{replaceOption, appendOption}, nil)
+	modeRadio.SetSelected(replaceOption)
+
+	items := []*widget.FormItem{
+		widget.NewFormItem(i18n.T("Model"), modelSelect),
+		widget.NewFormItem(i18n.T("Result"), modeRadio),
 	}
 
-	// Update the classic view transcriptBox
-	a.rebuildClassicViewText()
+	dialog.ShowForm(i18n.T("Re-run with model"), i18n.T("Run"), i18n.T("Cancel"), items, func(confirmed bool) {
+		if !confirmed || modelSelect.Selected == "" {
+			return
+		}
+		a.retranscribeSegment(v, segment, modelSelect.Selected, modeRadio.Selected == replaceOption)
+	}, a.mainWindow)
+}
+
+// retranscribeSegment runs the segment's audio through the chosen model in the
+// background and replaces the segment or inserts the result after it
+func (a *App) retranscribeSegment(v *sessionView, segment session.Segment, modelSize string, replace bool) {
+	a.ShowTemporaryStatus(i18n.Tf("Re-transcribing with %s model...", modelSize), 3*time.Second)
+
+	go func() {
+		text, err := a.onRetranscribe(segment.Audio, modelSize)
+		if err != nil {
+			logger.Error(logger.CategoryUI, "Re-transcription failed: %v", err)
+			dialog.ShowError(fmt.Errorf(i18n.T("Re-transcription failed: %v"), err), a.mainWindow)
+			return
+		}
+		if text == "" {
+			a.ShowTemporaryStatus(i18n.T("No speech found in the recording"), 3*time.Second)
+			return
+		}
+
+		if replace {
+			err = v.session.Update(segment.ID, text)
+		} else {
+			_, err = v.session.InsertAfter(segment.ID, text, segment.Audio)
+		}
+		if err != nil {
+			// The segment was deleted while the model was running
+			logger.Warning(logger.CategoryUI, "Failed to apply re-transcription: %v", err)
+			a.ShowTemporaryStatus(i18n.T("Segment no longer exists"), 3*time.Second)
+			return
+		}
+
+		a.saveView(v)
+		v.rebuild()
+		a.recordFeature(analytics.FeatureRetranscribe)
+		a.ShowTemporaryStatus(i18n.T("Re-transcribed (Ctrl+Z to undo)"), 3*time.Second)
+	}()
+}
+
+// rewriteSegment has the segment's text improved in the background, e.g. by
+// transcribing its audio with a more accurate model, and replaces the live
+// text with the result unless the segment was edited, merged or deleted in
+// the meantime
+func (a *App) rewriteSegment(v *sessionView, segment session.Segment, samples []float32) {
+	a.onRewrite(v.session.ID, segment, samples, func(text string) {
+		if text == "" || text == segment.Text {
+			return
+		}
+		if err := v.session.Revise(segment.ID, segment.Text, text); err != nil {
+			logger.Debug(logger.CategoryUI, "Not rewriting segment %d: %v", segment.ID, err)
+			return
+		}
+		a.saveView(v)
+		v.rebuild()
+	})
 }
 
 // saveTranscriptionSegment saves a segment for later use
 func (a *App) saveTranscriptionSegment(text string) {
 	// Implement the save functionality (e.g., to a file or clipboard)
-	clipboard.SetText(text)
+	clipboard.SetText(a.filterClipboardText(text))
 
 	// Show a temporary status message
-	a.ShowTemporaryStatus("Segment saved to clipboard", 2*time.Second)
-}
-
-// rebuildClassicViewText rebuilds the classic view text from the finalized segments
-func (a *App) rebuildClassicViewText() {
-	if len(a.finalizedSegmentTexts) == 0 {
-		a.transcriptBox.SetText("")
-		return
-	}
-
-	text := strings.Join(a.finalizedSegmentTexts, "\n\n")
-	a.transcriptBox.SetText(text)
+	a.ShowTemporaryStatus(i18n.T("Segment saved to clipboard"), 2*time.Second)
 }
 
 // ProcessStreamingTranscription handles incoming transcription text in the two-stage process
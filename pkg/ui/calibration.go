@@ -0,0 +1,122 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/jeff-barlow-spady/ramble/pkg/audio"
+	"github.com/jeff-barlow-spady/ramble/pkg/i18n"
+)
+
+// meterFloorDB is the level shown as an empty meter
+const meterFloorDB = -60.0
+
+// SetCalibrationCallbacks sets the functions used by the microphone calibration
+// view. start begins capturing and reports levels until stop is called;
+// setGain changes the input gain while calibrating.
+func (a *App) SetCalibrationCallbacks(start func(onReading func(audio.Reading)) error, stop func(), setGain func(gain float64)) {
+	a.onStartCalibration = start
+	a.onStopCalibration = stop
+	a.onGainChanged = setGain
+}
+
+// showCalibration shows live input levels so the user can check their
+// microphone and adjust the input gain, which is saved with the preferences
+func (d *PreferencesDialog) showCalibration(onGain func(gain float64)) {
+	a := d.app
+	if a.onStartCalibration == nil {
+		dialog.ShowError(errors.New(i18n.T("Microphone calibration is not available")), d.window)
+		return
+	}
+
+	device := d.prefs.InputDevice
+	if device == "" {
+		device = i18n.T("Default input")
+	}
+
+	rmsBar := newLevelBar()
+	peakBar := newLevelBar()
+	statusLabel := widget.NewLabel(i18n.T("Waiting for audio..."))
+	statusLabel.Wrapping = fyne.TextWrapWord
+
+	gainLabel := widget.NewLabel("")
+	showGain := func(gain float64) {
+		gainLabel.SetText(fmt.Sprintf("%.1fx (%+.1f dB)", gain, 20*math.Log10(math.Max(gain, 0.01))))
+	}
+	gainSlider := widget.NewSlider(0.1, audio.MaxGain)
+	gainSlider.Step = 0.1
+	gainSlider.SetValue(d.prefs.InputGain)
+	showGain(d.prefs.InputGain)
+	gainSlider.OnChanged = func(gain float64) {
+		showGain(gain)
+		onGain(gain)
+		if a.onGainChanged != nil {
+			a.onGainChanged(gain)
+		}
+	}
+
+	w := a.fyneApp.NewWindow(i18n.T("Microphone Calibration"))
+	w.Resize(fyne.NewSize(420, 320))
+
+	err := a.onStartCalibration(func(r audio.Reading) {
+		rmsBar.SetValue(meterValue(r.RMS))
+		peakBar.SetValue(meterValue(r.Peak))
+		switch r.Status {
+		case audio.LevelClipping:
+			statusLabel.SetText(i18n.T("Clipping: the signal is too loud and distorts. Lower the gain or the microphone volume."))
+		case audio.LevelTooQuiet:
+			statusLabel.SetText(i18n.T("Too quiet: speak normally, then raise the gain or the microphone volume if this stays."))
+		default:
+			statusLabel.SetText(i18n.T("Level is good."))
+		}
+	})
+	if err != nil {
+		dialog.ShowError(fmt.Errorf(i18n.T("Failed to start calibration: %w"), err), d.window)
+		return
+	}
+
+	w.SetOnClosed(func() {
+		if a.onStopCalibration != nil {
+			a.onStopCalibration()
+		}
+	})
+
+	w.SetContent(container.NewVBox(
+		widget.NewLabelWithStyle(i18n.Tf("Microphone: %s", device), fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		widget.NewLabel(i18n.T("Speak at your usual volume and distance from the microphone.")),
+		widget.NewForm(
+			widget.NewFormItem(i18n.T("Level (RMS)"), rmsBar),
+			widget.NewFormItem(i18n.T("Peak"), peakBar),
+		),
+		statusLabel,
+		widget.NewSeparator(),
+		widget.NewForm(
+			widget.NewFormItem(i18n.T("Input gain"), container.NewBorder(nil, nil, nil, gainLabel, gainSlider)),
+		),
+		widget.NewButton(i18n.T("Done"), w.Close),
+	))
+	w.Show()
+}
+
+// newLevelBar creates a meter that labels its value in dBFS
+func newLevelBar() *widget.ProgressBar {
+	bar := widget.NewProgressBar()
+	bar.TextFormatter = func() string {
+		if bar.Value <= 0 {
+			return "silent"
+		}
+		return fmt.Sprintf("%.0f dBFS", meterFloorDB*(1-bar.Value))
+	}
+	return bar
+}
+
+// meterValue maps a level onto a meter from meterFloorDB to full scale
+func meterValue(level float32) float64 {
+	return math.Max(0, math.Min(1, 1-audio.DBFS(level)/meterFloorDB))
+}
@@ -0,0 +1,80 @@
+package ui
+
+import (
+	"time"
+
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/jeff-barlow-spady/ramble/pkg/i18n"
+	"github.com/jeff-barlow-spady/ramble/pkg/logger"
+)
+
+// SetCommandCallback sets the function that runs the voice command for a
+// phrase heard in command mode. It is called in the background and returns a
+// description of what it ran.
+func (a *App) SetCommandCallback(onCommand func(text string) (string, error)) {
+	a.onCommand = onCommand
+}
+
+// CommandMode reports whether phrases heard run voice commands instead of
+// being added to the transcript. It is safe to call from any goroutine.
+func (a *App) CommandMode() bool {
+	return a.commandMode.Load()
+}
+
+// toggleCommandMode switches between dictation and command mode
+func (a *App) toggleCommandMode() {
+	if a.onCommand == nil {
+		a.ShowTemporaryStatus(i18n.T("Voice commands are not available"), 2*time.Second)
+		return
+	}
+
+	enabled := !a.commandMode.Load()
+	a.SetCommandMode(enabled)
+	if enabled {
+		a.ShowTemporaryStatus(i18n.T("Command mode: phrases run commands"), 2*time.Second)
+	} else {
+		a.ShowTemporaryStatus(i18n.T("Dictation mode"), 2*time.Second)
+	}
+}
+
+// SetCommandMode switches to command mode if enabled, or else to dictation.
+// Command mode needs the callback set by SetCommandCallback.
+func (a *App) SetCommandMode(enabled bool) {
+	enabled = enabled && a.onCommand != nil
+	a.commandMode.Store(enabled)
+	logger.Info(logger.CategoryUI, "Command mode enabled: %v", enabled)
+
+	if a.commandButton != nil {
+		if enabled {
+			a.commandButton.SetText(i18n.T("Commands"))
+			a.commandButton.Importance = widget.WarningImportance
+		} else {
+			a.commandButton.SetText(i18n.T("Dictation"))
+			a.commandButton.Importance = widget.MediumImportance
+		}
+		a.commandButton.Refresh()
+	}
+}
+
+// newCommandButton creates the toolbar button switching between dictation and
+// command mode
+func (a *App) newCommandButton() *widget.Button {
+	a.commandButton = widget.NewButtonWithIcon(i18n.T("Dictation"), theme.ComputerIcon(), a.toggleCommandMode)
+	return a.commandButton
+}
+
+// runVoiceCommand runs the command for a phrase heard in command mode
+func (a *App) runVoiceCommand(text string) {
+	go func() {
+		ran, err := a.onCommand(text)
+		if err != nil {
+			logger.Warning(logger.CategoryUI, "Voice command %q failed: %v", text, err)
+			a.ShowTemporaryStatus(i18n.Tf("Command failed: %v", err), 3*time.Second)
+			return
+		}
+		logger.Info(logger.CategoryUI, "Voice command %q ran %s", text, ran)
+		a.ShowTemporaryStatus(i18n.Tf("Ran %s", ran), 2*time.Second)
+	}()
+}
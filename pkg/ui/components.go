@@ -10,6 +10,8 @@ import (
 	"fyne.io/fyne/v2/layout"
 	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
+
+	"github.com/jeff-barlow-spady/ramble/pkg/i18n"
 )
 
 // UIComponents holds all the UI components
@@ -34,7 +36,7 @@ func createTranscriptArea() *widget.Entry {
 	transcript.Wrapping = fyne.TextWrapWord
 
 	// Use clear placeholder text with high contrast
-	transcript.SetPlaceHolder("Transcribed text will appear here (waiting for speech)...")
+	transcript.SetPlaceHolder(i18n.T("Transcribed text will appear here (waiting for speech)..."))
 
 	// Use styling for better visibility - bold is set in the TextStyle struct
 	transcript.TextStyle = fyne.TextStyle{
@@ -46,7 +48,7 @@ func createTranscriptArea() *widget.Entry {
 	// with larger text size in theme.go (1.2x normal size)
 
 	// Set initial text to make sure it's working
-	transcript.SetText("Ready for transcription. Press Record to start.")
+	transcript.SetText(i18n.T("Ready for transcription. Press Record to start."))
 
 	// Set read-only to false to allow text selection and copying
 	// This improves usability while still preventing user editing
@@ -64,10 +66,10 @@ func createControlPanel(
 	onInsert func(),
 ) (*fyne.Container, *widget.Button, *widget.Button, *widget.Button, *widget.Button) {
 	// Create more subtle buttons with icons but less prominent styling
-	listenButton := widget.NewButtonWithIcon("Record", theme.MediaRecordIcon(), onListen)
-	clearButton := widget.NewButtonWithIcon("Clear", theme.ContentClearIcon(), onClear)
-	copyButton := widget.NewButtonWithIcon("Copy", theme.ContentCopyIcon(), onCopy)
-	insertButton := widget.NewButtonWithIcon("Insert at Cursor", theme.ContentPasteIcon(), onInsert)
+	listenButton := widget.NewButtonWithIcon(i18n.T("Record"), theme.MediaRecordIcon(), onListen)
+	clearButton := widget.NewButtonWithIcon(i18n.T("Clear"), theme.ContentClearIcon(), onClear)
+	copyButton := widget.NewButtonWithIcon(i18n.T("Copy"), theme.ContentCopyIcon(), onCopy)
+	insertButton := widget.NewButtonWithIcon(i18n.T("Insert at Cursor"), theme.ContentPasteIcon(), onInsert)
 
 	// Create vertical button stack with subtle separators and more compact spacing
 	controlPanel := container.NewVBox(
@@ -90,7 +92,7 @@ func createControlPanel(
 // createStatusBar creates the status bar with audio visualization
 func createStatusBar(waveform *WaveformVisualizer) (*fyne.Container, *canvas.Text) {
 	// Create status label with improved style but less prominent
-	statusLabel := canvas.NewText("Ready", color.NRGBA{R: 220, G: 220, B: 220, A: 255})
+	statusLabel := canvas.NewText(i18n.T("Ready"), color.NRGBA{R: 220, G: 220, B: 220, A: 255})
 	statusLabel.TextSize = 14
 	statusLabel.Alignment = fyne.TextAlignCenter
 
@@ -136,7 +138,7 @@ func createStreamingPreviewArea() *widget.Entry {
 	preview.Wrapping = fyne.TextWrapWord
 
 	// Use clear placeholder text with high contrast
-	preview.SetPlaceHolder("Live transcription will appear here...")
+	preview.SetPlaceHolder(i18n.T("Live transcription will appear here..."))
 
 	// Use styling for better visibility
 	preview.TextStyle = fyne.TextStyle{
@@ -147,7 +149,7 @@ func createStreamingPreviewArea() *widget.Entry {
 	// The actual text size comes from the theme
 
 	// Set initial text
-	preview.SetText("Waiting for speech...")
+	preview.SetText(i18n.T("Waiting for speech..."))
 
 	// Make read-only but still allow selection
 	preview.DisableableWidget.Disable()
@@ -168,7 +170,9 @@ func createFinalizedSegmentsContainer() *fyne.Container {
 }
 
 // createTranscriptionSegmentCard creates an individual card for a finalized transcription segment
-func createTranscriptionSegmentCard(text string, onDelete func(), onSave func()) *fyne.Container {
+// onDelete, onPlay, onRead, onRerun, onMerge and onSplit are optional; their buttons are only shown when set.
+// timeRange is shown above the text unless it is empty.
+func createTranscriptionSegmentCard(text, timeRange string, onDelete, onSave, onPlay, onRead, onRerun, onMerge, onSplit func()) *fyne.Container {
 	// Create the text display with better styling
 	textLabel := widget.NewLabel(text)
 	textLabel.Wrapping = fyne.TextWrapWord
@@ -176,20 +180,52 @@ func createTranscriptionSegmentCard(text string, onDelete func(), onSave func())
 		Bold: true, // Make text bold for better readability
 	}
 
+	// When the segment was spoken, if known
+	timeLabel := widget.NewLabel(timeRange)
+	timeLabel.TextStyle = fyne.TextStyle{Italic: true}
+	if timeRange == "" {
+		timeLabel.Hide()
+	}
+
 	// Create action buttons with clearer labels and larger size
-	deleteButton := widget.NewButtonWithIcon("Delete", theme.DeleteIcon(), onDelete)
+	deleteButton := widget.NewButtonWithIcon(i18n.T("Delete"), theme.DeleteIcon(), onDelete)
 	deleteButton.Importance = widget.WarningImportance
 
-	saveButton := widget.NewButtonWithIcon("Copy", theme.ContentCopyIcon(), onSave)
+	saveButton := widget.NewButtonWithIcon(i18n.T("Copy"), theme.ContentCopyIcon(), onSave)
 	saveButton.Importance = widget.HighImportance
 
 	// Create a horizontal container for buttons with better spacing
-	buttonContainer := container.NewHBox(
-		layout.NewSpacer(),
-		saveButton,
-		container.NewPadded(widget.NewSeparator()),
-		deleteButton,
-	)
+	buttonContainer := container.NewHBox(layout.NewSpacer())
+	if onMerge != nil {
+		buttonContainer.Add(widget.NewButtonWithIcon(i18n.T("Merge with previous"), theme.MoveUpIcon(), onMerge))
+	}
+	if onSplit != nil {
+		buttonContainer.Add(widget.NewButtonWithIcon(i18n.T("Split..."), theme.ContentCutIcon(), onSplit))
+	}
+	if onMerge != nil || onSplit != nil {
+		buttonContainer.Add(container.NewPadded(widget.NewSeparator()))
+	}
+	if onPlay != nil {
+		buttonContainer.Add(widget.NewButtonWithIcon(i18n.T("Play"), theme.MediaPlayIcon(), onPlay))
+	}
+	if onRead != nil {
+		buttonContainer.Add(widget.NewButtonWithIcon(i18n.T("Read Aloud"), theme.VolumeUpIcon(), onRead))
+	}
+	if onPlay != nil || onRead != nil {
+		if onRerun == nil {
+			buttonContainer.Add(container.NewPadded(widget.NewSeparator()))
+		}
+	}
+	if onRerun != nil {
+		rerunButton := widget.NewButtonWithIcon(i18n.T("Re-run with model..."), theme.ViewRefreshIcon(), onRerun)
+		buttonContainer.Add(rerunButton)
+		buttonContainer.Add(container.NewPadded(widget.NewSeparator()))
+	}
+	buttonContainer.Add(saveButton)
+	if onDelete != nil {
+		buttonContainer.Add(container.NewPadded(widget.NewSeparator()))
+		buttonContainer.Add(deleteButton)
+	}
 
 	// Create a card with a border and background that's more visually distinct
 	background := canvas.NewRectangle(color.NRGBA{R: 40, G: 50, B: 80, A: 255})
@@ -199,6 +235,7 @@ func createTranscriptionSegmentCard(text string, onDelete func(), onSave func())
 
 	// Create the content with padding
 	content := container.NewVBox(
+		timeLabel,
 		container.NewPadded(textLabel),
 		widget.NewSeparator(), // Add a separator between text and buttons
 		buttonContainer,
@@ -227,7 +264,7 @@ func createMainUI(
 
 	// Create a tabbed container to separate the different views
 	tabs := container.NewAppTabs(
-		container.NewTabItem("Two-Stage View",
+		container.NewTabItem(i18n.T("Two-Stage View"),
 			container.NewBorder(
 				nil,
 				statusBar,
@@ -239,7 +276,7 @@ func createMainUI(
 				),
 			),
 		),
-		container.NewTabItem("Classic View",
+		container.NewTabItem(i18n.T("Classic View"),
 			container.NewBorder(
 				nil,
 				statusBar,
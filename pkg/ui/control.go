@@ -0,0 +1,49 @@
+package ui
+
+import (
+	"errors"
+	"time"
+
+	"fyne.io/fyne/v2"
+
+	"github.com/jeff-barlow-spady/ramble/pkg/clipboard"
+	"github.com/jeff-barlow-spady/ramble/pkg/i18n"
+)
+
+// ShowMainWindow shows and focuses the main window, leaving the hover window
+func (a *App) ShowMainWindow() {
+	a.showMainWindow()
+}
+
+// ToggleListening starts or stops recording as the record button does
+func (a *App) ToggleListening() {
+	a.toggleListening()
+}
+
+// StopForSilence stops a recording in which no speech was heard for the
+// given number of minutes, telling the user with a desktop notification in
+// case Ramble is hidden
+func (a *App) StopForSilence(minutes int) {
+	if !isRecordingState(a.state) {
+		return
+	}
+	a.SetListening(false)
+	message := i18n.Tf("No speech was heard for %d minutes.", minutes)
+	a.ShowTemporaryStatus(i18n.T("Recording stopped after silence"), 5*time.Second)
+	a.fyneApp.SendNotification(fyne.NewNotification(i18n.T("Recording stopped"), message))
+}
+
+// CopyLastSegment copies the newest segment of the live session to the
+// clipboard and returns the text copied
+func (a *App) CopyLastSegment() (string, error) {
+	texts := a.live.session.Texts()
+	if len(texts) == 0 {
+		return "", errors.New("no segment to copy")
+	}
+	text := a.filterClipboardText(texts[len(texts)-1])
+	if err := clipboard.SetText(text); err != nil {
+		return "", err
+	}
+	a.ShowTemporaryStatus(i18n.T("Copied to clipboard"), 2*time.Second)
+	return text, nil
+}
@@ -6,6 +6,7 @@ import (
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/widget"
 	"github.com/getlantern/systray"
+	"github.com/jeff-barlow-spady/ramble/pkg/i18n"
 	"github.com/jeff-barlow-spady/ramble/pkg/resources"
 )
 
@@ -34,7 +35,7 @@ func NewEssentialUI() *EssentialUI {
 		window:       w,
 		currentText:  widget.NewEntry(),
 		historyList:  history,
-		statusLabel:  widget.NewLabel("Ready"),
+		statusLabel:  widget.NewLabel(i18n.T("Ready")),
 		transcriptCh: make(chan string, 100),
 	}
 }
@@ -86,12 +87,12 @@ func setupSystray(window fyne.Window) {
 			// Set up systray
 			systray.SetIcon(iconData)
 			systray.SetTitle("Ramble")
-			systray.SetTooltip("Ramble Speech-to-Text")
+			systray.SetTooltip(i18n.T("Ramble Speech-to-Text"))
 
 			// Add menu items
-			mShow := systray.AddMenuItem("Show Window", "Show the main window")
+			mShow := systray.AddMenuItem(i18n.T("Show Window"), i18n.T("Show the main window"))
 			systray.AddSeparator()
-			mQuit := systray.AddMenuItem("Quit", "Quit the application")
+			mQuit := systray.AddMenuItem(i18n.T("Quit"), i18n.T("Quit the application"))
 
 			// Handle menu item clicks
 			go func() {
@@ -15,6 +15,7 @@ import (
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/widget"
+	"github.com/jeff-barlow-spady/ramble/pkg/i18n"
 	logger "github.com/jeff-barlow-spady/ramble/pkg/logger"
 )
 
@@ -150,12 +151,12 @@ func (s *GUIExecutableSelector) SelectExecutable(executables []string) (string,
 
 		// Create dialog
 		content := container.NewVBox(
-			widget.NewLabel("Multiple Whisper executables found. Please select one:"),
+			widget.NewLabel(i18n.T("Multiple Whisper executables found. Please select one:")),
 			radio,
 		)
 
 		// Use a custom dialog for better control
-		dlg := dialog.NewCustom("Select Whisper Executable", "OK", content, s.parentWindow)
+		dlg := dialog.NewCustom(i18n.T("Select Whisper Executable"), i18n.T("OK"), content, s.parentWindow)
 
 		// Set up the callback for when the dialog is dismissed
 		dlg.SetOnClosed(func() {
@@ -0,0 +1,86 @@
+package ui
+
+import (
+	"sync"
+	"time"
+
+	"github.com/jeff-barlow-spady/ramble/pkg/logger"
+)
+
+// Frontend shows recording and transcription to the user and lets them start
+// and stop it. The Fyne App, the terminal UI and NullFrontend implement it,
+// so a recording pipeline can run behind any of them.
+type Frontend interface {
+	// SetCallbacks sets the functions called when the user starts or stops
+	// recording, or clears the transcript
+	SetCallbacks(onStart, onStop, onClear func())
+	// SetState shows whether Ramble is recording, loading, etc.
+	SetState(state AppState)
+	// UpdateAudioLevel shows the level of the audio being recorded
+	UpdateAudioLevel(level float32)
+	// UpdateStreamingPreview shows text transcribed live, not yet part of the transcript
+	UpdateStreamingPreview(text string)
+	// UpdateTranscript replaces the transcript shown
+	UpdateTranscript(text string)
+	// ShowTemporaryStatus shows a message for duration
+	ShowTemporaryStatus(message string, duration time.Duration)
+	// ShowErrorDialog tells the user something failed
+	ShowErrorDialog(title, message string)
+	// Run shows the frontend and blocks until the user quits
+	Run()
+}
+
+var (
+	_ Frontend = (*App)(nil)
+	_ Frontend = (*TerminalUI)(nil)
+	_ Frontend = (*NullFrontend)(nil)
+)
+
+// NullFrontend shows nothing, for running without a user interface, e.g. as a
+// server. Status messages and errors are logged, and Run blocks until Quit.
+type NullFrontend struct {
+	quit chan struct{}
+	once sync.Once
+}
+
+// NewNullFrontend creates a frontend that shows nothing
+func NewNullFrontend() *NullFrontend {
+	return &NullFrontend{quit: make(chan struct{})}
+}
+
+// SetCallbacks does nothing; there are no buttons to press
+func (n *NullFrontend) SetCallbacks(onStart, onStop, onClear func()) {}
+
+// SetState logs the state
+func (n *NullFrontend) SetState(state AppState) {
+	logger.Debug(logger.CategoryUI, "State changed to %d", state)
+}
+
+// UpdateAudioLevel does nothing
+func (n *NullFrontend) UpdateAudioLevel(level float32) {}
+
+// UpdateStreamingPreview does nothing
+func (n *NullFrontend) UpdateStreamingPreview(text string) {}
+
+// UpdateTranscript does nothing
+func (n *NullFrontend) UpdateTranscript(text string) {}
+
+// ShowTemporaryStatus logs the message
+func (n *NullFrontend) ShowTemporaryStatus(message string, duration time.Duration) {
+	logger.Info(logger.CategoryUI, "%s", message)
+}
+
+// ShowErrorDialog logs the error
+func (n *NullFrontend) ShowErrorDialog(title, message string) {
+	logger.Error(logger.CategoryUI, "%s: %s", title, message)
+}
+
+// Run blocks until Quit is called
+func (n *NullFrontend) Run() {
+	<-n.quit
+}
+
+// Quit ends Run
+func (n *NullFrontend) Quit() {
+	n.once.Do(func() { close(n.quit) })
+}
@@ -0,0 +1,45 @@
+package ui
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNullFrontendRunsUntilQuit(t *testing.T) {
+	frontend := NewNullFrontend()
+	done := make(chan struct{})
+	go func() {
+		frontend.Run()
+		close(done)
+	}()
+
+	frontend.SetState(StateListening)
+	frontend.ShowTemporaryStatus("Recording", time.Second)
+	select {
+	case <-done:
+		t.Fatal("Expected Run to block until Quit")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	frontend.Quit()
+	frontend.Quit()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Error("Expected Quit to end Run")
+	}
+}
+
+func TestTerminalStatus(t *testing.T) {
+	model := NewTerminalModel("")
+	model.SetRecordingState(true)
+	model.SetStatus("Copied")
+	model.clearStatus("Something else")
+	if model.statusMessage != "Copied" {
+		t.Errorf("Expected a newer status to be kept, got %q", model.statusMessage)
+	}
+	model.clearStatus("Copied")
+	if model.statusMessage != "Recording..." {
+		t.Errorf("Expected the recording state to be shown again, got %q", model.statusMessage)
+	}
+}
@@ -4,6 +4,7 @@ package ui
 import (
 	"image/color"
 	"strings"
+	"sync"
 	"time"
 
 	"fyne.io/fyne/v2"
@@ -12,8 +13,19 @@ import (
 	"fyne.io/fyne/v2/layout"
 	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
+
+	"github.com/jeff-barlow-spady/ramble/pkg/i18n"
+	"github.com/jeff-barlow-spady/ramble/pkg/logger"
+	"github.com/jeff-barlow-spady/ramble/pkg/textproc"
 )
 
+// hoverWindowTitle is how the window manager finds the hover window
+const hoverWindowTitle = "Ramble Transcription"
+
+// hintDelay gives the window manager time to map a newly shown window
+// before hints are applied to it
+const hintDelay = 300 * time.Millisecond
+
 // HoverWindow represents a compact floating window for transcription
 type HoverWindow struct {
 	window             fyne.Window
@@ -21,35 +33,41 @@ type HoverWindow struct {
 	waveform           *WaveformVisualizer
 	recordButton       *widget.Button
 	copyButton         *widget.Button
+	pinButton          *widget.Button
 	closeButton        *widget.Button
 	statusLabel        *canvas.Text
 	isRecording        bool
+	visible            bool
+	settings           HoverSettings
+	hintMu             sync.Mutex
+	hintError          string // Last error applying settings, reported once
 	onRecordToggle     func()
 	onCopy             func()
 	onClose            func()
+	onPin              func(onTop bool)
 	defaultX, defaultY int
 }
 
 // NewHoverWindow creates a new compact hover window
 func NewHoverWindow(app fyne.App) *HoverWindow {
 	// Create a new window that will float above others
-	window := app.NewWindow("Ramble Transcription")
-	window.SetCloseIntercept(func() {
-		// Hide instead of close when 'X' is clicked
-		window.Hide()
-	})
+	window := app.NewWindow(hoverWindowTitle)
 
 	// Set a more compact size for the hover window
-	window.Resize(fyne.NewSize(300, 200))
+	window.Resize(fyne.NewSize(defaultHoverWidth, defaultHoverHeight))
 
 	// Create a new hover window instance
 	hw := &HoverWindow{
 		window:      window,
 		isRecording: false,
+		settings:    HoverSettings{OnTop: true, Width: defaultHoverWidth, Height: defaultHoverHeight, Opacity: 1},
 		defaultX:    100,
 		defaultY:    100,
 	}
 
+	// Hide instead of close when 'X' is clicked
+	window.SetCloseIntercept(hw.Hide)
+
 	// Initialize UI components
 	hw.createUI()
 
@@ -64,7 +82,7 @@ func (hw *HoverWindow) createUI() {
 	// Create the transcript text area (read-only)
 	hw.transcriptBox = widget.NewMultiLineEntry()
 	hw.transcriptBox.Disable() // Makes it read-only
-	hw.transcriptBox.SetText("Ready for transcription...")
+	hw.transcriptBox.SetText(i18n.T("Ready for transcription..."))
 	hw.transcriptBox.Wrapping = fyne.TextWrapWord
 
 	// Make the font size smaller for the compact view
@@ -76,7 +94,7 @@ func (hw *HoverWindow) createUI() {
 	hw.waveform.SetAmplitude(0.1) // Small initial amplitude
 
 	// Create the status label - smaller font
-	hw.statusLabel = canvas.NewText("Ready", color.NRGBA{R: 150, G: 150, B: 150, A: 255})
+	hw.statusLabel = canvas.NewText(i18n.T("Ready"), color.NRGBA{R: 150, G: 150, B: 150, A: 255})
 	hw.statusLabel.TextSize = 10
 
 	// Create control buttons - more compact with icons only
@@ -92,6 +110,17 @@ func (hw *HoverWindow) createUI() {
 		}
 	})
 
+	// Pinning keeps the window above the application being dictated into
+	hw.pinButton = widget.NewButtonWithIcon("", theme.MoveUpIcon(), func() {
+		hw.settings.OnTop = !hw.settings.OnTop
+		hw.refreshPinButton()
+		hw.applyHints()
+		if hw.onPin != nil {
+			hw.onPin(hw.settings.OnTop)
+		}
+	})
+	hw.refreshPinButton()
+
 	hw.closeButton = widget.NewButtonWithIcon("", theme.CancelIcon(), func() {
 		hw.Hide()
 		if hw.onClose != nil {
@@ -103,6 +132,7 @@ func (hw *HoverWindow) createUI() {
 	buttonSize := fyne.NewSize(24, 24)
 	hw.recordButton.Resize(buttonSize)
 	hw.copyButton.Resize(buttonSize)
+	hw.pinButton.Resize(buttonSize)
 	hw.closeButton.Resize(buttonSize)
 
 	// Create a compact button bar
@@ -111,6 +141,7 @@ func (hw *HoverWindow) createUI() {
 		layout.NewSpacer(),
 		hw.copyButton,
 		layout.NewSpacer(),
+		hw.pinButton,
 		hw.closeButton,
 	)
 
@@ -140,17 +171,80 @@ func (hw *HoverWindow) createUI() {
 // Show displays the hover window
 func (hw *HoverWindow) Show() {
 	hw.window.Show()
+	hw.visible = true
+	hw.applyHints()
+}
+
+// ApplySettings resizes the window and asks the window manager to keep it on
+// top, make it translucent and let clicks through as settings say
+func (hw *HoverWindow) ApplySettings(settings HoverSettings) {
+	if settings.Width <= 0 || settings.Height <= 0 {
+		settings.Width, settings.Height = defaultHoverWidth, defaultHoverHeight
+	}
+	hw.settings = settings
+	hw.window.Resize(fyne.NewSize(settings.Width, settings.Height))
+	hw.refreshPinButton()
+	if hw.visible {
+		hw.applyHints()
+	}
+}
+
+// Size returns the size of the window, which the user may have changed
+func (hw *HoverWindow) Size() fyne.Size {
+	return hw.window.Canvas().Size()
+}
+
+// SetPinCallback sets the function called when the pin button keeps the
+// window on top or stops doing so
+func (hw *HoverWindow) SetPinCallback(onPin func(onTop bool)) {
+	hw.onPin = onPin
+}
+
+// refreshPinButton highlights the pin button while the window is kept on top
+func (hw *HoverWindow) refreshPinButton() {
+	if hw.pinButton == nil {
+		return
+	}
+	if hw.settings.OnTop {
+		hw.pinButton.Importance = widget.HighImportance
+	} else {
+		hw.pinButton.Importance = widget.MediumImportance
+	}
+	hw.pinButton.Refresh()
+}
+
+// applyHints passes the window settings to the window manager in the
+// background, once the window is mapped
+func (hw *HoverWindow) applyHints() {
+	settings := hw.settings
+	go func() {
+		time.Sleep(hintDelay)
+		err := applyWindowHints(hoverWindowTitle, settings)
+		if err == nil {
+			return
+		}
+		hw.hintMu.Lock()
+		reported := err.Error() == hw.hintError
+		hw.hintError = err.Error()
+		hw.hintMu.Unlock()
+		if reported {
+			return
+		}
+		logger.Warning(logger.CategoryUI, "Hover window settings not fully applied: %v", err)
+		hw.ShowTemporaryStatus(i18n.T("Some window settings are unsupported here"), 3*time.Second)
+	}()
 }
 
 // Hide hides the hover window
 func (hw *HoverWindow) Hide() {
 	hw.window.Hide()
+	hw.visible = false
 }
 
 // UpdateTranscript updates the transcript text
 func (hw *HoverWindow) UpdateTranscript(text string) {
 	if text == "" {
-		hw.transcriptBox.SetText("Ready for transcription...")
+		hw.transcriptBox.SetText(i18n.T("Ready for transcription..."))
 	} else {
 		hw.transcriptBox.SetText(text)
 	}
@@ -170,31 +264,18 @@ func (hw *HoverWindow) AppendTranscript(text string) {
 	current := hw.transcriptBox.Text
 
 	// Replace the placeholder if it's the initial text
-	if current == "Ready for transcription..." {
+	if current == i18n.T("Ready for transcription...") {
 		hw.transcriptBox.SetText(trimmedText)
 		return
 	}
 
-	// Otherwise append with proper spacing
+	// Otherwise start a new paragraph for a capitalized sentence after a
+	// finished one, and join the text to the sentence anywhere else
 	var newText string
-
-	// Check if we need a new paragraph, space, or nothing
-	if strings.HasSuffix(current, ".") || strings.HasSuffix(current, "!") ||
-		strings.HasSuffix(current, "?") || strings.HasSuffix(current, "\n") {
-		// After end of sentence
-		if len(trimmedText) > 0 && trimmedText[0] >= 'A' && trimmedText[0] <= 'Z' {
-			// New paragraph for capitalized text after sentence
-			newText = current + "\n\n" + trimmedText
-		} else {
-			// Just space after sentence
-			newText = current + " " + trimmedText
-		}
-	} else if !strings.HasSuffix(current, " ") && !strings.HasPrefix(trimmedText, " ") {
-		// Add space between words
-		newText = current + " " + trimmedText
+	if textproc.EndsSentence(current) && textproc.StartsSentence(trimmedText) {
+		newText = current + "\n\n" + trimmedText
 	} else {
-		// No additional space needed
-		newText = current + trimmedText
+		newText = textproc.Join(current, trimmedText)
 	}
 
 	hw.transcriptBox.SetText(newText)
@@ -210,11 +291,11 @@ func (hw *HoverWindow) SetRecordingState(isRecording bool) {
 	hw.isRecording = isRecording
 	if isRecording {
 		hw.recordButton.SetIcon(theme.MediaStopIcon())
-		hw.statusLabel.Text = "Recording..."
+		hw.statusLabel.Text = i18n.T("Recording...")
 		hw.statusLabel.Color = color.RGBA{R: 255, G: 0, B: 0, A: 255}
 	} else {
 		hw.recordButton.SetIcon(theme.MediaRecordIcon())
-		hw.statusLabel.Text = "Ready"
+		hw.statusLabel.Text = i18n.T("Ready")
 		hw.statusLabel.Color = color.NRGBA{R: 150, G: 150, B: 150, A: 255}
 	}
 	hw.statusLabel.Refresh()
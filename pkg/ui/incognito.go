@@ -0,0 +1,96 @@
+package ui
+
+import (
+	"image/color"
+	"time"
+
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/jeff-barlow-spady/ramble/pkg/i18n"
+	"github.com/jeff-barlow-spady/ramble/pkg/logger"
+	"github.com/jeff-barlow-spady/ramble/pkg/output"
+	"github.com/jeff-barlow-spady/ramble/pkg/session"
+)
+
+// incognitoColor marks everything that shows incognito dictation is on
+var incognitoColor = color.NRGBA{R: 190, G: 120, B: 255, A: 255}
+
+// SetPrivacy sets the incognito switch shared with the outputs. Without it
+// incognito dictation is not available.
+func (a *App) SetPrivacy(privacy *output.Privacy) {
+	a.privacy = privacy
+}
+
+// toggleIncognito turns incognito dictation on or off. Each switch starts a
+// new live session: the session so far is saved before incognito starts,
+// and the incognito session is dropped when it ends, so nothing recorded
+// while incognito is ever saved. It is never on when Ramble starts.
+func (a *App) toggleIncognito() {
+	if a.privacy == nil {
+		a.ShowTemporaryStatus(i18n.T("Incognito dictation is not available"), 2*time.Second)
+		return
+	}
+	if isRecordingState(a.state) {
+		a.ShowTemporaryStatus(i18n.T("Stop recording to switch incognito dictation"), 2*time.Second)
+		return
+	}
+
+	enabled := !a.privacy.Incognito()
+	a.saveView(a.live)
+	a.privacy.SetIncognito(enabled)
+	a.live.session = session.New()
+	a.live.rebuild()
+	a.clearLiveText()
+	logger.Info(logger.CategoryUI, "Incognito dictation enabled: %v", enabled)
+
+	a.showIncognito(enabled)
+	if enabled {
+		a.ShowTemporaryStatus(i18n.T("Incognito: nothing is saved or sent"), 3*time.Second)
+	} else {
+		a.ShowTemporaryStatus(i18n.T("Incognito session discarded"), 3*time.Second)
+	}
+}
+
+// showIncognito marks the toolbar, the live session tab and the status bar
+// while incognito dictation is on
+func (a *App) showIncognito(enabled bool) {
+	if a.incognitoButton != nil {
+		if enabled {
+			a.incognitoButton.SetIcon(theme.VisibilityOffIcon())
+			a.incognitoButton.Importance = widget.DangerImportance
+		} else {
+			a.incognitoButton.SetIcon(theme.VisibilityIcon())
+			a.incognitoButton.Importance = widget.MediumImportance
+		}
+		a.incognitoButton.Refresh()
+	}
+
+	if enabled {
+		a.live.tab.Text = i18n.T("Incognito Session")
+		a.live.tab.Icon = theme.VisibilityOffIcon()
+		a.incognitoLabel.Text = i18n.T("INCOGNITO - nothing is saved")
+	} else {
+		a.live.tab.Text = a.live.title
+		a.live.tab.Icon = nil
+		a.incognitoLabel.Text = ""
+	}
+	a.sessionTabs.Refresh()
+	a.incognitoLabel.Refresh()
+}
+
+// newIncognitoButton creates the toolbar button switching incognito
+// dictation on and off
+func (a *App) newIncognitoButton() *widget.Button {
+	a.incognitoButton = widget.NewButtonWithIcon(i18n.T("Incognito"), theme.VisibilityIcon(), a.toggleIncognito)
+	return a.incognitoButton
+}
+
+// newIncognitoLabel creates the status bar text shown while incognito
+func (a *App) newIncognitoLabel() *canvas.Text {
+	a.incognitoLabel = canvas.NewText("", incognitoColor)
+	a.incognitoLabel.TextSize = 14
+	a.incognitoLabel.TextStyle.Bold = true
+	return a.incognitoLabel
+}
@@ -0,0 +1,120 @@
+package ui
+
+import (
+	"image/color"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/driver/desktop"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/jeff-barlow-spady/ramble/pkg/logger"
+)
+
+// indicatorWindowTitle is how the window manager finds the recording indicator
+const indicatorWindowTitle = "Ramble Recording Indicator"
+
+// Size and place of the recording indicator, near the top left corner of
+// the screen
+const (
+	indicatorSize   = 24
+	indicatorMargin = 16
+)
+
+// Colors the recording indicator pulses between
+var (
+	indicatorBright = color.NRGBA{R: 255, G: 40, B: 40, A: 255}
+	indicatorDim    = color.NRGBA{R: 140, G: 20, B: 20, A: 255}
+)
+
+// recordingIndicator is a small red dot kept above other windows while
+// recording, apart from the main and hover windows, so the microphone isn't
+// left on unnoticed. Tapping it stops recording.
+type recordingIndicator struct {
+	window  fyne.Window
+	dot     *indicatorDot
+	pulse   *fyne.Animation
+	visible bool
+}
+
+// newRecordingIndicator creates the indicator, hidden; onTapped is called
+// when the dot is tapped
+func newRecordingIndicator(app fyne.App, onTapped func()) *recordingIndicator {
+	// Splash windows have no borders or title bar
+	var window fyne.Window
+	if drv, ok := app.Driver().(desktop.Driver); ok {
+		window = drv.CreateSplashWindow()
+		window.SetTitle(indicatorWindowTitle)
+	} else {
+		window = app.NewWindow(indicatorWindowTitle)
+	}
+	window.SetPadded(false)
+	window.SetFixedSize(true)
+
+	ri := &recordingIndicator{window: window, dot: newIndicatorDot(onTapped)}
+	window.SetContent(ri.dot)
+	window.Resize(fyne.NewSize(indicatorSize, indicatorSize))
+
+	ri.pulse = canvas.NewColorRGBAAnimation(indicatorBright, indicatorDim, 800*time.Millisecond, func(c color.Color) {
+		ri.dot.circle.FillColor = c
+		ri.dot.circle.Refresh()
+	})
+	ri.pulse.AutoReverse = true
+	ri.pulse.RepeatCount = fyne.AnimationRepeatForever
+	return ri
+}
+
+// SetVisible shows the pulsing dot above other windows, or hides it
+func (ri *recordingIndicator) SetVisible(visible bool) {
+	if visible == ri.visible {
+		return
+	}
+	ri.visible = visible
+	if !visible {
+		ri.pulse.Stop()
+		ri.window.Hide()
+		return
+	}
+
+	ri.window.Show()
+	ri.pulse.Start()
+	settings := HoverSettings{OnTop: true, Opacity: 1, Move: true, X: indicatorMargin, Y: indicatorMargin}
+	go func() {
+		time.Sleep(hintDelay)
+		if err := applyWindowHints(indicatorWindowTitle, settings); err != nil {
+			logger.Warning(logger.CategoryUI, "Recording indicator may not stay on top: %v", err)
+		}
+	}()
+}
+
+// indicatorDot is the red dot of the recording indicator
+type indicatorDot struct {
+	widget.BaseWidget
+	circle   *canvas.Circle
+	onTapped func()
+}
+
+// newIndicatorDot creates the dot, calling onTapped when it is tapped
+func newIndicatorDot(onTapped func()) *indicatorDot {
+	d := &indicatorDot{circle: canvas.NewCircle(indicatorBright), onTapped: onTapped}
+	d.ExtendBaseWidget(d)
+	return d
+}
+
+// CreateRenderer draws the dot filling the widget
+func (d *indicatorDot) CreateRenderer() fyne.WidgetRenderer {
+	return widget.NewSimpleRenderer(d.circle)
+}
+
+// MinSize keeps the dot at the size of the indicator
+func (d *indicatorDot) MinSize() fyne.Size {
+	return fyne.NewSize(indicatorSize, indicatorSize)
+}
+
+// Tapped stops recording
+func (d *indicatorDot) Tapped(*fyne.PointEvent) {
+	if d.onTapped != nil {
+		d.onTapped()
+	}
+}
@@ -0,0 +1,93 @@
+package ui
+
+import (
+	"sync"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/jeff-barlow-spady/ramble/pkg/transcription"
+)
+
+// karaokeLine shows the newest transcribed words, highlighting each in turn
+// at the pace it was spoken so the user can see their speech being followed
+type karaokeLine struct {
+	text *widget.RichText
+
+	mu         sync.Mutex
+	generation int // Incremented to stop the replay of older words
+}
+
+// newKaraokeLine creates an empty karaoke line
+func newKaraokeLine() *karaokeLine {
+	k := &karaokeLine{text: widget.NewRichText()}
+	k.text.Wrapping = fyne.TextWrapWord
+	return k
+}
+
+// Show replaces the line with words and starts highlighting them
+func (k *karaokeLine) Show(words []transcription.Word) {
+	k.mu.Lock()
+	k.generation++
+	generation := k.generation
+	k.mu.Unlock()
+
+	if len(words) == 0 {
+		k.render(nil, -1)
+		return
+	}
+
+	go func() {
+		start := time.Now()
+		for i, word := range words {
+			time.Sleep(time.Until(start.Add(word.Start - words[0].Start)))
+			if !k.current(generation) {
+				return
+			}
+			k.render(words, i)
+		}
+
+		// Leave the whole line shown as spoken
+		time.Sleep(time.Until(start.Add(words[len(words)-1].End - words[0].Start)))
+		if k.current(generation) {
+			k.render(words, len(words))
+		}
+	}()
+}
+
+// Clear empties the line and stops highlighting
+func (k *karaokeLine) Clear() {
+	k.Show(nil)
+}
+
+// current reports whether generation is still the words being shown
+func (k *karaokeLine) current(generation int) bool {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return k.generation == generation
+}
+
+// render shows words with the one at index highlighted, earlier ones as
+// spoken and later ones dimmed
+func (k *karaokeLine) render(words []transcription.Word, index int) {
+	segments := make([]widget.RichTextSegment, 0, len(words))
+	for i, word := range words {
+		style := widget.RichTextStyle{Inline: true, ColorName: theme.ColorNameForeground}
+		switch {
+		case i == index:
+			style.ColorName = theme.ColorNamePrimary
+			style.TextStyle = fyne.TextStyle{Bold: true}
+		case i > index:
+			style.ColorName = theme.ColorNameDisabled
+		}
+		text := word.Text
+		if i < len(words)-1 {
+			text += " "
+		}
+		segments = append(segments, &widget.TextSegment{Style: style, Text: text})
+	}
+	k.text.Segments = segments
+	k.text.Refresh()
+}
@@ -0,0 +1,160 @@
+package ui
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/jeff-barlow-spady/ramble/pkg/clipboard"
+	"github.com/jeff-barlow-spady/ramble/pkg/i18n"
+	"github.com/jeff-barlow-spady/ramble/pkg/logger"
+)
+
+// logLevelFilters are the minimum levels the Logs tab can show, by label
+var logLevelFilters = []struct {
+	label string
+	level logger.LogLevel
+}{
+	{"Debug and above", logger.LevelDebug},
+	{"Info and above", logger.LevelInfo},
+	{"Warnings and errors", logger.LevelWarning},
+	{"Errors only", logger.LevelError},
+}
+
+// maxLogViewEntries is how many entries the Logs tab keeps; the oldest half
+// is dropped when it fills up
+const maxLogViewEntries = 2000
+
+// logView is the Logs tab, showing recent log entries as they are written
+type logView struct {
+	tab         *container.TabItem
+	list        *widget.List
+	unsubscribe func()
+
+	mu       sync.Mutex
+	entries  []logger.Entry // Everything received, up to maxLogViewEntries
+	shown    []logger.Entry // Entries at or above minLevel
+	minLevel logger.LogLevel
+}
+
+// newLogView creates the Logs tab and starts following the logger
+func newLogView(a *App) *logView {
+	v := &logView{minLevel: logger.LevelInfo, entries: logger.RecentEntries()}
+	v.filter()
+
+	v.list = widget.NewList(
+		func() int {
+			v.mu.Lock()
+			defer v.mu.Unlock()
+			return len(v.shown)
+		},
+		func() fyne.CanvasObject {
+			label := widget.NewLabel("")
+			label.TextStyle = fyne.TextStyle{Monospace: true}
+			label.Truncation = fyne.TextTruncateEllipsis
+			return label
+		},
+		func(id widget.ListItemID, item fyne.CanvasObject) {
+			v.mu.Lock()
+			defer v.mu.Unlock()
+			if id < len(v.shown) {
+				item.(*widget.Label).SetText(v.shown[id].String())
+			}
+		},
+	)
+
+	labels := make([]string, len(logLevelFilters))
+	for i, filter := range logLevelFilters {
+		labels[i] = filter.label
+	}
+	levelSelect := widget.NewSelect(labels, func(selected string) {
+		for _, filter := range logLevelFilters {
+			if filter.label == selected {
+				v.mu.Lock()
+				v.minLevel = filter.level
+				v.filter()
+				v.mu.Unlock()
+			}
+		}
+		v.list.Refresh()
+		v.list.ScrollToBottom()
+	})
+	levelSelect.SetSelected(logLevelFilters[1].label)
+
+	copyButton := widget.NewButtonWithIcon(i18n.T("Copy"), theme.ContentCopyIcon(), func() {
+		if err := clipboard.SetText(v.text()); err != nil {
+			logger.Error(logger.CategoryUI, "Failed to copy logs to clipboard: %v", err)
+			return
+		}
+		a.ShowTemporaryStatus(i18n.T("Logs copied to clipboard"), 2*time.Second)
+	})
+
+	// Only lines at the level set in Preferences > Logging reach the logger
+	hint := widget.NewLabel(i18n.T("Change what is logged under Preferences > Logging"))
+	hint.TextStyle = fyne.TextStyle{Italic: true}
+
+	toolbar := container.NewHBox(widget.NewLabel(i18n.T("Show:")), levelSelect, hint, layout.NewSpacer(), copyButton)
+	v.tab = container.NewTabItemWithIcon(i18n.T("Logs"), theme.DocumentIcon(), container.NewBorder(toolbar, nil, nil, nil, v.list))
+
+	v.unsubscribe = logger.Subscribe(v.add)
+	v.list.ScrollToBottom()
+	return v
+}
+
+// add shows a newly logged entry
+func (v *logView) add(entry logger.Entry) {
+	v.mu.Lock()
+	v.entries = append(v.entries, entry)
+	if len(v.entries) > maxLogViewEntries {
+		v.entries = append(v.entries[:0], v.entries[len(v.entries)-maxLogViewEntries/2:]...)
+		v.filter()
+	} else if entry.Level >= v.minLevel {
+		v.shown = append(v.shown, entry)
+	}
+	v.mu.Unlock()
+
+	v.list.Refresh()
+	v.list.ScrollToBottom()
+}
+
+// filter recomputes the shown entries. The caller must hold v.mu.
+func (v *logView) filter() {
+	v.shown = v.shown[:0]
+	for _, entry := range v.entries {
+		if entry.Level >= v.minLevel {
+			v.shown = append(v.shown, entry)
+		}
+	}
+}
+
+// text returns the shown entries as log lines, for bug reports
+func (v *logView) text() string {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	lines := make([]string, len(v.shown))
+	for i, entry := range v.shown {
+		lines[i] = entry.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// close stops following the logger
+func (v *logView) close() {
+	v.unsubscribe()
+}
+
+// showLogs opens the Logs tab, or selects it if it is already open
+func (a *App) showLogs() {
+	if a.logs == nil {
+		a.logs = newLogView(a)
+		a.sessionTabs.Append(a.logs.tab)
+	}
+	a.sessionTabs.Select(a.logs.tab)
+}
@@ -6,6 +6,8 @@ import (
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/widget"
 	"github.com/getlantern/systray"
+
+	"github.com/jeff-barlow-spady/ramble/pkg/i18n"
 )
 
 type MinimalUI struct {
@@ -32,12 +34,12 @@ func (m *MinimalUI) Run(textChan <-chan string) {
 	systray.Run(func() {
 		systray.SetIcon(rambleIcon)
 		systray.SetTitle("Ramble")
-		systray.SetTooltip("Voice Transcription")
+		systray.SetTooltip(i18n.T("Voice Transcription"))
 
-		show := systray.AddMenuItem("Show", "Show window")
-		hide := systray.AddMenuItem("Hide", "Hide window")
+		show := systray.AddMenuItem(i18n.T("Show"), i18n.T("Show window"))
+		hide := systray.AddMenuItem(i18n.T("Hide"), i18n.T("Hide window"))
 		systray.AddSeparator()
-		quit := systray.AddMenuItem("Quit", "Exit application")
+		quit := systray.AddMenuItem(i18n.T("Quit"), i18n.T("Exit application"))
 
 		go func() {
 			for {
@@ -56,12 +58,12 @@ func (m *MinimalUI) Run(textChan <-chan string) {
 
 	// Main content
 	var recordBtn *widget.Button
-	recordBtn = widget.NewButton("Start Recording", func() {
+	recordBtn = widget.NewButton(i18n.T("Start Recording"), func() {
 		m.recording = !m.recording
 		if m.recording {
-			recordBtn.SetText("Stop Recording")
+			recordBtn.SetText(i18n.T("Stop Recording"))
 		} else {
-			recordBtn.SetText("Start Recording")
+			recordBtn.SetText(i18n.T("Start Recording"))
 		}
 	})
 
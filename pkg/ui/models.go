@@ -0,0 +1,163 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/jeff-barlow-spady/ramble/pkg/i18n"
+	"github.com/jeff-barlow-spady/ramble/pkg/logger"
+	"github.com/jeff-barlow-spady/ramble/pkg/transcription"
+)
+
+// createModelsTab creates the tab listing the installed Whisper models, where
+// models can be downloaded and deleted. The list is refreshed whenever the
+// tab is shown, so it warns about a model size just picked on the
+// Transcription tab.
+func (d *PreferencesDialog) createModelsTab() fyne.CanvasObject {
+	warning := widget.NewLabelWithStyle("", fyne.TextAlignLeading, fyne.TextStyle{Bold: true})
+	warning.Wrapping = fyne.TextWrapWord
+	rows := container.NewVBox()
+
+	var refresh func()
+	refresh = func() {
+		models := transcription.FindModels()
+		rows.RemoveAll()
+		if len(models) == 0 {
+			rows.Add(widget.NewLabel(i18n.T("No models found.")))
+		}
+		for _, model := range models {
+			model := model
+			label := widget.NewLabel(fmt.Sprintf("%s (%s)\n%s", model.Size, formatModelBytes(model.Bytes), model.Path))
+			status := widget.NewLabel("")
+			if err := transcription.VerifyModel(model.Path); err != nil {
+				status.SetText(i18n.T("Invalid"))
+			}
+			deleteButton := widget.NewButtonWithIcon("", theme.DeleteIcon(), func() {
+				dialog.ShowConfirm(i18n.T("Delete Model"), i18n.Tf("Delete %s?", model.Path), func(confirmed bool) {
+					if !confirmed {
+						return
+					}
+					if err := os.Remove(model.Path); err != nil {
+						logger.Error(logger.CategoryUI, "Failed to delete model: %v", err)
+						dialog.ShowError(fmt.Errorf(i18n.T("Failed to delete model: %v"), err), d.window)
+					}
+					refresh()
+				}, d.window)
+			})
+			rows.Add(container.NewBorder(nil, nil, nil, container.NewHBox(status, deleteButton), label))
+		}
+
+		// Only the first copy of a size is loaded, so check that one
+		selected := transcription.ModelSize(d.prefs.ModelSize)
+		path := transcription.GetLocalModelPath(selected)
+		switch {
+		case selected == "":
+			warning.Hide()
+		case selected == transcription.ModelAuto && path == "":
+			warning.SetText(i18n.T("No model is installed. Download one below."))
+			warning.Show()
+		case selected == transcription.ModelAuto:
+			warning.SetText(i18n.Tf("Auto uses the %s model, the largest that fits in available memory.", transcription.AutoModel()))
+			warning.Show()
+		case path == "":
+			warning.SetText(i18n.Tf("The selected %s model is not installed. Download it below.", selected))
+			warning.Show()
+		case transcription.VerifyModel(path) != nil:
+			warning.SetText(i18n.Tf("The selected %s model at %s is not a valid model. Delete it and download it again.", selected, path))
+			warning.Show()
+		default:
+			warning.Hide()
+		}
+	}
+	d.refreshModels = refresh
+
+	sizes := modelOptions()
+	sizeSelect := widget.NewSelect(sizes, nil)
+	if slices.Contains(sizes, d.prefs.ModelSize) {
+		sizeSelect.SetSelected(d.prefs.ModelSize)
+	} else {
+		sizeSelect.SetSelected(string(transcription.ModelAuto))
+	}
+
+	progress := widget.NewProgressBar()
+	progress.Hide()
+	var cancel context.CancelFunc
+	var downloadButton *widget.Button
+	downloadButton = widget.NewButtonWithIcon(i18n.T("Download"), theme.DownloadIcon(), func() {
+		if cancel != nil {
+			cancel()
+			return
+		}
+
+		size := transcription.ModelSize(sizeSelect.Selected)
+		var ctx context.Context
+		ctx, cancel = context.WithCancel(context.Background())
+		downloadButton.SetText(i18n.T("Cancel"))
+		downloadButton.SetIcon(theme.CancelIcon())
+		sizeSelect.Disable()
+		progress.SetValue(0)
+		progress.Show()
+
+		go func() {
+			path, err := transcription.DownloadModel(ctx, size, progress.SetValue)
+			cancel()
+			cancel = nil
+			downloadButton.SetText(i18n.T("Download"))
+			downloadButton.SetIcon(theme.DownloadIcon())
+			sizeSelect.Enable()
+			progress.Hide()
+
+			switch {
+			case ctx.Err() != nil:
+				logger.Info(logger.CategoryUI, "Download of the %s model cancelled", size)
+			case err != nil:
+				logger.Error(logger.CategoryUI, "Model download failed: %v", err)
+				dialog.ShowError(err, d.window)
+			default:
+				logger.Info(logger.CategoryUI, "Downloaded the %s model to %s", size, path)
+			}
+			refresh()
+		}()
+	})
+
+	refresh()
+	return container.NewVBox(
+		widget.NewLabelWithStyle(i18n.T("Installed Models"), fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		warning,
+		rows,
+		widget.NewLabel(""), // Spacer
+		widget.NewLabelWithStyle(i18n.T("Download"), fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		container.NewHBox(widget.NewLabel(i18n.T("Model Size:")), sizeSelect, layout.NewSpacer(), downloadButton),
+		progress,
+		widget.NewLabel(i18n.Tf("Models are downloaded to %s", transcription.UserModelDir())),
+		widget.NewLabel(i18n.T("Quantized models (q8_0, q5_1, q5_0) are smaller and faster but slightly less accurate.")),
+	)
+}
+
+// modelOptions lists the models that can be selected: auto, then every size
+// with its quantized variants
+func modelOptions() []string {
+	options := []string{string(transcription.ModelAuto)}
+	for _, size := range transcription.ModelVariants() {
+		options = append(options, string(size))
+	}
+	return options
+}
+
+// formatModelBytes returns a model file size in megabytes or gigabytes
+func formatModelBytes(bytes int64) string {
+	const mb = 1 << 20
+	if bytes >= 1<<30 {
+		return fmt.Sprintf("%.1f GB", float64(bytes)/(1<<30))
+	}
+	return fmt.Sprintf("%.0f MB", float64(bytes)/mb)
+}
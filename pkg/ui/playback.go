@@ -0,0 +1,193 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/jeff-barlow-spady/ramble/pkg/audio"
+	"github.com/jeff-barlow-spady/ramble/pkg/i18n"
+	"github.com/jeff-barlow-spady/ramble/pkg/logger"
+	"github.com/jeff-barlow-spady/ramble/pkg/session"
+)
+
+// playbackRefresh is how often the playback position is updated
+const playbackRefresh = 100 * time.Millisecond
+
+// showAudioPreview plays a segment's archived recording. Segments that can
+// be edited can also have their recording trimmed to the part kept.
+func (a *App) showAudioPreview(v *sessionView, segment session.Segment, editable bool) {
+	samples, err := audio.LoadRecording(segment.Audio)
+	if err != nil {
+		logger.Warning(logger.CategoryUI, "Failed to load recording %s: %v", segment.Audio, err)
+		dialog.ShowError(errors.New(i18n.T("The recorded audio for this segment is no longer available")), a.mainWindow)
+		return
+	}
+	player := audio.NewPlayer(samples)
+	duration := player.Duration()
+
+	position := widget.NewProgressBar()
+	position.TextFormatter = func() string {
+		return formatPlaybackTime(player.Position()) + " / " + formatPlaybackTime(duration)
+	}
+	showPosition := func() {
+		if duration > 0 {
+			position.SetValue(float64(player.Position()) / float64(duration))
+		}
+	}
+
+	playButton := widget.NewButtonWithIcon(i18n.T("Play"), theme.MediaPlayIcon(), nil)
+	showPlaying := func(playing bool) {
+		if playing {
+			playButton.SetText(i18n.T("Pause"))
+			playButton.SetIcon(theme.MediaPauseIcon())
+		} else {
+			playButton.SetText(i18n.T("Play"))
+			playButton.SetIcon(theme.MediaPlayIcon())
+		}
+	}
+	playButton.OnTapped = func() {
+		if player.Playing() {
+			if err := player.Pause(); err != nil {
+				logger.Warning(logger.CategoryAudio, "Failed to pause playback: %v", err)
+			}
+			showPlaying(false)
+			return
+		}
+		if err := player.Play(); err != nil {
+			logger.Error(logger.CategoryAudio, "Failed to play recording: %v", err)
+			dialog.ShowError(fmt.Errorf(i18n.T("Failed to play the recording: %v"), err), a.mainWindow)
+			return
+		}
+		showPlaying(true)
+	}
+	player.SetFinishedCallback(func() {
+		showPlaying(false)
+		showPosition()
+	})
+
+	content := container.NewVBox(
+		widget.NewLabel(filepath.Base(segment.Audio)),
+		container.NewBorder(nil, nil, playButton, nil, position),
+	)
+
+	var dlg dialog.Dialog
+	if editable {
+		// The trim keeps the audio between the two sliders, which also
+		// limit what is played
+		trimStart := widget.NewSlider(0, duration.Seconds())
+		trimEnd := widget.NewSlider(0, duration.Seconds())
+		trimStart.Step, trimEnd.Step = 0.1, 0.1
+		trimEnd.Value = trimEnd.Max
+		kept := widget.NewLabel("")
+		updateRange := func() {
+			start := time.Duration(trimStart.Value * float64(time.Second))
+			end := time.Duration(trimEnd.Value * float64(time.Second))
+			player.SetRange(start, end)
+			kept.SetText(i18n.Tf("Keep %s to %s", formatPlaybackTime(start), formatPlaybackTime(end)))
+			showPosition()
+		}
+		trimStart.OnChanged = func(value float64) {
+			if value > trimEnd.Value {
+				trimEnd.SetValue(value)
+			}
+			updateRange()
+		}
+		trimEnd.OnChanged = func(value float64) {
+			if value < trimStart.Value {
+				trimStart.SetValue(value)
+			}
+			updateRange()
+		}
+		updateRange()
+
+		trimButton := widget.NewButtonWithIcon(i18n.T("Trim"), theme.ContentCutIcon(), func() {
+			if err := a.trimSegmentAudio(v, segment, player.Range()); err != nil {
+				dialog.ShowError(err, a.mainWindow)
+				return
+			}
+			dlg.Hide()
+		})
+		content.Add(widget.NewSeparator())
+		content.Add(widget.NewForm(
+			widget.NewFormItem(i18n.T("Start"), trimStart),
+			widget.NewFormItem(i18n.T("End"), trimEnd),
+		))
+		content.Add(container.NewBorder(nil, nil, nil, trimButton, kept))
+	}
+
+	// Follow the playhead while the dialog is open
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(playbackRefresh)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if player.Playing() {
+					showPosition()
+				}
+			}
+		}
+	}()
+
+	dlg = dialog.NewCustom(i18n.T("Recording"), i18n.T("Close"), content, a.mainWindow)
+	dlg.SetOnClosed(func() {
+		close(done)
+		if err := player.Close(); err != nil {
+			logger.Warning(logger.CategoryAudio, "Failed to stop playback: %v", err)
+		}
+	})
+	dlg.Resize(fyne.NewSize(500, 0))
+	dlg.Show()
+}
+
+// trimSegmentAudio saves the kept part of a segment's recording next to the
+// original and links the segment to it. The original is left for the other
+// segments of the recording, and the change can be undone.
+func (a *App) trimSegmentAudio(v *sessionView, segment session.Segment, kept []float32) error {
+	if len(kept) == 0 {
+		return errors.New(i18n.T("Keep some of the recording"))
+	}
+
+	path := trimmedAudioPath(segment.Audio, time.Now())
+	if err := audio.SaveRecording(kept, path); err != nil {
+		logger.Error(logger.CategoryAudio, "Failed to save trimmed recording: %v", err)
+		return fmt.Errorf(i18n.T("Failed to save the trimmed recording: %v"), err)
+	}
+	if err := v.session.SetAudio(segment.ID, path); err != nil {
+		// The segment was deleted while the dialog was open
+		os.Remove(path)
+		logger.Warning(logger.CategoryUI, "Failed to link trimmed recording: %v", err)
+		return errors.New(i18n.T("Segment no longer exists"))
+	}
+
+	a.saveView(v)
+	v.rebuild()
+	a.ShowTemporaryStatus(i18n.T("Recording trimmed (Ctrl+Z to undo)"), 3*time.Second)
+	return nil
+}
+
+// trimmedAudioPath names the trimmed copy of a recording after the original
+// and the time it was made, e.g. "20250301-101500-trim-101742.wav"
+func trimmedAudioPath(path string, now time.Time) string {
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + "-trim-" + now.Format("150405") + ext
+}
+
+// formatPlaybackTime shows a position in a recording as minutes and seconds
+func formatPlaybackTime(d time.Duration) string {
+	seconds := int(d.Round(time.Second) / time.Second)
+	return fmt.Sprintf("%d:%02d", seconds/60, seconds%60)
+}
@@ -1,57 +1,233 @@
 package ui
 
 import (
+	"fmt"
+	"io"
 	"log"
+	"maps"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/storage"
+	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
+	"github.com/jeff-barlow-spady/ramble/pkg/audio"
+	"github.com/jeff-barlow-spady/ramble/pkg/commands"
+	"github.com/jeff-barlow-spady/ramble/pkg/i18n"
+	"github.com/jeff-barlow-spady/ramble/pkg/integrations/llm"
+	"github.com/jeff-barlow-spady/ramble/pkg/logger"
+	"github.com/jeff-barlow-spady/ramble/pkg/output"
+	"github.com/jeff-barlow-spady/ramble/pkg/textproc"
+	"github.com/jeff-barlow-spady/ramble/pkg/transcription"
+	"github.com/jeff-barlow-spady/ramble/pkg/tts"
 )
 
 // Preferences represents application preferences
 type Preferences struct {
 	// Audio settings
+	AudioBackend    string // Audio system to record from, see audio.BackendNames
 	SampleRate      float64
 	Channels        int
 	FramesPerBuffer int
+	InputDevice     string  // Name of the input device; shown only
+	InputGain       float64 // Gain for InputDevice
+	FallbackDevice  bool    // Switch to the default input if the microphone is unplugged
 
 	// Appearance settings
 	MinimizeToTray bool
 	DarkTheme      bool
+	UILanguage     string // Code from i18n.Languages, or i18n.LanguageAuto for the system's
+	Visualization  string // VisualizationWaveform or VisualizationSpectrogram
+
+	// Transcript display settings
+	TranscriptFontSize    float32
+	TranscriptFont        string  // TranscriptFontMonospace, TranscriptFontSans or a font file
+	TranscriptLineSpacing float32 // Multiple of the normal line spacing
+
+	// Hover window settings
+	HoverOnTop        bool
+	HoverWidth        float32
+	HoverHeight       float32
+	HoverOpacity      float64
+	HoverClickThrough bool
+
+	// Recording indicator settings
+	RecordingIndicator bool // Show a red dot above other windows while recording
 
 	// Hotkey settings
 	HotkeyModifiers []string
 	HotkeyKey       string
 
 	// Behavior settings
-	AutoCopy        bool
-	SaveTranscripts bool
-	TranscriptPath  string
-	StartMinimized  bool
-	TestMode        bool
+	AutoCopy          bool
+	AutoCopyMode      string // "segment" or "session"
+	AutoCopyTransient bool   // Keep auto-copied text out of clipboard history where supported
+	CopyToPrimary     bool   // Also set the primary selection for middle-click paste (Linux)
+	SaveTranscripts   bool
+	TranscriptPath    string
+	StartMinimized    bool
+	StartAtLogin      bool // Start Ramble minimized when the user logs in
+	TestMode          bool
+	RelaunchOnCrash   bool
+	WebhookURL        string   // Where "Send to Webhook" posts selected transcript text
+	SegmentWebhooks   []string // Notified of every finalized segment
+	WebhookSecret     string   // Signs webhook requests
+
+	// Note-taking app integration
+	NotesEnabled  bool
+	NotesPath     string
+	NotesTemplate string
+
+	// MQTT publishing
+	MQTTEnabled        bool
+	MQTTBroker         string
+	MQTTUsername       string
+	MQTTPassword       string
+	MQTTTopic          string
+	MQTTPublishPartial bool
+
+	// Watched keywords, one phrase or /pattern/ each
+	WatchKeywords []string
+
+	// Voice commands, one "phrase => action" each
+	VoiceCommands []string
+
+	// Hooks run on each finalized segment, one command line each
+	Hooks []string
+
+	// Text replacements, one "from => to" or "/pattern/ => to" each
+	Corrections []string
+
+	// Session summaries
+	SummaryEnabled  bool
+	SummaryProvider string // llm.ProviderOllama or llm.ProviderOpenAI
+	SummaryURL      string
+	SummaryModel    string
+	SummaryAPIKey   string
+	SummaryPrompt   string
+
+	// Reading segments aloud
+	TTSEngine string // One of tts.Engines, or tts.EngineAuto
+	TTSVoice  string
+
+	// Outputs that receive every finalized recording
+	Outputs      []output.Config
+	NumberLocale string
+	AppOutputs   []output.AppRule // What outputs do while a matching application has focus
+
+	// Local usage statistics
+	AnalyticsSessions bool
+	AnalyticsFeatures bool
+
+	// Redaction rules and where they apply
+	RedactProfanity    bool
+	RedactEmails       bool
+	RedactPhoneNumbers bool
+	RedactCardNumbers  bool
+	RedactDisplay      bool
+	RedactClipboard    bool
+	RedactSaved        bool
+
+	// Logging settings
+	LogLevel          string            // "debug", "info", "warn", "error" or "silent"
+	LogCategoryLevels map[string]string // Overrides of LogLevel, by category
+	LogJSON           bool
+	LogToFile         bool
+
+	// Audio archive settings
+	ArchiveAudio     bool
+	ArchiveMaxDays   int
+	ArchiveMaxSizeMB int
+	ArchiveFormat    string // "wav" or "flac"
 
 	// Transcription settings
-	ModelSize string
+	TranscriptionBackend    string                            // Name of one of transcription.Backends
+	WhisperServerURL        string                            // Used by the whisper-server backend
+	FasterWhisperCommand    string                            // Sidecar run by the faster-whisper backend
+	VoskCommand             string                            // Sidecar run by the Vosk backend
+	Decoding                map[string]transcription.Decoding // Whisper decoding by backend name; missing ones use their defaults
+	ModelSize               string
+	LatencyProfile          string // One of transcription.LatencyProfiles
+	Language                string // Language code, or transcription.LanguageAuto
+	RewriteModel            string // Model rewriting each finalized segment ("" = off)
+	CleanupSegments         bool   // Fix punctuation and casing of finalized segments
+	CleanupCommand          string // Program doing the cleanup ("" = built-in rules)
+	IdleReleaseMinutes      int
+	UtteranceSilenceSeconds float64 // Pause that finalizes a segment while recording (0 = off)
+	SuppressHallucinations  bool    // Drop phrases whisper invents on silence or noise
+	RolloverMinutes         int     // Start a new segment after this long recording (0 = off)
+	RolloverCharacters      int     // Start a new segment after this much text (0 = off)
+	AutoStopSilenceMinutes  int     // Stop recording after this long without speech (0 = never)
+	KeepContext             bool    // Prompt each live window with the text before it
 }
 
 // DefaultPreferences returns the default preferences
 func DefaultPreferences() Preferences {
 	return Preferences{
-		SampleRate:      16000,
-		Channels:        1,
-		FramesPerBuffer: 1024,
-		MinimizeToTray:  true,
-		DarkTheme:       true,
-		HotkeyModifiers: []string{"ctrl", "shift"},
-		HotkeyKey:       "s",
-		AutoCopy:        false,
-		SaveTranscripts: false,
-		TranscriptPath:  "",
-		StartMinimized:  false,
-		TestMode:        false,
-		ModelSize:       "small",
+		AudioBackend:           audio.DefaultBackend,
+		SampleRate:             16000,
+		Channels:               1,
+		FramesPerBuffer:        1024,
+		InputGain:              1,
+		FallbackDevice:         true,
+		MinimizeToTray:         true,
+		DarkTheme:              true,
+		Visualization:          VisualizationWaveform,
+		TranscriptFontSize:     DefaultTranscriptFontSize,
+		TranscriptFont:         TranscriptFontMonospace,
+		TranscriptLineSpacing:  DefaultTranscriptLineSpacing,
+		HoverOnTop:             true,
+		HoverWidth:             defaultHoverWidth,
+		HoverHeight:            defaultHoverHeight,
+		HoverOpacity:           1,
+		RecordingIndicator:     false,
+		HotkeyModifiers:        []string{"ctrl", "shift"},
+		HotkeyKey:              "s",
+		AutoCopy:               false,
+		AutoCopyMode:           "segment",
+		AutoCopyTransient:      false,
+		CopyToPrimary:          false,
+		SaveTranscripts:        false,
+		TranscriptPath:         "",
+		StartMinimized:         false,
+		StartAtLogin:           false,
+		TestMode:               false,
+		RelaunchOnCrash:        false,
+		ArchiveAudio:           false,
+		ArchiveMaxDays:         30,
+		ArchiveMaxSizeMB:       1024,
+		ArchiveFormat:          "wav",
+		TranscriptionBackend:   transcription.BackendWhisper,
+		WhisperServerURL:       transcription.DefaultServerURL,
+		FasterWhisperCommand:   transcription.DefaultFasterWhisperCommand,
+		VoskCommand:            transcription.DefaultVoskCommand,
+		ModelSize:              "auto",
+		LatencyProfile:         string(transcription.ProfileBalanced),
+		Language:               transcription.DefaultLanguage,
+		SuppressHallucinations: true,
+		KeepContext:            true,
+		IdleReleaseMinutes:     10,
+		RedactDisplay:          true,
+		RedactClipboard:        true,
+		RedactSaved:            true,
+		LogLevel:               "info",
+	}
+}
+
+// hoverSettings returns the settings of the hover window
+func (p Preferences) hoverSettings() HoverSettings {
+	return HoverSettings{
+		OnTop:        p.HoverOnTop,
+		Width:        p.HoverWidth,
+		Height:       p.HoverHeight,
+		Opacity:      p.HoverOpacity,
+		ClickThrough: p.HoverClickThrough,
 	}
 }
 
@@ -61,6 +237,8 @@ type PreferencesDialog struct {
 	window fyne.Window
 	onSave func(Preferences)
 	prefs  Preferences
+
+	refreshModels func() // Rescans the Models tab
 }
 
 // Global variable to track the preferences window
@@ -76,7 +254,7 @@ func (a *App) ShowPreferences(onSave func(Preferences)) {
 	}
 
 	// Create a new window for preferences
-	w := a.fyneApp.NewWindow("Ramble Preferences")
+	w := a.fyneApp.NewWindow(i18n.T("Ramble Preferences"))
 	w.Resize(fyne.NewSize(500, 450))
 
 	// Store reference to the window
@@ -107,16 +285,34 @@ func (a *App) ShowPreferences(onSave func(Preferences)) {
 func (d *PreferencesDialog) setupUI() {
 	// Create tabs for different settings categories
 	tabs := container.NewAppTabs(
-		container.NewTabItem("General", d.createGeneralTab()),
-		container.NewTabItem("Audio", d.createAudioTab()),
-		container.NewTabItem("Hotkeys", d.createHotkeysTab()),
-		container.NewTabItem("Appearance", d.createAppearanceTab()),
-		container.NewTabItem("Transcription", d.createTranscriptionTab()),
+		container.NewTabItem(i18n.T("General"), d.createGeneralTab()),
+		container.NewTabItem(i18n.T("Audio"), d.createAudioTab()),
+		container.NewTabItem(i18n.T("Hotkeys"), d.createHotkeysTab()),
+		container.NewTabItem(i18n.T("Appearance"), d.createAppearanceTab()),
+		container.NewTabItem(i18n.T("Transcription"), d.createTranscriptionTab()),
+		container.NewTabItem(i18n.T("Models"), container.NewVScroll(d.createModelsTab())),
+		container.NewTabItem(i18n.T("Outputs"), d.createOutputsTab()),
+		container.NewTabItem(i18n.T("Notes"), d.createNotesTab()),
+		container.NewTabItem("MQTT", d.createMQTTTab()),
+		container.NewTabItem(i18n.T("Summary"), d.createSummaryTab()),
+		container.NewTabItem(i18n.T("Read Aloud"), d.createReadAloudTab()),
+		container.NewTabItem(i18n.T("Replacements"), d.createReplacementsTab()),
+		container.NewTabItem(i18n.T("Keywords"), d.createKeywordsTab()),
+		container.NewTabItem(i18n.T("Commands"), d.createCommandsTab()),
+		container.NewTabItem(i18n.T("Hooks"), d.createHooksTab()),
+		container.NewTabItem(i18n.T("Privacy"), d.createPrivacyTab()),
+		container.NewTabItem(i18n.T("Logging"), d.createLoggingTab()),
+		container.NewTabItem(i18n.T("Advanced"), d.createAdvancedTab()),
 	)
 	tabs.SetTabLocation(container.TabLocationTop)
+	tabs.OnSelected = func(tab *container.TabItem) {
+		if tab.Text == i18n.T("Models") {
+			d.refreshModels()
+		}
+	}
 
 	// Create buttons
-	saveButton := widget.NewButton("Save", func() {
+	saveButton := widget.NewButton(i18n.T("Save"), func() {
 		if d.onSave != nil {
 			d.onSave(d.prefs)
 		}
@@ -124,7 +320,7 @@ func (d *PreferencesDialog) setupUI() {
 		preferencesWindow = nil
 	})
 
-	cancelButton := widget.NewButton("Cancel", func() {
+	cancelButton := widget.NewButton(i18n.T("Cancel"), func() {
 		d.window.Close()
 		preferencesWindow = nil
 	})
@@ -152,13 +348,43 @@ func (d *PreferencesDialog) setupUI() {
 // createGeneralTab creates the general settings tab
 func (d *PreferencesDialog) createGeneralTab() fyne.CanvasObject {
 	// Auto-copy checkbox
-	autoCopyCheck := widget.NewCheck("Automatically copy transcriptions to clipboard", func(checked bool) {
+	autoCopyCheck := widget.NewCheck(i18n.T("Automatically copy transcriptions to clipboard"), func(checked bool) {
 		d.prefs.AutoCopy = checked
 	})
 	autoCopyCheck.Checked = d.prefs.AutoCopy
 
+	// What to copy when a recording ends
+	copySegmentOption := i18n.T("Each finalized segment")
+	copySessionOption := i18n.T("Full session")
+	autoCopyModeSelect := widget.NewSelect([]string{copySegmentOption, copySessionOption}, func(selected string) {
+		if selected == copySessionOption {
+			d.prefs.AutoCopyMode = "session"
+		} else {
+			d.prefs.AutoCopyMode = "segment"
+		}
+	})
+	if d.prefs.AutoCopyMode == "session" {
+		autoCopyModeSelect.SetSelected(copySessionOption)
+	} else {
+		autoCopyModeSelect.SetSelected(copySegmentOption)
+	}
+
+	transientCheck := widget.NewCheck(i18n.T("Keep copied text out of clipboard history (macOS, Windows)"), func(checked bool) {
+		d.prefs.AutoCopyTransient = checked
+	})
+	transientCheck.Checked = d.prefs.AutoCopyTransient
+
+	// Middle-click paste only exists on Linux
+	primaryCheck := widget.NewCheck(i18n.T("Also copy to primary selection (middle-click paste)"), func(checked bool) {
+		d.prefs.CopyToPrimary = checked
+	})
+	primaryCheck.Checked = d.prefs.CopyToPrimary
+	if runtime.GOOS != "linux" {
+		primaryCheck.Hide()
+	}
+
 	// Save transcripts checkbox
-	saveTranscriptsCheck := widget.NewCheck("Save transcriptions to file", func(checked bool) {
+	saveTranscriptsCheck := widget.NewCheck(i18n.T("Save transcriptions to file"), func(checked bool) {
 		d.prefs.SaveTranscripts = checked
 	})
 	saveTranscriptsCheck.Checked = d.prefs.SaveTranscripts
@@ -171,7 +397,7 @@ func (d *PreferencesDialog) createGeneralTab() fyne.CanvasObject {
 	}
 
 	// Choose folder button
-	chooseFolderButton := widget.NewButton("Choose Folder", func() {
+	chooseFolderButton := widget.NewButton(i18n.T("Choose Folder"), func() {
 		dialog.ShowFolderOpen(func(uri fyne.ListableURI, err error) {
 			if err != nil {
 				log.Println("Error selecting folder:", err)
@@ -187,33 +413,103 @@ func (d *PreferencesDialog) createGeneralTab() fyne.CanvasObject {
 	})
 
 	// Start minimized checkbox
-	startMinimizedCheck := widget.NewCheck("Start application minimized", func(checked bool) {
+	startMinimizedCheck := widget.NewCheck(i18n.T("Start application minimized"), func(checked bool) {
 		d.prefs.StartMinimized = checked
 	})
 	startMinimizedCheck.Checked = d.prefs.StartMinimized
 
+	// Start at login checkbox; Ramble then starts minimized to the tray
+	startAtLoginCheck := widget.NewCheck(i18n.T("Start Ramble when I log in"), func(checked bool) {
+		d.prefs.StartAtLogin = checked
+	})
+	startAtLoginCheck.Checked = d.prefs.StartAtLogin
+
 	// Test mode checkbox
-	testModeCheck := widget.NewCheck("Test mode (simulated audio)", func(checked bool) {
+	testModeCheck := widget.NewCheck(i18n.T("Test mode (simulated audio)"), func(checked bool) {
 		d.prefs.TestMode = checked
 	})
 	testModeCheck.Checked = d.prefs.TestMode
 
+	// Relaunch after crash checkbox
+	relaunchCheck := widget.NewCheck(i18n.T("Restart Ramble after a crash"), func(checked bool) {
+		d.prefs.RelaunchOnCrash = checked
+	})
+	relaunchCheck.Checked = d.prefs.RelaunchOnCrash
+
+	// Webhook for the "Send to Webhook" transcript action
+	webhookEntry := widget.NewEntry()
+	webhookEntry.SetPlaceHolder("https://example.com/hook")
+	webhookEntry.SetText(d.prefs.WebhookURL)
+	webhookEntry.OnChanged = func(text string) {
+		d.prefs.WebhookURL = strings.TrimSpace(text)
+	}
+
+	// Webhooks that receive every finalized segment, one per line
+	segmentWebhooksEntry := widget.NewMultiLineEntry()
+	segmentWebhooksEntry.SetPlaceHolder(i18n.T("One URL per line"))
+	segmentWebhooksEntry.SetMinRowsVisible(2)
+	segmentWebhooksEntry.SetText(strings.Join(d.prefs.SegmentWebhooks, "\n"))
+	segmentWebhooksEntry.OnChanged = func(text string) {
+		d.prefs.SegmentWebhooks = nil
+		for _, line := range strings.Split(text, "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				d.prefs.SegmentWebhooks = append(d.prefs.SegmentWebhooks, line)
+			}
+		}
+	}
+
+	webhookSecretEntry := widget.NewPasswordEntry()
+	webhookSecretEntry.SetPlaceHolder(i18n.T("Optional"))
+	webhookSecretEntry.SetText(d.prefs.WebhookSecret)
+	webhookSecretEntry.OnChanged = func(text string) {
+		d.prefs.WebhookSecret = text
+	}
+
 	// Create the layout
 	return container.NewVBox(
-		widget.NewLabelWithStyle("General Settings", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		widget.NewLabelWithStyle(i18n.T("General Settings"), fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
 		container.NewPadded(autoCopyCheck),
+		container.NewGridWithColumns(2,
+			widget.NewLabel(i18n.T("Copy when recording stops:")),
+			autoCopyModeSelect,
+		),
+		container.NewPadded(transientCheck),
+		container.NewPadded(primaryCheck),
 		container.NewPadded(saveTranscriptsCheck),
 		container.NewGridWithColumns(2,
-			widget.NewLabel("Transcript folder:"),
+			widget.NewLabel(i18n.T("Transcript folder:")),
 			container.NewBorder(nil, nil, nil, chooseFolderButton, transcriptPathEntry),
 		),
 		container.NewPadded(startMinimizedCheck),
+		container.NewPadded(startAtLoginCheck),
 		container.NewPadded(testModeCheck),
+		container.NewPadded(relaunchCheck),
+		container.NewGridWithColumns(2,
+			widget.NewLabel(i18n.T("Webhook URL:")),
+			webhookEntry,
+		),
+		container.NewGridWithColumns(2,
+			widget.NewLabel(i18n.T("Send each segment to:")),
+			segmentWebhooksEntry,
+		),
+		container.NewGridWithColumns(2,
+			widget.NewLabel(i18n.T("Webhook signing secret:")),
+			webhookSecretEntry,
+		),
 	)
 }
 
 // createAudioTab creates the audio settings tab
 func (d *PreferencesDialog) createAudioTab() fyne.CanvasObject {
+	// Audio system to record from
+	backendSelect := widget.NewSelect(audio.BackendNames, func(selected string) {
+		d.prefs.AudioBackend = selected
+	})
+	backendSelect.SetSelected(d.prefs.AudioBackend)
+	if backendSelect.Selected == "" {
+		backendSelect.SetSelected(audio.DefaultBackend)
+	}
+
 	// Sample rate selection
 	sampleRateSelect := widget.NewSelect([]string{"8000", "16000", "22050", "44100", "48000"}, func(selected string) {
 		var rate float64
@@ -234,17 +530,18 @@ func (d *PreferencesDialog) createAudioTab() fyne.CanvasObject {
 	sampleRateSelect.SetSelected(intToString(int(d.prefs.SampleRate)))
 
 	// Channels selection
-	channelsSelect := widget.NewSelect([]string{"1 (Mono)", "2 (Stereo)"}, func(selected string) {
-		if selected == "1 (Mono)" {
+	monoOption, stereoOption := i18n.T("1 (Mono)"), i18n.T("2 (Stereo)")
+	channelsSelect := widget.NewSelect([]string{monoOption, stereoOption}, func(selected string) {
+		if selected == monoOption {
 			d.prefs.Channels = 1
 		} else {
 			d.prefs.Channels = 2
 		}
 	})
 	if d.prefs.Channels == 1 {
-		channelsSelect.SetSelected("1 (Mono)")
+		channelsSelect.SetSelected(monoOption)
 	} else {
-		channelsSelect.SetSelected("2 (Stereo)")
+		channelsSelect.SetSelected(stereoOption)
 	}
 
 	// Buffer size selection
@@ -264,34 +561,116 @@ func (d *PreferencesDialog) createAudioTab() fyne.CanvasObject {
 	})
 	bufferSizeSelect.SetSelected(intToString(d.prefs.FramesPerBuffer))
 
+	// Input gain, adjusted while watching the live levels
+	gainLabel := widget.NewLabel("")
+	showGain := func(gain float64) {
+		gainLabel.SetText(fmt.Sprintf("%.1fx", gain))
+	}
+	if d.prefs.InputGain <= 0 {
+		d.prefs.InputGain = 1
+	}
+	showGain(d.prefs.InputGain)
+	calibrateButton := widget.NewButtonWithIcon(i18n.T("Calibrate..."), theme.VolumeUpIcon(), func() {
+		d.showCalibration(func(gain float64) {
+			d.prefs.InputGain = gain
+			showGain(gain)
+		})
+	})
+
+	// Recovery from an unplugged microphone
+	fallbackCheck := widget.NewCheck(i18n.T("Switch to the default microphone if this one is unplugged"), func(checked bool) {
+		d.prefs.FallbackDevice = checked
+	})
+	fallbackCheck.Checked = d.prefs.FallbackDevice
+
+	// Audio archive settings
+	archiveCheck := widget.NewCheck(i18n.T("Save recorded audio (16kHz mono) for re-transcription"), func(checked bool) {
+		d.prefs.ArchiveAudio = checked
+	})
+	archiveCheck.Checked = d.prefs.ArchiveAudio
+
+	maxDaysEntry := widget.NewEntry()
+	maxDaysEntry.SetText(strconv.Itoa(d.prefs.ArchiveMaxDays))
+	maxDaysEntry.OnChanged = func(text string) {
+		if days, err := strconv.Atoi(text); err == nil && days >= 0 {
+			d.prefs.ArchiveMaxDays = days
+		}
+	}
+
+	maxSizeEntry := widget.NewEntry()
+	maxSizeEntry.SetText(strconv.Itoa(d.prefs.ArchiveMaxSizeMB))
+	maxSizeEntry.OnChanged = func(text string) {
+		if size, err := strconv.Atoi(text); err == nil && size >= 0 {
+			d.prefs.ArchiveMaxSizeMB = size
+		}
+	}
+
+	// Compressed archives take about half the space and play back the same
+	wavOption, flacOption := i18n.T("WAV (uncompressed)"), i18n.T("FLAC (lossless, about half the size)")
+	archiveFormatSelect := widget.NewSelect([]string{wavOption, flacOption}, func(selected string) {
+		if selected == flacOption {
+			d.prefs.ArchiveFormat = "flac"
+		} else {
+			d.prefs.ArchiveFormat = "wav"
+		}
+	})
+	if d.prefs.ArchiveFormat == "flac" {
+		archiveFormatSelect.SetSelected(flacOption)
+	} else {
+		archiveFormatSelect.SetSelected(wavOption)
+	}
+
 	// Create the layout
 	return container.NewVBox(
-		widget.NewLabelWithStyle("Audio Settings", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		widget.NewLabelWithStyle(i18n.T("Audio Settings"), fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
 		container.NewGridWithColumns(2,
-			widget.NewLabel("Sample Rate (Hz):"),
+			widget.NewLabel(i18n.T("Audio backend:")),
+			backendSelect,
+		),
+		container.NewGridWithColumns(2,
+			widget.NewLabel(i18n.T("Sample Rate (Hz):")),
 			sampleRateSelect,
 		),
 		container.NewGridWithColumns(2,
-			widget.NewLabel("Channels:"),
+			widget.NewLabel(i18n.T("Channels:")),
 			channelsSelect,
 		),
 		container.NewGridWithColumns(2,
-			widget.NewLabel("Buffer Size (frames):"),
+			widget.NewLabel(i18n.T("Buffer Size (frames):")),
 			bufferSizeSelect,
 		),
+		container.NewGridWithColumns(2,
+			widget.NewLabel(i18n.T("Input gain:")),
+			container.NewBorder(nil, nil, nil, calibrateButton, gainLabel),
+		),
+		container.NewPadded(fallbackCheck),
+		widget.NewSeparator(),
+		container.NewPadded(archiveCheck),
+		container.NewGridWithColumns(2,
+			widget.NewLabel(i18n.T("Keep audio for (days, 0 = forever):")),
+			maxDaysEntry,
+		),
+		container.NewGridWithColumns(2,
+			widget.NewLabel(i18n.T("Maximum archive size (MB, 0 = unlimited):")),
+			maxSizeEntry,
+		),
+		container.NewGridWithColumns(2,
+			widget.NewLabel(i18n.T("Archive format:")),
+			archiveFormatSelect,
+		),
 	)
 }
 
 // createHotkeysTab creates the hotkeys settings tab
 func (d *PreferencesDialog) createHotkeysTab() fyne.CanvasObject {
 	// Modifiers selection
-	ctrlCheck := widget.NewCheck("Ctrl", func(checked bool) {
+	ctrlCheck := widget.NewCheck(i18n.T("Ctrl"), func(checked bool) {
 		updateModifiers(checked, "ctrl", &d.prefs.HotkeyModifiers)
 	})
-	shiftCheck := widget.NewCheck("Shift", func(checked bool) {
+	shiftCheck := widget.NewCheck(i18n.T("Shift"), func(checked bool) {
 		updateModifiers(checked, "shift", &d.prefs.HotkeyModifiers)
 	})
-	altCheck := widget.NewCheck("Alt", func(checked bool) {
+	altCheck := widget.NewCheck(i18n.T("Alt"), func(checked bool) {
 		updateModifiers(checked, "alt", &d.prefs.HotkeyModifiers)
 	})
 
@@ -326,20 +705,20 @@ func (d *PreferencesDialog) createHotkeysTab() fyne.CanvasObject {
 
 	// Warning label
 	warningLabel := widget.NewLabelWithStyle(
-		"Note: Hotkey changes will take effect after restarting the application.",
+		i18n.T("Note: Hotkey changes will take effect after restarting the application."),
 		fyne.TextAlignCenter,
 		fyne.TextStyle{Italic: true},
 	)
 
 	// Create the layout
 	return container.NewVBox(
-		widget.NewLabelWithStyle("Hotkey Settings", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		widget.NewLabelWithStyle(i18n.T("Hotkey Settings"), fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
 		container.NewGridWithColumns(2,
-			widget.NewLabel("Modifiers:"),
+			widget.NewLabel(i18n.T("Modifiers:")),
 			modifiersBox,
 		),
 		container.NewGridWithColumns(2,
-			widget.NewLabel("Key:"),
+			widget.NewLabel(i18n.T("Key:")),
 			keyEntry,
 		),
 		container.NewPadded(warningLabel),
@@ -349,29 +728,185 @@ func (d *PreferencesDialog) createHotkeysTab() fyne.CanvasObject {
 // createAppearanceTab creates the appearance settings tab
 func (d *PreferencesDialog) createAppearanceTab() fyne.CanvasObject {
 	// Theme selection
-	themeCheck := widget.NewCheck("Dark theme", func(checked bool) {
+	themeCheck := widget.NewCheck(i18n.T("Dark theme"), func(checked bool) {
 		d.prefs.DarkTheme = checked
 	})
 	themeCheck.Checked = d.prefs.DarkTheme
 
 	// Minimize to tray checkbox
-	minimizeToTrayCheck := widget.NewCheck("Minimize to system tray when closing", func(checked bool) {
+	minimizeToTrayCheck := widget.NewCheck(i18n.T("Minimize to system tray when closing"), func(checked bool) {
 		d.prefs.MinimizeToTray = checked
 	})
 	minimizeToTrayCheck.Checked = d.prefs.MinimizeToTray
 
+	// Interface language, shown in the language itself
+	systemOption := i18n.T("System")
+	languageNames := []string{systemOption}
+	for _, language := range i18n.Languages {
+		languageNames = append(languageNames, language.Name)
+	}
+	languageSelect := widget.NewSelect(languageNames, func(selected string) {
+		d.prefs.UILanguage = i18n.LanguageAuto
+		for _, language := range i18n.Languages {
+			if language.Name == selected {
+				d.prefs.UILanguage = language.Code
+			}
+		}
+	})
+	languageSelect.SetSelected(systemOption)
+	for _, language := range i18n.Languages {
+		if language.Code == d.prefs.UILanguage {
+			languageSelect.SetSelected(language.Name)
+		}
+	}
+
+	// Live view of the microphone input
+	waveformOption, spectrogramOption := i18n.T("Waveform"), i18n.T("Spectrogram")
+	visualizationSelect := widget.NewSelect([]string{waveformOption, spectrogramOption}, func(selected string) {
+		if selected == spectrogramOption {
+			d.prefs.Visualization = VisualizationSpectrogram
+		} else {
+			d.prefs.Visualization = VisualizationWaveform
+		}
+	})
+	if d.prefs.Visualization == VisualizationSpectrogram {
+		visualizationSelect.SetSelected(spectrogramOption)
+	} else {
+		visualizationSelect.SetSelected(waveformOption)
+	}
+
+	// Transcript text, which is too small on high-resolution screens by default
+	fontSizeSelect := widget.NewSelect([]string{"12", "14", "16", "18", "20", "24", "28", "32"}, func(selected string) {
+		if size, err := strconv.Atoi(selected); err == nil {
+			d.prefs.TranscriptFontSize = float32(size)
+		}
+	})
+	fontSizeSelect.SetSelected(strconv.Itoa(int(d.prefs.TranscriptFontSize)))
+
+	monospaceOption := i18n.T("Monospace")
+	sansOption := i18n.T("Sans-serif")
+	fontLabel := widget.NewLabel("")
+	fontSelect := widget.NewSelect([]string{monospaceOption, sansOption}, func(selected string) {
+		if selected == "" {
+			return // Cleared for a font file
+		}
+		if selected == sansOption {
+			d.prefs.TranscriptFont = TranscriptFontSans
+		} else {
+			d.prefs.TranscriptFont = TranscriptFontMonospace
+		}
+		fontLabel.SetText("")
+	})
+	switch d.prefs.TranscriptFont {
+	case TranscriptFontSans:
+		fontSelect.SetSelected(sansOption)
+	case "", TranscriptFontMonospace:
+		fontSelect.SetSelected(monospaceOption)
+	default:
+		fontLabel.SetText(filepath.Base(d.prefs.TranscriptFont))
+	}
+
+	// Any TrueType or OpenType font can be used instead
+	chooseFontButton := widget.NewButton(i18n.T("Font File..."), func() {
+		picker := dialog.NewFileOpen(func(reader fyne.URIReadCloser, err error) {
+			if err != nil {
+				log.Println("Error selecting font:", err)
+				return
+			}
+			if reader == nil {
+				return
+			}
+			reader.Close()
+			d.prefs.TranscriptFont = reader.URI().Path()
+			fontSelect.ClearSelected()
+			fontLabel.SetText(reader.URI().Name())
+		}, d.window)
+		picker.SetFilter(storage.NewExtensionFileFilter([]string{".ttf", ".otf"}))
+		picker.Show()
+	})
+
+	lineSpacingSelect := widget.NewSelect([]string{"1", "1.25", "1.5", "2"}, func(selected string) {
+		if spacing, err := strconv.ParseFloat(selected, 32); err == nil {
+			d.prefs.TranscriptLineSpacing = float32(spacing)
+		}
+	})
+	lineSpacingSelect.SetSelected(strconv.FormatFloat(float64(d.prefs.TranscriptLineSpacing), 'f', -1, 32))
+
+	// Hover window, shown with Ctrl+Shift+S while dictating into other applications
+	onTopCheck := widget.NewCheck(i18n.T("Keep the hover window on top of other windows"), func(checked bool) {
+		d.prefs.HoverOnTop = checked
+	})
+	onTopCheck.Checked = d.prefs.HoverOnTop
+
+	widthEntry := widget.NewEntry()
+	widthEntry.SetText(strconv.Itoa(int(d.prefs.HoverWidth)))
+	widthEntry.OnChanged = func(text string) {
+		if width, err := strconv.Atoi(text); err == nil && width >= 100 {
+			d.prefs.HoverWidth = float32(width)
+		}
+	}
+	heightEntry := widget.NewEntry()
+	heightEntry.SetText(strconv.Itoa(int(d.prefs.HoverHeight)))
+	heightEntry.OnChanged = func(text string) {
+		if height, err := strconv.Atoi(text); err == nil && height >= 80 {
+			d.prefs.HoverHeight = float32(height)
+		}
+	}
+
+	opacityLabel := widget.NewLabel(i18n.Tf("Opacity: %.0f%%", d.prefs.HoverOpacity*100))
+	opacitySlider := widget.NewSlider(MinHoverOpacity*100, 100)
+	opacitySlider.Step = 5
+	opacitySlider.OnChanged = func(value float64) {
+		d.prefs.HoverOpacity = value / 100
+		opacityLabel.SetText(i18n.Tf("Opacity: %.0f%%", value))
+	}
+	opacitySlider.SetValue(d.prefs.HoverOpacity * 100)
+
+	clickThroughCheck := widget.NewCheck(i18n.T("Let clicks through to the window below (Windows)"), func(checked bool) {
+		d.prefs.HoverClickThrough = checked
+	})
+	clickThroughCheck.Checked = d.prefs.HoverClickThrough
+
+	indicatorCheck := widget.NewCheck(i18n.T("Show a red dot above other windows while recording"), func(checked bool) {
+		d.prefs.RecordingIndicator = checked
+	})
+	indicatorCheck.Checked = d.prefs.RecordingIndicator
+
 	// Create the layout
 	return container.NewVBox(
-		widget.NewLabelWithStyle("Appearance Settings", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		widget.NewLabelWithStyle(i18n.T("Appearance Settings"), fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
 		container.NewPadded(themeCheck),
 		container.NewPadded(minimizeToTrayCheck),
+		container.NewGridWithColumns(2, widget.NewLabel(i18n.T("Interface language:")), languageSelect),
+		widget.NewLabel(i18n.T("A new interface language is used after restarting the application.")),
+		container.NewGridWithColumns(2, widget.NewLabel(i18n.T("Microphone display:")), visualizationSelect),
+		widget.NewLabel(i18n.T("The spectrogram shows background noise and hum that the waveform hides.")),
+		widget.NewLabelWithStyle(i18n.T("Transcript"), fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		container.NewGridWithColumns(2, widget.NewLabel(i18n.T("Text size:")), fontSizeSelect),
+		container.NewGridWithColumns(2,
+			widget.NewLabel(i18n.T("Font:")),
+			container.NewBorder(nil, nil, nil, container.NewHBox(fontLabel, chooseFontButton), fontSelect),
+		),
+		container.NewGridWithColumns(2, widget.NewLabel(i18n.T("Line spacing:")), lineSpacingSelect),
+		widget.NewLabelWithStyle(i18n.T("Hover Window"), fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		container.NewPadded(onTopCheck),
+		container.NewGridWithColumns(4,
+			widget.NewLabel(i18n.T("Width:")), widthEntry,
+			widget.NewLabel(i18n.T("Height:")), heightEntry,
+		),
+		container.NewBorder(nil, nil, opacityLabel, nil, opacitySlider),
+		container.NewPadded(clickThroughCheck),
+		widget.NewLabel(i18n.T("While clicks go through, leave the hover window with Show Window in the tray menu.")),
+		widget.NewLabelWithStyle(i18n.T("Recording Indicator"), fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		container.NewPadded(indicatorCheck),
+		widget.NewLabel(i18n.T("The dot stays near the top left corner of the screen; click it to stop recording.")),
 	)
 }
 
 // createTranscriptionTab creates the transcription settings tab
 func (d *PreferencesDialog) createTranscriptionTab() fyne.CanvasObject {
 	// Model size selection
-	modelSizeSelect := widget.NewSelect([]string{"tiny", "base", "small", "medium", "large"}, func(selected string) {
+	modelSizeSelect := widget.NewSelect(modelOptions(), func(selected string) {
 		d.prefs.ModelSize = selected
 	})
 
@@ -379,22 +914,1098 @@ func (d *PreferencesDialog) createTranscriptionTab() fyne.CanvasObject {
 	if d.prefs.ModelSize != "" {
 		modelSizeSelect.SetSelected(d.prefs.ModelSize)
 	} else {
-		modelSizeSelect.SetSelected("small") // Default to small
+		modelSizeSelect.SetSelected("auto") // Default to the largest model that fits
+	}
+
+	// Two-pass transcription: tiny transcribes live and a larger model
+	// rewrites each segment once it is finalized
+	rewriteOff := i18n.T("Off")
+	rewriteSelect := widget.NewSelect(append([]string{rewriteOff}, modelOptions()...), func(selected string) {
+		if selected == rewriteOff {
+			selected = ""
+		}
+		d.prefs.RewriteModel = selected
+	})
+	if d.prefs.RewriteModel != "" {
+		rewriteSelect.SetSelected(d.prefs.RewriteModel)
+	} else {
+		rewriteSelect.SetSelected(rewriteOff)
+	}
+
+	// Where speech is recognized. Settings that don't apply to the selected
+	// backend are disabled, and its capabilities are described below it.
+	serverEntry := widget.NewEntry()
+	serverEntry.SetPlaceHolder(transcription.DefaultServerURL)
+	serverEntry.SetText(d.prefs.WhisperServerURL)
+	serverEntry.OnChanged = func(text string) {
+		d.prefs.WhisperServerURL = strings.TrimSpace(text)
+	}
+	commandEntry := widget.NewEntry()
+	commandEntry.OnChanged = func(text string) {
+		switch d.prefs.TranscriptionBackend {
+		case transcription.BackendFasterWhisper:
+			d.prefs.FasterWhisperCommand = strings.TrimSpace(text)
+		case transcription.BackendVosk:
+			d.prefs.VoskCommand = strings.TrimSpace(text)
+		}
+	}
+	backendInfo := widget.NewLabel("")
+	backendInfo.Wrapping = fyne.TextWrapWord
+
+	backends := transcription.AvailableBackends()
+	backendLabels := make([]string, len(backends))
+	for i, backend := range backends {
+		backendLabels[i] = backend.Label
+	}
+	backendSelect := widget.NewSelect(backendLabels, func(selected string) {
+		var backend transcription.BackendInfo
+		for _, b := range backends {
+			if b.Label == selected {
+				backend = b
+			}
+		}
+		d.prefs.TranscriptionBackend = backend.Name
+		backendInfo.SetText(backendDescription(backend))
+
+		enable(serverEntry, backend.URL)
+		enable(modelSizeSelect, backend.LocalModel)
+		enable(rewriteSelect, backend.LocalModel)
+		enable(commandEntry, backend.Command)
+		switch backend.Name {
+		case transcription.BackendFasterWhisper:
+			commandEntry.SetPlaceHolder(transcription.DefaultFasterWhisperCommand)
+			commandEntry.SetText(d.prefs.FasterWhisperCommand)
+		case transcription.BackendVosk:
+			commandEntry.SetPlaceHolder(transcription.DefaultVoskCommand)
+			commandEntry.SetText(d.prefs.VoskCommand)
+		default:
+			commandEntry.SetPlaceHolder("")
+			commandEntry.SetText("")
+		}
+	})
+	backendSelect.SetSelected(backends[0].Label)
+	for _, backend := range backends {
+		if backend.Name == d.prefs.TranscriptionBackend {
+			backendSelect.SetSelected(backend.Label)
+		}
+	}
+
+	// Language spoken, or detection for speakers switching languages
+	detectLanguage := i18n.T("Detect (may change between segments)")
+	languageNames := []string{detectLanguage}
+	for _, language := range transcription.Languages {
+		languageNames = append(languageNames, language.Name)
+	}
+	languageSelect := widget.NewSelect(languageNames, func(selected string) {
+		if selected == detectLanguage {
+			d.prefs.Language = transcription.LanguageAuto
+			return
+		}
+		d.prefs.Language = transcription.LanguageCode(selected)
+	})
+	if d.prefs.Language == transcription.LanguageAuto {
+		languageSelect.SetSelected(detectLanguage)
+	} else {
+		languageSelect.SetSelected(transcription.LanguageName(d.prefs.Language))
+	}
+
+	// Latency profile for live transcription
+	profileNames := make([]string, len(transcription.LatencyProfiles))
+	for i, profile := range transcription.LatencyProfiles {
+		profileNames[i] = i18n.T(latencyProfileLabels[profile])
+	}
+	profileSelect := widget.NewSelect(profileNames, func(selected string) {
+		for profile, label := range latencyProfileLabels {
+			if i18n.T(label) == selected {
+				d.prefs.LatencyProfile = string(profile)
+			}
+		}
+	})
+	if label, ok := latencyProfileLabels[transcription.LatencyProfile(d.prefs.LatencyProfile)]; ok {
+		profileSelect.SetSelected(i18n.T(label))
+	} else {
+		profileSelect.SetSelected(i18n.T(latencyProfileLabels[transcription.ProfileBalanced]))
+	}
+
+	// Idle release delay
+	idleEntry := widget.NewEntry()
+	idleEntry.SetText(strconv.Itoa(d.prefs.IdleReleaseMinutes))
+	idleEntry.OnChanged = func(text string) {
+		if minutes, err := strconv.Atoi(text); err == nil && minutes >= 0 {
+			d.prefs.IdleReleaseMinutes = minutes
+		}
+	}
+
+	// Pause that ends a paragraph while recording
+	silenceEntry := widget.NewEntry()
+	silenceEntry.SetText(strconv.FormatFloat(d.prefs.UtteranceSilenceSeconds, 'f', -1, 64))
+	silenceEntry.OnChanged = func(text string) {
+		if seconds, err := strconv.ParseFloat(text, 64); err == nil && seconds >= 0 {
+			d.prefs.UtteranceSilenceSeconds = seconds
+		}
+	}
+
+	// Rollover of long recordings into new segments
+	rolloverMinutesEntry := widget.NewEntry()
+	rolloverMinutesEntry.SetText(strconv.Itoa(d.prefs.RolloverMinutes))
+	rolloverMinutesEntry.OnChanged = func(text string) {
+		if minutes, err := strconv.Atoi(text); err == nil && minutes >= 0 {
+			d.prefs.RolloverMinutes = minutes
+		}
+	}
+	rolloverCharsEntry := widget.NewEntry()
+	rolloverCharsEntry.SetText(strconv.Itoa(d.prefs.RolloverCharacters))
+	rolloverCharsEntry.OnChanged = func(text string) {
+		if chars, err := strconv.Atoi(text); err == nil && chars >= 0 {
+			d.prefs.RolloverCharacters = chars
+		}
+	}
+
+	// Stopping recordings left running in silence
+	autoStopEntry := widget.NewEntry()
+	autoStopEntry.SetText(strconv.Itoa(d.prefs.AutoStopSilenceMinutes))
+	autoStopEntry.OnChanged = func(text string) {
+		if minutes, err := strconv.Atoi(text); err == nil && minutes >= 0 {
+			d.prefs.AutoStopSilenceMinutes = minutes
+		}
+	}
+
+	// Carrying text over between live windows
+	contextCheck := widget.NewCheck(i18n.T("Continue sentences from one live window to the next"), func(checked bool) {
+		d.prefs.KeepContext = checked
+	})
+	contextCheck.Checked = d.prefs.KeepContext
+
+	// Hallucination suppression
+	suppressCheck := widget.NewCheck(i18n.T("Drop phrases Whisper invents during silence"), func(checked bool) {
+		d.prefs.SuppressHallucinations = checked
+	})
+	suppressCheck.Checked = d.prefs.SuppressHallucinations
+
+	// Punctuation and casing cleanup of finalized segments
+	cleanupEntry := widget.NewEntry()
+	cleanupEntry.SetPlaceHolder(i18n.T("Built-in rules"))
+	cleanupEntry.SetText(d.prefs.CleanupCommand)
+	cleanupEntry.OnChanged = func(text string) {
+		d.prefs.CleanupCommand = strings.TrimSpace(text)
 	}
+	cleanupCheck := widget.NewCheck(i18n.T("Fix punctuation and casing of finished segments"), func(checked bool) {
+		d.prefs.CleanupSegments = checked
+		enable(cleanupEntry, checked)
+	})
+	cleanupCheck.SetChecked(d.prefs.CleanupSegments)
+	enable(cleanupEntry, d.prefs.CleanupSegments)
 
 	// Create the layout
 	return container.NewVBox(
-		widget.NewLabelWithStyle("Transcription Settings", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		widget.NewLabelWithStyle(i18n.T("Transcription Settings"), fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		container.NewGridWithColumns(2,
+			widget.NewLabel(i18n.T("Backend (after a restart):")),
+			backendSelect,
+		),
+		backendInfo,
+		container.NewGridWithColumns(2,
+			widget.NewLabel(i18n.T("whisper-server URL:")),
+			serverEntry,
+		),
+		container.NewGridWithColumns(2,
+			widget.NewLabel(i18n.T("Sidecar command:")),
+			commandEntry,
+		),
 		container.NewGridWithColumns(2,
-			widget.NewLabel("Model Size:"),
+			widget.NewLabel(i18n.T("Model Size:")),
 			modelSizeSelect,
 		),
+		container.NewGridWithColumns(2,
+			widget.NewLabel(i18n.T("Language:")),
+			languageSelect,
+		),
+		container.NewGridWithColumns(2,
+			widget.NewLabel(i18n.T("Latency profile:")),
+			profileSelect,
+		),
+		container.NewGridWithColumns(2,
+			widget.NewLabel(i18n.T("Rewrite segments with model:")),
+			rewriteSelect,
+		),
+		container.NewGridWithColumns(2,
+			widget.NewLabel(i18n.T("Free memory after idle (minutes, 0 = never):")),
+			idleEntry,
+		),
+		container.NewGridWithColumns(2,
+			widget.NewLabel(i18n.T("Start a new segment after a pause of (seconds, 0 = off):")),
+			silenceEntry,
+		),
+		container.NewGridWithColumns(2,
+			widget.NewLabel(i18n.T("Start a new segment every (minutes, 0 = off):")),
+			rolloverMinutesEntry,
+		),
+		container.NewGridWithColumns(2,
+			widget.NewLabel(i18n.T("Start a new segment after (characters, 0 = off):")),
+			rolloverCharsEntry,
+		),
+		container.NewGridWithColumns(2,
+			widget.NewLabel(i18n.T("Stop recording after silence of (minutes, 0 = never):")),
+			autoStopEntry,
+		),
+		container.NewPadded(contextCheck),
+		container.NewPadded(suppressCheck),
+		container.NewPadded(cleanupCheck),
+		container.NewGridWithColumns(2,
+			widget.NewLabel(i18n.T("Cleanup command (optional):")),
+			cleanupEntry,
+		),
 		widget.NewLabel(""), // Spacer
-		widget.NewLabel("Smaller models are faster but less accurate."),
-		widget.NewLabel("Larger models are more accurate but use more resources."),
+		widget.NewLabel(i18n.T("Smaller models are faster but less accurate.")),
+		widget.NewLabel(i18n.T("Larger models are more accurate but use more resources.")),
+		widget.NewLabel(i18n.T("Auto picks the largest installed model that fits in available memory.")),
+		widget.NewLabel(i18n.T("A new latency profile's model is used for live transcription after a restart.")),
+		widget.NewLabel(i18n.T("Rewriting shows tiny's text at once and replaces it with the chosen model's when ready.")),
+		widget.NewLabel(i18n.T("Other languages and detection need a multilingual model, not one ending in .en.")),
+		widget.NewLabel(i18n.T("Continuing sentences avoids stray capitals and repeated phrases, but can carry a mistake along.")),
 	)
 }
 
+// backendDescription describes a transcription backend and what it can do
+func backendDescription(backend transcription.BackendInfo) string {
+	text := backend.Description
+	if backend.StreamingNative {
+		text += " Recognizes speech as it arrives."
+	}
+	if !backend.Punctuation {
+		text += " Text is not punctuated."
+	}
+	if !backend.LocalModel {
+		text += " Uses its own model, not the model size below."
+	}
+	return text
+}
+
+// enable enables or disables a widget
+func enable(w fyne.Disableable, enabled bool) {
+	if enabled {
+		w.Enable()
+	} else {
+		w.Disable()
+	}
+}
+
+// latencyProfileLabels names each latency profile in the Transcription tab
+var latencyProfileLabels = map[transcription.LatencyProfile]string{
+	transcription.ProfileLowLatency: "Low latency",
+	transcription.ProfileBalanced:   "Balanced",
+	transcription.ProfileAccuracy:   "Accuracy",
+}
+
+// outputLabels names each kind of output in the Outputs tab
+var outputLabels = map[output.Kind]string{
+	output.KindClipboard: "Copy to clipboard",
+	output.KindFile:      "Append to file",
+	output.KindType:      "Type at cursor",
+	output.KindWebhook:   "Post to webhook",
+	output.KindStdout:    "Print to standard output",
+	output.KindPipe:      "Write to named pipe",
+}
+
+// appActionLabels names each action of an app rule in the Outputs tab
+var appActionLabels = map[string]string{
+	output.AppType:      "Type, don't copy",
+	output.AppClipboard: "Copy, don't type",
+	output.AppOff:       "Send nowhere",
+}
+
+// createOutputsTab creates the settings tab for the outputs that receive
+// every finalized recording. Several can be enabled at once.
+func (d *PreferencesDialog) createOutputsTab() fyne.CanvasObject {
+	configs := make(map[output.Kind]output.Config)
+	enabled := make(map[output.Kind]bool)
+	for _, cfg := range d.prefs.Outputs {
+		configs[cfg.Kind] = cfg
+		enabled[cfg.Kind] = true
+	}
+
+	// Keep the enabled outputs in the order they are shown
+	update := func() {
+		d.prefs.Outputs = nil
+		for _, kind := range output.Kinds {
+			if enabled[kind] {
+				cfg := configs[kind]
+				cfg.Kind = kind
+				d.prefs.Outputs = append(d.prefs.Outputs, cfg)
+			}
+		}
+	}
+
+	rows := container.NewVBox(
+		widget.NewLabelWithStyle(i18n.T("Outputs"), fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		widget.NewLabel(i18n.T("Send every finished recording to each enabled output.")),
+	)
+	for _, kind := range output.Kinds {
+		kind := kind
+		check := widget.NewCheck(i18n.T(outputLabels[kind]), func(checked bool) {
+			enabled[kind] = checked
+			update()
+		})
+		check.Checked = enabled[kind]
+		rows.Add(container.NewPadded(check))
+
+		switch kind {
+		case output.KindFile, output.KindWebhook, output.KindPipe:
+			targetEntry := widget.NewEntry()
+			targetEntry.SetPlaceHolder("~/Documents/ramble-{{date}}.txt")
+			switch kind {
+			case output.KindWebhook:
+				targetEntry.SetPlaceHolder("https://example.com/hook")
+			case output.KindPipe:
+				targetEntry.SetPlaceHolder("~/.cache/ramble/transcript.fifo")
+			}
+			targetEntry.SetText(configs[kind].Target)
+			targetEntry.OnChanged = func(text string) {
+				cfg := configs[kind]
+				cfg.Target = strings.TrimSpace(text)
+				configs[kind] = cfg
+				update()
+			}
+			label := i18n.T("File:")
+			switch kind {
+			case output.KindWebhook:
+				label = i18n.T("URL:")
+			case output.KindPipe:
+				label = i18n.T("Pipe:")
+			}
+			rows.Add(container.NewGridWithColumns(2, widget.NewLabel(label), targetEntry))
+		}
+
+		formatEntry := widget.NewEntry()
+		formatEntry.SetPlaceHolder(output.DefaultFormat)
+		formatEntry.SetText(configs[kind].Format)
+		formatEntry.OnChanged = func(text string) {
+			cfg := configs[kind]
+			cfg.Format = text
+			configs[kind] = cfg
+			update()
+		}
+		rows.Add(container.NewGridWithColumns(2, widget.NewLabel(i18n.T("Format:")), formatEntry))
+
+		numbersCheck := widget.NewCheck(i18n.T("Write numbers, dates and times as digits"), func(checked bool) {
+			cfg := configs[kind]
+			cfg.Numbers = checked
+			configs[kind] = cfg
+			update()
+		})
+		numbersCheck.Checked = configs[kind].Numbers
+		rows.Add(numbersCheck)
+	}
+	rows.Add(widget.NewLabel(i18n.T("Placeholders: {{text}}, {{date}}, {{time}}, {{datetime}}, {{session}}, {{segment}}.")))
+
+	// Locale used by outputs that write numbers as digits
+	var locales []string
+	for _, l := range textproc.NumberLocales {
+		locales = append(locales, l.Code)
+	}
+	localeSelect := widget.NewSelect(locales, func(selected string) {
+		d.prefs.NumberLocale = selected
+	})
+	localeSelect.SetSelected(d.prefs.NumberLocale)
+	if localeSelect.Selected == "" {
+		localeSelect.SetSelected(textproc.DefaultNumberLocale)
+	}
+	rows.Add(container.NewGridWithColumns(2, widget.NewLabel(i18n.T("Number format:")), localeSelect))
+
+	// App rules, one row each, edited in a copy so Cancel leaves them alone
+	d.prefs.AppOutputs = append([]output.AppRule(nil), d.prefs.AppOutputs...)
+	actionNames := make([]string, len(output.AppActions))
+	for i, action := range output.AppActions {
+		actionNames[i] = i18n.T(appActionLabels[action])
+	}
+	appRows := container.NewVBox()
+	var refreshApps func()
+	refreshApps = func() {
+		appRows.RemoveAll()
+		for i, rule := range d.prefs.AppOutputs {
+			i := i
+			matchEntry := widget.NewEntry()
+			matchEntry.SetPlaceHolder("firefox")
+			matchEntry.SetText(rule.Match)
+			matchEntry.OnChanged = func(text string) {
+				d.prefs.AppOutputs[i].Match = strings.TrimSpace(text)
+			}
+			actionSelect := widget.NewSelect(actionNames, func(selected string) {
+				for action, label := range appActionLabels {
+					if i18n.T(label) == selected {
+						d.prefs.AppOutputs[i].Action = action
+					}
+				}
+			})
+			actionSelect.SetSelected(i18n.T(appActionLabels[rule.Action]))
+			removeButton := widget.NewButtonWithIcon("", theme.DeleteIcon(), func() {
+				d.prefs.AppOutputs = append(d.prefs.AppOutputs[:i:i], d.prefs.AppOutputs[i+1:]...)
+				refreshApps()
+			})
+			appRows.Add(container.NewBorder(nil, nil, nil, removeButton, container.NewGridWithColumns(2, matchEntry, actionSelect)))
+		}
+	}
+	refreshApps()
+	addAppButton := widget.NewButtonWithIcon(i18n.T("Add Rule"), theme.ContentAddIcon(), func() {
+		d.prefs.AppOutputs = append(d.prefs.AppOutputs, output.AppRule{Action: output.AppType})
+		refreshApps()
+	})
+
+	rows.Add(widget.NewLabelWithStyle(i18n.T("Applications"), fyne.TextAlignLeading, fyne.TextStyle{Bold: true}))
+	rows.Add(widget.NewLabel(i18n.T("While an application whose window class or title contains the text has focus,\n" +
+		"its rule replaces the clipboard and typing outputs. The first matching rule applies.")))
+	rows.Add(appRows)
+	rows.Add(container.NewHBox(addAppButton))
+	rows.Add(widget.NewLabel(i18n.T("\"Send nowhere\" turns off every output, e.g. for password managers. Needs xprop on Linux.")))
+
+	return container.NewVScroll(rows)
+}
+
+// createNotesTab creates the settings tab for appending recordings to notes
+func (d *PreferencesDialog) createNotesTab() fyne.CanvasObject {
+	enabledCheck := widget.NewCheck(i18n.T("Append each recording to a note when it stops"), func(checked bool) {
+		d.prefs.NotesEnabled = checked
+	})
+	enabledCheck.Checked = d.prefs.NotesEnabled
+
+	pathEntry := widget.NewEntry()
+	pathEntry.SetPlaceHolder("~/Vault/Daily/{{date}}.md")
+	pathEntry.SetText(d.prefs.NotesPath)
+	pathEntry.OnChanged = func(text string) {
+		d.prefs.NotesPath = strings.TrimSpace(text)
+	}
+
+	// Pick the vault folder; the daily note file name is kept
+	chooseFolderButton := widget.NewButton(i18n.T("Choose Folder"), func() {
+		dialog.ShowFolderOpen(func(uri fyne.ListableURI, err error) {
+			if err != nil {
+				log.Println("Error selecting folder:", err)
+				return
+			}
+			if uri == nil {
+				return
+			}
+			pathEntry.SetText(filepath.Join(uri.Path(), "{{date}}.md"))
+		}, d.window)
+	})
+
+	templateEntry := widget.NewMultiLineEntry()
+	templateEntry.SetMinRowsVisible(4)
+	templateEntry.SetText(d.prefs.NotesTemplate)
+	templateEntry.OnChanged = func(text string) {
+		d.prefs.NotesTemplate = text
+	}
+
+	return container.NewVBox(
+		widget.NewLabelWithStyle(i18n.T("Notes"), fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		container.NewPadded(enabledCheck),
+		container.NewGridWithColumns(2,
+			widget.NewLabel(i18n.T("Note file:")),
+			container.NewBorder(nil, nil, nil, chooseFolderButton, pathEntry),
+		),
+		widget.NewLabel(i18n.T("Template:")),
+		templateEntry,
+		widget.NewLabel(i18n.T("Placeholders: {{text}}, {{date}}, {{time}}, {{datetime}}, {{session}}.\n"+
+			"Use {{date}} in the file name to write to a daily note, e.g. in an Obsidian vault.")),
+	)
+}
+
+// createMQTTTab creates the settings tab for publishing transcripts over MQTT
+func (d *PreferencesDialog) createMQTTTab() fyne.CanvasObject {
+	enabledCheck := widget.NewCheck(i18n.T("Publish transcripts to an MQTT broker"), func(checked bool) {
+		d.prefs.MQTTEnabled = checked
+	})
+	enabledCheck.Checked = d.prefs.MQTTEnabled
+
+	brokerEntry := widget.NewEntry()
+	brokerEntry.SetPlaceHolder("localhost:1883")
+	brokerEntry.SetText(d.prefs.MQTTBroker)
+	brokerEntry.OnChanged = func(text string) {
+		d.prefs.MQTTBroker = strings.TrimSpace(text)
+	}
+
+	usernameEntry := widget.NewEntry()
+	usernameEntry.SetPlaceHolder(i18n.T("Optional"))
+	usernameEntry.SetText(d.prefs.MQTTUsername)
+	usernameEntry.OnChanged = func(text string) {
+		d.prefs.MQTTUsername = strings.TrimSpace(text)
+	}
+
+	passwordEntry := widget.NewPasswordEntry()
+	passwordEntry.SetPlaceHolder(i18n.T("Optional"))
+	passwordEntry.SetText(d.prefs.MQTTPassword)
+	passwordEntry.OnChanged = func(text string) {
+		d.prefs.MQTTPassword = text
+	}
+
+	topicEntry := widget.NewEntry()
+	topicEntry.SetPlaceHolder("ramble")
+	topicEntry.SetText(d.prefs.MQTTTopic)
+	topicEntry.OnChanged = func(text string) {
+		d.prefs.MQTTTopic = strings.TrimSpace(text)
+	}
+
+	partialCheck := widget.NewCheck(i18n.T("Also publish text while it is being transcribed"), func(checked bool) {
+		d.prefs.MQTTPublishPartial = checked
+	})
+	partialCheck.Checked = d.prefs.MQTTPublishPartial
+
+	return container.NewVBox(
+		widget.NewLabelWithStyle("MQTT", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		container.NewPadded(enabledCheck),
+		container.NewGridWithColumns(2,
+			widget.NewLabel(i18n.T("Broker:")),
+			brokerEntry,
+		),
+		container.NewGridWithColumns(2,
+			widget.NewLabel(i18n.T("User name:")),
+			usernameEntry,
+		),
+		container.NewGridWithColumns(2,
+			widget.NewLabel(i18n.T("Password:")),
+			passwordEntry,
+		),
+		container.NewGridWithColumns(2,
+			widget.NewLabel(i18n.T("Topic:")),
+			topicEntry,
+		),
+		container.NewPadded(partialCheck),
+		widget.NewLabel(i18n.T("Finished recordings are published to <topic>/final and live text to <topic>/partial.")),
+	)
+}
+
+// createSummaryTab creates the settings tab for summarizing sessions with a
+// language model
+func (d *PreferencesDialog) createSummaryTab() fyne.CanvasObject {
+	enabledCheck := widget.NewCheck(i18n.T("Summarize sessions with a language model"), func(checked bool) {
+		d.prefs.SummaryEnabled = checked
+	})
+	enabledCheck.Checked = d.prefs.SummaryEnabled
+
+	urlEntry := widget.NewEntry()
+	urlEntry.SetText(d.prefs.SummaryURL)
+	urlEntry.OnChanged = func(text string) {
+		d.prefs.SummaryURL = strings.TrimSpace(text)
+	}
+
+	modelEntry := widget.NewEntry()
+	modelEntry.SetPlaceHolder("llama3.2")
+	modelEntry.SetText(d.prefs.SummaryModel)
+	modelEntry.OnChanged = func(text string) {
+		d.prefs.SummaryModel = strings.TrimSpace(text)
+	}
+
+	keyEntry := widget.NewPasswordEntry()
+	keyEntry.SetPlaceHolder(i18n.T("Optional"))
+	keyEntry.SetText(d.prefs.SummaryAPIKey)
+	keyEntry.OnChanged = func(text string) {
+		d.prefs.SummaryAPIKey = strings.TrimSpace(text)
+	}
+
+	const ollamaOption = "Ollama"
+	openAIOption := i18n.T("OpenAI-compatible")
+	providerSelect := widget.NewSelect([]string{ollamaOption, openAIOption}, func(selected string) {
+		if selected == openAIOption {
+			d.prefs.SummaryProvider = llm.ProviderOpenAI
+			urlEntry.SetPlaceHolder("https://api.openai.com")
+		} else {
+			d.prefs.SummaryProvider = llm.ProviderOllama
+			urlEntry.SetPlaceHolder(llm.DefaultOllamaURL)
+		}
+	})
+	if d.prefs.SummaryProvider == llm.ProviderOpenAI {
+		providerSelect.SetSelected(openAIOption)
+	} else {
+		providerSelect.SetSelected(ollamaOption)
+	}
+
+	promptEntry := widget.NewMultiLineEntry()
+	promptEntry.SetMinRowsVisible(6)
+	promptEntry.Wrapping = fyne.TextWrapWord
+	promptEntry.SetPlaceHolder(llm.DefaultPrompt)
+	promptEntry.SetText(d.prefs.SummaryPrompt)
+	promptEntry.OnChanged = func(text string) {
+		d.prefs.SummaryPrompt = text
+	}
+
+	return container.NewVBox(
+		widget.NewLabelWithStyle(i18n.T("Summary"), fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		container.NewPadded(enabledCheck),
+		container.NewGridWithColumns(2,
+			widget.NewLabel(i18n.T("Provider:")),
+			providerSelect,
+		),
+		container.NewGridWithColumns(2,
+			widget.NewLabel(i18n.T("Server URL:")),
+			urlEntry,
+		),
+		container.NewGridWithColumns(2,
+			widget.NewLabel(i18n.T("Model:")),
+			modelEntry,
+		),
+		container.NewGridWithColumns(2,
+			widget.NewLabel(i18n.T("API key:")),
+			keyEntry,
+		),
+		widget.NewLabel(i18n.T("Prompt (leave empty for the default):")),
+		promptEntry,
+		widget.NewLabel(i18n.T("{{transcript}} is replaced by the session transcript, redacted like copied text.\n"+
+			"Use the Summary tab of a session to summarize it.")),
+	)
+}
+
+// createReadAloudTab creates the settings tab for reading segments aloud
+// with a speech synthesizer
+func (d *PreferencesDialog) createReadAloudTab() fyne.CanvasObject {
+	automaticOption := i18n.T("Automatic")
+	options := []string{automaticOption}
+	for _, engine := range tts.Engines {
+		options = append(options, engine.Label)
+	}
+	engineSelect := widget.NewSelect(options, func(selected string) {
+		d.prefs.TTSEngine = tts.EngineAuto
+		for _, engine := range tts.Engines {
+			if engine.Label == selected {
+				d.prefs.TTSEngine = engine.Name
+			}
+		}
+	})
+	engineSelect.SetSelected(automaticOption)
+	for _, engine := range tts.Engines {
+		if engine.Name == d.prefs.TTSEngine {
+			engineSelect.SetSelected(engine.Label)
+		}
+	}
+
+	voiceEntry := widget.NewEntry()
+	voiceEntry.SetPlaceHolder(i18n.T("Default"))
+	voiceEntry.SetText(d.prefs.TTSVoice)
+	voiceEntry.OnChanged = func(text string) {
+		d.prefs.TTSVoice = strings.TrimSpace(text)
+	}
+
+	return container.NewVBox(
+		widget.NewLabelWithStyle(i18n.T("Read Aloud"), fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		container.NewGridWithColumns(2,
+			widget.NewLabel(i18n.T("Speech synthesizer:")),
+			engineSelect,
+		),
+		container.NewGridWithColumns(2,
+			widget.NewLabel(i18n.T("Voice:")),
+			voiceEntry,
+		),
+		widget.NewLabel(i18n.T("Use Read Aloud on a segment or on selected text to hear it.\n"+
+			"Automatic uses Windows speech, macOS say, Piper or eSpeak NG, whichever is installed.\n"+
+			"Piper needs the path of a voice model (.onnx) as the voice.")),
+	)
+}
+
+// createReplacementsTab creates the settings tab for replacements applied to
+// transcribed text, with import and export of the dictionary as a text file
+func (d *PreferencesDialog) createReplacementsTab() fyne.CanvasObject {
+	statusLabel := widget.NewLabel("")
+	replacementsEntry := widget.NewMultiLineEntry()
+	replacementsEntry.SetMinRowsVisible(8)
+	replacementsEntry.SetPlaceHolder(i18n.T("ramble => Ramble\nbrb => be right back\n/(\\d+) percent/ => $1%"))
+	replacementsEntry.SetText(strings.Join(d.prefs.Corrections, "\n"))
+	replacementsEntry.OnChanged = func(text string) {
+		d.prefs.Corrections = nil
+		for _, line := range strings.Split(text, "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				d.prefs.Corrections = append(d.prefs.Corrections, line)
+			}
+		}
+		if _, err := textproc.ParseCorrections(text); err != nil {
+			statusLabel.SetText(err.Error())
+		} else {
+			statusLabel.SetText("")
+		}
+	}
+
+	importButton := widget.NewButtonWithIcon(i18n.T("Import..."), theme.FolderOpenIcon(), func() {
+		openDialog := dialog.NewFileOpen(func(reader fyne.URIReadCloser, err error) {
+			if err != nil {
+				dialog.ShowError(err, d.window)
+				return
+			}
+			if reader == nil {
+				return // Cancelled
+			}
+			defer reader.Close()
+			data, err := io.ReadAll(reader)
+			if err != nil {
+				dialog.ShowError(err, d.window)
+				return
+			}
+			rules, err := textproc.ParseCorrections(string(data))
+			if err != nil {
+				dialog.ShowError(fmt.Errorf("%s: %w", reader.URI().Name(), err), d.window)
+				return
+			}
+
+			// Imported rules are added after the existing ones
+			text := strings.TrimSpace(replacementsEntry.Text)
+			if text != "" {
+				text += "\n"
+			}
+			replacementsEntry.SetText(text + textproc.FormatCorrections(rules))
+			statusLabel.SetText(i18n.Tf("Imported %d replacements", len(rules)))
+		}, d.window)
+		openDialog.SetFilter(storage.NewExtensionFileFilter([]string{".txt"}))
+		openDialog.Show()
+	})
+	exportButton := widget.NewButtonWithIcon(i18n.T("Export..."), theme.DocumentSaveIcon(), func() {
+		saveDialog := dialog.NewFileSave(func(writer fyne.URIWriteCloser, err error) {
+			if err != nil {
+				dialog.ShowError(err, d.window)
+				return
+			}
+			if writer == nil {
+				return // Cancelled
+			}
+			defer writer.Close()
+			if _, err := io.WriteString(writer, strings.Join(d.prefs.Corrections, "\n")+"\n"); err != nil {
+				dialog.ShowError(fmt.Errorf(i18n.T("Failed to export replacements: %v"), err), d.window)
+				return
+			}
+			statusLabel.SetText(i18n.Tf("Exported to %s", writer.URI().Name()))
+		}, d.window)
+		saveDialog.SetFileName("ramble-replacements.txt")
+		saveDialog.Show()
+	})
+
+	return container.NewVBox(
+		widget.NewLabelWithStyle(i18n.T("Text Replacements"), fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		widget.NewLabel(i18n.T("One replacement per line, written as \"from => to\". Replacements are applied\n"+
+			"in order to transcribed text before it is shown.")),
+		replacementsEntry,
+		container.NewHBox(importButton, exportButton, statusLabel),
+		widget.NewLabel(i18n.T("Words match whole words, ignoring case. Write /pattern/ for a regular expression,\n"+
+			"whose groups can be used in the replacement as $1, $2 and so on.")),
+	)
+}
+
+// createKeywordsTab creates the settings tab for keywords that tag segments
+// and show a notification when heard
+func (d *PreferencesDialog) createKeywordsTab() fyne.CanvasObject {
+	keywordsEntry := widget.NewMultiLineEntry()
+	keywordsEntry.SetMinRowsVisible(8)
+	keywordsEntry.SetPlaceHolder(i18n.T("action item\nyour name\n/project (bluebird|redwood)/"))
+	keywordsEntry.SetText(strings.Join(d.prefs.WatchKeywords, "\n"))
+	keywordsEntry.OnChanged = func(text string) {
+		d.prefs.WatchKeywords = nil
+		for _, line := range strings.Split(text, "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				d.prefs.WatchKeywords = append(d.prefs.WatchKeywords, line)
+			}
+		}
+	}
+
+	return container.NewVBox(
+		widget.NewLabelWithStyle(i18n.T("Watched Keywords"), fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		widget.NewLabel(i18n.T("One phrase per line. When a finished segment contains one, it is tagged\n"+
+			"in the session history and a desktop notification is shown.")),
+		keywordsEntry,
+		widget.NewLabel(i18n.T("Phrases match whole words, ignoring case. Write /pattern/ for a regular expression.")),
+	)
+}
+
+// createCommandsTab creates the settings tab for phrases that run commands
+// in command mode
+func (d *PreferencesDialog) createCommandsTab() fyne.CanvasObject {
+	errorLabel := widget.NewLabel("")
+	commandsEntry := widget.NewMultiLineEntry()
+	commandsEntry.SetMinRowsVisible(8)
+	commandsEntry.SetPlaceHolder(i18n.T("open browser => xdg-open https://\nnext track => playerctl next\nnew tab => keys: ctrl+t"))
+	commandsEntry.SetText(strings.Join(d.prefs.VoiceCommands, "\n"))
+	commandsEntry.OnChanged = func(text string) {
+		d.prefs.VoiceCommands = nil
+		for _, line := range strings.Split(text, "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				d.prefs.VoiceCommands = append(d.prefs.VoiceCommands, line)
+			}
+		}
+		if _, err := commands.Parse(text); err != nil {
+			errorLabel.SetText(err.Error())
+		} else {
+			errorLabel.SetText("")
+		}
+	}
+
+	return container.NewVBox(
+		widget.NewLabelWithStyle(i18n.T("Voice Commands"), fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		widget.NewLabel(i18n.T("One command per line, written as \"phrase => action\". In command mode (Ctrl+Shift+M),\n"+
+			"each phrase you say runs its action instead of being added to the transcript.")),
+		commandsEntry,
+		errorLabel,
+		widget.NewLabel(i18n.T("An action is a shell command, or a key combination after \"keys:\".\n"+
+			"Phrases match the whole utterance, ignoring case and punctuation.")),
+	)
+}
+
+// createHooksTab creates the settings tab for programs run on each
+// finalized segment
+func (d *PreferencesDialog) createHooksTab() fyne.CanvasObject {
+	hooksEntry := widget.NewMultiLineEntry()
+	hooksEntry.SetMinRowsVisible(8)
+	hooksEntry.SetPlaceHolder(i18n.T("~/bin/expand-abbreviations\npython3 /path/to/translate.py --to de"))
+	hooksEntry.SetText(strings.Join(d.prefs.Hooks, "\n"))
+	hooksEntry.OnChanged = func(text string) {
+		d.prefs.Hooks = nil
+		for _, line := range strings.Split(text, "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				d.prefs.Hooks = append(d.prefs.Hooks, line)
+			}
+		}
+	}
+
+	return container.NewVBox(
+		widget.NewLabelWithStyle(i18n.T("Hooks"), fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		widget.NewLabel(i18n.T("One program and its arguments per line. Each finished segment is passed through\n"+
+			"them in order, and text a program prints replaces the segment's text.")),
+		hooksEntry,
+		widget.NewLabel(i18n.T("Programs read the text on standard input and find the segment's session,\n"+
+			"number and language in RAMBLE_ environment variables. Hooks don't run while incognito.")),
+	)
+}
+
+// createPrivacyTab creates the redaction and usage statistics settings tab
+func (d *PreferencesDialog) createPrivacyTab() fyne.CanvasObject {
+	redactChecks := container.NewVBox()
+	for _, option := range []struct {
+		label string
+		value *bool
+	}{
+		{"Mask profanity", &d.prefs.RedactProfanity},
+		{"Mask email addresses", &d.prefs.RedactEmails},
+		{"Mask phone numbers", &d.prefs.RedactPhoneNumbers},
+		{"Mask card numbers", &d.prefs.RedactCardNumbers},
+		{"Apply to text shown in the window", &d.prefs.RedactDisplay},
+		{"Apply to text copied to the clipboard", &d.prefs.RedactClipboard},
+		{"Apply to saved transcripts", &d.prefs.RedactSaved},
+	} {
+		value := option.value
+		check := widget.NewCheck(i18n.T(option.label), func(checked bool) {
+			*value = checked
+		})
+		check.Checked = *value
+		redactChecks.Add(check)
+	}
+
+	sessionsCheck := widget.NewCheck(i18n.T("Record sessions, minutes transcribed and models used"), func(checked bool) {
+		d.prefs.AnalyticsSessions = checked
+	})
+	sessionsCheck.Checked = d.prefs.AnalyticsSessions
+
+	featuresCheck := widget.NewCheck(i18n.T("Record how often each feature is used"), func(checked bool) {
+		d.prefs.AnalyticsFeatures = checked
+	})
+	featuresCheck.Checked = d.prefs.AnalyticsFeatures
+
+	return container.NewVBox(
+		widget.NewLabelWithStyle(i18n.T("Redaction"), fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		container.NewPadded(redactChecks),
+		widget.NewLabelWithStyle(i18n.T("Usage Statistics"), fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		widget.NewLabel(i18n.T("Statistics are stored only on this computer and are never sent anywhere.\n"+
+			"View them with the Usage Statistics button in the main window.")),
+		container.NewPadded(sessionsCheck),
+		container.NewPadded(featuresCheck),
+	)
+}
+
+// logLevelOptions are the levels offered in the logging tab
+var logLevelOptions = []string{"debug", "info", "warn", "error", "silent"}
+
+// defaultLevelOption is chosen for a category that uses the overall level
+const defaultLevelOption = "default"
+
+// createLoggingTab creates the settings tab for log levels and log files.
+// Changes take effect when the preferences are saved, without restarting.
+func (d *PreferencesDialog) createLoggingTab() fyne.CanvasObject {
+	levelSelect := widget.NewSelect(logLevelOptions, func(selected string) {
+		d.prefs.LogLevel = selected
+	})
+	levelSelect.SetSelected(d.prefs.LogLevel)
+	if levelSelect.Selected == "" {
+		levelSelect.SetSelected("info")
+	}
+
+	// Edit a copy so cancelling leaves the saved overrides untouched
+	d.prefs.LogCategoryLevels = maps.Clone(d.prefs.LogCategoryLevels)
+	categoryRows := container.NewVBox()
+	for _, category := range logger.Categories {
+		name := string(category)
+		categorySelect := widget.NewSelect(append([]string{defaultLevelOption}, logLevelOptions...), func(selected string) {
+			if selected == defaultLevelOption {
+				delete(d.prefs.LogCategoryLevels, name)
+				return
+			}
+			if d.prefs.LogCategoryLevels == nil {
+				d.prefs.LogCategoryLevels = make(map[string]string)
+			}
+			d.prefs.LogCategoryLevels[name] = selected
+		})
+		if level, ok := d.prefs.LogCategoryLevels[name]; ok {
+			categorySelect.SetSelected(level)
+		} else {
+			categorySelect.SetSelected(defaultLevelOption)
+		}
+		categoryRows.Add(container.NewGridWithColumns(2,
+			widget.NewLabel(name[:1]+strings.ToLower(name[1:])+":"),
+			categorySelect,
+		))
+	}
+
+	jsonCheck := widget.NewCheck(i18n.T("Write logs as JSON, one object per line"), func(checked bool) {
+		d.prefs.LogJSON = checked
+	})
+	jsonCheck.Checked = d.prefs.LogJSON
+
+	fileCheck := widget.NewCheck(i18n.T("Also write logs to a file"), func(checked bool) {
+		d.prefs.LogToFile = checked
+	})
+	fileCheck.Checked = d.prefs.LogToFile
+
+	return container.NewVBox(
+		widget.NewLabelWithStyle(i18n.T("Logging"), fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		container.NewGridWithColumns(2,
+			widget.NewLabel(i18n.T("Level:")),
+			levelSelect,
+		),
+		widget.NewLabel(i18n.T("Per-category levels:")),
+		categoryRows,
+		container.NewPadded(jsonCheck),
+		container.NewPadded(fileCheck),
+		widget.NewLabel(i18n.T("Log files are kept in ~/.ramble/logs and rotated as they grow.")),
+	)
+}
+
+// createAdvancedTab creates the settings tab for how whisper decodes speech,
+// set separately for each backend running whisper
+func (d *PreferencesDialog) createAdvancedTab() fyne.CanvasObject {
+	// Edit a copy so cancelling leaves the saved settings untouched
+	d.prefs.Decoding = maps.Clone(d.prefs.Decoding)
+	if d.prefs.Decoding == nil {
+		d.prefs.Decoding = make(map[string]transcription.Decoding)
+	}
+
+	var backends []transcription.BackendInfo
+	for _, backend := range transcription.AvailableBackends() {
+		if backend.Decoding {
+			backends = append(backends, backend)
+		}
+	}
+	backend := backends[0].Name // Backend whose settings are shown
+
+	// current returns the settings of the backend shown
+	current := func() transcription.Decoding {
+		if decoding, ok := d.prefs.Decoding[backend]; ok {
+			return decoding
+		}
+		decoding, _ := transcription.DefaultDecoding(backend)
+		return decoding
+	}
+	// change keeps an edit of the backend's settings once they are valid
+	change := func(edit func(decoding *transcription.Decoding)) {
+		decoding := current()
+		edit(&decoding)
+		if decoding != current() && decoding.Validate() == nil {
+			d.prefs.Decoding[backend] = decoding
+		}
+	}
+	floatEntry := func(edit func(decoding *transcription.Decoding, value float64)) *widget.Entry {
+		entry := widget.NewEntry()
+		entry.OnChanged = func(text string) {
+			if value, err := strconv.ParseFloat(text, 64); err == nil {
+				change(func(decoding *transcription.Decoding) { edit(decoding, value) })
+			}
+		}
+		return entry
+	}
+
+	temperatureEntry := floatEntry(func(decoding *transcription.Decoding, value float64) {
+		decoding.Temperature = value
+	})
+	fallbackEntry := floatEntry(func(decoding *transcription.Decoding, value float64) {
+		decoding.TemperatureFallback = value
+	})
+	entropyEntry := floatEntry(func(decoding *transcription.Decoding, value float64) {
+		decoding.EntropyThreshold = value
+	})
+	noSpeechEntry := floatEntry(func(decoding *transcription.Decoding, value float64) {
+		decoding.NoSpeechThreshold = value
+	})
+	beamEntry := widget.NewEntry()
+	beamEntry.OnChanged = func(text string) {
+		if size, err := strconv.Atoi(text); err == nil {
+			change(func(decoding *transcription.Decoding) { decoding.BeamSize = size })
+		}
+	}
+
+	show := func() {
+		decoding := current()
+		temperatureEntry.SetText(strconv.FormatFloat(decoding.Temperature, 'f', -1, 64))
+		fallbackEntry.SetText(strconv.FormatFloat(decoding.TemperatureFallback, 'f', -1, 64))
+		beamEntry.SetText(strconv.Itoa(decoding.BeamSize))
+		entropyEntry.SetText(strconv.FormatFloat(decoding.EntropyThreshold, 'f', -1, 64))
+		noSpeechEntry.SetText(strconv.FormatFloat(decoding.NoSpeechThreshold, 'f', -1, 64))
+	}
+
+	backendLabels := make([]string, len(backends))
+	for i, b := range backends {
+		backendLabels[i] = b.Label
+	}
+	backendSelect := widget.NewSelect(backendLabels, func(selected string) {
+		for _, b := range backends {
+			if b.Label == selected {
+				backend = b.Name
+			}
+		}
+		show()
+	})
+	backendSelect.SetSelected(backends[0].Label)
+	for _, b := range backends {
+		if b.Name == d.prefs.TranscriptionBackend {
+			backendSelect.SetSelected(b.Label)
+		}
+	}
+
+	resetButton := widget.NewButton(i18n.T("Reset to Defaults"), func() {
+		delete(d.prefs.Decoding, backend)
+		show()
+	})
+
+	return container.NewVScroll(container.NewVBox(
+		widget.NewLabelWithStyle(i18n.T("Whisper Decoding"), fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		widget.NewLabel(i18n.T("How Whisper turns speech into text, and when it rejects text as invented.\nThe defaults suit most recordings; invalid values are ignored.")),
+		container.NewGridWithColumns(2,
+			widget.NewLabel(i18n.T("Backend:")),
+			backendSelect,
+		),
+		container.NewGridWithColumns(2,
+			widget.NewLabel(i18n.T("Temperature (0 to 1, 0 = most likely text):")),
+			temperatureEntry,
+		),
+		container.NewGridWithColumns(2,
+			widget.NewLabel(i18n.T("Temperature fallback (0 = never retry):")),
+			fallbackEntry,
+		),
+		container.NewGridWithColumns(2,
+			widget.NewLabel(i18n.T("Beam size (1 = fastest):")),
+			beamEntry,
+		),
+		container.NewGridWithColumns(2,
+			widget.NewLabel(i18n.T("Entropy threshold (retry repetitive text):")),
+			entropyEntry,
+		),
+		container.NewGridWithColumns(2,
+			widget.NewLabel(i18n.T("No-speech threshold (0 to 1):")),
+			noSpeechEntry,
+		),
+		resetButton,
+	))
+}
+
 // Helper functions
 
 // intToString converts an int to string
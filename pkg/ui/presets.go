@@ -0,0 +1,38 @@
+package ui
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/driver/desktop"
+
+	"github.com/jeff-barlow-spady/ramble/pkg/logger"
+)
+
+// presetKeys are the keys that apply the first nine presets with Ctrl+Alt
+var presetKeys = []fyne.KeyName{fyne.Key1, fyne.Key2, fyne.Key3, fyne.Key4, fyne.Key5, fyne.Key6, fyne.Key7, fyne.Key8, fyne.Key9}
+
+// SetPresets offers the named presets in the tray menu and on Ctrl+Alt+1 to
+// 9, in order, with active checked. onSelect is called with the name of the
+// preset chosen and should apply it and call SetActivePreset.
+func (a *App) SetPresets(names []string, active string, onSelect func(name string)) {
+	a.systray.SetPresets(names, active, onSelect)
+
+	for i, key := range presetKeys {
+		shortcut := &desktop.CustomShortcut{KeyName: key, Modifier: fyne.KeyModifierControl | fyne.KeyModifierAlt}
+		if i >= len(names) {
+			a.mainWindow.Canvas().RemoveShortcut(shortcut)
+			continue
+		}
+		name := names[i]
+		a.mainWindow.Canvas().AddShortcut(shortcut, func(fyne.Shortcut) {
+			if !a.isTestMode && a.keyHandlerEnabled {
+				logger.Info(logger.CategoryUI, "Preset shortcut triggered for %s", name)
+				onSelect(name)
+			}
+		})
+	}
+}
+
+// SetActivePreset checks the preset applied in the tray menu
+func (a *App) SetActivePreset(name string) {
+	a.systray.SetActivePreset(name)
+}
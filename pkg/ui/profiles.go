@@ -0,0 +1,93 @@
+package ui
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/app"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/jeff-barlow-spady/ramble/pkg/i18n"
+	"github.com/jeff-barlow-spady/ramble/pkg/resources"
+)
+
+// defaultProfileLabel is shown in the profile list for the default profile
+const defaultProfileLabel = "Default"
+
+// sharedFyneApp is the Fyne application used by every window. Fyne can only
+// run one event loop per process, so the profile chooser and the main window
+// must share it.
+var sharedFyneApp fyne.App
+
+// fyneApplication returns the shared Fyne application, creating it on first use
+func fyneApplication() fyne.App {
+	if sharedFyneApp == nil {
+		sharedFyneApp = app.New()
+		sharedFyneApp.Settings().SetTheme(NewRambleTheme(true))
+		if icon := resources.LoadAppIcon(); icon != nil {
+			sharedFyneApp.SetIcon(icon)
+		}
+	}
+	return sharedFyneApp
+}
+
+// ChooseProfile shows a window for picking a user profile at startup and runs
+// the UI event loop until the application quits. onChosen is called with the
+// chosen profile name ("" for the default profile) and should create the main
+// window and call Show on it. validate checks names typed for a new profile.
+func ChooseProfile(profiles []string, validate func(string) error, onChosen func(string)) {
+	fyneApp := fyneApplication()
+	window := fyneApp.NewWindow(i18n.T("Ramble - Choose Profile"))
+	window.Resize(fyne.NewSize(360, 320))
+
+	options := append([]string{i18n.T(defaultProfileLabel)}, profiles...)
+	selected := -1
+	list := widget.NewList(
+		func() int { return len(options) },
+		func() fyne.CanvasObject { return widget.NewLabel("") },
+		func(id widget.ListItemID, obj fyne.CanvasObject) {
+			obj.(*widget.Label).SetText(options[id])
+		},
+	)
+
+	choose := func(name string) {
+		onChosen(name)
+		// Close after the main window exists so the application keeps running
+		window.Close()
+	}
+
+	continueButton := widget.NewButton(i18n.T("Continue"), func() {
+		if selected == 0 {
+			choose("")
+		} else if selected > 0 {
+			choose(options[selected])
+		}
+	})
+	continueButton.Importance = widget.HighImportance
+	continueButton.Disable()
+	list.OnSelected = func(id widget.ListItemID) {
+		selected = id
+		continueButton.Enable()
+	}
+
+	newButton := widget.NewButton(i18n.T("New Profile..."), func() {
+		nameEntry := widget.NewEntry()
+		nameEntry.Validator = validate
+		dialog.ShowForm(i18n.T("New Profile"), i18n.T("Create"), i18n.T("Cancel"),
+			[]*widget.FormItem{widget.NewFormItem(i18n.T("Name"), nameEntry)},
+			func(confirmed bool) {
+				if confirmed {
+					choose(nameEntry.Text)
+				}
+			}, window)
+	})
+
+	window.SetContent(container.NewBorder(
+		widget.NewLabelWithStyle(i18n.T("Who is using Ramble?"), fyne.TextAlignCenter, fyne.TextStyle{Bold: true}),
+		container.NewPadded(container.NewGridWithColumns(2, newButton, continueButton)),
+		nil, nil,
+		list,
+	))
+	window.CenterOnScreen()
+	window.ShowAndRun()
+}
@@ -0,0 +1,66 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"fyne.io/fyne/v2/dialog"
+
+	"github.com/jeff-barlow-spady/ramble/pkg/i18n"
+	"github.com/jeff-barlow-spady/ramble/pkg/logger"
+)
+
+// reading is the text being read aloud
+type reading struct {
+	mu     sync.Mutex
+	text   string
+	stop   context.CancelFunc // Stops the reading; nil when nothing is read
+	number int                // Counts readings, so one that ended can't clear a newer one
+}
+
+// SetReadAloudCallback sets the function that speaks text, returning once it
+// was spoken or ctx was cancelled. Without it Read Aloud is hidden.
+func (a *App) SetReadAloudCallback(onReadAloud func(ctx context.Context, text string) error) {
+	a.onReadAloud = onReadAloud
+}
+
+// readAloud speaks text in the background, stopping anything being read.
+// Reading the same text again only stops it.
+func (a *App) readAloud(text string) {
+	r := &a.reading
+	r.mu.Lock()
+	stop, current := r.stop, r.text
+	r.stop, r.text = nil, ""
+	if stop != nil {
+		stop()
+		if current == text {
+			r.mu.Unlock()
+			a.ShowTemporaryStatus(i18n.T("Stopped reading aloud"), 2*time.Second)
+			return
+		}
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	r.number++
+	number := r.number
+	r.stop, r.text = cancel, text
+	r.mu.Unlock()
+
+	a.ShowTemporaryStatus(i18n.T("Reading aloud... (choose Read Aloud again to stop)"), 3*time.Second)
+	go func() {
+		err := a.onReadAloud(ctx, text)
+
+		r.mu.Lock()
+		if r.number == number {
+			r.stop, r.text = nil, ""
+		}
+		r.mu.Unlock()
+		cancel()
+
+		if err != nil {
+			logger.Error(logger.CategoryUI, "Read aloud failed: %v", err)
+			dialog.ShowError(fmt.Errorf(i18n.T("Failed to read aloud: %v"), err), a.mainWindow)
+		}
+	}()
+}
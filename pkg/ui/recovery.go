@@ -0,0 +1,86 @@
+package ui
+
+import (
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2/dialog"
+
+	"github.com/jeff-barlow-spady/ramble/pkg/i18n"
+	"github.com/jeff-barlow-spady/ramble/pkg/logger"
+	"github.com/jeff-barlow-spady/ramble/pkg/output"
+	"github.com/jeff-barlow-spady/ramble/pkg/session"
+)
+
+// journalPending writes the text of the segment being recorded to the
+// recovery journal, so it survives a crash before the segment is finalized
+func (a *App) journalPending() {
+	if a.journal == nil || !a.privacy.Allows(output.KindHistory) {
+		return
+	}
+	err := a.journal.Write(session.Pending{
+		SessionID: a.live.session.ID,
+		Started:   a.segmentStarted,
+		Text:      a.sessionStore.FilterText(a.currentSessionText),
+	})
+	if err != nil {
+		logger.Warning(logger.CategoryUI, "Failed to journal unsaved text: %v", err)
+	}
+}
+
+// clearJournal empties the recovery journal once its text is saved or discarded
+func (a *App) clearJournal() {
+	if a.journal == nil {
+		return
+	}
+	if err := a.journal.Clear(); err != nil {
+		logger.Warning(logger.CategoryUI, "%v", err)
+	}
+}
+
+// offerRecovery asks whether to restore text a previous run recognized but
+// never saved, e.g. because it crashed while recording
+func (a *App) offerRecovery() {
+	if a.journal == nil {
+		return
+	}
+
+	pending, err := a.journal.Read()
+	if err != nil {
+		logger.Warning(logger.CategoryUI, "%v", err)
+		return
+	}
+	if pending == nil {
+		return
+	}
+
+	when := pending.Updated.Format("2006-01-02 15:04")
+	preview := pending.Text
+	if words := strings.Fields(preview); len(words) > 40 {
+		preview = strings.Join(words[:40], " ") + " ..."
+	}
+	message := i18n.Tf("Ramble closed at %s before this text was saved:\n\n%s\n\nAdd it to the live session?", when, preview)
+
+	dialog.ShowConfirm(i18n.T("Restore Unsaved Transcript"), message, func(restore bool) {
+		if restore {
+			a.restorePending(pending)
+		}
+		a.clearJournal()
+	}, a.mainWindow)
+}
+
+// restorePending adds recovered text to the live session as a segment
+func (a *App) restorePending(pending *session.Pending) {
+	ended := pending.Updated
+	if ended.IsZero() {
+		ended = time.Now()
+	}
+	segment := a.live.session.AppendSegment(session.Segment{
+		Text:      pending.Text,
+		StartedAt: pending.Started,
+		EndedAt:   ended,
+	})
+	a.saveView(a.live)
+	a.live.addSegment(segment)
+	a.ShowTemporaryStatus(i18n.T("Unsaved transcript restored"), 2*time.Second)
+}
@@ -0,0 +1,165 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/jeff-barlow-spady/ramble/pkg/analytics"
+	"github.com/jeff-barlow-spady/ramble/pkg/clipboard"
+	"github.com/jeff-barlow-spady/ramble/pkg/i18n"
+	"github.com/jeff-barlow-spady/ramble/pkg/logger"
+	"github.com/jeff-barlow-spady/ramble/pkg/output"
+)
+
+// maxSearchResults limits how many history matches are listed
+const maxSearchResults = 100
+
+// SetSelectionCallbacks sets the functions behind the transcript context menu
+// actions. A nil function hides its action.
+func (a *App) SetSelectionCallbacks(onAddVocabulary func(word string) error, onAddCorrection func(from, to string) error, onSendToWebhook func(text string) error) {
+	a.onAddVocabulary = onAddVocabulary
+	a.onAddCorrection = onAddCorrection
+	a.onSendToWebhook = onSendToWebhook
+}
+
+// selectionMenuItems builds the context menu for text selected in a transcript
+func (a *App) selectionMenuItems(selected string) []*fyne.MenuItem {
+	selected = strings.TrimSpace(selected)
+
+	copyItem := fyne.NewMenuItem(i18n.T("Copy"), func() {
+		if err := clipboard.SetText(a.filterClipboardText(selected)); err != nil {
+			dialog.ShowError(fmt.Errorf(i18n.T("Failed to copy text: %v"), err), a.mainWindow)
+			return
+		}
+		a.ShowTemporaryStatus(i18n.T("Copied to clipboard"), 2*time.Second)
+	})
+	searchItem := fyne.NewMenuItem(i18n.T("Search History"), func() {
+		a.showHistorySearch(selected)
+	})
+	items := []*fyne.MenuItem{copyItem, searchItem}
+
+	if a.onAddVocabulary != nil {
+		items = append(items, fyne.NewMenuItem(i18n.T("Add to Vocabulary"), func() {
+			if err := a.onAddVocabulary(selected); err != nil {
+				dialog.ShowError(err, a.mainWindow)
+				return
+			}
+			a.recordFeature(analytics.FeatureVocabulary)
+			a.ShowTemporaryStatus(i18n.Tf("Added %q to vocabulary", selected), 2*time.Second)
+		}))
+	}
+	if a.onAddCorrection != nil {
+		items = append(items, fyne.NewMenuItem(i18n.T("Create Correction Rule..."), func() {
+			a.showCorrectionDialog(selected)
+		}))
+	}
+	if a.onSendToWebhook != nil && a.privacy.Allows(output.KindWebhook) {
+		items = append(items, fyne.NewMenuItem(i18n.T("Send to Webhook"), func() {
+			a.sendToWebhook(selected)
+		}))
+	}
+	if a.onReadAloud != nil {
+		items = append(items, fyne.NewMenuItem(i18n.T("Read Aloud"), func() {
+			a.readAloud(selected)
+		}))
+	}
+
+	// Every action needs a selection
+	for _, item := range items {
+		item.Disabled = selected == ""
+	}
+	return items
+}
+
+// showHistorySearch lists segments from saved sessions containing the query
+func (a *App) showHistorySearch(query string) {
+	if a.sessionStore == nil {
+		dialog.ShowError(errors.New(i18n.T("Session history is unavailable")), a.mainWindow)
+		return
+	}
+
+	a.recordFeature(analytics.FeatureSearch)
+	results, err := a.sessionStore.Search(query, maxSearchResults)
+	if err != nil {
+		dialog.ShowError(fmt.Errorf(i18n.T("Search failed: %v"), err), a.mainWindow)
+		return
+	}
+	if len(results) == 0 {
+		dialog.ShowInformation(i18n.T("Search History"), i18n.Tf("No earlier transcripts contain %q.", query), a.mainWindow)
+		return
+	}
+
+	list := widget.NewList(
+		func() int { return len(results) },
+		func() fyne.CanvasObject {
+			label := widget.NewLabel("")
+			label.Wrapping = fyne.TextWrapWord
+			return label
+		},
+		func(id widget.ListItemID, obj fyne.CanvasObject) {
+			result := results[id]
+			obj.(*widget.Label).SetText(fmt.Sprintf("%s\n%s",
+				result.Created.Format("2006-01-02 15:04"), result.Segment.Text))
+		},
+	)
+	list.OnSelected = func(id widget.ListItemID) {
+		if err := clipboard.SetText(a.filterClipboardText(results[id].Segment.Text)); err == nil {
+			a.ShowTemporaryStatus(i18n.T("Copied to clipboard"), 2*time.Second)
+		}
+	}
+
+	content := container.NewBorder(
+		widget.NewLabel(i18n.Tf("%d matches for %q. Select one to copy it.", len(results), query)),
+		nil, nil, nil,
+		list,
+	)
+	dlg := dialog.NewCustom(i18n.T("Search History"), i18n.T("Close"), content, a.mainWindow)
+	dlg.Resize(fyne.NewSize(550, 400))
+	dlg.Show()
+}
+
+// showCorrectionDialog asks what the selected text should be replaced with
+func (a *App) showCorrectionDialog(from string) {
+	fromEntry := widget.NewEntry()
+	fromEntry.SetText(from)
+	toEntry := widget.NewEntry()
+	toEntry.SetText(from)
+
+	items := []*widget.FormItem{
+		widget.NewFormItem(i18n.T("Replace"), fromEntry),
+		widget.NewFormItem(i18n.T("With"), toEntry),
+	}
+	dialog.ShowForm(i18n.T("Create Correction Rule"), i18n.T("Create"), i18n.T("Cancel"), items, func(confirmed bool) {
+		if !confirmed || strings.TrimSpace(fromEntry.Text) == "" {
+			return
+		}
+		if err := a.onAddCorrection(fromEntry.Text, toEntry.Text); err != nil {
+			dialog.ShowError(err, a.mainWindow)
+			return
+		}
+		a.recordFeature(analytics.FeatureCorrection)
+		a.ShowTemporaryStatus(i18n.T("Correction rule added"), 2*time.Second)
+	}, a.mainWindow)
+}
+
+// sendToWebhook posts the selected text to the configured webhook in the background
+func (a *App) sendToWebhook(text string) {
+	a.ShowTemporaryStatus(i18n.T("Sending to webhook..."), 2*time.Second)
+	go func() {
+		// Text leaving the app is redacted like text copied to the clipboard
+		if err := a.onSendToWebhook(a.filterClipboardText(text)); err != nil {
+			logger.Warning(logger.CategoryUI, "Webhook failed: %v", err)
+			dialog.ShowError(err, a.mainWindow)
+			return
+		}
+		a.recordFeature(analytics.FeatureWebhook)
+		a.ShowTemporaryStatus(i18n.T("Sent to webhook"), 2*time.Second)
+	}()
+}
@@ -0,0 +1,319 @@
+package ui
+
+import (
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/jeff-barlow-spady/ramble/pkg/i18n"
+	"github.com/jeff-barlow-spady/ramble/pkg/logger"
+	"github.com/jeff-barlow-spady/ramble/pkg/session"
+)
+
+// transcriptPlaceholder is shown in an empty transcript box
+const transcriptPlaceholder = "Your transcription will appear here..."
+
+// bottomTolerance is how far from the end the segment list may be scrolled
+// and still count as following new segments
+const bottomTolerance = 20
+
+// segmentPageSize is how many more spilled segments each "Show earlier
+// segments" reads back from the session history
+const segmentPageSize = 100
+
+// sessionMaxSegments is how many segments a session keeps in memory before
+// the oldest are spilled to the session history, leaving sessionKeepSegments.
+// This keeps hours of dictation from slowing down the window.
+const (
+	sessionMaxSegments  = 500
+	sessionKeepSegments = 200
+)
+
+// sessionView is one transcription session shown in its own tab, such as the
+// live microphone session or a file transcription job
+type sessionView struct {
+	app     *App
+	title   string
+	live    bool             // The microphone session, which can't be closed
+	session *session.Session // Finalized segments and their undo history
+
+	transcriptBox    *transcriptEntry
+	streamingPreview *transcriptEntry
+	karaoke          *karaokeLine // Highlights the newest live words as they were spoken
+	segmentsList     *widget.List // Only creates cards for the visible segments
+	progress         *widget.ProgressBar
+	progressLabel    *widget.Label
+	tab              *container.TabItem
+	jumpButton       *widget.Button             // Shown while scrolled up and new segments arrive
+	earlierButton    *widget.Button             // Reads another page of spilled segments
+	summary          *summaryPanel              // The Summary tab
+	stats            *statsPanel                // The Statistics tab
+	styled           []*container.ThemeOverride // Parts drawn with the transcript theme
+
+	segments []session.Segment             // The session's segments in memory, as listed
+	earlier  []session.Segment             // Spilled segments read back for display, which can't be edited
+	spilled  int                           // Spilled segment count when earlier was read
+	heights  map[widget.ListItemID]float32 // Measured height of each rendered card
+
+	busy   bool // A file transcription job is running
+	unseen int  // Segments added while scrolled away from the bottom
+}
+
+// newSessionView creates the widgets for a session and the tab showing them
+func newSessionView(a *App, title string, s *session.Session, live bool) *sessionView {
+	v := &sessionView{app: a, title: title, live: live, session: s}
+
+	// Create the transcript box with improved readability
+	v.transcriptBox = newTranscriptEntry(a.selectionMenuItems)
+	v.transcriptBox.SetPlaceHolder(i18n.T(transcriptPlaceholder))
+	v.transcriptBox.SetMinRowsVisible(12) // The font comes from the transcript theme
+
+	// Create the streaming preview area
+	v.streamingPreview = newTranscriptEntry(a.selectionMenuItems)
+	v.streamingPreview.TextStyle = fyne.TextStyle{Italic: true} // Indicate this is not final text
+
+	// Create the finalized segments list
+	v.segmentsList = widget.NewList(
+		func() int {
+			return len(v.earlier) + len(v.segments)
+		},
+		func() fyne.CanvasObject {
+			return container.NewStack()
+		},
+		v.updateItem,
+	)
+
+	v.earlierButton = widget.NewButtonWithIcon("", theme.MoveUpIcon(), v.showEarlier)
+	v.earlierButton.Hide()
+
+	// Floats over the segments while new ones arrive out of view
+	v.jumpButton = widget.NewButtonWithIcon("", theme.MoveDownIcon(), v.jumpToLive)
+	v.jumpButton.Importance = widget.HighImportance
+	v.jumpButton.Hide()
+	segments := container.NewBorder(v.earlierButton, nil, nil, nil,
+		container.NewStack(
+			v.segmentsList,
+			container.NewVBox(
+				layout.NewSpacer(),
+				container.NewHBox(layout.NewSpacer(), v.jumpButton, layout.NewSpacer()),
+			),
+		),
+	)
+
+	// File jobs show their progress where live sessions show the streaming preview
+	v.progress = widget.NewProgressBar()
+	v.progressLabel = widget.NewLabel("")
+	var top fyne.CanvasObject
+	if live {
+		v.streamingPreview.SetPlaceHolder(i18n.T("Live transcription will appear here..."))
+		v.karaoke = newKaraokeLine()
+		preview := container.NewBorder(v.karaoke.text, nil, nil, nil, v.styleTranscript(v.streamingPreview))
+		split := container.NewVSplit(preview, segments)
+		split.Offset = 0.25 // 25% for streaming, 75% for finalized segments
+		top = split
+	} else {
+		top = container.NewBorder(
+			container.NewVBox(v.progressLabel, v.progress),
+			nil, nil, nil,
+			segments,
+		)
+	}
+
+	// Each session has a segment view, a plain full transcript, a summary
+	// and statistics
+	summary, summaryTab := newSummaryPanel(a, v)
+	v.summary = summary
+	stats, statsTab := newStatsPanel()
+	v.stats = stats
+	views := container.NewAppTabs(
+		container.NewTabItem(i18n.T("Segments"), top),
+		container.NewTabItem(i18n.T("Full Transcript"), v.styleTranscript(v.transcriptBox)),
+		container.NewTabItem(i18n.T("Summary"), summaryTab),
+		container.NewTabItem(i18n.T("Statistics"), statsTab),
+	)
+	views.SetTabLocation(container.TabLocationBottom)
+
+	v.tab = container.NewTabItem(title, views)
+	v.rebuild()
+	a.spillView(v)
+	return v
+}
+
+// styleTranscript draws obj with the transcript font, text size and line spacing
+func (v *sessionView) styleTranscript(obj fyne.CanvasObject) fyne.CanvasObject {
+	override := container.NewThemeOverride(obj, v.app.transcriptTheme)
+	v.styled = append(v.styled, override)
+	return override
+}
+
+// restyle redraws the session after the transcript style changed
+func (v *sessionView) restyle() {
+	for _, override := range v.styled {
+		override.Refresh()
+	}
+	// Cards are measured again at the new text size
+	v.heights = make(map[widget.ListItemID]float32)
+	v.segmentsList.Refresh()
+}
+
+// updateItem shows the segment at a list position in a recycled row
+func (v *sessionView) updateItem(id widget.ListItemID, item fyne.CanvasObject) {
+	var card *fyne.Container
+	if id < len(v.earlier) {
+		card = v.app.newSegmentCard(v, v.earlier[id], false)
+	} else {
+		card = v.app.newSegmentCard(v, v.segments[id-len(v.earlier)], true)
+	}
+	row := item.(*fyne.Container)
+	row.Objects = []fyne.CanvasObject{container.NewThemeOverride(card, v.app.transcriptTheme)}
+	row.Refresh()
+
+	// Cards wrap their text, so measure them at the list's width
+	card.Resize(fyne.NewSize(v.segmentsList.Size().Width, card.MinSize().Height))
+	if height := card.MinSize().Height; v.heights[id] != height {
+		v.heights[id] = height
+		v.segmentsList.SetItemHeight(id, height)
+	}
+
+	// The newest segment has scrolled into view
+	if id == len(v.earlier)+len(v.segments)-1 && v.unseen > 0 {
+		v.unseen = 0
+		v.jumpButton.Hide()
+	}
+}
+
+// rebuild re-reads the segments and full transcript from the session
+func (v *sessionView) rebuild() {
+	v.segments = v.session.List()
+	spilled := v.session.Len() - len(v.segments)
+
+	// Earlier pages no longer line up once more segments were spilled
+	if spilled != v.spilled {
+		v.earlier = nil
+		v.spilled = spilled
+	}
+	v.updateEarlierButton()
+
+	v.heights = make(map[widget.ListItemID]float32)
+	v.segmentsList.Refresh()
+
+	// Update the full transcript
+	texts := make([]string, len(v.segments))
+	for i, segment := range v.segments {
+		texts[i] = segment.Text
+	}
+	v.transcriptBox.SetText(transcriptText(texts, spilled))
+	v.stats.show(v.session.Stats())
+}
+
+// updateEarlierButton shows how many spilled segments are not displayed
+func (v *sessionView) updateEarlierButton() {
+	hidden := v.spilled - len(v.earlier)
+	if hidden <= 0 || v.app.sessionStore == nil {
+		v.earlierButton.Hide()
+		return
+	}
+	v.earlierButton.SetText(i18n.Tf("Show earlier segments (%d in history)", hidden))
+	v.earlierButton.Show()
+}
+
+// showEarlier reads another page of spilled segments back from the history
+func (v *sessionView) showEarlier() {
+	spilled, err := v.app.sessionStore.Spilled(v.session)
+	if err != nil {
+		logger.Warning(logger.CategoryUI, "Failed to read earlier segments: %v", err)
+		return
+	}
+	shown := min(len(spilled), len(v.earlier)+segmentPageSize)
+	v.earlier = spilled[len(spilled)-shown:]
+	v.spilled = len(spilled)
+	v.updateEarlierButton()
+
+	v.heights = make(map[widget.ListItemID]float32)
+	v.segmentsList.Refresh()
+	v.segmentsList.ScrollToTop()
+}
+
+// transcriptText joins segment texts for the Full Transcript tab, noting how
+// many segments were spilled to the history before them
+func transcriptText(texts []string, spilled int) string {
+	if spilled > 0 {
+		return i18n.Tf("[%d earlier segments not shown; copy or export the session for the full transcript]\n\n", spilled) +
+			strings.Join(texts, "\n\n")
+	}
+	return strings.Join(texts, "\n\n")
+}
+
+// addSegment shows a newly finalized segment
+func (v *sessionView) addSegment(segment session.Segment) {
+	// Only follow new segments if the user hasn't scrolled up to read
+	follow := v.atBottom()
+
+	// Only the new row and the end of the transcript change
+	v.segments = append(v.segments, segment)
+	v.segmentsList.Refresh()
+	if v.transcriptBox.Text == "" {
+		v.transcriptBox.SetText(segment.Text)
+	} else {
+		v.transcriptBox.Append("\n\n" + segment.Text)
+	}
+
+	// Auto-scroll to bottom of the finalized segments
+	if follow {
+		v.segmentsList.ScrollToBottom()
+		return
+	}
+	v.unseen++
+	if v.unseen == 1 {
+		v.jumpButton.SetText(i18n.T("Jump to live (1 new segment)"))
+	} else {
+		v.jumpButton.SetText(i18n.Tf("Jump to live (%d new segments)", v.unseen))
+	}
+	v.jumpButton.Show()
+}
+
+// atBottom reports whether the segment list is scrolled to its end. Rows
+// that were never rendered are assumed to be as tall as the average card.
+func (v *sessionView) atBottom() bool {
+	rows := len(v.earlier) + len(v.segments)
+	if rows == 0 {
+		return true
+	}
+
+	var measured float32
+	for _, height := range v.heights {
+		measured += height
+	}
+	average := v.segmentsList.MinSize().Height
+	if len(v.heights) > 0 {
+		average = measured / float32(len(v.heights))
+	}
+	content := measured + average*float32(rows-len(v.heights)) + theme.Padding()*float32(rows-1)
+
+	hidden := content - v.segmentsList.Size().Height
+	return v.segmentsList.GetScrollOffset() >= hidden-bottomTolerance
+}
+
+// jumpToLive scrolls to the newest segment
+func (v *sessionView) jumpToLive() {
+	v.unseen = 0
+	v.jumpButton.Hide()
+	v.segmentsList.ScrollToBottom()
+}
+
+// setProgress shows the progress of a file transcription job
+func (v *sessionView) setProgress(message string, fraction float64) {
+	v.progressLabel.SetText(message)
+	v.progress.SetValue(fraction)
+}
+
+// finishProgress hides the progress bar once a job is done
+func (v *sessionView) finishProgress(message string) {
+	v.busy = false
+	v.progressLabel.SetText(message)
+	v.progress.Hide()
+}
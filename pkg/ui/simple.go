@@ -13,6 +13,9 @@ import (
 	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
+
+	"github.com/jeff-barlow-spady/ramble/pkg/i18n"
+	"github.com/jeff-barlow-spady/ramble/pkg/textproc"
 )
 
 // Simple provides a minimal UI for transcription
@@ -92,7 +95,7 @@ func NewSimpleWithOptions(testMode bool) *Simple {
 		ui.toggleRecording,
 		ui.showPreferencesDialog,
 		func() {
-			dialog.ShowInformation("About Ramble", "Ramble Speech-to-Text\nVersion 0.1.0\n\nTranscribe speech to text quickly and easily.", w)
+			dialog.ShowInformation(i18n.T("About Ramble"), i18n.T("Ramble Speech-to-Text\nVersion 0.1.0\n\nTranscribe speech to text quickly and easily."), w)
 		},
 		ui.doQuit,
 		ui.showMainWindow,
@@ -109,16 +112,16 @@ func (s *Simple) setupUI() {
 	// Create a simplified layout for legacy support
 	s.transcript = widget.NewMultiLineEntry()
 	s.transcript.Disable()
-	s.transcript.SetPlaceHolder("Your transcription will appear here...")
+	s.transcript.SetPlaceHolder(i18n.T("Your transcription will appear here..."))
 
 	s.waveform = NewWaveformVisualizer(theme.PrimaryColor())
-	s.recordBtn = widget.NewButton("Start Recording", s.toggleRecording)
-	s.statusLabel = widget.NewLabel("Ready")
+	s.recordBtn = widget.NewButton(i18n.T("Start Recording"), s.toggleRecording)
+	s.statusLabel = widget.NewLabel(i18n.T("Ready"))
 
 	buttons := container.NewHBox(
 		s.recordBtn,
-		widget.NewButton("Copy", s.copyToClipboard),
-		widget.NewButton("Clear", s.clearTranscript),
+		widget.NewButton(i18n.T("Copy"), s.copyToClipboard),
+		widget.NewButton(i18n.T("Clear"), s.clearTranscript),
 	)
 
 	// Simple layout without problematic nested containers
@@ -176,10 +179,10 @@ func (s *Simple) AppendTranscript(text string) {
 	}
 
 	current := s.transcript.Text
-	if current == "" || current == "Your transcription will appear here..." {
+	if current == "" || current == i18n.T("Your transcription will appear here...") {
 		s.transcript.SetText(trimmedText)
 	} else {
-		s.transcript.SetText(current + " " + trimmedText)
+		s.transcript.SetText(textproc.Join(current, trimmedText))
 	}
 }
 
@@ -198,7 +201,7 @@ func (s *Simple) ShowTemporaryStatus(message string, duration time.Duration) {
 	s.ShowStatus(message)
 	go func() {
 		time.Sleep(duration)
-		s.ShowStatus("Ready")
+		s.ShowStatus(i18n.T("Ready"))
 	}()
 }
 
@@ -283,7 +286,7 @@ func ShowPreferences(fyneApp fyne.App, parent fyne.Window, currentPrefs Preferen
 	}
 
 	// Create a new window for preferences
-	w := fyneApp.NewWindow("Ramble Preferences")
+	w := fyneApp.NewWindow(i18n.T("Ramble Preferences"))
 	w.Resize(fyne.NewSize(500, 450))
 
 	// Store reference to the window
@@ -0,0 +1,122 @@
+package ui
+
+import (
+	"image"
+	"image/color"
+	"sync"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/widget"
+)
+
+// Visualizations of the microphone input in the main window
+const (
+	VisualizationWaveform    = "waveform"
+	VisualizationSpectrogram = "spectrogram"
+)
+
+// spectrogramHistory is how many spectra are shown, one per audio buffer,
+// about ten seconds with the default buffer size
+const spectrogramHistory = 160
+
+// spectrogramColors map band levels from silence to full scale, starting
+// from the background of the visualization area
+var spectrogramColors = []color.RGBA{
+	{R: 30, G: 36, B: 66, A: 255},
+	{R: 60, G: 80, B: 180, A: 255},
+	{R: 100, G: 140, B: 240, A: 255},
+	{R: 230, G: 90, B: 140, A: 255},
+	{R: 255, G: 220, B: 110, A: 255},
+}
+
+// SpectrogramVisualizer shows the spectrum of the microphone input over time,
+// with time running left to right and frequency bottom to top. Unlike the
+// waveform bars it shows background noise, hum and muffled speech.
+type SpectrogramVisualizer struct {
+	widget.BaseWidget
+	mu      sync.Mutex
+	columns [][]float32 // Ring of band levels from 0 to 1, low frequencies first
+	next    int         // Column replaced by the next spectrum
+	raster  *canvas.Raster
+}
+
+// NewSpectrogramVisualizer creates an empty spectrogram
+func NewSpectrogramVisualizer() *SpectrogramVisualizer {
+	s := &SpectrogramVisualizer{columns: make([][]float32, spectrogramHistory)}
+	s.raster = canvas.NewRaster(s.draw)
+	s.ExtendBaseWidget(s)
+	return s
+}
+
+// AddSpectrum appends the levels of one audio buffer, scrolling the oldest
+// out. It may be called from the audio callback.
+func (s *SpectrogramVisualizer) AddSpectrum(bands []float32) {
+	s.mu.Lock()
+	column := s.columns[s.next]
+	if len(column) != len(bands) {
+		column = make([]float32, len(bands))
+		s.columns[s.next] = column
+	}
+	copy(column, bands)
+	s.next = (s.next + 1) % len(s.columns)
+	s.mu.Unlock()
+
+	canvas.Refresh(s.raster)
+}
+
+// Clear removes all spectra
+func (s *SpectrogramVisualizer) Clear() {
+	s.mu.Lock()
+	for i := range s.columns {
+		s.columns[i] = nil
+	}
+	s.next = 0
+	s.mu.Unlock()
+
+	canvas.Refresh(s.raster)
+}
+
+// CreateRenderer implements the widget interface
+func (s *SpectrogramVisualizer) CreateRenderer() fyne.WidgetRenderer {
+	return widget.NewSimpleRenderer(s.raster)
+}
+
+// draw renders the spectra, oldest on the left
+func (s *SpectrogramVisualizer) draw(width, height int) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	if width <= 0 || height <= 0 {
+		return img
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	background := spectrogramColors[0]
+	for x := 0; x < width; x++ {
+		column := s.columns[(s.next+x*len(s.columns)/width)%len(s.columns)]
+		for y := 0; y < height; y++ {
+			c := background
+			if len(column) > 0 {
+				c = spectrogramColor(column[(height-1-y)*len(column)/height])
+			}
+			i := img.PixOffset(x, y)
+			img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3] = c.R, c.G, c.B, c.A
+		}
+	}
+	return img
+}
+
+// spectrogramColor blends the colors of the scale for a level from 0 to 1
+func spectrogramColor(level float32) color.RGBA {
+	level = min(max(level, 0), 1)
+	pos := level * float32(len(spectrogramColors)-1)
+	i := min(int(pos), len(spectrogramColors)-2)
+	frac := pos - float32(i)
+
+	from, to := spectrogramColors[i], spectrogramColors[i+1]
+	blend := func(a, b uint8) uint8 {
+		return uint8(float32(a) + (float32(b)-float32(a))*frac)
+	}
+	return color.RGBA{R: blend(from.R, to.R), G: blend(from.G, to.G), B: blend(from.B, to.B), A: 255}
+}
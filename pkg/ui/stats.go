@@ -0,0 +1,109 @@
+package ui
+
+import (
+	"fmt"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/jeff-barlow-spady/ramble/pkg/i18n"
+	"github.com/jeff-barlow-spady/ramble/pkg/logger"
+	"github.com/jeff-barlow-spady/ramble/pkg/session"
+)
+
+// statsPanel is a session's Statistics tab, showing how the session was
+// spoken as of the end of the last recording
+type statsPanel struct {
+	note     *widget.Label
+	form     *widget.Form
+	words    *widget.Label
+	rate     *widget.Label
+	duration *widget.Label
+	speech   *widget.Label
+	pauses   *widget.Label
+	longest  *widget.Label
+}
+
+// newStatsPanel creates the Statistics tab of a session view
+func newStatsPanel() (*statsPanel, fyne.CanvasObject) {
+	p := &statsPanel{
+		note:     widget.NewLabel(i18n.T("Statistics appear here when a recording stops.")),
+		words:    widget.NewLabel(""),
+		rate:     widget.NewLabel(""),
+		duration: widget.NewLabel(""),
+		speech:   widget.NewLabel(""),
+		pauses:   widget.NewLabel(""),
+		longest:  widget.NewLabel(""),
+	}
+	p.note.Wrapping = fyne.TextWrapWord
+	p.form = widget.NewForm(
+		widget.NewFormItem(i18n.T("Words"), p.words),
+		widget.NewFormItem(i18n.T("Speaking rate"), p.rate),
+		widget.NewFormItem(i18n.T("Recorded"), p.duration),
+		widget.NewFormItem(i18n.T("Speaking"), p.speech),
+		widget.NewFormItem(i18n.T("Pauses"), p.pauses),
+		widget.NewFormItem(i18n.T("Longest utterance"), p.longest),
+	)
+	return p, container.NewVScroll(container.NewVBox(p.form, p.note))
+}
+
+// show displays statistics, or the note alone if there are none
+func (p *statsPanel) show(stats session.Stats, ok bool) {
+	if !ok {
+		p.form.Hide()
+		p.note.Show()
+		return
+	}
+
+	p.words.SetText(fmt.Sprintf("%d", stats.Words))
+	if wpm := stats.WordsPerMinute(); wpm > 0 {
+		p.rate.SetText(i18n.Tf("%.0f words per minute", wpm))
+	} else {
+		p.rate.SetText(i18n.T("Not enough speech"))
+	}
+	p.duration.SetText(formatStatsDuration(stats.Duration))
+	p.speech.SetText(formatStatsDuration(stats.Speech))
+	p.pauses.SetText(formatStatsDuration(stats.Pauses))
+	p.longest.SetText(formatStatsDuration(stats.Longest))
+	p.note.Hide()
+	p.form.Show()
+}
+
+// formatStatsDuration shows a duration to the second
+func formatStatsDuration(d time.Duration) string {
+	return d.Round(time.Second).String()
+}
+
+// SetSpeechCallback sets the function returning how long the speaker was
+// heard in the segment being finalized
+func (a *App) SetSpeechCallback(takeSpeech func() time.Duration) {
+	a.onTakeSegmentSpeech = takeSpeech
+}
+
+// updateSessionStats computes the statistics of a session's complete
+// transcript, saves them with the session and shows them in its Statistics tab
+func (a *App) updateSessionStats(v *sessionView) {
+	segments := v.session.List()
+	if a.sessionStore != nil {
+		all, err := a.sessionStore.Segments(v.session)
+		if err != nil {
+			logger.Warning(logger.CategoryUI, "Counting statistics for only the segments in memory: %v", err)
+		} else {
+			segments = all
+		}
+	}
+	if len(segments) == 0 {
+		return
+	}
+
+	stats := session.ComputeStats(segments)
+	v.session.SetStats(stats)
+	a.saveView(v)
+	v.stats.show(stats, true)
+
+	if wpm := stats.WordsPerMinute(); wpm > 0 {
+		a.ShowTemporaryStatus(i18n.Tf("%d words at %.0f words per minute", stats.Words, wpm), 3*time.Second)
+	}
+}
@@ -0,0 +1,117 @@
+package ui
+
+import (
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/jeff-barlow-spady/ramble/pkg/clipboard"
+	"github.com/jeff-barlow-spady/ramble/pkg/i18n"
+	"github.com/jeff-barlow-spady/ramble/pkg/logger"
+)
+
+// summaryMetaKey is the session metadata holding the latest summary, so it is
+// saved and exported with the session
+const summaryMetaKey = "summary"
+
+// summaryPanel is a session's Summary tab, showing a summary and action items
+// written by a language model
+type summaryPanel struct {
+	text      *widget.RichText
+	status    *widget.Label
+	summarize *widget.Button
+	copy      *widget.Button
+}
+
+// newSummaryPanel creates the Summary tab of a session view, showing the
+// summary saved with the session, if any
+func newSummaryPanel(a *App, v *sessionView) (*summaryPanel, fyne.CanvasObject) {
+	p := &summaryPanel{
+		text:   widget.NewRichTextFromMarkdown(""),
+		status: widget.NewLabel(""),
+	}
+	p.text.Wrapping = fyne.TextWrapWord
+	p.summarize = widget.NewButtonWithIcon(i18n.T("Summarize"), theme.DocumentCreateIcon(), func() {
+		a.summarizeSession(v)
+	})
+	p.copy = widget.NewButtonWithIcon(i18n.T("Copy"), theme.ContentCopyIcon(), func() {
+		if err := clipboard.SetText(a.filterClipboardText(v.session.Meta(summaryMetaKey))); err != nil {
+			logger.Error(logger.CategoryUI, "Failed to copy summary to clipboard: %v", err)
+			return
+		}
+		a.ShowTemporaryStatus(i18n.T("Summary copied to clipboard"), 2*time.Second)
+	})
+	p.show(v.session.Meta(summaryMetaKey))
+
+	toolbar := container.NewHBox(p.summarize, p.status, layout.NewSpacer(), p.copy)
+	return p, container.NewBorder(toolbar, nil, nil, nil, container.NewVScroll(p.text))
+}
+
+// show displays a summary in Markdown
+func (p *summaryPanel) show(summary string) {
+	if summary == "" {
+		p.text.ParseMarkdown("*Summarize the transcript to see its key points and action items here.*")
+		p.copy.Disable()
+		return
+	}
+	p.text.ParseMarkdown(summary)
+	p.copy.Enable()
+}
+
+// SetSummarizeCallback sets the function that summarizes a transcript. It is
+// called in the background and returns the summary in Markdown.
+func (a *App) SetSummarizeCallback(onSummarize func(transcript string) (string, error)) {
+	a.onSummarize = onSummarize
+}
+
+// summarizeSession sends the session's complete transcript to the language
+// model and shows the summary in its Summary tab
+func (a *App) summarizeSession(v *sessionView) {
+	if a.onSummarize == nil {
+		return
+	}
+
+	segments := v.session.List()
+	if a.sessionStore != nil {
+		all, err := a.sessionStore.Segments(v.session)
+		if err != nil {
+			logger.Warning(logger.CategoryUI, "Summarizing only the segments in memory: %v", err)
+		} else {
+			segments = all
+		}
+	}
+	texts := make([]string, len(segments))
+	for i, segment := range segments {
+		texts[i] = segment.Text
+	}
+	transcript := strings.TrimSpace(strings.Join(texts, "\n\n"))
+	if transcript == "" {
+		a.ShowTemporaryStatus(i18n.T("Nothing to summarize yet"), 2*time.Second)
+		return
+	}
+
+	p := v.summary
+	p.summarize.Disable()
+	p.status.SetText(i18n.T("Summarizing..."))
+	go func() {
+		started := time.Now()
+		// Text leaving the app is redacted like text copied to the clipboard
+		summary, err := a.onSummarize(a.filterClipboardText(transcript))
+		p.summarize.Enable()
+		if err != nil {
+			logger.Error(logger.CategoryUI, "Summary failed: %v", err)
+			p.status.SetText(i18n.Tf("Failed: %v", err))
+			return
+		}
+
+		v.session.SetMeta(summaryMetaKey, summary)
+		a.saveView(v)
+		p.show(summary)
+		p.status.SetText(i18n.Tf("Summarized %d segments in %v", len(segments), time.Since(started).Round(time.Second)))
+	}()
+}
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"fyne.io/systray"
+	"github.com/jeff-barlow-spady/ramble/pkg/i18n"
 	"github.com/jeff-barlow-spady/ramble/pkg/resources"
 )
 
@@ -26,10 +27,17 @@ type SystemTray struct {
 	mAbout       *systray.MenuItem
 	mQuit        *systray.MenuItem
 	mShowWindow  *systray.MenuItem
+	mPresets     *systray.MenuItem
+	presetItems  []*systray.MenuItem
 
 	// Callbacks
 	onShowWindow func()
 
+	// Presets listed in the menu, and the one checked
+	presets        []string
+	activePreset   string
+	onPresetSelect func(name string)
+
 	mu sync.Mutex
 }
 
@@ -106,9 +114,9 @@ func (s *SystemTray) UpdateRecordingState(isRecording bool) {
 
 	// Update menu text safely
 	if isRecording {
-		s.mStartStop.SetTitle("Stop Recording")
+		s.mStartStop.SetTitle(i18n.T("Stop Recording"))
 	} else {
-		s.mStartStop.SetTitle("Start Recording")
+		s.mStartStop.SetTitle(i18n.T("Start Recording"))
 	}
 
 	// Update icon based on recording state - only if needed
@@ -146,15 +154,19 @@ func (s *SystemTray) onReady() {
 
 	// Set application name and tooltip
 	systray.SetTitle("Ramble")
-	systray.SetTooltip("Ramble Speech-to-Text")
+	systray.SetTooltip(i18n.T("Ramble Speech-to-Text"))
 
 	// Create menu items
-	s.mStartStop = systray.AddMenuItem("Start Recording", "Start/Stop speech recording")
-	s.mShowWindow = systray.AddMenuItem("Show Window", "Show the main application window")
+	s.mStartStop = systray.AddMenuItem(i18n.T("Start Recording"), i18n.T("Start/Stop speech recording"))
+	s.mShowWindow = systray.AddMenuItem(i18n.T("Show Window"), i18n.T("Show the main application window"))
+	s.mu.Lock()
+	s.mPresets = systray.AddMenuItem(i18n.T("Preset"), i18n.T("Switch to the settings of a use case"))
+	s.addPresetItems()
+	s.mu.Unlock()
 	systray.AddSeparator()
-	s.mPreferences = systray.AddMenuItem("Preferences", "Configure Ramble")
-	s.mAbout = systray.AddMenuItem("About", "About Ramble")
-	s.mQuit = systray.AddMenuItem("Quit", "Quit Ramble")
+	s.mPreferences = systray.AddMenuItem(i18n.T("Preferences"), i18n.T("Configure Ramble"))
+	s.mAbout = systray.AddMenuItem(i18n.T("About"), i18n.T("About Ramble"))
+	s.mQuit = systray.AddMenuItem(i18n.T("Quit"), i18n.T("Quit Ramble"))
 
 	// Handle menu item clicks in a goroutine to keep the UI responsive
 	go s.handleMenuClicks()
@@ -196,6 +208,61 @@ func (s *SystemTray) handleMenuClicks() {
 	}
 }
 
+// SetPresets lists presets in the menu, checking active, and sets the
+// function called with the name of the one clicked
+func (s *SystemTray) SetPresets(names []string, active string, onSelect func(name string)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.presets = names
+	s.activePreset = active
+	s.onPresetSelect = onSelect
+	if s.mPresets != nil {
+		for _, item := range s.presetItems {
+			item.Remove()
+		}
+		s.addPresetItems()
+	}
+}
+
+// SetActivePreset checks the named preset in the menu
+func (s *SystemTray) SetActivePreset(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.activePreset = name
+	for i, item := range s.presetItems {
+		if s.presets[i] == name {
+			item.Check()
+		} else {
+			item.Uncheck()
+		}
+	}
+}
+
+// addPresetItems adds a checkbox for each preset to the preset menu, hiding
+// it if there are none. The caller must hold s.mu.
+func (s *SystemTray) addPresetItems() {
+	s.presetItems = nil
+	if len(s.presets) == 0 {
+		s.mPresets.Hide()
+		return
+	}
+	for _, name := range s.presets {
+		item := s.mPresets.AddSubMenuItemCheckbox(name, i18n.Tf("Apply the %s preset", name), name == s.activePreset)
+		s.presetItems = append(s.presetItems, item)
+		go func(clicked <-chan struct{}) {
+			for range clicked {
+				s.mu.Lock()
+				onSelect := s.onPresetSelect
+				s.mu.Unlock()
+				if onSelect != nil {
+					onSelect(name)
+				}
+			}
+		}(item.ClickedCh)
+	}
+	s.mPresets.Show()
+}
+
 // onExit is called when the systray is exiting
 func (s *SystemTray) onExit() {
 	// Clean up resources if needed
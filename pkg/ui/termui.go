@@ -12,6 +12,8 @@ import (
 	"github.com/charmbracelet/bubbles/spinner"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+
+	"github.com/jeff-barlow-spady/ramble/pkg/logger"
 )
 
 const (
@@ -221,10 +223,37 @@ func (m *TerminalModel) SetRecordingState(isRecording bool) {
 	defer m.mutex.Unlock()
 
 	m.isRecording = isRecording
+	m.statusMessage = recordingStatus(isRecording)
+}
+
+// recordingStatus returns the status shown while recording or not
+func recordingStatus(isRecording bool) string {
 	if isRecording {
-		m.statusMessage = "Recording..."
-	} else {
-		m.statusMessage = "Ready"
+		return "Recording..."
+	}
+	return "Ready"
+}
+
+// IsRecording returns whether recording is shown as in progress
+func (m *TerminalModel) IsRecording() bool {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return m.isRecording
+}
+
+// SetStatus shows a status message until the recording state changes
+func (m *TerminalModel) SetStatus(message string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.statusMessage = message
+}
+
+// clearStatus shows the recording state again if message is still shown
+func (m *TerminalModel) clearStatus(message string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if m.statusMessage == message {
+		m.statusMessage = recordingStatus(m.isRecording)
 	}
 }
 
@@ -273,7 +302,11 @@ func (m *TerminalModel) View() string {
 	s.WriteString("\n" + statusLine)
 
 	// Hotkey info with added scroll help
-	hotkeyInfo := infoStyle.Render("Hotkey: " + m.hotkeyStr + " | Press 'r' or SPACE to toggle recording | Press 'q' to quit | Scroll logs: ↑/↓ arrows")
+	hotkeyInfo := "Press 'r' or SPACE to toggle recording | Press 'q' to quit | Scroll logs: ↑/↓ arrows"
+	if m.hotkeyStr != "" {
+		hotkeyInfo = "Hotkey: " + m.hotkeyStr + " | " + hotkeyInfo
+	}
+	hotkeyInfo = infoStyle.Render(hotkeyInfo)
 	s.WriteString("\n" + hotkeyInfo)
 
 	// Audio visualization
@@ -409,6 +442,13 @@ type TerminalUI struct {
 	initializedCh chan struct{}
 	logCh         chan string
 	statusChan    chan struct{} // Channel for keyboard shortcuts
+
+	// Used when running as a Frontend
+	mu         sync.Mutex
+	onStart    func()
+	onStop     func()
+	transcript string
+	preview    string
 }
 
 // NewTerminalUI creates a new terminal UI
@@ -501,6 +541,91 @@ func (t *TerminalUI) GetStatusChannel() <-chan struct{} {
 	return t.statusChan
 }
 
+// SetCallbacks sets the functions called when the user presses space or r to
+// start or stop recording. The terminal has no way to clear the transcript.
+func (t *TerminalUI) SetCallbacks(onStart, onStop, onClear func()) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.onStart, t.onStop = onStart, onStop
+}
+
+// SetState shows whether Ramble is recording
+func (t *TerminalUI) SetState(state AppState) {
+	t.model.SetRecordingState(isRecordingState(state))
+	switch state {
+	case StateWarmingUp:
+		t.model.SetStatus("Loading the model...")
+	case StateReconnecting:
+		t.model.SetStatus("Waiting for the microphone...")
+	}
+}
+
+// UpdateStreamingPreview shows text transcribed live below the transcript
+func (t *TerminalUI) UpdateStreamingPreview(text string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.preview = text
+	t.showText()
+}
+
+// UpdateTranscript replaces the transcript, clearing the live text
+func (t *TerminalUI) UpdateTranscript(text string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.transcript, t.preview = text, ""
+	t.showText()
+}
+
+// showText shows the transcript followed by the live text
+func (t *TerminalUI) showText() {
+	text := t.transcript
+	if t.preview != "" {
+		text = strings.TrimSpace(text + "\n\n" + t.preview)
+	}
+	t.model.UpdateText(text)
+}
+
+// ShowTemporaryStatus shows message in the status line for duration
+func (t *TerminalUI) ShowTemporaryStatus(message string, duration time.Duration) {
+	t.model.SetStatus(message)
+	time.AfterFunc(duration, func() { t.model.clearStatus(message) })
+}
+
+// ShowErrorDialog shows the error below the transcript
+func (t *TerminalUI) ShowErrorDialog(title, message string) {
+	t.SetError(title + ": " + message)
+}
+
+// Run shows the terminal UI until the user quits, starting and stopping
+// recording as they press space or r
+func (t *TerminalUI) Run() {
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case <-t.statusChan:
+			}
+
+			t.mu.Lock()
+			toggle := t.onStart
+			if t.model.IsRecording() {
+				toggle = t.onStop
+			}
+			t.mu.Unlock()
+			if toggle != nil {
+				toggle()
+			}
+		}
+	}()
+
+	if err := t.RunBlocking(); err != nil {
+		logger.Error(logger.CategoryUI, "Terminal UI failed: %v", err)
+	}
+}
+
 // LogConsumer is an interface for components that can consume log messages
 type LogConsumer interface {
 	AddLog(message string)
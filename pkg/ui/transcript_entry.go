@@ -0,0 +1,36 @@
+package ui
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/widget"
+)
+
+// transcriptEntry is a read-only multi-line entry whose right-click menu
+// offers actions on the selected text
+type transcriptEntry struct {
+	widget.Entry
+	menuItems func(selected string) []*fyne.MenuItem
+}
+
+// newTranscriptEntry creates a transcript entry. menuItems builds the context
+// menu for the current selection.
+func newTranscriptEntry(menuItems func(selected string) []*fyne.MenuItem) *transcriptEntry {
+	e := &transcriptEntry{menuItems: menuItems}
+	e.MultiLine = true
+	e.Wrapping = fyne.TextWrapWord
+	e.ExtendBaseWidget(e)
+	e.Disable() // Make read-only but still selectable
+	return e
+}
+
+// TappedSecondary shows the selection actions instead of the standard entry menu
+func (e *transcriptEntry) TappedSecondary(pe *fyne.PointEvent) {
+	if e.menuItems == nil {
+		return
+	}
+	c := fyne.CurrentApp().Driver().CanvasForObject(e)
+	if c == nil {
+		return
+	}
+	widget.ShowPopUpMenuAtPosition(fyne.NewMenu("", e.menuItems(e.SelectedText())...), c, pe.AbsolutePosition)
+}
@@ -0,0 +1,112 @@
+package ui
+
+import (
+	"image/color"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/theme"
+
+	"github.com/jeff-barlow-spady/ramble/pkg/logger"
+)
+
+// Font families for transcripts; anything else is the path of a font file
+const (
+	TranscriptFontMonospace = "monospace"
+	TranscriptFontSans      = "sans"
+)
+
+// Default look of transcripts
+const (
+	DefaultTranscriptFontSize    = 18
+	DefaultTranscriptLineSpacing = 1
+)
+
+// transcriptTheme extends the app's theme for the transcript, live preview
+// and segment cards with the user's font, text size and line spacing.
+// Everything else, such as colors, follows the app's theme as it changes.
+type transcriptTheme struct {
+	fontSize    float32
+	lineSpacing float32       // Multiple of the normal distance between lines
+	family      string        // TranscriptFontMonospace, TranscriptFontSans or a file
+	font        fyne.Resource // Loaded from family if it is a file
+}
+
+// newTranscriptTheme creates a transcript theme with the default style
+func newTranscriptTheme() *transcriptTheme {
+	return &transcriptTheme{
+		fontSize:    DefaultTranscriptFontSize,
+		lineSpacing: DefaultTranscriptLineSpacing,
+		family:      TranscriptFontMonospace,
+	}
+}
+
+// set changes the style, loading the font file if family names one. A font
+// that can't be loaded falls back to monospace.
+func (t *transcriptTheme) set(fontSize, lineSpacing float32, family string) {
+	if fontSize <= 0 {
+		fontSize = DefaultTranscriptFontSize
+	}
+	if lineSpacing <= 0 {
+		lineSpacing = DefaultTranscriptLineSpacing
+	}
+	t.fontSize, t.lineSpacing = fontSize, lineSpacing
+
+	if family == t.family {
+		return
+	}
+	t.family, t.font = family, nil
+	switch family {
+	case "", TranscriptFontMonospace:
+		t.family = TranscriptFontMonospace
+	case TranscriptFontSans:
+	default:
+		font, err := fyne.LoadResourceFromPath(family)
+		if err != nil {
+			logger.Warning(logger.CategoryUI, "Failed to load transcript font %s, using monospace: %v", family, err)
+			t.family = TranscriptFontMonospace
+			return
+		}
+		t.font = font
+	}
+}
+
+// base returns the app's current theme
+func (t *transcriptTheme) base() fyne.Theme {
+	if app := fyne.CurrentApp(); app != nil {
+		return app.Settings().Theme()
+	}
+	return theme.DefaultTheme()
+}
+
+// Color returns the app theme's color
+func (t *transcriptTheme) Color(name fyne.ThemeColorName, variant fyne.ThemeVariant) color.Color {
+	return t.base().Color(name, variant)
+}
+
+// Font returns the transcript font, keeping bold and italic where the
+// family has them
+func (t *transcriptTheme) Font(style fyne.TextStyle) fyne.Resource {
+	if t.font != nil {
+		return t.font
+	}
+	style.Monospace = t.family == TranscriptFontMonospace
+	return t.base().Font(style)
+}
+
+// Icon returns the app theme's icon
+func (t *transcriptTheme) Icon(name fyne.ThemeIconName) fyne.Resource {
+	return t.base().Icon(name)
+}
+
+// Size returns the transcript text size and line spacing, and the app
+// theme's other sizes
+func (t *transcriptTheme) Size(name fyne.ThemeSizeName) float32 {
+	switch name {
+	case theme.SizeNameText:
+		return t.fontSize
+	case theme.SizeNameLineSpacing:
+		// Extra spacing grows with the text
+		return t.base().Size(name) + (t.lineSpacing-1)*t.fontSize
+	}
+	return t.base().Size(name)
+}
@@ -0,0 +1,130 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// HoverSettings are how the hover window looks and behaves over other windows
+type HoverSettings struct {
+	OnTop        bool    // Keep the window above other windows
+	Width        float32 // Size of the window
+	Height       float32
+	Opacity      float64 // From MinHoverOpacity (mostly transparent) to 1 (opaque)
+	ClickThrough bool    // Let clicks through to the window below
+	Move         bool    // Place the window's top left corner at X, Y on the screen
+	X, Y         int
+}
+
+// MinHoverOpacity keeps the hover window from becoming invisible
+const MinHoverOpacity = 0.2
+
+// Default size of the hover window
+const (
+	defaultHoverWidth  = 300
+	defaultHoverHeight = 200
+)
+
+// applyWindowHints asks the window manager to apply settings to the window
+// titled title. Fyne has no API for these, so it uses wmctrl and xprop on
+// Linux and user32 through PowerShell on Windows. Settings the platform
+// can't apply are reported in the returned error.
+func applyWindowHints(title string, settings HoverSettings) error {
+	commands, unsupported := windowHintCommands(runtime.GOOS, os.Getenv, exec.LookPath, title, settings)
+	errs := []error{unsupported}
+	for _, command := range commands {
+		if out, err := exec.Command(command[0], command[1:]...).CombinedOutput(); err != nil {
+			errs = append(errs, fmt.Errorf("%s failed: %w: %s", command[0], err, strings.TrimSpace(string(out))))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// windowHintCommands returns the commands applying settings to the window
+// titled title on the given platform, and an error for any setting that
+// can't be applied there
+func windowHintCommands(goos string, getenv func(string) string, lookPath func(string) (string, error), title string, settings HoverSettings) ([][]string, error) {
+	opacity := min(max(settings.Opacity, MinHoverOpacity), 1)
+
+	switch goos {
+	case "windows":
+		return [][]string{{"powershell", "-NoProfile", "-Command", windowsHintScript(title, settings, opacity)}}, nil
+	case "linux", "freebsd", "openbsd", "netbsd":
+	default:
+		return nil, fmt.Errorf("keeping on top, opacity and click-through are not supported on %s", goos)
+	}
+
+	// Fyne windows are X11 windows, also under XWayland
+	if getenv("DISPLAY") == "" {
+		return nil, errors.New("keeping on top, opacity and click-through need an X11 display")
+	}
+
+	var commands [][]string
+	var errs []error
+	if _, err := lookPath("wmctrl"); err == nil {
+		action := "remove,above"
+		if settings.OnTop {
+			action = "add,above"
+		}
+		commands = append(commands, []string{"wmctrl", "-F", "-r", title, "-b", action})
+		if settings.Move {
+			commands = append(commands, []string{"wmctrl", "-F", "-r", title, "-e", fmt.Sprintf("0,%d,%d,-1,-1", settings.X, settings.Y)})
+		}
+	} else if settings.OnTop || settings.Move {
+		errs = append(errs, errors.New("keeping the window on top or placing it needs wmctrl to be installed"))
+	}
+
+	if _, err := lookPath("xprop"); err == nil {
+		if opacity < 1 {
+			// The property is a fraction of 0xffffffff
+			value := fmt.Sprint(uint32(opacity * 0xffffffff))
+			commands = append(commands, []string{"xprop", "-name", title, "-f", "_NET_WM_WINDOW_OPACITY", "32c", "-set", "_NET_WM_WINDOW_OPACITY", value})
+		} else {
+			commands = append(commands, []string{"xprop", "-name", title, "-remove", "_NET_WM_WINDOW_OPACITY"})
+		}
+	} else if opacity < 1 {
+		errs = append(errs, errors.New("opacity needs xprop to be installed"))
+	}
+
+	if settings.ClickThrough {
+		errs = append(errs, errors.New("click-through is only supported on Windows"))
+	}
+	return commands, errors.Join(errs...)
+}
+
+// windowsHintScript returns a PowerShell script setting the window's
+// z-order, position, transparency and click-through through user32
+func windowsHintScript(title string, settings HoverSettings, opacity float64) string {
+	insertAfter := -2 // HWND_NOTOPMOST
+	if settings.OnTop {
+		insertAfter = -1 // HWND_TOPMOST
+	}
+	position := "0, 0, 0, 0, 0x13" // SWP_NOSIZE | SWP_NOMOVE | SWP_NOACTIVATE
+	if settings.Move {
+		position = fmt.Sprintf("%d, %d, 0, 0, 0x11", settings.X, settings.Y) // SWP_NOSIZE | SWP_NOACTIVATE
+	}
+	transparent := "$style = $style -band -bnot 0x20"
+	if settings.ClickThrough {
+		transparent = "$style = $style -bor 0x20" // WS_EX_TRANSPARENT
+	}
+
+	return strings.Join([]string{
+		`$sig = '[DllImport("user32.dll")] public static extern IntPtr FindWindow(string c, string w);` +
+			` [DllImport("user32.dll")] public static extern int GetWindowLong(IntPtr h, int i);` +
+			` [DllImport("user32.dll")] public static extern int SetWindowLong(IntPtr h, int i, int v);` +
+			` [DllImport("user32.dll")] public static extern bool SetLayeredWindowAttributes(IntPtr h, uint k, byte a, uint f);` +
+			` [DllImport("user32.dll")] public static extern bool SetWindowPos(IntPtr h, IntPtr a, int x, int y, int cx, int cy, uint f);'`,
+		`$w = Add-Type -MemberDefinition $sig -Name Window -Namespace Ramble -PassThru`,
+		`$h = $w::FindWindow([NullString]::Value, '` + strings.ReplaceAll(title, "'", "''") + `')`,
+		`if ($h -eq [IntPtr]::Zero) { throw 'window not found' }`,
+		`$style = $w::GetWindowLong($h, -20) -bor 0x80000`, // WS_EX_LAYERED
+		transparent,
+		`[void]$w::SetWindowLong($h, -20, $style)`,
+		fmt.Sprintf(`[void]$w::SetLayeredWindowAttributes($h, 0, %d, 2)`, int(opacity*255+0.5)), // LWA_ALPHA
+		fmt.Sprintf(`[void]$w::SetWindowPos($h, [IntPtr](%d), %s)`, insertAfter, position),
+	}, "; ")
+}
@@ -0,0 +1,64 @@
+package ui
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestWindowHintCommands(t *testing.T) {
+	installed := func(name string) (string, error) { return "/usr/bin/" + name, nil }
+	missing := func(string) (string, error) { return "", errors.New("not found") }
+	display := func(name string) string {
+		if name == "DISPLAY" {
+			return ":0"
+		}
+		return ""
+	}
+
+	commands, err := windowHintCommands("linux", display, installed, "Hover", HoverSettings{OnTop: true, Opacity: 0.5})
+	if err != nil {
+		t.Fatalf("Expected all settings to apply, got %v", err)
+	}
+	if len(commands) != 2 || strings.Join(commands[0], " ") != "wmctrl -F -r Hover -b add,above" {
+		t.Fatalf("Unexpected commands %q", commands)
+	}
+	if commands[1][len(commands[1])-1] != "2147483647" {
+		t.Errorf("Expected half opacity, got %q", commands[1])
+	}
+
+	commands, _ = windowHintCommands("linux", display, installed, "Hover", HoverSettings{Opacity: 1})
+	if strings.Join(commands[0], " ") != "wmctrl -F -r Hover -b remove,above" || commands[1][len(commands[1])-1] != "_NET_WM_WINDOW_OPACITY" {
+		t.Errorf("Expected the hints to be removed, got %q", commands)
+	}
+
+	commands, _ = windowHintCommands("linux", display, installed, "Dot", HoverSettings{OnTop: true, Opacity: 1, Move: true, X: 16, Y: 24})
+	if len(commands) != 3 || strings.Join(commands[1], " ") != "wmctrl -F -r Dot -e 0,16,24,-1,-1" {
+		t.Errorf("Expected the window to be placed, got %q", commands)
+	}
+
+	if _, err := windowHintCommands("linux", display, missing, "Hover", HoverSettings{OnTop: true, ClickThrough: true}); err == nil {
+		t.Error("Expected errors without wmctrl and for click-through")
+	}
+	if _, err := windowHintCommands("linux", func(string) string { return "" }, installed, "Hover", HoverSettings{}); err == nil {
+		t.Error("Expected an error without an X11 display")
+	}
+	if _, err := windowHintCommands("darwin", display, installed, "Hover", HoverSettings{}); err == nil {
+		t.Error("Expected an error on macOS")
+	}
+
+	commands, err = windowHintCommands("windows", nil, nil, "Hover's", HoverSettings{OnTop: true, Opacity: 0.8, ClickThrough: true})
+	script := commands[0][len(commands[0])-1]
+	if err != nil || !strings.Contains(script, "'Hover''s'") || !strings.Contains(script, "SetLayeredWindowAttributes($h, 0, 204, 2)") ||
+		!strings.Contains(script, "$style -bor 0x20") || !strings.Contains(script, "[IntPtr](-1)") {
+		t.Errorf("Unexpected script %q (%v)", script, err)
+	}
+	if !strings.Contains(script, "[IntPtr](-1), 0, 0, 0, 0, 0x13)") {
+		t.Errorf("Expected the window to stay where it is, got %q", script)
+	}
+
+	commands, _ = windowHintCommands("windows", nil, nil, "Dot", HoverSettings{OnTop: true, Opacity: 1, Move: true, X: 16, Y: 24})
+	if script := commands[0][len(commands[0])-1]; !strings.Contains(script, "[IntPtr](-1), 16, 24, 0, 0, 0x11)") {
+		t.Errorf("Expected the window to be placed, got %q", script)
+	}
+}
@@ -0,0 +1,19 @@
+#!/bin/bash
+# Downloads the tiny model built into the binary with the embed_model tag:
+#   ./scripts/fetch-embedded-model.sh
+#   go build -tags=whisper_go,embed_model -o ramble ./cmd/ramble
+set -e
+
+SCRIPT_DIR="$(cd "$(dirname "${BASH_SOURCE[0]}")" && pwd)"
+MODEL_DIR="$SCRIPT_DIR/../cmd/ramble/models"
+MODEL_URL="https://huggingface.co/ggerganov/whisper.cpp/resolve/main/ggml-tiny.en.bin"
+
+mkdir -p "$MODEL_DIR"
+if [ -f "$MODEL_DIR/ggml-tiny.en.bin" ]; then
+  echo "Embedded model already downloaded"
+  exit 0
+fi
+
+curl -fL -o "$MODEL_DIR/ggml-tiny.en.bin.part" "$MODEL_URL"
+mv "$MODEL_DIR/ggml-tiny.en.bin.part" "$MODEL_DIR/ggml-tiny.en.bin"
+echo "Downloaded the embedded model to $MODEL_DIR/ggml-tiny.en.bin"